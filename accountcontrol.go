@@ -0,0 +1,112 @@
+package adsi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AccountControl is the value of the userAccountControl attribute and its
+// constructed counterpart msDS-User-Account-Control-Computed. It is a set of
+// flags that control the behavior of user and computer accounts.
+//
+// See https://learn.microsoft.com/troubleshoot/windows-server/active-directory/useraccountcontrol-manipulate-account-properties
+type AccountControl uint32
+
+// Account control flags.
+const (
+	AccountControlScript                       AccountControl = 0x00000001
+	AccountControlAccountDisable               AccountControl = 0x00000002
+	AccountControlHomeDirRequired              AccountControl = 0x00000008
+	AccountControlLockout                      AccountControl = 0x00000010
+	AccountControlPasswordNotRequired          AccountControl = 0x00000020
+	AccountControlPasswordCantChange           AccountControl = 0x00000040
+	AccountControlEncryptedTextPasswordAllowed AccountControl = 0x00000080
+	AccountControlTempDuplicateAccount         AccountControl = 0x00000100
+	AccountControlNormalAccount                AccountControl = 0x00000200
+	AccountControlInterdomainTrustAccount      AccountControl = 0x00000800
+	AccountControlWorkstationTrustAccount      AccountControl = 0x00001000
+	AccountControlServerTrustAccount           AccountControl = 0x00002000
+	AccountControlDontExpirePassword           AccountControl = 0x00010000
+	AccountControlMNSLogonAccount              AccountControl = 0x00020000
+	AccountControlSmartcardRequired            AccountControl = 0x00040000
+	AccountControlTrustedForDelegation         AccountControl = 0x00080000
+	AccountControlNotDelegated                 AccountControl = 0x00100000
+	AccountControlUseDESKeyOnly                AccountControl = 0x00200000
+	AccountControlDontRequirePreauth           AccountControl = 0x00400000
+	AccountControlPasswordExpired              AccountControl = 0x00800000
+	AccountControlTrustedToAuthForDelegation   AccountControl = 0x01000000
+	AccountControlPartialSecretsAccount        AccountControl = 0x04000000
+)
+
+var accountControlNames = []struct {
+	flag AccountControl
+	name string
+}{
+	{AccountControlScript, "SCRIPT"},
+	{AccountControlAccountDisable, "ACCOUNTDISABLE"},
+	{AccountControlHomeDirRequired, "HOMEDIR_REQUIRED"},
+	{AccountControlLockout, "LOCKOUT"},
+	{AccountControlPasswordNotRequired, "PASSWD_NOTREQD"},
+	{AccountControlPasswordCantChange, "PASSWD_CANT_CHANGE"},
+	{AccountControlEncryptedTextPasswordAllowed, "ENCRYPTED_TEXT_PWD_ALLOWED"},
+	{AccountControlTempDuplicateAccount, "TEMP_DUPLICATE_ACCOUNT"},
+	{AccountControlNormalAccount, "NORMAL_ACCOUNT"},
+	{AccountControlInterdomainTrustAccount, "INTERDOMAIN_TRUST_ACCOUNT"},
+	{AccountControlWorkstationTrustAccount, "WORKSTATION_TRUST_ACCOUNT"},
+	{AccountControlServerTrustAccount, "SERVER_TRUST_ACCOUNT"},
+	{AccountControlDontExpirePassword, "DONT_EXPIRE_PASSWORD"},
+	{AccountControlMNSLogonAccount, "MNS_LOGON_ACCOUNT"},
+	{AccountControlSmartcardRequired, "SMARTCARD_REQUIRED"},
+	{AccountControlTrustedForDelegation, "TRUSTED_FOR_DELEGATION"},
+	{AccountControlNotDelegated, "NOT_DELEGATED"},
+	{AccountControlUseDESKeyOnly, "USE_DES_KEY_ONLY"},
+	{AccountControlDontRequirePreauth, "DONT_REQ_PREAUTH"},
+	{AccountControlPasswordExpired, "PASSWORD_EXPIRED"},
+	{AccountControlTrustedToAuthForDelegation, "TRUSTED_TO_AUTH_FOR_DELEGATION"},
+	{AccountControlPartialSecretsAccount, "PARTIAL_SECRETS_ACCOUNT"},
+}
+
+// Has returns true if all of the given flags are set.
+func (c AccountControl) Has(flags AccountControl) bool {
+	return c&flags == flags
+}
+
+// String returns the names of the flags that are set, separated by '|'.
+func (c AccountControl) String() string {
+	var names []string
+	remaining := c
+	for _, entry := range accountControlNames {
+		if c&entry.flag != 0 {
+			names = append(names, entry.name)
+			remaining &^= entry.flag
+		}
+	}
+	if remaining != 0 {
+		names = append(names, fmt.Sprintf("0x%08X", uint32(remaining)))
+	}
+	if len(names) == 0 {
+		return "0"
+	}
+	return strings.Join(names, "|")
+}
+
+// AccountControl retrieves the userAccountControl attribute of the object.
+func (o *object) AccountControl() (c AccountControl, err error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return 0, ErrClosed
+	}
+	value, err := o.AttrInt64("userAccountControl")
+	if err != nil {
+		return
+	}
+	return AccountControl(uint32(value)), nil
+}
+
+// SetAccountControl sets the userAccountControl attribute of the object in
+// the ADSI attribute cache. The value must be commited with SetInfo to be
+// made persistent.
+func (o *object) SetAccountControl(c AccountControl) error {
+	return o.PutInt("userAccountControl", int(int32(c)))
+}
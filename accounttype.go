@@ -0,0 +1,89 @@
+package adsi
+
+import "fmt"
+
+// AccountType is the value of the sAMAccountType attribute, which classifies
+// security principals in Active Directory.
+//
+// See https://learn.microsoft.com/windows/win32/adschema/a-samaccounttype
+type AccountType uint32
+
+// Account types.
+const (
+	AccountTypeDomainObject           AccountType = 0x00000000
+	AccountTypeGroupObject            AccountType = 0x10000000
+	AccountTypeNonSecurityGroupObject AccountType = 0x10000001
+	AccountTypeAliasObject            AccountType = 0x20000000
+	AccountTypeNonSecurityAliasObject AccountType = 0x20000001
+	AccountTypeNormalUserAccount      AccountType = 0x30000000
+	AccountTypeMachineAccount         AccountType = 0x30000001
+	AccountTypeTrustAccount           AccountType = 0x30000002
+	AccountTypeAppBasicGroup          AccountType = 0x40000000
+	AccountTypeAppQueryGroup          AccountType = 0x40000001
+)
+
+var accountTypeNames = map[AccountType]string{
+	AccountTypeDomainObject:           "domain object",
+	AccountTypeGroupObject:            "group object",
+	AccountTypeNonSecurityGroupObject: "non-security group object",
+	AccountTypeAliasObject:            "alias object",
+	AccountTypeNonSecurityAliasObject: "non-security alias object",
+	AccountTypeNormalUserAccount:      "normal user account",
+	AccountTypeMachineAccount:         "machine account",
+	AccountTypeTrustAccount:           "trust account",
+	AccountTypeAppBasicGroup:          "app basic group",
+	AccountTypeAppQueryGroup:          "app query group",
+}
+
+// String returns the name of the account type.
+func (t AccountType) String() string {
+	if name, ok := accountTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown account type 0x%08X", uint32(t))
+}
+
+// IsUser returns true if the account type describes a user, machine or trust
+// account.
+func (t AccountType) IsUser() bool {
+	return t&0xF0000000 == 0x30000000
+}
+
+// IsMachine returns true if the account type describes a computer account.
+func (t AccountType) IsMachine() bool {
+	return t == AccountTypeMachineAccount
+}
+
+// IsGroup returns true if the account type describes a group or alias of any
+// kind.
+func (t AccountType) IsGroup() bool {
+	switch t & 0xF0000000 {
+	case 0x10000000, 0x20000000, 0x40000000:
+		return true
+	}
+	return false
+}
+
+// IsSecurity returns true if the account type describes a security
+// principal. Distribution groups and aliases are not security principals.
+func (t AccountType) IsSecurity() bool {
+	switch t {
+	case AccountTypeNonSecurityGroupObject, AccountTypeNonSecurityAliasObject:
+		return false
+	}
+	return true
+}
+
+// AccountType retrieves the sAMAccountType attribute of the object.
+func (o *object) AccountType() (t AccountType, err error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return 0, ErrClosed
+	}
+	value, err := o.AttrInt64("sAMAccountType")
+	if err != nil {
+		return
+	}
+	return AccountType(uint32(value)), nil
+}
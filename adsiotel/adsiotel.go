@@ -0,0 +1,80 @@
+// Package adsiotel records the directory operations of adsi clients as
+// OpenTelemetry spans.
+//
+//	client.SetTracer(adsiotel.NewTracer(otel.GetTracerProvider(), nil))
+package adsiotel
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-adsi/adsi"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName is the name of the tracer used to create spans.
+const instrumentationName = "github.com/go-adsi/adsi"
+
+// Attribute keys recorded on each span, in addition to server.address.
+const (
+	KeyPath       = attribute.Key("adsi.path")
+	KeyFilter     = attribute.Key("adsi.filter")
+	KeyRows       = attribute.Key("adsi.rows")
+	KeyAttributes = attribute.Key("adsi.attributes")
+	KeyHRESULT    = attribute.Key("adsi.hresult")
+)
+
+// Tracer is an adsi.Tracer that records spans with OpenTelemetry.
+type Tracer struct {
+	tracer trace.Tracer
+	parent func() context.Context
+}
+
+// NewTracer returns a tracer that creates spans with the given provider.
+//
+// ADSI calls do not take a context, so parent is called for each span to
+// obtain the context of its parent span, such as the span of the request
+// being served. If parent is nil or returns nil the spans have no parent.
+func NewTracer(tp trace.TracerProvider, parent func() context.Context) *Tracer {
+	return &Tracer{tracer: tp.Tracer(instrumentationName), parent: parent}
+}
+
+// Span records a span for a completed directory operation.
+func (t *Tracer) Span(s adsi.TraceSpan) {
+	var ctx context.Context
+	if t.parent != nil {
+		ctx = t.parent()
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var attrs []attribute.KeyValue
+	if s.Server != "" {
+		attrs = append(attrs, attribute.String("server.address", s.Server))
+	}
+	if s.Path != "" {
+		attrs = append(attrs, KeyPath.String(s.Path))
+	}
+	if s.Name == adsi.SpanSearch {
+		attrs = append(attrs, KeyFilter.String(s.Filter), KeyRows.Int(s.Rows))
+	}
+	if len(s.Attributes) > 0 {
+		attrs = append(attrs, KeyAttributes.StringSlice(s.Attributes))
+	}
+	if s.HRESULT != 0 {
+		attrs = append(attrs, KeyHRESULT.String(fmt.Sprintf("0x%08X", s.HRESULT)))
+	}
+
+	_, span := t.tracer.Start(ctx, s.Name,
+		trace.WithTimestamp(s.Start),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+	if s.Err != nil {
+		span.RecordError(s.Err)
+		span.SetStatus(codes.Error, s.Err.Error())
+	}
+	span.End(trace.WithTimestamp(s.End))
+}
@@ -0,0 +1,17 @@
+module github.com/go-adsi/adsi/adsiotel
+
+go 1.22
+
+require (
+	github.com/go-adsi/adsi v0.1.0
+	go.opentelemetry.io/otel v1.31.0
+	go.opentelemetry.io/otel/trace v1.31.0
+)
+
+require (
+	github.com/go-ole/go-ole v1.3.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/scjalliance/comshim v0.0.0-20240712181150-e070933cb68e // indirect
+	github.com/scjalliance/comutil v0.0.0-20240712181340-772427873823 // indirect
+	golang.org/x/sys v0.22.0 // indirect
+)
@@ -0,0 +1,433 @@
+// Package adsitest provides an in-memory directory that implements the
+// interfaces of the adsi package, so that code written against them can be
+// tested without a domain controller.
+//
+//	dir := adsitest.New()
+//	dir.Add("CN=Jane Doe,CN=Users,DC=example,DC=com", adsitest.Attributes{
+//		"objectClass":    {"top", "person", "organizationalPerson", "user"},
+//		"sAMAccountName": {"jane"},
+//	})
+//	var d adsi.Directory = dir
+//
+// Objects are identified by their distinguished names, and containers are
+// implied by them: an object is a child of the object whose name is the
+// remainder of its own after the first component. Searches support the
+// subtree, one level and base scopes and evaluate the filters described in
+// ParseFilter.
+//
+// Tests that need a real domain can instead be run once against one through
+// a Recorder, which captures the results of each call to a golden file, and
+// afterwards against a Replayer that serves them back:
+//
+//	if *record {
+//		rec := adsitest.Record(client)
+//		defer rec.WriteFile("testdata/users.json")
+//		dir = rec
+//	} else {
+//		dir, err = adsitest.ReadFile("testdata/users.json")
+//	}
+package adsitest
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-adsi/adsi"
+	"github.com/go-adsi/adsi/adspath"
+	"github.com/go-adsi/adsi/api"
+)
+
+// The fake directory implements the interfaces of the adsi package that it
+// stands in for.
+var (
+	_ adsi.Directory   = (*Directory)(nil)
+	_ adsi.Entry       = (*Entry)(nil)
+	_ adsi.RowIterator = (*Rows)(nil)
+)
+
+// Attributes maps attribute names to their values. Values may be strings,
+// byte slices, booleans, integers or times, as returned by adsi.Object.Attr.
+type Attributes map[string][]interface{}
+
+// Directory is an in-memory directory. It implements adsi.Directory. The
+// zero value is an empty directory ready to use.
+type Directory struct {
+	m       sync.Mutex
+	objects map[string]*object
+}
+
+type object struct {
+	dn    string
+	attrs Attributes
+}
+
+// New returns an empty directory.
+func New() *Directory {
+	return &Directory{}
+}
+
+// Add adds an object with the given distinguished name and attributes to the
+// directory, replacing any object with the same name. Its distinguishedName
+// and name attributes are set from dn unless they are included in attrs.
+// Parent objects are not required to exist.
+func (d *Directory) Add(dn string, attrs Attributes) {
+	obj := &object{dn: dn, attrs: make(Attributes, len(attrs)+2)}
+	for name, values := range attrs {
+		obj.attrs[name] = append([]interface{}(nil), values...)
+	}
+	if obj.attr("distinguishedName") == nil {
+		obj.attrs["distinguishedName"] = []interface{}{dn}
+	}
+	if obj.attr("name") == nil {
+		if _, value, ok := strings.Cut(rdn(dn), "="); ok {
+			obj.attrs["name"] = []interface{}{value}
+		}
+	}
+
+	d.m.Lock()
+	defer d.m.Unlock()
+	if d.objects == nil {
+		d.objects = make(map[string]*object)
+	}
+	d.objects[normalizeDN(dn)] = obj
+}
+
+// Remove removes the object with the given distinguished name from the
+// directory. Its children are not removed.
+func (d *Directory) Remove(dn string) {
+	d.m.Lock()
+	defer d.m.Unlock()
+	delete(d.objects, normalizeDN(dn))
+}
+
+// Attributes returns a copy of the attributes of the object with the given
+// distinguished name, or nil if there is no such object. It allows tests to
+// check the changes made by the code under test.
+func (d *Directory) Attributes(dn string) Attributes {
+	d.m.Lock()
+	defer d.m.Unlock()
+	obj := d.objects[normalizeDN(dn)]
+	if obj == nil {
+		return nil
+	}
+	return obj.copy()
+}
+
+// Bind returns the object with the given ADsPath. It returns an error
+// matching adsi.ErrNoSuchObject if there is no such object.
+func (d *Directory) Bind(path string) (adsi.Entry, error) {
+	dn, err := pathDN(path)
+	if err != nil {
+		return nil, err
+	}
+	d.m.Lock()
+	defer d.m.Unlock()
+	obj := d.objects[normalizeDN(dn)]
+	if obj == nil {
+		return nil, &adsi.Error{Op: "Open", Path: path, HRESULT: adsi.ErrNoSuchObject.HRESULT}
+	}
+	return &Entry{d: d, dn: obj.dn}, nil
+}
+
+// Find searches the directory beneath the object with the given ADsPath. The
+// filter, attributes, scope and size limit of the query are honored. Rows are
+// returned in order of their distinguished names, and each includes an
+// ADsPath column.
+func (d *Directory) Find(path string, q adsi.Query) (adsi.RowIterator, error) {
+	base, err := pathDN(path)
+	if err != nil {
+		return nil, err
+	}
+	text := q.Filter
+	if text == "" {
+		text = "(objectClass=*)"
+	}
+	filter, err := ParseFilter(text)
+	if err != nil {
+		return nil, err
+	}
+
+	d.m.Lock()
+	defer d.m.Unlock()
+	key := normalizeDN(base)
+	if d.objects[key] == nil {
+		return nil, &adsi.Error{Op: "Search", Path: path, HRESULT: adsi.ErrNoSuchObject.HRESULT}
+	}
+	var matches []*object
+	for dn, obj := range d.objects {
+		if inScope(dn, key, q.Scope) && filter.Match(obj.attrs) {
+			matches = append(matches, obj)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].dn < matches[j].dn })
+	if q.SizeLimit > 0 && len(matches) > q.SizeLimit {
+		matches = matches[:q.SizeLimit]
+	}
+
+	rows := make([]*adsi.Row, len(matches))
+	for i, obj := range matches {
+		rows[i] = obj.row(pathPrefix(path), q.Attributes)
+	}
+	return &Rows{rows: rows}, nil
+}
+
+// attr returns the values of the named attribute. Names are matched
+// case-insensitively.
+func (o *object) attr(name string) []interface{} {
+	if values, ok := o.attrs[name]; ok {
+		return values
+	}
+	for n, values := range o.attrs {
+		if strings.EqualFold(n, name) {
+			return values
+		}
+	}
+	return nil
+}
+
+// key returns the name under which the named attribute is stored, or name
+// itself if the object doesn't have it.
+func (o *object) key(name string) string {
+	for n := range o.attrs {
+		if strings.EqualFold(n, name) {
+			return n
+		}
+	}
+	return name
+}
+
+// copy returns a copy of the object's attributes.
+func (o *object) copy() Attributes {
+	attrs := make(Attributes, len(o.attrs))
+	for name, values := range o.attrs {
+		attrs[name] = append([]interface{}(nil), values...)
+	}
+	return attrs
+}
+
+// row returns a search row holding the requested attributes of the object,
+// or all of them if none are requested or "*" is among them.
+func (o *object) row(prefix string, names []string) *adsi.Row {
+	var columns []adsi.Column
+	if len(names) == 0 || slices.Contains(names, "*") {
+		var all []string
+		for name := range o.attrs {
+			all = append(all, name)
+		}
+		sort.Strings(all)
+		for _, name := range names {
+			if name != "*" && o.attrs[o.key(name)] == nil {
+				all = append(all, name)
+			}
+		}
+		names = all
+	}
+	for _, name := range names {
+		if strings.EqualFold(name, "ADsPath") {
+			continue
+		}
+		if values := o.attr(name); values != nil {
+			columns = append(columns, adsi.Column{Name: o.key(name), Values: append([]interface{}(nil), values...)})
+		}
+	}
+	columns = append(columns, adsi.Column{Name: "ADsPath", Type: api.ADSTYPE_CASE_IGNORE_STRING, Values: []interface{}{prefix + adspath.EscapeDN(o.dn)}})
+	return adsi.NewRow(columns...)
+}
+
+// Entry is an object opened from a Directory. It implements adsi.Entry.
+// Changes made with PutEx are kept by the entry until SetInfo is called.
+type Entry struct {
+	d       *Directory
+	dn      string
+	pending []change
+	closed  bool
+}
+
+type change struct {
+	code   uint32
+	name   string
+	values []interface{}
+}
+
+// object returns the directory object of the entry. The caller must hold the
+// directory's lock.
+func (e *Entry) object() (*object, error) {
+	if e.closed {
+		return nil, adsi.ErrClosed
+	}
+	obj := e.d.objects[normalizeDN(e.dn)]
+	if obj == nil {
+		return nil, &adsi.Error{Op: "GetInfo", Path: "LDAP://" + e.dn, HRESULT: adsi.ErrNoSuchObject.HRESULT}
+	}
+	return obj, nil
+}
+
+// Name returns the first component of the entry's distinguished name, such as
+// "CN=Jane Doe".
+func (e *Entry) Name() (string, error) {
+	e.d.m.Lock()
+	defer e.d.m.Unlock()
+	obj, err := e.object()
+	if err != nil {
+		return "", err
+	}
+	return rdn(obj.dn), nil
+}
+
+// Class returns the last value of the entry's objectClass attribute.
+func (e *Entry) Class() (string, error) {
+	e.d.m.Lock()
+	defer e.d.m.Unlock()
+	obj, err := e.object()
+	if err != nil {
+		return "", err
+	}
+	classes := obj.attr("objectClass")
+	if len(classes) == 0 {
+		return "", nil
+	}
+	class, _ := classes[len(classes)-1].(string)
+	return class, nil
+}
+
+// Path returns the serverless ADsPath of the entry.
+func (e *Entry) Path() (string, error) {
+	e.d.m.Lock()
+	defer e.d.m.Unlock()
+	obj, err := e.object()
+	if err != nil {
+		return "", err
+	}
+	return "LDAP://" + adspath.EscapeDN(obj.dn), nil
+}
+
+// Attr returns a copy of the values of the named attribute, including any
+// changes that have not yet been saved. It returns an error with the
+// E_ADS_PROPERTY_NOT_FOUND HRESULT if the attribute has no values.
+func (e *Entry) Attr(name string) ([]interface{}, error) {
+	e.d.m.Lock()
+	defer e.d.m.Unlock()
+	obj, err := e.object()
+	if err != nil {
+		return nil, err
+	}
+	values := obj.attr(name)
+	for _, c := range e.pending {
+		if strings.EqualFold(c.name, name) {
+			values = apply(values, c.code, c.values)
+		}
+	}
+	if len(values) == 0 {
+		return nil, &adsi.Error{Op: "GetEx " + name, HRESULT: api.E_ADS_PROPERTY_NOT_FOUND, Err: api.ErrPropertyNotFound}
+	}
+	return append([]interface{}(nil), values...), nil
+}
+
+// PutEx records a change to the named attribute, which is applied to the
+// directory by SetInfo. The control code is one of the ADS_PROPERTY_*
+// constants in the api package.
+func (e *Entry) PutEx(controlCode uint32, name string, values ...interface{}) error {
+	if controlCode < api.ADS_PROPERTY_CLEAR || controlCode > api.ADS_PROPERTY_DELETE {
+		return errors.New("adsitest: invalid control code")
+	}
+	e.d.m.Lock()
+	defer e.d.m.Unlock()
+	if _, err := e.object(); err != nil {
+		return err
+	}
+	e.pending = append(e.pending, change{code: controlCode, name: name, values: append([]interface{}(nil), values...)})
+	return nil
+}
+
+// SetInfo applies the changes recorded by PutEx to the directory.
+func (e *Entry) SetInfo() error {
+	e.d.m.Lock()
+	defer e.d.m.Unlock()
+	obj, err := e.object()
+	if err != nil {
+		return err
+	}
+	for _, c := range e.pending {
+		key := obj.key(c.name)
+		if values := apply(obj.attrs[key], c.code, c.values); len(values) > 0 {
+			obj.attrs[key] = values
+		} else {
+			delete(obj.attrs, key)
+		}
+	}
+	e.pending = nil
+	return nil
+}
+
+// Close releases the entry. Changes that have not been saved are discarded.
+func (e *Entry) Close() error {
+	e.d.m.Lock()
+	defer e.d.m.Unlock()
+	e.closed = true
+	e.pending = nil
+	return nil
+}
+
+// apply returns the values that result from applying a change with the given
+// control code to values. The original slice is not modified.
+func apply(values []interface{}, code uint32, changed []interface{}) []interface{} {
+	switch code {
+	case api.ADS_PROPERTY_CLEAR:
+		return nil
+	case api.ADS_PROPERTY_UPDATE:
+		return append([]interface{}(nil), changed...)
+	case api.ADS_PROPERTY_APPEND:
+		return append(append([]interface{}(nil), values...), changed...)
+	case api.ADS_PROPERTY_DELETE:
+		var kept []interface{}
+		for _, v := range values {
+			deleted := false
+			for _, c := range changed {
+				if equalValues(v, c) {
+					deleted = true
+					break
+				}
+			}
+			if !deleted {
+				kept = append(kept, v)
+			}
+		}
+		return kept
+	}
+	return values
+}
+
+// Rows iterates over the rows returned by Directory.Find and by Recorder and
+// Replayer. It implements adsi.RowIterator.
+type Rows struct {
+	rows   []*adsi.Row
+	err    error
+	closed bool
+}
+
+// Next returns the next row, or io.EOF once every row has been returned.
+func (r *Rows) Next() (*adsi.Row, error) {
+	if r.closed {
+		return nil, adsi.ErrClosed
+	}
+	if len(r.rows) == 0 {
+		if r.err != nil {
+			return nil, r.err
+		}
+		return nil, io.EOF
+	}
+	row := r.rows[0]
+	r.rows = r.rows[1:]
+	return row, nil
+}
+
+// Close releases the rows.
+func (r *Rows) Close() error {
+	r.closed = true
+	r.rows = nil
+	return nil
+}
@@ -0,0 +1,80 @@
+package adsitest
+
+import (
+	"strings"
+
+	"github.com/go-adsi/adsi"
+	"github.com/go-adsi/adsi/adspath"
+)
+
+// splitDN splits a distinguished name into its components, honoring
+// escaped commas.
+func splitDN(dn string) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(dn); i++ {
+		switch dn[i] {
+		case '\\':
+			i++
+		case ',':
+			parts = append(parts, strings.TrimSpace(dn[start:i]))
+			start = i + 1
+		}
+	}
+	if dn = strings.TrimSpace(dn[start:]); dn != "" || len(parts) > 0 {
+		parts = append(parts, dn)
+	}
+	return parts
+}
+
+// normalizeDN returns the form of a distinguished name that is used to look
+// up objects, in which case and the spacing between components don't
+// matter.
+func normalizeDN(dn string) string {
+	return strings.ToLower(strings.Join(splitDN(dn), ","))
+}
+
+// rdn returns the first component of a distinguished name.
+func rdn(dn string) string {
+	if parts := splitDN(dn); len(parts) > 0 {
+		return parts[0]
+	}
+	return ""
+}
+
+// inScope returns true if the object with the normalized name dn is within
+// the given scope of the normalized base.
+func inScope(dn, base string, scope adsi.SearchScope) bool {
+	switch scope {
+	case adsi.ScopeBase:
+		return dn == base
+	case adsi.ScopeOneLevel:
+		parts := splitDN(dn)
+		return len(parts) > 1 && strings.Join(parts[1:], ",") == base
+	default:
+		return dn == base || base == "" || strings.HasSuffix(dn, ","+base)
+	}
+}
+
+// pathDN returns the distinguished name held by an LDAP or GC ADsPath.
+func pathDN(path string) (string, error) {
+	p, err := adspath.Parse(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.ReplaceAll(p.Path, `\/`, "/"), nil
+}
+
+// pathPrefix returns the part of an ADsPath that precedes the distinguished
+// name, such as "LDAP://dc1.example.com/".
+func pathPrefix(path string) string {
+	p, err := adspath.Parse(path)
+	if err != nil || p.Scheme == "" {
+		return "LDAP://"
+	}
+	prefix := p.Scheme + "://"
+	if p.Host != "" {
+		prefix += p.Host + "/"
+	}
+	return prefix
+}
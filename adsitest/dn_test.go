@@ -0,0 +1,121 @@
+package adsitest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-adsi/adsi"
+)
+
+func TestSplitDN(t *testing.T) {
+	tests := []struct {
+		dn   string
+		want []string
+	}{
+		{"", nil},
+		{"DC=com", []string{"DC=com"}},
+		{"CN=Jane Doe, OU=Staff ,DC=example,DC=com", []string{"CN=Jane Doe", "OU=Staff", "DC=example", "DC=com"}},
+		{`CN=Doe\, Jane,OU=Staff`, []string{`CN=Doe\, Jane`, "OU=Staff"}},
+		{`CN=a\\,OU=Staff`, []string{`CN=a\\`, "OU=Staff"}},
+		{"CN=a,", []string{"CN=a", ""}},
+	}
+	for _, tt := range tests {
+		if got := splitDN(tt.dn); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitDN(%q) = %q, want %q", tt.dn, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeDN(t *testing.T) {
+	tests := []struct {
+		dn, want string
+	}{
+		{"CN=Jane Doe, OU=Staff, DC=Example, DC=com", "cn=jane doe,ou=staff,dc=example,dc=com"},
+		{`CN=Doe\, Jane , DC=com`, `cn=doe\, jane,dc=com`},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeDN(tt.dn); got != tt.want {
+			t.Errorf("normalizeDN(%q) = %q, want %q", tt.dn, got, tt.want)
+		}
+	}
+}
+
+func TestRDN(t *testing.T) {
+	tests := []struct {
+		dn, want string
+	}{
+		{"CN=Jane Doe,OU=Staff,DC=example,DC=com", "CN=Jane Doe"},
+		{`CN=Doe\, Jane,DC=com`, `CN=Doe\, Jane`},
+		{"DC=com", "DC=com"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := rdn(tt.dn); got != tt.want {
+			t.Errorf("rdn(%q) = %q, want %q", tt.dn, got, tt.want)
+		}
+	}
+}
+
+func TestInScope(t *testing.T) {
+	const base = "ou=staff,dc=example,dc=com"
+	tests := []struct {
+		dn    string
+		base  string
+		scope adsi.SearchScope
+		want  bool
+	}{
+		{base, base, adsi.ScopeBase, true},
+		{"cn=a," + base, base, adsi.ScopeBase, false},
+		{"cn=a," + base, base, adsi.ScopeOneLevel, true},
+		{"cn=b,cn=a," + base, base, adsi.ScopeOneLevel, false},
+		{base, base, adsi.ScopeOneLevel, false},
+		{base, base, adsi.ScopeSubtree, true},
+		{"cn=b,cn=a," + base, base, adsi.ScopeSubtree, true},
+		{"cn=a,ou=otherstaff,dc=example,dc=com", base, adsi.ScopeSubtree, false},
+		{"cn=a,dc=example,dc=com", base, adsi.ScopeSubtree, false},
+		{"cn=a,dc=example,dc=com", "", adsi.ScopeSubtree, true},
+	}
+	for _, tt := range tests {
+		if got := inScope(tt.dn, tt.base, tt.scope); got != tt.want {
+			t.Errorf("inScope(%q, %q, %v) = %v, want %v", tt.dn, tt.base, tt.scope, got, tt.want)
+		}
+	}
+}
+
+func TestPathDN(t *testing.T) {
+	tests := []struct {
+		path, want string
+	}{
+		{"LDAP://CN=Jane Doe,DC=example,DC=com", "CN=Jane Doe,DC=example,DC=com"},
+		{"LDAP://dc1.example.com/CN=Jane Doe,DC=example,DC=com", "CN=Jane Doe,DC=example,DC=com"},
+		{"GC://DC=example,DC=com", "DC=example,DC=com"},
+		{`LDAP://dc1.example.com/CN=a\/b,DC=example,DC=com`, "CN=a/b,DC=example,DC=com"},
+	}
+	for _, tt := range tests {
+		got, err := pathDN(tt.path)
+		if err != nil {
+			t.Errorf("pathDN(%q) returned %v", tt.path, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("pathDN(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestPathPrefix(t *testing.T) {
+	tests := []struct {
+		path, want string
+	}{
+		{"LDAP://CN=Jane Doe,DC=example,DC=com", "LDAP://"},
+		{"LDAP://dc1.example.com/CN=Jane Doe,DC=example,DC=com", "LDAP://dc1.example.com/"},
+		{"GC://DC=example,DC=com", "GC://"},
+		{"CN=Jane Doe,DC=example,DC=com", "LDAP://"},
+	}
+	for _, tt := range tests {
+		if got := pathPrefix(tt.path); got != tt.want {
+			t.Errorf("pathPrefix(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package adsitest
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-adsi/adsi"
+)
+
+// ErrInvalidFilter is returned when a search filter cannot be parsed.
+var ErrInvalidFilter = errors.New("adsitest: invalid search filter")
+
+// Matching rules supported by extensible match filters.
+const (
+	ruleBitAnd  = "1.2.840.113556.1.4.803"
+	ruleBitOr   = "1.2.840.113556.1.4.804"
+	ruleInChain = "1.2.840.113556.1.4.1941"
+)
+
+// Filter is a parsed LDAP search filter.
+type Filter struct {
+	op       byte // '&', '|', '!', '=', '>', '<', '~', '*' (presence), 's' (substrings) or ':'
+	attr     string
+	rule     string
+	value    []byte
+	subs     [][]byte // initial, any..., final for substring filters
+	children []*Filter
+}
+
+// ParseFilter parses an LDAP search filter as described by RFC 4515. It
+// supports the and, or and not operators, and equality, ordering,
+// approximate, presence and substring assertions, as well as the extensible
+// matches used by Active Directory with the bitwise and and or matching
+// rules. The in-chain matching rule is evaluated as an equality match,
+// without following the links between objects.
+//
+// Values are compared according to their type: strings case-insensitively,
+// integers and times numerically and byte slices exactly. As in Active
+// Directory, objectCategory may be matched by the name of the category
+// instead of its distinguished name, and (objectClass=*) matches every
+// object.
+func ParseFilter(s string) (*Filter, error) {
+	f, rest, err := parseFilter(strings.TrimSpace(s))
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(rest) != "" {
+		return nil, ErrInvalidFilter
+	}
+	return f, nil
+}
+
+// parseFilter parses a parenthesized filter at the start of s and returns
+// the rest of s.
+func parseFilter(s string) (f *Filter, rest string, err error) {
+	if !strings.HasPrefix(s, "(") || len(s) < 3 {
+		return nil, "", ErrInvalidFilter
+	}
+	s = s[1:]
+	switch s[0] {
+	case '&', '|':
+		f = &Filter{op: s[0]}
+		s = s[1:]
+		for strings.HasPrefix(s, "(") {
+			var child *Filter
+			if child, s, err = parseFilter(s); err != nil {
+				return nil, "", err
+			}
+			f.children = append(f.children, child)
+		}
+	case '!':
+		var child *Filter
+		if child, s, err = parseFilter(s[1:]); err != nil {
+			return nil, "", err
+		}
+		f = &Filter{op: '!', children: []*Filter{child}}
+	default:
+		end := strings.IndexByte(s, ')')
+		if end < 0 {
+			return nil, "", ErrInvalidFilter
+		}
+		if f, err = parseItem(s[:end]); err != nil {
+			return nil, "", err
+		}
+		s = s[end:]
+	}
+	if !strings.HasPrefix(s, ")") {
+		return nil, "", ErrInvalidFilter
+	}
+	return f, s[1:], nil
+}
+
+// parseItem parses a single assertion, without its parentheses.
+func parseItem(s string) (*Filter, error) {
+	i := strings.IndexByte(s, '=')
+	if i < 1 {
+		return nil, ErrInvalidFilter
+	}
+	attr, raw := s[:i], s[i+1:]
+	f := &Filter{op: '='}
+	switch attr[len(attr)-1] {
+	case '>', '<', '~':
+		f.op = attr[len(attr)-1]
+		attr = attr[:len(attr)-1]
+	case ':':
+		f.op = ':'
+		parts := strings.Split(attr[:len(attr)-1], ":")
+		if len(parts) != 2 || parts[1] == "" {
+			return nil, ErrInvalidFilter
+		}
+		attr, f.rule = parts[0], parts[1]
+	}
+	if attr == "" {
+		return nil, ErrInvalidFilter
+	}
+	f.attr = attr
+
+	if f.op == '=' && raw == "*" {
+		f.op = '*'
+		return f, nil
+	}
+	if f.op == '=' && strings.Contains(raw, "*") {
+		f.op = 's'
+		for _, part := range strings.Split(raw, "*") {
+			value, err := unescape(part)
+			if err != nil {
+				return nil, err
+			}
+			f.subs = append(f.subs, value)
+		}
+		return f, nil
+	}
+	value, err := unescape(raw)
+	if err != nil {
+		return nil, err
+	}
+	f.value = value
+	return f, nil
+}
+
+// unescape replaces the \xx escape sequences of a filter value with the
+// bytes they represent.
+func unescape(s string) ([]byte, error) {
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b = append(b, s[i])
+			continue
+		}
+		if i+3 > len(s) {
+			return nil, ErrInvalidFilter
+		}
+		n, err := strconv.ParseUint(s[i+1:i+3], 16, 8)
+		if err != nil {
+			return nil, ErrInvalidFilter
+		}
+		b = append(b, byte(n))
+		i += 2
+	}
+	return b, nil
+}
+
+// Match returns true if an object with the given attributes matches the
+// filter.
+func (f *Filter) Match(attrs Attributes) bool {
+	switch f.op {
+	case '&':
+		for _, child := range f.children {
+			if !child.Match(attrs) {
+				return false
+			}
+		}
+		return true
+	case '|':
+		for _, child := range f.children {
+			if child.Match(attrs) {
+				return true
+			}
+		}
+		return false
+	case '!':
+		return !f.children[0].Match(attrs)
+	}
+
+	obj := object{attrs: attrs}
+	values := obj.attr(f.attr)
+	if f.op == '*' {
+		return len(values) > 0 || strings.EqualFold(f.attr, "objectClass")
+	}
+	for _, v := range values {
+		if f.matchValue(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchValue returns true if a single attribute value satisfies the
+// assertion.
+func (f *Filter) matchValue(v interface{}) bool {
+	if f.op == 's' {
+		s, ok := v.(string)
+		return ok && matchSubstrings(strings.ToLower(s), f.subs)
+	}
+	if f.op == ':' {
+		switch f.rule {
+		case ruleBitAnd, ruleBitOr:
+			n, ok := toInt64(v)
+			a, err := strconv.ParseInt(string(f.value), 10, 64)
+			if !ok || err != nil {
+				return false
+			}
+			if f.rule == ruleBitAnd {
+				return n&a == a
+			}
+			return n&a != 0
+		case ruleInChain:
+			return compare(f.attr, v, f.value) == 0
+		}
+		return false
+	}
+	c := compare(f.attr, v, f.value)
+	switch f.op {
+	case '=', '~':
+		return c == 0
+	case '>':
+		return c >= 0 && c != incomparable
+	case '<':
+		return c <= 0
+	}
+	return false
+}
+
+// incomparable is returned by compare when a value cannot be compared with
+// an assertion.
+const incomparable = 2
+
+// compare compares an attribute value with an assertion value, returning -1,
+// 0 or 1, or incomparable.
+func compare(attr string, v interface{}, assertion []byte) int {
+	switch x := v.(type) {
+	case string:
+		a := string(assertion)
+		if strings.EqualFold(attr, "objectCategory") && !strings.Contains(a, "=") {
+			if _, name, ok := strings.Cut(rdn(x), "="); ok {
+				x = name
+			}
+		}
+		return strings.Compare(strings.ToLower(x), strings.ToLower(a))
+	case []byte:
+		return bytes.Compare(x, assertion)
+	case bool:
+		if strings.EqualFold(string(assertion), strconv.FormatBool(x)) {
+			return 0
+		}
+		return incomparable
+	case time.Time:
+		t, err := adsi.ParseGeneralizedTime(string(assertion))
+		if err != nil {
+			return incomparable
+		}
+		return x.Compare(t)
+	}
+	if n, ok := toInt64(v); ok {
+		a, err := strconv.ParseInt(string(assertion), 10, 64)
+		switch {
+		case err != nil:
+			return incomparable
+		case n < a:
+			return -1
+		case n > a:
+			return 1
+		}
+		return 0
+	}
+	return incomparable
+}
+
+// matchSubstrings returns true if s, which must be in lower case, matches
+// the initial, any and final parts of a substring filter.
+func matchSubstrings(s string, subs [][]byte) bool {
+	first, last := strings.ToLower(string(subs[0])), strings.ToLower(string(subs[len(subs)-1]))
+	if !strings.HasPrefix(s, first) {
+		return false
+	}
+	s = s[len(first):]
+	for _, sub := range subs[1 : len(subs)-1] {
+		i := strings.Index(s, strings.ToLower(string(sub)))
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(sub):]
+	}
+	return strings.HasSuffix(s, last)
+}
+
+// toInt64 converts an integer value to an int64.
+func toInt64(v interface{}) (int64, bool) {
+	switch x := v.(type) {
+	case int:
+		return int64(x), true
+	case int32:
+		return int64(x), true
+	case int64:
+		return x, true
+	case uint32:
+		return int64(x), true
+	}
+	return 0, false
+}
+
+// equalValues returns true if two attribute values are equal. Strings are
+// compared case-insensitively.
+func equalValues(a, b interface{}) bool {
+	if x, ok := a.(string); ok {
+		y, ok := b.(string)
+		return ok && strings.EqualFold(x, y)
+	}
+	if x, ok := toInt64(a); ok {
+		y, ok := toInt64(b)
+		return ok && x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
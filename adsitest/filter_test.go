@@ -0,0 +1,157 @@
+package adsitest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		filter string
+		valid  bool
+	}{
+		{"(cn=Jane Doe)", true},
+		{"  (cn=Jane Doe)  ", true},
+		{"(&(objectClass=user)(cn=j*))", true},
+		{"(|(cn=a)(cn=b)(cn=c))", true},
+		{"(!(cn=a))", true},
+		{"(&(!(cn=a))(|(sn=b)(sn=c)))", true},
+		{"(whenChanged>=20240101000000.0Z)", true},
+		{"(uSNChanged<=100)", true},
+		{"(cn~=jane)", true},
+		{"(mail=*)", true},
+		{"(cn=*a*b*)", true},
+		{"(userAccountControl:1.2.840.113556.1.4.803:=2)", true},
+		{"(memberOf:1.2.840.113556.1.4.1941:=CN=Staff,DC=example,DC=com)", true},
+		{`(cn=a\2ab)`, true},
+		{"(cn=)", true},
+		{"", false},
+		{"cn=a", false},
+		{"()", false},
+		{"(cn=a", false},
+		{"(cn=a))", false},
+		{"(cn=a)(cn=b)", false},
+		{"(=a)", false},
+		{"(cn)", false},
+		{"(>=a)", false},
+		{"(&(cn=a)", false},
+		{"(!cn=a)", false},
+		{"(cn:=a)", false},
+		{"(cn:1.2:3:=a)", false},
+		{`(cn=a\2)`, false},
+		{`(cn=a\zz)`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			f, err := ParseFilter(tt.filter)
+			switch {
+			case tt.valid && err != nil:
+				t.Errorf("ParseFilter(%q) returned %v", tt.filter, err)
+			case !tt.valid && err != ErrInvalidFilter:
+				t.Errorf("ParseFilter(%q) = %v, %v, want ErrInvalidFilter", tt.filter, f, err)
+			}
+		})
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	attrs := Attributes{
+		"objectClass":            {"top", "person", "organizationalPerson", "user"},
+		"objectCategory":         {"CN=Person,CN=Schema,CN=Configuration,DC=example,DC=com"},
+		"cn":                     {"Jane Doe"},
+		"sAMAccountName":         {"jdoe"},
+		"userAccountControl":     {int32(0x202)},
+		"uSNChanged":             {int64(12345)},
+		"badPwdCount":            {0},
+		"isCriticalSystemObject": {false},
+		"whenChanged":            {time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+		"objectSid":              {[]byte{1, 2, 3}},
+		"manager":                {"CN=Boss,OU=Staff,DC=example,DC=com"},
+	}
+	tests := []struct {
+		filter string
+		want   bool
+	}{
+		{"(cn=Jane Doe)", true},
+		{"(CN=jane doe)", true},
+		{"(cn=John Doe)", false},
+		{"(cn=*)", true},
+		{"(mail=*)", false},
+		{"(objectClass=*)", true},
+		{"(objectClass=USER)", true},
+		{"(objectCategory=person)", true},
+		{"(objectCategory=computer)", false},
+		{"(objectCategory=CN=Person,CN=Schema,CN=Configuration,DC=example,DC=com)", true},
+		{"(cn=jane*)", true},
+		{"(cn=*doe)", true},
+		{"(cn=*ne d*)", true},
+		{"(cn=j*e*e)", true},
+		{"(cn=jane*jane)", false},
+		{"(cn=d*)", false},
+		{"(userAccountControl=514)", true},
+		{"(userAccountControl>=514)", true},
+		{"(userAccountControl>=515)", false},
+		{"(userAccountControl<=514)", true},
+		{"(userAccountControl<=513)", false},
+		{"(userAccountControl=abc)", false},
+		{"(userAccountControl<=abc)", false},
+		{"(userAccountControl:1.2.840.113556.1.4.803:=2)", true},
+		{"(userAccountControl:1.2.840.113556.1.4.803:=514)", true},
+		{"(userAccountControl:1.2.840.113556.1.4.803:=3)", false},
+		{"(userAccountControl:1.2.840.113556.1.4.804:=3)", true},
+		{"(userAccountControl:1.2.840.113556.1.4.804:=1)", false},
+		{"(userAccountControl:1.2.3:=2)", false},
+		{"(uSNChanged>=12000)", true},
+		{"(badPwdCount=0)", true},
+		{"(isCriticalSystemObject=FALSE)", true},
+		{"(isCriticalSystemObject=TRUE)", false},
+		{"(whenChanged>=20240101000000.0Z)", true},
+		{"(whenChanged<=20240101000000.0Z)", false},
+		{"(whenChanged>=garbage)", false},
+		{`(objectSid=\01\02\03)`, true},
+		{`(objectSid=\01\02)`, false},
+		{`(cn=jane\20doe)`, true},
+		{"(manager:1.2.840.113556.1.4.1941:=cn=boss,ou=staff,dc=example,dc=com)", true},
+		{"(&(objectClass=user)(sAMAccountName=jdoe))", true},
+		{"(&(objectClass=user)(sAMAccountName=other))", false},
+		{"(|(sAMAccountName=other)(cn=jane*))", true},
+		{"(|(sAMAccountName=other)(cn=john*))", false},
+		{"(!(cn=Jane Doe))", false},
+		{"(!(mail=*))", true},
+		{"(&)", true},
+		{"(|)", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			f, err := ParseFilter(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := f.Match(attrs); got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEqualValues(t *testing.T) {
+	tests := []struct {
+		a, b interface{}
+		want bool
+	}{
+		{"Jane", "jane", true},
+		{"Jane", "John", false},
+		{"1", 1, false},
+		{int32(5), int64(5), true},
+		{5, uint32(5), true},
+		{int32(5), 6, false},
+		{[]byte{1, 2}, []byte{1, 2}, true},
+		{[]byte{1, 2}, []byte{2, 1}, false},
+		{true, true, true},
+	}
+	for _, tt := range tests {
+		if got := equalValues(tt.a, tt.b); got != tt.want {
+			t.Errorf("equalValues(%#v, %#v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
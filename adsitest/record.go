@@ -0,0 +1,443 @@
+package adsitest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-adsi/adsi"
+)
+
+var (
+	_ adsi.Directory = (*Recorder)(nil)
+	_ adsi.Directory = (*Replayer)(nil)
+	_ adsi.Entry     = (*recordedEntry)(nil)
+	_ adsi.Entry     = (*replayedEntry)(nil)
+)
+
+// ErrNotRecorded is returned by a Replayer when a call was not made while
+// the recording was captured.
+var ErrNotRecorded = errors.New("adsitest: call not recorded")
+
+// A recording holds the calls captured by a Recorder, in the order they were
+// made. It is stored as JSON so that golden files can be reviewed and
+// edited.
+type recording struct {
+	Calls []*call `json:"calls"`
+}
+
+// call is a single recorded call and its result.
+type call struct {
+	Op         string           `json:"op"`
+	Path       string           `json:"path"`
+	Filter     string           `json:"filter,omitempty"`
+	Scope      adsi.SearchScope `json:"scope,omitempty"`
+	Attributes []string         `json:"attributes,omitempty"`
+	Name       string           `json:"name,omitempty"`
+	Code       uint32           `json:"code,omitempty"`
+	Values     []*value         `json:"values,omitempty"`
+	Rows       [][]*column      `json:"rows,omitempty"`
+	Result     string           `json:"result,omitempty"`
+	Err        *recordedError   `json:"error,omitempty"`
+}
+
+// key identifies the calls that are interchangeable when replaying.
+func (c *call) key() string {
+	parts := []string{c.Op, strings.ToLower(c.Path), c.Name}
+	if c.Op == "Find" {
+		parts = append(parts, c.Filter, fmt.Sprint(c.Scope), strings.ToLower(strings.Join(c.Attributes, ",")))
+	}
+	return strings.Join(parts, "\x00")
+}
+
+type column struct {
+	Name   string   `json:"name"`
+	Type   uint32   `json:"type,omitempty"`
+	Values []*value `json:"values"`
+}
+
+// value holds an attribute value with its type, so that it can be restored
+// as the same Go type. Exactly one field is set.
+type value struct {
+	String *string    `json:"string,omitempty"`
+	Bytes  *[]byte    `json:"bytes,omitempty"`
+	Int    *int       `json:"int,omitempty"`
+	Int32  *int32     `json:"int32,omitempty"`
+	Int64  *int64     `json:"int64,omitempty"`
+	Bool   *bool      `json:"bool,omitempty"`
+	Time   *time.Time `json:"time,omitempty"`
+}
+
+type recordedError struct {
+	Op      string `json:"op,omitempty"`
+	Path    string `json:"path,omitempty"`
+	HRESULT uint32 `json:"hresult,omitempty"`
+	Message string `json:"message,omitempty"`
+	Closed  bool   `json:"closed,omitempty"`
+}
+
+// encodeValues converts attribute values for recording. Values of types that
+// cannot be recorded, such as COM interfaces, are omitted.
+func encodeValues(values []interface{}) []*value {
+	encoded := make([]*value, 0, len(values))
+	for _, v := range values {
+		switch x := v.(type) {
+		case string:
+			encoded = append(encoded, &value{String: &x})
+		case []byte:
+			encoded = append(encoded, &value{Bytes: &x})
+		case int:
+			encoded = append(encoded, &value{Int: &x})
+		case int32:
+			encoded = append(encoded, &value{Int32: &x})
+		case int64:
+			encoded = append(encoded, &value{Int64: &x})
+		case bool:
+			encoded = append(encoded, &value{Bool: &x})
+		case time.Time:
+			encoded = append(encoded, &value{Time: &x})
+		}
+	}
+	return encoded
+}
+
+// decodeValues restores recorded attribute values.
+func decodeValues(encoded []*value) []interface{} {
+	values := make([]interface{}, 0, len(encoded))
+	for _, v := range encoded {
+		switch {
+		case v.String != nil:
+			values = append(values, *v.String)
+		case v.Bytes != nil:
+			values = append(values, *v.Bytes)
+		case v.Int != nil:
+			values = append(values, *v.Int)
+		case v.Int32 != nil:
+			values = append(values, *v.Int32)
+		case v.Int64 != nil:
+			values = append(values, *v.Int64)
+		case v.Bool != nil:
+			values = append(values, *v.Bool)
+		case v.Time != nil:
+			values = append(values, *v.Time)
+		}
+	}
+	return values
+}
+
+// encodeError converts an error for recording.
+func encodeError(err error) *recordedError {
+	if err == nil {
+		return nil
+	}
+	if err == adsi.ErrClosed {
+		return &recordedError{Message: err.Error(), Closed: true}
+	}
+	var e *adsi.Error
+	if errors.As(err, &e) {
+		r := &recordedError{Op: e.Op, Path: e.Path, HRESULT: e.HRESULT}
+		if e.Err != nil {
+			r.Message = e.Err.Error()
+		}
+		return r
+	}
+	return &recordedError{Message: err.Error()}
+}
+
+// decodeError restores a recorded error. Errors that came from ADSI are
+// restored as an *adsi.Error with the same HRESULT, so that they can be
+// matched with errors.Is and functions such as adsi.IsNotFound.
+func decodeError(r *recordedError) error {
+	switch {
+	case r == nil:
+		return nil
+	case r.Closed:
+		return adsi.ErrClosed
+	case r.Op != "" || r.HRESULT != 0:
+		e := &adsi.Error{Op: r.Op, Path: r.Path, HRESULT: r.HRESULT}
+		if r.Message != "" {
+			e.Err = errors.New(r.Message)
+		}
+		return e
+	}
+	return errors.New(r.Message)
+}
+
+// Recorder is an adsi.Directory that passes calls to another directory,
+// typically an *adsi.Client connected to a real domain, and records the
+// paths, filters, attribute values and errors involved. The recording can be
+// saved as a golden file and served back by a Replayer, so that tests
+// written against a real domain can later run without one.
+//
+// The rows of a search are read in full when Find is called. Attribute
+// values that are COM interfaces are passed through but not recorded.
+type Recorder struct {
+	d adsi.Directory
+
+	m   sync.Mutex
+	rec recording
+}
+
+// Record returns a recorder for the given directory.
+func Record(d adsi.Directory) *Recorder {
+	return &Recorder{d: d}
+}
+
+// add records a call.
+func (r *Recorder) add(c *call) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.rec.Calls = append(r.rec.Calls, c)
+}
+
+// Bind opens the object with the given path and records the outcome.
+func (r *Recorder) Bind(path string) (adsi.Entry, error) {
+	entry, err := r.d.Bind(path)
+	r.add(&call{Op: "Bind", Path: path, Err: encodeError(err)})
+	if err != nil {
+		return nil, err
+	}
+	return &recordedEntry{r: r, path: path, e: entry}, nil
+}
+
+// Find performs the search, reads and records all of its rows and returns
+// them.
+func (r *Recorder) Find(path string, q adsi.Query) (adsi.RowIterator, error) {
+	c := &call{Op: "Find", Path: path, Filter: q.Filter, Scope: q.Scope, Attributes: q.Attributes}
+	defer r.add(c)
+	iter, err := r.d.Find(path, q)
+	if err != nil {
+		c.Err = encodeError(err)
+		return nil, err
+	}
+	defer iter.Close()
+	var rows []*adsi.Row
+	for {
+		row, err := iter.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			c.Err = encodeError(err)
+			break
+		}
+		rows = append(rows, row)
+		var cols []*column
+		for _, col := range row.Columns() {
+			cols = append(cols, &column{Name: col.Name, Type: col.Type, Values: encodeValues(col.Values)})
+		}
+		c.Rows = append(c.Rows, cols)
+	}
+	return &Rows{rows: rows, err: decodeError(c.Err)}, nil
+}
+
+// Save writes the recording to w as JSON.
+func (r *Recorder) Save(w io.Writer) error {
+	r.m.Lock()
+	defer r.m.Unlock()
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(&r.rec)
+}
+
+// WriteFile saves the recording to the named file, creating or truncating
+// it.
+func (r *Recorder) WriteFile(name string) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err = r.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// recordedEntry records the calls made on an entry opened by a Recorder.
+type recordedEntry struct {
+	r    *Recorder
+	path string
+	e    adsi.Entry
+}
+
+func (e *recordedEntry) str(op string, fn func() (string, error)) (string, error) {
+	s, err := fn()
+	e.r.add(&call{Op: op, Path: e.path, Result: s, Err: encodeError(err)})
+	return s, err
+}
+
+func (e *recordedEntry) Name() (string, error)  { return e.str("Name", e.e.Name) }
+func (e *recordedEntry) Class() (string, error) { return e.str("Class", e.e.Class) }
+func (e *recordedEntry) Path() (string, error)  { return e.str("Path", e.e.Path) }
+
+func (e *recordedEntry) Attr(name string) ([]interface{}, error) {
+	values, err := e.e.Attr(name)
+	e.r.add(&call{Op: "Attr", Path: e.path, Name: name, Values: encodeValues(values), Err: encodeError(err)})
+	return values, err
+}
+
+func (e *recordedEntry) PutEx(controlCode uint32, name string, values ...interface{}) error {
+	err := e.e.PutEx(controlCode, name, values...)
+	e.r.add(&call{Op: "PutEx", Path: e.path, Name: name, Code: controlCode, Values: encodeValues(values), Err: encodeError(err)})
+	return err
+}
+
+func (e *recordedEntry) SetInfo() error {
+	err := e.e.SetInfo()
+	e.r.add(&call{Op: "SetInfo", Path: e.path, Err: encodeError(err)})
+	return err
+}
+
+func (e *recordedEntry) Close() error {
+	return e.e.Close()
+}
+
+// Replayer is an adsi.Directory that serves the results of a recording made
+// by a Recorder. Calls are matched by their operation and arguments, so the
+// code under test may make them in a different order than when they were
+// recorded, but each recorded call is served once. A call that was not
+// recorded, or that is made more often than it was recorded, returns an
+// error matching ErrNotRecorded.
+type Replayer struct {
+	m     sync.Mutex
+	calls map[string][]*call
+}
+
+// Replay returns a replayer for the recording read from rd.
+func Replay(rd io.Reader) (*Replayer, error) {
+	var rec recording
+	if err := json.NewDecoder(rd).Decode(&rec); err != nil {
+		return nil, fmt.Errorf("adsitest: unable to read recording: %v", err)
+	}
+	r := &Replayer{calls: make(map[string][]*call)}
+	for _, c := range rec.Calls {
+		key := c.key()
+		r.calls[key] = append(r.calls[key], c)
+	}
+	return r, nil
+}
+
+// ReadFile returns a replayer for the recording saved in the named file.
+func ReadFile(name string) (*Replayer, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Replay(f)
+}
+
+// next returns the next recorded call matching c.
+func (r *Replayer) next(c *call) (*call, error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	key := c.key()
+	calls := r.calls[key]
+	if len(calls) == 0 {
+		return nil, fmt.Errorf("%w: %s %s", ErrNotRecorded, c.Op, c.Path)
+	}
+	r.calls[key] = calls[1:]
+	return calls[0], nil
+}
+
+// Bind returns the recorded outcome of opening the object with the given
+// path.
+func (r *Replayer) Bind(path string) (adsi.Entry, error) {
+	c, err := r.next(&call{Op: "Bind", Path: path})
+	if err != nil {
+		return nil, err
+	}
+	if err = decodeError(c.Err); err != nil {
+		return nil, err
+	}
+	return &replayedEntry{r: r, path: path}, nil
+}
+
+// Find returns the recorded rows of the search.
+func (r *Replayer) Find(path string, q adsi.Query) (adsi.RowIterator, error) {
+	c, err := r.next(&call{Op: "Find", Path: path, Filter: q.Filter, Scope: q.Scope, Attributes: q.Attributes})
+	if err != nil {
+		return nil, err
+	}
+	if c.Rows == nil && c.Err != nil {
+		return nil, decodeError(c.Err)
+	}
+	rows := make([]*adsi.Row, len(c.Rows))
+	for i, cols := range c.Rows {
+		columns := make([]adsi.Column, len(cols))
+		for j, col := range cols {
+			columns[j] = adsi.Column{Name: col.Name, Type: col.Type, Values: decodeValues(col.Values)}
+		}
+		rows[i] = adsi.NewRow(columns...)
+	}
+	return &Rows{rows: rows, err: decodeError(c.Err)}, nil
+}
+
+// replayedEntry serves the recorded results of calls on an entry.
+type replayedEntry struct {
+	r      *Replayer
+	path   string
+	closed bool
+}
+
+func (e *replayedEntry) str(op string) (string, error) {
+	if e.closed {
+		return "", adsi.ErrClosed
+	}
+	c, err := e.r.next(&call{Op: op, Path: e.path})
+	if err != nil {
+		return "", err
+	}
+	return c.Result, decodeError(c.Err)
+}
+
+func (e *replayedEntry) Name() (string, error)  { return e.str("Name") }
+func (e *replayedEntry) Class() (string, error) { return e.str("Class") }
+func (e *replayedEntry) Path() (string, error)  { return e.str("Path") }
+
+func (e *replayedEntry) Attr(name string) ([]interface{}, error) {
+	if e.closed {
+		return nil, adsi.ErrClosed
+	}
+	c, err := e.r.next(&call{Op: "Attr", Path: e.path, Name: name})
+	if err != nil {
+		return nil, err
+	}
+	if err = decodeError(c.Err); err != nil {
+		return nil, err
+	}
+	return decodeValues(c.Values), nil
+}
+
+func (e *replayedEntry) PutEx(controlCode uint32, name string, values ...interface{}) error {
+	if e.closed {
+		return adsi.ErrClosed
+	}
+	c, err := e.r.next(&call{Op: "PutEx", Path: e.path, Name: name})
+	if err != nil {
+		return err
+	}
+	return decodeError(c.Err)
+}
+
+func (e *replayedEntry) SetInfo() error {
+	if e.closed {
+		return adsi.ErrClosed
+	}
+	c, err := e.r.next(&call{Op: "SetInfo", Path: e.path})
+	if err != nil {
+		return err
+	}
+	return decodeError(c.Err)
+}
+
+func (e *replayedEntry) Close() error {
+	e.closed = true
+	return nil
+}
@@ -0,0 +1,225 @@
+package adsitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-adsi/adsi"
+	"github.com/go-adsi/adsi/api"
+)
+
+func TestValuesRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []interface{}
+		want []interface{}
+	}{
+		{"empty", []interface{}{}, []interface{}{}},
+		{"string", []interface{}{"Jane Doe", ""}, []interface{}{"Jane Doe", ""}},
+		{"bytes", []interface{}{[]byte{1, 5, 0, 0xff}}, []interface{}{[]byte{1, 5, 0, 0xff}}},
+		{"int", []interface{}{0, -7}, []interface{}{0, -7}},
+		{"int32", []interface{}{int32(0x202)}, []interface{}{int32(0x202)}},
+		{"int64", []interface{}{int64(1) << 40}, []interface{}{int64(1) << 40}},
+		{"bool", []interface{}{true, false}, []interface{}{true, false}},
+		{"time", []interface{}{time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)}, []interface{}{time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)}},
+		{"mixed", []interface{}{"a", int32(1), true}, []interface{}{"a", int32(1), true}},
+		{"unsupported", []interface{}{"a", struct{}{}, 1.5, "b"}, []interface{}{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(encodeValues(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var encoded []*value
+			if err = json.Unmarshal(data, &encoded); err != nil {
+				t.Fatal(err)
+			}
+			if got := decodeValues(encoded); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("values = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorRoundTrip(t *testing.T) {
+	notFound := &adsi.Error{Op: "Open", Path: "LDAP://CN=x", HRESULT: adsi.ErrNoSuchObject.HRESULT}
+	tests := []struct {
+		name string
+		in   error
+		want error
+	}{
+		{"nil", nil, nil},
+		{"closed", adsi.ErrClosed, adsi.ErrClosed},
+		{"hresult", notFound, notFound},
+		{"wrapped", &adsi.Error{Op: "GetEx cn", HRESULT: api.E_ADS_PROPERTY_NOT_FOUND, Err: api.ErrPropertyNotFound},
+			&adsi.Error{Op: "GetEx cn", HRESULT: api.E_ADS_PROPERTY_NOT_FOUND, Err: errors.New(api.ErrPropertyNotFound.Error())}},
+		{"plain", errors.New("boom"), errors.New("boom")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(encodeError(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var encoded *recordedError
+			if err = json.Unmarshal(data, &encoded); err != nil {
+				t.Fatal(err)
+			}
+			if got := decodeError(encoded); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("error = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+	if err := decodeError(encodeError(notFound)); !adsi.IsNotFound(err) {
+		t.Errorf("IsNotFound(%v) = false after replay", err)
+	}
+}
+
+// record runs fn against a directory through a recorder and returns the
+// recording.
+func record(t *testing.T, fn func(d adsi.Directory)) *Replayer {
+	t.Helper()
+	d := New()
+	d.Add("DC=example,DC=com", Attributes{"objectClass": {"domain"}})
+	d.Add("CN=Jane Doe,DC=example,DC=com", Attributes{
+		"objectClass":        {"top", "user"},
+		"sAMAccountName":     {"jdoe"},
+		"userAccountControl": {int32(512)},
+	})
+	r := Record(d)
+	fn(r)
+	var buf bytes.Buffer
+	if err := r.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	replayer, err := Replay(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return replayer
+}
+
+func readRows(t *testing.T, iter adsi.RowIterator) (rows []*adsi.Row) {
+	t.Helper()
+	defer iter.Close()
+	for {
+		row, err := iter.Next()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows = append(rows, row)
+	}
+}
+
+func TestRecordReplay(t *testing.T) {
+	const (
+		base = "LDAP://DC=example,DC=com"
+		user = "LDAP://CN=Jane Doe,DC=example,DC=com"
+	)
+	q := adsi.Query{Filter: "(sAMAccountName=jdoe)", Attributes: []string{"sAMAccountName", "userAccountControl"}, Scope: adsi.ScopeSubtree}
+	calls := func(d adsi.Directory) (rows []*adsi.Row, attr []interface{}, bindErr error) {
+		iter, err := d.Find(base, q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows = readRows(t, iter)
+		entry, err := d.Bind(user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer entry.Close()
+		if attr, err = entry.Attr("sAMAccountName"); err != nil {
+			t.Fatal(err)
+		}
+		if err = entry.PutEx(api.ADS_PROPERTY_UPDATE, "description", "x"); err != nil {
+			t.Fatal(err)
+		}
+		if err = entry.SetInfo(); err != nil {
+			t.Fatal(err)
+		}
+		_, bindErr = d.Bind("LDAP://CN=Missing,DC=example,DC=com")
+		return
+	}
+
+	var wantRows []*adsi.Row
+	var wantAttr []interface{}
+	replayer := record(t, func(d adsi.Directory) {
+		var err error
+		if wantRows, wantAttr, err = calls(d); !adsi.IsNotFound(err) {
+			t.Fatalf("Bind of a missing object returned %v", err)
+		}
+	})
+
+	rows, attr, err := calls(replayer)
+	if !adsi.IsNotFound(err) {
+		t.Errorf("replayed Bind of a missing object returned %v", err)
+	}
+	if len(rows) != len(wantRows) {
+		t.Fatalf("replayed %d rows, want %d", len(rows), len(wantRows))
+	}
+	for i := range rows {
+		if got, want := rows[i].Columns(), wantRows[i].Columns(); !reflect.DeepEqual(got, want) {
+			t.Errorf("row %d = %#v, want %#v", i, got, want)
+		}
+	}
+	if !reflect.DeepEqual(attr, wantAttr) {
+		t.Errorf("replayed Attr = %#v, want %#v", attr, wantAttr)
+	}
+
+	// Each recorded call is served once
+	if _, err := replayer.Find(base, q); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("second replayed Find returned %v, want ErrNotRecorded", err)
+	}
+}
+
+func TestReplayerUnrecorded(t *testing.T) {
+	replayer := record(t, func(d adsi.Directory) {})
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"bind", func() error { _, err := replayer.Bind("LDAP://DC=example,DC=com"); return err }},
+		{"find", func() error {
+			_, err := replayer.Find("LDAP://DC=example,DC=com", adsi.Query{Filter: "(cn=*)"})
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, ErrNotRecorded) {
+				t.Errorf("error = %v, want ErrNotRecorded", err)
+			}
+		})
+	}
+}
+
+func TestReplayFindMatchesQuery(t *testing.T) {
+	const base = "LDAP://DC=example,DC=com"
+	replayer := record(t, func(d adsi.Directory) {
+		iter, err := d.Find(base, adsi.Query{Filter: "(objectClass=user)", Scope: adsi.ScopeSubtree})
+		if err != nil {
+			t.Fatal(err)
+		}
+		readRows(t, iter)
+	})
+	if _, err := replayer.Find(base, adsi.Query{Filter: "(objectClass=user)", Scope: adsi.ScopeOneLevel}); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("Find with another scope returned %v, want ErrNotRecorded", err)
+	}
+	// Paths are matched case-insensitively
+	iter, err := replayer.Find("ldap://dc=EXAMPLE,dc=com", adsi.Query{Filter: "(objectClass=user)", Scope: adsi.ScopeSubtree})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows := readRows(t, iter); len(rows) != 1 {
+		t.Errorf("replayed %d rows, want 1", len(rows))
+	}
+}
@@ -67,8 +67,9 @@ func Parse(rawpath string) (path *Path, err error) {
 	rest = rest[2:]
 
 	authority, rest := split(rest, "/")
-	if rest == "" && path.Scheme == LDAP && strings.ContainsRune(authority, '=') {
-		// This is serverless LDAP binding
+	if rest == "" && (path.Scheme == LDAP || path.Scheme == GC) && (strings.ContainsRune(authority, '=') || strings.HasPrefix(authority, "<")) {
+		// This is serverless LDAP or global catalog binding, to a
+		// distinguished name or to an object identified as in "<SID=...>"
 		rest = authority
 		authority = ""
 	}
@@ -138,3 +139,26 @@ func (p *Path) String() string {
 	buf.WriteString(p.Path)
 	return buf.String()
 }
+
+// EscapeDN escapes the forward slashes in a distinguished name so that it can
+// be used as the path component of an LDAP or GC ADsPath.
+//
+// See https://msdn.microsoft.com/library/aa746384
+func EscapeDN(dn string) string {
+	var buf bytes.Buffer
+	for i := 0; i < len(dn); i++ {
+		c := dn[i]
+		switch {
+		case c == '\\' && i+1 < len(dn):
+			// Preserve existing escape sequences
+			buf.WriteByte(c)
+			buf.WriteByte(dn[i+1])
+			i++
+		case c == '/':
+			buf.WriteString(`\/`)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return buf.String()
+}
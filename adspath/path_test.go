@@ -0,0 +1,63 @@
+package adspath
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Path
+	}{
+		{"LDAP:", Path{Scheme: LDAP}},
+		{"ldap://dc1.example.com/DC=example,DC=com", Path{Scheme: LDAP, Host: "dc1.example.com", Path: "DC=example,DC=com"}},
+		{"LDAP://dc1.example.com:636/CN=Users,DC=example,DC=com", Path{Scheme: LDAP, Host: "dc1.example.com:636", Path: "CN=Users,DC=example,DC=com"}},
+		{"LDAP://DC=example,DC=com", Path{Scheme: LDAP, Path: "DC=example,DC=com"}},
+		{"LDAP://<SID=S-1-5-21-1-2-3-1104>", Path{Scheme: LDAP, Path: "<SID=S-1-5-21-1-2-3-1104>"}},
+		{"LDAP://RootDSE", Path{Scheme: LDAP, Host: "RootDSE"}},
+		{"LDAP://dc1/RootDSE", Path{Scheme: LDAP, Host: "dc1", Path: "RootDSE"}},
+		{"gc://example.com/DC=example,DC=com", Path{Scheme: GC, Host: "example.com", Path: "DC=example,DC=com"}},
+		{"GC://DC=example,DC=com", Path{Scheme: GC, Path: "DC=example,DC=com"}},
+		{"GC://<SID=S-1-5-21-1-2-3-1104>", Path{Scheme: GC, Path: "<SID=S-1-5-21-1-2-3-1104>"}},
+		{"GC://example.com", Path{Scheme: GC, Host: "example.com"}},
+		{"winnt://WORKGROUP/host", Path{Scheme: WinNT, Host: "WORKGROUP", Path: "host"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != tt.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.in, *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, in := range []string{"", ":foo", "LDAP:DC=example"} {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) succeeded", in)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		in   Path
+		want string
+	}{
+		{Path{Scheme: LDAP}, "LDAP:"},
+		{Path{Scheme: LDAP, Path: "DC=example,DC=com"}, "LDAP://DC=example,DC=com"},
+		{Path{Scheme: GC, Path: "DC=example,DC=com"}, "GC://DC=example,DC=com"},
+		{Path{Scheme: LDAP, Host: "dc1", Path: "DC=example,DC=com"}, "LDAP://dc1/DC=example,DC=com"},
+		{Path{Scheme: GC, Host: "example.com"}, "GC://example.com"},
+	}
+	for _, tt := range tests {
+		if got := tt.in.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+		if p, err := Parse(tt.want); err != nil || *p != tt.in {
+			t.Errorf("Parse(%q) = %+v, %v, want %+v", tt.want, p, err, tt.in)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package adsi
+
+import (
+	"strconv"
+
+	ole "github.com/go-ole/go-ole"
+)
+
+// Apartment identifies the COM apartment model used by the workers of a
+// client.
+type Apartment int
+
+// Apartment models. The zero value is ApartmentMTA.
+const (
+	// ApartmentMTA places the workers of a client in the multithreaded
+	// apartment of the process, which is kept initialized by comshim for as
+	// long as the client is open. Interfaces created by the workers may be
+	// used from any thread, including those returned by OpenDispatch and
+	// OpenInterface. It is the best choice for headless services.
+	ApartmentMTA Apartment = iota
+
+	// ApartmentSTA gives each worker of a client a single-threaded apartment
+	// of its own. Interfaces created by a worker must only be used by that
+	// worker, so the interfaces returned by OpenDispatch and OpenInterface
+	// and the IDispatch values returned by Attr must not be used outside of
+	// the object that returned them. It avoids joining the multithreaded
+	// apartment in hosts that require single-threaded apartments, such as
+	// GUI applications.
+	ApartmentSTA
+)
+
+// String returns the name of the apartment model.
+func (a Apartment) String() string {
+	switch a {
+	case ApartmentMTA:
+		return "MTA"
+	case ApartmentSTA:
+		return "STA"
+	default:
+		return "Apartment(" + strconv.Itoa(int(a)) + ")"
+	}
+}
+
+// coinit returns the concurrency model flag passed to CoInitializeEx by the
+// workers of the apartment model.
+func (a Apartment) coinit() uint32 {
+	if a == ApartmentSTA {
+		return ole.COINIT_APARTMENTTHREADED
+	}
+	return ole.COINIT_MULTITHREADED
+}
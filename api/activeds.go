@@ -0,0 +1,12 @@
+package api
+
+// ExtendedError holds the extended error information recorded by an ADSI
+// provider for the calling thread. For the LDAP provider Code is the Win32
+// error code and Message is the diagnostic message returned by the server,
+// such as "00002098: SecErr: DSID-03150F94, problem 4003
+// (INSUFF_ACCESS_RIGHTS), data 0".
+type ExtendedError struct {
+	Code     uint32
+	Message  string
+	Provider string
+}
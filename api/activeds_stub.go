@@ -0,0 +1,16 @@
+//go:build !windows
+// +build !windows
+
+package api
+
+// ADsGetLastError returns the extended error information recorded by an
+// ADSI provider for the calling thread. It must be called on the same
+// thread as the call that failed.
+func ADsGetLastError() (e ExtendedError, err error) {
+	return e, ErrUnsupported
+}
+
+// ADsSetLastError sets the extended error information of the calling
+// thread. Calling it with a zero code and empty strings clears it.
+func ADsSetLastError(code uint32, message, provider string) {
+}
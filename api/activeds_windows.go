@@ -0,0 +1,65 @@
+//go:build windows
+// +build windows
+
+package api
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var (
+	modactiveds = syscall.NewLazyDLL("activeds.dll")
+
+	procFreeADsMem      = modactiveds.NewProc("FreeADsMem")
+	procADsGetLastError = modactiveds.NewProc("ADsGetLastError")
+	procADsSetLastError = modactiveds.NewProc("ADsSetLastError")
+)
+
+// extendedErrorLength is the length in characters of the buffers that
+// receive extended error messages and provider names.
+const extendedErrorLength = 1024
+
+// freeADsMem releases memory allocated by ADSI, such as the column names
+// returned by IDirectorySearch.GetNextColumnName.
+func freeADsMem(p unsafe.Pointer) {
+	if p != nil {
+		procFreeADsMem.Call(uintptr(p))
+	}
+}
+
+// ADsGetLastError returns the extended error information recorded by an
+// ADSI provider for the calling thread. It must be called on the same
+// thread as the call that failed.
+func ADsGetLastError() (e ExtendedError, err error) {
+	message := make([]uint16, extendedErrorLength)
+	provider := make([]uint16, extendedErrorLength)
+	hr, _, _ := procADsGetLastError.Call(
+		uintptr(unsafe.Pointer(&e.Code)),
+		uintptr(unsafe.Pointer(&message[0])),
+		uintptr(len(message)),
+		uintptr(unsafe.Pointer(&provider[0])),
+		uintptr(len(provider)))
+	if err = convertHresultToError(hr); err != nil {
+		return
+	}
+	e.Message = syscall.UTF16ToString(message)
+	e.Provider = syscall.UTF16ToString(provider)
+	return
+}
+
+// ADsSetLastError sets the extended error information of the calling
+// thread. Calling it with a zero code and empty strings clears it.
+func ADsSetLastError(code uint32, message, provider string) {
+	var pMessage, pProvider *uint16
+	if message != "" {
+		pMessage, _ = syscall.UTF16PtrFromString(message)
+	}
+	if provider != "" {
+		pProvider, _ = syscall.UTF16PtrFromString(provider)
+	}
+	procADsSetLastError.Call(
+		uintptr(code),
+		uintptr(unsafe.Pointer(pMessage)),
+		uintptr(unsafe.Pointer(pProvider)))
+}
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"errors"
+	"time"
+	"unsafe"
+)
+
+// The ADSTYPEENUM enumeration specifies the data types used to interpret
+// ADSVALUE structures.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/ne-iads-adstypeenum
+const (
+	ADSTYPE_INVALID uint32 = iota
+	ADSTYPE_DN_STRING
+	ADSTYPE_CASE_EXACT_STRING
+	ADSTYPE_CASE_IGNORE_STRING
+	ADSTYPE_PRINTABLE_STRING
+	ADSTYPE_NUMERIC_STRING
+	ADSTYPE_BOOLEAN
+	ADSTYPE_INTEGER
+	ADSTYPE_OCTET_STRING
+	ADSTYPE_UTC_TIME
+	ADSTYPE_LARGE_INTEGER
+	ADSTYPE_PROV_SPECIFIC
+	ADSTYPE_OBJECT_CLASS
+	ADSTYPE_CASEIGNORE_LIST
+	ADSTYPE_OCTET_LIST
+	ADSTYPE_PATH
+	ADSTYPE_POSTALADDRESS
+	ADSTYPE_TIMESTAMP
+	ADSTYPE_BACKLINK
+	ADSTYPE_TYPEDNAME
+	ADSTYPE_HOLD
+	ADSTYPE_NETADDRESS
+	ADSTYPE_REPLICAPOINTER
+	ADSTYPE_FAXNUMBER
+	ADSTYPE_EMAIL
+	ADSTYPE_NT_SECURITY_DESCRIPTOR
+	ADSTYPE_UNKNOWN
+	ADSTYPE_DN_WITH_BINARY
+	ADSTYPE_DN_WITH_STRING
+)
+
+// ErrUnsupportedADsType is returned when an ADSVALUE holds a data type that
+// cannot be converted to a native Go type.
+var ErrUnsupportedADsType = errors.New("unsupported ADSTYPE")
+
+// ADSVALUE is the native representation of a single attribute value returned
+// by the IDirectorySearch and IDirectoryObject interfaces. The value is held
+// in a union whose interpretation depends on its type.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/ns-iads-adsvalue
+type ADSVALUE struct {
+	Type uint32
+	_    uint32
+	data [2]uint64
+}
+
+// adsOctetString mirrors the ADS_OCTET_STRING, ADS_NT_SECURITY_DESCRIPTOR and
+// ADS_PROV_SPECIFIC structures, which share the same layout.
+type adsOctetString struct {
+	Length uint32
+	Value  *byte
+}
+
+// adsDNWithBinary mirrors the ADS_DN_WITH_BINARY structure.
+type adsDNWithBinary struct {
+	Length uint32
+	Value  *byte
+	DN     *uint16
+}
+
+// adsDNWithString mirrors the ADS_DN_WITH_STRING structure.
+type adsDNWithString struct {
+	Value *uint16
+	DN    *uint16
+}
+
+// systemTime mirrors the SYSTEMTIME structure.
+type systemTime struct {
+	Year         uint16
+	Month        uint16
+	DayOfWeek    uint16
+	Day          uint16
+	Hour         uint16
+	Minute       uint16
+	Second       uint16
+	Milliseconds uint16
+}
+
+// DNWithBinary is the Go representation of an ADSTYPE_DN_WITH_BINARY value,
+// such as the values of the wellKnownObjects attribute.
+type DNWithBinary struct {
+	DN     string
+	Binary []byte
+}
+
+// DNWithString is the Go representation of an ADSTYPE_DN_WITH_STRING value.
+type DNWithString struct {
+	DN     string
+	String string
+}
+
+func (v *ADSVALUE) union() unsafe.Pointer {
+	return unsafe.Pointer(&v.data)
+}
+
+// StringValue interprets the value as a null-terminated string. It is valid
+// for the DN, case exact, case ignore, printable, numeric and object class
+// string types.
+func (v *ADSVALUE) StringValue() string {
+	return UTF16PtrToString(*(**uint16)(v.union()))
+}
+
+// IntegerValue interprets the value as a 32-bit integer. It is valid for the
+// integer and boolean types.
+func (v *ADSVALUE) IntegerValue() uint32 {
+	return *(*uint32)(v.union())
+}
+
+// LargeIntegerValue interprets the value as a 64-bit integer.
+func (v *ADSVALUE) LargeIntegerValue() int64 {
+	return *(*int64)(v.union())
+}
+
+// BytesValue interprets the value as a counted array of bytes and returns a
+// copy of them. It is valid for the octet string, security descriptor and
+// provider specific types.
+func (v *ADSVALUE) BytesValue() []byte {
+	s := (*adsOctetString)(v.union())
+	return copyBytes(s.Value, s.Length)
+}
+
+// TimeValue interprets the value as a SYSTEMTIME in UTC.
+func (v *ADSVALUE) TimeValue() time.Time {
+	st := (*systemTime)(v.union())
+	return time.Date(int(st.Year), time.Month(st.Month), int(st.Day), int(st.Hour), int(st.Minute), int(st.Second), int(st.Milliseconds)*int(time.Millisecond), time.UTC)
+}
+
+// DNWithBinaryValue interprets the value as an ADS_DN_WITH_BINARY structure.
+func (v *ADSVALUE) DNWithBinaryValue() DNWithBinary {
+	p := *(**adsDNWithBinary)(v.union())
+	if p == nil {
+		return DNWithBinary{}
+	}
+	return DNWithBinary{DN: UTF16PtrToString(p.DN), Binary: copyBytes(p.Value, p.Length)}
+}
+
+// DNWithStringValue interprets the value as an ADS_DN_WITH_STRING structure.
+func (v *ADSVALUE) DNWithStringValue() DNWithString {
+	p := *(**adsDNWithString)(v.union())
+	if p == nil {
+		return DNWithString{}
+	}
+	return DNWithString{DN: UTF16PtrToString(p.DN), String: UTF16PtrToString(p.Value)}
+}
+
+// RawData returns a copy of the data held by the value, which remains valid
+// once the memory of the value has been freed. String types are returned as
+// their UTF-16 code units in little-endian order, without the terminator.
+// Booleans and integers are returned as 4 little-endian bytes, large
+// integers as 8, and UTC times as the 16 bytes of a SYSTEMTIME structure.
+// Octet strings, security descriptors and provider specific values are
+// returned as their octets. Other types refer to structures of their own and
+// return nil.
+func (v *ADSVALUE) RawData() []byte {
+	switch v.Type {
+	case ADSTYPE_DN_STRING, ADSTYPE_CASE_EXACT_STRING, ADSTYPE_CASE_IGNORE_STRING,
+		ADSTYPE_PRINTABLE_STRING, ADSTYPE_NUMERIC_STRING, ADSTYPE_OBJECT_CLASS:
+		s := utf16Slice(*(**uint16)(v.union()))
+		return copyBytes((*byte)(unsafe.Pointer(unsafe.SliceData(s))), uint32(len(s)*2))
+	case ADSTYPE_BOOLEAN, ADSTYPE_INTEGER:
+		return copyBytes((*byte)(v.union()), 4)
+	case ADSTYPE_LARGE_INTEGER:
+		return copyBytes((*byte)(v.union()), 8)
+	case ADSTYPE_UTC_TIME:
+		return copyBytes((*byte)(v.union()), uint32(unsafe.Sizeof(systemTime{})))
+	case ADSTYPE_OCTET_STRING, ADSTYPE_NT_SECURITY_DESCRIPTOR, ADSTYPE_PROV_SPECIFIC:
+		return v.BytesValue()
+	default:
+		return nil
+	}
+}
+
+// Value converts the value to the Go type that best matches its ADSTYPE.
+//
+// String types are returned as string, booleans as bool, integers as int32,
+// large integers as int64, octet strings and security descriptors as []byte,
+// UTC times as time.Time, and DN-with types as DNWithBinary or DNWithString.
+// Other types return ErrUnsupportedADsType.
+//
+// Use a Decoder to convert many values while reusing memory.
+func (v *ADSVALUE) Value() (value interface{}, err error) {
+	var d *Decoder
+	return d.Value(v)
+}
+
+// UTF16PtrToString converts a pointer to a null-terminated UTF-16 string to a
+// Go string. A nil pointer is returned as an empty string.
+func UTF16PtrToString(p *uint16) string {
+	var d *Decoder
+	return d.String(p)
+}
+
+func copyBytes(p *byte, length uint32) []byte {
+	if p == nil || length == 0 {
+		return []byte{}
+	}
+	out := make([]byte, length)
+	copy(out, unsafe.Slice(p, length))
+	return out
+}
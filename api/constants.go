@@ -78,6 +78,45 @@ const (
 	ADS_NAME_TYPE_SID_OR_SID_HISTORY_NAME
 )
 
+// The ADS_PROPERTY_OPERATION_ENUM enumeration specifies the way that values
+// are updated in the property cache by IADs.PutEx.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/ne-iads-ads_property_operation_enum
+const (
+	ADS_PROPERTY_CLEAR uint32 = iota + 1
+	ADS_PROPERTY_UPDATE
+	ADS_PROPERTY_APPEND
+	ADS_PROPERTY_DELETE
+)
+
+// The ADS_OPTION_ENUM enumeration specifies the options of an object that
+// are read and set with IADsObjectOptions.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/ne-iads-ads_option_enum
+const (
+	ADS_OPTION_SERVERNAME uint32 = iota
+	ADS_OPTION_REFERRALS
+	ADS_OPTION_PAGE_SIZE
+	ADS_OPTION_SECURITY_MASK
+	ADS_OPTION_MUTUAL_AUTH_STATUS
+	ADS_OPTION_QUOTA
+	ADS_OPTION_PASSWORD_PORTNUMBER
+	ADS_OPTION_PASSWORD_METHOD
+	ADS_OPTION_ACCUMULATIVE_MODIFICATION
+	ADS_OPTION_SKIP_SID_LOOKUP
+)
+
+// The ADS_SECURITY_INFO_ENUM enumeration specifies the parts of a security
+// descriptor that are read and written with ADS_OPTION_SECURITY_MASK.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/ne-iads-ads_security_info_enum
+const (
+	ADS_SECURITY_INFO_OWNER = 0x1
+	ADS_SECURITY_INFO_GROUP = 0x2
+	ADS_SECURITY_INFO_DACL  = 0x4
+	ADS_SECURITY_INFO_SACL  = 0x8
+)
+
 var (
 	ErrInvalidNamespace = errors.New("The provided name or namespace is invalid.")
 	ErrAccessDenied     = errors.New("Access denied.")
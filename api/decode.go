@@ -0,0 +1,157 @@
+package api
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// Limits on the strings interned by a Decoder.
+const (
+	internMaxLength  = 64   // maximum length in bytes of an interned string
+	internMaxEntries = 4096 // maximum number of interned strings
+)
+
+// Decoder converts ADSVALUE structures and UTF-16 strings to Go values while
+// reusing memory between calls. Strings are decoded into a buffer that is
+// kept between calls, and short strings, such as attribute names and the
+// values of objectClass, are interned so that values repeated across the rows
+// of a search share a single allocation, as do the interfaces returned by
+// Value that hold them.
+//
+// A Decoder is not safe for concurrent use. The zero value is ready to use,
+// and a nil Decoder converts values without reusing any memory.
+type Decoder struct {
+	buf []byte
+
+	// interned maps each interned string to itself, held in an interface so
+	// that Value can return it without allocating
+	interned map[string]interface{}
+}
+
+// String converts a pointer to a null-terminated UTF-16 string to a Go
+// string. A nil pointer is returned as an empty string.
+func (d *Decoder) String(p *uint16) string {
+	str, _ := d.decode(utf16Slice(p))
+	return str
+}
+
+// BSTR converts a BSTR to a Go string. Unlike String it uses the length
+// prefix of the BSTR, so the string may contain null characters.
+func (d *Decoder) BSTR(p *uint16) string {
+	if p == nil {
+		return ""
+	}
+	n := *(*uint32)(unsafe.Add(unsafe.Pointer(p), -4)) / 2
+	str, _ := d.decode(unsafe.Slice(p, n))
+	return str
+}
+
+// stringValue converts a pointer to a null-terminated UTF-16 string to a Go
+// string held in an interface.
+func (d *Decoder) stringValue(p *uint16) interface{} {
+	str, boxed := d.decode(utf16Slice(p))
+	if boxed != nil {
+		return boxed
+	}
+	return str
+}
+
+// Value converts the value to the Go type that best matches its ADSTYPE, as
+// described by ADSVALUE.Value.
+func (d *Decoder) Value(v *ADSVALUE) (value interface{}, err error) {
+	switch v.Type {
+	case ADSTYPE_DN_STRING, ADSTYPE_CASE_EXACT_STRING, ADSTYPE_CASE_IGNORE_STRING,
+		ADSTYPE_PRINTABLE_STRING, ADSTYPE_NUMERIC_STRING, ADSTYPE_OBJECT_CLASS:
+		return d.stringValue(*(**uint16)(v.union())), nil
+	case ADSTYPE_BOOLEAN:
+		return v.IntegerValue() != 0, nil
+	case ADSTYPE_INTEGER:
+		return int32(v.IntegerValue()), nil
+	case ADSTYPE_LARGE_INTEGER:
+		return v.LargeIntegerValue(), nil
+	case ADSTYPE_OCTET_STRING, ADSTYPE_NT_SECURITY_DESCRIPTOR, ADSTYPE_PROV_SPECIFIC:
+		return v.BytesValue(), nil
+	case ADSTYPE_UTC_TIME:
+		return v.TimeValue(), nil
+	case ADSTYPE_DN_WITH_BINARY:
+		p := *(**adsDNWithBinary)(v.union())
+		if p == nil {
+			return DNWithBinary{}, nil
+		}
+		return DNWithBinary{DN: d.String(p.DN), Binary: copyBytes(p.Value, p.Length)}, nil
+	case ADSTYPE_DN_WITH_STRING:
+		p := *(**adsDNWithString)(v.union())
+		if p == nil {
+			return DNWithString{}, nil
+		}
+		return DNWithString{DN: d.String(p.DN), String: d.String(p.Value)}, nil
+	default:
+		return nil, ErrUnsupportedADsType
+	}
+}
+
+// decode converts UTF-16 code units to a Go string. If the string has been
+// interned it is also returned held in an interface, otherwise boxed is nil.
+func (d *Decoder) decode(s []uint16) (str string, boxed interface{}) {
+	if len(s) == 0 {
+		return "", nil
+	}
+	if d == nil {
+		b := appendUTF16(make([]byte, 0, len(s)), s)
+		return unsafe.String(unsafe.SliceData(b), len(b)), nil
+	}
+	d.buf = appendUTF16(d.buf[:0], s)
+	if len(d.buf) > internMaxLength {
+		return string(d.buf), nil
+	}
+	if boxed, ok := d.interned[string(d.buf)]; ok {
+		return boxed.(string), boxed
+	}
+	str = string(d.buf)
+	if d.interned == nil {
+		d.interned = make(map[string]interface{})
+	}
+	if len(d.interned) < internMaxEntries {
+		boxed = str
+		d.interned[str] = boxed
+	}
+	return str, boxed
+}
+
+// utf16Slice returns the code units of a null-terminated UTF-16 string,
+// excluding the terminator.
+func utf16Slice(p *uint16) []uint16 {
+	if p == nil {
+		return nil
+	}
+	n := 0
+	for ptr := unsafe.Pointer(p); *(*uint16)(ptr) != 0; n++ {
+		ptr = unsafe.Add(ptr, 2)
+	}
+	return unsafe.Slice(p, n)
+}
+
+// appendUTF16 appends the UTF-8 encoding of the UTF-16 code units to dst.
+// Invalid surrogates are replaced with utf8.RuneError, as by utf16.Decode.
+func appendUTF16(dst []byte, s []uint16) []byte {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			dst = append(dst, byte(c))
+			continue
+		}
+		r := rune(c)
+		if utf16.IsSurrogate(r) {
+			r = utf8.RuneError
+			if i+1 < len(s) {
+				if dec := utf16.DecodeRune(rune(c), rune(s[i+1])); dec != utf8.RuneError {
+					r = dec
+					i++
+				}
+			}
+		}
+		dst = utf8.AppendRune(dst, r)
+	}
+	return dst
+}
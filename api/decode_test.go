@@ -0,0 +1,157 @@
+package api
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf16"
+	"unsafe"
+)
+
+// utf16z returns a pointer to a null-terminated UTF-16 copy of s.
+func utf16z(s string) *uint16 {
+	return &append(utf16.Encode([]rune(s)), 0)[0]
+}
+
+// bstr returns a pointer to a BSTR holding s, with its length prefix.
+func bstr(s string) *uint16 {
+	units := utf16.Encode([]rune(s))
+	buf := make([]uint16, 2, 2+len(units)+1)
+	*(*uint32)(unsafe.Pointer(&buf[0])) = uint32(len(units) * 2)
+	buf = append(append(buf, units...), 0)
+	return &buf[2]
+}
+
+// strs keeps the strings of the values returned by stringValue alive, as
+// the union of an ADSVALUE is invisible to the garbage collector.
+var strs []*uint16
+
+// stringValue returns an ADSVALUE of the given type holding s.
+func stringValue(adsType uint32, s string) ADSVALUE {
+	v := ADSVALUE{Type: adsType}
+	p := utf16z(s)
+	strs = append(strs, p)
+	*(**uint16)(v.union()) = p
+	return v
+}
+
+func TestDecoderString(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"empty", ""},
+		{"ascii", "CN=Users,DC=example,DC=com"},
+		{"accented", "CN=ZoëQuéré,OU=Équipe"},
+		{"surrogate pair", "emoji \U0001F600"},
+		{"long", "CN=" + strings.Repeat("x", internMaxLength)},
+	}
+	var d Decoder
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := d.String(utf16z(tt.in)); got != tt.in {
+				t.Errorf("String() = %q, want %q", got, tt.in)
+			}
+			if got := d.BSTR(bstr(tt.in)); got != tt.in {
+				t.Errorf("BSTR() = %q, want %q", got, tt.in)
+			}
+			var nilDecoder *Decoder
+			if got := nilDecoder.String(utf16z(tt.in)); got != tt.in {
+				t.Errorf("nil String() = %q, want %q", got, tt.in)
+			}
+		})
+	}
+	if got := d.String(nil); got != "" {
+		t.Errorf("String(nil) = %q, want \"\"", got)
+	}
+}
+
+func TestDecoderStringUnpairedSurrogate(t *testing.T) {
+	var d Decoder
+	units := []uint16{'a', 0xd800, 'b', 0}
+	if got, want := d.String(&units[0]), "a�b"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestDecoderInterns(t *testing.T) {
+	var d Decoder
+	a := d.String(utf16z("objectClass"))
+	b := d.String(utf16z("objectClass"))
+	if unsafe.StringData(a) != unsafe.StringData(b) {
+		t.Error("repeated short strings do not share storage")
+	}
+}
+
+func TestDecoderValue(t *testing.T) {
+	integer := ADSVALUE{Type: ADSTYPE_INTEGER}
+	*(*uint32)(integer.union()) = 0xffffffff
+	large := ADSVALUE{Type: ADSTYPE_LARGE_INTEGER}
+	*(*int64)(large.union()) = 1 << 40
+	boolean := ADSVALUE{Type: ADSTYPE_BOOLEAN}
+	*(*uint32)(boolean.union()) = 1
+
+	tests := []struct {
+		name string
+		in   ADSVALUE
+		want interface{}
+	}{
+		{"dn", stringValue(ADSTYPE_DN_STRING, "CN=x"), "CN=x"},
+		{"case ignore", stringValue(ADSTYPE_CASE_IGNORE_STRING, "value"), "value"},
+		{"object class", stringValue(ADSTYPE_OBJECT_CLASS, "user"), "user"},
+		{"integer", integer, int32(-1)},
+		{"large integer", large, int64(1 << 40)},
+		{"boolean", boolean, true},
+		{"dn with binary", ADSVALUE{Type: ADSTYPE_DN_WITH_BINARY}, DNWithBinary{}},
+		{"dn with string", ADSVALUE{Type: ADSTYPE_DN_WITH_STRING}, DNWithString{}},
+	}
+	var d Decoder
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := d.Value(&tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Value() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := d.Value(&ADSVALUE{Type: ADSTYPE_INVALID}); err != ErrUnsupportedADsType {
+		t.Errorf("Value() of invalid type returned %v, want ErrUnsupportedADsType", err)
+	}
+}
+
+func BenchmarkDecoderString(b *testing.B) {
+	names := []*uint16{utf16z("distinguishedName"), utf16z("objectClass"), utf16z("sAMAccountName"), utf16z("userPrincipalName")}
+	var d Decoder
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, name := range names {
+			d.String(name)
+		}
+	}
+}
+
+func BenchmarkDecoderValue(b *testing.B) {
+	integer := ADSVALUE{Type: ADSTYPE_INTEGER}
+	*(*uint32)(integer.union()) = 512
+	values := []ADSVALUE{
+		stringValue(ADSTYPE_DN_STRING, "CN=Jane Doe,OU=Staff,DC=example,DC=com"),
+		stringValue(ADSTYPE_CASE_IGNORE_STRING, "jdoe"),
+		stringValue(ADSTYPE_OBJECT_CLASS, "user"),
+		integer,
+	}
+	var d Decoder
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range values {
+			if _, err := d.Value(&values[j]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package api
+
+import "github.com/google/uuid"
+
+// Flags that can be passed to DsGetDcName to specify the requirements of the
+// domain controller that is located.
+//
+// See https://learn.microsoft.com/windows/win32/api/dsgetdc/nf-dsgetdc-dsgetdcnamew
+const (
+	DS_FORCE_REDISCOVERY            uint32 = 0x00000001
+	DS_DIRECTORY_SERVICE_REQUIRED   uint32 = 0x00000010
+	DS_DIRECTORY_SERVICE_PREFERRED  uint32 = 0x00000020
+	DS_GC_SERVER_REQUIRED           uint32 = 0x00000040
+	DS_PDC_REQUIRED                 uint32 = 0x00000080
+	DS_BACKGROUND_ONLY              uint32 = 0x00000100
+	DS_IP_REQUIRED                  uint32 = 0x00000200
+	DS_KDC_REQUIRED                 uint32 = 0x00000400
+	DS_TIMESERV_REQUIRED            uint32 = 0x00000800
+	DS_WRITABLE_REQUIRED            uint32 = 0x00001000
+	DS_GOOD_TIMESERV_PREFERRED      uint32 = 0x00002000
+	DS_AVOID_SELF                   uint32 = 0x00004000
+	DS_ONLY_LDAP_NEEDED             uint32 = 0x00008000
+	DS_IS_FLAT_NAME                 uint32 = 0x00010000
+	DS_IS_DNS_NAME                  uint32 = 0x00020000
+	DS_TRY_NEXTCLOSEST_SITE         uint32 = 0x00040000
+	DS_DIRECTORY_SERVICE_6_REQUIRED uint32 = 0x00080000
+	DS_WEB_SERVICE_REQUIRED         uint32 = 0x00100000
+	DS_RETURN_DNS_NAME              uint32 = 0x40000000
+	DS_RETURN_FLAT_NAME             uint32 = 0x80000000
+)
+
+// Flags returned by DsGetDcName that describe the capabilities of the domain
+// controller that was located.
+//
+// See https://learn.microsoft.com/windows/win32/api/dsgetdc/ns-dsgetdc-domain_controller_infow
+const (
+	DS_PDC_FLAG                    uint32 = 0x00000001
+	DS_GC_FLAG                     uint32 = 0x00000004
+	DS_LDAP_FLAG                   uint32 = 0x00000008
+	DS_DS_FLAG                     uint32 = 0x00000010
+	DS_KDC_FLAG                    uint32 = 0x00000020
+	DS_TIMESERV_FLAG               uint32 = 0x00000040
+	DS_CLOSEST_FLAG                uint32 = 0x00000080
+	DS_WRITABLE_FLAG               uint32 = 0x00000100
+	DS_GOOD_TIMESERV_FLAG          uint32 = 0x00000200
+	DS_NDNC_FLAG                   uint32 = 0x00000400
+	DS_SELECT_SECRET_DOMAIN_6_FLAG uint32 = 0x00000800
+	DS_FULL_SECRET_DOMAIN_6_FLAG   uint32 = 0x00001000
+	DS_WS_FLAG                     uint32 = 0x00002000
+	DS_DNS_CONTROLLER_FLAG         uint32 = 0x20000000
+	DS_DNS_DOMAIN_FLAG             uint32 = 0x40000000
+	DS_DNS_FOREST_FLAG             uint32 = 0x80000000
+)
+
+// DomainControllerInfo is the Go representation of the
+// DOMAIN_CONTROLLER_INFO structure returned by DsGetDcName.
+type DomainControllerInfo struct {
+	DomainControllerName        string
+	DomainControllerAddress     string
+	DomainControllerAddressType uint32
+	DomainGUID                  uuid.UUID
+	DomainName                  string
+	DnsForestName               string
+	Flags                       uint32
+	DcSiteName                  string
+	ClientSiteName              string
+}
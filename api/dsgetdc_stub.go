@@ -0,0 +1,12 @@
+//go:build !windows
+// +build !windows
+
+package api
+
+// DsGetDcName locates a domain controller in the given domain that satisfies
+// the given flags. If computer is empty the local computer performs the
+// lookup. If domain is empty the primary domain of the computer is used. If
+// site is non-empty a domain controller in that site is preferred.
+func DsGetDcName(computer, domain, site string, flags uint32) (info DomainControllerInfo, err error) {
+	return info, ErrUnsupported
+}
@@ -0,0 +1,82 @@
+//go:build windows
+// +build windows
+
+package api
+
+import (
+	"syscall"
+	"unsafe"
+
+	ole "github.com/go-ole/go-ole"
+	"github.com/google/uuid"
+)
+
+var (
+	modnetapi32 = syscall.NewLazyDLL("netapi32.dll")
+
+	procDsGetDcNameW     = modnetapi32.NewProc("DsGetDcNameW")
+	procNetApiBufferFree = modnetapi32.NewProc("NetApiBufferFree")
+)
+
+// domainControllerInfo mirrors the DOMAIN_CONTROLLER_INFOW structure.
+type domainControllerInfo struct {
+	DomainControllerName        *uint16
+	DomainControllerAddress     *uint16
+	DomainControllerAddressType uint32
+	DomainGUID                  ole.GUID
+	DomainName                  *uint16
+	DnsForestName               *uint16
+	Flags                       uint32
+	DcSiteName                  *uint16
+	ClientSiteName              *uint16
+}
+
+// DsGetDcName locates a domain controller in the given domain that satisfies
+// the given flags. If computer is empty the local computer performs the
+// lookup. If domain is empty the primary domain of the computer is used. If
+// site is non-empty a domain controller in that site is preferred.
+func DsGetDcName(computer, domain, site string, flags uint32) (info DomainControllerInfo, err error) {
+	var dci *domainControllerInfo
+	r, _, _ := procDsGetDcNameW.Call(
+		uintptr(unsafe.Pointer(utf16PtrOrNil(computer))),
+		uintptr(unsafe.Pointer(utf16PtrOrNil(domain))),
+		0,
+		uintptr(unsafe.Pointer(utf16PtrOrNil(site))),
+		uintptr(flags),
+		uintptr(unsafe.Pointer(&dci)))
+	if r != 0 {
+		return info, syscall.Errno(r)
+	}
+	defer procNetApiBufferFree.Call(uintptr(unsafe.Pointer(dci)))
+
+	g := dci.DomainGUID
+	info = DomainControllerInfo{
+		DomainControllerName:        UTF16PtrToString(dci.DomainControllerName),
+		DomainControllerAddress:     UTF16PtrToString(dci.DomainControllerAddress),
+		DomainControllerAddressType: dci.DomainControllerAddressType,
+		DomainGUID: uuid.UUID{
+			byte(g.Data1 >> 24), byte(g.Data1 >> 16), byte(g.Data1 >> 8), byte(g.Data1),
+			byte(g.Data2 >> 8), byte(g.Data2), byte(g.Data3 >> 8), byte(g.Data3),
+			g.Data4[0], g.Data4[1], g.Data4[2], g.Data4[3], g.Data4[4], g.Data4[5], g.Data4[6], g.Data4[7],
+		},
+		DomainName:     UTF16PtrToString(dci.DomainName),
+		DnsForestName:  UTF16PtrToString(dci.DnsForestName),
+		Flags:          dci.Flags,
+		DcSiteName:     UTF16PtrToString(dci.DcSiteName),
+		ClientSiteName: UTF16PtrToString(dci.ClientSiteName),
+	}
+	return
+}
+
+// utf16PtrOrNil returns a pointer to a null-terminated UTF-16 copy of s, or
+// nil if s is empty.
+func utf16PtrOrNil(s string) *uint16 {
+	if s == "" {
+		return nil
+	}
+	p, err := syscall.UTF16PtrFromString(s)
+	if err != nil {
+		return nil
+	}
+	return p
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+// +build !windows
+
+package api
+
+import (
+	ole "github.com/go-ole/go-ole"
+)
+
+// EnumNext retrieves up to len(items) variants from the enumerator and
+// stores them in items. It returns the number of variants retrieved, which is
+// less than len(items) once the enumerator has reached the end of its
+// collection. It is the caller's responsibility to clear the retrieved
+// variants.
+func EnumNext(enum *ole.IEnumVARIANT, items []ole.VARIANT) (n int, err error) {
+	return 0, ErrUnsupported
+}
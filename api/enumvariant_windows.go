@@ -0,0 +1,36 @@
+//go:build windows
+// +build windows
+
+package api
+
+import (
+	"syscall"
+	"unsafe"
+
+	ole "github.com/go-ole/go-ole"
+)
+
+// EnumNext retrieves up to len(items) variants from the enumerator and
+// stores them in items. It returns the number of variants retrieved, which is
+// less than len(items) once the enumerator has reached the end of its
+// collection. It is the caller's responsibility to clear the retrieved
+// variants.
+func EnumNext(enum *ole.IEnumVARIANT, items []ole.VARIANT) (n int, err error) {
+	if len(items) == 0 {
+		return 0, nil
+	}
+	var fetched uint32
+	hr, _, _ := syscall.Syscall6(
+		enum.VTable().Next,
+		4,
+		uintptr(unsafe.Pointer(enum)),
+		uintptr(len(items)),
+		uintptr(unsafe.Pointer(&items[0])),
+		uintptr(unsafe.Pointer(&fetched)),
+		0,
+		0)
+	if hr != 0 && hr != 1 { // S_FALSE is returned when fewer items remain
+		return 0, convertHresultToError(hr)
+	}
+	return int(fetched), nil
+}
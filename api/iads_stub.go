@@ -9,33 +9,33 @@ import (
 
 // Name retrieves the name of the object.
 func (v *IADs) Name() (name string, err error) {
-	return "", ole.NewError(ole.E_NOTIMPL)
+	return "", ErrUnsupported
 }
 
 // Class retrieves the class of the object.
 func (v *IADs) Class() (class string, err error) {
-	return "", ole.NewError(ole.E_NOTIMPL)
+	return "", ErrUnsupported
 }
 
 // GUID retrieves the GUID of the object as a string.
 func (v *IADs) GUID() (guid string, err error) {
-	return "", ole.NewError(ole.E_NOTIMPL)
+	return "", ErrUnsupported
 }
 
 // AdsPath retrieves the fully qualified path of the object.
 func (v *IADs) AdsPath() (path string, err error) {
-	return "", ole.NewError(ole.E_NOTIMPL)
+	return "", ErrUnsupported
 }
 
 // Parent retrieves the fully qualified path of the object's parent.
 func (v *IADs) Parent() (path string, err error) {
-	return "", ole.NewError(ole.E_NOTIMPL)
+	return "", ErrUnsupported
 }
 
 // Schema retrieves the fully qualified path of the object's schema class
 // object.
 func (v *IADs) Schema() (path string, err error) {
-	return "", ole.NewError(ole.E_NOTIMPL)
+	return "", ErrUnsupported
 }
 
 // Get retrieves a property with the given name. If the property holds a single
@@ -43,7 +43,7 @@ func (v *IADs) Schema() (path string, err error) {
 // items, a VARIANT array is returned containing the items, with each value
 // being a VARIANT itself.
 func (v *IADs) Get(name string) (prop *ole.VARIANT, err error) {
-	return nil, ole.NewError(ole.E_NOTIMPL)
+	return nil, ErrUnsupported
 }
 
 // GetEx retrieves a property with the given name. The property is returned as
@@ -51,28 +51,51 @@ func (v *IADs) Get(name string) (prop *ole.VARIANT, err error) {
 // Get function, if the property holds a single item, it is returned as a
 // VARIANT array with one member.
 func (v *IADs) GetEx(name string) (prop *ole.VARIANT, err error) {
-	return nil, ole.NewError(ole.E_NOTIMPL)
+	return nil, ErrUnsupported
+}
+
+// GetInfo loads the values of all of the object's properties into the cache,
+// replacing any values that have been put but not yet saved.
+func (v *IADs) GetInfo() (err error) {
+	return ErrUnsupported
 }
 
 // GetInfoEx loads the given set of property names into the cache. The given
 // variant must be a safe array of null-terminated unicode strings.
 func (v *IADs) GetInfoEx(variant *ole.VARIANT) (err error) {
-	return ole.NewError(ole.E_NOTIMPL)
+	return ErrUnsupported
+}
+
+// Put sets the values of an attribute in the ADSI attribute cache. The value
+// must be commited with SetInfo to be made persistent.
+func (v *IADs) Put(name string, val *ole.VARIANT) error {
+	return ErrUnsupported
+}
+
+// PutEx modifies the values of an attribute in the ADSI attribute cache. The
+// control code determines whether the given values replace, are appended to,
+// or are deleted from the existing values, or whether the attribute is
+// cleared. See ADS_PROPERTY_OPERATION_ENUM for the available options.
+//
+// The given variant must be a variant array unless the attribute is being
+// cleared. The value must be commited with SetInfo to be made persistent.
+func (v *IADs) PutEx(controlCode uint32, name string, val *ole.VARIANT) error {
+	return ErrUnsupported
 }
 
 // PutInt sets the values of an int attribute in the ADSI attribute
 // cache. The value must be commited with SetInfo to be made persistent.
 func (v *IADs) PutInt(name string, val int) error {
-	return ole.NewError(ole.E_NOTIMPL)
+	return ErrUnsupported
 }
 
 // PutString sets the values of a string attribute in the ADSI attribute
 // cache. The value must be commited with SetInfo to be made persistent.
 func (v *IADs) PutString(name string, val string) error {
-	return ole.NewError(ole.E_NOTIMPL)
+	return ErrUnsupported
 }
 
 // SetInfo saves the cached property values of the ADSI object to the underlying directory store.
 func (v *IADs) SetInfo() error {
-	return ole.NewError(ole.E_NOTIMPL)
+	return ErrUnsupported
 }
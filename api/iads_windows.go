@@ -180,6 +180,21 @@ func (v *IADs) GetEx(name string) (prop *ole.VARIANT, err error) {
 	return
 }
 
+// GetInfo loads the values of all of the object's properties into the cache,
+// replacing any values that have been put but not yet saved.
+func (v *IADs) GetInfo() (err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().GetInfo),
+		1,
+		uintptr(unsafe.Pointer(v)),
+		0,
+		0)
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return nil
+}
+
 // GetInfoEx loads the given set of property names into the cache. The given
 // variant must be a safe array of null-terminated unicode strings.
 func (v *IADs) GetInfoEx(variant *ole.VARIANT) (err error) {
@@ -195,6 +210,56 @@ func (v *IADs) GetInfoEx(variant *ole.VARIANT) (err error) {
 	return nil
 }
 
+// Put sets the values of an attribute in the ADSI attribute cache. The value
+// must be commited with SetInfo to be made persistent.
+func (v *IADs) Put(name string, val *ole.VARIANT) error {
+	bname := ole.SysAllocStringLen(name)
+	if bname == nil {
+		return ole.NewError(ole.E_OUTOFMEMORY)
+	}
+	defer ole.SysFreeString(bname)
+
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().Put),
+		3,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(bname)),
+		uintptr(unsafe.Pointer(val)))
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return nil
+}
+
+// PutEx modifies the values of an attribute in the ADSI attribute cache. The
+// control code determines whether the given values replace, are appended to,
+// or are deleted from the existing values, or whether the attribute is
+// cleared. See ADS_PROPERTY_OPERATION_ENUM for the available options.
+//
+// The given variant must be a variant array unless the attribute is being
+// cleared. The value must be commited with SetInfo to be made persistent.
+func (v *IADs) PutEx(controlCode uint32, name string, val *ole.VARIANT) error {
+	bname := ole.SysAllocStringLen(name)
+	if bname == nil {
+		return ole.NewError(ole.E_OUTOFMEMORY)
+	}
+	defer ole.SysFreeString(bname)
+
+	hr, _, _ := syscall.Syscall6(
+		uintptr(v.VTable().PutEx),
+		4,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(controlCode),
+		uintptr(unsafe.Pointer(bname)),
+		uintptr(unsafe.Pointer(val)),
+		0,
+		0)
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return nil
+}
+
 // PutInt sets the values of an int attribute in the ADSI attribute
 // cache. The value must be commited with SetInfo to be made persistent.
 func (v *IADs) PutInt(name string, val int) error {
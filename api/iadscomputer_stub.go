@@ -2,19 +2,17 @@
 
 package api
 
-import "github.com/go-ole/go-ole"
-
 // ComputerID retrieves the globally unique identifier of the computer.
 func (v *IADsComputer) ComputerID() (id string, err error) {
-	return "", ole.NewError(ole.E_NOTIMPL)
+	return "", ErrUnsupported
 }
 
 // Site retrieves the site of the computer.
 func (v *IADsComputer) Site() (site string, err error) {
-	return "", ole.NewError(ole.E_NOTIMPL)
+	return "", ErrUnsupported
 }
 
 // OperatingSystem retrieves the operating system of the computer.
 func (v *IADsComputer) OperatingSystem() (os string, err error) {
-	return "", ole.NewError(ole.E_NOTIMPL)
+	return "", ErrUnsupported
 }
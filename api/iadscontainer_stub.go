@@ -11,7 +11,7 @@ import (
 // NewIADsContainer returns a new instance of the IADsContainer
 // component object model interface.
 func NewIADsContainer(server string, clsid uuid.UUID) (*IADsContainer, error) {
-	return nil, ole.NewError(ole.E_NOTIMPL)
+	return nil, ErrUnsupported
 }
 
 // NewEnum retrieves an enumerator interface that provides access to the objects
@@ -19,21 +19,41 @@ func NewIADsContainer(server string, clsid uuid.UUID) (*IADsContainer, error) {
 //
 // See https://msdn.microsoft.com/library/aa705990
 func (v *IADsContainer) NewEnum() (enum *ole.IUnknown, err error) {
-	return nil, ole.NewError(ole.E_NOTIMPL)
+	return nil, ErrUnsupported
 }
 
 // Filter retrieves the filter for the container.
 func (v *IADsContainer) Filter() (variant *ole.VARIANT, err error) {
-	return nil, ole.NewError(ole.E_NOTIMPL)
+	return nil, ErrUnsupported
 }
 
 // GetObject returns a descendant object with the given class and relative
 // name.
 func (v *IADsContainer) GetObject(class, name string) (obj *ole.IDispatch, err error) {
-	return nil, ole.NewError(ole.E_NOTIMPL)
+	return nil, ErrUnsupported
 }
 
 // SetFilter sets the filter for the container.
 func (v *IADsContainer) SetFilter(variant *ole.VARIANT) (err error) {
-	return ole.NewError(ole.E_NOTIMPL)
+	return ErrUnsupported
+}
+
+// Create sets up a request to create a directory object of the given class
+// and relative name in the container. The object is not written to the
+// directory until SetInfo is called on it.
+func (v *IADsContainer) Create(class, name string) (obj *ole.IDispatch, err error) {
+	return nil, ErrUnsupported
+}
+
+// Delete deletes the directory object of the given class and relative name
+// from the container. The object must not have any children.
+func (v *IADsContainer) Delete(class, name string) (err error) {
+	return ErrUnsupported
+}
+
+// MoveHere moves the object with the given ADsPath into the container. If
+// name is not empty the object is also renamed to the given relative name.
+// Moving an object within the same container renames it.
+func (v *IADsContainer) MoveHere(sourcePath, name string) (obj *ole.IDispatch, err error) {
+	return nil, ErrUnsupported
 }
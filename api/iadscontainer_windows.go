@@ -101,3 +101,96 @@ func (v *IADsContainer) GetObject(class, name string) (obj *ole.IDispatch, err e
 	}
 	return
 }
+
+// Create sets up a request to create a directory object of the given class
+// and relative name in the container. The object is not written to the
+// directory until SetInfo is called on it.
+func (v *IADsContainer) Create(class, name string) (obj *ole.IDispatch, err error) {
+	bclass := ole.SysAllocStringLen(class)
+	if bclass == nil {
+		return nil, ole.NewError(ole.E_OUTOFMEMORY)
+	}
+	defer ole.SysFreeString(bclass)
+
+	bname := ole.SysAllocStringLen(name)
+	if bname == nil {
+		return nil, ole.NewError(ole.E_OUTOFMEMORY)
+	}
+	defer ole.SysFreeString(bname)
+
+	hr, _, _ := syscall.Syscall6(
+		uintptr(v.VTable().Create),
+		4,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(bclass)),
+		uintptr(unsafe.Pointer(bname)),
+		uintptr(unsafe.Pointer(&obj)),
+		0,
+		0)
+	if hr != 0 {
+		return nil, convertHresultToError(hr)
+	}
+	return
+}
+
+// Delete deletes the directory object of the given class and relative name
+// from the container. The object must not have any children.
+func (v *IADsContainer) Delete(class, name string) (err error) {
+	bclass := ole.SysAllocStringLen(class)
+	if bclass == nil {
+		return ole.NewError(ole.E_OUTOFMEMORY)
+	}
+	defer ole.SysFreeString(bclass)
+
+	bname := ole.SysAllocStringLen(name)
+	if bname == nil {
+		return ole.NewError(ole.E_OUTOFMEMORY)
+	}
+	defer ole.SysFreeString(bname)
+
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().Delete),
+		3,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(bclass)),
+		uintptr(unsafe.Pointer(bname)))
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return
+}
+
+// MoveHere moves the object with the given ADsPath into the container. If
+// name is not empty the object is also renamed to the given relative name.
+// Moving an object within the same container renames it.
+//
+// See https://learn.microsoft.com/windows/win32/api/iads/nf-iads-iadscontainer-movehere
+func (v *IADsContainer) MoveHere(sourcePath, name string) (obj *ole.IDispatch, err error) {
+	bsource := ole.SysAllocStringLen(sourcePath)
+	if bsource == nil {
+		return nil, ole.NewError(ole.E_OUTOFMEMORY)
+	}
+	defer ole.SysFreeString(bsource)
+
+	var bname *int16
+	if name != "" {
+		if bname = ole.SysAllocStringLen(name); bname == nil {
+			return nil, ole.NewError(ole.E_OUTOFMEMORY)
+		}
+		defer ole.SysFreeString(bname)
+	}
+
+	hr, _, _ := syscall.Syscall6(
+		uintptr(v.VTable().MoveHere),
+		4,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(bsource)),
+		uintptr(unsafe.Pointer(bname)),
+		uintptr(unsafe.Pointer(&obj)),
+		0,
+		0)
+	if hr != 0 {
+		return nil, convertHresultToError(hr)
+	}
+	return
+}
@@ -2,26 +2,30 @@
 
 package api
 
-import "github.com/go-ole/go-ole"
-
 // Add adds an ADSI object to an existing group.
 func (v *IADsGroup) Add(member string) (err error) {
-	return ole.NewError(ole.E_NOTIMPL)
+	return ErrUnsupported
 }
 
 // Description retrieves the description of the group.
 func (v *IADsGroup) Description() (desc string, err error) {
-	return "", ole.NewError(ole.E_NOTIMPL)
+	return "", ErrUnsupported
+}
+
+// IsMember determines whether the ADSI object with the given path is a direct
+// member of the group.
+func (v *IADsGroup) IsMember(member string) (isMember bool, err error) {
+	return false, ErrUnsupported
 }
 
 // Members retrieves an IADsMembers interface that provides access to the
 // membership of the group.
 func (v *IADsGroup) Members() (members *IADsMembers, err error) {
-	return nil, ole.NewError(ole.E_NOTIMPL)
+	return nil, ErrUnsupported
 }
 
 // Remove removes the specified user object from this group. The operation
 // does not remove the group object itself even when there is no member remaining in the group.
 func (v *IADsGroup) Remove(member string) (err error) {
-	return ole.NewError(ole.E_NOTIMPL)
+	return ErrUnsupported
 }
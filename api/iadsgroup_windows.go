@@ -48,6 +48,27 @@ func (v *IADsGroup) Description() (desc string, err error) {
 	return
 }
 
+// IsMember determines whether the ADSI object with the given path is a direct
+// member of the group.
+func (v *IADsGroup) IsMember(member string) (isMember bool, err error) {
+	m := ole.SysAllocStringLen(member)
+	if m == nil {
+		return false, ole.NewError(ole.E_OUTOFMEMORY)
+	}
+	defer ole.SysFreeString(m)
+	var result int16
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().IsMember),
+		3,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(m)),
+		uintptr(unsafe.Pointer(&result)))
+	if hr != 0 {
+		return false, convertHresultToError(hr)
+	}
+	return result != 0, nil
+}
+
 // Members retrieves an IADsMembers interface that provides access to the
 // membership of the group.
 func (v *IADsGroup) Members() (members *IADsMembers, err error) {
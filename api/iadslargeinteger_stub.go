@@ -2,19 +2,38 @@
 
 package api
 
-import ole "github.com/go-ole/go-ole"
+// NewIADsLargeInteger returns a new instance of the IADsLargeInteger
+// component object model interface. Its value is initially zero.
+func NewIADsLargeInteger() (*IADsLargeInteger, error) {
+	return nil, ErrUnsupported
+}
 
 // HighPart retrieves the upper 32 bits of the 64 bit value.
 func (v *IADsLargeInteger) HighPart() (upper int32, err error) {
-	return 0, ole.NewError(ole.E_NOTIMPL)
+	return 0, ErrUnsupported
 }
 
 // LowPart retrieves the lower 32 bits of the 64 bit value.
 func (v *IADsLargeInteger) LowPart() (lower int32, err error) {
-	return 0, ole.NewError(ole.E_NOTIMPL)
+	return 0, ErrUnsupported
 }
 
 // Value retrieves the 64 bit value.
 func (v *IADsLargeInteger) Value() (value int64, err error) {
-	return 0, ole.NewError(ole.E_NOTIMPL)
+	return 0, ErrUnsupported
+}
+
+// SetHighPart sets the upper 32 bits of the 64 bit value.
+func (v *IADsLargeInteger) SetHighPart(upper int32) (err error) {
+	return ErrUnsupported
+}
+
+// SetLowPart sets the lower 32 bits of the 64 bit value.
+func (v *IADsLargeInteger) SetLowPart(lower int32) (err error) {
+	return ErrUnsupported
+}
+
+// SetValue sets the 64 bit value.
+func (v *IADsLargeInteger) SetValue(value int64) (err error) {
+	return ErrUnsupported
 }
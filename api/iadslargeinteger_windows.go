@@ -5,8 +5,19 @@ package api
 import (
 	"syscall"
 	"unsafe"
+
+	"github.com/go-adsi/adsi/comclsid"
+	"github.com/go-adsi/adsi/comiid"
+	"github.com/scjalliance/comutil"
 )
 
+// NewIADsLargeInteger returns a new instance of the IADsLargeInteger
+// component object model interface. Its value is initially zero.
+func NewIADsLargeInteger() (*IADsLargeInteger, error) {
+	p, err := comutil.CreateObject(comclsid.LargeInteger, comiid.IADsLargeInteger)
+	return (*IADsLargeInteger)(unsafe.Pointer(p)), err
+}
+
 // HighPart retrieves the upper 32 bits of the 64 bit value.
 func (v *IADsLargeInteger) HighPart() (upper int32, err error) {
 	hr, _, _ := syscall.Syscall(
@@ -48,3 +59,39 @@ func (v *IADsLargeInteger) Value() (value int64, err error) {
 	}
 	return (int64(uint32(upper)) << 32) | int64(uint32(lower)), nil
 }
+
+// SetHighPart sets the upper 32 bits of the 64 bit value.
+func (v *IADsLargeInteger) SetHighPart(upper int32) (err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().SetHighPart),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(upper),
+		0)
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return
+}
+
+// SetLowPart sets the lower 32 bits of the 64 bit value.
+func (v *IADsLargeInteger) SetLowPart(lower int32) (err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().SetLowPart),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(lower),
+		0)
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return
+}
+
+// SetValue sets the 64 bit value.
+func (v *IADsLargeInteger) SetValue(value int64) (err error) {
+	if err = v.SetHighPart(int32(value >> 32)); err != nil {
+		return
+	}
+	return v.SetLowPart(int32(uint32(value)))
+}
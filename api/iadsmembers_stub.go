@@ -9,15 +9,15 @@ import "github.com/go-ole/go-ole"
 //
 // See https://msdn.microsoft.com/library/aa706042
 func (v *IADsMembers) NewEnum() (enum *ole.IUnknown, err error) {
-	return nil, ole.NewError(ole.E_NOTIMPL)
+	return nil, ErrUnsupported
 }
 
 // Filter retrieves the filter for the membership.
 func (v *IADsMembers) Filter() (variant *ole.VARIANT, err error) {
-	return nil, ole.NewError(ole.E_NOTIMPL)
+	return nil, ErrUnsupported
 }
 
 // SetFilter sets the filter for the membership.
 func (v *IADsMembers) SetFilter(variant *ole.VARIANT) (err error) {
-	return ole.NewError(ole.E_NOTIMPL)
+	return ErrUnsupported
 }
@@ -9,22 +9,34 @@ import (
 
 // NewIADsNameTranslate returns an IADsNameTranslate that manages the given COM interface.
 func NewIADsNameTranslate(server string) (*IADsNameTranslate, error) {
-	return nil, ole.NewError(ole.E_NOTIMPL)
+	return nil, ErrUnsupported
 }
 
 // Get retrieves the name of a directory object in the specified format.
 // The distinguished name must have been set in the appropriate format by the Set function.
 func (v *IADsNameTranslate) Get(formatType uint32) (adsPath string, err error) {
-	return "", ole.NewError(ole.E_NOTIMPL)
+	return "", ErrUnsupported
 }
 
 // Init initializes a name translate object by binding to a specified directory server, domain,
 // or global catalog, using the credentials of the current user.
 func (v *IADsNameTranslate) Init(adsPath string, initType uint32) (err error) {
-	return ole.NewError(ole.E_NOTIMPL)
+	return ErrUnsupported
 }
 
 // Set directs the directory service to set up a specified object for name translation.
 func (v *IADsNameTranslate) Set(adsPath string, setType uint32) (err error) {
-	return ole.NewError(ole.E_NOTIMPL)
+	return ErrUnsupported
+}
+
+// SetEx directs the directory service to set up the specified objects for
+// name translation.
+func (v *IADsNameTranslate) SetEx(formatType uint32, names *ole.VARIANT) (err error) {
+	return ErrUnsupported
+}
+
+// GetEx retrieves the names of the directory objects set by SetEx in the
+// specified format.
+func (v *IADsNameTranslate) GetEx(formatType uint32) (names *ole.VARIANT, err error) {
+	return nil, ErrUnsupported
 }
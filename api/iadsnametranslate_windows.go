@@ -78,3 +78,38 @@ func (v *IADsNameTranslate) Set(adsPath string, setType uint32) (err error) {
 	}
 	return
 }
+
+// SetEx directs the directory service to set up the specified objects for
+// name translation. The names must be given as a variant array of strings in
+// the format matching formatType.
+func (v *IADsNameTranslate) SetEx(formatType uint32, names *ole.VARIANT) (err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().SetEx),
+		3,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(formatType),
+		uintptr(unsafe.Pointer(names)))
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return
+}
+
+// GetEx retrieves the names of the directory objects set by SetEx in the
+// specified format, as a variant array of strings in the order they were set.
+// It is the caller's responsibilty to clear the returned variant.
+func (v *IADsNameTranslate) GetEx(formatType uint32) (names *ole.VARIANT, err error) {
+	names = new(ole.VARIANT)
+	ole.VariantInit(names)
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().GetEx),
+		3,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(formatType),
+		uintptr(unsafe.Pointer(names)))
+	if hr != 0 {
+		names.Clear()
+		return nil, convertHresultToError(hr)
+	}
+	return
+}
@@ -0,0 +1,15 @@
+// +build !windows
+
+package api
+
+// IntOption retrieves the value of an option of the object that holds an
+// integer, such as ADS_OPTION_SECURITY_MASK.
+func (v *IADsObjectOptions) IntOption(option uint32) (value int32, err error) {
+	return 0, ErrUnsupported
+}
+
+// SetIntOption sets an option of the object that holds an integer, such as
+// ADS_OPTION_SECURITY_MASK.
+func (v *IADsObjectOptions) SetIntOption(option uint32, value int32) (err error) {
+	return ErrUnsupported
+}
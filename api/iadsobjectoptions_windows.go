@@ -0,0 +1,44 @@
+// +build windows
+
+package api
+
+import (
+	"syscall"
+	"unsafe"
+
+	ole "github.com/go-ole/go-ole"
+)
+
+// IntOption retrieves the value of an option of the object that holds an
+// integer, such as ADS_OPTION_SECURITY_MASK.
+func (v *IADsObjectOptions) IntOption(option uint32) (value int32, err error) {
+	var variant ole.VARIANT
+	ole.VariantInit(&variant)
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().GetOption),
+		3,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(option),
+		uintptr(unsafe.Pointer(&variant)))
+	if hr != 0 {
+		return 0, convertHresultToError(hr)
+	}
+	defer variant.Clear()
+	return int32(variant.Val), nil
+}
+
+// SetIntOption sets an option of the object that holds an integer, such as
+// ADS_OPTION_SECURITY_MASK.
+func (v *IADsObjectOptions) SetIntOption(option uint32, value int32) (err error) {
+	variant := ole.NewVariant(ole.VT_I4, int64(value))
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().SetOption),
+		3,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(option),
+		uintptr(unsafe.Pointer(&variant)))
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+// +build !windows
+
+package api
+
+// Name retrieves the name of the property.
+func (v *IADsPropertyEntry) Name() (name string, err error) {
+	return "", ErrUnsupported
+}
+
+// ADsType retrieves the ADSTYPE of the property's values.
+func (v *IADsPropertyEntry) ADsType() (adsType int32, err error) {
+	return 0, ErrUnsupported
+}
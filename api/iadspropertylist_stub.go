@@ -0,0 +1,22 @@
+//go:build !windows
+// +build !windows
+
+package api
+
+// PropertyCount retrieves the number of properties in the property cache.
+func (v *IADsPropertyList) PropertyCount() (count int32, err error) {
+	return 0, ErrUnsupported
+}
+
+// Next retrieves the next entry of the property cache as an
+// IADsPropertyEntry. It returns ok as false once the end of the list has
+// been reached. It is the caller's responsibility to release the returned
+// entry.
+func (v *IADsPropertyList) Next() (entry *IADsPropertyEntry, ok bool, err error) {
+	return nil, false, ErrUnsupported
+}
+
+// Reset moves back to the first entry of the property cache.
+func (v *IADsPropertyList) Reset() (err error) {
+	return ErrUnsupported
+}
@@ -0,0 +1,67 @@
+//go:build windows
+// +build windows
+
+package api
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/go-ole/go-ole"
+)
+
+// PropertyCount retrieves the number of properties in the property cache.
+func (v *IADsPropertyList) PropertyCount() (count int32, err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().PropertyCount),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&count)),
+		0)
+	if hr != 0 {
+		return 0, convertHresultToError(hr)
+	}
+	return
+}
+
+// Next retrieves the next entry of the property cache as an
+// IADsPropertyEntry. It returns ok as false once the end of the list has
+// been reached. It is the caller's responsibility to release the returned
+// entry.
+func (v *IADsPropertyList) Next() (entry *IADsPropertyEntry, ok bool, err error) {
+	var variant ole.VARIANT
+	ole.VariantInit(&variant)
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().Next),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&variant)),
+		0)
+	if hr == 1 { // S_FALSE
+		return nil, false, nil
+	}
+	if hr != 0 {
+		return nil, false, convertHresultToError(hr)
+	}
+	defer variant.Clear()
+	idispatch := variant.ToIDispatch()
+	if idispatch == nil {
+		return nil, false, nil
+	}
+	idispatch.AddRef()
+	return (*IADsPropertyEntry)(unsafe.Pointer(idispatch)), true, nil
+}
+
+// Reset moves back to the first entry of the property cache.
+func (v *IADsPropertyList) Reset() (err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().Reset),
+		1,
+		uintptr(unsafe.Pointer(v)),
+		0,
+		0)
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return nil
+}
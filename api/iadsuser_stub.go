@@ -3,19 +3,31 @@
 
 package api
 
-import "github.com/go-ole/go-ole"
-
 // AccountDisabled retrieves the disablement status of a user account.
 func (v *IADsUser) AccountDisabled() (disabled bool, err error) {
-	return false, ole.NewError(ole.E_NOTIMPL)
+	return false, ErrUnsupported
 }
 
 // SetAccountDisabled sets an account as disabled.
 func (v *IADsUser) SetAccountDisabled(disabled bool) (err error) {
-	return ole.NewError(ole.E_NOTIMPL)
+	return ErrUnsupported
 }
 
 // FullName returns the user's FullName property.
 func (v *IADsUser) FullName() (name string, err error) {
-	return "", ole.NewError(ole.E_NOTIMPL)
+	return "", ErrUnsupported
+}
+
+// SetPassword sets the password of the user account. The change takes effect
+// immediately and does not require a call to SetInfo. The caller must have
+// the right to reset the password of the account.
+func (v *IADsUser) SetPassword(password string) (err error) {
+	return ErrUnsupported
+}
+
+// ChangePassword changes the password of the user account from oldPassword
+// to newPassword. The change takes effect immediately and does not require a
+// call to SetInfo.
+func (v *IADsUser) ChangePassword(oldPassword, newPassword string) (err error) {
+	return ErrUnsupported
 }
@@ -56,3 +56,53 @@ func (v *IADsUser) FullName() (name string, err error) {
 	name = ole.BstrToString((*uint16)(unsafe.Pointer(bstr)))
 	return
 }
+
+// SetPassword sets the password of the user account. The change takes effect
+// immediately and does not require a call to SetInfo. The caller must have
+// the right to reset the password of the account.
+func (v *IADsUser) SetPassword(password string) (err error) {
+	bpassword := ole.SysAllocStringLen(password)
+	if bpassword == nil {
+		return ole.NewError(ole.E_OUTOFMEMORY)
+	}
+	defer ole.SysFreeString(bpassword)
+
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().SetPassword),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(bpassword)),
+		0)
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return
+}
+
+// ChangePassword changes the password of the user account from oldPassword
+// to newPassword. The change takes effect immediately and does not require a
+// call to SetInfo.
+func (v *IADsUser) ChangePassword(oldPassword, newPassword string) (err error) {
+	bold := ole.SysAllocStringLen(oldPassword)
+	if bold == nil {
+		return ole.NewError(ole.E_OUTOFMEMORY)
+	}
+	defer ole.SysFreeString(bold)
+
+	bnew := ole.SysAllocStringLen(newPassword)
+	if bnew == nil {
+		return ole.NewError(ole.E_OUTOFMEMORY)
+	}
+	defer ole.SysFreeString(bnew)
+
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().ChangePassword),
+		3,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(bold)),
+		uintptr(unsafe.Pointer(bnew)))
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return
+}
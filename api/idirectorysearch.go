@@ -1,31 +1,238 @@
 package api
 
 import (
+	"unicode/utf16"
 	"unsafe"
 
 	"github.com/go-ole/go-ole"
 )
 
+// The ADS_SEARCHPREF_ENUM enumeration specifies the search preferences that
+// can be set with IDirectorySearch.SetSearchPreference.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/ne-iads-ads_searchpref_enum
+const (
+	ADS_SEARCHPREF_ASYNCHRONOUS uint32 = iota
+	ADS_SEARCHPREF_DEREF_ALIASES
+	ADS_SEARCHPREF_SIZE_LIMIT
+	ADS_SEARCHPREF_TIME_LIMIT
+	ADS_SEARCHPREF_ATTRIBTYPES_ONLY
+	ADS_SEARCHPREF_SEARCH_SCOPE
+	ADS_SEARCHPREF_TIMEOUT
+	ADS_SEARCHPREF_PAGESIZE
+	ADS_SEARCHPREF_PAGED_TIME_LIMIT
+	ADS_SEARCHPREF_CHASE_REFERRALS
+	ADS_SEARCHPREF_SORT_ON
+	ADS_SEARCHPREF_CACHE_RESULTS
+	ADS_SEARCHPREF_DIRSYNC
+	ADS_SEARCHPREF_TOMBSTONE
+	ADS_SEARCHPREF_VLV
+	ADS_SEARCHPREF_ATTRIBUTE_QUERY
+	ADS_SEARCHPREF_SECURITY_MASK
+	ADS_SEARCHPREF_DIRSYNC_FLAG
+	ADS_SEARCHPREF_EXTENDED_DN
+)
+
+// Flags for the ADS_SEARCHPREF_DIRSYNC_FLAG search preference.
+//
+// See https://learn.microsoft.com/openspecs/windows_protocols/ms-adts/2213a7f2-0a36-483c-b2a4-8574d53aa1e3
+const (
+	LDAP_DIRSYNC_OBJECT_SECURITY       uint32 = 0x00000001
+	LDAP_DIRSYNC_ANCESTORS_FIRST_ORDER uint32 = 0x00000800
+	LDAP_DIRSYNC_PUBLIC_DATA_ONLY      uint32 = 0x00002000
+	LDAP_DIRSYNC_INCREMENTAL_VALUES    uint32 = 0x80000000
+)
+
+// ADS_DIRSYNC_COOKIE is the name of the column that holds the updated
+// cookie of a DirSync search once all of its rows have been read.
+const ADS_DIRSYNC_COOKIE = "fc8cb04d-311d-406c-8cb9-1ae8b843b418"
+
+// ADS_VLV_RESPONSE is the name of the column that holds the server's
+// response to a virtual list view search, as an ADS_VLV structure.
+const ADS_VLV_RESPONSE = "fc8cb04d-311d-406c-8cb9-1ae8b843b419"
+
+// The ADS_SCOPEENUM enumeration specifies the scope of a directory search.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/ne-iads-ads_scopeenum
+const (
+	ADS_SCOPE_BASE uint32 = iota
+	ADS_SCOPE_ONELEVEL
+	ADS_SCOPE_SUBTREE
+)
+
+// The ADS_STATUSENUM enumeration specifies the status of a search preference
+// after a call to IDirectorySearch.SetSearchPreference.
+const (
+	ADS_STATUS_S_OK uint32 = iota
+	ADS_STATUS_INVALID_SEARCHPREF
+	ADS_STATUS_INVALID_SEARCHPREFVALUE
+)
+
+// ADS_SEARCH_HANDLE is a handle to the results of a directory search.
+type ADS_SEARCH_HANDLE uintptr
+
+// ADS_SEARCHPREF_INFO specifies a search preference for a directory search.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/ns-iads-ads_searchpref_info
+type ADS_SEARCHPREF_INFO struct {
+	SearchPref uint32
+	_          uint32
+	Value      ADSVALUE
+	Status     uint32
+	_          uint32
+}
+
+// NewSearchPrefInteger returns a search preference holding an integer value.
+func NewSearchPrefInteger(pref uint32, value uint32) ADS_SEARCHPREF_INFO {
+	info := ADS_SEARCHPREF_INFO{SearchPref: pref}
+	info.Value.Type = ADSTYPE_INTEGER
+	*(*uint32)(info.Value.union()) = value
+	return info
+}
+
+// NewSearchPrefBoolean returns a search preference holding a boolean value.
+func NewSearchPrefBoolean(pref uint32, value bool) ADS_SEARCHPREF_INFO {
+	info := ADS_SEARCHPREF_INFO{SearchPref: pref}
+	info.Value.Type = ADSTYPE_BOOLEAN
+	if value {
+		*(*uint32)(info.Value.union()) = 1
+	}
+	return info
+}
+
+// NewSearchPrefProvSpecific returns a search preference holding a provider
+// specific value, such as a DirSync cookie. The preference refers to the
+// memory of the given slice, which must be kept alive until the preference
+// has been applied.
+func NewSearchPrefProvSpecific(pref uint32, value []byte) ADS_SEARCHPREF_INFO {
+	info := ADS_SEARCHPREF_INFO{SearchPref: pref}
+	info.Value.Type = ADSTYPE_PROV_SPECIFIC
+	s := (*adsOctetString)(info.Value.union())
+	s.Length = uint32(len(value))
+	if len(value) > 0 {
+		s.Value = &value[0]
+	}
+	return info
+}
+
+// ADS_SORTKEY specifies an attribute by which the results of a search are
+// sorted with the ADS_SEARCHPREF_SORT_ON search preference.
+//
+// See https://learn.microsoft.com/windows/win32/api/iads/ns-iads-ads_sortkey
+type ADS_SORTKEY struct {
+	AttrType     *uint16
+	Reserved     *uint16
+	ReverseOrder bool
+}
+
+// NewSortKey returns a sort key for the attribute with the given name.
+func NewSortKey(attr string, reverse bool) ADS_SORTKEY {
+	name := utf16.Encode([]rune(attr + "\x00"))
+	return ADS_SORTKEY{AttrType: &name[0], ReverseOrder: reverse}
+}
+
+// NewSearchPrefSortOn returns a search preference that sorts the results by
+// the given keys. The preference refers to the memory of the keys, which
+// must be kept alive until the preference has been applied.
+func NewSearchPrefSortOn(keys []ADS_SORTKEY) ADS_SEARCHPREF_INFO {
+	info := ADS_SEARCHPREF_INFO{SearchPref: ADS_SEARCHPREF_SORT_ON}
+	info.Value.Type = ADSTYPE_PROV_SPECIFIC
+	s := (*adsOctetString)(info.Value.union())
+	s.Length = uint32(uintptr(len(keys)) * unsafe.Sizeof(ADS_SORTKEY{}))
+	if len(keys) > 0 {
+		s.Value = (*byte)(unsafe.Pointer(&keys[0]))
+	}
+	return info
+}
+
+// ADS_VLV holds the parameters of a virtual list view search set with the
+// ADS_SEARCHPREF_VLV search preference, and the server's response to one.
+//
+// See https://learn.microsoft.com/windows/win32/api/iads/ns-iads-ads_vlv
+type ADS_VLV struct {
+	BeforeCount     uint32
+	AfterCount      uint32
+	Offset          uint32
+	ContentCount    uint32
+	Target          *uint16
+	ContextIDLength uint32
+	ContextID       *byte
+}
+
+// NewSearchPrefVLV returns a search preference that makes the search a
+// virtual list view search with the given parameters. The preference refers
+// to the memory of vlv, which must be kept alive until the preference has
+// been applied. A virtual list view search must also be sorted.
+func NewSearchPrefVLV(vlv *ADS_VLV) ADS_SEARCHPREF_INFO {
+	info := ADS_SEARCHPREF_INFO{SearchPref: ADS_SEARCHPREF_VLV}
+	info.Value.Type = ADSTYPE_PROV_SPECIFIC
+	s := (*adsOctetString)(info.Value.union())
+	s.Length = uint32(unsafe.Sizeof(*vlv))
+	s.Value = (*byte)(unsafe.Pointer(vlv))
+	return info
+}
+
+// VLVValue interprets the value as the ADS_VLV structure held by the
+// ADS_VLV_RESPONSE column. The target and context ID refer to memory owned
+// by the column and are not returned.
+func (v *ADSVALUE) VLVValue() (vlv ADS_VLV) {
+	s := (*adsOctetString)(v.union())
+	if s.Value == nil || uintptr(s.Length) < unsafe.Sizeof(vlv) {
+		return
+	}
+	vlv = *(*ADS_VLV)(unsafe.Pointer(s.Value))
+	vlv.Target, vlv.ContextIDLength, vlv.ContextID = nil, 0, nil
+	return
+}
+
+// ADS_SEARCH_COLUMN holds the values of a single attribute in a row of
+// search results. Columns are allocated by IDirectorySearch.GetColumn and
+// must be released with IDirectorySearch.FreeColumn.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/ns-iads-ads_search_column
+type ADS_SEARCH_COLUMN struct {
+	AttrName  *uint16
+	ADsType   uint32
+	ADsValues *ADSVALUE
+	NumValues uint32
+	Reserved  uintptr
+}
+
+// Name returns the name of the attribute held by the column.
+func (c *ADS_SEARCH_COLUMN) Name() string {
+	return UTF16PtrToString(c.AttrName)
+}
+
+// ValueSlice returns the values held by the column. The returned slice refers
+// to memory owned by the column and is only valid until the column is freed.
+func (c *ADS_SEARCH_COLUMN) ValueSlice() []ADSVALUE {
+	if c.ADsValues == nil || c.NumValues == 0 {
+		return nil
+	}
+	return unsafe.Slice(c.ADsValues, c.NumValues)
+}
+
 // IDirectorySearchVtbl represents the component object model virtual
 // function table for the IDirectorySearch interface.
 type IDirectorySearchVtbl struct {
-	ole.IDispatchVtbl
-	SetSearchPreferences uintptr
-	ExecuteSearch        uintptr
-	AbandonSearch        uintptr
-	GetFirstRow          uintptr
-	GetNextRow           uintptr
-	GetPreviousRow       uintptr
-	GetNextColumnName    uintptr
-	GetColumn            uintptr
-	FreeColumn           uintptr
-	CloseSearchHandle    uintptr
+	ole.IUnknownVtbl
+	SetSearchPreference uintptr
+	ExecuteSearch       uintptr
+	AbandonSearch       uintptr
+	GetFirstRow         uintptr
+	GetNextRow          uintptr
+	GetPreviousRow      uintptr
+	GetNextColumnName   uintptr
+	GetColumn           uintptr
+	FreeColumn          uintptr
+	CloseSearchHandle   uintptr
 }
 
 // IDirectorySearch represents the component object model interface for
-// conducting directory searches.
+// conducting directory searches. Unlike most ADSI interfaces it derives from
+// IUnknown rather than IDispatch.
 type IDirectorySearch struct {
-	ole.IDispatch
+	ole.IUnknown
 }
 
 // VTable returns the component object model virtual function table for the
@@ -1,3 +1,78 @@
+//go:build !windows
 // +build !windows
 
 package api
+
+// SetSearchPreference specifies the preferences that will be used by
+// subsequent calls to ExecuteSearch. If any of the preferences could not be
+// applied ErrQueryFailed is returned and the Status member of the offending
+// preferences will indicate the reason.
+func (v *IDirectorySearch) SetSearchPreference(prefs []ADS_SEARCHPREF_INFO) (err error) {
+	return ErrUnsupported
+}
+
+// ExecuteSearch executes a search with the given LDAP filter and returns a
+// handle to its results. If no attribute names are provided all attributes
+// will be returned.
+//
+// The returned handle must be closed with CloseSearchHandle.
+func (v *IDirectorySearch) ExecuteSearch(filter string, attrs []string) (handle ADS_SEARCH_HANDLE, err error) {
+	return 0, ErrUnsupported
+}
+
+// AbandonSearch abandons a search that is in progress.
+func (v *IDirectorySearch) AbandonSearch(handle ADS_SEARCH_HANDLE) (err error) {
+	return ErrUnsupported
+}
+
+// GetFirstRow moves the search to the first row of its results. If there are
+// no rows ErrNoMoreRows is returned.
+func (v *IDirectorySearch) GetFirstRow(handle ADS_SEARCH_HANDLE) (err error) {
+	return ErrUnsupported
+}
+
+// GetNextRow moves the search to the next row of its results. If there are
+// no more rows ErrNoMoreRows is returned.
+func (v *IDirectorySearch) GetNextRow(handle ADS_SEARCH_HANDLE) (err error) {
+	return ErrUnsupported
+}
+
+// GetPreviousRow moves the search to the previous row of its results. It is
+// only supported when results are cached.
+func (v *IDirectorySearch) GetPreviousRow(handle ADS_SEARCH_HANDLE) (err error) {
+	return ErrUnsupported
+}
+
+// GetNextColumnName returns the name of the next column in the current row.
+// When there are no more columns ErrNoMoreColumns is returned.
+func (v *IDirectorySearch) GetNextColumnName(handle ADS_SEARCH_HANDLE) (name string, err error) {
+	return "", ErrUnsupported
+}
+
+// GetColumn retrieves the column with the given name from the current row.
+// The column must be released with FreeColumn when it is no longer needed.
+func (v *IDirectorySearch) GetColumn(handle ADS_SEARCH_HANDLE, name string, column *ADS_SEARCH_COLUMN) (err error) {
+	return ErrUnsupported
+}
+
+// GetNextColumn retrieves the next column of the current row. It is
+// equivalent to calling GetNextColumnName followed by GetColumn, but passes
+// the name returned by ADSI straight back to it instead of converting it.
+// The name of the column is available from its AttrName field. When there
+// are no more columns ErrNoMoreColumns is returned. The column must be
+// released with FreeColumn when it is no longer needed.
+func (v *IDirectorySearch) GetNextColumn(handle ADS_SEARCH_HANDLE, column *ADS_SEARCH_COLUMN) (err error) {
+	return ErrUnsupported
+}
+
+// FreeColumn releases the memory held by a column that was retrieved with
+// GetColumn.
+func (v *IDirectorySearch) FreeColumn(column *ADS_SEARCH_COLUMN) (err error) {
+	return ErrUnsupported
+}
+
+// CloseSearchHandle closes the handle to the results of a search and
+// releases its resources.
+func (v *IDirectorySearch) CloseSearchHandle(handle ADS_SEARCH_HANDLE) (err error) {
+	return ErrUnsupported
+}
@@ -1,3 +1,218 @@
+//go:build windows
 // +build windows
 
 package api
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// SetSearchPreference specifies the preferences that will be used by
+// subsequent calls to ExecuteSearch. If any of the preferences could not be
+// applied ErrQueryFailed is returned and the Status member of the offending
+// preferences will indicate the reason.
+func (v *IDirectorySearch) SetSearchPreference(prefs []ADS_SEARCHPREF_INFO) (err error) {
+	if len(prefs) == 0 {
+		return nil
+	}
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().SetSearchPreference),
+		3,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&prefs[0])),
+		uintptr(len(prefs)))
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return nil
+}
+
+// ExecuteSearch executes a search with the given LDAP filter and returns a
+// handle to its results. If no attribute names are provided all attributes
+// will be returned.
+//
+// The returned handle must be closed with CloseSearchHandle.
+func (v *IDirectorySearch) ExecuteSearch(filter string, attrs []string) (handle ADS_SEARCH_HANDLE, err error) {
+	bfilter, err := syscall.UTF16PtrFromString(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	// An attribute count of -1 requests all attributes
+	count := ^uintptr(0)
+	var names **uint16
+	if len(attrs) > 0 {
+		ptrs := make([]*uint16, len(attrs))
+		for i := range attrs {
+			if ptrs[i], err = syscall.UTF16PtrFromString(attrs[i]); err != nil {
+				return 0, err
+			}
+		}
+		names = &ptrs[0]
+		count = uintptr(len(ptrs))
+	}
+
+	hr, _, _ := syscall.Syscall6(
+		uintptr(v.VTable().ExecuteSearch),
+		5,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(bfilter)),
+		uintptr(unsafe.Pointer(names)),
+		count,
+		uintptr(unsafe.Pointer(&handle)),
+		0)
+	if hr != 0 {
+		return 0, convertHresultToError(hr)
+	}
+	return
+}
+
+// AbandonSearch abandons a search that is in progress.
+func (v *IDirectorySearch) AbandonSearch(handle ADS_SEARCH_HANDLE) (err error) {
+	return v.handleCall(v.VTable().AbandonSearch, handle)
+}
+
+// GetFirstRow moves the search to the first row of its results. If there are
+// no rows ErrNoMoreRows is returned.
+func (v *IDirectorySearch) GetFirstRow(handle ADS_SEARCH_HANDLE) (err error) {
+	return v.handleCall(v.VTable().GetFirstRow, handle)
+}
+
+// GetNextRow moves the search to the next row of its results. If there are
+// no more rows ErrNoMoreRows is returned.
+func (v *IDirectorySearch) GetNextRow(handle ADS_SEARCH_HANDLE) (err error) {
+	return v.handleCall(v.VTable().GetNextRow, handle)
+}
+
+// GetPreviousRow moves the search to the previous row of its results. It is
+// only supported when results are cached.
+func (v *IDirectorySearch) GetPreviousRow(handle ADS_SEARCH_HANDLE) (err error) {
+	return v.handleCall(v.VTable().GetPreviousRow, handle)
+}
+
+// GetNextColumnName returns the name of the next column in the current row.
+// When there are no more columns ErrNoMoreColumns is returned.
+func (v *IDirectorySearch) GetNextColumnName(handle ADS_SEARCH_HANDLE) (name string, err error) {
+	var pname *uint16
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().GetNextColumnName),
+		3,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(handle),
+		uintptr(unsafe.Pointer(&pname)))
+	if pname != nil {
+		defer freeADsMem(unsafe.Pointer(pname))
+	}
+	switch hr {
+	case 0:
+		return UTF16PtrToString(pname), nil
+	case S_ADS_NOMORE_COLUMNS:
+		return "", ErrNoMoreColumns
+	default:
+		return "", convertHresultToError(hr)
+	}
+}
+
+// GetColumn retrieves the column with the given name from the current row.
+// The column must be released with FreeColumn when it is no longer needed.
+func (v *IDirectorySearch) GetColumn(handle ADS_SEARCH_HANDLE, name string, column *ADS_SEARCH_COLUMN) (err error) {
+	bname, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return err
+	}
+	hr, _, _ := syscall.Syscall6(
+		uintptr(v.VTable().GetColumn),
+		4,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(handle),
+		uintptr(unsafe.Pointer(bname)),
+		uintptr(unsafe.Pointer(column)),
+		0,
+		0)
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return nil
+}
+
+// GetNextColumn retrieves the next column of the current row. It is
+// equivalent to calling GetNextColumnName followed by GetColumn, but passes
+// the name returned by ADSI straight back to it instead of converting it.
+// The name of the column is available from its AttrName field. When there
+// are no more columns ErrNoMoreColumns is returned. The column must be
+// released with FreeColumn when it is no longer needed.
+func (v *IDirectorySearch) GetNextColumn(handle ADS_SEARCH_HANDLE, column *ADS_SEARCH_COLUMN) (err error) {
+	var pname *uint16
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().GetNextColumnName),
+		3,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(handle),
+		uintptr(unsafe.Pointer(&pname)))
+	if pname != nil {
+		defer freeADsMem(unsafe.Pointer(pname))
+	}
+	switch hr {
+	case 0:
+	case S_ADS_NOMORE_COLUMNS:
+		return ErrNoMoreColumns
+	default:
+		return convertHresultToError(hr)
+	}
+	hr, _, _ = syscall.Syscall6(
+		uintptr(v.VTable().GetColumn),
+		4,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(handle),
+		uintptr(unsafe.Pointer(pname)),
+		uintptr(unsafe.Pointer(column)),
+		0,
+		0)
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return nil
+}
+
+// FreeColumn releases the memory held by a column that was retrieved with
+// GetColumn.
+func (v *IDirectorySearch) FreeColumn(column *ADS_SEARCH_COLUMN) (err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().FreeColumn),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(column)),
+		0)
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return nil
+}
+
+// CloseSearchHandle closes the handle to the results of a search and
+// releases its resources.
+func (v *IDirectorySearch) CloseSearchHandle(handle ADS_SEARCH_HANDLE) (err error) {
+	return v.handleCall(v.VTable().CloseSearchHandle, handle)
+}
+
+// handleCall invokes a virtual function that accepts a search handle as its
+// only argument. The S_ADS_NOMORE_ROWS success code is returned as
+// ErrNoMoreRows.
+func (v *IDirectorySearch) handleCall(fn uintptr, handle ADS_SEARCH_HANDLE) (err error) {
+	hr, _, _ := syscall.Syscall(
+		fn,
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(handle),
+		0)
+	switch hr {
+	case 0:
+		return nil
+	case S_ADS_NOMORE_ROWS:
+		return ErrNoMoreRows
+	default:
+		return convertHresultToError(hr)
+	}
+}
+
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Modification operations of an LDAP modify request.
+//
+// See https://learn.microsoft.com/windows/win32/api/winldap/ns-winldap-ldapmoda
+const (
+	LDAP_MOD_ADD     uint32 = 0x00
+	LDAP_MOD_DELETE  uint32 = 0x01
+	LDAP_MOD_REPLACE uint32 = 0x02
+	LDAP_MOD_BVALUES uint32 = 0x80
+)
+
+// Object identifiers of the Active Directory server controls that are used
+// with LDAPModify and LDAPNotify.
+//
+// See https://learn.microsoft.com/openspecs/windows_protocols/ms-adts/3c5e87db-4728-4f29-b164-01dd7d7391ea
+const (
+	LDAP_SERVER_SHOW_DELETED_OID      = "1.2.840.113556.1.4.417"
+	LDAP_SERVER_SHOW_RECYCLED_OID     = "1.2.840.113556.1.4.2064"
+	LDAP_SERVER_LAZY_COMMIT_OID       = "1.2.840.113556.1.4.619"
+	LDAP_SERVER_PERMISSIVE_MODIFY_OID = "1.2.840.113556.1.4.1413"
+	LDAP_SERVER_SD_FLAGS_OID          = "1.2.840.113556.1.4.801"
+	LDAP_SERVER_NOTIFICATION_OID      = "1.2.840.113556.1.4.528"
+)
+
+// LDAP result codes that are commonly returned by Active Directory.
+//
+// See https://learn.microsoft.com/windows/win32/api/winldap/ne-winldap-ldap_retcode
+const (
+	LDAP_SUCCESS              uint32 = 0x00
+	LDAP_NO_SUCH_ATTRIBUTE    uint32 = 0x10
+	LDAP_NO_SUCH_OBJECT       uint32 = 0x20
+	LDAP_INSUFFICIENT_RIGHTS  uint32 = 0x32
+	LDAP_UNWILLING_TO_PERFORM uint32 = 0x35
+	LDAP_ALREADY_EXISTS       uint32 = 0x44
+)
+
+// LDAPModification describes a single change made by LDAPModify.
+type LDAPModification struct {
+	// Op is one of the LDAP_MOD_* operations.
+	Op uint32
+
+	// Attr is the name of the attribute to modify.
+	Attr string
+
+	// Values holds the values to add, delete or replace. A delete operation
+	// with no values removes the attribute entirely.
+	Values []string
+
+	// BinaryValues holds binary values, such as security descriptors. If it
+	// is non-empty Values is ignored.
+	BinaryValues [][]byte
+}
+
+// LDAPControl is a server control sent with an LDAP request.
+type LDAPControl struct {
+	OID      string
+	Value    []byte
+	Critical bool
+}
+
+// LDAP search scopes.
+const (
+	LDAP_SCOPE_BASE     uint32 = 0x00
+	LDAP_SCOPE_ONELEVEL uint32 = 0x01
+	LDAP_SCOPE_SUBTREE  uint32 = 0x02
+)
+
+// LDAPEntry is an entry returned by an LDAP search. Attribute values are
+// returned in their raw binary form.
+type LDAPEntry struct {
+	DN         string
+	Attributes map[string][][]byte
+}
+
+// Values returns the values of the attribute with the given name, which is
+// matched case-insensitively.
+func (e *LDAPEntry) Values(name string) [][]byte {
+	if values, ok := e.Attributes[name]; ok {
+		return values
+	}
+	for attr, values := range e.Attributes {
+		if strings.EqualFold(attr, name) {
+			return values
+		}
+	}
+	return nil
+}
+
+// LDAPNotification is an outstanding change notification search started by
+// LDAPNotify. It must be closed when it is no longer needed. It is not safe
+// for concurrent use.
+type LDAPNotification struct {
+	ld    uintptr
+	msgid uint32
+}
+
+// LDAPError is an LDAP result code returned by the Windows LDAP client.
+type LDAPError uint32
+
+func (e LDAPError) Error() string {
+	if s := ldapErrorString(uint32(e)); s != "" {
+		return fmt.Sprintf("ldap: %s (0x%x)", s, uint32(e))
+	}
+	return fmt.Sprintf("ldap: error 0x%x", uint32(e))
+}
@@ -0,0 +1,51 @@
+//go:build !windows
+// +build !windows
+
+package api
+
+import "time"
+
+// LDAPModify connects to the given domain controller with the Windows LDAP
+// client, binds with the given credentials, and applies the given
+// modifications to the object with the given distinguished name. The given
+// server controls are sent with the request. If host is empty a domain
+// controller of the computer's domain is chosen by the client. If user is
+// empty the credentials of the calling thread are used. The user may be given
+// as a user principal name or in DOMAIN\user form.
+//
+// It is intended for operations that ADSI cannot express because they need
+// server controls, such as restoring deleted objects.
+func LDAPModify(host, user, password, dn string, mods []LDAPModification, controls []LDAPControl) error {
+	return ErrUnsupported
+}
+
+// LDAPNotify connects to the given domain controller in the same way as
+// LDAPModify, with the credentials of the calling thread, and starts an asynchronous search with the change notification
+// control, which remains outstanding until it is closed. Each time an object
+// within the scope of the search is changed the server returns it as a
+// search entry holding the given attributes, which can be read with
+// LDAPNotification.Next. Any additional server controls are sent with the
+// request.
+//
+// Active Directory only permits base and one-level scopes, or a subtree
+// scope rooted at the head of a naming context.
+func LDAPNotify(host, baseDN string, scope uint32, attrs []string, controls []LDAPControl) (n *LDAPNotification, err error) {
+	return nil, ErrUnsupported
+}
+
+// Next waits up to the given timeout for the next changed object and
+// returns it. If no object changes within the timeout nil is returned
+// without an error. If the server ends the search io.EOF or the error
+// reported by the server is returned.
+func (n *LDAPNotification) Next(timeout time.Duration) (entry *LDAPEntry, err error) {
+	return nil, ErrUnsupported
+}
+
+// Close abandons the search and closes the connection.
+func (n *LDAPNotification) Close() error {
+	return ErrUnsupported
+}
+
+func ldapErrorString(code uint32) string {
+	return ""
+}
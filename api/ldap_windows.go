@@ -0,0 +1,431 @@
+//go:build windows
+// +build windows
+
+package api
+
+import (
+	"io"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+var (
+	modwldap32 = syscall.NewLazyDLL("wldap32.dll")
+
+	procLdapInitW        = modwldap32.NewProc("ldap_initW")
+	procLdapSetOptionW   = modwldap32.NewProc("ldap_set_optionW")
+	procLdapBindSW       = modwldap32.NewProc("ldap_bind_sW")
+	procLdapModifyExtSW  = modwldap32.NewProc("ldap_modify_ext_sW")
+	procLdapSearchExtW   = modwldap32.NewProc("ldap_search_extW")
+	procLdapResult       = modwldap32.NewProc("ldap_result")
+	procLdapResult2Error = modwldap32.NewProc("ldap_result2error")
+	procLdapMsgfree      = modwldap32.NewProc("ldap_msgfree")
+	procLdapAbandon      = modwldap32.NewProc("ldap_abandon")
+	procLdapFirstEntry   = modwldap32.NewProc("ldap_first_entry")
+	procLdapGetDnW       = modwldap32.NewProc("ldap_get_dnW")
+	procLdapFirstAttrW   = modwldap32.NewProc("ldap_first_attributeW")
+	procLdapNextAttrW    = modwldap32.NewProc("ldap_next_attributeW")
+	procLdapGetValuesLen = modwldap32.NewProc("ldap_get_values_lenW")
+	procLdapCountValLen  = modwldap32.NewProc("ldap_count_values_len")
+	procLdapValueFreeLen = modwldap32.NewProc("ldap_value_free_len")
+	procLdapMemfreeW     = modwldap32.NewProc("ldap_memfreeW")
+	procBerFree          = modwldap32.NewProc("ber_free")
+	procLdapUnbind       = modwldap32.NewProc("ldap_unbind")
+	procLdapGetLastError = modwldap32.NewProc("LdapGetLastError")
+	procLdapErr2StringW  = modwldap32.NewProc("ldap_err2stringW")
+)
+
+const (
+	ldapPort               = 389
+	ldapAuthNegotiate      = 0x0486
+	ldapOptReferrals       = 0x08
+	ldapOptProtocolVersion = 0x11
+	ldapOptSign            = 0x95
+	ldapOptEncrypt         = 0x96
+	ldapOptOff             = 0
+	ldapOptOn              = 1
+	ldapMsgOne             = 0
+	ldapResSearchEntry     = 0x64
+	ldapResSearchResult    = 0x65
+	ldapResultFailed       = 0xFFFFFFFF
+
+	secWinNTAuthIdentityUnicode = 0x2
+)
+
+// ldapMod mirrors the LDAPModW structure.
+type ldapMod struct {
+	Op     uint32
+	Type   *uint16
+	Values **uint16
+}
+
+// berval mirrors the berval structure.
+type berval struct {
+	Len uint32
+	Val *byte
+}
+
+// ldapControl mirrors the LDAPControlW structure.
+type ldapControl struct {
+	OID      *uint16
+	ValueLen uint32
+	Value    *byte
+	Critical byte
+}
+
+// secWinNTAuthIdentity mirrors the SEC_WINNT_AUTH_IDENTITY_W structure.
+type secWinNTAuthIdentity struct {
+	User           *uint16
+	UserLength     uint32
+	Domain         *uint16
+	DomainLength   uint32
+	Password       *uint16
+	PasswordLength uint32
+	Flags          uint32
+}
+
+// ldapTimeval mirrors the l_timeval structure.
+type ldapTimeval struct {
+	Sec  int32
+	Usec int32
+}
+
+// LDAPModify connects to the given domain controller with the Windows LDAP
+// client, binds with the given credentials, and applies the given
+// modifications to the object with the given distinguished name. The given
+// server controls are sent with the request. If host is empty a domain
+// controller of the computer's domain is chosen by the client. If user is
+// empty the credentials of the calling thread are used. The user may be given
+// as a user principal name or in DOMAIN\user form.
+//
+// It is intended for operations that ADSI cannot express because they need
+// server controls, such as restoring deleted objects.
+func LDAPModify(host, user, password, dn string, mods []LDAPModification, controls []LDAPControl) error {
+	ld, err := ldapConnect(host, user, password)
+	if err != nil {
+		return err
+	}
+	defer procLdapUnbind.Call(ld)
+
+	dnPtr, err := syscall.UTF16PtrFromString(dn)
+	if err != nil {
+		return err
+	}
+
+	// Both lists are null-terminated arrays of pointers
+	modPtrs := make([]*ldapMod, 0, len(mods)+1)
+	for _, mod := range mods {
+		m := &ldapMod{Op: mod.Op}
+		if m.Type, err = syscall.UTF16PtrFromString(mod.Attr); err != nil {
+			return err
+		}
+		if len(mod.BinaryValues) > 0 {
+			values := make([]*berval, len(mod.BinaryValues)+1)
+			for i, value := range mod.BinaryValues {
+				values[i] = &berval{Len: uint32(len(value))}
+				if len(value) > 0 {
+					values[i].Val = &value[0]
+				}
+			}
+			m.Op |= LDAP_MOD_BVALUES
+			m.Values = (**uint16)(unsafe.Pointer(&values[0]))
+		} else if len(mod.Values) > 0 {
+			values := make([]*uint16, len(mod.Values)+1)
+			for i, value := range mod.Values {
+				if values[i], err = syscall.UTF16PtrFromString(value); err != nil {
+					return err
+				}
+			}
+			m.Values = &values[0]
+		}
+		modPtrs = append(modPtrs, m)
+	}
+	modPtrs = append(modPtrs, nil)
+
+	ctrlPtrs, err := ldapControls(controls)
+	if err != nil {
+		return err
+	}
+
+	r, _, _ := procLdapModifyExtSW.Call(
+		ld,
+		uintptr(unsafe.Pointer(dnPtr)),
+		uintptr(unsafe.Pointer(&modPtrs[0])),
+		ldapControlsArg(ctrlPtrs),
+		0)
+	runtime.KeepAlive(modPtrs)
+	runtime.KeepAlive(ctrlPtrs)
+	if r != 0 {
+		return LDAPError(r)
+	}
+	return nil
+}
+
+// LDAPNotify connects to the given domain controller in the same way as
+// LDAPModify, with the credentials of the calling thread, and starts an asynchronous search with the change notification
+// control, which remains outstanding until it is closed. Each time an object
+// within the scope of the search is changed the server returns it as a
+// search entry holding the given attributes, which can be read with
+// LDAPNotification.Next. Any additional server controls are sent with the
+// request.
+//
+// Active Directory only permits base and one-level scopes, or a subtree
+// scope rooted at the head of a naming context.
+func LDAPNotify(host, baseDN string, scope uint32, attrs []string, controls []LDAPControl) (n *LDAPNotification, err error) {
+	ld, err := ldapConnect(host, "", "")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			procLdapUnbind.Call(ld)
+		}
+	}()
+
+	basePtr, err := syscall.UTF16PtrFromString(baseDN)
+	if err != nil {
+		return nil, err
+	}
+	filterPtr, err := syscall.UTF16PtrFromString("(objectClass=*)")
+	if err != nil {
+		return nil, err
+	}
+	attrPtrs := make([]*uint16, len(attrs)+1)
+	for i, attr := range attrs {
+		if attrPtrs[i], err = syscall.UTF16PtrFromString(attr); err != nil {
+			return nil, err
+		}
+	}
+	controls = append([]LDAPControl{{OID: LDAP_SERVER_NOTIFICATION_OID, Critical: true}}, controls...)
+	ctrlPtrs, err := ldapControls(controls)
+	if err != nil {
+		return nil, err
+	}
+
+	var msgid uint32
+	r, _, _ := procLdapSearchExtW.Call(
+		ld,
+		uintptr(unsafe.Pointer(basePtr)),
+		uintptr(scope),
+		uintptr(unsafe.Pointer(filterPtr)),
+		uintptr(unsafe.Pointer(&attrPtrs[0])),
+		0,
+		ldapControlsArg(ctrlPtrs),
+		0,
+		0,
+		0,
+		uintptr(unsafe.Pointer(&msgid)))
+	runtime.KeepAlive(attrPtrs)
+	runtime.KeepAlive(ctrlPtrs)
+	if r != 0 {
+		return nil, LDAPError(r)
+	}
+	return &LDAPNotification{ld: ld, msgid: msgid}, nil
+}
+
+// Next waits up to the given timeout for the next changed object and
+// returns it. If no object changes within the timeout nil is returned
+// without an error. If the server ends the search io.EOF or the error
+// reported by the server is returned.
+func (n *LDAPNotification) Next(timeout time.Duration) (entry *LDAPEntry, err error) {
+	if n.ld == 0 {
+		return nil, io.EOF
+	}
+	tv := ldapTimeval{Sec: int32(timeout / time.Second), Usec: int32(timeout % time.Second / time.Microsecond)}
+	var res uintptr
+	r, _, _ := procLdapResult.Call(n.ld, uintptr(n.msgid), ldapMsgOne, uintptr(unsafe.Pointer(&tv)), uintptr(unsafe.Pointer(&res)))
+	switch uint32(r) {
+	case 0:
+		return nil, nil
+	case ldapResultFailed:
+		code, _, _ := procLdapGetLastError.Call()
+		return nil, LDAPError(code)
+	}
+	defer procLdapMsgfree.Call(res)
+	switch uint32(r) {
+	case ldapResSearchEntry:
+		return ldapReadEntry(n.ld, res), nil
+	case ldapResSearchResult:
+		if code, _, _ := procLdapResult2Error.Call(n.ld, res, 0); code != 0 {
+			return nil, LDAPError(code)
+		}
+		return nil, io.EOF
+	}
+	// Other messages, such as referrals, are ignored
+	return nil, nil
+}
+
+// Close abandons the search and closes the connection.
+func (n *LDAPNotification) Close() error {
+	if n.ld == 0 {
+		return nil
+	}
+	procLdapAbandon.Call(n.ld, uintptr(n.msgid))
+	rc, _, _ := procLdapUnbind.Call(n.ld)
+	n.ld = 0
+	if rc != 0 {
+		return LDAPError(rc)
+	}
+	return nil
+}
+
+// ldapReadEntry copies the first entry of a search result message.
+func ldapReadEntry(ld, res uintptr) *LDAPEntry {
+	e, _, _ := procLdapFirstEntry.Call(ld, res)
+	if e == 0 {
+		return nil
+	}
+	entry := &LDAPEntry{Attributes: make(map[string][][]byte)}
+	if dn, _, _ := procLdapGetDnW.Call(ld, e); dn != 0 {
+		entry.DN = UTF16PtrToString(*(**uint16)(unsafe.Pointer(&dn)))
+		procLdapMemfreeW.Call(dn)
+	}
+	var ber uintptr
+	attr, _, _ := procLdapFirstAttrW.Call(ld, e, uintptr(unsafe.Pointer(&ber)))
+	for attr != 0 {
+		name := UTF16PtrToString(*(**uint16)(unsafe.Pointer(&attr)))
+		if vals, _, _ := procLdapGetValuesLen.Call(ld, e, attr); vals != 0 {
+			count, _, _ := procLdapCountValLen.Call(vals)
+			bervals := unsafe.Slice(*(***berval)(unsafe.Pointer(&vals)), count)
+			values := make([][]byte, len(bervals))
+			for i, bv := range bervals {
+				values[i] = make([]byte, bv.Len)
+				if bv.Len > 0 {
+					copy(values[i], unsafe.Slice(bv.Val, bv.Len))
+				}
+			}
+			entry.Attributes[name] = values
+			procLdapValueFreeLen.Call(vals)
+		}
+		procLdapMemfreeW.Call(attr)
+		attr, _, _ = procLdapNextAttrW.Call(ld, e, ber)
+	}
+	if ber != 0 {
+		procBerFree.Call(ber, 0)
+	}
+	return entry
+}
+
+// ldapConnect connects to the given domain controller, enables signing and
+// sealing, and binds with the given credentials, or with those of the
+// calling thread if user is empty.
+func ldapConnect(host, user, password string) (ld uintptr, err error) {
+	var identity *secWinNTAuthIdentity
+	if user != "" {
+		if identity, err = newSecWinNTAuthIdentity(user, password); err != nil {
+			return 0, err
+		}
+	}
+
+	ld, _, _ = procLdapInitW.Call(uintptr(unsafe.Pointer(utf16PtrOrNil(host))), ldapPort)
+	if ld == 0 {
+		r, _, _ := procLdapGetLastError.Call()
+		return 0, LDAPError(r)
+	}
+	defer func() {
+		if err != nil {
+			procLdapUnbind.Call(ld)
+		}
+	}()
+
+	version := uint32(3)
+	if err = ldapSetOption(ld, ldapOptProtocolVersion, uintptr(unsafe.Pointer(&version))); err != nil {
+		return 0, err
+	}
+	for _, option := range []uintptr{ldapOptSign, ldapOptEncrypt} {
+		if err = ldapSetOption(ld, option, ldapOptOn); err != nil {
+			return 0, err
+		}
+	}
+	if err = ldapSetOption(ld, ldapOptReferrals, ldapOptOff); err != nil {
+		return 0, err
+	}
+	r, _, _ := procLdapBindSW.Call(ld, 0, uintptr(unsafe.Pointer(identity)), ldapAuthNegotiate)
+	runtime.KeepAlive(identity)
+	if r != 0 {
+		err = LDAPError(r)
+		return 0, err
+	}
+	return ld, nil
+}
+
+// newSecWinNTAuthIdentity returns the explicit credentials of a bind. A user
+// in DOMAIN\user form is split into its domain and user name.
+func newSecWinNTAuthIdentity(user, password string) (identity *secWinNTAuthIdentity, err error) {
+	var domain string
+	if i := strings.IndexByte(user, '\\'); i >= 0 {
+		domain, user = user[:i], user[i+1:]
+	}
+	identity = &secWinNTAuthIdentity{Flags: secWinNTAuthIdentityUnicode}
+	if identity.User, identity.UserLength, err = utf16PtrAndLength(user); err != nil {
+		return nil, err
+	}
+	if identity.Domain, identity.DomainLength, err = utf16PtrAndLength(domain); err != nil {
+		return nil, err
+	}
+	if identity.Password, identity.PasswordLength, err = utf16PtrAndLength(password); err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// utf16PtrAndLength returns a pointer to the null-terminated UTF-16 form of s
+// and its length in characters, excluding the terminator. An empty string
+// yields a nil pointer.
+func utf16PtrAndLength(s string) (ptr *uint16, length uint32, err error) {
+	if s == "" {
+		return nil, 0, nil
+	}
+	u, err := syscall.UTF16FromString(s)
+	if err != nil {
+		return nil, 0, err
+	}
+	return &u[0], uint32(len(u) - 1), nil
+}
+
+// ldapControls converts the given controls to a null-terminated array of
+// LDAPControlW pointers.
+func ldapControls(controls []LDAPControl) (ptrs []*ldapControl, err error) {
+	ptrs = make([]*ldapControl, 0, len(controls)+1)
+	for _, control := range controls {
+		c := &ldapControl{ValueLen: uint32(len(control.Value))}
+		if c.OID, err = syscall.UTF16PtrFromString(control.OID); err != nil {
+			return nil, err
+		}
+		if len(control.Value) > 0 {
+			c.Value = &control.Value[0]
+		}
+		if control.Critical {
+			c.Critical = 1
+		}
+		ptrs = append(ptrs, c)
+	}
+	return append(ptrs, nil), nil
+}
+
+// ldapControlsArg returns the argument that passes the given control array
+// to the LDAP client, which is null if the array holds no controls.
+func ldapControlsArg(ptrs []*ldapControl) uintptr {
+	if len(ptrs) <= 1 {
+		return 0
+	}
+	return uintptr(unsafe.Pointer(&ptrs[0]))
+}
+
+func ldapSetOption(ld, option, value uintptr) error {
+	if r, _, _ := procLdapSetOptionW.Call(ld, option, value); r != 0 {
+		return LDAPError(r)
+	}
+	return nil
+}
+
+func ldapErrorString(code uint32) string {
+	if procLdapErr2StringW.Find() != nil {
+		return ""
+	}
+	r, _, _ := procLdapErr2StringW.Call(uintptr(code))
+	return UTF16PtrToString(*(**uint16)(unsafe.Pointer(&r)))
+}
@@ -0,0 +1,9 @@
+package api
+
+// Flags that can be passed to NCryptUnprotectSecret.
+//
+// See https://learn.microsoft.com/windows/win32/api/ncryptprotect/nf-ncryptprotect-ncryptunprotectsecret
+const (
+	NCRYPT_SILENT_FLAG          uint32 = 0x00000040
+	NCRYPT_UNPROTECT_NO_DECRYPT uint32 = 0x00000001
+)
@@ -0,0 +1,11 @@
+//go:build !windows
+// +build !windows
+
+package api
+
+// NCryptUnprotectSecret decrypts a blob protected with DPAPI-NG, such as the
+// encrypted password of Windows LAPS, using the credentials of the calling
+// thread. It returns the decrypted data.
+func NCryptUnprotectSecret(blob []byte, flags uint32) (data []byte, err error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,44 @@
+//go:build windows
+// +build windows
+
+package api
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var (
+	modncrypt   = syscall.NewLazyDLL("ncrypt.dll")
+	modkernel32 = syscall.NewLazyDLL("kernel32.dll")
+
+	procNCryptUnprotectSecret = modncrypt.NewProc("NCryptUnprotectSecret")
+	procLocalFree             = modkernel32.NewProc("LocalFree")
+)
+
+// NCryptUnprotectSecret decrypts a blob protected with DPAPI-NG, such as the
+// encrypted password of Windows LAPS, using the credentials of the calling
+// thread. It returns the decrypted data.
+func NCryptUnprotectSecret(blob []byte, flags uint32) (data []byte, err error) {
+	if len(blob) == 0 {
+		return nil, syscall.EINVAL
+	}
+	var (
+		out    *byte
+		outLen uint32
+	)
+	r, _, _ := procNCryptUnprotectSecret.Call(
+		0,
+		uintptr(flags),
+		uintptr(unsafe.Pointer(&blob[0])),
+		uintptr(len(blob)),
+		0,
+		0,
+		uintptr(unsafe.Pointer(&out)),
+		uintptr(unsafe.Pointer(&outLen)))
+	if r != 0 {
+		return nil, convertHresultToError(r)
+	}
+	defer procLocalFree.Call(uintptr(unsafe.Pointer(out)))
+	return copyBytes(out, outLen), nil
+}
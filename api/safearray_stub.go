@@ -0,0 +1,22 @@
+//go:build !windows
+// +build !windows
+
+package api
+
+import (
+	"unsafe"
+
+	ole "github.com/go-ole/go-ole"
+)
+
+// SafeArrayAccessData locks the array and returns a pointer to its elements,
+// which can then be read in place. The array must be unlocked with
+// SafeArrayUnaccessData once the elements are no longer needed.
+func SafeArrayAccessData(array *ole.SafeArray) (data unsafe.Pointer, err error) {
+	return nil, ErrUnsupported
+}
+
+// SafeArrayUnaccessData unlocks an array locked by SafeArrayAccessData.
+func SafeArrayUnaccessData(array *ole.SafeArray) (err error) {
+	return ErrUnsupported
+}
@@ -0,0 +1,37 @@
+//go:build windows
+// +build windows
+
+package api
+
+import (
+	"syscall"
+	"unsafe"
+
+	ole "github.com/go-ole/go-ole"
+)
+
+var (
+	modoleaut32 = syscall.NewLazyDLL("oleaut32.dll")
+
+	procSafeArrayAccessData   = modoleaut32.NewProc("SafeArrayAccessData")
+	procSafeArrayUnaccessData = modoleaut32.NewProc("SafeArrayUnaccessData")
+)
+
+// SafeArrayAccessData locks the array and returns a pointer to its elements,
+// which can then be read in place. The array must be unlocked with
+// SafeArrayUnaccessData once the elements are no longer needed.
+func SafeArrayAccessData(array *ole.SafeArray) (data unsafe.Pointer, err error) {
+	hr, _, _ := procSafeArrayAccessData.Call(
+		uintptr(unsafe.Pointer(array)),
+		uintptr(unsafe.Pointer(&data)))
+	if hr != 0 {
+		return nil, convertHresultToError(hr)
+	}
+	return data, nil
+}
+
+// SafeArrayUnaccessData unlocks an array locked by SafeArrayAccessData.
+func SafeArrayUnaccessData(array *ole.SafeArray) (err error) {
+	hr, _, _ := procSafeArrayUnaccessData.Call(uintptr(unsafe.Pointer(array)))
+	return convertHresultToError(hr)
+}
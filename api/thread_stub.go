@@ -0,0 +1,9 @@
+//go:build !windows
+// +build !windows
+
+package api
+
+// CurrentThreadID returns the identifier of the calling thread.
+func CurrentThreadID() uint32 {
+	return 0
+}
@@ -0,0 +1,12 @@
+//go:build windows
+// +build windows
+
+package api
+
+var procGetCurrentThreadId = modkernel32.NewProc("GetCurrentThreadId")
+
+// CurrentThreadID returns the identifier of the calling thread.
+func CurrentThreadID() uint32 {
+	id, _, _ := procGetCurrentThreadId.Call()
+	return uint32(id)
+}
@@ -0,0 +1,27 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/go-ole/go-ole"
+)
+
+// ErrUnsupported is returned by every function and method of this package
+// that calls into ADSI when the program is built for an operating system
+// other than Windows.
+//
+// It matches errors.ErrUnsupported, and it carries the E_NOTIMPL HRESULT of
+// an *ole.OleError for callers that inspect the code of COM errors.
+var ErrUnsupported error = unsupportedError{}
+
+type unsupportedError struct{}
+
+func (unsupportedError) Error() string {
+	return "ADSI is not supported on this platform"
+}
+
+func (unsupportedError) Unwrap() []error {
+	return []error{errors.ErrUnsupported, errNotImplemented}
+}
+
+var errNotImplemented = ole.NewError(ole.E_NOTIMPL)
@@ -0,0 +1,100 @@
+package adsi
+
+import (
+	"strconv"
+	"strings"
+)
+
+// AttributeSet is a named selection of commonly requested attributes, for use
+// as the Attributes of a Query:
+//
+//	q := adsi.Query{
+//		Filter:     "(objectCategory=person)",
+//		Attributes: adsi.AttributesIdentity.Names("title"),
+//	}
+//
+// The ADsPath of each object is returned by every search and is not part of
+// any set.
+type AttributeSet int
+
+// Attribute sets.
+const (
+	// AttributesMinimal identifies objects and their classes.
+	AttributesMinimal AttributeSet = iota + 1
+
+	// AttributesIdentity adds the names by which users, groups and computers
+	// are known, and their security identifiers.
+	AttributesIdentity
+
+	// AttributesSecurity adds the attributes that determine the access and
+	// privileges of security principals: account control flags, group
+	// memberships, delegation settings and the security descriptor.
+	AttributesSecurity
+
+	// AttributesAll requests every attribute with a value, by way of "*".
+	// Constructed attributes are not included in "*" and must be requested
+	// by name.
+	AttributesAll
+)
+
+var attributeSets = map[AttributeSet][]string{
+	AttributesMinimal: {
+		"distinguishedName", "name", "objectClass", "objectGUID",
+	},
+	AttributesIdentity: {
+		"distinguishedName", "name", "objectClass", "objectGUID",
+		"objectSid", "sAMAccountName", "userPrincipalName", "displayName",
+		"cn", "mail", "dNSHostName",
+	},
+	AttributesSecurity: {
+		"distinguishedName", "name", "objectClass", "objectGUID",
+		"objectSid", "sAMAccountName", "userPrincipalName", "sAMAccountType",
+		"userAccountControl", "adminCount", "primaryGroupID", "memberOf",
+		"pwdLastSet", "lastLogonTimestamp", "servicePrincipalName",
+		"msDS-AllowedToDelegateTo", "msDS-AllowedToActOnBehalfOfOtherIdentity",
+		"nTSecurityDescriptor",
+	},
+	AttributesAll: {"*"},
+}
+
+// Names returns the names of the attributes in the set followed by the given
+// extra attributes, such as constructed attributes to be returned on top of
+// AttributesAll. Extra attributes that are already in the set are not
+// repeated. The returned slice belongs to the caller.
+func (s AttributeSet) Names(extra ...string) []string {
+	set := attributeSets[s]
+	names := make([]string, len(set), len(set)+len(extra))
+	copy(names, set)
+	for _, name := range extra {
+		if !containsFold(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// String returns the name of the set, such as "Identity".
+func (s AttributeSet) String() string {
+	switch s {
+	case AttributesMinimal:
+		return "Minimal"
+	case AttributesIdentity:
+		return "Identity"
+	case AttributesSecurity:
+		return "Security"
+	case AttributesAll:
+		return "All"
+	default:
+		return "AttributeSet(" + strconv.Itoa(int(s)) + ")"
+	}
+}
+
+// containsFold returns true if names contains name, ignoring case.
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
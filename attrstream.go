@@ -0,0 +1,193 @@
+package adsi
+
+import (
+	"io"
+	"sync"
+	"unsafe"
+
+	"github.com/go-adsi/adsi/api"
+	ole "github.com/go-ole/go-ole"
+	"github.com/scjalliance/comshim"
+	"github.com/scjalliance/comutil"
+)
+
+// Large octet string attributes, such as thumbnailPhoto and
+// userSMIMECertificate, can hold values of several megabytes. ADSI returns
+// them in a single variant, which Attr copies into a byte slice. The
+// functions in this file read the value in place instead, so that it is
+// never held in Go memory in its entirety.
+
+// WriteAttrTo writes the first value of the octet string attribute with the
+// given name to w, directly from the memory ADSI returned it in, and returns
+// the number of bytes written. It returns an error matching
+// ErrNonOctetStringAttribute if the attribute holds values of another type.
+//
+// The value is written from the object's worker, so w must not call methods
+// of the object.
+func (o *object) WriteAttrTo(name string, w io.Writer) (n int64, err error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return 0, ErrClosed
+	}
+	o.h.run(func() {
+		var v *octetValue
+		if v, err = o.octetValue(name); err != nil {
+			return
+		}
+		defer v.release()
+		var written int
+		written, err = w.Write(v.data)
+		n = int64(written)
+	})
+	return
+}
+
+// AttrReader returns a reader over the first value of the octet string
+// attribute with the given name, which reads the value in place from the
+// memory ADSI returned it in. It returns an error matching
+// ErrNonOctetStringAttribute if the attribute holds values of another type.
+//
+// The reader remains valid after the object is closed. It holds the value
+// until it is closed. It is the caller's responsibilty to call Close on the
+// returned reader when it is no longer needed.
+func (o *object) AttrReader(name string) (r io.ReadCloser, err error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return nil, ErrClosed
+	}
+	o.h.run(func() {
+		var v *octetValue
+		if v, err = o.octetValue(name); err == nil {
+			comshim.Add(1)
+			r = &attrReader{v: v}
+		}
+	})
+	return
+}
+
+// octetValue holds the variant returned for an attribute, with its first
+// octet string value locked for reading in place.
+type octetValue struct {
+	variant *ole.VARIANT
+	array   *ole.SafeArray
+	data    []byte
+}
+
+// octetValue retrieves the attribute with the given name and locks its first
+// value for reading. It must be called on the object's worker. The caller
+// must release the returned value.
+func (o *object) octetValue(name string) (v *octetValue, err error) {
+	if err = o.cache.check(name); err != nil {
+		return nil, err
+	}
+	defer beginCall()()
+	variant, err := o.iface.GetEx(name)
+	if err != nil {
+		return nil, o.err("GetEx "+name, err)
+	}
+	v = &octetValue{variant: variant}
+	defer func() {
+		if err != nil {
+			v.release()
+			v = nil
+		}
+	}()
+
+	values := variant.ToArray()
+	if values == nil {
+		return nil, ErrNonArrayAttribute
+	}
+	vt, err := values.GetType()
+	if err != nil {
+		return nil, err
+	}
+	if ole.VT(vt) != ole.VT_VARIANT {
+		return nil, comutil.ErrNonVariantArray
+	}
+	if n, err := values.TotalElements(0); err != nil || n <= 0 {
+		return nil, ErrNonOctetStringAttribute
+	}
+	elements, err := api.SafeArrayAccessData(values.Array)
+	if err != nil {
+		return nil, err
+	}
+	first := (*ole.VARIANT)(elements)
+	kind, array := first.VT, *(**ole.SafeArray)(unsafe.Pointer(&first.Val))
+	api.SafeArrayUnaccessData(values.Array)
+	if kind != ole.VT_ARRAY|ole.VT_UI1 || array == nil {
+		return nil, ErrNonOctetStringAttribute
+	}
+
+	length, err := (&ole.SafeArrayConversion{Array: array}).TotalElements(0)
+	if err != nil {
+		return nil, err
+	}
+	data, err := api.SafeArrayAccessData(array)
+	if err != nil {
+		return nil, err
+	}
+	v.array = array
+	if length > 0 {
+		v.data = unsafe.Slice((*byte)(data), length)
+	}
+	return v, nil
+}
+
+// release unlocks the value and frees the variant that holds it.
+func (v *octetValue) release() {
+	if v.array != nil {
+		api.SafeArrayUnaccessData(v.array)
+		v.array, v.data = nil, nil
+	}
+	v.variant.Clear()
+}
+
+// attrReader reads an octet string value in place.
+type attrReader struct {
+	m   sync.Mutex
+	v   *octetValue
+	off int
+}
+
+// Read reads the next bytes of the value.
+func (r *attrReader) Read(p []byte) (n int, err error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	if r.v == nil {
+		return 0, ErrClosed
+	}
+	if r.off >= len(r.v.data) {
+		return 0, io.EOF
+	}
+	n = copy(p, r.v.data[r.off:])
+	r.off += n
+	return n, nil
+}
+
+// WriteTo writes the rest of the value to w. It implements io.WriterTo, so
+// that io.Copy writes the value without an intermediate buffer.
+func (r *attrReader) WriteTo(w io.Writer) (n int64, err error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	if r.v == nil {
+		return 0, ErrClosed
+	}
+	written, err := w.Write(r.v.data[r.off:])
+	r.off += written
+	return int64(written), err
+}
+
+// Close releases the value.
+func (r *attrReader) Close() error {
+	r.m.Lock()
+	defer r.m.Unlock()
+	if r.v == nil {
+		return nil
+	}
+	r.v.release()
+	r.v = nil
+	comshim.Done()
+	return nil
+}
@@ -0,0 +1,141 @@
+package adsi
+
+import (
+	"strconv"
+	"time"
+)
+
+// AuditOp identifies the kind of write operation reported to an audit hook.
+type AuditOp int
+
+// Audited operations.
+const (
+	// AuditPut reports a change to the attribute cache of an object, which
+	// is not written to the directory until SetInfo is called.
+	AuditPut AuditOp = iota + 1
+
+	// AuditSetInfo reports the commit of an object's attribute cache. The
+	// attributes are those that were put since the previous commit.
+	AuditSetInfo
+
+	AuditCreate
+	AuditDelete
+	AuditMove
+	AuditAddMember
+	AuditRemoveMember
+	AuditSetPassword
+
+	// AuditRestore reports the restore of a deleted object. Source is the
+	// ADsPath of the deleted object and Path the ADsPath it was restored to.
+	AuditRestore
+)
+
+// String returns the name of the operation.
+func (op AuditOp) String() string {
+	switch op {
+	case AuditPut:
+		return "Put"
+	case AuditSetInfo:
+		return "SetInfo"
+	case AuditCreate:
+		return "Create"
+	case AuditDelete:
+		return "Delete"
+	case AuditMove:
+		return "Move"
+	case AuditAddMember:
+		return "AddMember"
+	case AuditRemoveMember:
+		return "RemoveMember"
+	case AuditSetPassword:
+		return "SetPassword"
+	case AuditRestore:
+		return "Restore"
+	default:
+		return "AuditOp(" + strconv.Itoa(int(op)) + ")"
+	}
+}
+
+// AuditEvent describes a write operation performed through a client.
+type AuditEvent struct {
+	Op AuditOp
+
+	// Path is the ADsPath of the object that was changed. For Create, Delete
+	// and Move it is the path of the container, and Class and Name identify
+	// the child object by its class and relative name.
+	Path  string
+	Class string
+	Name  string
+
+	// Source is the ADsPath of the object that was moved or restored.
+	Source string
+
+	// Attributes lists the names of the attributes that were changed. For
+	// AddMember and RemoveMember it holds the ADsPath of the member. Values
+	// are never included, so that secrets are not written to audit logs.
+	Attributes []string
+
+	// Time is the time at which the operation completed, and Duration is
+	// the time it took.
+	Time     time.Time
+	Duration time.Duration
+
+	// Err is the outcome of the operation. It is nil if the operation
+	// succeeded.
+	Err error
+}
+
+// AuditFunc is a function that receives audit events. It is called
+// synchronously after each operation and must not call back into the
+// object or container that was changed.
+type AuditFunc func(AuditEvent)
+
+// SetAuditHook sets a function that is called for every write operation
+// made through objects and containers opened by the client, including those
+// derived from them with methods such as ToUser, Create and Children. It
+// applies only to objects opened after it is called. A nil function
+// disables auditing.
+func (c *Client) SetAuditHook(fn AuditFunc) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	h := c.h.clone()
+	h.audit = fn
+	c.h = h
+}
+
+// audit reports an operation on the object to the audit hook and logger, if
+// there are any. The caller must hold the object's lock.
+func (o *object) audit(op AuditOp, start time.Time, attrs []string, err error) {
+	if !o.h.auditing() {
+		return
+	}
+	path, _ := o.iface.AdsPath()
+	o.h.write(start, AuditEvent{Op: op, Path: path, Attributes: attrs, Err: err})
+}
+
+// auditPut reports a change to the attribute cache and records the attribute
+// so that it is reported again by the next SetInfo. It also marks the
+// attribute as loaded for objects on which NoImplicitGetInfo has been
+// called. The caller must hold the object's lock.
+func (o *object) auditPut(name string, start time.Time, err error) {
+	if err == nil {
+		o.cache.add(name)
+	}
+	if !o.h.auditing() {
+		return
+	}
+	if err == nil {
+		o.pending = append(o.pending, name)
+	}
+	o.audit(AuditPut, start, []string{name}, err)
+}
+
+// audit reports an operation on a child of the container to the audit hook
+// and logger, if there are any. The caller must hold the container's lock.
+func (c *Container) audit(start time.Time, e AuditEvent) {
+	if !c.h.auditing() {
+		return
+	}
+	e.Path = c.path()
+	c.h.write(start, e)
+}
@@ -0,0 +1,133 @@
+package adsi
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidRecoveryKeyID is returned when a BitLocker recovery key ID is
+// neither a full GUID nor the eight character prefix shown on the recovery
+// screen.
+var ErrInvalidRecoveryKeyID = errors.New("invalid BitLocker recovery key ID")
+
+// bitLockerFilter matches BitLocker recovery information objects.
+const bitLockerFilter = "(objectClass=msFVE-RecoveryInformation)"
+
+var bitLockerAttrs = []string{
+	"distinguishedName", "msFVE-RecoveryGuid", "msFVE-VolumeGuid",
+	"msFVE-RecoveryPassword", "whenCreated",
+}
+
+// BitLockerRecovery is a BitLocker recovery password escrowed to the
+// directory as an msFVE-RecoveryInformation child of a computer.
+type BitLockerRecovery struct {
+	DN string
+
+	// ComputerDN is the distinguished name of the computer the recovery
+	// information belongs to.
+	ComputerDN string
+
+	// RecoveryGUID identifies the recovery password. Its first eight
+	// characters are the key ID shown on the BitLocker recovery screen.
+	RecoveryGUID uuid.UUID
+
+	// VolumeGUID identifies the protected volume.
+	VolumeGUID uuid.UUID
+
+	// Password is the 48 digit recovery password.
+	Password string
+
+	Created time.Time
+}
+
+// KeyID returns the short key ID shown on the BitLocker recovery screen,
+// which is the first eight characters of the recovery GUID in upper case.
+func (r *BitLockerRecovery) KeyID() string {
+	return strings.ToUpper(r.RecoveryGUID.String()[:8])
+}
+
+func bitLockerRecoveryFromRow(row *Row) *BitLockerRecovery {
+	dn := row.AttrString("distinguishedName")
+	return &BitLockerRecovery{
+		DN:           dn,
+		ComputerDN:   parentDN(dn),
+		RecoveryGUID: guidFromWindowsBytes(row.AttrBytes("msFVE-RecoveryGuid")),
+		VolumeGUID:   guidFromWindowsBytes(row.AttrBytes("msFVE-VolumeGuid")),
+		Password:     row.AttrString("msFVE-RecoveryPassword"),
+		Created:      row.AttrTime("whenCreated"),
+	}
+}
+
+// bitLockerRecoveries converts the rows to recovery information ordered
+// from the most recently created.
+func bitLockerRecoveries(rows []*Row) (recoveries []*BitLockerRecovery) {
+	for _, row := range rows {
+		recoveries = append(recoveries, bitLockerRecoveryFromRow(row))
+	}
+	sort.SliceStable(recoveries, func(i, j int) bool {
+		return recoveries[i].Created.After(recoveries[j].Created)
+	})
+	return
+}
+
+// BitLockerRecovery returns the BitLocker recovery information escrowed
+// beneath the computer, most recently created first. Reading the recovery
+// passwords requires the caller to have been granted access to them, which
+// is normally limited to domain administrators. Entries the caller cannot
+// read are returned with an empty Password.
+func (c *Computer) BitLockerRecovery() (recoveries []*BitLockerRecovery, err error) {
+	dn, err := c.DN()
+	if err != nil {
+		return
+	}
+	rows, err := c.searchDNAll(dn, Query{
+		Filter:     bitLockerFilter,
+		Attributes: bitLockerAttrs,
+		Scope:      ScopeOneLevel,
+	})
+	if err != nil {
+		return
+	}
+	return bitLockerRecoveries(rows), nil
+}
+
+// FindBitLockerRecovery searches the domain for the BitLocker recovery
+// information with the given key ID. The key ID may be a full recovery GUID,
+// or the eight character prefix of it shown on the BitLocker recovery
+// screen, which may match more than one entry.
+func (d *Domain) FindBitLockerRecovery(keyID string) (recoveries []*BitLockerRecovery, err error) {
+	keyID = strings.Trim(strings.TrimSpace(keyID), "{}")
+	var filter string
+	if guid, parseErr := uuid.Parse(keyID); parseErr == nil {
+		filter = "(&" + bitLockerFilter + "(msFVE-RecoveryGuid=" + EscapeFilterBytes(windowsBytesFromGUID(guid)) + "))"
+	} else if len(keyID) == 8 && isHexString(keyID) {
+		// The recovery GUID forms the end of the object's name
+		filter = "(&" + bitLockerFilter + "(name=*{" + keyID + "-*))"
+	} else {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRecoveryKeyID, keyID)
+	}
+
+	domain, err := d.DN()
+	if err != nil {
+		return
+	}
+	rows, err := d.searchDNAll(domain, Query{Filter: filter, Attributes: bitLockerAttrs})
+	if err != nil {
+		return
+	}
+	return bitLockerRecoveries(rows), nil
+}
+
+func isHexString(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isHex(s[i]) {
+			return false
+		}
+	}
+	return true
+}
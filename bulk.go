@@ -0,0 +1,210 @@
+package adsi
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/go-adsi/adsi/adspath"
+	"github.com/go-adsi/adsi/api"
+)
+
+// DefaultBulkWorkers is the number of worker goroutines used by Bulk when
+// BulkOptions does not specify one.
+const DefaultBulkWorkers = 8
+
+// CreateSpec describes an object to be created by Bulk.
+type CreateSpec struct {
+	// Parent is the distinguished name of the container the object is
+	// created in.
+	Parent string
+
+	// Class is the object class, such as "user" or "group".
+	Class string
+
+	// Name is the relative distinguished name of the object, such as
+	// "CN=Jane Doe". The value must already be escaped.
+	Name string
+
+	// Attributes maps attribute names to their values. The values may be of
+	// any type accepted by PutEx.
+	Attributes map[string][]interface{}
+
+	// Password, if set, is set as the initial password of the object after
+	// it has been created, and the account is then enabled. It is only
+	// meaningful for users and computers.
+	Password string
+
+	// MustChangePassword requires the user to change the initial password
+	// at next logon.
+	MustChangePassword bool
+}
+
+// CreateResult reports the outcome of a single CreateSpec processed by
+// Bulk.
+type CreateResult struct {
+	// Index is the position of the spec in the input stream, starting at
+	// zero. Results are delivered in the order they complete, so Index is
+	// needed to correlate them with their specs.
+	Index int
+
+	Spec CreateSpec
+
+	// DN is the distinguished name of the created object.
+	DN string
+
+	Err error
+}
+
+// BulkOptions controls the connections and concurrency used by Bulk.
+type BulkOptions struct {
+	// Server is the domain controller on which every object is created.
+	// Specifying a server lets all workers share its connection and avoids
+	// replication delays between dependent objects. If empty a serverless
+	// binding is used.
+	Server string
+
+	// User and Password are the credentials used to bind. If empty the
+	// security context of the application is used.
+	User     string
+	Password string
+
+	// Workers is the number of objects created concurrently. If zero
+	// DefaultBulkWorkers is used.
+	Workers int
+}
+
+// Bulk creates the objects described by the specs received from the given
+// channel and reports the outcome of each on the returned channel, which is
+// closed once the specs channel has been closed and every spec has been
+// processed. The caller must drain the returned channel.
+//
+// Each worker goroutine binds to the parent containers it needs once, with
+// ADS_FAST_BIND so that no extra round trips are spent verifying them, and
+// reuses those bindings for every subsequent object. ADSI multiplexes all
+// bindings with the same server and credentials over a single connection.
+// If an object is created but its password cannot be set, the object is
+// deleted again and the error is reported.
+func (c *Client) Bulk(opts BulkOptions, specs <-chan CreateSpec) <-chan CreateResult {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = DefaultBulkWorkers
+	}
+	flags := fastBindFlags(opts.Server)
+
+	type job struct {
+		index int
+		spec  CreateSpec
+	}
+	jobs := make(chan job)
+	results := make(chan CreateResult)
+	go func() {
+		defer close(jobs)
+		index := 0
+		for spec := range specs {
+			jobs <- job{index: index, spec: spec}
+			index++
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := &bulkWorker{client: c, opts: opts, flags: flags, parents: make(map[string]*Container)}
+			defer w.close()
+			for j := range jobs {
+				dn, err := w.create(&j.spec)
+				results <- CreateResult{Index: j.index, Spec: j.spec, DN: dn, Err: err}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
+
+// fastBindFlags returns the flags used to bind to objects for writing
+// without verifying them first. If server is not empty it is bound to
+// directly.
+func fastBindFlags(server string) uint32 {
+	flags := uint32(api.ADS_SECURE_AUTHENTICATION | api.ADS_USE_SEALING | api.ADS_FAST_BIND)
+	if server != "" {
+		flags |= api.ADS_SERVER_BIND
+	}
+	return flags
+}
+
+// bulkWorker holds the container bindings of a single Bulk worker.
+type bulkWorker struct {
+	client  *Client
+	opts    BulkOptions
+	flags   uint32
+	parents map[string]*Container
+}
+
+func (w *bulkWorker) close() {
+	for _, container := range w.parents {
+		container.Close()
+	}
+}
+
+// parent returns the binding for the container with the given
+// distinguished name, opening it if necessary.
+func (w *bulkWorker) parent(dn string) (*Container, error) {
+	if container, ok := w.parents[dn]; ok {
+		return container, nil
+	}
+	path := (&adspath.Path{Scheme: "LDAP", Host: w.opts.Server, Path: adspath.EscapeDN(dn)}).String()
+	container, err := w.client.OpenContainerSC(path, w.opts.User, w.opts.Password, w.flags)
+	if err != nil {
+		return nil, err
+	}
+	w.parents[dn] = container
+	return container, nil
+}
+
+// create creates the object described by spec and returns its
+// distinguished name.
+func (w *bulkWorker) create(spec *CreateSpec) (dn string, err error) {
+	if spec.Parent == "" || spec.Class == "" || spec.Name == "" {
+		return "", errors.New("create spec requires a parent, class and name")
+	}
+	container, err := w.parent(spec.Parent)
+	if err != nil {
+		return
+	}
+	obj, err := container.Create(spec.Class, spec.Name)
+	if err != nil {
+		return
+	}
+	defer obj.Close()
+	for name, values := range spec.Attributes {
+		if len(values) == 0 {
+			continue
+		}
+		if err = obj.PutEx(api.ADS_PROPERTY_UPDATE, name, values...); err != nil {
+			return
+		}
+	}
+	if err = obj.SetInfo(); err != nil {
+		return
+	}
+	dn = spec.Name + "," + spec.Parent
+
+	if spec.Password == "" {
+		return dn, nil
+	}
+	user, err := obj.ToUser()
+	if err == nil {
+		err = user.initialize(UserOptions{Password: spec.Password, MustChangePassword: spec.MustChangePassword})
+		user.Close()
+	}
+	if err != nil {
+		container.Delete(spec.Class, spec.Name)
+		return "", err
+	}
+	return dn, nil
+}
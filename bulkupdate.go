@@ -0,0 +1,160 @@
+package adsi
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/go-adsi/adsi/adspath"
+)
+
+// ErrSkipUpdate may be returned by a BulkUpdate mutation function to
+// indicate that an object needs no changes. The object is reported as
+// unchanged rather than failed.
+var ErrSkipUpdate = errors.New("skip update")
+
+// UpdateOptions controls the concurrency and behavior of BulkUpdate.
+type UpdateOptions struct {
+	// Workers is the number of objects updated concurrently. If zero
+	// DefaultBulkWorkers is used.
+	Workers int
+
+	// DryRun calls the mutation function for every object but discards its
+	// changes instead of committing them with SetInfo.
+	DryRun bool
+
+	// User and Password are the credentials used to bind to each object. If
+	// empty the security context of the application is used.
+	User     string
+	Password string
+}
+
+// UpdateResult reports the outcome of a single object processed by
+// BulkUpdate.
+type UpdateResult struct {
+	// Path is the ADsPath of the object.
+	Path string
+
+	// Updated is true if the changes made by the mutation function were
+	// committed, or would have been committed in a dry run.
+	Updated bool
+
+	Err error
+}
+
+// UpdateReport summarizes the outcome of BulkUpdate.
+type UpdateReport struct {
+	// Results holds the outcome of each object, in the order the objects
+	// were processed.
+	Results []UpdateResult
+
+	// DryRun is true if the changes were not committed.
+	DryRun bool
+}
+
+// Updated returns the number of objects that were updated.
+func (r *UpdateReport) Updated() (n int) {
+	for i := range r.Results {
+		if r.Results[i].Updated {
+			n++
+		}
+	}
+	return
+}
+
+// Failed returns the results of the objects that could not be updated.
+func (r *UpdateReport) Failed() (failed []UpdateResult) {
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return
+}
+
+// BulkUpdate binds to every object returned by iter and calls fn on it. If
+// fn returns nil the changes it made to the object's property cache are
+// committed with SetInfo. If fn returns an error matching ErrSkipUpdate the
+// object is left unchanged. Any other error is recorded against the object
+// and processing continues with the next one.
+//
+// Objects are processed concurrently by opts.Workers goroutines, so fn must
+// be safe for concurrent use. The search should include the ADsPath of each
+// row, which ADSI does unless the query's attribute list excludes it.
+//
+// The returned error is only non-nil if iter itself fails, in which case
+// the report covers the objects processed until then. The caller remains
+// responsible for closing iter.
+func (c *Client) BulkUpdate(iter *SearchResult, fn func(obj *Object) error, opts UpdateOptions) (report *UpdateReport, err error) {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = DefaultBulkWorkers
+	}
+
+	report = &UpdateReport{DryRun: opts.DryRun}
+	var (
+		m       sync.Mutex
+		iterErr error
+		wg      sync.WaitGroup
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				row, err := iter.Next()
+				if err != nil {
+					if err != io.EOF {
+						m.Lock()
+						if iterErr == nil {
+							iterErr = err
+						}
+						m.Unlock()
+					}
+					return
+				}
+				result := c.update(row.Path(), fn, &opts)
+				m.Lock()
+				report.Results = append(report.Results, result)
+				m.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return report, iterErr
+}
+
+// update binds to the object at path, applies fn and commits the result.
+func (c *Client) update(path string, fn func(obj *Object) error, opts *UpdateOptions) (result UpdateResult) {
+	result.Path = path
+	if path == "" {
+		result.Err = errors.New("search row has no ADsPath")
+		return
+	}
+
+	var server string
+	if p, err := adspath.Parse(path); err == nil {
+		server = p.Host
+	}
+	obj, err := c.OpenSC(path, opts.User, opts.Password, fastBindFlags(server))
+	if err != nil {
+		result.Err = err
+		return
+	}
+	defer obj.Close()
+
+	if err = fn(obj); err != nil {
+		if !errors.Is(err, ErrSkipUpdate) {
+			result.Err = err
+		}
+		return
+	}
+	if !opts.DryRun {
+		if err = obj.SetInfo(); err != nil {
+			result.Err = err
+			return
+		}
+	}
+	result.Updated = true
+	return
+}
@@ -0,0 +1,132 @@
+package adsi
+
+import (
+	"strings"
+
+	"github.com/go-adsi/adsi/api"
+)
+
+// Canonical names present the distinguished name of an object the way
+// Active Directory Users and Computers does, as the DNS name of the domain
+// followed by the names of the containers of the object and the name of the
+// object itself:
+//
+//	CN=Jane Doe,OU=Sales,OU=Staff,DC=example,DC=com
+//	example.com/Staff/Sales/Jane Doe
+//
+// Forward slashes and backslashes within names are escaped with a backslash.
+// Canonical names do not record whether each container is an organizational
+// unit or a container, so they can only be converted back to distinguished
+// names by the directory.
+
+// CanonicalName converts a distinguished name to a canonical name, such as
+// "example.com/Staff/Sales/Jane Doe". The conversion is made locally and
+// matches the constructed canonicalName attribute of the object. The
+// canonical name of a domain ends with a forward slash, as in
+// "example.com/".
+//
+// An error matching ErrInvalidDN is returned if dn is not a distinguished
+// name that ends with domain components.
+func CanonicalName(dn string) (name string, err error) {
+	rdns := splitDN(dn)
+	var labels, names []string
+	for i := len(rdns) - 1; i >= 0; i-- {
+		attr, value, ok := strings.Cut(rdns[i], "=")
+		if !ok || strings.TrimSpace(attr) == "" {
+			return "", &Error{Op: "CanonicalName", Path: dn, Err: ErrInvalidDN}
+		}
+		value = unescapeDNValue(strings.TrimSpace(value))
+		if len(names) == 0 && strings.EqualFold(strings.TrimSpace(attr), "DC") {
+			labels = append([]string{value}, labels...)
+			continue
+		}
+		names = append(names, escapeCanonicalName(value))
+	}
+	if len(labels) == 0 {
+		return "", &Error{Op: "CanonicalName", Path: dn, Err: ErrInvalidDN}
+	}
+	return strings.Join(labels, ".") + "/" + strings.Join(names, "/"), nil
+}
+
+// ParseCanonicalName splits a canonical name into the DNS name of its domain
+// and the unescaped names that follow it, outermost first. It is suitable
+// for validating canonical names entered by users before they are resolved
+// with DNFromCanonicalName.
+//
+// An error matching ErrInvalidCanonicalName is returned if name has no
+// domain or contains an empty name.
+func ParseCanonicalName(name string) (domain string, names []string, err error) {
+	invalid := &Error{Op: "ParseCanonicalName", Path: name, Err: ErrInvalidCanonicalName}
+	var b strings.Builder
+	var parts []string
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c == '\\' && i+1 < len(name):
+			i++
+			b.WriteByte(name[i])
+		case c == '/':
+			parts = append(parts, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(c)
+		}
+	}
+	parts = append(parts, b.String())
+	if len(parts) < 2 || parts[0] == "" {
+		return "", nil, invalid
+	}
+	domain, names = parts[0], parts[1:]
+	if len(names) == 1 && names[0] == "" {
+		// The canonical name of the domain itself
+		return domain, nil, nil
+	}
+	for _, n := range names {
+		if n == "" {
+			return "", nil, invalid
+		}
+	}
+	return domain, names, nil
+}
+
+// DNFromCanonicalName converts a canonical name to the distinguished name of
+// the object it names. The conversion is made by a global catalog with the
+// IADsNameTranslate interface, which knows the classes of the containers in
+// the name. The connection is made using the security context of the
+// application.
+//
+// An error matching ErrInvalidCanonicalName is returned if name is not a
+// canonical name, before the global catalog is contacted.
+func DNFromCanonicalName(name string) (dn string, err error) {
+	if _, _, err = ParseCanonicalName(name); err != nil {
+		return "", err
+	}
+	nt, err := NewNameTranslator("")
+	if err != nil {
+		return "", err
+	}
+	defer nt.Close()
+	if err = nt.Init("", api.ADS_NAME_INITTYPE_GC); err != nil {
+		return "", err
+	}
+	if err = nt.Set(name, api.ADS_NAME_TYPE_CANONICAL); err != nil {
+		return "", err
+	}
+	return nt.Get(api.ADS_NAME_TYPE_1779)
+}
+
+// escapeCanonicalName escapes the forward slashes and backslashes of a name
+// for use in a canonical name.
+func escapeCanonicalName(name string) string {
+	if !strings.ContainsAny(name, `/\`) {
+		return name
+	}
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		if c := name[i]; c == '/' || c == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(name[i])
+	}
+	return b.String()
+}
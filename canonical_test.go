@@ -0,0 +1,118 @@
+package adsi
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCanonicalName(t *testing.T) {
+	tests := []struct {
+		dn, want string
+	}{
+		{"CN=Jane Doe,OU=Sales,OU=Staff,DC=example,DC=com", "example.com/Staff/Sales/Jane Doe"},
+		{"cn=Jane Doe, ou=Staff, dc=example, dc=com", "example.com/Staff/Jane Doe"},
+		{"DC=example,DC=com", "example.com/"},
+		{"DC=com", "com/"},
+		{"CN=Users,DC=eu,DC=example,DC=com", "eu.example.com/Users"},
+		{`CN=Doe\, Jane,OU=Staff,DC=example,DC=com`, "example.com/Staff/Doe, Jane"},
+		{`CN=AC/DC,OU=Bands,DC=example,DC=com`, `example.com/Bands/AC\/DC`},
+		{`CN=back\\slash,DC=example,DC=com`, `example.com/back\\slash`},
+		{`CN=Jos\C3\A9,DC=example,DC=com`, "example.com/José"},
+		{"CN=Configuration,DC=example,DC=com", "example.com/Configuration"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dn, func(t *testing.T) {
+			got, err := CanonicalName(tt.dn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("CanonicalName(%q) = %q, want %q", tt.dn, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalNameInvalid(t *testing.T) {
+	for _, dn := range []string{
+		"",
+		"CN=Jane Doe,OU=Staff",
+		"CN=Jane Doe,OU=Staff,example",
+		"=Jane,DC=com",
+	} {
+		_, err := CanonicalName(dn)
+		if !errors.Is(err, ErrInvalidDN) {
+			t.Errorf("CanonicalName(%q) returned %v, want ErrInvalidDN", dn, err)
+			continue
+		}
+		var e *Error
+		if errors.As(err, &e) && (e.Op != "CanonicalName" || e.Path != dn) {
+			t.Errorf("CanonicalName(%q) returned Op %q, Path %q", dn, e.Op, e.Path)
+		}
+	}
+}
+
+func TestParseCanonicalName(t *testing.T) {
+	tests := []struct {
+		name       string
+		wantDomain string
+		wantNames  []string
+	}{
+		{"example.com/Staff/Sales/Jane Doe", "example.com", []string{"Staff", "Sales", "Jane Doe"}},
+		{"example.com/", "example.com", nil},
+		{`example.com/Bands/AC\/DC`, "example.com", []string{"Bands", "AC/DC"}},
+		{`example.com/back\\slash`, "example.com", []string{`back\slash`}},
+		{"example.com/Staff/Doe, Jane", "example.com", []string{"Staff", "Doe, Jane"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			domain, names, err := ParseCanonicalName(tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if domain != tt.wantDomain || !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("ParseCanonicalName(%q) = %q, %q, want %q, %q", tt.name, domain, names, tt.wantDomain, tt.wantNames)
+			}
+		})
+	}
+}
+
+func TestParseCanonicalNameInvalid(t *testing.T) {
+	for _, name := range []string{
+		"",
+		"example.com",
+		"/Staff",
+		"example.com//Jane Doe",
+		"example.com/Staff/",
+	} {
+		if _, _, err := ParseCanonicalName(name); !errors.Is(err, ErrInvalidCanonicalName) {
+			t.Errorf("ParseCanonicalName(%q) returned %v, want ErrInvalidCanonicalName", name, err)
+		}
+	}
+}
+
+func TestCanonicalNameRoundTrip(t *testing.T) {
+	for _, dn := range []string{
+		"CN=Jane Doe,OU=Sales,OU=Staff,DC=example,DC=com",
+		`CN=AC/DC,OU=Back\\Slash,DC=example,DC=com`,
+	} {
+		name, err := CanonicalName(dn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		domain, names, err := ParseCanonicalName(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rdns := splitDN(dn)
+		if domain != "example.com" || len(names) != len(rdns)-2 {
+			t.Fatalf("ParseCanonicalName(%q) = %q, %q", name, domain, names)
+		}
+		for i, n := range names {
+			if want := rdnValue(rdns[len(rdns)-3-i]); n != want {
+				t.Errorf("name %d of %q = %q, want %q", i, name, n, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package adsi
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/go-adsi/adsi/api"
+)
+
+// AttrCertificates attempts to retrieve the octet string attribute with the
+// given name and parse each of its values as a DER encoded X.509 certificate.
+// It is intended for use with multi-valued certificate attributes such as
+// userCertificate and userSMIMECertificate.
+//
+// An error is returned if any value cannot be parsed.
+func (o *object) AttrCertificates(name string) (certs []*x509.Certificate, err error) {
+	values, err := o.AttrBytesSlice(name)
+	if err != nil {
+		return
+	}
+	for i, value := range values {
+		cert, parseErr := x509.ParseCertificate(value)
+		if parseErr != nil {
+			return nil, fmt.Errorf("attribute \"%s\" value %d: %v", name, i, parseErr)
+		}
+		certs = append(certs, cert)
+	}
+	return
+}
+
+// Certificates returns the certificates published in the userCertificate
+// attribute of the user. An empty slice is returned if the user has no
+// published certificates.
+func (u *User) Certificates() ([]*x509.Certificate, error) {
+	return u.certificates("userCertificate")
+}
+
+// SMIMECertificates returns the certificates published in the
+// userSMIMECertificate attribute of the user. An empty slice is returned if
+// the user has no published certificates.
+func (u *User) SMIMECertificates() ([]*x509.Certificate, error) {
+	return u.certificates("userSMIMECertificate")
+}
+
+// PublishCertificate appends cert to the userCertificate attribute of the
+// user. The value must be commited with SetInfo to be made persistent.
+func (u *User) PublishCertificate(cert *x509.Certificate) error {
+	if cert == nil {
+		return errors.New("nil certificate")
+	}
+	return u.PutEx(api.ADS_PROPERTY_APPEND, "userCertificate", cert.Raw)
+}
+
+// UnpublishCertificate removes cert from the userCertificate attribute of
+// the user. The value must be commited with SetInfo to be made persistent.
+func (u *User) UnpublishCertificate(cert *x509.Certificate) error {
+	if cert == nil {
+		return errors.New("nil certificate")
+	}
+	return u.PutEx(api.ADS_PROPERTY_DELETE, "userCertificate", cert.Raw)
+}
+
+// SetCertificates replaces the contents of the given certificate attribute
+// with certs. If certs is empty the attribute is cleared. The value must be
+// commited with SetInfo to be made persistent.
+func (u *User) SetCertificates(name string, certs []*x509.Certificate) error {
+	if len(certs) == 0 {
+		return u.PutEx(api.ADS_PROPERTY_CLEAR, name)
+	}
+	values := make([]interface{}, 0, len(certs))
+	for _, cert := range certs {
+		if cert == nil {
+			return errors.New("nil certificate")
+		}
+		values = append(values, cert.Raw)
+	}
+	return u.PutEx(api.ADS_PROPERTY_UPDATE, name, values...)
+}
+
+func (u *User) certificates(name string) (certs []*x509.Certificate, err error) {
+	u.m.Lock()
+	defer u.m.Unlock()
+	if u.closed() {
+		return nil, ErrClosed
+	}
+	certs, err = u.object.AttrCertificates(name)
+	if errors.Is(err, api.ErrPropertyNotFound) {
+		return nil, nil
+	}
+	return
+}
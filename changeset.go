@@ -0,0 +1,398 @@
+package adsi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-adsi/adsi/adspath"
+	"github.com/go-adsi/adsi/api"
+)
+
+var (
+	// ErrInvalidChange is returned when a change recorded in a changeset is
+	// not permitted by the schema.
+	ErrInvalidChange = errors.New("invalid change")
+
+	// ErrChangeNotApplied is recorded against the changes that were not
+	// attempted because an earlier change in the changeset failed.
+	ErrChangeNotApplied = errors.New("change not applied because an earlier change failed")
+)
+
+// ChangeKind identifies the operation performed by a Change.
+type ChangeKind int
+
+// Change kinds.
+const (
+	ChangeCreate ChangeKind = iota
+	ChangePut
+	ChangeDelete
+)
+
+// String returns the name of the change kind.
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeCreate:
+		return "create"
+	case ChangePut:
+		return "put"
+	case ChangeDelete:
+		return "delete"
+	}
+	return fmt.Sprintf("unknown change kind %d", int(k))
+}
+
+// Change is a single write recorded in a Changeset.
+type Change struct {
+	Kind ChangeKind
+
+	// DN is the distinguished name of the object the change applies to.
+	DN string
+
+	// Class is the object class of the object being created or deleted.
+	Class string
+
+	// Control, Attr and Values describe an attribute change, with the same
+	// meaning as the arguments of PutEx.
+	Control uint32
+	Attr    string
+	Values  []interface{}
+}
+
+// String returns a short description of the change.
+func (c Change) String() string {
+	if c.Kind == ChangePut {
+		return fmt.Sprintf("put %s on %s", c.Attr, c.DN)
+	}
+	return fmt.Sprintf("%s %s %s", c.Kind, c.Class, c.DN)
+}
+
+// Changeset records writes to any number of objects so that they can be
+// validated against the schema before any of them are made, and then
+// applied in order.
+//
+// Consecutive attribute changes to the same object, including those that
+// directly follow its creation, are committed together with a single
+// SetInfo.
+type Changeset struct {
+	// Server is the domain controller to which the changes are applied. If
+	// empty a serverless binding is used, in which case successive changes
+	// may be made on different domain controllers.
+	Server string
+
+	// User and Password are the credentials used to bind. If empty the
+	// security context of the application is used.
+	User     string
+	Password string
+
+	// Schema is the schema that the changes are validated against. If nil
+	// it is read from Server when the changeset is applied.
+	Schema *Schema
+
+	changes []Change
+}
+
+// Changes returns the changes recorded in the changeset, in order.
+func (cs *Changeset) Changes() []Change {
+	return cs.changes
+}
+
+// Create records the creation of an object of the given class with the
+// given distinguished name. Its attributes are recorded with Put and PutEx.
+func (cs *Changeset) Create(class, dn string) {
+	cs.changes = append(cs.changes, Change{Kind: ChangeCreate, DN: dn, Class: class})
+}
+
+// Put records the replacement of the values of an attribute.
+func (cs *Changeset) Put(dn, attr string, values ...interface{}) {
+	cs.PutEx(dn, api.ADS_PROPERTY_UPDATE, attr, values...)
+}
+
+// PutEx records an attribute change using one of the ADS_PROPERTY_*
+// control codes, as described by Object.PutEx.
+func (cs *Changeset) PutEx(dn string, controlCode uint32, attr string, values ...interface{}) {
+	cs.changes = append(cs.changes, Change{Kind: ChangePut, DN: dn, Control: controlCode, Attr: attr, Values: values})
+}
+
+// Delete records the deletion of the object of the given class with the
+// given distinguished name.
+func (cs *Changeset) Delete(class, dn string) {
+	cs.changes = append(cs.changes, Change{Kind: ChangeDelete, DN: dn, Class: class})
+}
+
+// Validate checks the recorded changes against the given schema without
+// contacting the server. It verifies that classes and attributes exist,
+// that attributes are writable and permitted by the class of objects
+// created in the changeset, that single-valued attributes are given at most
+// one value, and that deleted objects are not changed afterwards.
+//
+// Mandatory attributes are not checked, because the server supplies many
+// of them itself.
+func (cs *Changeset) Validate(s *Schema) error {
+	created := make(map[string]string)
+	deleted := make(map[string]bool)
+	for i, change := range cs.changes {
+		key := strings.ToLower(change.DN)
+		invalid := func(format string, args ...interface{}) error {
+			return fmt.Errorf("%w: %d (%s): %s", ErrInvalidChange, i, change, fmt.Sprintf(format, args...))
+		}
+		if parentDN(change.DN) == "" {
+			return invalid("distinguished name has no parent")
+		}
+		if deleted[key] {
+			return invalid("object was deleted by an earlier change")
+		}
+		switch change.Kind {
+		case ChangeCreate:
+			class, ok := s.Class(change.Class)
+			if !ok {
+				return invalid("class not found")
+			}
+			if class.Category != ClassCategoryStructural {
+				return invalid("instances can't be created of %s class", class.Category)
+			}
+			rdn := splitDN(change.DN)[0]
+			if j := strings.IndexByte(rdn, '='); j < 0 || !strings.EqualFold(rdn[:j], class.RDNAttribute) {
+				return invalid("relative distinguished name must use %s", class.RDNAttribute)
+			}
+			created[key] = class.Name
+		case ChangePut:
+			attr, ok := s.Attribute(change.Attr)
+			if !ok {
+				return invalid("attribute not found")
+			}
+			if forward, ok := s.ForwardLink(attr.Name); ok {
+				return invalid("attribute is a back link; write %s on the linked objects instead", forward.Name)
+			}
+			if attr.SystemOnly || attr.Constructed || attr.IsBackLink() {
+				return invalid("attribute is not writable")
+			}
+			if attr.SingleValued && len(change.Values) > 1 && change.Control != api.ADS_PROPERTY_DELETE {
+				return invalid("attribute is single-valued")
+			}
+			if class, ok := created[key]; ok && !s.permits(class, attr.Name) {
+				return invalid("attribute is not permitted by class %s", class)
+			}
+		case ChangeDelete:
+			delete(created, key)
+			deleted[key] = true
+		default:
+			return invalid("unknown change kind")
+		}
+	}
+	return nil
+}
+
+// permits returns true if instances of the given class may hold the given
+// attribute.
+func (s *Schema) permits(class, attr string) bool {
+	for _, fn := range []func(string) ([]string, error){s.MandatoryAttributes, s.OptionalAttributes} {
+		attrs, err := fn(class)
+		if err != nil {
+			return false
+		}
+		for _, name := range attrs {
+			if strings.EqualFold(name, attr) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ChangeResult reports the outcome of a single change applied by a
+// changeset.
+type ChangeResult struct {
+	Change Change
+
+	// Applied is true if the change was committed to the directory.
+	Applied bool
+
+	// Undo, if not nil, is a change that reverses an applied change. It is
+	// recorded on a best-effort basis from the values held by the object
+	// before it was modified. Deletions can't be undone by a change and
+	// must be restored from the recycle bin instead.
+	Undo *Change
+
+	Err error
+}
+
+// ChangesetReport summarizes the outcome of applying a changeset.
+type ChangesetReport struct {
+	// Results holds the outcome of each change, in the order the changes
+	// were recorded.
+	Results []ChangeResult
+}
+
+// Err returns the error of the first change that failed, or nil if every
+// change was applied.
+func (r *ChangesetReport) Err() error {
+	for _, result := range r.Results {
+		if result.Err != nil {
+			return result.Err
+		}
+	}
+	return nil
+}
+
+// Applied returns the number of changes that were applied.
+func (r *ChangesetReport) Applied() (n int) {
+	for i := range r.Results {
+		if r.Results[i].Applied {
+			n++
+		}
+	}
+	return
+}
+
+// Rollback returns a changeset that reverses the applied changes, as far
+// as their undo changes allow, in reverse order.
+func (r *ChangesetReport) Rollback() *Changeset {
+	rollback := new(Changeset)
+	for i := len(r.Results) - 1; i >= 0; i-- {
+		if undo := r.Results[i].Undo; r.Results[i].Applied && undo != nil {
+			rollback.changes = append(rollback.changes, *undo)
+		}
+	}
+	return rollback
+}
+
+// Apply validates the changeset against the schema of the target forest
+// and, if it is valid, applies its changes in order. Unless the Schema field
+// is set, the schema is read with the credentials of the changeset, and the
+// copy cached by the client is only used when the changeset has none.
+// Application stops at the first
+// change that fails, and the remaining changes are reported with
+// ErrChangeNotApplied. Changes that have already been committed are not
+// reverted, but the returned report describes how to undo them.
+//
+// The returned error is only non-nil if the changeset could not be
+// validated, in which case no changes are made.
+func (cs *Changeset) Apply(c *Client) (report *ChangesetReport, err error) {
+	s := cs.Schema
+	switch {
+	case s != nil:
+	case cs.User == "":
+		s, err = c.Schema(cs.Server)
+	default:
+		s, err = c.SchemaSC(cs.Server, cs.User, cs.Password)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = cs.Validate(s); err != nil {
+		return nil, err
+	}
+
+	report = &ChangesetReport{Results: make([]ChangeResult, len(cs.changes))}
+	for i := range cs.changes {
+		report.Results[i].Change = cs.changes[i]
+	}
+	for i := 0; i < len(cs.changes); {
+		var n int
+		switch cs.changes[i].Kind {
+		case ChangeDelete:
+			n, err = 1, cs.delete(c, report.Results[i:i+1])
+		default:
+			n = i + 1
+			for n < len(cs.changes) && cs.changes[n].Kind == ChangePut && strings.EqualFold(cs.changes[n].DN, cs.changes[i].DN) {
+				n++
+			}
+			n -= i
+			err = cs.modify(c, report.Results[i:i+n])
+		}
+		if err != nil {
+			for j := i; j < i+n; j++ {
+				report.Results[j].Err = err
+			}
+			for j := i + n; j < len(cs.changes); j++ {
+				report.Results[j].Err = ErrChangeNotApplied
+			}
+			return report, nil
+		}
+		i += n
+	}
+	return report, nil
+}
+
+// path returns the ADsPath for the given distinguished name.
+func (cs *Changeset) path(dn string) string {
+	return (&adspath.Path{Scheme: "LDAP", Host: cs.Server, Path: adspath.EscapeDN(dn)}).String()
+}
+
+// parent binds to the parent container of the given distinguished name.
+func (cs *Changeset) parent(c *Client, dn string) (*Container, error) {
+	return c.OpenContainerSC(cs.path(parentDN(dn)), cs.User, cs.Password, fastBindFlags(cs.Server))
+}
+
+// delete applies a single deletion.
+func (cs *Changeset) delete(c *Client, results []ChangeResult) error {
+	change := results[0].Change
+	container, err := cs.parent(c, change.DN)
+	if err != nil {
+		return err
+	}
+	defer container.Close()
+	if err = container.Delete(change.Class, splitDN(change.DN)[0]); err != nil {
+		return err
+	}
+	results[0].Applied = true
+	return nil
+}
+
+// modify applies a creation or a run of attribute changes to the same
+// object and commits them together.
+func (cs *Changeset) modify(c *Client, results []ChangeResult) (err error) {
+	first := results[0].Change
+	var obj *Object
+	if first.Kind == ChangeCreate {
+		container, err := cs.parent(c, first.DN)
+		if err != nil {
+			return err
+		}
+		obj, err = container.Create(first.Class, splitDN(first.DN)[0])
+		container.Close()
+		if err != nil {
+			return err
+		}
+		results[0].Undo = &Change{Kind: ChangeDelete, DN: first.DN, Class: first.Class}
+	} else if obj, err = c.OpenSC(cs.path(first.DN), cs.User, cs.Password, fastBindFlags(cs.Server)); err != nil {
+		return err
+	}
+	defer obj.Close()
+
+	seen := make(map[string]bool)
+	for i := range results {
+		change := results[i].Change
+		if change.Kind != ChangePut {
+			continue
+		}
+		if key := strings.ToLower(change.Attr); first.Kind != ChangeCreate && !seen[key] {
+			seen[key] = true
+			results[i].Undo = cs.undo(obj, change)
+		}
+		if err = obj.PutEx(change.Control, change.Attr, change.Values...); err != nil {
+			return err
+		}
+	}
+	if err = obj.SetInfo(); err != nil {
+		return err
+	}
+	for i := range results {
+		results[i].Applied = true
+	}
+	return nil
+}
+
+// undo returns a change that restores the current values of the attribute
+// modified by change. It must be called before the change is staged.
+func (cs *Changeset) undo(obj *Object, change Change) *Change {
+	values, err := obj.Attr(change.Attr)
+	switch {
+	case errors.Is(err, api.ErrPropertyNotFound):
+		return &Change{Kind: ChangePut, DN: change.DN, Control: api.ADS_PROPERTY_CLEAR, Attr: change.Attr}
+	case err != nil:
+		return nil
+	}
+	return &Change{Kind: ChangePut, DN: change.DN, Control: api.ADS_PROPERTY_UPDATE, Attr: change.Attr, Values: values}
+}
@@ -3,6 +3,8 @@ package adsi
 import (
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 	"unsafe"
 
 	"github.com/go-ole/go-ole"
@@ -24,10 +26,51 @@ type namespace struct {
 
 // Client provides access to Active Directory Service Interfaces for
 // any namespace supported by a local or remote COM server.
+//
+// Each client owns one or more dedicated threads, called workers, that make
+// all of the COM calls of the client and of the objects opened through it.
+// Each object is pinned to the worker that opened it, and the objects derived
+// from it are pinned to the same worker, so that every interface is used from
+// the apartment in which it was created. Binds are distributed among the
+// workers in turn, which allows independent binds and searches to proceed in
+// parallel when the client has more than one worker.
+//
+// The client and its objects may be used from any goroutine. A worker exits
+// once the client and all of the objects pinned to it have been closed. The
+// apartment model of the workers is chosen with ClientOptions.Apartment.
 type Client struct {
-	m     sync.RWMutex
-	n     []namespace
-	flags uint32
+	m         sync.RWMutex
+	slots     []slot
+	next      atomic.Uint32
+	apartment Apartment
+	server    string
+	flags     uint32
+	h         *hooks
+
+	sm      sync.Mutex
+	schemas map[string]*Schema
+}
+
+// slot holds a worker of a client and the namespaces opened on it.
+type slot struct {
+	w *worker
+	n []namespace
+}
+
+// ClientOptions holds the options of a client created with
+// NewClientWithOptions.
+type ClientOptions struct {
+	// Server is the computer on which the ADSI providers are created. If
+	// empty a local client is created.
+	Server string
+
+	// Workers is the number of worker threads owned by the client. If zero
+	// a single worker is used, which serializes all of the COM calls made
+	// through the client.
+	Workers int
+
+	// Apartment is the apartment model of the workers.
+	Apartment Apartment
 }
 
 // NewClient creates a new ADSI client. When done with a client it should be
@@ -46,22 +89,51 @@ func NewClient() (*Client, error) {
 // If no server is provided a local client is created instead and the
 // resulting behavior is identical to NewClient.
 func NewRemoteClient(server string) (*Client, error) {
-	comshim.Add(1)
-	c := &Client{flags: defaultFlags}
-	if err := c.init(server); err != nil {
-		comshim.Done()
+	return NewClientWithOptions(ClientOptions{Server: server})
+}
+
+// NewClientWithOptions creates a new ADSI client with the given options. When
+// done with a client it should be closed with a call to Close(). If
+// NewClientWithOptions is successful it will return a client and error will
+// be nil, otherwise the returned client will be nil and error will be
+// non-nil.
+func NewClientWithOptions(opts ClientOptions) (*Client, error) {
+	workers := opts.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	c := &Client{apartment: opts.Apartment, server: opts.Server, flags: defaultFlags, h: &hooks{levels: DefaultLogLevels}}
+	for i := 0; i < workers; i++ {
+		w, err := startWorker(opts.Apartment)
+		if err == nil {
+			var n []namespace
+			w.run(func() { n, err = loadNamespaces(opts.Server) })
+			if err == nil {
+				c.slots = append(c.slots, slot{w: w, n: n})
+				continue
+			}
+			w.release()
+		}
+		c.release()
 		return nil, err
 	}
+	if opts.Apartment == ApartmentMTA {
+		// Keep the multithreaded apartment initialized for the threads that
+		// use the interfaces created by the workers
+		comshim.Add(1)
+	}
 	// TODO: Add finalizer for ds?
 	return c, nil
 }
 
-func (c *Client) init(server string) (err error) {
+// loadNamespaces returns the namespaces available on the given server. It
+// must be called on the worker that will use them.
+func loadNamespaces(server string) (n []namespace, err error) {
 	// Acquiring a container for the CLSID_ADsNamespaces class gives us access to
 	// an enumeration of all of the available namespaces.
 	iface, err := api.NewIADsContainer(server, comclsid.ADsNamespaces)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	root := NewContainer(iface)
@@ -69,18 +141,18 @@ func (c *Client) init(server string) (err error) {
 
 	iter, err := root.Children()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer iter.Close()
 
-	c.n = make([]namespace, 0, 12)
+	n = make([]namespace, 0, 12)
 
 	for child, iterErr := iter.Next(); iterErr == nil; child, iterErr = iter.Next() {
 		defer child.Close()
 
 		// Add the entry and whip up a pointer to it
-		c.n = append(c.n, namespace{})
-		item := &c.n[len(c.n)-1]
+		n = append(n, namespace{})
+		item := &n[len(n)-1]
 
 		// Name
 		item.Name, item.Err = child.Name()
@@ -111,24 +183,55 @@ func (c *Client) init(server string) (err error) {
 }
 
 func (c *Client) closed() bool {
-	return (c.n == nil)
+	return (c.slots == nil)
 }
 
 // Close will release resources consumed by the client. It should be called
 // when the client is no longer needed.
-func (c *Client) Close() {
+func (c *Client) Close() error {
 	c.m.Lock()
 	defer c.m.Unlock()
 	if c.closed() {
-		return
+		return nil
 	}
-	defer comshim.Done()
-	for i := 0; i < len(c.n); i++ {
-		if c.n[i].Iface != nil {
-			c.n[i].Iface.Release()
-		}
+	if c.apartment == ApartmentMTA {
+		defer comshim.Done()
 	}
-	c.n = nil
+	c.release()
+	return nil
+}
+
+// release releases the namespaces of the client and its references to its
+// workers.
+func (c *Client) release() {
+	for i := range c.slots {
+		c.slots[i].close()
+		c.slots[i].w.release()
+	}
+	c.slots = nil
+}
+
+// close releases the namespaces opened on the worker of the slot.
+func (s *slot) close() {
+	s.w.run(func() {
+		for i := 0; i < len(s.n); i++ {
+			if s.n[i].Iface != nil {
+				s.n[i].Iface.Release()
+			}
+		}
+	})
+}
+
+// Apartment returns the apartment model of the client's workers.
+func (c *Client) Apartment() Apartment {
+	return c.apartment
+}
+
+// Workers returns the number of worker threads owned by the client.
+func (c *Client) Workers() int {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	return len(c.slots)
 }
 
 // Flags returns the default flags that are used when opening a connection.
@@ -185,12 +288,13 @@ func (c *Client) Open(path string) (obj *Object, err error) {
 // caller's responsibilty to call Close on the returned object when it is no
 // longer needed.
 func (c *Client) OpenSC(path, user, password string, flags uint32) (obj *Object, err error) {
-	idispatch, err := c.OpenInterfaceSC(path, user, password, flags, comiid.IADs)
+	idispatch, h, err := c.openInterface(path, user, password, flags, comiid.IADs)
 	if err != nil {
 		return nil, err
 	}
 	iface := (*api.IADs)(unsafe.Pointer(idispatch))
 	obj = NewObject(iface)
+	obj.h = h
 	return
 }
 
@@ -234,12 +338,13 @@ func (c *Client) OpenContainer(path string) (container *Container, err error) {
 // caller's responsibilty to call Close on the returned container when it is no
 // longer needed.
 func (c *Client) OpenContainerSC(path, user, password string, flags uint32) (container *Container, err error) {
-	idispatch, err := c.OpenInterfaceSC(path, user, password, flags, comiid.IADsContainer)
+	idispatch, h, err := c.openInterface(path, user, password, flags, comiid.IADsContainer)
 	if err != nil {
 		return nil, err
 	}
 	iface := (*api.IADsContainer)(unsafe.Pointer(idispatch))
 	container = NewContainer(iface)
+	container.h = h
 	return
 }
 
@@ -283,15 +388,129 @@ func (c *Client) OpenComputer(path string) (computer *Computer, err error) {
 // caller's responsibilty to call Close on the returned computer when it is no
 // longer needed.
 func (c *Client) OpenComputerSC(path, user, password string, flags uint32) (computer *Computer, err error) {
-	idispatch, err := c.OpenInterfaceSC(path, user, password, flags, comiid.IADsComputer)
+	idispatch, h, err := c.openInterface(path, user, password, flags, comiid.IADsComputer)
 	if err != nil {
 		return nil, err
 	}
 	iface := (*api.IADsComputer)(unsafe.Pointer(idispatch))
 	computer = NewComputer(iface)
+	computer.h = h
 	return
 }
 
+// OpenSearcher opens an ADSI directory searcher rooted at the given path. The
+// existing security context of the application and any flags specified via
+// SetFlags will be used when making the connection. The default flags specify
+// an encrypted read-only connection.
+//
+// OpenSearcher returns the searcher as a Searcher type, which provides an
+// idiomatic go wrapper around the underlying component object model
+// IDirectorySearch interface.
+//
+// OpenSearcher calls QueryInterface internally to acquire an implementation
+// of the IDirectorySearch interface that is needed by the Searcher type. If
+// the returned directory object does not implement the IDirectorySearch
+// interface an error is returned.
+//
+// The returned searcher consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned searcher when it is no
+// longer needed.
+func (c *Client) OpenSearcher(path string) (searcher *Searcher, err error) {
+	return c.OpenSearcherSC(path, "", "", c.Flags())
+}
+
+// OpenSearcherSC opens an ADSI directory searcher rooted at the given path.
+// When provided, the username and password are used to establish a security
+// context for the connection. When credentials are not provided the existing
+// security context of the application is used instead. The provided flags
+// will be used when making the connection.
+//
+// OpenSearcherSC returns the searcher as a Searcher type, which provides an
+// idiomatic go wrapper around the underlying component object model
+// IDirectorySearch interface.
+//
+// OpenSearcherSC calls QueryInterface internally to acquire an implementation
+// of the IDirectorySearch interface that is needed by the Searcher type. If
+// the returned directory object does not implement the IDirectorySearch
+// interface an error is returned.
+//
+// The returned searcher consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned searcher when it is no
+// longer needed.
+func (c *Client) OpenSearcherSC(path, user, password string, flags uint32) (searcher *Searcher, err error) {
+	idispatch, h, err := c.openInterface(path, user, password, flags, comiid.IDirectorySearch)
+	if err != nil {
+		return nil, err
+	}
+	iface := (*api.IDirectorySearch)(unsafe.Pointer(idispatch))
+	searcher = NewSearcher(iface)
+	searcher.h = h
+	return
+}
+
+// OpenDomain opens the Active Directory domain object with the given path,
+// such as "LDAP://DC=example,DC=com". The existing security context of the
+// application and any flags specified via SetFlags will be used when making
+// the connection. The default flags specify an encrypted read-only
+// connection.
+//
+// OpenDomain returns the domain as a Domain type, which provides
+// domain-specific helpers on top of the underlying component object model
+// IADs interface.
+//
+// The returned domain consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned domain when it is no
+// longer needed.
+func (c *Client) OpenDomain(path string) (domain *Domain, err error) {
+	return c.OpenDomainSC(path, "", "", c.Flags())
+}
+
+// OpenDomainSC opens the Active Directory domain object with the given path.
+// When provided, the username and password are used to establish a security
+// context for the connection. When credentials are not provided the existing
+// security context of the application is used instead. The provided flags
+// will be used when making the connection.
+//
+// OpenDomainSC returns the domain as a Domain type, which provides
+// domain-specific helpers on top of the underlying component object model
+// IADs interface.
+//
+// The returned domain consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned domain when it is no
+// longer needed.
+func (c *Client) OpenDomainSC(path, user, password string, flags uint32) (domain *Domain, err error) {
+	idispatch, h, err := c.openInterface(path, user, password, flags, comiid.IADs)
+	if err != nil {
+		return nil, err
+	}
+	iface := (*api.IADs)(unsafe.Pointer(idispatch))
+	domain = NewDomain(iface)
+	domain.h = h
+	return
+}
+
+// Search executes the given query against the directory, rooted at the object
+// with the given path. It opens a searcher with OpenSearcher and releases it
+// once the search has been executed.
+//
+// The returned result set consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned result set when it is
+// no longer needed.
+func (c *Client) Search(path string, q Query) (result *SearchResult, err error) {
+	return c.searchSC(path, "", "", c.Flags(), q)
+}
+
+// searchSC executes the given query in the same way as Search, opening the
+// searcher with OpenSearcherSC.
+func (c *Client) searchSC(path, user, password string, flags uint32, q Query) (result *SearchResult, err error) {
+	searcher, err := c.OpenSearcherSC(path, user, password, flags)
+	if err != nil {
+		return nil, err
+	}
+	defer searcher.Close()
+	return searcher.Search(q)
+}
+
 // OpenDispatch opens an ADSI object with the given path. The existing security
 // context of the application and any flags specified via SetFlags will be
 // used when making the connection. The default flags specify an encrypted
@@ -332,12 +551,12 @@ func (c *Client) OpenDispatch(path string) (obj *ole.IDispatch, err error) {
 // caller's responsibilty to call Release on the returned object when it is no
 // longer needed.
 func (c *Client) OpenDispatchSC(path, user, password string, flags uint32) (obj *ole.IDispatch, err error) {
-	c.m.Lock()
-	defer c.m.Unlock()
+	c.m.RLock()
+	defer c.m.RUnlock()
 	if c.closed() {
 		return nil, ErrClosed
 	}
-	obj, err = c.open(path, user, password, flags)
+	obj, err = c.open(c.pick(), c.h, path, user, password, flags)
 	return
 }
 
@@ -381,35 +600,161 @@ func (c *Client) OpenInterface(path string, iid uuid.UUID) (obj *ole.IDispatch,
 // caller's responsibilty to call Release on the returned object when it is no
 // longer needed.
 func (c *Client) OpenInterfaceSC(path, user, password string, flags uint32, iid uuid.UUID) (obj *ole.IDispatch, err error) {
-	c.m.Lock()
-	defer c.m.Unlock()
+	obj, h, err := c.openInterface(path, user, password, flags, iid)
+	h.released()
+	return
+}
+
+// openInterface opens a directory object with the given interface on the
+// next worker of the client. It returns the hooks of the objects pinned to
+// that worker, which hold a reference to it.
+func (c *Client) openInterface(path, user, password string, flags uint32, iid uuid.UUID) (obj *ole.IDispatch, h *hooks, err error) {
+	c.m.RLock()
+	defer c.m.RUnlock()
 	if c.closed() {
-		return nil, ErrClosed
+		return nil, nil, ErrClosed
 	}
-	idispatch, err := c.open(path, user, password, flags)
+	s := c.pick()
+	h = c.h.clone()
+	h.w = s.w
+	h.client, h.user, h.password, h.flags = c, user, password, flags
+	idispatch, err := c.open(s, h, path, user, password, flags)
 	if err != nil {
-		return
+		return nil, nil, err
 	}
-	defer idispatch.Release()
-	obj, err = idispatch.QueryInterface(comutil.GUID(iid))
-	return
+	s.w.run(func() {
+		defer idispatch.Release()
+		obj, err = idispatch.QueryInterface(comutil.GUID(iid))
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return obj, h.opened(), nil
 }
 
-func (c *Client) open(path, user, password string, flags uint32) (obj *ole.IDispatch, err error) {
-	p, err := adspath.Parse(path)
+// reopen binds to the object with the given path on behalf of an object
+// opened through the client with the given hooks, with the credentials and
+// flags that object was opened with. If pinned is true the bind is made on
+// the worker of that object, otherwise it is made on the next worker of the
+// client in turn. The returned hooks belong to the new interface.
+//
+// If the client has since been closed, as the ephemeral clients of the
+// package-level functions are, the bind is made on the worker of the object
+// and the namespaces are loaded again for the duration of the bind.
+func (c *Client) reopen(h *hooks, pinned bool, path string, iid uuid.UUID) (obj *ole.IDispatch, oh *hooks, err error) {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	var s *slot
+	if pinned || c.closed() {
+		for i := range c.slots {
+			if c.slots[i].w == h.w {
+				s = &c.slots[i]
+			}
+		}
+	} else {
+		s = c.pick()
+	}
+	if s == nil {
+		s = &slot{w: h.w}
+		h.w.run(func() { s.n, err = loadNamespaces(c.server) })
+		if err != nil {
+			return nil, nil, err
+		}
+		defer s.close()
+	}
+	oh = h
+	if s.w != h.w {
+		oh = h.clone()
+		oh.w = s.w
+	}
+	idispatch, err := c.open(s, oh, path, h.user, h.password, h.flags)
 	if err != nil {
-		return
+		return nil, nil, err
+	}
+	s.w.run(func() {
+		defer idispatch.Release()
+		obj, err = idispatch.QueryInterface(comutil.GUID(iid))
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return obj, oh.opened(), nil
+}
+
+// open opens the object with the given path on behalf of an object sharing
+// the hooks, in the same way as that object was opened. Objects that were not
+// opened through a client open the path with Open instead.
+//
+// The returned object consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned object when it is no
+// longer needed.
+func (h *hooks) open(path string) (obj *Object, err error) {
+	if h == nil || h.client == nil {
+		return Open(path)
+	}
+	idispatch, oh, err := h.client.reopen(h, true, path, comiid.IADs)
+	if err != nil {
+		return nil, err
 	}
+	obj = NewObject((*api.IADs)(unsafe.Pointer(idispatch)))
+	obj.h = oh
+	return
+}
 
-	ns := c.namespace(p.Scheme)
-	if ns == nil {
-		return nil, api.ErrInvalidNamespace
+// search executes the given query on behalf of an object sharing the hooks,
+// rooted at the object with the given path. The searcher is opened with the
+// same credentials and flags as open uses, but on the next worker of the
+// client in turn, so that independent searches can proceed in parallel.
+//
+// The returned result set consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned result set when it is
+// no longer needed.
+func (h *hooks) search(path string, q Query) (result *SearchResult, err error) {
+	if h == nil || h.client == nil {
+		return Search(path, q)
 	}
-	if ns.Err != nil {
-		return nil, ns.Err
+	idispatch, oh, err := h.client.reopen(h, false, path, comiid.IDirectorySearch)
+	if err != nil {
+		return nil, err
 	}
+	searcher := NewSearcher((*api.IDirectorySearch)(unsafe.Pointer(idispatch)))
+	searcher.h = oh
+	defer searcher.Close()
+	return searcher.Search(q)
+}
 
-	obj, err = ns.Iface.OpenDSObject(path, user, password, flags)
+// pick returns the slot of the worker that makes the next bind. The caller
+// must hold the client's lock.
+func (c *Client) pick() *slot {
+	i := c.next.Add(1) - 1
+	return &c.slots[i%uint32(len(c.slots))]
+}
+
+// open binds to the object with the given path on the worker of the given
+// slot, reporting the bind to the given hooks. The caller must hold the
+// client's lock.
+func (c *Client) open(s *slot, h *hooks, path, user, password string, flags uint32) (obj *ole.IDispatch, err error) {
+	p, err := adspath.Parse(path)
+	if err != nil {
+		return
+	}
+
+	start := time.Now()
+	defer func() { h.bind(path, user, flags, start, err) }()
+
+	ns := s.namespace(p.Scheme)
+	s.w.run(func() {
+		defer beginCall()()
+		switch {
+		case ns == nil:
+			err = api.ErrInvalidNamespace
+		case ns.Err != nil:
+			err = ns.Err
+		default:
+			obj, err = ns.Iface.OpenDSObject(path, user, password, flags)
+		}
+		err = wrapError("Open", path, err)
+	})
 	return
 }
 
@@ -417,10 +762,10 @@ func (c *Client) open(path, user, password string, flags uint32) (obj *ole.IDisp
 // no namespace has been registered with that name then nil is returend.
 //
 // The name matching is case-sensitive.
-func (c *Client) namespace(name string) *namespace {
-	for i := 0; i < len(c.n); i++ {
-		if c.n[i].Name == name {
-			return &c.n[i]
+func (s *slot) namespace(name string) *namespace {
+	for i := 0; i < len(s.n); i++ {
+		if s.n[i].Name == name {
+			return &s.n[i]
 		}
 	}
 	return nil
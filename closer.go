@@ -0,0 +1,22 @@
+package adsi
+
+import "io"
+
+// Every type that holds resources satisfies io.Closer, so that it can be
+// released by cleanup helpers that accept one.
+var (
+	_ io.Closer = (*Client)(nil)
+	_ io.Closer = (*Object)(nil)
+	_ io.Closer = (*Container)(nil)
+	_ io.Closer = (*Computer)(nil)
+	_ io.Closer = (*Domain)(nil)
+	_ io.Closer = (*Group)(nil)
+	_ io.Closer = (*User)(nil)
+	_ io.Closer = (*Members)(nil)
+	_ io.Closer = (*ObjectIter)(nil)
+	_ io.Closer = (*Searcher)(nil)
+	_ io.Closer = (*SearchResult)(nil)
+	_ io.Closer = (*NameTranslator)(nil)
+	_ io.Closer = (*Watcher)(nil)
+	_ io.Closer = (*Poller)(nil)
+)
@@ -71,4 +71,10 @@ var (
 	// CLSID_NameTranslate
 	// {274fae1f-3626-11d1-a3a4-00c04fb950dc}
 	NameTranslate = uuid.UUID{0x27, 0x4F, 0xAE, 0x1F, 0x36, 0x26, 0x11, 0xD1, 0xA3, 0xA4, 0x00, 0xC0, 0x4F, 0xB9, 0x50, 0xDC}
+
+	// LargeInteger is a component object model class identifier.
+	//
+	// CLSID_LargeInteger
+	// {927971F5-0939-11D1-8BE1-00C04FD8D503}
+	LargeInteger = uuid.UUID{0x92, 0x79, 0x71, 0xF5, 0x09, 0x39, 0x11, 0xD1, 0x8B, 0xE1, 0x00, 0xC0, 0x4F, 0xD8, 0xD5, 0x03}
 )
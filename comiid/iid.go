@@ -65,6 +65,20 @@ var (
 	// {79FA9AD0-A97C-11D0-8534-00C04FD8D503}
 	IADsPropertyValue = uuid.UUID{0x79, 0xFA, 0x9A, 0xD0, 0xA9, 0x7C, 0x11, 0xD0, 0x85, 0x34, 0x00, 0xC0, 0x4F, 0xD8, 0xD5, 0x03}
 
+	// IADsPropertyList is the component object model identifier of the
+	// IADsPropertyList interface.
+	//
+	// IID_IADsPropertyList
+	// {C6F602B6-8F69-11D0-8528-00C04FD8D503}
+	IADsPropertyList = uuid.UUID{0xC6, 0xF6, 0x02, 0xB6, 0x8F, 0x69, 0x11, 0xD0, 0x85, 0x28, 0x00, 0xC0, 0x4F, 0xD8, 0xD5, 0x03}
+
+	// IADsPropertyEntry is the component object model identifier of the
+	// IADsPropertyEntry interface.
+	//
+	// IID_IADsPropertyEntry
+	// {05792C8E-941F-11D0-8529-00C04FD8D503}
+	IADsPropertyEntry = uuid.UUID{0x05, 0x79, 0x2C, 0x8E, 0x94, 0x1F, 0x11, 0xD0, 0x85, 0x29, 0x00, 0xC0, 0x4F, 0xD8, 0xD5, 0x03}
+
 	// IADsLargeInteger is the component object model identifier of the
 	// IADsLargeInteger interface.
 	//
@@ -72,6 +86,13 @@ var (
 	// {9068270B-0939-11D1-8BE1-00C04FD8D503}
 	IADsLargeInteger = uuid.UUID{0x90, 0x68, 0x27, 0x0B, 0x09, 0x39, 0x11, 0xD1, 0x8B, 0xE1, 0x00, 0xC0, 0x4F, 0xD8, 0xD5, 0x03}
 
+	// IADsObjectOptions is the component object model identifier of the
+	// IADsObjectOptions interface.
+	//
+	// IID_IADsObjectOptions
+	// {46F14FDA-232B-11D1-A808-00C04FD8D5A8}
+	IADsObjectOptions = uuid.UUID{0x46, 0xF1, 0x4F, 0xDA, 0x23, 0x2B, 0x11, 0xD1, 0xA8, 0x08, 0x00, 0xC0, 0x4F, 0xD8, 0xD5, 0xA8}
+
 	// IDirectorySearch is the component object model identifier of the
 	// IDirectorySearch interface.
 	//
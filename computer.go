@@ -15,7 +15,7 @@ type Computer struct {
 // NewComputer returns a computer that manages the given COM interface.
 func NewComputer(iface *api.IADsComputer) *Computer {
 	comshim.Add(1)
-	return &Computer{iface: iface, object: object{iface: &iface.IADs}}
+	return track(&Computer{iface: iface, object: object{iface: &iface.IADs}})
 }
 
 func (c *Computer) closed() bool {
@@ -24,16 +24,22 @@ func (c *Computer) closed() bool {
 
 // Close will release resources consumed by the computer. It should be
 // called when the computer is no longer needed.
-func (c *Computer) Close() {
+func (c *Computer) Close() error {
 	c.m.Lock()
 	defer c.m.Unlock()
 	if c.closed() {
-		return
+		return nil
 	}
+	untrack(c)
 	defer comshim.Done()
-	c.iface.Release()
+	defer c.h.released()
+	c.h.run(func() {
+		c.releaseInterfaces()
+		c.iface.Release()
+	})
 	c.object.iface = nil
 	c.iface = nil
+	return nil
 }
 
 // ID retrieves the ID of the computer.
@@ -43,7 +49,11 @@ func (c *Computer) ID() (id string, err error) {
 	if c.closed() {
 		return "", ErrClosed
 	}
-	id, err = c.iface.ComputerID()
+	c.h.run(func() {
+		defer beginCall()()
+		id, err = c.iface.ComputerID()
+		err = c.err("ID", err)
+	})
 	return
 }
 
@@ -54,7 +64,11 @@ func (c *Computer) Site() (site string, err error) {
 	if c.closed() {
 		return "", ErrClosed
 	}
-	site, err = c.iface.Site()
+	c.h.run(func() {
+		defer beginCall()()
+		site, err = c.iface.Site()
+		err = c.err("Site", err)
+	})
 	return
 }
 
@@ -65,6 +79,10 @@ func (c *Computer) OperatingSystem() (kind string, err error) {
 	if c.closed() {
 		return "", ErrClosed
 	}
-	kind, err = c.iface.OperatingSystem()
+	c.h.run(func() {
+		defer beginCall()()
+		kind, err = c.iface.OperatingSystem()
+		err = c.err("OperatingSystem", err)
+	})
 	return
 }
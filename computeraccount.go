@@ -0,0 +1,161 @@
+package adsi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-adsi/adsi/sddl"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidComputerName is returned when a computer name cannot be used as
+// the NetBIOS name of a computer account.
+var ErrInvalidComputerName = errors.New("invalid computer name")
+
+// maxComputerNameLength is the maximum length of a NetBIOS computer name.
+const maxComputerNameLength = 15
+
+// Rights granted to the principal that is allowed to join a computer to the
+// domain using a pre-staged account, matching those granted by Active
+// Directory Users and Computers.
+var (
+	rightResetPassword        = uuid.MustParse("00299570-246d-11d0-a768-00aa006e0529")
+	rightAccountRestrictions  = uuid.MustParse("4c164200-20c0-11d0-a768-00aa006e0529")
+	rightValidatedDNSHostName = uuid.MustParse("72e39547-7b18-11d1-adef-00c04fd8d5cd")
+	rightValidatedSPN         = uuid.MustParse("f3a64788-5306-11d1-a9c5-00c04f79f805")
+)
+
+// ComputerOptions holds the optional settings of a computer account created
+// by CreateComputer.
+type ComputerOptions struct {
+	// SAMAccountName is the pre-Windows 2000 name of the account. If empty
+	// the upper case computer name followed by "$" is used. A trailing "$"
+	// is added if it is missing.
+	SAMAccountName string
+
+	DNSHostName string
+	Description string
+
+	// Disabled creates the account in a disabled state.
+	Disabled bool
+
+	// JoinPrincipal, if set, is the user or group that is allowed to join a
+	// computer to the domain using the account. It is granted the right to
+	// reset the account password, write its account restrictions and make
+	// validated writes to its DNS host name and service principal names.
+	JoinPrincipal *SID
+}
+
+// CreateComputer creates and commits a computer account with the given
+// name within the container, pre-staged so that a computer of that name can
+// join the domain with it. The account is created as a workstation trust
+// account and its password is set to the default described by ResetAccount.
+//
+// The returned computer consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned computer when it is
+// no longer needed.
+func (c *Container) CreateComputer(name string, opts ComputerOptions) (computer *Computer, err error) {
+	if name == "" || len(name) > maxComputerNameLength || strings.ContainsAny(name, `\/:*?"<>|. `) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidComputerName, name)
+	}
+	sam := opts.SAMAccountName
+	if sam == "" {
+		sam = strings.ToUpper(name)
+	}
+	if !strings.HasSuffix(sam, "$") {
+		sam += "$"
+	}
+	uac := AccountControlWorkstationTrustAccount | AccountControlPasswordNotRequired
+	if opts.Disabled {
+		uac |= AccountControlAccountDisable
+	}
+
+	obj, err := c.Create("computer", "CN="+escapeRDNValue(name))
+	if err != nil {
+		return
+	}
+	defer obj.Close()
+	if err = obj.PutString("sAMAccountName", sam); err != nil {
+		return
+	}
+	if err = obj.SetAccountControl(uac); err != nil {
+		return
+	}
+	if opts.DNSHostName != "" {
+		if err = obj.PutString("dNSHostName", opts.DNSHostName); err != nil {
+			return
+		}
+	}
+	if opts.Description != "" {
+		if err = obj.PutString("description", opts.Description); err != nil {
+			return
+		}
+	}
+	if err = obj.SetInfo(); err != nil {
+		return
+	}
+
+	if computer, err = obj.ToComputer(); err != nil {
+		return
+	}
+	if err = computer.ResetAccount(); err == nil && opts.JoinPrincipal != nil {
+		err = computer.grantJoin(*opts.JoinPrincipal)
+	}
+	if err != nil {
+		computer.Close()
+		return nil, err
+	}
+	return computer, nil
+}
+
+// ResetAccount resets the password of the computer account to its default
+// value, the lower case pre-Windows 2000 name of the account without its
+// trailing "$" and truncated to 14 characters. This is the same as the Reset
+// Account command of Active Directory Users and Computers, and allows the
+// computer to rejoin the domain with the existing account. The change takes
+// effect immediately.
+func (c *Computer) ResetAccount() error {
+	sam, err := c.samAccountName()
+	if err != nil {
+		return err
+	}
+	password := strings.ToLower(strings.TrimSuffix(sam, "$"))
+	if len(password) > 14 {
+		password = password[:14]
+	}
+	u, err := c.ToUser()
+	if err != nil {
+		return err
+	}
+	defer u.Close()
+	return u.SetPassword(password)
+}
+
+func (c *Computer) samAccountName() (sam string, err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return "", ErrClosed
+	}
+	return c.AttrString("sAMAccountName")
+}
+
+// grantJoin grants the given principal the rights needed to join a computer
+// to the domain using the account.
+func (c *Computer) grantJoin(principal SID) error {
+	sd, err := c.SecurityDescriptor()
+	if err != nil {
+		return err
+	}
+	if sd.DACL == nil {
+		sd.DACL = new(ACL)
+	}
+	sd.DACL.AddExplicit(
+		ACE{Type: ACETypeAccessAllowedObject, Mask: sddl.RightDSControlAccess, ObjectType: rightResetPassword, SID: principal},
+		ACE{Type: ACETypeAccessAllowedObject, Mask: sddl.RightDSWriteProperty, ObjectType: rightAccountRestrictions, SID: principal},
+		ACE{Type: ACETypeAccessAllowedObject, Mask: sddl.RightDSSelf, ObjectType: rightValidatedDNSHostName, SID: principal},
+		ACE{Type: ACETypeAccessAllowedObject, Mask: sddl.RightDSSelf, ObjectType: rightValidatedSPN, SID: principal},
+	)
+	return c.SetDACL(sd.DACL)
+}
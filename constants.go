@@ -33,6 +33,43 @@ var (
 	// ErrNonVariantArrayAttribute is returned when the array members of a given
 	// attribute are not variants.
 	ErrNonVariantArrayAttribute = errors.New("attribute contains non-variant array members")
+
+	// ErrNonOctetStringAttribute is returned when an attribute read with
+	// WriteAttrTo or AttrReader does not hold an octet string.
+	ErrNonOctetStringAttribute = errors.New("attribute is not an octet string")
+
+	// ErrNotLoaded is returned when an attribute is read from an object on
+	// which NoImplicitGetInfo has been called before the attribute has been
+	// loaded with Pull or Refresh.
+	ErrNotLoaded = errors.New("attribute has not been loaded into the property cache")
+
+	// ErrUnsupported is returned by the functions that connect to a directory,
+	// such as NewClient, Open and NewNameTranslator, when the program is built
+	// for an operating system other than Windows. The package compiles on
+	// every platform so that cross-platform applications can test for it
+	// with errors.Is and disable their directory features. It matches
+	// errors.ErrUnsupported.
+	ErrUnsupported = api.ErrUnsupported
+
+	// ErrInvalidIdentity is returned when an identity given to FindUser,
+	// FindGroup or FindComputer is empty.
+	ErrInvalidIdentity = errors.New("invalid identity")
+
+	// ErrAmbiguousIdentity is returned when more than one object matches an
+	// identity given to FindUser, FindGroup or FindComputer.
+	ErrAmbiguousIdentity = errors.New("identity matches more than one object")
+
+	// ErrInvalidWellKnownObject is returned when a value given to
+	// OpenWellKnownContainer or WellKnownDN is not a well-known object.
+	ErrInvalidWellKnownObject = errors.New("invalid well-known object")
+
+	// ErrInvalidDN is returned when a distinguished name given to
+	// CanonicalName is malformed or does not end with domain components.
+	ErrInvalidDN = errors.New("invalid distinguished name")
+
+	// ErrInvalidCanonicalName is returned when a canonical name given to
+	// ParseCanonicalName or DNFromCanonicalName is malformed.
+	ErrInvalidCanonicalName = errors.New("invalid canonical name")
 )
 
 const (
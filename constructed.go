@@ -0,0 +1,120 @@
+package adsi
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-adsi/adsi/api"
+)
+
+// Constructed attributes are computed by the domain controller when they are
+// requested and are never returned by the implicit GetInfo call that ADSI
+// makes on first access. They must be retrieved explicitly with Pull before
+// they can be read. The getters in this file take care of that.
+
+// ParentDN retrieves the distinguished name of the object's parent from the
+// constructed msDS-parentdistname attribute.
+func (o *object) ParentDN() (dn string, err error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return "", ErrClosed
+	}
+	if err = o.Pull("msDS-parentdistname"); err != nil {
+		return
+	}
+	return o.AttrString("msDS-parentdistname")
+}
+
+// WritableAttributes returns the attributes of the object that the bound
+// identity is permitted to write, from the constructed
+// allowedAttributesEffective attribute. The domain controller evaluates the
+// security descriptor of the object against the token of the caller, so the
+// result reflects group memberships and inherited permissions.
+func (o *object) WritableAttributes() (attrs []string, err error) {
+	return o.pullStringSlice("allowedAttributesEffective")
+}
+
+// CanWrite returns true if the bound identity is permitted to write the
+// attribute with the given name, according to WritableAttributes. Names are
+// matched case-insensitively.
+func (o *object) CanWrite(attr string) (ok bool, err error) {
+	attrs, err := o.WritableAttributes()
+	if err != nil {
+		return false, err
+	}
+	return containsFold(attrs, attr), nil
+}
+
+// AllowedChildClasses returns the classes of the objects that the bound
+// identity is permitted to create beneath the object, from the constructed
+// allowedChildClassesEffective attribute.
+func (o *object) AllowedChildClasses() (classes []string, err error) {
+	return o.pullStringSlice("allowedChildClassesEffective")
+}
+
+// pullStringSlice retrieves the given constructed string attribute from the
+// server and returns its values, or nil if it has none.
+func (o *object) pullStringSlice(name string) (values []string, err error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return nil, ErrClosed
+	}
+	if err = o.Pull(name); err != nil {
+		return
+	}
+	values, err = o.AttrStringSlice(name)
+	if errors.Is(err, api.ErrPropertyNotFound) {
+		return nil, nil
+	}
+	return
+}
+
+// AccountControlComputed retrieves the constructed
+// msDS-User-Account-Control-Computed attribute of the user. Unlike
+// userAccountControl, it reports the AccountControlLockout and
+// AccountControlPasswordExpired flags as evaluated by the domain controller.
+func (u *User) AccountControlComputed() (c AccountControl, err error) {
+	value, err := u.pullInt64("msDS-User-Account-Control-Computed")
+	if err != nil {
+		return
+	}
+	return AccountControl(uint32(value)), nil
+}
+
+// PasswordExpiry returns the time at which the user's password expires, as
+// computed by the domain controller from pwdLastSet and the effective
+// password policy of the user. If the password never expires then expires is
+// false and the returned time is the zero time.
+//
+// If the user must change their password at next logon, expires is true and
+// the returned time is the zero time.
+func (u *User) PasswordExpiry() (t time.Time, expires bool, err error) {
+	value, err := u.pullInt64("msDS-UserPasswordExpiryTimeComputed")
+	if err != nil {
+		return
+	}
+	if value == FileTimeNever {
+		return time.Time{}, false, nil
+	}
+	return TimeFromFileTime(value), true, nil
+}
+
+// pullInt64 retrieves the given constructed large integer attribute from the
+// server and returns its value.
+func (u *User) pullInt64(name string) (value int64, err error) {
+	u.m.Lock()
+	defer u.m.Unlock()
+	if u.closed() {
+		return 0, ErrClosed
+	}
+	if err = u.object.Pull(name); err != nil {
+		return
+	}
+	value, err = u.object.AttrInt64(name)
+	if errors.Is(err, api.ErrPropertyNotFound) {
+		return 0, nil
+	}
+	return
+}
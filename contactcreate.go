@@ -0,0 +1,90 @@
+package adsi
+
+import "errors"
+
+// ContactOptions holds the settings of a contact created by CreateContact.
+type ContactOptions struct {
+	// Name is the common name of the contact, which forms its relative
+	// distinguished name. If empty it is formed from the given name and
+	// surname, or the display name.
+	Name string
+
+	GivenName   string
+	Surname     string
+	DisplayName string
+	Description string
+
+	// Mail is the external e-mail address of the contact.
+	Mail string
+
+	// ProxyAddresses holds additional addresses of a mail-enabled contact.
+	// If Mail is set and no primary SMTP address is given, Mail becomes the
+	// primary SMTP address.
+	ProxyAddresses ProxyAddresses
+}
+
+// name returns the common name of the contact.
+func (opts *ContactOptions) name() string {
+	switch {
+	case opts.Name != "":
+		return opts.Name
+	case opts.GivenName != "" && opts.Surname != "":
+		return opts.GivenName + " " + opts.Surname
+	case opts.DisplayName != "":
+		return opts.DisplayName
+	}
+	return opts.GivenName + opts.Surname
+}
+
+// CreateContact creates a contact within the container with its common
+// attributes staged and committed in a single SetInfo, and returns it.
+//
+// The returned object consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned object when it is no
+// longer needed.
+func (c *Container) CreateContact(opts ContactOptions) (obj *Object, err error) {
+	name := opts.name()
+	if name == "" {
+		return nil, errors.New("contact name is required")
+	}
+	obj, err = c.Create("contact", "CN="+escapeRDNValue(name))
+	if err != nil {
+		return
+	}
+	displayName := opts.DisplayName
+	if displayName == "" {
+		displayName = name
+	}
+	for _, attr := range []struct{ name, value string }{
+		{"givenName", opts.GivenName},
+		{"sn", opts.Surname},
+		{"displayName", displayName},
+		{"description", opts.Description},
+		{"mail", opts.Mail},
+	} {
+		if attr.value == "" {
+			continue
+		}
+		if err = obj.PutString(attr.name, attr.value); err != nil {
+			obj.Close()
+			return nil, err
+		}
+	}
+	addrs := append(ProxyAddresses(nil), opts.ProxyAddresses...)
+	if opts.Mail != "" {
+		if _, ok := addrs.Primary("SMTP"); !ok {
+			addrs.SetPrimary("SMTP", opts.Mail)
+		}
+	}
+	if len(addrs) > 0 {
+		if err = obj.SetProxyAddresses(addrs); err != nil {
+			obj.Close()
+			return nil, err
+		}
+	}
+	if err = obj.SetInfo(); err != nil {
+		obj.Close()
+		return nil, err
+	}
+	return obj, nil
+}
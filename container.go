@@ -1,8 +1,10 @@
 package adsi
 
 import (
+	"context"
 	"io"
 	"sync"
+	"time"
 	"unsafe"
 
 	"github.com/go-ole/go-ole"
@@ -16,12 +18,13 @@ import (
 type Container struct {
 	m     sync.RWMutex
 	iface *api.IADsContainer
+	h     *hooks
 }
 
 // NewContainer returns a container that manages the given COM interface.
 func NewContainer(iface *api.IADsContainer) *Container {
 	comshim.Add(1)
-	return &Container{iface: iface}
+	return track(&Container{iface: iface})
 }
 
 func (c *Container) closed() bool {
@@ -30,15 +33,18 @@ func (c *Container) closed() bool {
 
 // Close will release resources consumed by the container. It should be
 // called when the container is no longer needed.
-func (c *Container) Close() {
+func (c *Container) Close() error {
 	c.m.Lock()
 	defer c.m.Unlock()
 	if c.closed() {
-		return
+		return nil
 	}
+	untrack(c)
 	defer comshim.Done()
-	c.iface.Release() // FIXME: What happens if release returns an error?
+	defer c.h.released()
+	c.h.run(func() { c.iface.Release() })
 	c.iface = nil
+	return nil
 }
 
 // Children returns an object iterator that provides access to the immediate
@@ -49,18 +55,41 @@ func (c *Container) Children() (iter *ObjectIter, err error) {
 	if c.closed() {
 		return nil, ErrClosed
 	}
-	iunknown, err := c.iface.NewEnum()
-	if err != nil {
-		return
+	c.h.run(func() {
+		defer beginCall()()
+		var iunknown *ole.IUnknown
+		iunknown, err = c.iface.NewEnum()
+		if err != nil {
+			err = c.err("NewEnum", err)
+			return
+		}
+		defer iunknown.Release()
+		var idispatch *ole.IDispatch
+		idispatch, err = iunknown.QueryInterface(ole.IID_IEnumVariant)
+		if err != nil {
+			return
+		}
+		iface := (*ole.IEnumVARIANT)(unsafe.Pointer(idispatch))
+		iter = NewObjectIter(iface)
+		iter.h = c.h.opened()
+	})
+	return
+}
+
+// ChildrenContext returns an object iterator like Children that is bound to
+// ctx. The iterator releases its enumerator as soon as ctx is done or the
+// last child has been returned, so an iterator that is abandoned part way is
+// released when ctx is cancelled without having to be closed. After ctx is
+// done Next returns the error of ctx.
+func (c *Container) ChildrenContext(ctx context.Context) (iter *ObjectIter, err error) {
+	if err = ctx.Err(); err != nil {
+		return nil, err
 	}
-	defer iunknown.Release()
-	idispatch, err := iunknown.QueryInterface(ole.IID_IEnumVariant)
-	if err != nil {
-		return
+	if iter, err = c.Children(); err != nil {
+		return nil, err
 	}
-	iface := (*ole.IEnumVARIANT)(unsafe.Pointer(idispatch))
-	iter = NewObjectIter(iface)
-	return
+	iter.bind(ctx)
+	return iter, nil
 }
 
 // Filter returns the current filter of the container.
@@ -70,12 +99,17 @@ func (c *Container) Filter() (filter []string, err error) {
 	if c.closed() {
 		return nil, ErrClosed
 	}
-	variant, err := c.iface.Filter()
-	if err != nil {
-		return
-	}
-	defer variant.Clear()
-	filter = variant.ToArray().ToStringArray()
+	c.h.run(func() {
+		defer beginCall()()
+		var variant *ole.VARIANT
+		variant, err = c.iface.Filter()
+		if err != nil {
+			err = c.err("Filter", err)
+			return
+		}
+		defer variant.Clear()
+		filter = variant.ToArray().ToStringArray()
+	})
 	return
 }
 
@@ -86,11 +120,15 @@ func (c *Container) SetFilter(filter ...string) (err error) {
 	if c.closed() {
 		return ErrClosed
 	}
-	safeByteArray := comutil.SafeArrayFromStringSlice(filter)
-	variant := ole.NewVariant(ole.VT_ARRAY|ole.VT_BSTR, int64(uintptr(unsafe.Pointer(safeByteArray))))
-	v := &variant
-	defer v.Clear()
-	return c.iface.SetFilter(v)
+	c.h.run(func() {
+		defer beginCall()()
+		safeByteArray := comutil.SafeArrayFromStringSlice(filter)
+		variant := ole.NewVariant(ole.VT_ARRAY|ole.VT_BSTR, int64(uintptr(unsafe.Pointer(safeByteArray))))
+		v := &variant
+		defer v.Clear()
+		err = c.err("SetFilter", c.iface.SetFilter(v))
+	})
+	return
 }
 
 // Object returns a descendant object with the given class and relative name.
@@ -103,17 +141,24 @@ func (c *Container) Object(class, name string) (obj *Object, err error) {
 	if c.closed() {
 		return nil, ErrClosed
 	}
-	idispatch, err := c.iface.GetObject(class, name)
-	if err != nil {
-		return
-	}
-	defer idispatch.Release()
-	iresult, err := idispatch.QueryInterface(comutil.GUID(comiid.IADs))
-	if err != nil {
-		return
-	}
-	iface := (*api.IADs)(unsafe.Pointer(iresult))
-	obj = NewObject(iface)
+	c.h.run(func() {
+		defer beginCall()()
+		var idispatch *ole.IDispatch
+		idispatch, err = c.iface.GetObject(class, name)
+		if err != nil {
+			err = c.err("GetObject "+name, err)
+			return
+		}
+		defer idispatch.Release()
+		var iresult *ole.IDispatch
+		iresult, err = idispatch.QueryInterface(comutil.GUID(comiid.IADs))
+		if err != nil {
+			return
+		}
+		iface := (*api.IADs)(unsafe.Pointer(iresult))
+		obj = NewObject(iface)
+		obj.h = c.h.opened()
+	})
 	return
 }
 
@@ -124,12 +169,14 @@ func (c *Container) ToObject() (o *Object, err error) {
 	if c.closed() {
 		return nil, ErrClosed
 	}
-	idispatch, err := c.iface.QueryInterface(comutil.GUID(comiid.IADs))
+	var idispatch *ole.IDispatch
+	c.h.run(func() { idispatch, err = c.iface.QueryInterface(comutil.GUID(comiid.IADs)) })
 	if err != nil {
 		return
 	}
 	iface := (*api.IADs)(unsafe.Pointer(idispatch))
 	o = NewObject(iface)
+	o.h = c.h.opened()
 	return
 }
 
@@ -144,17 +191,118 @@ func (c *Container) Container(class, name string) (container *Container, err err
 	if c.closed() {
 		return nil, ErrClosed
 	}
-	idispatch, err := c.iface.GetObject(class, name)
-	if err != nil {
-		return
+	c.h.run(func() {
+		defer beginCall()()
+		var idispatch *ole.IDispatch
+		idispatch, err = c.iface.GetObject(class, name)
+		if err != nil {
+			err = c.err("GetObject "+name, err)
+			return
+		}
+		defer idispatch.Release()
+		var iresult *ole.IDispatch
+		iresult, err = idispatch.QueryInterface(comutil.GUID(comiid.IADsContainer))
+		if err != nil {
+			return
+		}
+		iface := (*api.IADsContainer)(unsafe.Pointer(iresult))
+		container = NewContainer(iface)
+		container.h = c.h.opened()
+	})
+	return
+}
+
+// Create prepares a new object of the given class with the given relative
+// name, such as "CN=Jane Doe", within the container. The object is not
+// written to the directory until its mandatory attributes have been set and
+// SetInfo has been called on it.
+//
+// The returned object consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned object when it is no
+// longer needed.
+func (c *Container) Create(class, name string) (obj *Object, err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return nil, ErrClosed
 	}
-	defer idispatch.Release()
-	iresult, err := idispatch.QueryInterface(comutil.GUID(comiid.IADsContainer))
-	if err != nil {
-		return
+	c.h.run(func() {
+		defer beginCall()()
+		start := time.Now()
+		var idispatch *ole.IDispatch
+		idispatch, err = c.iface.Create(class, name)
+		err = c.err("Create "+name, err)
+		c.audit(start, AuditEvent{Op: AuditCreate, Class: class, Name: name, Err: err})
+		if err != nil {
+			return
+		}
+		defer idispatch.Release()
+		var iresult *ole.IDispatch
+		iresult, err = idispatch.QueryInterface(comutil.GUID(comiid.IADs))
+		if err != nil {
+			return
+		}
+		iface := (*api.IADs)(unsafe.Pointer(iresult))
+		obj = NewObject(iface)
+		obj.h = c.h.opened()
+	})
+	return
+}
+
+// Delete removes the object of the given class and relative name from the
+// container. The operation takes effect immediately. The object must not
+// have any children.
+func (c *Container) Delete(class, name string) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return ErrClosed
 	}
-	iface := (*api.IADsContainer)(unsafe.Pointer(iresult))
-	container = NewContainer(iface)
+	var err error
+	c.h.run(func() {
+		defer beginCall()()
+		start := time.Now()
+		err = c.iface.Delete(class, name)
+		err = c.err("Delete "+name, err)
+		c.audit(start, AuditEvent{Op: AuditDelete, Class: class, Name: name, Err: err})
+	})
+	return err
+}
+
+// MoveHere moves the object with the given ADsPath into the container and
+// returns it. If name is not empty the object is also given that relative
+// name, such as "CN=Jane Doe". Moving an object to its current container
+// with a new name renames it. The operation takes effect immediately.
+//
+// The returned object consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned object when it is no
+// longer needed.
+func (c *Container) MoveHere(path, name string) (obj *Object, err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return nil, ErrClosed
+	}
+	c.h.run(func() {
+		defer beginCall()()
+		start := time.Now()
+		var idispatch *ole.IDispatch
+		idispatch, err = c.iface.MoveHere(path, name)
+		err = wrapError("MoveHere", path, err)
+		c.audit(start, AuditEvent{Op: AuditMove, Name: name, Source: path, Err: err})
+		if err != nil {
+			return
+		}
+		defer idispatch.Release()
+		var iresult *ole.IDispatch
+		iresult, err = idispatch.QueryInterface(comutil.GUID(comiid.IADs))
+		if err != nil {
+			return
+		}
+		iface := (*api.IADs)(unsafe.Pointer(iresult))
+		obj = NewObject(iface)
+		obj.h = c.h.opened()
+	})
 	return
 }
 
@@ -162,52 +310,117 @@ func (c *Container) Container(class, name string) (container *Container, err err
 type ObjectIter struct {
 	m     sync.RWMutex
 	iface *ole.IEnumVARIANT
+	h     *hooks
+
+	// Objects retrieved from the enumerator but not yet returned
+	batch int
+	buf   []ole.VARIANT
+	pos   int
+	done  bool
+
+	// Context state of iterators returned by ChildrenContext and
+	// IterContext
+	ctx  context.Context
+	stop func() bool
+	end  error
 }
 
 // NewObjectIter returns an object iterator that provides access to the objects
 // contained in the given enumerator.
 func NewObjectIter(enumerator *ole.IEnumVARIANT) *ObjectIter {
 	comshim.Add(1)
-	return &ObjectIter{iface: enumerator}
+	return track(&ObjectIter{iface: enumerator, batch: 1})
+}
+
+// SetBatchSize sets the number of objects requested from the enumerator at a
+// time. By default objects are requested one at a time. Larger batches take
+// fewer round trips to iterate large containers and groups, at the cost of
+// holding the objects of a batch in memory until they are returned by Next.
+// Values less than 1 are treated as 1.
+func (iter *ObjectIter) SetBatchSize(n int) {
+	iter.m.Lock()
+	defer iter.m.Unlock()
+	iter.batch = max(n, 1)
 }
 
 // Next moves the iterator to the next object and returns a pointer to it. If it
 // has reached the end of the set it will return io.EOF. It the iterator has
 // already been closed it will return ErrClosed.
-//
-// FIXME: Make sure that io.EOF is being returned as expected. We might have
-// to intercept an internal error.
 func (iter *ObjectIter) Next() (obj *Object, err error) {
 	iter.m.Lock()
 	defer iter.m.Unlock()
+	if iter.end != nil {
+		return nil, iter.end
+	}
 	if iter.closed() {
 		return nil, ErrClosed
 	}
+	if iter.ctx != nil {
+		if err = iter.ctx.Err(); err != nil {
+			iter.finish(err)
+			return nil, err
+		}
+		defer func() {
+			if err == io.EOF {
+				iter.finish(err)
+			}
+		}()
+	}
+
+	iter.h.run(func() {
+		if iter.pos == len(iter.buf) {
+			if err = iter.fetch(); err != nil {
+				return
+			}
+		}
+		array := &iter.buf[iter.pos]
+		iter.pos++
+		defer array.Clear()
+
+		idispatch := array.ToIDispatch()
+		if idispatch == nil {
+			err = ErrNonDispatchVariant
+			return
+		}
+		// Note: Do *not* call idispatch.Release() here, as it will be called
+		//       automatically by array.Clear()
+
+		var iresult *ole.IDispatch
+		iresult, err = idispatch.QueryInterface(comutil.GUID(comiid.IADs))
+		if err != nil {
+			return
+		}
+		iface := (*api.IADs)(unsafe.Pointer(iresult))
+		obj = NewObject(iface)
+		obj.h = iter.h.opened()
+	})
+	return
+}
 
+// fetch retrieves the next batch of objects from the enumerator. It returns
+// io.EOF if there are none left. It must be called on the iterator's worker
+// while holding its lock.
+func (iter *ObjectIter) fetch() error {
 	// See https://msdn.microsoft.com/library/aa705990
-	array, length, err := iter.iface.Next(1)
-	if err != nil {
-		return
-	}
-	defer array.Clear()
-	if length == 0 {
-		return nil, io.EOF
+	iter.buf, iter.pos = iter.buf[:0], 0
+	if iter.done {
+		return io.EOF
 	}
-
-	idispatch := array.ToIDispatch()
-	if idispatch == nil {
-		return nil, ErrNonDispatchVariant
+	if cap(iter.buf) < iter.batch {
+		iter.buf = make([]ole.VARIANT, iter.batch)
 	}
-	// Note: Do *not* call idispatch.Release() here, as it will be called
-	//       automatically by array.Clear()
-
-	iresult, err := idispatch.QueryInterface(comutil.GUID(comiid.IADs))
+	n, err := api.EnumNext(iter.iface, iter.buf[:iter.batch])
 	if err != nil {
-		return
+		return err
 	}
-	iface := (*api.IADs)(unsafe.Pointer(iresult))
-	obj = NewObject(iface)
-	return
+	iter.buf = iter.buf[:n]
+	if n < iter.batch {
+		iter.done = true
+	}
+	if n == 0 {
+		return io.EOF
+	}
+	return nil
 }
 
 func (iter *ObjectIter) closed() bool {
@@ -216,13 +429,51 @@ func (iter *ObjectIter) closed() bool {
 
 // Close will release resources consumed by the iterator. It should be
 // called when the iterator is no longer needed.
-func (iter *ObjectIter) Close() {
+func (iter *ObjectIter) Close() error {
 	iter.m.Lock()
 	defer iter.m.Unlock()
+	if iter.stop != nil {
+		iter.stop()
+	}
+	iter.close()
+	return nil
+}
+
+// close releases the enumerator. The caller must hold the iterator's lock.
+func (iter *ObjectIter) close() {
 	if iter.closed() {
 		return
 	}
+	untrack(iter)
 	defer comshim.Done()
-	iter.iface.Release() // FIXME: What happens if release returns an error?
+	defer iter.h.released()
+	iter.h.run(func() {
+		for i := iter.pos; i < len(iter.buf); i++ {
+			iter.buf[i].Clear()
+		}
+		iter.iface.Release()
+	})
+	iter.buf = nil
 	iter.iface = nil
 }
+
+// bind ties the iterator to ctx, so that it is closed as soon as ctx is
+// done or the end of the set is reached. Once that happens Next returns the
+// error of ctx or io.EOF rather than ErrClosed.
+func (iter *ObjectIter) bind(ctx context.Context) {
+	iter.ctx = ctx
+	iter.stop = context.AfterFunc(ctx, func() {
+		iter.m.Lock()
+		defer iter.m.Unlock()
+		iter.finish(ctx.Err())
+	})
+}
+
+// finish closes an iterator bound to a context and records the error that
+// ended the iteration. The caller must hold the iterator's lock.
+func (iter *ObjectIter) finish(err error) {
+	if iter.end == nil && !iter.closed() {
+		iter.end = err
+		iter.close()
+	}
+}
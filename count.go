@@ -0,0 +1,88 @@
+package adsi
+
+import (
+	"errors"
+	"runtime"
+
+	"github.com/go-adsi/adsi/api"
+)
+
+// countSortKey is the attribute by which the virtual list view searches of
+// EstimateCount are sorted. Every object has a name, and the attribute is
+// indexed, so the server can answer without sorting the results itself.
+const countSortKey = "name"
+
+// EstimateCount returns the server's estimate of the number of objects
+// matching the query beneath the object with the given path. It is read from
+// the content count of a virtual list view search, for which the server
+// returns a single row, so it is cheap to obtain even for very large
+// searches and can drive progress reporting or size buffers before the
+// search itself is made.
+//
+// The count is approximate, and may differ from the number of rows returned
+// by the search, particularly when the directory is changing. The page size
+// and size limit of the query are ignored. DirSync queries cannot be
+// counted. Servers on which virtual list view searches are disabled return
+// an error.
+func (c *Client) EstimateCount(path string, q Query) (n int, err error) {
+	if q.DirSync {
+		return 0, &Error{Op: "EstimateCount", Path: path, Err: errors.New("DirSync searches cannot be counted")}
+	}
+	searcher, err := c.OpenSearcher(path)
+	if err != nil {
+		return 0, err
+	}
+	// The sort and virtual list view preferences cannot be cleared once they
+	// have been set, so the searcher is not reused
+	defer searcher.Close()
+	searcher.m.Lock()
+	defer searcher.m.Unlock()
+	if searcher.closed() {
+		return 0, ErrClosed
+	}
+	searcher.h.run(func() { n, err = searcher.estimateCount(q) })
+	return
+}
+
+// estimateCount executes a virtual list view search for the query on the
+// searcher's worker. The caller must hold the searcher's lock.
+func (s *Searcher) estimateCount(q Query) (n int, err error) {
+	defer beginCall()()
+	defer func() {
+		if err != nil {
+			err = wrapError("EstimateCount", s.searchBase(), err)
+		}
+	}()
+	// Virtual list view searches return a window of the results rather than
+	// pages of them
+	q.PageSize, q.SizeLimit = -1, 0
+	keys := []api.ADS_SORTKEY{api.NewSortKey(countSortKey, false)}
+	vlv := &api.ADS_VLV{Offset: 1}
+	err = s.iface.SetSearchPreference(append(q.prefs(), api.NewSearchPrefSortOn(keys), api.NewSearchPrefVLV(vlv)))
+	runtime.KeepAlive(keys)
+	runtime.KeepAlive(vlv)
+	if err != nil {
+		return
+	}
+	filter := q.Filter
+	if filter == "" {
+		filter = "(objectClass=*)"
+	}
+	handle, err := s.iface.ExecuteSearch(filter, []string{"ADsPath"})
+	if err != nil {
+		return
+	}
+	defer s.iface.CloseSearchHandle(handle)
+	if err = s.iface.GetFirstRow(handle); err != nil && err != api.ErrNoMoreRows {
+		return
+	}
+	var col api.ADS_SEARCH_COLUMN
+	if err = s.iface.GetColumn(handle, api.ADS_VLV_RESPONSE, &col); err != nil {
+		return
+	}
+	defer s.iface.FreeColumn(&col)
+	if values := col.ValueSlice(); len(values) > 0 {
+		n = int(values[0].VLVValue().ContentCount)
+	}
+	return
+}
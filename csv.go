@@ -0,0 +1,88 @@
+package adsi
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvValueSeparator separates the values of multi-valued attributes within a
+// single CSV field.
+const csvValueSeparator = ";"
+
+// WriteCSV writes the remaining rows in the result set to w as CSV, with a
+// header row followed by one record per row. If no columns are given the
+// columns of the first row are used.
+//
+// Values are formatted by type: GUIDs, SIDs and timestamps are converted in
+// the same way as by Row.MarshalJSON, byte slices are base64 encoded, and
+// the values of multi-valued attributes are joined with a semicolon.
+func (r *SearchResult) WriteCSV(w io.Writer, columns ...string) error {
+	cw := csv.NewWriter(w)
+	wroteHeader := false
+	for {
+		row, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if !wroteHeader {
+			if len(columns) == 0 {
+				for _, column := range row.Columns() {
+					columns = append(columns, column.Name)
+				}
+			}
+			if err = cw.Write(columns); err != nil {
+				return err
+			}
+			wroteHeader = true
+		}
+		record := make([]string, len(columns))
+		for i, name := range columns {
+			values := row.Attr(name)
+			fields := make([]string, len(values))
+			for j, value := range values {
+				fields[j] = csvValue(name, value)
+			}
+			record[i] = strings.Join(fields, csvValueSeparator)
+		}
+		if err = cw.Write(record); err != nil {
+			return err
+		}
+	}
+	if !wroteHeader && len(columns) > 0 {
+		if err := cw.Write(columns); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvValue formats a value of the given attribute as a CSV field.
+func csvValue(attr string, value interface{}) string {
+	switch v := jsonValue(attr, value).(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
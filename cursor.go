@@ -0,0 +1,182 @@
+package adsi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// cursorVersion is the version of the state written by Cursor.MarshalBinary.
+const cursorVersion = 1
+
+// ErrStaleCursor is returned by Cursor.Next when a resumed search no longer
+// returns the row the cursor was positioned on at its offset, because objects
+// have been added or removed since its state was saved. The export must be
+// restarted.
+var ErrStaleCursor = errors.New("search results have changed since the cursor was saved")
+
+// Cursor reads the rows of a paged search and records its position, so that
+// a long-running export can be stopped and resumed later, or handed to
+// another process, with MarshalBinary and ResumeCursor.
+//
+// ADSI keeps the page cookies of a search to itself, and domain controllers
+// only honour a cookie on the connection that received it, so the state of a
+// cursor holds the search parameters and the number of rows already read
+// rather than a cookie. A resumed cursor repeats the search and skips the
+// rows that were read before, checking that the last of them is the row
+// recorded in the state. Results are returned in the same order when the
+// directory has not changed; if it has, Next returns ErrStaleCursor.
+//
+// Resuming is therefore not free: the skipped rows are transferred from the
+// server again, so the first call to Next on a resumed cursor takes about as
+// long as reading its offset rows did, and an export that is resumed many
+// times reads its first rows as many times. The staleness check looks only at the
+// last skipped row. Changes that leave that row at the same offset, such as
+// an object added before it and another removed, go unnoticed, and the
+// resumed cursor may then return a row twice or skip one. Exports that must
+// be exact should be read in a single pass, or through a DirSync search.
+//
+// DirSync searches carry their own cookie, which can be saved with a
+// SyncSession, and cannot be read through a cursor.
+type Cursor struct {
+	dir    DirectorySearcher
+	state  cursorState
+	rows   RowIterator
+	skip   int
+	done   bool
+	closed bool
+}
+
+// cursorState is the serialized form of a cursor.
+type cursorState struct {
+	Version int    `json:"version"`
+	Path    string `json:"path"`
+	Query   Query  `json:"query"`
+	Offset  int    `json:"offset"`
+	Last    string `json:"last,omitempty"`
+}
+
+// NewCursor returns a cursor over the results of the given query beneath the
+// object with the given path. No search is made until Next is first called.
+//
+// The returned cursor consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned cursor when it is no
+// longer needed.
+func NewCursor(dir DirectorySearcher, path string, q Query) (*Cursor, error) {
+	if q.DirSync {
+		return nil, &Error{Op: "NewCursor", Path: path, Err: errors.New("DirSync searches cannot be read through a cursor")}
+	}
+	return &Cursor{dir: dir, state: cursorState{Version: cursorVersion, Path: path, Query: q}}, nil
+}
+
+// ResumeCursor returns a cursor positioned after the rows read by the cursor
+// whose state was returned by MarshalBinary. The search is made through dir,
+// which need not be the directory the original cursor used.
+//
+// The returned cursor consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned cursor when it is no
+// longer needed.
+func ResumeCursor(dir DirectorySearcher, state []byte) (*Cursor, error) {
+	var s cursorState
+	if err := json.Unmarshal(state, &s); err != nil {
+		return nil, &Error{Op: "ResumeCursor", Err: fmt.Errorf("invalid cursor state: %w", err)}
+	}
+	if s.Version != cursorVersion {
+		return nil, &Error{Op: "ResumeCursor", Path: s.Path, Err: fmt.Errorf("unsupported cursor state version %d", s.Version)}
+	}
+	if s.Offset < 0 || s.Query.DirSync {
+		return nil, &Error{Op: "ResumeCursor", Path: s.Path, Err: errors.New("invalid cursor state")}
+	}
+	return &Cursor{dir: dir, state: s, skip: s.Offset}, nil
+}
+
+// Path returns the path of the search base.
+func (c *Cursor) Path() string {
+	return c.state.Path
+}
+
+// Query returns the query of the search.
+func (c *Cursor) Query() Query {
+	return c.state.Query
+}
+
+// Offset returns the number of rows that have been read through the cursor
+// and any cursors it was resumed from.
+func (c *Cursor) Offset() int {
+	return c.state.Offset
+}
+
+// Next returns the next row, or io.EOF once every row has been returned. It
+// implements RowIterator.
+func (c *Cursor) Next() (*Row, error) {
+	if c.closed {
+		return nil, ErrClosed
+	}
+	if c.done {
+		return nil, io.EOF
+	}
+	if c.rows == nil {
+		if err := c.open(); err != nil {
+			return nil, err
+		}
+	}
+	row, err := c.rows.Next()
+	if err == io.EOF {
+		c.done = true
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.state.Offset++
+	c.state.Last = row.Path()
+	return row, nil
+}
+
+// open starts the search and skips the rows that were read before the
+// cursor was resumed.
+func (c *Cursor) open() error {
+	rows, err := c.dir.Find(c.state.Path, c.state.Query)
+	if err != nil {
+		return err
+	}
+	// The offset is only cleared once every row has been skipped, so that a
+	// retry after a failure skips them all again.
+	for n := c.skip; n > 0; n-- {
+		row, err := rows.Next()
+		if err == io.EOF {
+			err = ErrStaleCursor
+		}
+		if err == nil && n == 1 && !strings.EqualFold(row.Path(), c.state.Last) {
+			err = ErrStaleCursor
+		}
+		if err != nil {
+			rows.Close()
+			return err
+		}
+	}
+	c.skip = 0
+	c.rows = rows
+	return nil
+}
+
+// MarshalBinary returns the state of the cursor, which can be passed to
+// ResumeCursor to continue the search after the rows that have been read.
+// It implements encoding.BinaryMarshaler.
+func (c *Cursor) MarshalBinary() ([]byte, error) {
+	return json.Marshal(c.state)
+}
+
+// Close releases the search. The state of the cursor remains available from
+// MarshalBinary.
+func (c *Cursor) Close() error {
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	if c.rows == nil {
+		return nil
+	}
+	return c.rows.Close()
+}
@@ -0,0 +1,185 @@
+package adsi_test
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/go-adsi/adsi"
+	"github.com/go-adsi/adsi/adsitest"
+)
+
+const cursorBase = "LDAP://DC=example,DC=com"
+
+var cursorQuery = adsi.Query{Filter: "(objectClass=user)", Attributes: []string{"sAMAccountName"}}
+
+// newCursorDirectory returns a directory holding the users user1 to user5.
+func newCursorDirectory() *adsitest.Directory {
+	dir := adsitest.New()
+	dir.Add("DC=example,DC=com", adsitest.Attributes{"objectClass": {"top", "domain"}})
+	for _, name := range []string{"user1", "user2", "user3", "user4", "user5"} {
+		dir.Add("CN="+name+",DC=example,DC=com", adsitest.Attributes{
+			"objectClass":    {"top", "person", "organizationalPerson", "user"},
+			"sAMAccountName": {name},
+		})
+	}
+	return dir
+}
+
+// readCursor reads up to n rows from the cursor, or every row if n is
+// negative, and returns their account names.
+func readCursor(t *testing.T, c *adsi.Cursor, n int) (names []string) {
+	t.Helper()
+	for ; n != 0; n-- {
+		row, err := c.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() failed: %v", err)
+		}
+		names = append(names, row.AttrString("sAMAccountName"))
+	}
+	return
+}
+
+// saveCursor reads n rows through a new cursor and returns its state.
+func saveCursor(t *testing.T, dir adsi.DirectorySearcher, n int) []byte {
+	t.Helper()
+	c, err := adsi.NewCursor(dir, cursorBase, cursorQuery)
+	if err != nil {
+		t.Fatalf("NewCursor() failed: %v", err)
+	}
+	defer c.Close()
+	readCursor(t, c, n)
+	state, err := c.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() failed: %v", err)
+	}
+	return state
+}
+
+func TestCursorResume(t *testing.T) {
+	dir := newCursorDirectory()
+	state := saveCursor(t, dir, 2)
+
+	c, err := adsi.ResumeCursor(dir, state)
+	if err != nil {
+		t.Fatalf("ResumeCursor() failed: %v", err)
+	}
+	defer c.Close()
+	if c.Path() != cursorBase || !reflect.DeepEqual(c.Query(), cursorQuery) {
+		t.Errorf("ResumeCursor() = %s %+v, want %s %+v", c.Path(), c.Query(), cursorBase, cursorQuery)
+	}
+	if got := c.Offset(); got != 2 {
+		t.Errorf("Offset() before Next = %d, want 2", got)
+	}
+	if got, want := readCursor(t, c, -1), []string{"user3", "user4", "user5"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("resumed rows = %v, want %v", got, want)
+	}
+	if got := c.Offset(); got != 5 {
+		t.Errorf("Offset() after last row = %d, want 5", got)
+	}
+	if _, err := c.Next(); err != io.EOF {
+		t.Errorf("Next() after last row = %v, want io.EOF", err)
+	}
+}
+
+func TestCursorResumeTwice(t *testing.T) {
+	dir := newCursorDirectory()
+	c, err := adsi.ResumeCursor(dir, saveCursor(t, dir, 1))
+	if err != nil {
+		t.Fatalf("ResumeCursor() failed: %v", err)
+	}
+	readCursor(t, c, 2)
+	state, err := c.MarshalBinary()
+	c.Close()
+	if err != nil {
+		t.Fatalf("MarshalBinary() failed: %v", err)
+	}
+
+	c, err = adsi.ResumeCursor(dir, state)
+	if err != nil {
+		t.Fatalf("ResumeCursor() failed: %v", err)
+	}
+	defer c.Close()
+	if got, want := readCursor(t, c, -1), []string{"user4", "user5"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("resumed rows = %v, want %v", got, want)
+	}
+}
+
+func TestCursorStale(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(dir *adsitest.Directory)
+	}{
+		{"last row removed", func(dir *adsitest.Directory) {
+			dir.Remove("CN=user2,DC=example,DC=com")
+		}},
+		{"earlier row removed", func(dir *adsitest.Directory) {
+			dir.Remove("CN=user1,DC=example,DC=com")
+		}},
+		{"row added before", func(dir *adsitest.Directory) {
+			dir.Add("CN=user0,DC=example,DC=com", adsitest.Attributes{"objectClass": {"user"}})
+		}},
+		{"fewer rows than offset", func(dir *adsitest.Directory) {
+			for _, name := range []string{"user1", "user2", "user3", "user4"} {
+				dir.Remove("CN=" + name + ",DC=example,DC=com")
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newCursorDirectory()
+			state := saveCursor(t, dir, 2)
+			tt.change(dir)
+			c, err := adsi.ResumeCursor(dir, state)
+			if err != nil {
+				t.Fatalf("ResumeCursor() failed: %v", err)
+			}
+			defer c.Close()
+			if _, err := c.Next(); !errors.Is(err, adsi.ErrStaleCursor) {
+				t.Errorf("Next() = %v, want ErrStaleCursor", err)
+			}
+		})
+	}
+}
+
+func TestCursorRetry(t *testing.T) {
+	dir := newCursorDirectory()
+	state := saveCursor(t, dir, 2)
+	attrs := dir.Attributes("CN=user2,DC=example,DC=com")
+	dir.Remove("CN=user2,DC=example,DC=com")
+
+	c, err := adsi.ResumeCursor(dir, state)
+	if err != nil {
+		t.Fatalf("ResumeCursor() failed: %v", err)
+	}
+	defer c.Close()
+	if _, err := c.Next(); !errors.Is(err, adsi.ErrStaleCursor) {
+		t.Fatalf("Next() = %v, want ErrStaleCursor", err)
+	}
+	dir.Add("CN=user2,DC=example,DC=com", attrs)
+	if got, want := readCursor(t, c, -1), []string{"user3", "user4", "user5"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("rows after retry = %v, want %v", got, want)
+	}
+}
+
+func TestResumeCursorInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		state string
+	}{
+		{"not json", "cursor"},
+		{"unknown version", `{"version":2,"path":"LDAP://DC=example,DC=com"}`},
+		{"negative offset", `{"version":1,"path":"LDAP://DC=example,DC=com","offset":-1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := adsi.ResumeCursor(newCursorDirectory(), []byte(tt.state)); err == nil {
+				t.Errorf("ResumeCursor(%s) succeeded, want error", tt.state)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package adsi
+
+import (
+	"fmt"
+	"io"
+	"unsafe"
+
+	"github.com/go-adsi/adsi/api"
+	"github.com/go-adsi/adsi/comiid"
+	ole "github.com/go-ole/go-ole"
+	"github.com/scjalliance/comutil"
+)
+
+// describe formats the class, name and path of a directory object for
+// String.
+func describe(class, name, path string) string {
+	s := class
+	if name != "" {
+		if s != "" {
+			s += " "
+		}
+		s += name
+	}
+	if path != "" {
+		if s != "" {
+			s += " "
+		}
+		s += "(" + path + ")"
+	}
+	return s
+}
+
+// String returns the class, name and ADsPath of the object, such as
+// "user CN=Jane Doe (LDAP://CN=Jane Doe,CN=Users,DC=example,DC=com)". It
+// returns "closed object" if the object has been closed.
+func (o *object) String() string {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return "closed object"
+	}
+	var class, name, path string
+	o.h.run(func() {
+		class, _ = o.iface.Class()
+		name, _ = o.iface.Name()
+		path, _ = o.iface.AdsPath()
+	})
+	return describe(class, name, path)
+}
+
+// String returns the class, name and ADsPath of the container, in the same
+// form as Object.String. It returns "closed container" if the container has
+// been closed.
+func (c *Container) String() string {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return "closed container"
+	}
+	var s string
+	c.h.run(func() {
+		idispatch, err := c.iface.QueryInterface(comutil.GUID(comiid.IADs))
+		if err != nil {
+			return
+		}
+		defer idispatch.Release()
+		iface := (*api.IADs)(unsafe.Pointer(idispatch))
+		class, _ := iface.Class()
+		name, _ := iface.Name()
+		path, _ := iface.AdsPath()
+		s = describe(class, name, path)
+	})
+	return s
+}
+
+// String returns the most specific objectClass, name and ADsPath of the
+// row's object, in the same form as Object.String. Values that were not
+// requested by the search are omitted.
+func (r *Row) String() string {
+	var class string
+	if classes := r.AttrStringSlice("objectClass"); len(classes) > 0 {
+		class = classes[len(classes)-1]
+	}
+	return describe(class, r.AttrString("name"), r.Path())
+}
+
+// Dump writes the name and values of every attribute in the object's
+// property cache to w, one value per line, for debugging. Values are
+// formatted as described by Object.MarshalJSON.
+//
+// Only attributes that have been loaded into the cache are written. Use
+// Refresh or Pull beforehand to load them.
+func (o *object) Dump(w io.Writer) (err error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return ErrClosed
+	}
+	o.h.run(func() {
+		defer beginCall()()
+		var idispatch *ole.IDispatch
+		idispatch, err = o.queryInterface(comiid.IADsPropertyList)
+		if err != nil {
+			err = o.err("QueryInterface IADsPropertyList", err)
+			return
+		}
+		defer idispatch.Release()
+		list := (*api.IADsPropertyList)(unsafe.Pointer(idispatch))
+		var count int32
+		if count, err = list.PropertyCount(); err != nil {
+			err = o.err("PropertyCount", err)
+			return
+		}
+		if err = list.Reset(); err != nil {
+			err = o.err("Reset", err)
+			return
+		}
+		for i := int32(0); i < count; i++ {
+			var entry *api.IADsPropertyEntry
+			var ok bool
+			entry, ok, err = list.Next()
+			if err != nil {
+				err = o.err("Next", err)
+				return
+			}
+			if !ok {
+				break
+			}
+			name, nameErr := entry.Name()
+			entry.Release()
+			if nameErr != nil {
+				continue
+			}
+			if err = o.dumpAttr(w, name); err != nil {
+				return
+			}
+		}
+	})
+	return
+}
+
+// dumpAttr writes the values of the named attribute to w. It must be called
+// on the object's worker while holding the object's lock.
+func (o *object) dumpAttr(w io.Writer, name string) error {
+	values, err := o.attr(name)
+	if err != nil {
+		_, err = fmt.Fprintf(w, "%s: <%v>\n", name, err)
+		return err
+	}
+	for _, value := range values {
+		switch v := value.(type) {
+		case *ole.IDispatch:
+			if n, convErr := dispatchToInt64(v); convErr == nil {
+				value = n
+			} else {
+				value = "<IDispatch>"
+			}
+			v.Release()
+		case *ole.IUnknown:
+			value = "<IUnknown>"
+			v.Release()
+		}
+		value = jsonValue(name, value)
+		if b, ok := value.([]byte); ok {
+			value = fmt.Sprintf("%x", b)
+		}
+		if _, err = fmt.Fprintf(w, "%s: %v\n", name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package adsi
+
+import (
+	"errors"
+
+	"github.com/go-adsi/adsi/api"
+)
+
+// Delegation describes the Kerberos delegation settings of an account.
+type Delegation struct {
+	// Unconstrained is true if the account is trusted to delegate to any
+	// service, as set by TRUSTED_FOR_DELEGATION.
+	Unconstrained bool
+
+	// AllowedToDelegateTo holds the service principal names the account may
+	// delegate to with constrained delegation, from msDS-AllowedToDelegateTo.
+	AllowedToDelegateTo []string
+
+	// ProtocolTransition is true if constrained delegation may be used with
+	// any authentication protocol, as set by TRUSTED_TO_AUTH_FOR_DELEGATION.
+	ProtocolTransition bool
+
+	// Sensitive is true if the account is sensitive and cannot be
+	// delegated, as set by NOT_DELEGATED.
+	Sensitive bool
+
+	// AllowedToActOnBehalf holds the principals that may delegate to the
+	// account with resource-based constrained delegation, decoded from
+	// msDS-AllowedToActOnBehalfOfOtherIdentity.
+	AllowedToActOnBehalf []SID
+}
+
+// Delegation retrieves the Kerberos delegation settings of the object.
+func (o *object) Delegation() (d *Delegation, err error) {
+	row, err := o.baseRow(Query{Attributes: []string{"userAccountControl", "msDS-AllowedToDelegateTo"}})
+	if err != nil {
+		return
+	}
+	uac := AccountControl(uint32(row.AttrInt64("userAccountControl")))
+	d = &Delegation{
+		Unconstrained:       uac.Has(AccountControlTrustedForDelegation),
+		AllowedToDelegateTo: row.AttrStringSlice("msDS-AllowedToDelegateTo"),
+		ProtocolTransition:  uac.Has(AccountControlTrustedToAuthForDelegation),
+		Sensitive:           uac.Has(AccountControlNotDelegated),
+	}
+	if d.AllowedToActOnBehalf, err = o.AllowedToActOnBehalf(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// SetUnconstrainedDelegation sets or clears the TRUSTED_FOR_DELEGATION flag
+// of the object. Enabling unconstrained delegation disables protocol
+// transition, as the two are mutually exclusive in the Active Directory
+// tools. The value must be commited with SetInfo to be made persistent.
+func (o *object) SetUnconstrainedDelegation(enabled bool) error {
+	if enabled {
+		return o.updateAccountControl(AccountControlTrustedForDelegation, AccountControlTrustedToAuthForDelegation)
+	}
+	return o.updateAccountControl(0, AccountControlTrustedForDelegation)
+}
+
+// SetConstrainedDelegation replaces msDS-AllowedToDelegateTo with the given
+// service principal names and sets TRUSTED_TO_AUTH_FOR_DELEGATION according
+// to protocolTransition. Unconstrained delegation is disabled. If no SPNs are
+// given constrained delegation is disabled entirely. The value must be
+// commited with SetInfo to be made persistent.
+func (o *object) SetConstrainedDelegation(spns []string, protocolTransition bool) error {
+	if len(spns) == 0 {
+		if err := o.PutEx(api.ADS_PROPERTY_CLEAR, "msDS-AllowedToDelegateTo"); err != nil {
+			return err
+		}
+		return o.updateAccountControl(0, AccountControlTrustedToAuthForDelegation)
+	}
+	if err := o.PutEx(api.ADS_PROPERTY_UPDATE, "msDS-AllowedToDelegateTo", stringsToValues(spns)...); err != nil {
+		return err
+	}
+	if protocolTransition {
+		return o.updateAccountControl(AccountControlTrustedToAuthForDelegation, AccountControlTrustedForDelegation)
+	}
+	return o.updateAccountControl(0, AccountControlTrustedForDelegation|AccountControlTrustedToAuthForDelegation)
+}
+
+// AllowedToActOnBehalf decodes the security descriptor held by
+// msDS-AllowedToActOnBehalfOfOtherIdentity and returns the security
+// identifiers of the principals that may delegate to the object with
+// resource-based constrained delegation. An empty slice is returned if the
+// attribute is not set.
+func (o *object) AllowedToActOnBehalf() ([]SID, error) {
+	return o.allowedPrincipals("msDS-AllowedToActOnBehalfOfOtherIdentity")
+}
+
+// SetAllowedToActOnBehalf replaces msDS-AllowedToActOnBehalfOfOtherIdentity
+// with a security descriptor that allows the given principals to delegate to
+// the object with resource-based constrained delegation. If no principals
+// are given the attribute is cleared. The value must be commited with
+// SetInfo to be made persistent.
+func (o *object) SetAllowedToActOnBehalf(sids ...SID) error {
+	return o.setAllowedPrincipals("msDS-AllowedToActOnBehalfOfOtherIdentity", allowedPrincipalRights, sids)
+}
+
+// updateAccountControl sets and clears the given flags of the cached
+// userAccountControl attribute of the object.
+func (o *object) updateAccountControl(set, clear AccountControl) error {
+	c, err := o.AccountControl()
+	if errors.Is(err, api.ErrPropertyNotFound) {
+		return errors.New("account has no userAccountControl attribute")
+	}
+	if err != nil {
+		return err
+	}
+	return o.SetAccountControl(c&^clear | set)
+}
@@ -0,0 +1,101 @@
+package adsi
+
+// The interfaces in this file describe the parts of the package that most
+// applications depend on, so that code can be written against them and
+// tested with a fake directory, such as the one provided by the adsitest
+// package, instead of a domain controller, or run over plain LDAP with the
+// ldapdir package on hosts without ADSI. They are deliberately small:
+// applications that need more of the package should define their own
+// interfaces in the same way.
+
+// Entry is a directory object. *Object implements Entry.
+type Entry interface {
+	// Name returns the relative name of the object, such as "CN=Jane Doe".
+	Name() (string, error)
+
+	// Class returns the most specific class of the object, such as "user".
+	Class() (string, error)
+
+	// Path returns the ADsPath of the object.
+	Path() (string, error)
+
+	// Attr returns the values of the attribute with the given name, as
+	// described by Object.Attr.
+	Attr(name string) ([]interface{}, error)
+
+	// PutEx changes the values of the attribute with the given name, as
+	// described by Object.PutEx. Changes take effect when SetInfo is
+	// called.
+	PutEx(controlCode uint32, name string, values ...interface{}) error
+
+	// SetInfo writes the changed attributes to the directory.
+	SetInfo() error
+
+	// Close releases the object.
+	Close() error
+}
+
+// RowIterator iterates over the rows returned by a search. *SearchResult
+// implements RowIterator.
+type RowIterator interface {
+	// Next returns the next row, or io.EOF once every row has been
+	// returned.
+	Next() (*Row, error)
+
+	// Close releases the result set.
+	Close() error
+}
+
+// Binder opens directory objects by ADsPath. *Client implements Binder.
+type Binder interface {
+	Bind(path string) (Entry, error)
+}
+
+// DirectorySearcher searches the directory beneath an ADsPath. *Client
+// implements DirectorySearcher.
+type DirectorySearcher interface {
+	Find(path string, q Query) (RowIterator, error)
+}
+
+// Directory combines Binder and DirectorySearcher. *Client implements
+// Directory.
+type Directory interface {
+	Binder
+	DirectorySearcher
+}
+
+// The types of the package that the interfaces describe.
+var (
+	_ Directory   = (*Client)(nil)
+	_ Entry       = (*Object)(nil)
+	_ RowIterator = (*SearchResult)(nil)
+	_ RowIterator = (*Cursor)(nil)
+)
+
+// Bind opens the object with the given path like Open, and returns it as an
+// Entry so that Client implements Binder.
+func (c *Client) Bind(path string) (Entry, error) {
+	obj, err := c.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// Find searches beneath the object with the given path like Search, and
+// returns the result set as a RowIterator so that Client implements
+// DirectorySearcher.
+func (c *Client) Find(path string, q Query) (RowIterator, error) {
+	result, err := c.Search(path, q)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// NewRow returns a row holding the given columns. Rows are normally returned
+// by a search. NewRow allows implementations of RowIterator, such as fakes
+// used in tests, to produce them.
+func NewRow(columns ...Column) *Row {
+	return &Row{columns: columns}
+}
@@ -0,0 +1,202 @@
+package adsi
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"github.com/go-adsi/adsi/adspath"
+)
+
+// Invalid DirSync cookies are rejected with ERROR_DS_UNWILLING_TO_PERFORM,
+// or with E_INVALIDARG if the cookie is malformed.
+const (
+	hresultUnwillingToPerform = 0x80072035
+	hresultInvalidArg         = 0x80070057
+)
+
+// CookieStore persists the cookie of a SyncSession between runs.
+type CookieStore interface {
+	// Load returns the saved cookie, or nil if no cookie has been saved.
+	Load() ([]byte, error)
+
+	// Save replaces the saved cookie.
+	Save(cookie []byte) error
+}
+
+// FileCookieStore is a CookieStore that keeps the cookie in the file with
+// the given name.
+type FileCookieStore string
+
+// Load returns the contents of the file, or nil if it does not exist.
+func (f FileCookieStore) Load() ([]byte, error) {
+	cookie, err := os.ReadFile(string(f))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return cookie, err
+}
+
+// Save writes the cookie to a temporary file and renames it over the file,
+// so that an interrupted save leaves the previous cookie intact.
+func (f FileCookieStore) Save(cookie []byte) error {
+	tmp := string(f) + ".tmp"
+	if err := os.WriteFile(tmp, cookie, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, string(f))
+}
+
+// SyncOptions controls the objects and attributes returned by a
+// SyncSession.
+type SyncOptions struct {
+	// Server is the domain controller to synchronize with. DirSync cookies
+	// may be used with any domain controller of the domain. If empty a
+	// domain controller of the computer's domain is chosen.
+	Server string
+
+	// Filter is an LDAP search filter. If empty every object is returned.
+	Filter string
+
+	// Attributes is the list of attributes to return for each changed
+	// object. If empty all attributes are returned.
+	Attributes []string
+
+	// Flags holds a combination of the LDAP_DIRSYNC_* flags in the api
+	// package. Without api.LDAP_DIRSYNC_OBJECT_SECURITY the caller needs the
+	// Replicating Directory Changes right on the naming context.
+	Flags uint32
+
+	// Store persists the cookie between runs. If nil the cookie is only
+	// kept in memory.
+	Store CookieStore
+}
+
+// SyncSession retrieves the changes made to a naming context with
+// successive DirSync searches, persisting its progress so that it can be
+// resumed after a restart.
+type SyncSession struct {
+	client        *Client
+	namingContext string
+	opts          SyncOptions
+	cookie        []byte
+}
+
+// NewSyncSession returns a session that synchronizes the naming context
+// with the given distinguished name, resuming from the cookie held by
+// opts.Store if there is one. Each synchronization is made with an ephemeral
+// client, using the security context of the application and the default
+// client flags.
+func NewSyncSession(namingContext string, opts SyncOptions) (*SyncSession, error) {
+	return newSyncSession(nil, namingContext, opts)
+}
+
+// NewSyncSession returns a session that synchronizes the naming context
+// with the given distinguished name, resuming from the cookie held by
+// opts.Store if there is one. Each synchronization is made through the
+// client, using the existing security context of the application and any
+// flags specified via SetFlags, and fails once the client has been closed.
+func (c *Client) NewSyncSession(namingContext string, opts SyncOptions) (*SyncSession, error) {
+	return newSyncSession(c, namingContext, opts)
+}
+
+func newSyncSession(c *Client, namingContext string, opts SyncOptions) (*SyncSession, error) {
+	s := &SyncSession{client: c, namingContext: namingContext, opts: opts}
+	if opts.Store != nil {
+		cookie, err := opts.Store.Load()
+		if err != nil {
+			return nil, err
+		}
+		s.cookie = cookie
+	}
+	return s, nil
+}
+
+// Cookie returns the current cookie of the session.
+func (s *SyncSession) Cookie() []byte {
+	return s.cookie
+}
+
+// Reset discards the cookie, so that the next call to Sync performs a full
+// synchronization.
+func (s *SyncSession) Reset() error {
+	s.cookie = nil
+	if s.opts.Store != nil {
+		return s.opts.Store.Save(nil)
+	}
+	return nil
+}
+
+// Sync calls fn for each object that has changed since the previous
+// synchronization. Each row holds only the attributes that have changed,
+// and deleted objects are returned with isDeleted set. Once every row has
+// been handled the new cookie is saved.
+//
+// If the session has no cookie, or the server rejects its cookie, every
+// object is returned and full is true, in which case the caller should
+// treat the results as a complete replacement of its copy of the data.
+//
+// If fn returns an error Sync stops and returns it without saving the
+// cookie, so the same changes are returned again by the next call.
+func (s *SyncSession) Sync(fn func(row *Row) error) (full bool, err error) {
+	full = len(s.cookie) == 0
+	cookie, err := s.sync(fn)
+	if err != nil && !full && isInvalidDirSyncCookie(err) {
+		full = true
+		s.cookie = nil
+		cookie, err = s.sync(fn)
+	}
+	if err != nil {
+		return full, err
+	}
+	if s.opts.Store != nil {
+		if err = s.opts.Store.Save(cookie); err != nil {
+			return full, err
+		}
+	}
+	s.cookie = cookie
+	return full, nil
+}
+
+// sync runs a single DirSync search with the current cookie and returns the
+// updated cookie.
+func (s *SyncSession) sync(fn func(row *Row) error) (cookie []byte, err error) {
+	path := (&adspath.Path{Scheme: "LDAP", Host: s.opts.Server, Path: adspath.EscapeDN(s.namingContext)}).String()
+	q := Query{
+		Filter:        s.opts.Filter,
+		Attributes:    s.opts.Attributes,
+		DirSync:       true,
+		DirSyncCookie: s.cookie,
+		DirSyncFlags:  s.opts.Flags,
+	}
+	var result *SearchResult
+	if s.client == nil {
+		result, err = Search(path, q)
+	} else {
+		result, err = s.client.Search(path, q)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+	for {
+		row, err := result.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err = fn(row); err != nil {
+			return nil, err
+		}
+	}
+	return result.DirSyncCookie()
+}
+
+// isInvalidDirSyncCookie returns true if err reports that the server
+// rejected a DirSync cookie.
+func isInvalidDirSyncCookie(err error) bool {
+	code, ok := hresult(err)
+	return ok && (code == hresultUnwillingToPerform || code == hresultInvalidArg)
+}
@@ -0,0 +1,160 @@
+package adsi
+
+import (
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/go-adsi/adsi/adspath"
+	"github.com/go-adsi/adsi/api"
+	"github.com/google/uuid"
+)
+
+// DiscoverOptions specifies the requirements of the domain controller located
+// by Discover. The zero value locates any domain controller of the computer's
+// primary domain that provides directory services.
+type DiscoverOptions struct {
+	// Domain is the DNS or NetBIOS name of the domain. If empty the primary
+	// domain of the computer is used.
+	Domain string
+
+	// Site is the site in which a domain controller is preferred. If empty
+	// the site of the computer is used.
+	Site string
+
+	// GlobalCatalog requires the domain controller to be a global catalog
+	// server.
+	GlobalCatalog bool
+
+	// PDC requires the domain controller to hold the PDC emulator role.
+	PDC bool
+
+	// Writable requires a writable domain controller, which excludes
+	// read-only domain controllers.
+	Writable bool
+
+	// AvoidSelf prevents the local computer from being returned when it is
+	// itself a domain controller.
+	AvoidSelf bool
+
+	// Force bypasses the locator's cache and forces a new discovery.
+	Force bool
+}
+
+// flags returns the DsGetDcName flags that implement the options.
+func (o *DiscoverOptions) flags() uint32 {
+	flags := api.DS_DIRECTORY_SERVICE_REQUIRED | api.DS_RETURN_DNS_NAME
+	if o.GlobalCatalog {
+		flags |= api.DS_GC_SERVER_REQUIRED
+	}
+	if o.PDC {
+		flags |= api.DS_PDC_REQUIRED
+	}
+	if o.Writable {
+		flags |= api.DS_WRITABLE_REQUIRED
+	}
+	if o.AvoidSelf {
+		flags |= api.DS_AVOID_SELF
+	}
+	if o.Force {
+		flags |= api.DS_FORCE_REDISCOVERY
+	}
+	return flags
+}
+
+// DomainController describes a domain controller located by Discover.
+type DomainController struct {
+	// Name is the DNS host name of the domain controller.
+	Name string
+
+	// Address is the network address of the domain controller.
+	Address string
+
+	// Domain is the DNS name of the domain the domain controller belongs to.
+	Domain string
+
+	// DomainGUID is the GUID of the domain.
+	DomainGUID uuid.UUID
+
+	// Forest is the DNS name of the forest root domain.
+	Forest string
+
+	// Site is the site the domain controller belongs to.
+	Site string
+
+	// ClientSite is the site of the computer that performed the discovery.
+	ClientSite string
+
+	// Flags holds the DS_*_FLAG capability flags defined in the api package.
+	Flags uint32
+}
+
+// IsGlobalCatalog returns true if the domain controller is a global catalog
+// server.
+func (dc *DomainController) IsGlobalCatalog() bool {
+	return dc.Flags&api.DS_GC_FLAG != 0
+}
+
+// IsPDC returns true if the domain controller holds the PDC emulator role.
+func (dc *DomainController) IsPDC() bool {
+	return dc.Flags&api.DS_PDC_FLAG != 0
+}
+
+// IsReadOnly returns true if the domain controller is a read-only domain
+// controller.
+func (dc *DomainController) IsReadOnly() bool {
+	return dc.Flags&api.DS_WRITABLE_FLAG == 0
+}
+
+// IsClosest returns true if the domain controller is in the same site as the
+// computer that performed the discovery.
+func (dc *DomainController) IsClosest() bool {
+	return dc.Flags&api.DS_CLOSEST_FLAG != 0
+}
+
+// Path returns an LDAP ADsPath that binds to the object with the given
+// distinguished name on the domain controller.
+func (dc *DomainController) Path(dn string) string {
+	return (&adspath.Path{Scheme: "LDAP", Host: dc.Name, Path: adspath.EscapeDN(dn)}).String()
+}
+
+// Discover locates a domain controller that satisfies the given options
+// using the Windows domain controller locator (DsGetDcName).
+func Discover(opts DiscoverOptions) (dc *DomainController, err error) {
+	info, err := api.DsGetDcName("", opts.Domain, opts.Site, opts.flags())
+	if err != nil {
+		return
+	}
+	return &DomainController{
+		Name:       strings.TrimPrefix(info.DomainControllerName, `\\`),
+		Address:    strings.TrimPrefix(info.DomainControllerAddress, `\\`),
+		Domain:     info.DomainName,
+		DomainGUID: info.DomainGUID,
+		Forest:     info.DnsForestName,
+		Site:       info.DcSiteName,
+		ClientSite: info.ClientSiteName,
+		Flags:      info.Flags,
+	}, nil
+}
+
+// DiscoverAll returns the DNS host names of all domain controllers that
+// advertise LDAP service for the given DNS domain. If site is non-empty only
+// domain controllers that cover that site are returned.
+//
+// DiscoverAll queries the DNS SRV records registered by domain controllers
+// and does not require the computer to be joined to the domain.
+func DiscoverAll(domain, site string) (servers []string, err error) {
+	name := "dc._msdcs." + domain
+	if site != "" {
+		name = site + "._sites." + name
+	}
+	_, records, err := net.LookupSRV("ldap", "tcp", name)
+	if err != nil {
+		return
+	}
+	for _, record := range records {
+		servers = append(servers, strings.TrimSuffix(record.Target, "."))
+	}
+	sort.Strings(servers)
+	return
+}
@@ -0,0 +1,108 @@
+package adsi
+
+import "strings"
+
+// splitDN splits a distinguished name into its relative distinguished names,
+// honoring escaped commas.
+func splitDN(dn string) (rdns []string) {
+	start := 0
+	for i := 0; i < len(dn); i++ {
+		switch dn[i] {
+		case '\\':
+			i++
+		case ',':
+			rdns = append(rdns, strings.TrimSpace(dn[start:i]))
+			start = i + 1
+		}
+	}
+	if start < len(dn) {
+		rdns = append(rdns, strings.TrimSpace(dn[start:]))
+	}
+	return
+}
+
+// parentDN returns the distinguished name of the parent of dn, or an empty
+// string if dn has no parent.
+func parentDN(dn string) string {
+	rdns := splitDN(dn)
+	if len(rdns) < 2 {
+		return ""
+	}
+	return strings.Join(rdns[1:], ",")
+}
+
+// rdnValue returns the unescaped value of the first relative distinguished
+// name of dn, such as "Default-First-Site-Name" for
+// "CN=Default-First-Site-Name,CN=Sites,CN=Configuration,DC=example,DC=com".
+func rdnValue(dn string) string {
+	rdns := splitDN(dn)
+	if len(rdns) == 0 {
+		return ""
+	}
+	rdn := rdns[0]
+	if i := strings.IndexByte(rdn, '='); i >= 0 {
+		rdn = rdn[i+1:]
+	}
+	return unescapeDNValue(rdn)
+}
+
+// unescapeDNValue removes the backslash escapes from an attribute value of a
+// distinguished name. Hex escapes such as "\0A" are decoded.
+func unescapeDNValue(value string) string {
+	if strings.IndexByte(value, '\\') < 0 {
+		return value
+	}
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c != '\\' || i+1 == len(value) {
+			b.WriteByte(c)
+			continue
+		}
+		if i+2 < len(value) && isHex(value[i+1]) && isHex(value[i+2]) {
+			b.WriteByte(unhex(value[i+1])<<4 | unhex(value[i+2]))
+			i += 2
+			continue
+		}
+		b.WriteByte(value[i+1])
+		i++
+	}
+	return b.String()
+}
+
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
+
+// escapeRDNValue escapes the special characters of an attribute value for
+// use in a relative distinguished name, as described by RFC 4514.
+func escapeRDNValue(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c == ',' || c == '+' || c == '"' || c == '\\' || c == '<' || c == '>' || c == ';' || c == '=':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case (c == ' ' && (i == 0 || i == len(value)-1)) || (c == '#' && i == 0):
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c == 0:
+			b.WriteString(`\00`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
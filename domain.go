@@ -0,0 +1,76 @@
+package adsi
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/go-adsi/adsi/api"
+	"github.com/scjalliance/comshim"
+)
+
+// Domain provides access to an Active Directory domain object, which is the
+// root of a domain naming context such as "DC=example,DC=com".
+type Domain struct {
+	object
+}
+
+// NewDomain returns a domain that manages the given COM interface.
+func NewDomain(iface *api.IADs) *Domain {
+	comshim.Add(1)
+	return track(&Domain{object{iface: iface}})
+}
+
+// domainDN returns the distinguished name of the domain naming context that
+// contains the object with the given distinguished name. It is formed from
+// the trailing DC components of dn.
+func domainDN(dn string) string {
+	lower := strings.ToLower(dn)
+	if strings.HasPrefix(lower, "dc=") {
+		return dn
+	}
+	for i := 0; i < len(lower); i++ {
+		switch lower[i] {
+		case '\\':
+			i++
+		case ',':
+			if strings.HasPrefix(lower[i+1:], "dc=") {
+				return dn[i+1:]
+			}
+		}
+	}
+	return ""
+}
+
+// domainControllerFilter matches the computer accounts of writable and
+// read-only domain controllers.
+const domainControllerFilter = "(&(objectCategory=computer)(|(userAccountControl:1.2.840.113556.1.4.803:=8192)(userAccountControl:1.2.840.113556.1.4.803:=67108864)))"
+
+// DomainControllers returns the DNS host names of the domain controllers of
+// the domain, including read-only domain controllers. It is determined from
+// the computer accounts held by the domain.
+func (d *Domain) DomainControllers() (servers []string, err error) {
+	dn, err := d.DN()
+	if err != nil {
+		return
+	}
+	return d.domainControllers(dn)
+}
+
+// domainControllers returns the DNS host names of the domain controllers of
+// the domain with the given distinguished name.
+func (o *object) domainControllers(domain string) (servers []string, err error) {
+	rows, err := o.searchDNAll(domain, Query{
+		Filter:     domainControllerFilter,
+		Attributes: []string{"dNSHostName"},
+	})
+	if err != nil {
+		return
+	}
+	for _, row := range rows {
+		if name := row.AttrString("dNSHostName"); name != "" {
+			servers = append(servers, name)
+		}
+	}
+	sort.Strings(servers)
+	return
+}
@@ -0,0 +1,115 @@
+package adsi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-adsi/adsi/api"
+)
+
+// ErrInvalidTTL is returned when a dynamic object is given a time to live
+// that is not a positive number of seconds.
+var ErrInvalidTTL = errors.New("invalid time to live")
+
+// Dynamic objects carry the dynamicObject auxiliary class and are deleted by
+// the directory once their entryTTL attribute counts down to zero. The domain
+// controller enforces a minimum time to live, which defaults to 15 minutes
+// and is configured by the DynamicObjectMinTTL setting of the forest.
+
+// CreateDynamic prepares a new dynamic object of the given class with the
+// given relative name within the container. The object will be deleted by
+// the directory once the given time to live has elapsed, unless it is
+// refreshed with RefreshTTL. The time to live is rounded up to a whole number
+// of seconds.
+//
+// As with Create, the object is not written to the directory until its
+// mandatory attributes have been set and SetInfo has been called on it. The
+// dynamicObject class can only be added when the object is created.
+//
+// The returned object consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned object when it is no
+// longer needed.
+func (c *Container) CreateDynamic(class, name string, ttl time.Duration) (obj *Object, err error) {
+	seconds, err := ttlSeconds(ttl)
+	if err != nil {
+		return
+	}
+	obj, err = c.Create(class, name)
+	if err != nil {
+		return
+	}
+	if err = obj.PutEx(api.ADS_PROPERTY_UPDATE, "objectClass", class, "dynamicObject"); err == nil {
+		err = obj.PutInt("entryTTL", seconds)
+	}
+	if err != nil {
+		obj.Close()
+		return nil, err
+	}
+	return
+}
+
+// IsDynamic returns true if the object is a dynamic object.
+func (o *object) IsDynamic() (dynamic bool, err error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return false, ErrClosed
+	}
+	classes, err := o.AttrStringSlice("objectClass")
+	if err != nil {
+		return
+	}
+	for _, class := range classes {
+		if strings.EqualFold(class, "dynamicObject") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// EntryTTL retrieves the remaining time to live of a dynamic object from the
+// constructed entryTTL attribute. Zero is returned for objects that are not
+// dynamic.
+func (o *object) EntryTTL() (ttl time.Duration, err error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return 0, ErrClosed
+	}
+	if err = o.Pull("entryTTL"); err != nil {
+		return
+	}
+	seconds, err := o.AttrInt("entryTTL")
+	if errors.Is(err, api.ErrPropertyNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// RefreshTTL resets the time to live of a dynamic object to the given
+// duration, which is rounded up to a whole number of seconds. Unlike most
+// setters the change is written to the directory immediately.
+func (o *object) RefreshTTL(ttl time.Duration) error {
+	seconds, err := ttlSeconds(ttl)
+	if err != nil {
+		return err
+	}
+	if err := o.PutInt("entryTTL", seconds); err != nil {
+		return err
+	}
+	return o.SetInfo()
+}
+
+// ttlSeconds converts ttl to the number of seconds stored in entryTTL.
+func ttlSeconds(ttl time.Duration) (int, error) {
+	seconds := (ttl + time.Second - 1) / time.Second
+	if seconds <= 0 || seconds > 1<<31-1 {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidTTL, ttl)
+	}
+	return int(seconds), nil
+}
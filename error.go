@@ -0,0 +1,364 @@
+package adsi
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"unsafe"
+
+	"github.com/go-adsi/adsi/api"
+	"github.com/go-adsi/adsi/comiid"
+	"github.com/scjalliance/comutil"
+)
+
+// HRESULT facilities that commonly appear in ADSI errors.
+const (
+	FacilityWin32 = 7
+	FacilityADSI  = 5
+)
+
+// Sentinel errors that can be matched against an *Error with errors.Is. They
+// match any error with the same HRESULT, regardless of the operation or path
+// that produced it.
+var (
+	// ErrNoSuchObject reports that the directory object does not exist.
+	ErrNoSuchObject = &Error{HRESULT: hresultNoSuchObject}
+
+	// ErrAccessDenied reports that the caller does not have the rights
+	// required by the operation.
+	ErrAccessDenied = &Error{HRESULT: 0x80070005}
+
+	// ErrObjectExists reports that an object with the same name already
+	// exists.
+	ErrObjectExists = &Error{HRESULT: 0x80071392}
+)
+
+// Error is returned when a call to ADSI fails. It records the operation that
+// failed, the ADsPath of the object it was made on and the HRESULT returned
+// by ADSI.
+type Error struct {
+	// Op is the name of the operation that failed, such as "Open" or
+	// "SetInfo".
+	Op string
+
+	// Path is the ADsPath of the object, if it is known.
+	Path string
+
+	// HRESULT is the COM error code, or zero if the error did not come from
+	// COM.
+	HRESULT uint32
+
+	// Err is the underlying error.
+	Err error
+
+	// Extended holds the extended error information recorded by the ADSI
+	// provider, if there is any. It often explains the cause of the error
+	// when the HRESULT alone does not.
+	Extended *api.ExtendedError
+}
+
+// Facility returns the facility of the HRESULT, such as FacilityWin32.
+func (e *Error) Facility() uint16 {
+	return uint16(e.HRESULT>>16) & 0x1fff
+}
+
+// Code returns the code held by the HRESULT. For errors in FacilityWin32 it
+// is a Win32 error code, such as 8240 (ERROR_DS_NO_SUCH_OBJECT).
+func (e *Error) Code() uint16 {
+	return uint16(e.HRESULT)
+}
+
+// Error returns a description of the error.
+func (e *Error) Error() string {
+	s := "adsi"
+	if e.Op != "" {
+		s += ": " + e.Op
+	}
+	if e.Path != "" {
+		s += " " + e.Path
+	}
+	if e.Err != nil {
+		s += ": " + e.Err.Error()
+	}
+	if e.HRESULT != 0 {
+		s += fmt.Sprintf(" (0x%08X)", e.HRESULT)
+	}
+	if e.Extended != nil && e.Extended.Message != "" {
+		s += ": " + strings.TrimSpace(strings.TrimRight(e.Extended.Message, "\x00"))
+	}
+	return s
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is returns true if target is an *Error with the same non-zero HRESULT.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.HRESULT != 0 && t.HRESULT == e.HRESULT
+}
+
+// wrapError returns err as an *Error for the given operation and path,
+// attaching the extended error information of the calling thread. The call
+// that returned err must have been made after beginCall, so that the
+// information is not left over from an earlier call. Nil errors, ErrClosed
+// and errors that are already an *Error are returned unchanged.
+func wrapError(op, path string, err error) error {
+	e, ok := newError(op, err)
+	if !ok {
+		return err
+	}
+	e.Path = path
+	return e
+}
+
+// newError returns err as an *Error for the given operation in the same way
+// as wrapError, leaving the path to be filled in by the caller. This allows
+// the path to be looked up with ADSI calls of its own once the extended
+// error information has been captured. It returns false for errors that
+// wrapError returns unchanged.
+func newError(op string, err error) (e *Error, ok bool) {
+	if err == nil || err == ErrClosed || errors.As(err, &e) {
+		return nil, false
+	}
+	e = &Error{Op: op, Err: err}
+	if code, ok := hresult(err); ok {
+		e.HRESULT = uint32(code)
+	}
+	if ext, extErr := api.ADsGetLastError(); extErr == nil && (ext.Code != 0 || ext.Message != "") {
+		e.Extended = &ext
+	}
+	return e, true
+}
+
+// beginCall prepares for a call to an ADSI provider that may record extended
+// error information. Extended errors are recorded per thread, so it locks
+// the calling goroutine to its thread and clears the thread's extended
+// error. The returned function unlocks the thread, and must be called after
+// the error of the call has been passed to wrapError:
+//
+//	defer beginCall()()
+func beginCall() func() {
+	runtime.LockOSThread()
+	api.ADsSetLastError(0, "", "")
+	return runtime.UnlockOSThread
+}
+
+// err returns err as an *Error for the given operation on the object. The
+// caller must hold the object's lock.
+func (o *object) err(op string, err error) error {
+	e, ok := newError(op, err)
+	if !ok {
+		return err
+	}
+	if o.iface != nil {
+		e.Path, _ = o.iface.AdsPath()
+	}
+	return e
+}
+
+// err returns err as an *Error for the given operation on the container. The
+// caller must hold the container's lock.
+func (c *Container) err(op string, err error) error {
+	e, ok := newError(op, err)
+	if !ok {
+		return err
+	}
+	e.Path = c.path()
+	return e
+}
+
+// path returns the ADsPath of the container, or an empty string if it can't
+// be determined. The caller must hold the container's lock.
+func (c *Container) path() string {
+	if c.iface == nil {
+		return ""
+	}
+	idispatch, err := c.iface.QueryInterface(comutil.GUID(comiid.IADs))
+	if err != nil {
+		return ""
+	}
+	defer idispatch.Release()
+	path, _ := (*api.IADs)(unsafe.Pointer(idispatch)).AdsPath()
+	return path
+}
+
+// ldapHRESULTs maps the LDAP result codes returned by the Windows LDAP client
+// to the HRESULTs that ADSI returns for the same conditions.
+var ldapHRESULTs = map[api.LDAPError]uint32{
+	0x13: 0x8007202F, // LDAP_CONSTRAINT_VIOLATION: ERROR_DS_CONSTRAINT_VIOLATION
+	0x14: 0x8007200D, // LDAP_ATTRIBUTE_OR_VALUE_EXISTS: ERROR_DS_ATTRIBUTE_OR_VALUE_EXISTS
+	0x20: 0x80072030, // LDAP_NO_SUCH_OBJECT: ERROR_DS_NO_SUCH_OBJECT
+	0x22: 0x80072032, // LDAP_INVALID_DN_SYNTAX: ERROR_DS_INVALID_DN_SYNTAX
+	0x31: 0x8007052E, // LDAP_INVALID_CREDENTIALS: ERROR_LOGON_FAILURE
+	0x32: 0x80070005, // LDAP_INSUFFICIENT_RIGHTS: E_ACCESSDENIED
+	0x33: 0x8007200E, // LDAP_BUSY: ERROR_DS_BUSY
+	0x34: 0x8007200F, // LDAP_UNAVAILABLE: ERROR_DS_UNAVAILABLE
+	0x35: 0x80072035, // LDAP_UNWILLING_TO_PERFORM: ERROR_DS_UNWILLING_TO_PERFORM
+	0x41: 0x80072014, // LDAP_OBJECT_CLASS_VIOLATION: ERROR_DS_OBJ_CLASS_VIOLATION
+	0x44: 0x80071392, // LDAP_ALREADY_EXISTS: ERROR_OBJECT_ALREADY_EXISTS
+	0x51: 0x8007203A, // LDAP_SERVER_DOWN: ERROR_DS_SERVER_DOWN
+}
+
+// wrapLDAPError returns err, which was returned by the Windows LDAP client,
+// as an *Error for the given operation and path. LDAP result codes are
+// given the HRESULT that ADSI would have returned, so that the error matches
+// the sentinel errors.
+func wrapLDAPError(op, path string, err error) error {
+	if err == nil {
+		return nil
+	}
+	e := &Error{Op: op, Path: path, Err: err}
+	var ldapErr api.LDAPError
+	if errors.As(err, &ldapErr) {
+		e.HRESULT = ldapHRESULTs[ldapErr]
+	}
+	return e
+}
+
+// errorClass describes the errors that indicate a particular condition, by
+// HRESULT, LDAP result code and sentinel error.
+type errorClass struct {
+	hresults []uint32
+	ldap     []api.LDAPError
+	errs     []error
+}
+
+// match returns true if err belongs to the class.
+func (c *errorClass) match(err error) bool {
+	if err == nil {
+		return false
+	}
+	for _, target := range c.errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	var ldapErr api.LDAPError
+	if errors.As(err, &ldapErr) {
+		for _, code := range c.ldap {
+			if ldapErr == code {
+				return true
+			}
+		}
+	}
+	var code uint32
+	var e *Error
+	if errors.As(err, &e) {
+		code = e.HRESULT
+	} else if hr, ok := hresult(err); ok {
+		code = uint32(hr)
+	}
+	for _, hr := range c.hresults {
+		if code == hr {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	notFoundErrors = errorClass{
+		hresults: []uint32{
+			0x80072030, // ERROR_DS_NO_SUCH_OBJECT
+			0x80070002, // ERROR_FILE_NOT_FOUND
+			0x80070525, // ERROR_NO_SUCH_USER
+			0x80070560, // ERROR_NO_SUCH_ALIAS
+			0x800708AD, // NERR_UserNotFound
+			0x800708AC, // NERR_GroupNotFound
+			api.E_ADS_UNKNOWN_OBJECT,
+		},
+		ldap: []api.LDAPError{0x20}, // LDAP_NO_SUCH_OBJECT
+		errs: []error{api.ErrUnknownObject},
+	}
+	accessDeniedErrors = errorClass{
+		hresults: []uint32{
+			0x80070005, // E_ACCESSDENIED
+			0x8007200A, // ERROR_DS_INSUFF_ACCESS_RIGHTS
+			api.E_ACCESS_DENIED,
+		},
+		ldap: []api.LDAPError{0x32}, // LDAP_INSUFFICIENT_RIGHTS
+		errs: []error{api.ErrAccessDenied},
+	}
+	alreadyExistsErrors = errorClass{
+		hresults: []uint32{
+			0x80071392, // ERROR_OBJECT_ALREADY_EXISTS
+			0x800700B7, // ERROR_ALREADY_EXISTS
+			0x8007200D, // ERROR_DS_ATTRIBUTE_OR_VALUE_EXISTS
+			0x80070562, // ERROR_MEMBER_IN_ALIAS
+			0x80070524, // ERROR_USER_EXISTS
+			api.E_ADS_OBJECT_EXISTS,
+		},
+		ldap: []api.LDAPError{0x44, 0x14}, // LDAP_ALREADY_EXISTS, LDAP_ATTRIBUTE_OR_VALUE_EXISTS
+		errs: []error{api.ErrObjectExists},
+	}
+	constraintViolationErrors = errorClass{
+		hresults: []uint32{
+			0x8007202F, // ERROR_DS_CONSTRAINT_VIOLATION
+			0x80072014, // ERROR_DS_OBJ_CLASS_VIOLATION
+			0x8007052D, // ERROR_PASSWORD_RESTRICTION
+			api.E_ADS_SCHEMA_VIOLATION,
+		},
+		ldap: []api.LDAPError{0x13, 0x41}, // LDAP_CONSTRAINT_VIOLATION, LDAP_OBJECT_CLASS_VIOLATION
+		errs: []error{api.ErrSchemaViolation},
+	}
+	busyErrors = errorClass{
+		hresults: []uint32{
+			0x8007200E, // ERROR_DS_BUSY
+			0x8007200F, // ERROR_DS_UNAVAILABLE
+			0x8007203A, // ERROR_DS_SERVER_DOWN
+			0x8007054B, // ERROR_NO_SUCH_DOMAIN
+			0x800705B4, // ERROR_TIMEOUT
+		},
+		ldap: []api.LDAPError{0x33, 0x34, 0x51, 0x55}, // LDAP_BUSY, LDAP_UNAVAILABLE, LDAP_SERVER_DOWN, LDAP_TIMEOUT
+	}
+	invalidCredentialsErrors = errorClass{
+		hresults: []uint32{
+			0x8007052E, // ERROR_LOGON_FAILURE
+			0x8007052B, // ERROR_WRONG_PASSWORD
+			0x80070056, // ERROR_INVALID_PASSWORD
+		},
+		ldap: []api.LDAPError{0x31}, // LDAP_INVALID_CREDENTIALS
+	}
+)
+
+// IsNotFound returns true if err reports that a directory object, user or
+// group does not exist.
+func IsNotFound(err error) bool {
+	return notFoundErrors.match(err)
+}
+
+// IsAccessDenied returns true if err reports that the caller does not have
+// the rights required by the operation.
+func IsAccessDenied(err error) bool {
+	return accessDeniedErrors.match(err)
+}
+
+// IsAlreadyExists returns true if err reports that an object, or an
+// attribute value, already exists.
+func IsAlreadyExists(err error) bool {
+	return alreadyExistsErrors.match(err)
+}
+
+// IsConstraintViolation returns true if err reports that a change was
+// rejected because it violates a constraint of the schema or of the
+// directory, such as the password policy.
+func IsConstraintViolation(err error) bool {
+	return constraintViolationErrors.match(err)
+}
+
+// IsBusy returns true if err reports that the server is busy, unavailable
+// or could not be reached. Operations that fail with such errors can usually
+// be retried.
+func IsBusy(err error) bool {
+	return busyErrors.match(err)
+}
+
+// IsInvalidCredentials returns true if err reports that the user name or
+// password used to bind was rejected.
+func IsInvalidCredentials(err error) bool {
+	return invalidCredentialsErrors.match(err)
+}
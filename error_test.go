@@ -0,0 +1,26 @@
+package adsi
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-adsi/adsi/api"
+)
+
+func TestWrapLDAPError(t *testing.T) {
+	err := wrapLDAPError("Restore", "LDAP://CN=x,DC=example,DC=com", api.LDAPError(0x20))
+	var e *Error
+	if !errors.As(err, &e) || e.Op != "Restore" || e.Path != "LDAP://CN=x,DC=example,DC=com" {
+		t.Fatalf("wrapLDAPError() = %#v, want *Error for Restore", err)
+	}
+	if !errors.Is(err, ErrNoSuchObject) {
+		t.Errorf("wrapLDAPError() = %v, want match for ErrNoSuchObject", err)
+	}
+	var ldapErr api.LDAPError
+	if !errors.As(err, &ldapErr) || ldapErr != 0x20 {
+		t.Errorf("wrapLDAPError() = %v, want LDAP result code 0x20", err)
+	}
+	if err := wrapLDAPError("Restore", "", nil); err != nil {
+		t.Errorf("wrapLDAPError(nil) = %v, want nil", err)
+	}
+}
@@ -0,0 +1,439 @@
+// Package events classifies the changes reported by adsi watchers and
+// pollers into typed events describing the creation, modification, move or
+// deletion of directory objects, and delivers them to subscribers.
+package events
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-adsi/adsi"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrStarted is returned when a subscriber is added to a subscription
+	// that has already been started.
+	ErrStarted = errors.New("events: subscription already started")
+)
+
+// Kind identifies the type of an event.
+type Kind int
+
+// Event kinds.
+const (
+	Created Kind = iota + 1
+	Modified
+	Moved
+	Deleted
+)
+
+// String returns the name of the event kind.
+func (k Kind) String() string {
+	switch k {
+	case Created:
+		return "Created"
+	case Modified:
+		return "Modified"
+	case Moved:
+		return "Moved"
+	case Deleted:
+		return "Deleted"
+	default:
+		return "Kind(" + strconv.Itoa(int(k)) + ")"
+	}
+}
+
+// Values maps attribute names to their values.
+type Values map[string][]interface{}
+
+// Event describes a change to a directory object.
+type Event struct {
+	Kind Kind
+	GUID uuid.UUID
+
+	// DN is the distinguished name of the object after the change. For
+	// moved objects OldDN holds the distinguished name before the move.
+	DN    string
+	OldDN string
+
+	// USN is the update sequence number of the change on the server that
+	// reported it.
+	USN int64
+
+	// Old holds the previous values of the attributes that changed, and New
+	// holds their values after the change. Old values are only known for
+	// objects that have already been seen by the subscription, so Old is nil
+	// for the first event of each object and New holds every attribute that
+	// was returned. Attributes that were removed appear in Old but not New.
+	Old Values
+	New Values
+
+	// Time is the time at which the change was received.
+	Time time.Time
+}
+
+// Handler is a function that receives events.
+type Handler func(Event)
+
+// Options controls the source of the events delivered by a subscription.
+type Options struct {
+	// Server is the domain controller to monitor. If empty a domain
+	// controller of the computer's domain is chosen when the subscription is
+	// created and used thereafter.
+	Server string
+
+	// Notify selects LDAP change notifications as the source of events,
+	// using adsi.Watch. Otherwise the directory is polled with adsi.Poll.
+	//
+	// Notifications are delivered promptly but hold a connection open, only
+	// support a Filter of "" and return raw attribute values: each value is
+	// a string if it is valid UTF-8 and a []byte otherwise.
+	Notify bool
+
+	// Scope applies to notification subscriptions only. Polling always
+	// searches the entire subtree.
+	Scope adsi.SearchScope
+
+	// Interval is the time between polls. If zero adsi.DefaultPollInterval
+	// is used.
+	Interval time.Duration
+
+	// Filter is an LDAP filter that changed objects must match when
+	// polling.
+	Filter string
+
+	// Attributes lists the attributes whose old and new values are reported
+	// by events.
+	Attributes []string
+
+	// Deleted reports deletions. Without it deleted objects are not seen
+	// and no Deleted events are delivered.
+	Deleted bool
+
+	// Resume continues polling from a previously recorded state.
+	Resume *adsi.PollState
+}
+
+// change is the common form of the changes reported by watchers and
+// pollers.
+type change struct {
+	dn         string
+	guid       uuid.UUID
+	usnCreated int64
+	usnChanged int64
+	created    bool
+	deleted    bool
+	values     Values
+	received   time.Time
+}
+
+// object is the last known state of an object.
+type object struct {
+	dn     string
+	values Values
+}
+
+// Subscription classifies the changes beneath a base object and delivers
+// them to its subscribers.
+type Subscription struct {
+	watcher *adsi.Watcher
+	poller  *adsi.Poller
+	changes chan change
+	done    chan struct{}
+	ended   chan struct{}
+	once    sync.Once
+
+	m        sync.Mutex
+	started  bool
+	handlers []Handler
+	channels []chan Event
+	objects  map[uuid.UUID]*object
+}
+
+// Subscribe starts monitoring the subtree beneath the object with the given
+// distinguished name. Changes are held until Start is called, so that
+// subscribers can be added first.
+//
+// Moves can only be recognized for objects that have been seen before, so
+// the first change to an object after subscribing is reported as Modified
+// even if the object was moved.
+//
+// It is the caller's responsibility to call Close on the subscription when
+// it is no longer needed.
+func Subscribe(baseDN string, opts Options) (*Subscription, error) {
+	attrs := append([]string{"uSNCreated"}, opts.Attributes...)
+	s := &Subscription{
+		changes: make(chan change),
+		done:    make(chan struct{}),
+		ended:   make(chan struct{}),
+		objects: make(map[uuid.UUID]*object),
+	}
+	if !opts.Notify {
+		p, err := adsi.Poll(baseDN, adsi.PollOptions{
+			Server:     opts.Server,
+			Interval:   opts.Interval,
+			Filter:     opts.Filter,
+			Attributes: opts.Attributes,
+			Deleted:    opts.Deleted,
+			Resume:     opts.Resume,
+		})
+		if err != nil {
+			return nil, err
+		}
+		s.poller = p
+		go s.poll(p, opts.Attributes)
+		return s, nil
+	}
+
+	// Objects that were created after the watch started are recognized by
+	// their uSNCreated, which is only meaningful on the server it was read
+	// from
+	root, err := adsi.ReadRootDSE(opts.Server)
+	if err != nil {
+		return nil, err
+	}
+	server := opts.Server
+	if server == "" {
+		server = root.DNSHostName
+	}
+	w, err := adsi.Watch(baseDN, adsi.WatchOptions{
+		Server:     server,
+		Scope:      opts.Scope,
+		Attributes: attrs,
+		Deleted:    opts.Deleted,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.watcher = w
+	go s.watch(w, root.HighestCommittedUSN, opts.Attributes)
+	return s, nil
+}
+
+// Handle adds a handler that is called for each event. Handlers are called
+// in the order they were added, on a single goroutine, and must return
+// promptly.
+func (s *Subscription) Handle(fn Handler) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.started {
+		return ErrStarted
+	}
+	s.handlers = append(s.handlers, fn)
+	return nil
+}
+
+// Chan returns a channel that receives each event. The channel is closed
+// when the subscription ends. Delivery to every subscriber waits until the
+// channel has room for the event, so it must be drained.
+func (s *Subscription) Chan(size int) (<-chan Event, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.started {
+		return nil, ErrStarted
+	}
+	c := make(chan Event, size)
+	s.channels = append(s.channels, c)
+	return c, nil
+}
+
+// Start begins delivering events to the subscribers. It returns at once.
+func (s *Subscription) Start() {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.started {
+		return
+	}
+	s.started = true
+	go s.dispatch()
+}
+
+// State returns the progress of a polling subscription, which may be saved
+// and passed to Options.Resume. It returns the zero state for a
+// notification subscription.
+func (s *Subscription) State() adsi.PollState {
+	if s.poller == nil {
+		return adsi.PollState{}
+	}
+	return s.poller.State()
+}
+
+// Err returns the error that ended the subscription, if any. It must only
+// be called after the subscription's channels have been closed or Close has
+// returned.
+func (s *Subscription) Err() error {
+	if s.poller != nil {
+		return s.poller.Err()
+	}
+	return s.watcher.Err()
+}
+
+// Close stops the subscription and closes the channels returned by Chan.
+// Events that have not yet been delivered are discarded.
+func (s *Subscription) Close() (err error) {
+	s.once.Do(func() {
+		close(s.done)
+		if s.poller != nil {
+			err = s.poller.Close()
+		} else {
+			err = s.watcher.Close()
+		}
+		s.Start()
+		<-s.ended
+	})
+	return err
+}
+
+// send passes a change to the dispatcher, returning false if the
+// subscription has been closed.
+func (s *Subscription) send(c change) bool {
+	select {
+	case s.changes <- c:
+		return true
+	case <-s.done:
+		return false
+	}
+}
+
+// poll converts the changes reported by a poller.
+func (s *Subscription) poll(p *adsi.Poller, attrs []string) {
+	defer close(s.changes)
+	for c := range p.C {
+		values := make(Values, len(attrs))
+		for _, name := range attrs {
+			if v := c.Row.Attr(name); v != nil {
+				values[name] = v
+			}
+		}
+		ok := s.send(change{
+			dn:         c.DN,
+			guid:       c.GUID,
+			usnCreated: c.USNCreated,
+			usnChanged: c.USNChanged,
+			created:    c.Created,
+			deleted:    c.Deleted,
+			values:     values,
+			received:   time.Now(),
+		})
+		if !ok {
+			return
+		}
+	}
+}
+
+// watch converts the changes reported by a watcher. Objects are considered
+// to have been created if their uSNCreated is later than the highest
+// committed USN when the watch started.
+func (s *Subscription) watch(w *adsi.Watcher, start int64, attrs []string) {
+	defer close(s.changes)
+	for c := range w.C {
+		values := make(Values, len(attrs))
+		var usnCreated int64
+		for name, raw := range c.Attributes {
+			if strings.EqualFold(name, "uSNCreated") && len(raw) > 0 {
+				usnCreated, _ = strconv.ParseInt(string(raw[0]), 10, 64)
+			}
+			for _, attr := range attrs {
+				if strings.EqualFold(name, attr) {
+					values[attr] = rawValues(raw)
+				}
+			}
+		}
+		ok := s.send(change{
+			dn:         c.DN,
+			guid:       c.GUID,
+			usnCreated: usnCreated,
+			usnChanged: c.USNChanged,
+			created:    usnCreated > start,
+			deleted:    c.Deleted,
+			values:     values,
+			received:   c.Received,
+		})
+		if !ok {
+			return
+		}
+	}
+}
+
+// dispatch classifies changes and delivers them until the source ends or
+// the subscription is closed.
+func (s *Subscription) dispatch() {
+	defer close(s.ended)
+	defer func() {
+		for _, ch := range s.channels {
+			close(ch)
+		}
+	}()
+	for c := range s.changes {
+		e := s.classify(c)
+		for _, fn := range s.handlers {
+			fn(e)
+		}
+		for _, ch := range s.channels {
+			select {
+			case ch <- e:
+			case <-s.done:
+				return
+			}
+		}
+	}
+}
+
+// classify converts a change to an event, updating the known state of the
+// object.
+func (s *Subscription) classify(c change) Event {
+	e := Event{GUID: c.guid, DN: c.dn, USN: c.usnChanged, Time: c.received}
+	prev, seen := s.objects[c.guid]
+	switch {
+	case c.deleted:
+		e.Kind = Deleted
+		delete(s.objects, c.guid)
+		if seen {
+			e.OldDN = prev.dn
+			e.Old = prev.values
+		}
+		return e
+	case seen && !strings.EqualFold(prev.dn, c.dn):
+		e.Kind = Moved
+		e.OldDN = prev.dn
+	case c.created && !seen:
+		e.Kind = Created
+	default:
+		e.Kind = Modified
+	}
+	s.objects[c.guid] = &object{dn: c.dn, values: c.values}
+	if !seen {
+		e.New = c.values
+		return e
+	}
+	d := adsi.Diff(adsi.Snapshot{Attributes: prev.values}, adsi.Snapshot{Attributes: c.values})
+	e.Old, e.New = make(Values), make(Values)
+	for _, a := range d.Added {
+		e.New[a.Name] = a.New
+	}
+	for _, a := range d.Removed {
+		e.Old[a.Name] = a.Old
+	}
+	for _, a := range d.Changed {
+		e.Old[a.Name], e.New[a.Name] = a.Old, a.New
+	}
+	return e
+}
+
+// rawValues converts the raw values of a notification.
+func rawValues(raw [][]byte) []interface{} {
+	values := make([]interface{}, len(raw))
+	for i, b := range raw {
+		if utf8.Valid(b) {
+			values[i] = string(b)
+		} else {
+			values[i] = b
+		}
+	}
+	return values
+}
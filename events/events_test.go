@@ -0,0 +1,158 @@
+package events
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestClassify(t *testing.T) {
+	a := uuid.MustParse("2f1e3d4c-5b6a-4978-8a9b-0c1d2e3f4a5b")
+	b := uuid.MustParse("7a8b9c0d-1e2f-4a3b-9c5d-6e7f8a9b0c1d")
+	dn := "CN=Alice,OU=Users,DC=example,DC=com"
+	moved := "CN=Alice,OU=Staff,DC=example,DC=com"
+
+	tests := []struct {
+		name    string
+		changes []change
+		want    []Event
+	}{
+		{
+			"first change is modified",
+			[]change{
+				{dn: dn, guid: a, usnChanged: 10, values: Values{"description": {"x"}}},
+			},
+			[]Event{
+				{Kind: Modified, GUID: a, DN: dn, USN: 10, New: Values{"description": {"x"}}},
+			},
+		},
+		{
+			"created",
+			[]change{
+				{dn: dn, guid: a, usnChanged: 10, created: true, values: Values{"description": {"x"}}},
+			},
+			[]Event{
+				{Kind: Created, GUID: a, DN: dn, USN: 10, New: Values{"description": {"x"}}},
+			},
+		},
+		{
+			"created after seen is modified",
+			[]change{
+				{dn: dn, guid: a, usnChanged: 10, values: Values{"description": {"x"}}},
+				{dn: dn, guid: a, usnChanged: 11, created: true, values: Values{"description": {"y"}}},
+			},
+			[]Event{
+				{Kind: Modified, GUID: a, DN: dn, USN: 10, New: Values{"description": {"x"}}},
+				{Kind: Modified, GUID: a, DN: dn, USN: 11, Old: Values{"description": {"x"}}, New: Values{"description": {"y"}}},
+			},
+		},
+		{
+			"unchanged values",
+			[]change{
+				{dn: dn, guid: a, usnChanged: 10, values: Values{"description": {"x"}}},
+				{dn: dn, guid: a, usnChanged: 11, values: Values{"description": {"x"}}},
+			},
+			[]Event{
+				{Kind: Modified, GUID: a, DN: dn, USN: 10, New: Values{"description": {"x"}}},
+				{Kind: Modified, GUID: a, DN: dn, USN: 11, Old: Values{}, New: Values{}},
+			},
+		},
+		{
+			"moved",
+			[]change{
+				{dn: dn, guid: a, usnChanged: 10, values: Values{"description": {"x"}}},
+				{dn: moved, guid: a, usnChanged: 11, values: Values{"description": {"x"}}},
+			},
+			[]Event{
+				{Kind: Modified, GUID: a, DN: dn, USN: 10, New: Values{"description": {"x"}}},
+				{Kind: Moved, GUID: a, DN: moved, OldDN: dn, USN: 11, Old: Values{}, New: Values{}},
+			},
+		},
+		{
+			"case of distinguished name changed",
+			[]change{
+				{dn: dn, guid: a, usnChanged: 10},
+				{dn: "cn=alice,ou=users,dc=example,dc=com", guid: a, usnChanged: 11},
+			},
+			[]Event{
+				{Kind: Modified, GUID: a, DN: dn, USN: 10},
+				{Kind: Modified, GUID: a, DN: "cn=alice,ou=users,dc=example,dc=com", USN: 11, Old: Values{}, New: Values{}},
+			},
+		},
+		{
+			"old and new values",
+			[]change{
+				{dn: dn, guid: a, usnChanged: 10, values: Values{"description": {"x"}, "title": {"Engineer"}}},
+				{dn: dn, guid: a, usnChanged: 11, values: Values{"description": {"y"}, "mail": {"alice@example.com"}}},
+			},
+			[]Event{
+				{Kind: Modified, GUID: a, DN: dn, USN: 10, New: Values{"description": {"x"}, "title": {"Engineer"}}},
+				{
+					Kind: Modified, GUID: a, DN: dn, USN: 11,
+					Old: Values{"description": {"x"}, "title": {"Engineer"}},
+					New: Values{"description": {"y"}, "mail": {"alice@example.com"}},
+				},
+			},
+		},
+		{
+			"deleted after seen",
+			[]change{
+				{dn: dn, guid: a, usnChanged: 10, values: Values{"description": {"x"}}},
+				{dn: "CN=Alice\\0ADEL:" + a.String() + ",CN=Deleted Objects,DC=example,DC=com", guid: a, usnChanged: 11, deleted: true},
+			},
+			[]Event{
+				{Kind: Modified, GUID: a, DN: dn, USN: 10, New: Values{"description": {"x"}}},
+				{
+					Kind: Deleted, GUID: a, DN: "CN=Alice\\0ADEL:" + a.String() + ",CN=Deleted Objects,DC=example,DC=com",
+					OldDN: dn, USN: 11, Old: Values{"description": {"x"}},
+				},
+			},
+		},
+		{
+			"deleted before seen",
+			[]change{
+				{dn: dn, guid: a, usnChanged: 10, deleted: true, values: Values{"description": {"x"}}},
+			},
+			[]Event{
+				{Kind: Deleted, GUID: a, DN: dn, USN: 10},
+			},
+		},
+		{
+			"forgotten after deletion",
+			[]change{
+				{dn: dn, guid: a, usnChanged: 10},
+				{dn: dn, guid: a, usnChanged: 11, deleted: true},
+				{dn: moved, guid: a, usnChanged: 12, values: Values{"description": {"x"}}},
+			},
+			[]Event{
+				{Kind: Modified, GUID: a, DN: dn, USN: 10},
+				{Kind: Deleted, GUID: a, DN: dn, OldDN: dn, USN: 11},
+				{Kind: Modified, GUID: a, DN: moved, USN: 12, New: Values{"description": {"x"}}},
+			},
+		},
+		{
+			"objects are tracked separately",
+			[]change{
+				{dn: dn, guid: a, usnChanged: 10},
+				{dn: moved, guid: b, usnChanged: 11, created: true},
+				{dn: moved, guid: a, usnChanged: 12},
+			},
+			[]Event{
+				{Kind: Modified, GUID: a, DN: dn, USN: 10},
+				{Kind: Created, GUID: b, DN: moved, USN: 11},
+				{Kind: Moved, GUID: a, DN: moved, OldDN: dn, USN: 12, Old: Values{}, New: Values{}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Subscription{objects: make(map[uuid.UUID]*object)}
+			for i, c := range tt.changes {
+				if got := s.classify(c); !reflect.DeepEqual(got, tt.want[i]) {
+					t.Errorf("classify(change %d) = %+v, want %+v", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
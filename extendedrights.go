@@ -0,0 +1,122 @@
+package adsi
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Values of the validAccesses attribute of a controlAccessRight object,
+// which determine how the right is used in an access control entry.
+const (
+	validAccessesValidatedWrite = 0x008
+	validAccessesPropertySet    = 0x030
+	validAccessesControlAccess  = 0x100
+)
+
+// ExtendedRight describes a control access right, validated write or property
+// set, as held by a controlAccessRight object in the Extended-Rights
+// container of the configuration naming context.
+type ExtendedRight struct {
+	// Name is the common name of the right, such as
+	// "User-Force-Change-Password".
+	Name string
+
+	// DisplayName is the friendly name of the right, such as
+	// "Reset Password".
+	DisplayName string
+	DN          string
+
+	// RightsGUID identifies the right in the ObjectType field of an object
+	// access control entry.
+	RightsGUID uuid.UUID
+
+	// AppliesTo holds the schemaIDGUID values of the classes the right
+	// applies to.
+	AppliesTo []uuid.UUID
+
+	ValidAccesses int
+}
+
+// IsControlAccess returns true if the right is a control access right, such
+// as "Reset Password".
+func (r *ExtendedRight) IsControlAccess() bool {
+	return r.ValidAccesses&validAccessesControlAccess != 0
+}
+
+// IsPropertySet returns true if the right is a property set, such as
+// "Personal Information".
+func (r *ExtendedRight) IsPropertySet() bool {
+	return r.ValidAccesses&validAccessesPropertySet != 0
+}
+
+// IsValidatedWrite returns true if the right is a validated write, such as
+// "Validated write to service principal name".
+func (r *ExtendedRight) IsValidatedWrite() bool {
+	return r.ValidAccesses&validAccessesValidatedWrite != 0 && !r.IsPropertySet()
+}
+
+// AppliesToClass returns true if the right applies to the class with the
+// given schemaIDGUID.
+func (r *ExtendedRight) AppliesToClass(schemaIDGUID uuid.UUID) bool {
+	for _, id := range r.AppliesTo {
+		if id == schemaIDGUID {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtendedRights is a set of extended rights returned by
+// Forest.ExtendedRights.
+type ExtendedRights []*ExtendedRight
+
+// Lookup returns the right whose common name or display name matches the
+// given name case-insensitively.
+func (rights ExtendedRights) Lookup(name string) (right *ExtendedRight, ok bool) {
+	for _, right := range rights {
+		if strings.EqualFold(right.Name, name) || strings.EqualFold(right.DisplayName, name) {
+			return right, true
+		}
+	}
+	return nil, false
+}
+
+// LookupGUID returns the right with the given rightsGuid.
+func (rights ExtendedRights) LookupGUID(id uuid.UUID) (right *ExtendedRight, ok bool) {
+	for _, right := range rights {
+		if right.RightsGUID == id {
+			return right, true
+		}
+	}
+	return nil, false
+}
+
+// ExtendedRights enumerates the controlAccessRight objects of the forest.
+func (f *Forest) ExtendedRights() (rights ExtendedRights, err error) {
+	rows, err := f.search("CN=Extended-Rights,"+f.root.ConfigurationNamingContext, Query{
+		Filter:     "(objectClass=controlAccessRight)",
+		Attributes: []string{"cn", "displayName", "distinguishedName", "rightsGuid", "appliesTo", "validAccesses"},
+		Scope:      ScopeOneLevel,
+	})
+	if err != nil {
+		return
+	}
+	for _, row := range rows {
+		right := &ExtendedRight{
+			Name:          row.AttrString("cn"),
+			DisplayName:   row.AttrString("displayName"),
+			DN:            row.AttrString("distinguishedName"),
+			ValidAccesses: row.AttrInt("validAccesses"),
+		}
+		// rightsGuid and appliesTo hold GUIDs in their string form
+		right.RightsGUID, _ = uuid.Parse(row.AttrString("rightsGuid"))
+		for _, value := range row.AttrStringSlice("appliesTo") {
+			if id, err := uuid.Parse(value); err == nil {
+				right.AppliesTo = append(right.AppliesTo, id)
+			}
+		}
+		rights = append(rights, right)
+	}
+	return
+}
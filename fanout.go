@@ -0,0 +1,61 @@
+package adsi
+
+import (
+	"fmt"
+	"sync"
+)
+
+// fanOutConcurrency is the number of domain controllers queried at once by
+// fanOut.
+const fanOutConcurrency = 8
+
+// fanOut calls fn concurrently for each domain controller of the domain
+// that holds the object with the given distinguished name, passing the
+// server name and an ADsPath for the object on that server. Searches made by
+// fn through the object run in parallel up to the number of workers of the
+// client that opened it.
+//
+// Errors returned by fn are collected by server. An error is returned only
+// if the domain controllers cannot be enumerated.
+func (o *object) fanOut(dn string, fn func(server, path string) error) (servers []string, errs map[string]error, err error) {
+	domain := domainDN(dn)
+	if domain == "" {
+		return nil, nil, fmt.Errorf("unable to determine domain of \"%s\"", dn)
+	}
+	servers, err = o.domainControllers(domain)
+	if err != nil {
+		return
+	}
+	if len(servers) == 0 {
+		return nil, nil, fmt.Errorf("no domain controllers found for \"%s\"", domain)
+	}
+
+	paths := make([]string, len(servers))
+	for i, server := range servers {
+		if paths[i], err = o.pathForDNOn(server, dn); err != nil {
+			return
+		}
+	}
+
+	errs = make(map[string]error)
+	var (
+		m   sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, fanOutConcurrency)
+	)
+	for i := range servers {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(server, path string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := fn(server, path); err != nil {
+				m.Lock()
+				errs[server] = err
+				m.Unlock()
+			}
+		}(servers[i], paths[i])
+	}
+	wg.Wait()
+	return servers, errs, nil
+}
@@ -0,0 +1,77 @@
+package adsi
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// FileTimeNever is the sentinel value stored in large integer time
+	// attributes such as accountExpires to indicate that an event will never
+	// occur.
+	FileTimeNever int64 = math.MaxInt64
+
+	// fileTimeUnixOffset is the number of 100 nanosecond intervals between the
+	// FILETIME epoch (January 1, 1601 UTC) and the unix epoch.
+	fileTimeUnixOffset int64 = 116444736000000000
+
+	// fileTimeTicksPerSecond is the number of 100 nanosecond intervals in a
+	// second.
+	fileTimeTicksPerSecond int64 = 10000000
+)
+
+// TimeFromFileTime converts a FILETIME-style large integer, expressed as the
+// number of 100 nanosecond intervals since January 1, 1601 UTC, to a
+// time.Time in UTC.
+//
+// Active Directory uses 0 and FileTimeNever to indicate that a value has
+// never been set or will never occur. Both sentinels are returned as the zero
+// time, which can be detected with time.Time.IsZero.
+func TimeFromFileTime(ft int64) time.Time {
+	if ft == 0 || ft == FileTimeNever {
+		return time.Time{}
+	}
+	ft -= fileTimeUnixOffset
+	return time.Unix(ft/fileTimeTicksPerSecond, (ft%fileTimeTicksPerSecond)*100).UTC()
+}
+
+// FileTimeFromTime converts t to a FILETIME-style large integer. The zero
+// time is converted to 0.
+func FileTimeFromTime(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()*fileTimeTicksPerSecond + int64(t.Nanosecond()/100) + fileTimeUnixOffset
+}
+
+// AttrTimeSlice attempts to retrieve the large integer attribute with the
+// given name and return its values as a slice of times. Each value is
+// interpreted as a FILETIME-style large integer as described by
+// TimeFromFileTime.
+func (o *object) AttrTimeSlice(name string) (values []time.Time, err error) {
+	ints, err := o.AttrInt64Slice(name)
+	if err != nil {
+		return
+	}
+	for _, v := range ints {
+		values = append(values, TimeFromFileTime(v))
+	}
+	return
+}
+
+// AttrTime attempts to retrieve the large integer attribute with the given
+// name and return its value as a time. If the attribute holds more than one
+// value, only the first value is returned.
+//
+// The zero time is returned when the attribute holds one of the sentinel
+// values described by TimeFromFileTime.
+func (o *object) AttrTime(name string) (attr time.Time, err error) {
+	array, err := o.AttrTimeSlice(name)
+	if err != nil {
+		return
+	}
+	if len(array) > 0 {
+		return array[0], nil
+	}
+	return time.Time{}, nil
+}
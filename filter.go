@@ -0,0 +1,33 @@
+package adsi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EscapeFilter escapes the given value for safe inclusion in an LDAP search
+// filter, as described by RFC 4515. The characters '*', '(', ')', '\' and NUL
+// are replaced by their escaped hexadecimal form.
+func EscapeFilter(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '*', '(', ')', '\\', 0:
+			fmt.Fprintf(&b, "\\%02x", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// EscapeFilterBytes escapes every byte of the given binary value for
+// inclusion in an LDAP search filter. It is used to match octet string
+// attributes such as objectGUID and objectSid.
+func EscapeFilterBytes(value []byte) string {
+	var b strings.Builder
+	for _, c := range value {
+		fmt.Fprintf(&b, "\\%02x", c)
+	}
+	return b.String()
+}
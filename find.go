@@ -0,0 +1,185 @@
+package adsi
+
+import (
+	"strings"
+
+	"github.com/go-adsi/adsi/adspath"
+	"github.com/google/uuid"
+)
+
+// Filters that select the objects returned by FindUser, FindGroup and
+// FindComputer. Each uses an indexed attribute.
+const (
+	findUserFilter     = "(sAMAccountType=805306368)" // AccountTypeNormalUserAccount
+	findGroupFilter    = "(objectCategory=group)"
+	findComputerFilter = "(objectCategory=computer)"
+)
+
+// FindUser returns the user with the given identity in the domain of the
+// computer, like the Get-ADUser cmdlet. The identity may be a distinguished
+// name, a SID in its string form, a GUID, a user principal name or a
+// sAMAccountName, optionally preceded by the NetBIOS name of the
+// domain as in "EXAMPLE\jane". A domain name selects the domain of the forest
+// that is searched instead of the domain of the computer.
+//
+// If no user has the identity an error matching ErrNoSuchObject is
+// returned, and if more than one does ErrAmbiguousIdentity is returned.
+//
+// The returned user consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned user when it is no
+// longer needed.
+func (c *Client) FindUser(identity string) (user *User, err error) {
+	obj, err := c.find("FindUser", identity, findUserFilter, false)
+	if err != nil {
+		return
+	}
+	defer obj.Close()
+	return obj.ToUser()
+}
+
+// FindGroup returns the group with the given identity in the domain of the
+// computer, like the Get-ADGroup cmdlet. The identity is interpreted as
+// described by FindUser.
+//
+// The returned group consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned group when it is no
+// longer needed.
+func (c *Client) FindGroup(identity string) (group *Group, err error) {
+	obj, err := c.find("FindGroup", identity, findGroupFilter, false)
+	if err != nil {
+		return
+	}
+	defer obj.Close()
+	return obj.ToGroup()
+}
+
+// FindComputer returns the computer with the given identity in the domain of
+// the computer, like the Get-ADComputer cmdlet. The identity is interpreted
+// as described by FindUser, except that the trailing "$" of the
+// sAMAccountName of the computer may be left out.
+//
+// The returned computer consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned computer when it is
+// no longer needed.
+func (c *Client) FindComputer(identity string) (computer *Computer, err error) {
+	obj, err := c.find("FindComputer", identity, findComputerFilter, true)
+	if err != nil {
+		return
+	}
+	defer obj.Close()
+	return obj.ToComputer()
+}
+
+// find searches the domain for the single object of the class selected by
+// classFilter that has the given identity, and opens it.
+func (c *Client) find(op, identity, classFilter string, computer bool) (obj *Object, err error) {
+	identity = strings.TrimSpace(identity)
+	if identity == "" {
+		return nil, &Error{Op: op, Err: ErrInvalidIdentity}
+	}
+
+	q := Query{Attributes: []string{"ADsPath"}, SizeLimit: 2}
+	var base string
+	if isDN(identity) {
+		// Distinguished names are resolved by binding to the object itself
+		base = identity
+		q.Filter = classFilter
+		q.Scope = ScopeBase
+	} else {
+		root, err := c.ReadRootDSE("")
+		if err != nil {
+			return nil, err
+		}
+		base = root.DefaultNamingContext
+		name := identity
+		if domain, rest, ok := strings.Cut(identity, `\`); ok {
+			if domain == "" || rest == "" {
+				return nil, &Error{Op: op, Path: identity, Err: ErrInvalidIdentity}
+			}
+			if base, err = c.domainNamingContext(root, domain); err != nil {
+				return nil, err
+			}
+			if base == "" {
+				return nil, &Error{Op: op, Path: identity, HRESULT: hresultNoSuchObject}
+			}
+			name = rest
+		}
+		q.Filter = "(&" + classFilter + identityFilter(name, computer) + ")"
+	}
+
+	path := (&adspath.Path{Scheme: "LDAP", Path: adspath.EscapeDN(base)}).String()
+	result, err := c.Search(path, q)
+	if err != nil {
+		if isNoSuchObject(err) {
+			return nil, &Error{Op: op, Path: identity, HRESULT: hresultNoSuchObject}
+		}
+		return nil, err
+	}
+	defer result.Close()
+	rows, err := result.All()
+	if err != nil {
+		return nil, err
+	}
+	switch len(rows) {
+	case 0:
+		return nil, &Error{Op: op, Path: identity, HRESULT: hresultNoSuchObject}
+	case 1:
+		return c.Open(rows[0].Path())
+	}
+	return nil, &Error{Op: op, Path: identity, Err: ErrAmbiguousIdentity}
+}
+
+// domainNamingContext returns the distinguished name of the domain of the
+// forest with the given NetBIOS name, or an empty string if the forest has
+// no such domain.
+func (c *Client) domainNamingContext(root *RootDSE, netBIOSName string) (dn string, err error) {
+	result, err := c.Search(root.path("CN=Partitions,"+root.ConfigurationNamingContext), Query{
+		Filter:     "(&(objectClass=crossRef)(nETBIOSName=" + EscapeFilter(netBIOSName) + "))",
+		Attributes: []string{"nCName"},
+		Scope:      ScopeOneLevel,
+	})
+	if err != nil {
+		return
+	}
+	defer result.Close()
+	rows, err := result.All()
+	if err != nil || len(rows) == 0 {
+		return
+	}
+	return rows[0].AttrString("nCName"), nil
+}
+
+// identityFilter returns a filter matching the objects with the given
+// identity, which is neither a distinguished name nor preceded by the name
+// of a domain.
+func identityFilter(identity string, computer bool) string {
+	if sid, err := ParseSIDString(identity); err == nil {
+		return "(objectSid=" + EscapeFilterBytes(sid.Bytes()) + ")"
+	}
+	if guid, err := uuid.Parse(strings.Trim(identity, "{}")); err == nil {
+		return "(objectGUID=" + EscapeFilterBytes(windowsBytesFromGUID(guid)) + ")"
+	}
+	if strings.Contains(identity, "@") {
+		return "(userPrincipalName=" + EscapeFilter(identity) + ")"
+	}
+	if computer && !strings.HasSuffix(identity, "$") {
+		return "(|(sAMAccountName=" + EscapeFilter(identity) + ")(sAMAccountName=" + EscapeFilter(identity) + "$))"
+	}
+	return "(sAMAccountName=" + EscapeFilter(identity) + ")"
+}
+
+// isDN returns true if s has the form of a distinguished name, which begins
+// with an attribute type and an equals sign, as in "CN=Jane Doe,...".
+func isDN(s string) bool {
+	name, _, ok := strings.Cut(s, "=")
+	if !ok || name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
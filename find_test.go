@@ -0,0 +1,47 @@
+package adsi
+
+import "testing"
+
+func TestIdentityFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		identity string
+		computer bool
+		want     string
+	}{
+		{"sid", "S-1-5-21-1-2-3-1104", false, `(objectSid=\01\05\00\00\00\00\00\05\15\00\00\00\01\00\00\00\02\00\00\00\03\00\00\00\50\04\00\00)`},
+		{"guid", "{6f4f5d3c-1b2a-4e8f-9c7d-0a1b2c3d4e5f}", false, `(objectGUID=\3c\5d\4f\6f\2a\1b\8f\4e\9c\7d\0a\1b\2c\3d\4e\5f)`},
+		{"upn", "jane@example.com", false, "(userPrincipalName=jane@example.com)"},
+		{"sam account name", "jane", false, "(sAMAccountName=jane)"},
+		{"escaped", "j*ne(x)", false, `(sAMAccountName=j\2ane\28x\29)`},
+		{"computer", "WS01", true, "(|(sAMAccountName=WS01)(sAMAccountName=WS01$))"},
+		{"computer with dollar", "WS01$", true, "(sAMAccountName=WS01$)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := identityFilter(tt.identity, tt.computer); got != tt.want {
+				t.Errorf("identityFilter(%q) = %q, want %q", tt.identity, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsDN(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"CN=Jane Doe,OU=Staff,DC=example,DC=com", true},
+		{"1.2.840.113556.1.4.1=x", true},
+		{"jane", false},
+		{"=jane", false},
+		{`EXAMPLE\jane`, false},
+		{"jane@example.com", false},
+		{"a b=c", false},
+	}
+	for _, tt := range tests {
+		if got := isDN(tt.in); got != tt.want {
+			t.Errorf("isDN(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
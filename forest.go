@@ -0,0 +1,80 @@
+package adsi
+
+// Forest provides access to the forest-wide configuration of Active
+// Directory, which is held in the configuration and schema naming contexts.
+//
+// A Forest does not hold any component object model resources of its own.
+// Each method opens the objects it needs and releases them before returning.
+type Forest struct {
+	root *RootDSE
+
+	// client, user, password and flags are the connection settings of a
+	// forest opened through a client. The client is nil for a forest opened
+	// with OpenForest, which connects with an ephemeral client instead.
+	client   *Client
+	user     string
+	password string
+	flags    uint32
+}
+
+// OpenForest returns the forest that the given server belongs to. If server
+// is empty a domain controller of the computer's domain is used. The
+// connection is made using the security context of the application and the
+// default client flags.
+func OpenForest(server string) (*Forest, error) {
+	root, err := ReadRootDSE(server)
+	if err != nil {
+		return nil, err
+	}
+	return &Forest{root: root}, nil
+}
+
+// OpenForest returns the forest that the given server belongs to. If server
+// is empty a domain controller of the computer's domain is used. The
+// existing security context of the application and any flags specified via
+// SetFlags will be used when making connections.
+//
+// The forest is bound to the client, and its methods fail once the client
+// has been closed.
+func (c *Client) OpenForest(server string) (*Forest, error) {
+	return c.OpenForestSC(server, "", "", c.Flags())
+}
+
+// OpenForestSC returns the forest that the given server belongs to. When
+// provided, the username and password are used to establish a security
+// context for each connection made by the forest. When credentials are not
+// provided the existing security context of the application is used instead.
+// The provided flags will be used when making connections.
+//
+// The forest is bound to the client, and its methods fail once the client
+// has been closed.
+func (c *Client) OpenForestSC(server, user, password string, flags uint32) (*Forest, error) {
+	root, err := readRootDSE(server, func(path string) (*Object, error) {
+		return c.OpenSC(path, user, password, flags)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Forest{root: root, client: c, user: user, password: password, flags: flags}, nil
+}
+
+// RootDSE returns the rootDSE of the server the forest was opened with.
+func (f *Forest) RootDSE() *RootDSE {
+	return f.root
+}
+
+// search executes the given query rooted at the object with the given
+// distinguished name and returns all of the resulting rows.
+func (f *Forest) search(dn string, q Query) (rows []*Row, err error) {
+	var result *SearchResult
+	if f.client == nil {
+		result, err = Search(f.root.path(dn), q)
+	} else {
+		result, err = f.client.searchSC(f.root.path(dn), f.user, f.password, f.flags, q)
+	}
+	if err != nil {
+		return
+	}
+	defer result.Close()
+	return result.All()
+}
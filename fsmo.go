@@ -0,0 +1,77 @@
+package adsi
+
+import "fmt"
+
+// FSMORoles holds the DNS host names of the domain controllers that own the
+// flexible single master operations roles.
+type FSMORoles struct {
+	// Forest-wide roles.
+	SchemaMaster       string
+	DomainNamingMaster string
+
+	// Domain-wide roles.
+	PDCEmulator          string
+	RIDMaster            string
+	InfrastructureMaster string
+}
+
+// FSMORoles returns the owners of the two forest-wide roles and of the three
+// domain-wide roles of the domain the forest was opened with.
+func (f *Forest) FSMORoles() (roles *FSMORoles, err error) {
+	return f.DomainFSMORoles(f.root.DefaultNamingContext)
+}
+
+// DomainFSMORoles returns the owners of the two forest-wide roles and of the
+// three domain-wide roles of the domain with the given distinguished name.
+//
+// The domain-wide roles are read from the server the forest was opened
+// with, which must hold a copy of the domain.
+func (f *Forest) DomainFSMORoles(domain string) (roles *FSMORoles, err error) {
+	roles = new(FSMORoles)
+	for _, role := range []struct {
+		dn    string
+		owner *string
+	}{
+		{f.root.SchemaNamingContext, &roles.SchemaMaster},
+		{"CN=Partitions," + f.root.ConfigurationNamingContext, &roles.DomainNamingMaster},
+		{domain, &roles.PDCEmulator},
+		{"CN=RID Manager$,CN=System," + domain, &roles.RIDMaster},
+		{"CN=Infrastructure," + domain, &roles.InfrastructureMaster},
+	} {
+		if *role.owner, err = f.roleOwner(role.dn); err != nil {
+			return nil, err
+		}
+	}
+	return
+}
+
+// roleOwner reads the fSMORoleOwner attribute of the object with the given
+// distinguished name and resolves it to the DNS host name of the domain
+// controller that holds the role.
+func (f *Forest) roleOwner(dn string) (owner string, err error) {
+	rows, err := f.search(dn, Query{Attributes: []string{"fSMORoleOwner"}, Scope: ScopeBase})
+	if err != nil {
+		return
+	}
+	if len(rows) == 0 || rows[0].AttrString("fSMORoleOwner") == "" {
+		return "", fmt.Errorf("unable to read role owner of \"%s\"", dn)
+	}
+	return f.serverName(rows[0].AttrString("fSMORoleOwner"))
+}
+
+// serverName resolves the distinguished name of the NTDS settings object of a
+// domain controller to its DNS host name. The host name is read from the
+// server object that contains the settings object.
+func (f *Forest) serverName(settings string) (name string, err error) {
+	server := parentDN(settings)
+	rows, err := f.search(server, Query{Attributes: []string{"dNSHostName"}, Scope: ScopeBase})
+	if err != nil {
+		return
+	}
+	if len(rows) == 0 || rows[0].AttrString("dNSHostName") == "" {
+		// The server object of a demoted domain controller may remain after
+		// the role has been seized. Report its name rather than failing.
+		return rdnValue(server), nil
+	}
+	return rows[0].AttrString("dNSHostName"), nil
+}
@@ -0,0 +1,104 @@
+package adsi
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-adsi/adsi/api"
+)
+
+// FunctionalLevel is the value of the msDS-Behavior-Version attribute, which
+// records the functional level of a domain, a forest or a domain controller.
+//
+// See https://learn.microsoft.com/openspecs/windows_protocols/ms-adts/d7422d35-448a-451a-8846-6a7def0044df
+type FunctionalLevel int
+
+// Functional levels.
+const (
+	FunctionalLevel2000        FunctionalLevel = 0
+	FunctionalLevel2003Interim FunctionalLevel = 1
+	FunctionalLevel2003        FunctionalLevel = 2
+	FunctionalLevel2008        FunctionalLevel = 3
+	FunctionalLevel2008R2      FunctionalLevel = 4
+	FunctionalLevel2012        FunctionalLevel = 5
+	FunctionalLevel2012R2      FunctionalLevel = 6
+	FunctionalLevel2016        FunctionalLevel = 7
+	FunctionalLevel2025        FunctionalLevel = 10
+)
+
+var functionalLevelNames = map[FunctionalLevel]string{
+	FunctionalLevel2000:        "Windows 2000",
+	FunctionalLevel2003Interim: "Windows Server 2003 interim",
+	FunctionalLevel2003:        "Windows Server 2003",
+	FunctionalLevel2008:        "Windows Server 2008",
+	FunctionalLevel2008R2:      "Windows Server 2008 R2",
+	FunctionalLevel2012:        "Windows Server 2012",
+	FunctionalLevel2012R2:      "Windows Server 2012 R2",
+	FunctionalLevel2016:        "Windows Server 2016",
+	FunctionalLevel2025:        "Windows Server 2025",
+}
+
+// String returns the human-readable name of the functional level.
+func (l FunctionalLevel) String() string {
+	if name, ok := functionalLevelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown functional level %d", int(l))
+}
+
+// FunctionalLevel retrieves the msDS-Behavior-Version attribute of the
+// object. Domains, the CN=Partitions container and the NTDS settings objects
+// of domain controllers carry this attribute. If the attribute is not set
+// FunctionalLevel2000 is returned.
+func (o *object) FunctionalLevel() (level FunctionalLevel, err error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return 0, ErrClosed
+	}
+	value, err := o.AttrInt("msDS-Behavior-Version")
+	if errors.Is(err, api.ErrPropertyNotFound) {
+		return FunctionalLevel2000, nil
+	}
+	return FunctionalLevel(value), err
+}
+
+// FunctionalLevel retrieves the forest functional level from the
+// msDS-Behavior-Version attribute of the CN=Partitions container. An error
+// matching ErrNoSuchObject is returned if the container cannot be read.
+func (f *Forest) FunctionalLevel() (level FunctionalLevel, err error) {
+	dn := "CN=Partitions," + f.root.ConfigurationNamingContext
+	rows, err := f.search(dn, Query{
+		Attributes: []string{"msDS-Behavior-Version"},
+		Scope:      ScopeBase,
+	})
+	if err != nil {
+		return
+	}
+	if len(rows) == 0 {
+		return 0, &Error{Op: "FunctionalLevel", Path: f.root.path(dn), HRESULT: hresultNoSuchObject}
+	}
+	return FunctionalLevel(rows[0].AttrInt("msDS-Behavior-Version")), nil
+}
+
+// DomainControllerLevels returns the functional level of every domain
+// controller in the forest, keyed by DNS host name. The level of each domain
+// controller is read from its NTDS settings object.
+func (f *Forest) DomainControllerLevels() (levels map[string]FunctionalLevel, err error) {
+	rows, err := f.search("CN=Sites,"+f.root.ConfigurationNamingContext, Query{
+		Filter:     "(objectClass=nTDSDSA)",
+		Attributes: []string{"distinguishedName", "msDS-Behavior-Version"},
+	})
+	if err != nil {
+		return
+	}
+	levels = make(map[string]FunctionalLevel)
+	for _, row := range rows {
+		name, err := f.serverName(row.AttrString("distinguishedName"))
+		if err != nil {
+			return nil, err
+		}
+		levels[name] = FunctionalLevel(row.AttrInt("msDS-Behavior-Version"))
+	}
+	return
+}
@@ -0,0 +1,214 @@
+package adsi
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	ole "github.com/go-ole/go-ole"
+)
+
+// ErrInvalidGeneralizedTime is returned when a value cannot be interpreted as
+// an LDAP GeneralizedTime string.
+var ErrInvalidGeneralizedTime = errors.New("invalid GeneralizedTime value")
+
+// oleDateEpoch is the epoch of OLE automation dates, which are used by VT_DATE
+// variants.
+var oleDateEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// ParseGeneralizedTime parses an LDAP GeneralizedTime string, such as the
+// values held by whenCreated, whenChanged and dSCorePropagationData.
+//
+// GeneralizedTime values take the form YYYYMMDDHH[MM[SS]][(.|,)fraction]
+// followed by either "Z" for UTC or a "+hh[mm]" or "-hh[mm]" offset. A value
+// without a time zone is interpreted as UTC. The returned time is always
+// expressed in UTC.
+//
+// See RFC 4517 section 3.3.13.
+func ParseGeneralizedTime(s string) (t time.Time, err error) {
+	if len(s) < 10 {
+		return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidGeneralizedTime, s)
+	}
+
+	digits := func(start, end int) (n int, ok bool) {
+		for _, c := range []byte(s[start:end]) {
+			if c < '0' || c > '9' {
+				return 0, false
+			}
+			n = n*10 + int(c-'0')
+		}
+		return n, true
+	}
+
+	var fields [6]int // year, month, day, hour, minute, second
+	var ok bool
+	if fields[0], ok = digits(0, 4); !ok {
+		return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidGeneralizedTime, s)
+	}
+	pos, n := 4, 1
+	for ; n < len(fields); n++ {
+		if pos+2 > len(s) || s[pos] < '0' || s[pos] > '9' {
+			if n < 4 {
+				// Year, month, day and hour are mandatory
+				return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidGeneralizedTime, s)
+			}
+			break
+		}
+		if fields[n], ok = digits(pos, pos+2); !ok {
+			return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidGeneralizedTime, s)
+		}
+		pos += 2
+	}
+
+	// The fraction applies to the least significant field that was present
+	var fraction float64
+	if pos < len(s) && (s[pos] == '.' || s[pos] == ',') {
+		end := pos + 1
+		for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+			end++
+		}
+		if end == pos+1 {
+			return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidGeneralizedTime, s)
+		}
+		fraction, _ = strconv.ParseFloat("0."+s[pos+1:end], 64)
+		pos = end
+	}
+
+	loc := time.UTC
+	if pos < len(s) {
+		switch s[pos] {
+		case 'Z':
+			pos++
+		case '+', '-':
+			zone := s[pos+1:]
+			if len(zone) != 2 && len(zone) != 4 {
+				return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidGeneralizedTime, s)
+			}
+			hours, ok := digits(pos+1, pos+3)
+			if !ok {
+				return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidGeneralizedTime, s)
+			}
+			var minutes int
+			if len(zone) == 4 {
+				if minutes, ok = digits(pos+3, pos+5); !ok {
+					return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidGeneralizedTime, s)
+				}
+			}
+			offset := hours*3600 + minutes*60
+			if s[pos] == '-' {
+				offset = -offset
+			}
+			loc = time.FixedZone("", offset)
+			pos = len(s)
+		}
+	}
+	if pos != len(s) {
+		return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidGeneralizedTime, s)
+	}
+
+	t = time.Date(fields[0], time.Month(fields[1]), fields[2], fields[3], fields[4], fields[5], 0, loc)
+	if t.Month() != time.Month(fields[1]) || t.Day() != fields[2] || t.Hour() != fields[3] || t.Minute() != fields[4] || t.Second() != fields[5] {
+		// time.Date normalizes fields that are out of range
+		return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidGeneralizedTime, s)
+	}
+	if fraction > 0 {
+		unit := time.Second
+		switch n {
+		case 4:
+			unit = time.Hour
+		case 5:
+			unit = time.Minute
+		}
+		t = t.Add(time.Duration(fraction * float64(unit)))
+	}
+	return t.UTC(), nil
+}
+
+// FormatGeneralizedTime formats t as an LDAP GeneralizedTime string in UTC,
+// in the form used by Active Directory (e.g. "20060102150405.0Z").
+func FormatGeneralizedTime(t time.Time) string {
+	return t.UTC().Format("20060102150405") + ".0Z"
+}
+
+// AttrGeneralizedTimeSlice attempts to retrieve the attribute with the given
+// name and return its values as a slice of times in UTC.
+//
+// The ADSI LDAP provider usually returns GeneralizedTime and UTCTime values
+// as VT_DATE variants, but string values in GeneralizedTime form are also
+// accepted. Any other values contained in the attribute will be ommitted.
+func (o *object) AttrGeneralizedTimeSlice(name string) (values []time.Time, err error) {
+	elements, err := o.Attr(name)
+	if err != nil {
+		return
+	}
+	for i, element := range elements {
+		switch v := element.(type) {
+		case time.Time:
+			// VT_DATE values carry no time zone, but Active Directory always
+			// stores them in UTC.
+			values = append(values, time.Date(v.Year(), v.Month(), v.Day(), v.Hour(), v.Minute(), v.Second(), v.Nanosecond(), time.UTC))
+		case float64:
+			values = append(values, timeFromOLEDate(v))
+		case string:
+			value, parseErr := ParseGeneralizedTime(v)
+			if parseErr != nil {
+				return nil, fmt.Errorf("attribute \"%s\" value %d: %v", name, i, parseErr)
+			}
+			values = append(values, value)
+		case *ole.IUnknown:
+			v.Release()
+		case *ole.IDispatch:
+			v.Release()
+		default:
+			// TODO: Consider returning error
+		}
+	}
+	return
+}
+
+// AttrGeneralizedTime attempts to retrieve the attribute with the given name
+// and return its value as a time in UTC. If the attribute holds more than one
+// value, only the first value is returned.
+//
+// Any non-time values contained in the attribute will be ignored.
+func (o *object) AttrGeneralizedTime(name string) (attr time.Time, err error) {
+	array, err := o.AttrGeneralizedTimeSlice(name)
+	if err != nil {
+		return
+	}
+	if len(array) > 0 {
+		return array[0], nil
+	}
+	return time.Time{}, nil
+}
+
+// timeFromOLEDate converts an OLE automation date, expressed as the number of
+// days since December 30, 1899, to a time in UTC.
+func timeFromOLEDate(v float64) time.Time {
+	days, frac := math.Modf(v)
+	t := oleDateEpoch.AddDate(0, 0, int(days))
+	return t.Add(time.Duration(math.Abs(frac) * float64(24*time.Hour)))
+}
+
+// WhenCreated returns the time at which the object was created.
+func (o *object) WhenCreated() (time.Time, error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return time.Time{}, ErrClosed
+	}
+	return o.AttrGeneralizedTime("whenCreated")
+}
+
+// WhenChanged returns the time at which the object was last changed on the
+// domain controller it is bound to.
+func (o *object) WhenChanged() (time.Time, error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return time.Time{}, ErrClosed
+	}
+	return o.AttrGeneralizedTime("whenChanged")
+}
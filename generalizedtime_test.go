@@ -0,0 +1,105 @@
+package adsi
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseGeneralizedTime(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"20240301123045.0Z", time.Date(2024, 3, 1, 12, 30, 45, 0, time.UTC)},
+		{"20240301123045Z", time.Date(2024, 3, 1, 12, 30, 45, 0, time.UTC)},
+		{"20240301123045", time.Date(2024, 3, 1, 12, 30, 45, 0, time.UTC)},
+		{"2024030112Z", time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+		{"202403011230Z", time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)},
+		{"20240301123045.25Z", time.Date(2024, 3, 1, 12, 30, 45, 250000000, time.UTC)},
+		{"20240301123045,5Z", time.Date(2024, 3, 1, 12, 30, 45, 500000000, time.UTC)},
+		{"2024030112.5Z", time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)},
+		{"202403011230.5Z", time.Date(2024, 3, 1, 12, 30, 30, 0, time.UTC)},
+		{"20240301123045+0200", time.Date(2024, 3, 1, 10, 30, 45, 0, time.UTC)},
+		{"20240301123045-05", time.Date(2024, 3, 1, 17, 30, 45, 0, time.UTC)},
+		{"20240301003045+0130", time.Date(2024, 2, 29, 23, 0, 45, 0, time.UTC)},
+		{"16010101000000.0Z", time.Date(1601, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseGeneralizedTime(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) || got.Location() != time.UTC {
+				t.Errorf("ParseGeneralizedTime(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGeneralizedTimeInvalid(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"2024",
+		"20240301",
+		"202403011",
+		"2024030112345",
+		"20240301123045.Z",
+		"20240301123045.0X",
+		"20240301123045+1",
+		"20240301123045+020",
+		"20240301123045+0a00",
+		"+0240301123045Z",
+		"2024+301123045Z",
+		"20241301123045Z",
+		"20240230123045Z",
+		"20240301253045Z",
+		"20240301126045Z",
+		"20240301123075Z",
+		"20240301123045Zjunk",
+	} {
+		t.Run(in, func(t *testing.T) {
+			if got, err := ParseGeneralizedTime(in); !errors.Is(err, ErrInvalidGeneralizedTime) {
+				t.Errorf("ParseGeneralizedTime(%q) = %v, %v, want ErrInvalidGeneralizedTime", in, got, err)
+			}
+		})
+	}
+}
+
+func TestFormatGeneralizedTime(t *testing.T) {
+	tests := []struct {
+		in   time.Time
+		want string
+	}{
+		{time.Date(2024, 3, 1, 12, 30, 45, 0, time.UTC), "20240301123045.0Z"},
+		{time.Date(2024, 3, 1, 12, 30, 45, 0, time.FixedZone("", 2*3600)), "20240301103045.0Z"},
+		{time.Date(2024, 3, 1, 12, 30, 45, 999999999, time.UTC), "20240301123045.0Z"},
+	}
+	for _, tt := range tests {
+		got := FormatGeneralizedTime(tt.in)
+		if got != tt.want {
+			t.Errorf("FormatGeneralizedTime(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+		if back, err := ParseGeneralizedTime(got); err != nil || !back.Equal(tt.in.Truncate(time.Second)) {
+			t.Errorf("ParseGeneralizedTime(%q) = %v, %v, want %v", got, back, err, tt.in.Truncate(time.Second))
+		}
+	}
+}
+
+func TestTimeFromOLEDate(t *testing.T) {
+	tests := []struct {
+		in   float64
+		want time.Time
+	}{
+		{0, time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)},
+		{2.5, time.Date(1900, 1, 1, 12, 0, 0, 0, time.UTC)},
+		{-1.25, time.Date(1899, 12, 29, 6, 0, 0, 0, time.UTC)},
+		{45352.75, time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := timeFromOLEDate(tt.in); !got.Equal(tt.want) {
+			t.Errorf("timeFromOLEDate(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package adsi
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"github.com/go-adsi/adsi/api"
+)
+
+var (
+	// ErrInvalidManagedPassword is returned when an msDS-ManagedPassword
+	// value cannot be decoded.
+	ErrInvalidManagedPassword = errors.New("invalid managed password blob")
+
+	// ErrNoManagedPassword is returned when the managed password of an
+	// account is not returned by the directory, either because the account
+	// is not a group managed service account or because the caller is not
+	// allowed to retrieve it.
+	ErrNoManagedPassword = errors.New("managed password not available")
+)
+
+// allowedPrincipalRights is the access mask granted to each principal in the
+// security descriptors of msDS-GroupMSAMembership and
+// msDS-AllowedToActOnBehalfOfOtherIdentity, matching the value written by
+// the Active Directory PowerShell module.
+const allowedPrincipalRights = 0x000F01FF
+
+// builtinAdministratorsSID is the owner of the security descriptors written
+// to msDS-GroupMSAMembership and msDS-AllowedToActOnBehalfOfOtherIdentity.
+var builtinAdministratorsSID = SID{Revision: 1, Authority: 5, SubAuthorities: []uint32{32, 544}}
+
+// PrincipalsAllowedToRetrieveManagedPassword decodes the security descriptor
+// held by msDS-GroupMSAMembership of a group managed service account and
+// returns the security identifiers that are allowed to retrieve its
+// password. An empty slice is returned if the attribute is not set.
+func (o *object) PrincipalsAllowedToRetrieveManagedPassword() (sids []SID, err error) {
+	return o.allowedPrincipals("msDS-GroupMSAMembership")
+}
+
+// SetPrincipalsAllowedToRetrieveManagedPassword replaces the security
+// descriptor held by msDS-GroupMSAMembership of a group managed service
+// account with one that allows the given principals to retrieve its
+// password. If no principals are given the attribute is cleared. The value
+// must be commited with SetInfo to be made persistent.
+func (o *object) SetPrincipalsAllowedToRetrieveManagedPassword(sids ...SID) error {
+	return o.setAllowedPrincipals("msDS-GroupMSAMembership", allowedPrincipalRights, sids)
+}
+
+// allowedPrincipals returns the principals granted access by the allow ACEs
+// of the security descriptor held by the given attribute.
+func (o *object) allowedPrincipals(name string) (sids []SID, err error) {
+	row, err := o.baseRow(Query{Attributes: []string{name}})
+	if err != nil {
+		return
+	}
+	b := row.AttrBytes(name)
+	if b == nil {
+		return nil, nil
+	}
+	sd, err := ParseSecurityDescriptor(b)
+	if err != nil || sd.DACL == nil {
+		return
+	}
+	for _, ace := range sd.DACL.ACEs {
+		if ace.Type == ACETypeAccessAllowed || ace.Type == ACETypeAccessAllowedObject {
+			sids = append(sids, ace.SID)
+		}
+	}
+	return
+}
+
+// setAllowedPrincipals writes a security descriptor to the given attribute
+// that grants the given access mask to each of the principals.
+func (o *object) setAllowedPrincipals(name string, mask uint32, sids []SID) error {
+	if len(sids) == 0 {
+		return o.PutEx(api.ADS_PROPERTY_CLEAR, name)
+	}
+	owner := builtinAdministratorsSID
+	sd := &SecurityDescriptor{Owner: &owner, DACL: new(ACL)}
+	for _, sid := range sids {
+		sd.DACL.ACEs = append(sd.DACL.ACEs, ACE{Type: ACETypeAccessAllowed, Mask: mask, SID: sid})
+	}
+	return o.PutBytes(name, sd.Bytes())
+}
+
+// ManagedPasswordInterval retrieves the number of days between automatic
+// password changes of a group managed service account, from
+// msDS-ManagedPasswordInterval. The interval can only be set when the
+// account is created. Zero is returned if the attribute is not set.
+func (o *object) ManagedPasswordInterval() (interval time.Duration, err error) {
+	row, err := o.baseRow(Query{Attributes: []string{"msDS-ManagedPasswordInterval"}})
+	if err != nil {
+		return
+	}
+	return time.Duration(row.AttrInt64("msDS-ManagedPasswordInterval")) * 24 * time.Hour, nil
+}
+
+// ManagedPassword is the decoded form of the constructed msDS-ManagedPassword
+// attribute of a group managed service account.
+//
+// See https://learn.microsoft.com/openspecs/windows_protocols/ms-adts/a9019740-3d73-46ef-a9ae-3ea8eb86ac2e
+type ManagedPassword struct {
+	// Current and Previous hold the passwords as little-endian UTF-16 without
+	// their null terminators. The passwords are random and are normally used
+	// to derive keys, such as the NT hash, rather than displayed. Previous
+	// is nil if the password has never been changed.
+	Current  []byte
+	Previous []byte
+
+	// QueryInterval is the time remaining until the password is next
+	// changed.
+	QueryInterval time.Duration
+
+	// UnchangedInterval is the time remaining until the current password
+	// is no longer accepted.
+	UnchangedInterval time.Duration
+}
+
+// ParseManagedPassword decodes an MSDS-MANAGEDPASSWORD_BLOB.
+func ParseManagedPassword(b []byte) (p *ManagedPassword, err error) {
+	if len(b) < 16 || binary.LittleEndian.Uint16(b) != 1 {
+		return nil, ErrInvalidManagedPassword
+	}
+	length := int(binary.LittleEndian.Uint32(b[4:]))
+	if length < 16 || length > len(b) {
+		return nil, ErrInvalidManagedPassword
+	}
+	b = b[:length]
+	offset := func(i int) int { return int(binary.LittleEndian.Uint16(b[8+2*i:])) }
+	p = new(ManagedPassword)
+	if p.Current, err = managedPasswordBytes(b, offset(0)); err != nil {
+		return nil, err
+	}
+	if p.Previous, err = managedPasswordBytes(b, offset(1)); err != nil {
+		return nil, err
+	}
+	for i, field := range []*time.Duration{&p.QueryInterval, &p.UnchangedInterval} {
+		off := offset(2 + i)
+		if off == 0 {
+			continue
+		}
+		if off+8 > len(b) {
+			return nil, ErrInvalidManagedPassword
+		}
+		*field = time.Duration(binary.LittleEndian.Uint64(b[off:])) * 100
+	}
+	return p, nil
+}
+
+// managedPasswordBytes returns the null-terminated UTF-16 password at the
+// given offset, without its terminator. An offset of zero means the password
+// is absent.
+func managedPasswordBytes(b []byte, offset int) ([]byte, error) {
+	if offset == 0 {
+		return nil, nil
+	}
+	for i := offset; i+2 <= len(b); i += 2 {
+		if b[i] == 0 && b[i+1] == 0 {
+			return append([]byte(nil), b[offset:i]...), nil
+		}
+	}
+	return nil, ErrInvalidManagedPassword
+}
+
+// ManagedPassword retrieves and decodes the constructed msDS-ManagedPassword
+// attribute of a group managed service account. The caller must be one of
+// the principals allowed to retrieve the password and the connection must be
+// encrypted. If the password cannot be read ErrNoManagedPassword is
+// returned.
+func (o *object) ManagedPassword() (p *ManagedPassword, err error) {
+	row, err := o.baseRow(Query{Attributes: []string{"msDS-ManagedPassword"}})
+	if err != nil {
+		return
+	}
+	b := row.AttrBytes("msDS-ManagedPassword")
+	if b == nil {
+		return nil, ErrNoManagedPassword
+	}
+	return ParseManagedPassword(b)
+}
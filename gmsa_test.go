@@ -0,0 +1,107 @@
+package adsi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// managedPasswordBlob builds an MSDS-MANAGEDPASSWORD_BLOB holding the given
+// passwords and intervals, in the layout written by domain controllers. An
+// empty previous password is omitted.
+func managedPasswordBlob(current, previous string, query, unchanged time.Duration) []byte {
+	utf16z := func(s string) []byte { return append(utf16LEBytes(s), 0, 0) }
+	b := make([]byte, 16)
+	binary.LittleEndian.PutUint16(b, 1)
+	binary.LittleEndian.PutUint16(b[8:], uint16(len(b)))
+	b = append(b, utf16z(current)...)
+	if previous != "" {
+		binary.LittleEndian.PutUint16(b[10:], uint16(len(b)))
+		b = append(b, utf16z(previous)...)
+	}
+	for len(b)%8 != 0 {
+		b = append(b, 0)
+	}
+	binary.LittleEndian.PutUint16(b[12:], uint16(len(b)))
+	b = binary.LittleEndian.AppendUint64(b, uint64(query/100))
+	binary.LittleEndian.PutUint16(b[14:], uint16(len(b)))
+	b = binary.LittleEndian.AppendUint64(b, uint64(unchanged/100))
+	binary.LittleEndian.PutUint32(b[4:], uint32(len(b)))
+	return b
+}
+
+func TestParseManagedPassword(t *testing.T) {
+	tests := []struct {
+		name               string
+		current, previous  string
+		query, unchanged   time.Duration
+		wantPreviousAbsent bool
+	}{
+		{"current only", "s3crét", "", 29 * 24 * time.Hour, 30 * 24 * time.Hour, true},
+		{"current and previous", "new password", "old password", time.Hour, 2 * time.Hour, false},
+		{"surrogate pair", "\U0001F511key", "", 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blob := managedPasswordBlob(tt.current, tt.previous, tt.query, tt.unchanged)
+			// Trailing bytes beyond the blob length are ignored
+			p, err := ParseManagedPassword(append(blob, 0xff, 0xff))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(p.Current, utf16LEBytes(tt.current)) {
+				t.Errorf("Current = %x, want %x", p.Current, utf16LEBytes(tt.current))
+			}
+			if tt.wantPreviousAbsent {
+				if p.Previous != nil {
+					t.Errorf("Previous = %x, want nil", p.Previous)
+				}
+			} else if !bytes.Equal(p.Previous, utf16LEBytes(tt.previous)) {
+				t.Errorf("Previous = %x, want %x", p.Previous, utf16LEBytes(tt.previous))
+			}
+			if p.QueryInterval != tt.query || p.UnchangedInterval != tt.unchanged {
+				t.Errorf("intervals = %v, %v, want %v, %v", p.QueryInterval, p.UnchangedInterval, tt.query, tt.unchanged)
+			}
+		})
+	}
+}
+
+func TestParseManagedPasswordInvalid(t *testing.T) {
+	valid := managedPasswordBlob("password", "", time.Hour, time.Hour)
+	modify := func(fn func(b []byte) []byte) []byte {
+		return fn(append([]byte(nil), valid...))
+	}
+	tests := []struct {
+		name string
+		blob []byte
+	}{
+		{"empty", nil},
+		{"short header", valid[:15]},
+		{"version", modify(func(b []byte) []byte { b[0] = 2; return b })},
+		{"length beyond blob", modify(func(b []byte) []byte { return b[:len(b)-1] })},
+		{"length within header", modify(func(b []byte) []byte {
+			binary.LittleEndian.PutUint32(b[4:], 8)
+			return b
+		})},
+		{"unterminated password", modify(func(b []byte) []byte {
+			binary.LittleEndian.PutUint32(b[4:], 16+4)
+			return b
+		})},
+		{"password offset beyond blob", modify(func(b []byte) []byte {
+			binary.LittleEndian.PutUint16(b[8:], uint16(len(b)+2))
+			return b
+		})},
+		{"interval beyond blob", modify(func(b []byte) []byte {
+			binary.LittleEndian.PutUint16(b[14:], uint16(len(b)-4))
+			return b
+		})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if p, err := ParseManagedPassword(tt.blob); err != ErrInvalidManagedPassword {
+				t.Errorf("ParseManagedPassword() = %+v, %v, want ErrInvalidManagedPassword", p, err)
+			}
+		})
+	}
+}
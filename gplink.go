@@ -0,0 +1,225 @@
+package adsi
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-adsi/adsi/adspath"
+	"github.com/go-adsi/adsi/api"
+)
+
+// ErrInvalidGPLink is returned when a gPLink value cannot be parsed.
+var ErrInvalidGPLink = errors.New("invalid gPLink")
+
+// Link options held by each entry of a gPLink value.
+const (
+	gpLinkDisabled = 0x1
+	gpLinkEnforced = 0x2
+)
+
+// gpOptionsBlockInheritance is the gPOptions value that blocks inheritance of
+// Group Policy from parent containers.
+const gpOptionsBlockInheritance = 0x1
+
+// GPLink is a single link from a domain, site or organizational unit to a
+// Group Policy object, as held by the gPLink attribute.
+type GPLink struct {
+	// DN is the distinguished name of the groupPolicyContainer object.
+	DN string
+
+	// Order is the link order of the link. Links with a lower order take
+	// precedence, starting at 1.
+	Order int
+
+	Disabled bool
+	Enforced bool
+}
+
+// String returns the link in its gPLink form.
+func (l GPLink) String() string {
+	options := 0
+	if l.Disabled {
+		options |= gpLinkDisabled
+	}
+	if l.Enforced {
+		options |= gpLinkEnforced
+	}
+	return fmt.Sprintf("[LDAP://%s;%d]", l.DN, options)
+}
+
+// ParseGPLink parses a gPLink value. The returned links are sorted by link
+// order, with the link that takes precedence first.
+//
+// Links are stored in gPLink in reverse order of precedence, so the last
+// entry of the value has link order 1.
+func ParseGPLink(s string) (links []GPLink, err error) {
+	s = strings.TrimSpace(s)
+	for s != "" {
+		if s[0] != '[' {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidGPLink, s)
+		}
+		end := strings.IndexByte(s, ']')
+		if end < 0 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidGPLink, s)
+		}
+		entry := s[1:end]
+		s = strings.TrimSpace(s[end+1:])
+
+		sep := strings.LastIndexByte(entry, ';')
+		if sep < 0 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidGPLink, entry)
+		}
+		options, err := strconv.Atoi(entry[sep+1:])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidGPLink, entry)
+		}
+		path, err := adspath.Parse(entry[:sep])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidGPLink, entry)
+		}
+		links = append(links, GPLink{
+			DN:       path.Path,
+			Disabled: options&gpLinkDisabled != 0,
+			Enforced: options&gpLinkEnforced != 0,
+		})
+	}
+	// Reverse the links so that they are in order of precedence
+	for i, j := 0, len(links)-1; i < j; i, j = i+1, j-1 {
+		links[i], links[j] = links[j], links[i]
+	}
+	for i := range links {
+		links[i].Order = i + 1
+	}
+	return
+}
+
+// FormatGPLink formats links as a gPLink value. The links are written in
+// reverse order of their position in the slice, so that the first link takes
+// precedence. The Order field of each link is ignored.
+func FormatGPLink(links []GPLink) string {
+	var b strings.Builder
+	for i := len(links) - 1; i >= 0; i-- {
+		b.WriteString(links[i].String())
+	}
+	return b.String()
+}
+
+// GPO describes a Group Policy object, as held by a groupPolicyContainer
+// object.
+type GPO struct {
+	DN string
+
+	// Name is the GUID of the policy in braces, which is the common name of
+	// the groupPolicyContainer object.
+	Name        string
+	DisplayName string
+
+	// FileSysPath is the UNC path of the policy's folder in SYSVOL.
+	FileSysPath string
+
+	// UserVersion and ComputerVersion are the version numbers of the user
+	// and computer halves of the policy.
+	UserVersion     int
+	ComputerVersion int
+
+	// UserDisabled and ComputerDisabled report whether the user or computer
+	// settings of the policy are disabled.
+	UserDisabled     bool
+	ComputerDisabled bool
+
+	WhenCreated time.Time
+	WhenChanged time.Time
+}
+
+// gpoAttrs are the attributes read from each groupPolicyContainer object.
+var gpoAttrs = []string{
+	"distinguishedName", "cn", "displayName", "gPCFileSysPath",
+	"versionNumber", "flags", "whenCreated", "whenChanged",
+}
+
+func gpoFromRow(row *Row) *GPO {
+	version := uint32(row.AttrInt64("versionNumber"))
+	flags := row.AttrInt("flags")
+	return &GPO{
+		DN:               row.AttrString("distinguishedName"),
+		Name:             row.AttrString("cn"),
+		DisplayName:      row.AttrString("displayName"),
+		FileSysPath:      row.AttrString("gPCFileSysPath"),
+		UserVersion:      int(version >> 16),
+		ComputerVersion:  int(version & 0xFFFF),
+		UserDisabled:     flags&0x1 != 0,
+		ComputerDisabled: flags&0x2 != 0,
+		WhenCreated:      row.AttrTime("whenCreated"),
+		WhenChanged:      row.AttrTime("whenChanged"),
+	}
+}
+
+// GPLinks retrieves and parses the gPLink attribute of the object, which may
+// be a domain, site or organizational unit. The links are sorted by link
+// order. If the object has no linked policies an empty slice is returned.
+func (o *object) GPLinks() (links []GPLink, err error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return nil, ErrClosed
+	}
+	value, err := o.AttrString("gPLink")
+	if errors.Is(err, api.ErrPropertyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return
+	}
+	return ParseGPLink(value)
+}
+
+// SetGPLinks replaces the gPLink attribute of the object with the given
+// links, the first of which takes precedence. If links is empty the attribute
+// is cleared. The value must be commited with SetInfo to be made persistent.
+func (o *object) SetGPLinks(links []GPLink) error {
+	if len(links) == 0 {
+		return o.PutEx(api.ADS_PROPERTY_CLEAR, "gPLink")
+	}
+	return o.PutString("gPLink", FormatGPLink(links))
+}
+
+// BlocksInheritance returns true if the gPOptions attribute of the object
+// blocks inheritance of Group Policy from its parents.
+func (o *object) BlocksInheritance() (blocked bool, err error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return false, ErrClosed
+	}
+	value, err := o.AttrInt("gPOptions")
+	if errors.Is(err, api.ErrPropertyNotFound) {
+		return false, nil
+	}
+	return value&gpOptionsBlockInheritance != 0, err
+}
+
+// LinkedGPOs resolves each of the object's Group Policy links to its
+// groupPolicyContainer object, in order of link precedence. Links to
+// policies that no longer exist are omitted.
+func (o *object) LinkedGPOs() (gpos []*GPO, err error) {
+	links, err := o.GPLinks()
+	if err != nil {
+		return
+	}
+	for _, link := range links {
+		rows, err := o.searchDNAll(link.DN, Query{Attributes: gpoAttrs, Scope: ScopeBase})
+		if err != nil {
+			if isNoSuchObject(err) {
+				continue
+			}
+			return nil, err
+		}
+		if len(rows) > 0 {
+			gpos = append(gpos, gpoFromRow(rows[0]))
+		}
+	}
+	return
+}
@@ -1,6 +1,8 @@
 package adsi
 
 import (
+	"time"
+
 	"github.com/scjalliance/comshim"
 
 	"github.com/go-adsi/adsi/api"
@@ -15,7 +17,7 @@ type Group struct {
 // NewGroup returns a group that manages the given COM interface.
 func NewGroup(iface *api.IADsGroup) *Group {
 	comshim.Add(1)
-	return &Group{iface: iface, object: object{iface: &iface.IADs}}
+	return track(&Group{iface: iface, object: object{iface: &iface.IADs}})
 }
 
 func (g *Group) closed() bool {
@@ -29,21 +31,34 @@ func (g *Group) Add(item string) (err error) {
 	if g.closed() {
 		return ErrClosed
 	}
-	return g.iface.Add(item)
+	g.h.run(func() {
+		defer beginCall()()
+		start := time.Now()
+		err = g.iface.Add(item)
+		err = g.err("Add "+item, err)
+		g.audit(AuditAddMember, start, []string{item}, err)
+	})
+	return err
 }
 
 // Close will release resources consumed by the group. It should be
 // called when the group is no longer needed.
-func (g *Group) Close() {
+func (g *Group) Close() error {
 	g.m.Lock()
 	defer g.m.Unlock()
 	if g.closed() {
-		return
+		return nil
 	}
+	untrack(g)
 	defer comshim.Done()
-	g.iface.Release()
+	defer g.h.released()
+	g.h.run(func() {
+		g.releaseInterfaces()
+		g.iface.Release()
+	})
 	g.object.iface = nil
 	g.iface = nil
+	return nil
 }
 
 // Description retrieves the description of the group.
@@ -53,7 +68,27 @@ func (g *Group) Description() (desc string, err error) {
 	if g.closed() {
 		return "", ErrClosed
 	}
-	desc, err = g.iface.Description()
+	g.h.run(func() {
+		defer beginCall()()
+		desc, err = g.iface.Description()
+		err = g.err("Description", err)
+	})
+	return
+}
+
+// IsMember determines whether the ADSI object with the given path is a direct
+// member of the group.
+func (g *Group) IsMember(item string) (isMember bool, err error) {
+	g.m.Lock()
+	defer g.m.Unlock()
+	if g.closed() {
+		return false, ErrClosed
+	}
+	g.h.run(func() {
+		defer beginCall()()
+		isMember, err = g.iface.IsMember(item)
+		err = g.err("IsMember "+item, err)
+	})
 	return
 }
 
@@ -65,11 +100,17 @@ func (g *Group) Members() (m *Members, err error) {
 	if g.closed() {
 		return nil, ErrClosed
 	}
-	imembers, err := g.iface.Members()
+	var imembers *api.IADsMembers
+	g.h.run(func() {
+		defer beginCall()()
+		imembers, err = g.iface.Members()
+		err = g.err("Members", err)
+	})
 	if err != nil {
 		return
 	}
 	m = NewMembers(imembers)
+	m.h = g.h.opened()
 	return
 }
 
@@ -81,5 +122,71 @@ func (g *Group) Remove(item string) error {
 	if g.closed() {
 		return ErrClosed
 	}
-	return g.iface.Remove(item)
+	var err error
+	g.h.run(func() {
+		defer beginCall()()
+		start := time.Now()
+		err = g.iface.Remove(item)
+		err = g.err("Remove "+item, err)
+		g.audit(AuditRemoveMember, start, []string{item}, err)
+	})
+	return err
+}
+
+// Type retrieves the groupType attribute of the group.
+func (g *Group) Type() (t GroupType, err error) {
+	g.m.Lock()
+	defer g.m.Unlock()
+	if g.closed() {
+		return 0, ErrClosed
+	}
+	value, err := g.object.AttrInt("groupType")
+	if err != nil {
+		return
+	}
+	return GroupType(uint32(value)), nil
+}
+
+// SetType sets the groupType attribute of the group in the ADSI attribute
+// cache. The value must be commited with SetInfo to be made persistent.
+func (g *Group) SetType(t GroupType) error {
+	g.m.Lock()
+	defer g.m.Unlock()
+	if g.closed() {
+		return ErrClosed
+	}
+	var err error
+	g.h.run(func() {
+		defer beginCall()()
+		start := time.Now()
+		err = g.iface.PutInt("groupType", int(int32(t)))
+		err = g.err("Put groupType", err)
+		g.auditPut("groupType", start, err)
+	})
+	return err
+}
+
+// Scope retrieves the scope of the group, which will be one of
+// GroupTypeBuiltinLocal, GroupTypeGlobal, GroupTypeDomainLocal or
+// GroupTypeUniversal.
+func (g *Group) Scope() (scope GroupType, err error) {
+	t, err := g.Type()
+	if err != nil {
+		return
+	}
+	return t.Scope(), nil
+}
+
+// SetScope changes the scope of the group while preserving its security
+// flag. The value must be commited with SetInfo to be made persistent.
+//
+// Active Directory only permits some scope transitions. A global or domain
+// local group must be converted to a universal group before it can be
+// converted to the other scope.
+func (g *Group) SetScope(scope GroupType) error {
+	t, err := g.Type()
+	if err != nil {
+		return err
+	}
+	return g.SetType(t.WithScope(scope))
 }
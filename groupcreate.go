@@ -0,0 +1,87 @@
+package adsi
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-adsi/adsi/api"
+)
+
+// GroupOptions holds the optional settings of a group created by
+// CreateGroup.
+type GroupOptions struct {
+	// SAMAccountName is the pre-Windows 2000 name of the group. If empty the
+	// group name is used.
+	SAMAccountName string
+
+	Description string
+	Mail        string
+
+	// ManagedBy is the distinguished name of the object that manages the
+	// group.
+	ManagedBy string
+
+	// Members holds the distinguished names of the initial members of the
+	// group.
+	Members []string
+}
+
+// CreateGroup creates a group with the given name, scope and category
+// within the container and returns it. The scope must be GroupTypeGlobal,
+// GroupTypeDomainLocal or GroupTypeUniversal, and the category must be
+// GroupTypeSecurity or GroupTypeDistribution. The group, its attributes and
+// its initial members are written to the directory in a single SetInfo.
+//
+// The returned group consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned group when it is no
+// longer needed.
+func (c *Container) CreateGroup(name string, scope, category GroupType, opts GroupOptions) (group *Group, err error) {
+	if name == "" {
+		return nil, errors.New("group name is required")
+	}
+	switch scope {
+	case GroupTypeGlobal, GroupTypeDomainLocal, GroupTypeUniversal:
+	default:
+		return nil, fmt.Errorf("invalid group scope: 0x%08X", uint32(scope))
+	}
+	if category != GroupTypeSecurity && category != GroupTypeDistribution {
+		return nil, fmt.Errorf("invalid group category: 0x%08X", uint32(category))
+	}
+	sam := opts.SAMAccountName
+	if sam == "" {
+		sam = name
+	}
+
+	obj, err := c.Create("group", "CN="+escapeRDNValue(name))
+	if err != nil {
+		return
+	}
+	defer obj.Close()
+	if err = obj.PutString("sAMAccountName", sam); err != nil {
+		return
+	}
+	if err = obj.PutInt("groupType", int(int32(scope|category))); err != nil {
+		return
+	}
+	for _, attr := range []struct{ name, value string }{
+		{"description", opts.Description},
+		{"mail", opts.Mail},
+		{"managedBy", opts.ManagedBy},
+	} {
+		if attr.value == "" {
+			continue
+		}
+		if err = obj.PutString(attr.name, attr.value); err != nil {
+			return
+		}
+	}
+	if len(opts.Members) > 0 {
+		if err = obj.PutEx(api.ADS_PROPERTY_UPDATE, "member", stringsToValues(opts.Members)...); err != nil {
+			return
+		}
+	}
+	if err = obj.SetInfo(); err != nil {
+		return
+	}
+	return obj.ToGroup()
+}
@@ -0,0 +1,68 @@
+package adsi
+
+// GroupType holds the flags stored in the groupType attribute of an Active
+// Directory group. A group type combines exactly one scope with an optional
+// security flag. Groups without the security flag are distribution groups.
+//
+// See https://learn.microsoft.com/windows/win32/adschema/a-grouptype
+type GroupType uint32
+
+// Group type flags.
+const (
+	GroupTypeBuiltinLocal GroupType = 0x00000001
+	GroupTypeGlobal       GroupType = 0x00000002
+	GroupTypeDomainLocal  GroupType = 0x00000004
+	GroupTypeUniversal    GroupType = 0x00000008
+	GroupTypeAppBasic     GroupType = 0x00000010
+	GroupTypeAppQuery     GroupType = 0x00000020
+	GroupTypeSecurity     GroupType = 0x80000000
+
+	// GroupTypeDistribution is the absence of the security flag. It is
+	// provided for readability when constructing group types.
+	GroupTypeDistribution GroupType = 0
+
+	groupTypeScopeMask = GroupTypeBuiltinLocal | GroupTypeGlobal | GroupTypeDomainLocal | GroupTypeUniversal | GroupTypeAppBasic | GroupTypeAppQuery
+)
+
+// Scope returns the scope flags of the group type with the security flag
+// removed.
+func (t GroupType) Scope() GroupType {
+	return t & groupTypeScopeMask
+}
+
+// IsSecurity returns true if the group type describes a security group.
+func (t GroupType) IsSecurity() bool {
+	return t&GroupTypeSecurity != 0
+}
+
+// WithScope returns a copy of the group type with its scope replaced by the
+// given scope. The security flag is preserved.
+func (t GroupType) WithScope(scope GroupType) GroupType {
+	return t&^groupTypeScopeMask | scope.Scope()
+}
+
+// String returns a human readable description of the group type, such as
+// "global security".
+func (t GroupType) String() string {
+	var scope string
+	switch t.Scope() {
+	case GroupTypeBuiltinLocal:
+		scope = "builtin local"
+	case GroupTypeGlobal:
+		scope = "global"
+	case GroupTypeDomainLocal:
+		scope = "domain local"
+	case GroupTypeUniversal:
+		scope = "universal"
+	case GroupTypeAppBasic:
+		scope = "app basic"
+	case GroupTypeAppQuery:
+		scope = "app query"
+	default:
+		scope = "unknown"
+	}
+	if t.IsSecurity() {
+		return scope + " security"
+	}
+	return scope + " distribution"
+}
@@ -0,0 +1,83 @@
+package adsi
+
+import (
+	"log/slog"
+	"time"
+)
+
+// hooks holds the worker and the callbacks of a client. Objects opened
+// through the client share its hooks and pass them on to the objects derived
+// from them. A hooks value is never modified once it has been shared; the
+// client replaces it with a modified copy instead.
+type hooks struct {
+	w      *worker
+	audit  AuditFunc
+	logger *slog.Logger
+	levels LogLevels
+	tracer Tracer
+	meter  MetricsRecorder
+
+	// schema identifies the linked attributes for write checks
+	schema *Schema
+
+	// client is the client that opened the objects sharing the hooks, and
+	// user, password and flags the security context of the bind. They are
+	// reused when an object opens another on its behalf.
+	client   *Client
+	user     string
+	password string
+	flags    uint32
+}
+
+// clone returns a copy of the hooks that may be modified.
+func (h *hooks) clone() *hooks {
+	if h == nil {
+		return &hooks{levels: DefaultLogLevels}
+	}
+	c := *h
+	return &c
+}
+
+// auditing returns true if write operations are reported to an audit hook,
+// a logger, a tracer or a metrics recorder.
+func (h *hooks) auditing() bool {
+	return h != nil && (h.audit != nil || h.logger != nil || h.tracer != nil || h.meter != nil)
+}
+
+// observing returns true if binds and searches are reported to a logger or
+// a tracer, which need the path of the search base.
+func (h *hooks) observing() bool {
+	return h.logging() || h.tracing()
+}
+
+// bind reports an attempt to open the object with the given path.
+func (h *hooks) bind(path, user string, flags uint32, start time.Time, err error) {
+	h.logBind(path, user, flags, start, err)
+	h.measure(SpanBind, start, err)
+	h.trace(TraceSpan{Name: SpanBind, Start: start, Path: path, Err: err})
+}
+
+// write reports a write operation that began at the given time to the audit
+// hook, the logger, the tracer and the metrics recorder.
+func (h *hooks) write(start time.Time, e AuditEvent) {
+	e.Time = time.Now()
+	e.Duration = e.Time.Sub(start)
+	if h.audit != nil {
+		h.audit(e)
+	}
+	h.logWrite(e)
+	h.measure("adsi."+e.Op.String(), start, e.Err)
+	h.trace(TraceSpan{Name: "adsi." + e.Op.String(), Start: start, End: e.Time, Path: e.Path, Attributes: e.Attributes, Err: e.Err})
+}
+
+// searched reports a search that has completed or failed. It is only
+// reported once.
+func (r *SearchResult) searched(err error) {
+	if r.reported {
+		return
+	}
+	r.reported = true
+	r.logSearch(err)
+	r.h.measure(SpanSearch, r.start, err)
+	r.h.trace(TraceSpan{Name: SpanSearch, Start: r.start, Path: r.base, Filter: r.filter, Rows: r.rows, Err: err})
+}
@@ -0,0 +1,83 @@
+package adsi
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// fileTimeAttributes are the large integer attributes that hold
+// FILETIME-style timestamps rather than counts or intervals.
+var fileTimeAttributes = map[string]bool{
+	"accountexpires":                true,
+	"badpasswordtime":               true,
+	"lastlogoff":                    true,
+	"lastlogon":                     true,
+	"lastlogontimestamp":            true,
+	"lockouttime":                   true,
+	"pwdlastset":                    true,
+	"creationtime":                  true,
+	"ms-mcs-admpwdexpirationtime":   true,
+	"mslaps-passwordexpirationtime": true,
+}
+
+// MarshalJSON encodes the row as a JSON object that maps each column name to
+// an array of its values. Values are encoded as described by
+// Object.MarshalJSON.
+func (r *Row) MarshalJSON() ([]byte, error) {
+	m := make(map[string][]interface{}, len(r.columns))
+	for _, column := range r.columns {
+		values := make([]interface{}, len(column.Values))
+		for i, value := range column.Values {
+			values[i] = jsonValue(column.Name, value)
+		}
+		m[column.Name] = values
+	}
+	return json.Marshal(m)
+}
+
+// MarshalJSON reads every attribute of the object that the caller is
+// permitted to read and encodes them as a JSON object that maps each
+// attribute name to an array of its values.
+//
+// GUIDs are encoded as strings in the form displayed by Windows tools and
+// SIDs in their "S-1-..." string form. Timestamps, including large integer
+// timestamps such as pwdLastSet, are encoded in RFC 3339 format, or as null
+// for values that have never been set. Other byte slices are base64
+// encoded.
+func (o *object) MarshalJSON() ([]byte, error) {
+	row, err := o.Row()
+	if err != nil {
+		return nil, err
+	}
+	return row.MarshalJSON()
+}
+
+// jsonValue converts a value of the given attribute to the form in which it
+// is encoded as JSON.
+func jsonValue(attr string, value interface{}) interface{} {
+	name := strings.ToLower(attr)
+	switch v := value.(type) {
+	case []byte:
+		switch {
+		case len(v) == 16 && strings.HasSuffix(name, "guid"):
+			return guidFromWindowsBytes(v).String()
+		case strings.HasSuffix(name, "sid") || name == "sidhistory" || strings.HasPrefix(name, "tokengroups"):
+			if sid, err := ParseSID(v); err == nil {
+				return sid.String()
+			}
+		}
+	case int64:
+		if fileTimeAttributes[name] {
+			if t := TimeFromFileTime(v); !t.IsZero() {
+				return t
+			}
+			return nil
+		}
+	case time.Time:
+		if v.IsZero() {
+			return nil
+		}
+	}
+	return value
+}
@@ -0,0 +1,199 @@
+package adsi
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-adsi/adsi/api"
+)
+
+var (
+	// ErrNoLAPSPassword is returned when a computer has no LAPS password
+	// that the caller is able to read.
+	ErrNoLAPSPassword = errors.New("no LAPS password available")
+
+	// ErrInvalidLAPSPassword is returned when a Windows LAPS password value
+	// cannot be decoded.
+	ErrInvalidLAPSPassword = errors.New("invalid LAPS password")
+)
+
+// LAPS attributes of computer objects. The legacy attributes are present when
+// the schema has been extended for the original Microsoft LAPS, while the
+// msLAPS attributes belong to Windows LAPS.
+var lapsAttrs = []string{
+	"ms-Mcs-AdmPwd", "ms-Mcs-AdmPwdExpirationTime",
+	"msLAPS-Password", "msLAPS-EncryptedPassword", "msLAPS-PasswordExpirationTime",
+}
+
+// LAPSPassword is a local administrator password managed by LAPS.
+type LAPSPassword struct {
+	// Account is the name of the managed local account. It is empty for
+	// legacy LAPS, which does not record it.
+	Account string
+
+	Password string
+
+	// UpdateTime is the time the password was last set. It is the zero time
+	// for legacy LAPS, which does not record it.
+	UpdateTime time.Time
+
+	// Expiration is the time at which the password will next be rotated.
+	Expiration time.Time
+
+	// Legacy is true if the password was read from the attributes of the
+	// original Microsoft LAPS rather than Windows LAPS.
+	Legacy bool
+
+	// Encrypted is true if the password was decrypted from
+	// msLAPS-EncryptedPassword.
+	Encrypted bool
+}
+
+// lapsJSON is the JSON form of a Windows LAPS password.
+type lapsJSON struct {
+	Account    string `json:"n"`
+	UpdateTime string `json:"t"`
+	Password   string `json:"p"`
+}
+
+// ParseLAPSPassword decodes the JSON form of a Windows LAPS password, as held
+// by msLAPS-Password or produced by decrypting msLAPS-EncryptedPassword. The
+// expiration time is not part of the value and is left unset.
+func ParseLAPSPassword(b []byte) (p *LAPSPassword, err error) {
+	var v lapsJSON
+	if err = json.Unmarshal(b, &v); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidLAPSPassword, err)
+	}
+	p = &LAPSPassword{Account: v.Account, Password: v.Password}
+	if v.UpdateTime != "" {
+		ft, err := strconv.ParseInt(v.UpdateTime, 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: update time %q", ErrInvalidLAPSPassword, v.UpdateTime)
+		}
+		p.UpdateTime = TimeFromFileTime(ft)
+	}
+	return p, nil
+}
+
+// LAPSEncryptedPassword is the decoded header of an msLAPS-EncryptedPassword
+// value.
+type LAPSEncryptedPassword struct {
+	// UpdateTime is the time the password was encrypted.
+	UpdateTime time.Time
+
+	Flags uint32
+
+	// Blob is the DPAPI-NG protected password.
+	Blob []byte
+}
+
+// lapsEncryptedHeaderSize is the size of the header that precedes the
+// protected blob in msLAPS-EncryptedPassword.
+const lapsEncryptedHeaderSize = 16
+
+// ParseLAPSEncryptedPassword decodes the header of an
+// msLAPS-EncryptedPassword value.
+func ParseLAPSEncryptedPassword(b []byte) (p *LAPSEncryptedPassword, err error) {
+	if len(b) < lapsEncryptedHeaderSize {
+		return nil, ErrInvalidLAPSPassword
+	}
+	high := binary.LittleEndian.Uint32(b[0:])
+	low := binary.LittleEndian.Uint32(b[4:])
+	size := binary.LittleEndian.Uint32(b[8:])
+	if int(size) > len(b)-lapsEncryptedHeaderSize {
+		return nil, ErrInvalidLAPSPassword
+	}
+	return &LAPSEncryptedPassword{
+		UpdateTime: TimeFromFileTime(int64(uint64(high)<<32 | uint64(low))),
+		Flags:      binary.LittleEndian.Uint32(b[12:]),
+		Blob:       b[lapsEncryptedHeaderSize : lapsEncryptedHeaderSize+int(size)],
+	}, nil
+}
+
+// Decrypt decrypts the password with DPAPI-NG using the credentials of the
+// calling thread, which must be an authorized decryptor of the password.
+func (p *LAPSEncryptedPassword) Decrypt() (*LAPSPassword, error) {
+	data, err := api.NCryptUnprotectSecret(p.Blob, api.NCRYPT_SILENT_FLAG)
+	if err != nil {
+		return nil, err
+	}
+	return parseLAPSSecret(data)
+}
+
+// parseLAPSSecret decodes a decrypted msLAPS-EncryptedPassword secret, which
+// is a null-terminated UTF-16 JSON document.
+func parseLAPSSecret(data []byte) (*LAPSPassword, error) {
+	password, err := ParseLAPSPassword([]byte(strings.TrimRight(decodeUTF16(data), "\x00")))
+	if err != nil {
+		return nil, err
+	}
+	password.Encrypted = true
+	return password, nil
+}
+
+// LAPSPassword retrieves the LAPS managed local administrator password of
+// the computer. Windows LAPS is preferred over legacy LAPS, and an encrypted
+// Windows LAPS password is preferred over a clear text one. If no password
+// is present or the caller cannot read any of them ErrNoLAPSPassword is
+// returned.
+//
+// Decrypting an encrypted password requires that the caller is an
+// authorized decryptor. If decryption fails the clear text and legacy
+// attributes are tried before the decryption error is returned.
+func (c *Computer) LAPSPassword() (p *LAPSPassword, err error) {
+	row, err := c.baseRow(Query{Attributes: lapsAttrs})
+	if err != nil {
+		return
+	}
+	expiration := row.AttrTime("msLAPS-PasswordExpirationTime")
+
+	var decryptErr error
+	if b := row.AttrBytes("msLAPS-EncryptedPassword"); len(b) > 0 {
+		encrypted, err := ParseLAPSEncryptedPassword(b)
+		if err != nil {
+			return nil, err
+		}
+		if p, decryptErr = encrypted.Decrypt(); decryptErr == nil {
+			p.Expiration = expiration
+			return p, nil
+		}
+	}
+	if s := row.AttrString("msLAPS-Password"); s != "" {
+		if p, err = ParseLAPSPassword([]byte(s)); err != nil {
+			return nil, err
+		}
+		p.Expiration = expiration
+		return p, nil
+	}
+	if s := row.AttrString("ms-Mcs-AdmPwd"); s != "" {
+		return &LAPSPassword{
+			Password:   s,
+			Expiration: row.AttrTime("ms-Mcs-AdmPwdExpirationTime"),
+			Legacy:     true,
+		}, nil
+	}
+	if decryptErr != nil {
+		return nil, decryptErr
+	}
+	return nil, ErrNoLAPSPassword
+}
+
+// LAPSExpiration retrieves the time at which the LAPS password of the
+// computer will next be rotated, which is readable by more principals than
+// the password itself. The zero time is returned if LAPS does not manage the
+// computer.
+func (c *Computer) LAPSExpiration() (t time.Time, err error) {
+	row, err := c.baseRow(Query{Attributes: []string{"msLAPS-PasswordExpirationTime", "ms-Mcs-AdmPwdExpirationTime"}})
+	if err != nil {
+		return
+	}
+	if t = row.AttrTime("msLAPS-PasswordExpirationTime"); t.IsZero() {
+		t = row.AttrTime("ms-Mcs-AdmPwdExpirationTime")
+	}
+	return t, nil
+}
@@ -0,0 +1,119 @@
+package adsi
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var lapsUpdateTime = time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+
+func TestParseLAPSPassword(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want LAPSPassword
+	}{
+		{
+			name: "windows laps",
+			in:   `{"n":"Administrator","t":"1da6bd42d69d400","p":"Wv6#9kR!2q"}`,
+			want: LAPSPassword{Account: "Administrator", Password: "Wv6#9kR!2q", UpdateTime: lapsUpdateTime},
+		},
+		{
+			name: "upper case update time",
+			in:   `{"n":"Administrator","t":"1DA6BD42D69D400","p":"x"}`,
+			want: LAPSPassword{Account: "Administrator", Password: "x", UpdateTime: lapsUpdateTime},
+		},
+		{
+			name: "no update time",
+			in:   `{"n":"LocalAdmin","p":"secret"}`,
+			want: LAPSPassword{Account: "LocalAdmin", Password: "secret"},
+		},
+		{
+			name: "escaped password",
+			in:   `{"n":"Admin","t":"0","p":"a\"b\\cé"}`,
+			want: LAPSPassword{Account: "Admin", Password: `a"b\cé`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLAPSPassword([]byte(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("ParseLAPSPassword() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLAPSPasswordInvalid(t *testing.T) {
+	for _, in := range []string{
+		``,
+		`not json`,
+		`{"n":"Administrator","t":"not hex","p":"x"}`,
+		`{"n":"Administrator","t":"-","p":"x"}`,
+		`["Administrator"]`,
+	} {
+		if p, err := ParseLAPSPassword([]byte(in)); !errors.Is(err, ErrInvalidLAPSPassword) {
+			t.Errorf("ParseLAPSPassword(%q) = %+v, %v, want ErrInvalidLAPSPassword", in, p, err)
+		}
+	}
+}
+
+func TestParseLAPSEncryptedPassword(t *testing.T) {
+	ft := uint64(FileTimeFromTime(lapsUpdateTime))
+	header := func(size uint32) []byte {
+		b := binary.LittleEndian.AppendUint32(nil, uint32(ft>>32))
+		b = binary.LittleEndian.AppendUint32(b, uint32(ft))
+		b = binary.LittleEndian.AppendUint32(b, size)
+		return binary.LittleEndian.AppendUint32(b, 0)
+	}
+	blob := []byte{0x30, 0x82, 0x01, 0x02}
+
+	tests := []struct {
+		name    string
+		in      []byte
+		want    *LAPSEncryptedPassword
+		wantErr bool
+	}{
+		{"blob", append(header(4), blob...), &LAPSEncryptedPassword{UpdateTime: lapsUpdateTime, Blob: blob}, false},
+		{"trailing bytes", append(header(4), append(blob, 0, 0)...), &LAPSEncryptedPassword{UpdateTime: lapsUpdateTime, Blob: blob}, false},
+		{"empty blob", header(0), &LAPSEncryptedPassword{UpdateTime: lapsUpdateTime, Blob: []byte{}}, false},
+		{"short header", header(0)[:15], nil, true},
+		{"size beyond value", append(header(5), blob...), nil, true},
+		{"negative size", append(header(0xffffffff), blob...), nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLAPSEncryptedPassword(tt.in)
+			if tt.wantErr {
+				if err != ErrInvalidLAPSPassword {
+					t.Errorf("ParseLAPSEncryptedPassword() = %+v, %v, want ErrInvalidLAPSPassword", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseLAPSEncryptedPassword() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLAPSSecret(t *testing.T) {
+	data := append(utf16LEBytes(`{"n":"Administrator","t":"1da6bd42d69d400","p":"pässword"}`), 0, 0)
+	got, err := parseLAPSSecret(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := LAPSPassword{Account: "Administrator", Password: "pässword", UpdateTime: lapsUpdateTime, Encrypted: true}
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("parseLAPSSecret() = %+v, want %+v", *got, want)
+	}
+}
@@ -0,0 +1,84 @@
+package adsi
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// LastLogonReport holds the lastLogon values of a user gathered from each
+// domain controller of its domain.
+type LastLogonReport struct {
+	// Latest is the most recent logon recorded by any domain controller, or
+	// the zero time if none recorded a logon.
+	Latest time.Time
+
+	// Server is the domain controller that recorded Latest.
+	Server string
+
+	// Servers maps each domain controller that was successfully queried to
+	// the lastLogon value it holds.
+	Servers map[string]time.Time
+
+	// Errors maps each domain controller that could not be queried to the
+	// error that was encountered.
+	Errors map[string]error
+}
+
+// LastLogonAll queries every domain controller of the user's domain for the
+// user's lastLogon attribute and reports the most recent value.
+//
+// The lastLogon attribute is not replicated, so each domain controller only
+// records the logons it processed itself. The replicated lastLogonTimestamp
+// attribute is cheaper to read but may lag by up to two weeks.
+//
+// Domain controllers that cannot be reached are recorded in the Errors field
+// of the report rather than causing the whole operation to fail. An error is
+// returned only if the domain controllers cannot be enumerated or none of
+// them could be queried.
+func (u *User) LastLogonAll() (report *LastLogonReport, err error) {
+	dn, err := u.DN()
+	if err != nil {
+		return
+	}
+
+	report = &LastLogonReport{Servers: make(map[string]time.Time)}
+	var m sync.Mutex
+	servers, errs, err := u.fanOut(dn, func(server, path string) error {
+		t, err := u.lastLogonAt(path)
+		if err != nil {
+			return err
+		}
+		m.Lock()
+		defer m.Unlock()
+		report.Servers[server] = t
+		if t.After(report.Latest) {
+			report.Latest = t
+			report.Server = server
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.Errors = errs
+
+	if len(report.Servers) == 0 {
+		return report, fmt.Errorf("unable to query lastLogon on any of %d domain controllers", len(servers))
+	}
+	return report, nil
+}
+
+// lastLogonAt reads the lastLogon attribute of the object at the given path.
+func (u *User) lastLogonAt(path string) (t time.Time, err error) {
+	result, err := u.h.search(path, Query{Attributes: []string{"lastLogon"}, Scope: ScopeBase})
+	if err != nil {
+		return
+	}
+	defer result.Close()
+	rows, err := result.All()
+	if err != nil || len(rows) == 0 {
+		return
+	}
+	return rows[0].AttrTime("lastLogon"), nil
+}
@@ -0,0 +1,251 @@
+// Command adsidump exports a subtree of the directory to LDIF or JSON:
+//
+//	adsidump -base "OU=Staff,DC=example,DC=com" -exclude whenChanged,uSNChanged -sort -o staff.ldif
+//
+// Every object beneath the base is written, with all of the attributes the
+// caller is permitted to read unless -attrs selects some of them. The search
+// is paged, so subtrees of any size can be exported. Binary values are
+// base64 encoded, or left out with -binary omit.
+//
+// With -sort the objects are written in order of their distinguished names,
+// parents before their children, and the attributes of each object in order
+// of their names, so that the exports of two environments, or of one
+// environment at different times, can be compared with a text diff.
+// Attributes that change on every replication, such as uSNChanged, are best
+// excluded with -exclude.
+//
+// As with adsiquery, the export is made through ADSI unless -ldap is given.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/go-adsi/adsi"
+	"github.com/go-adsi/adsi/ldapdir/internal/connect"
+	"github.com/go-adsi/adsi/ldif"
+)
+
+var (
+	server   = flag.String("server", "", "domain controller or domain to export from; required with -ldap")
+	base     = flag.String("base", "", "distinguished name of the subtree; defaults to the default naming context")
+	filter   = flag.String("filter", "(objectClass=*)", "LDAP search filter selecting the objects to export")
+	attrs    = flag.String("attrs", "", "comma separated list of attributes to export; defaults to all")
+	exclude  = flag.String("exclude", "", "comma separated list of attributes to leave out")
+	binary   = flag.String("binary", "base64", "handling of binary values: base64 or omit")
+	format   = flag.String("format", "ldif", "output format: ldif or json")
+	pageSize = flag.Int("pagesize", adsi.DefaultPageSize, "number of objects requested from the server in each page")
+	sorted   = flag.Bool("sort", false, "sort objects and attributes by name")
+	deleted  = flag.Bool("deleted", false, "include deleted objects")
+	output   = flag.String("o", "", "file to write to; defaults to standard output")
+	user     = flag.String("user", "", "user name to bind with")
+	password = flag.String("password", "", "password to bind with")
+	useLDAP  = flag.Bool("ldap", false, "export over LDAP instead of ADSI")
+	useTLS   = flag.Bool("tls", false, "connect with LDAPS; implies -ldap")
+)
+
+func main() {
+	flag.Parse()
+	if flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	if *format != "ldif" && *format != "json" {
+		return fmt.Errorf("invalid format: %q", *format)
+	}
+	if *binary != "base64" && *binary != "omit" {
+		return fmt.Errorf("invalid binary handling: %q", *binary)
+	}
+
+	q := adsi.Query{Filter: *filter, PageSize: *pageSize, Tombstone: *deleted}
+	if *attrs != "" {
+		// The distinguished name identifies each record
+		q.Attributes = append(strings.Split(*attrs, ","), "distinguishedName")
+	}
+	skip := make(map[string]bool)
+	for _, name := range strings.Split(*exclude, ",") {
+		skip[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+
+	opts := connect.Options{Server: *server, User: *user, Password: *password, LDAP: *useLDAP, TLS: *useTLS}
+	dir, closer, err := connect.Dial(opts)
+	if err != nil {
+		return fmt.Errorf("unable to connect: %w", err)
+	}
+	defer closer.Close()
+
+	dn := *base
+	if dn == "" {
+		if dn, err = opts.DefaultNamingContext(dir); err != nil {
+			return fmt.Errorf("unable to read the rootDSE: %w", err)
+		}
+	}
+
+	rows, err := dir.Find(opts.Path(dn), q)
+	if err != nil {
+		return fmt.Errorf("search failed: %w", err)
+	}
+	defer rows.Close()
+
+	out := os.Stdout
+	if *output != "" {
+		if out, err = os.Create(*output); err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+	w := bufio.NewWriter(out)
+	n, err := export(w, rows, skip)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil && *output != "" {
+		err = out.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("export failed after %d objects: %w", n, err)
+	}
+	log.Printf("Exported %d objects\n", n)
+	return nil
+}
+
+// export writes the rows to w in the selected format and returns the number
+// of objects written. With -sort every row is read before any is written.
+func export(w io.Writer, rows adsi.RowIterator, skip map[string]bool) (n int, err error) {
+	var records []*adsi.Row
+	write := newWriter(w)
+	for {
+		row, err := rows.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return n, err
+		}
+		row = clean(row, skip)
+		if *sorted {
+			records = append(records, row)
+			continue
+		}
+		if err = write.row(row); err != nil {
+			return n, err
+		}
+		n++
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return sortKey(records[i].AttrString("distinguishedName")) < sortKey(records[j].AttrString("distinguishedName"))
+	})
+	for _, row := range records {
+		if err = write.row(row); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, write.close()
+}
+
+// sortKey returns the key by which objects are sorted, which holds the
+// components of their distinguished name in reverse order so that parents
+// come before their children.
+func sortKey(dn string) string {
+	var rdns []string
+	start := 0
+	for i := 0; i < len(dn); i++ {
+		switch dn[i] {
+		case '\\':
+			i++
+		case ',':
+			rdns = append(rdns, strings.TrimSpace(dn[start:i]))
+			start = i + 1
+		}
+	}
+	rdns = append(rdns, strings.TrimSpace(dn[start:]))
+	for i, j := 0, len(rdns)-1; i < j; i, j = i+1, j-1 {
+		rdns[i], rdns[j] = rdns[j], rdns[i]
+	}
+	return strings.ToLower(strings.Join(rdns, "\x00"))
+}
+
+// clean returns a copy of the row without the excluded and, with -binary
+// omit, binary columns. With -sort the columns are sorted by name.
+func clean(row *adsi.Row, skip map[string]bool) *adsi.Row {
+	var columns []adsi.Column
+	for _, column := range row.Columns() {
+		if skip[strings.ToLower(column.Name)] || *binary == "omit" && isBinary(column) {
+			continue
+		}
+		columns = append(columns, column)
+	}
+	if *sorted {
+		sort.Slice(columns, func(i, j int) bool {
+			return strings.ToLower(columns[i].Name) < strings.ToLower(columns[j].Name)
+		})
+	}
+	return adsi.NewRow(columns...)
+}
+
+// isBinary returns true if the column holds byte slices.
+func isBinary(column adsi.Column) bool {
+	for _, value := range column.Values {
+		if _, ok := value.([]byte); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// writer writes rows in the selected format.
+type writer struct {
+	w     io.Writer
+	ldif  *ldif.Writer
+	count int
+}
+
+func newWriter(w io.Writer) *writer {
+	if *format == "ldif" {
+		return &writer{w: w, ldif: ldif.NewWriter(w)}
+	}
+	return &writer{w: w}
+}
+
+func (w *writer) row(row *adsi.Row) error {
+	w.count++
+	if w.ldif != nil {
+		return w.ldif.WriteRow(row)
+	}
+	b, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	sep := ","
+	if w.count == 1 {
+		sep = "["
+	}
+	_, err = fmt.Fprintf(w.w, "%s\n%s", sep, b)
+	return err
+}
+
+func (w *writer) close() error {
+	if w.ldif != nil {
+		return w.ldif.Flush()
+	}
+	if w.count == 0 {
+		_, err := fmt.Fprintln(w.w, "[]")
+		return err
+	}
+	_, err := fmt.Fprintln(w.w, "\n]")
+	return err
+}
@@ -0,0 +1,208 @@
+// Command adsiquery searches a directory and writes the matching objects to
+// standard output:
+//
+//	adsiquery -base "DC=example,DC=com" -filter "(objectClass=user)" -attrs cn,mail -format json
+//
+// The search is made through ADSI with the security context of the user, or
+// with the credentials given by -user and -password. With -ldap it is made
+// over plain LDAP with the ldapdir package instead, which also works on
+// platforms other than Windows. If no base is given the default naming
+// context of the server is searched.
+//
+// Results are written as LDIF, CSV or a JSON array, as selected by -format.
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/go-adsi/adsi"
+	"github.com/go-adsi/adsi/ldapdir/internal/connect"
+	"github.com/go-adsi/adsi/ldif"
+)
+
+var (
+	server   = flag.String("server", "", "domain controller or domain to search; required with -ldap")
+	base     = flag.String("base", "", "distinguished name of the search base; defaults to the default naming context")
+	filter   = flag.String("filter", "(objectClass=*)", "LDAP search filter")
+	attrs    = flag.String("attrs", "", "comma separated list of attributes to return; defaults to all")
+	scope    = flag.String("scope", "sub", "search scope: base, one or sub")
+	limit    = flag.Int("limit", 0, "maximum number of objects to return")
+	format   = flag.String("format", "ldif", "output format: ldif, csv or json")
+	user     = flag.String("user", "", "user name to bind with")
+	password = flag.String("password", "", "password to bind with")
+	useLDAP  = flag.Bool("ldap", false, "search over LDAP instead of ADSI")
+	useTLS   = flag.Bool("tls", false, "connect with LDAPS; implies -ldap")
+)
+
+func main() {
+	flag.Parse()
+	if flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	q := adsi.Query{Filter: *filter, SizeLimit: *limit}
+	if *attrs != "" {
+		q.Attributes = strings.Split(*attrs, ",")
+	}
+	switch *scope {
+	case "base":
+		q.Scope = adsi.ScopeBase
+	case "one":
+		q.Scope = adsi.ScopeOneLevel
+	case "sub":
+		q.Scope = adsi.ScopeSubtree
+	default:
+		return fmt.Errorf("invalid scope: %q", *scope)
+	}
+	var write func(io.Writer, adsi.RowIterator) error
+	switch *format {
+	case "ldif":
+		write = writeLDIF
+	case "csv":
+		write = func(w io.Writer, rows adsi.RowIterator) error {
+			return writeCSV(w, rows, q.Attributes)
+		}
+	case "json":
+		write = writeJSON
+	default:
+		return fmt.Errorf("invalid format: %q", *format)
+	}
+
+	opts := connect.Options{Server: *server, User: *user, Password: *password, LDAP: *useLDAP, TLS: *useTLS}
+	dir, closer, err := connect.Dial(opts)
+	if err != nil {
+		return fmt.Errorf("unable to connect: %w", err)
+	}
+	defer closer.Close()
+
+	dn := *base
+	if dn == "" {
+		if dn, err = opts.DefaultNamingContext(dir); err != nil {
+			return fmt.Errorf("unable to read the rootDSE: %w", err)
+		}
+	}
+
+	rows, err := dir.Find(opts.Path(dn), q)
+	if err != nil {
+		return fmt.Errorf("search failed: %w", err)
+	}
+	defer rows.Close()
+
+	out := bufio.NewWriter(os.Stdout)
+	err = write(out, rows)
+	if err == nil {
+		err = out.Flush()
+	}
+	if err != nil {
+		return fmt.Errorf("search failed: %w", err)
+	}
+	return nil
+}
+
+// writeLDIF writes the rows as LDIF content records.
+func writeLDIF(w io.Writer, rows adsi.RowIterator) error {
+	lw := ldif.NewWriter(w)
+	for {
+		row, err := rows.Next()
+		if err == io.EOF {
+			return lw.Flush()
+		}
+		if err != nil {
+			return err
+		}
+		if err = lw.WriteRow(row); err != nil {
+			return err
+		}
+	}
+}
+
+// writeCSV writes the rows with a header holding the requested attributes,
+// or the columns of the first row if none were requested. Binary values are
+// base64 encoded and the values of multi-valued attributes are separated by
+// semicolons.
+func writeCSV(w io.Writer, rows adsi.RowIterator, columns []string) error {
+	cw := csv.NewWriter(w)
+	header := false
+	for {
+		row, err := rows.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if !header {
+			if len(columns) == 0 {
+				for _, column := range row.Columns() {
+					columns = append(columns, column.Name)
+				}
+			}
+			if err = cw.Write(columns); err != nil {
+				return err
+			}
+			header = true
+		}
+		record := make([]string, len(columns))
+		for i, name := range columns {
+			var fields []string
+			for _, value := range row.Attr(name) {
+				s, binary, err := ldif.FormatValue(value)
+				if err != nil {
+					return err
+				}
+				if binary {
+					s = base64.StdEncoding.EncodeToString([]byte(s))
+				}
+				fields = append(fields, s)
+			}
+			record[i] = strings.Join(fields, ";")
+		}
+		if err = cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeJSON writes the rows as a JSON array of objects, as encoded by
+// Row.MarshalJSON.
+func writeJSON(w io.Writer, rows adsi.RowIterator) error {
+	sep := "["
+	for {
+		row, err := rows.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		b, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s\n%s", sep, b)
+		sep = ","
+	}
+	if sep == "[" {
+		_, err := fmt.Fprintln(w, "[]")
+		return err
+	}
+	_, err := fmt.Fprintln(w, "\n]")
+	return err
+}
@@ -0,0 +1,112 @@
+package ldapdir
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-adsi/adsi"
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// OIDs of the virtual list view controls.
+const (
+	controlTypeVLVRequest  = "2.16.840.1.113730.3.4.9"
+	controlTypeVLVResponse = "2.16.840.1.113730.3.4.10"
+)
+
+// countSortKey is the attribute by which the searches of EstimateCount are
+// sorted, as in the adsi package.
+const countSortKey = "name"
+
+// vlvControl is a virtual list view request that selects the first entry of
+// the sorted results and nothing either side of it.
+type vlvControl struct{}
+
+func (c vlvControl) GetControlType() string {
+	return controlTypeVLVRequest
+}
+
+func (c vlvControl) Encode() *ber.Packet {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Control")
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, controlTypeVLVRequest, "Control Type"))
+	packet.AppendChild(ber.NewBoolean(ber.ClassUniversal, ber.TypePrimitive, ber.TagBoolean, true, "Criticality"))
+	value := ber.Encode(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, nil, "Control Value")
+	request := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "VirtualListViewRequest")
+	request.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, 0, "Before Count"))
+	request.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, 0, "After Count"))
+	offset := ber.Encode(ber.ClassContext, ber.TypeConstructed, 0, nil, "By Offset")
+	offset.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, 1, "Offset"))
+	offset.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, 0, "Content Count"))
+	request.AppendChild(offset)
+	value.AppendChild(request)
+	packet.AppendChild(value)
+	return packet
+}
+
+func (c vlvControl) String() string {
+	return "Control Type: LDAP_CONTROL_VLVREQUEST"
+}
+
+// EstimateCount returns the server's estimate of the number of objects
+// matching the query beneath the object with the given ADsPath, like
+// adsi.Client.EstimateCount. The estimate is read from the response to a
+// virtual list view search, which returns a single entry.
+func (d *Directory) EstimateCount(path string, q adsi.Query) (int, error) {
+	dn, err := d.dn(path)
+	if err == nil && q.DirSync {
+		err = unsupported("counting directory synchronization searches")
+	}
+	if err != nil {
+		return 0, &adsi.Error{Op: "EstimateCount", Path: path, Err: err}
+	}
+	filter := q.Filter
+	if filter == "" {
+		filter = "(objectClass=*)"
+	}
+	scope := ldap.ScopeWholeSubtree
+	switch q.Scope {
+	case adsi.ScopeBase:
+		scope = ldap.ScopeBaseObject
+	case adsi.ScopeOneLevel:
+		scope = ldap.ScopeSingleLevel
+	}
+	controls := []ldap.Control{
+		ldap.NewControlServerSideSortingWithSortKeys([]*ldap.SortKey{{AttributeType: countSortKey}}),
+		vlvControl{},
+	}
+	if q.Tombstone {
+		controls = append(controls, ldap.NewControlMicrosoftShowDeleted())
+	}
+	req := ldap.NewSearchRequest(dn, scope, ldap.NeverDerefAliases, 0, int(q.TimeLimit/time.Second), false, filter, []string{"1.1"}, controls)
+	result, err := d.conn.Search(req)
+	if err != nil {
+		return 0, wrapError("EstimateCount", path, err)
+	}
+	n, err := contentCount(ldap.FindControl(result.Controls, controlTypeVLVResponse))
+	if err != nil {
+		return 0, wrapError("EstimateCount", path, err)
+	}
+	return n, nil
+}
+
+// contentCount returns the content count held by a virtual list view
+// response control.
+func contentCount(control ldap.Control) (int, error) {
+	c, ok := control.(*ldap.ControlString)
+	if !ok {
+		return 0, errors.New("ldapdir: server did not return a virtual list view response")
+	}
+	packet, err := ber.DecodePacketErr([]byte(c.ControlValue))
+	if err != nil {
+		return 0, err
+	}
+	if len(packet.Children) < 3 {
+		return 0, errors.New("ldapdir: invalid virtual list view response")
+	}
+	n, _ := packet.Children[1].Value.(int64)
+	if code, _ := packet.Children[2].Value.(int64); code != 0 {
+		return 0, ldap.NewError(uint16(code), nil)
+	}
+	return int(n), nil
+}
@@ -0,0 +1,314 @@
+package ldapdir
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-adsi/adsi"
+	"github.com/go-adsi/adsi/api"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// Entry is an object read from a Directory. It implements adsi.Entry.
+//
+// Like an ADSI object, an entry keeps a cache of the attributes it has read
+// and of the changes made with PutEx, which are written to the directory by
+// SetInfo in a single modify request. Attributes that hold more values than
+// the server returns at once, such as the member attribute of large groups,
+// are read in full with ranged retrieval.
+type Entry struct {
+	d    *Directory
+	dn   string
+	path string
+
+	m       sync.Mutex
+	attrs   map[string]*attribute
+	pending []change
+	closed  bool
+}
+
+// attribute holds the values of an attribute as returned by the server. An
+// attribute with no values is known not to be set.
+type attribute struct {
+	name   string
+	values [][]byte
+}
+
+type change struct {
+	code   uint32
+	name   string
+	values [][]byte
+}
+
+// load reads the named attributes of the entry into its cache. Attributes
+// that were requested by name but not returned are recorded as not set.
+// The caller must hold the entry's lock, unless the entry has not yet been
+// returned to its caller.
+func (e *Entry) load(names []string) error {
+	op := "GetInfoEx"
+	if e.attrs == nil {
+		op = "Open"
+		e.attrs = make(map[string]*attribute)
+	}
+	entry, err := e.read(op, names)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if name != "*" {
+			e.attrs[strings.ToLower(name)] = &attribute{name: name}
+		}
+	}
+	for _, attr := range entry.Attributes {
+		name, start, end, ranged := parseRange(attr.Name)
+		a := &attribute{name: name, values: attr.ByteValues}
+		for ranged && end >= start {
+			// The server returned part of the values, starting from start
+			next, err := e.read(op, []string{fmt.Sprintf("%s;range=%d-*", name, end+1)})
+			if err != nil {
+				return err
+			}
+			ranged = false
+			for _, attr := range next.Attributes {
+				var n string
+				if n, start, end, ranged = parseRange(attr.Name); strings.EqualFold(n, name) {
+					a.values = append(a.values, attr.ByteValues...)
+					break
+				}
+			}
+		}
+		e.attrs[strings.ToLower(name)] = a
+	}
+	return nil
+}
+
+// read reads the named attributes of the entry from the server.
+func (e *Entry) read(op string, names []string) (*ldap.Entry, error) {
+	result, err := e.d.conn.Search(ldap.NewSearchRequest(e.dn, ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false,
+		"(objectClass=*)", names, nil))
+	if err != nil {
+		return nil, wrapError(op, e.path, err)
+	}
+	if len(result.Entries) == 0 {
+		return nil, &adsi.Error{Op: op, Path: e.path, HRESULT: adsi.ErrNoSuchObject.HRESULT}
+	}
+	return result.Entries[0], nil
+}
+
+// parseRange splits an attribute name of the form "member;range=0-1499"
+// returned by ranged retrieval. The end of the last range is returned as -1.
+func parseRange(s string) (name string, start, end int, ranged bool) {
+	name, options, found := strings.Cut(s, ";")
+	if !found {
+		return s, 0, 0, false
+	}
+	for _, option := range strings.Split(options, ";") {
+		r, ok := strings.CutPrefix(strings.ToLower(option), "range=")
+		if !ok {
+			continue
+		}
+		first, last, _ := strings.Cut(r, "-")
+		start, _ = strconv.Atoi(first)
+		if end = -1; last != "*" {
+			end, _ = strconv.Atoi(last)
+		}
+		return name, start, end, true
+	}
+	return name, 0, 0, false
+}
+
+// attr returns the cached values of the named attribute, reading it from the
+// server if it isn't in the cache. The caller must hold the entry's lock.
+func (e *Entry) attr(name string) (*attribute, error) {
+	if e.closed {
+		return nil, adsi.ErrClosed
+	}
+	if a := e.attrs[strings.ToLower(name)]; a != nil {
+		return a, nil
+	}
+	if err := e.load([]string{name}); err != nil {
+		return nil, err
+	}
+	return e.attrs[strings.ToLower(name)], nil
+}
+
+// Name returns the first component of the entry's distinguished name, such
+// as "CN=Jane Doe".
+func (e *Entry) Name() (string, error) {
+	e.m.Lock()
+	defer e.m.Unlock()
+	if e.closed {
+		return "", adsi.ErrClosed
+	}
+	return rdn(e.dn), nil
+}
+
+// Class returns the last value of the entry's objectClass attribute, which
+// is its most specific class.
+func (e *Entry) Class() (string, error) {
+	e.m.Lock()
+	defer e.m.Unlock()
+	a, err := e.attr("objectClass")
+	if err != nil || len(a.values) == 0 {
+		return "", err
+	}
+	return string(a.values[len(a.values)-1]), nil
+}
+
+// Path returns the ADsPath of the entry on the directory's server.
+func (e *Entry) Path() (string, error) {
+	e.m.Lock()
+	defer e.m.Unlock()
+	if e.closed {
+		return "", adsi.ErrClosed
+	}
+	return e.d.path(e.dn), nil
+}
+
+// Attr returns the values of the named attribute, including any changes that
+// have not yet been saved, converted according to the syntax of the
+// attribute. It returns an error with the E_ADS_PROPERTY_NOT_FOUND HRESULT if
+// the attribute has no values.
+func (e *Entry) Attr(name string) ([]interface{}, error) {
+	e.m.Lock()
+	defer e.m.Unlock()
+	a, err := e.attr(name)
+	if err != nil {
+		return nil, err
+	}
+	values := a.values
+	s := e.d.syntax(name)
+	for _, c := range e.pending {
+		if strings.EqualFold(c.name, name) {
+			values = apply(values, c.code, c.values, s)
+		}
+	}
+	if len(values) == 0 {
+		return nil, &adsi.Error{Op: "GetEx " + name, Path: e.path, HRESULT: api.E_ADS_PROPERTY_NOT_FOUND, Err: api.ErrPropertyNotFound}
+	}
+	result, err := e.d.values(a.name, values)
+	if err != nil {
+		return nil, &adsi.Error{Op: "GetEx " + name, Path: e.path, Err: err}
+	}
+	return result, nil
+}
+
+// PutEx records a change to the named attribute, which is written to the
+// directory by SetInfo. The control code is one of the ADS_PROPERTY_*
+// constants in the api package. Values may be strings, byte slices,
+// booleans, integers, times and the api.DNWithBinary and api.DNWithString
+// types.
+func (e *Entry) PutEx(controlCode uint32, name string, values ...interface{}) error {
+	if controlCode < api.ADS_PROPERTY_CLEAR || controlCode > api.ADS_PROPERTY_DELETE {
+		return &adsi.Error{Op: "PutEx " + name, Path: e.path, Err: errors.New("ldapdir: invalid control code")}
+	}
+	c := change{code: controlCode, name: name, values: make([][]byte, len(values))}
+	for i, value := range values {
+		b, err := encode(value)
+		if err != nil {
+			return &adsi.Error{Op: "PutEx " + name, Path: e.path, Err: err}
+		}
+		c.values[i] = b
+	}
+	e.m.Lock()
+	defer e.m.Unlock()
+	if e.closed {
+		return adsi.ErrClosed
+	}
+	e.pending = append(e.pending, c)
+	return nil
+}
+
+// SetInfo writes the changes recorded by PutEx to the directory. The changed
+// attributes are read again from the server when they are next requested.
+func (e *Entry) SetInfo() error {
+	e.m.Lock()
+	defer e.m.Unlock()
+	if e.closed {
+		return adsi.ErrClosed
+	}
+	if len(e.pending) == 0 {
+		return nil
+	}
+	req := ldap.NewModifyRequest(e.dn, nil)
+	for _, c := range e.pending {
+		values := make([]string, len(c.values))
+		for i, v := range c.values {
+			values[i] = string(v)
+		}
+		switch c.code {
+		case api.ADS_PROPERTY_CLEAR:
+			req.Replace(c.name, nil)
+		case api.ADS_PROPERTY_UPDATE:
+			req.Replace(c.name, values)
+		case api.ADS_PROPERTY_APPEND:
+			req.Add(c.name, values)
+		case api.ADS_PROPERTY_DELETE:
+			req.Delete(c.name, values)
+		}
+	}
+	if err := e.d.conn.Modify(req); err != nil {
+		return wrapError("SetInfo", e.path, err)
+	}
+	for _, c := range e.pending {
+		delete(e.attrs, strings.ToLower(c.name))
+	}
+	e.pending = nil
+	return nil
+}
+
+// Close releases the entry. Changes that have not been saved are discarded.
+func (e *Entry) Close() error {
+	e.m.Lock()
+	defer e.m.Unlock()
+	e.closed = true
+	e.attrs = nil
+	e.pending = nil
+	return nil
+}
+
+// apply returns the values that result from applying a change with the given
+// control code to values. The original slice is not modified.
+func apply(values [][]byte, code uint32, changed [][]byte, s syntax) [][]byte {
+	switch code {
+	case api.ADS_PROPERTY_CLEAR:
+		return nil
+	case api.ADS_PROPERTY_UPDATE:
+		return append([][]byte(nil), changed...)
+	case api.ADS_PROPERTY_APPEND:
+		return append(append([][]byte(nil), values...), changed...)
+	case api.ADS_PROPERTY_DELETE:
+		var kept [][]byte
+		for _, v := range values {
+			deleted := false
+			for _, c := range changed {
+				if bytes.Equal(v, c) || !s.binary() && bytes.EqualFold(v, c) {
+					deleted = true
+					break
+				}
+			}
+			if !deleted {
+				kept = append(kept, v)
+			}
+		}
+		return kept
+	}
+	return values
+}
+
+// rdn returns the first component of a distinguished name.
+func rdn(dn string) string {
+	for i := 0; i < len(dn); i++ {
+		switch dn[i] {
+		case '\\':
+			i++
+		case ',':
+			return strings.TrimSpace(dn[:i])
+		}
+	}
+	return strings.TrimSpace(dn)
+}
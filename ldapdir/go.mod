@@ -0,0 +1,19 @@
+module github.com/go-adsi/adsi/ldapdir
+
+go 1.22
+
+require (
+	github.com/go-adsi/adsi v0.1.0
+	github.com/go-asn1-ber/asn1-ber v1.5.5
+	github.com/go-ldap/ldap/v3 v3.4.8
+)
+
+require (
+	github.com/Azure/go-ntlmssp v0.0.0-20221128193559-754e69321358 // indirect
+	github.com/go-ole/go-ole v1.3.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/scjalliance/comshim v0.0.0-20240712181150-e070933cb68e // indirect
+	github.com/scjalliance/comutil v0.0.0-20240712181340-772427873823 // indirect
+	golang.org/x/crypto v0.21.0 // indirect
+	golang.org/x/sys v0.22.0 // indirect
+)
@@ -0,0 +1,113 @@
+// Package connect opens the directory that the adsiquery and adsidump
+// commands read from, either through ADSI or over LDAP with the ldapdir
+// package, as selected by their command lines.
+package connect
+
+import (
+	"crypto/tls"
+	"io"
+	"strings"
+
+	"github.com/go-adsi/adsi"
+	"github.com/go-adsi/adsi/adspath"
+	"github.com/go-adsi/adsi/ldapdir"
+)
+
+var _ adsi.Directory = client{}
+
+// Options select the directory to connect to.
+type Options struct {
+	// Server is the domain controller or domain to connect to. It is
+	// required with LDAP.
+	Server string
+
+	// User and Password are the credentials to bind with. If both are empty
+	// ADSI binds with the security context of the user.
+	User     string
+	Password string
+
+	// LDAP selects plain LDAP instead of ADSI, and TLS selects LDAPS, which
+	// implies LDAP.
+	LDAP bool
+	TLS  bool
+}
+
+// Dial returns the directory selected by the options, and the closer that
+// releases it. It is the caller's responsibilty to call Close on the returned
+// closer when the directory is no longer needed.
+func Dial(opts Options) (adsi.Directory, io.Closer, error) {
+	if opts.LDAP || opts.TLS {
+		o := ldapdir.Options{Server: opts.Server, Username: opts.User, Password: opts.Password}
+		if opts.TLS {
+			host, _, _ := strings.Cut(opts.Server, ":")
+			o.TLS = &tls.Config{ServerName: host}
+		}
+		dir, err := ldapdir.Dial(o)
+		if err != nil {
+			return nil, nil, err
+		}
+		return dir, dir, nil
+	}
+	c, err := adsi.NewClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	return client{Client: c, user: opts.User, password: opts.Password}, c, nil
+}
+
+// Path returns the ADsPath of the object with the given distinguished name
+// on the selected server.
+func (opts Options) Path(dn string) string {
+	return (&adspath.Path{Scheme: "LDAP", Host: opts.Server, Path: adspath.EscapeDN(dn)}).String()
+}
+
+// DefaultNamingContext returns the distinguished name of the default naming
+// context of the selected server.
+func (opts Options) DefaultNamingContext(dir adsi.Binder) (string, error) {
+	root, err := dir.Bind((&adspath.Path{Scheme: "LDAP", Host: opts.Server, Path: "RootDSE"}).String())
+	if err != nil {
+		return "", err
+	}
+	defer root.Close()
+	values, err := root.Attr("defaultNamingContext")
+	if err != nil {
+		return "", err
+	}
+	dn, _ := values[0].(string)
+	return dn, nil
+}
+
+// client adapts an ADSI client to adsi.Directory, binding with the given
+// credentials if there are any.
+type client struct {
+	*adsi.Client
+	user     string
+	password string
+}
+
+func (c client) Bind(path string) (adsi.Entry, error) {
+	if c.user == "" && c.password == "" {
+		return c.Client.Bind(path)
+	}
+	obj, err := c.OpenSC(path, c.user, c.password, c.Flags())
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (c client) Find(path string, q adsi.Query) (adsi.RowIterator, error) {
+	if c.user == "" && c.password == "" {
+		return c.Client.Find(path, q)
+	}
+	searcher, err := c.OpenSearcherSC(path, c.user, c.password, c.Flags())
+	if err != nil {
+		return nil, err
+	}
+	defer searcher.Close()
+	result, err := searcher.Search(q)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
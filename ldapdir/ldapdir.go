@@ -0,0 +1,299 @@
+// Package ldapdir implements the interfaces of the adsi package over a plain
+// LDAP connection, so that applications written against adsi.Directory can
+// run against Active Directory from hosts without ADSI, such as Linux
+// containers.
+//
+//	dir, err := ldapdir.Dial(ldapdir.Options{
+//		Server:   "dc1.example.com",
+//		TLS:      &tls.Config{ServerName: "dc1.example.com"},
+//		Username: "jane@example.com",
+//		Password: password,
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	defer dir.Close()
+//	var d adsi.Directory = dir
+//
+// The connection is bound with a simple bind, or with Kerberos through a
+// GSSAPI client such as the ones provided by the gssapi package of go-ldap.
+//
+// Only a part of what ADSI offers is available. Objects can be read and
+// modified but not created, moved or deleted, paths must name the server
+// the directory is connected to, and searches of the global catalog and
+// directory synchronization searches are not supported. Operations that are
+// not available return an error that matches errors.ErrUnsupported and
+// names the missing feature. Values are converted using the attribute
+// syntaxes of the schema, which is read when it is first needed; the types
+// are those returned by adsi searches.
+package ldapdir
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-adsi/adsi"
+	"github.com/go-adsi/adsi/adspath"
+	"github.com/go-adsi/adsi/api"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// The directory implements the interfaces of the adsi package, so that it can
+// be used in place of an adsi.Client.
+var (
+	_ adsi.Directory   = (*Directory)(nil)
+	_ adsi.Entry       = (*Entry)(nil)
+	_ adsi.RowIterator = (*Rows)(nil)
+)
+
+// Default ports of the LDAP and LDAPS protocols.
+const (
+	portLDAP  = "389"
+	portLDAPS = "636"
+)
+
+// Options describe the connection made by Dial.
+type Options struct {
+	// Server is the host name of the domain controller, optionally followed
+	// by a port.
+	Server string
+
+	// TLS, when non-nil, is the configuration used to secure the
+	// connection. Unless StartTLS is set the connection is made with LDAPS.
+	TLS *tls.Config
+
+	// StartTLS upgrades a plain LDAP connection to TLS with the StartTLS
+	// extended operation before binding.
+	StartTLS bool
+
+	// Username and Password are the credentials of a simple bind. The user
+	// name may be a distinguished name, a user principal name or a name of
+	// the form DOMAIN\user. Domain controllers reject simple binds on
+	// connections that are not secured with TLS unless LDAP signing is
+	// disabled.
+	Username string
+	Password string
+
+	// GSSAPI, when non-nil, binds with Kerberos instead of a simple bind,
+	// using the service principal name given by ServicePrincipal. If
+	// ServicePrincipal is empty "ldap/" followed by the host name of the
+	// server is used.
+	GSSAPI           ldap.GSSAPIClient
+	ServicePrincipal string
+
+	// Timeout is the maximum amount of time each request waits for its
+	// response. If zero no limit is applied.
+	Timeout time.Duration
+}
+
+// Directory is a connection to a domain controller. It implements
+// adsi.Directory. It is safe for concurrent use.
+type Directory struct {
+	conn *ldap.Conn
+	host string
+
+	m      sync.Mutex
+	schema schema
+}
+
+// Dial connects to the server described by opts and binds to it.
+//
+// It is the caller's responsibilty to call Close on the returned directory
+// when it is no longer needed.
+func Dial(opts Options) (*Directory, error) {
+	host, port, err := net.SplitHostPort(opts.Server)
+	if err != nil {
+		host, port = opts.Server, portLDAP
+		if opts.TLS != nil && !opts.StartTLS {
+			port = portLDAPS
+		}
+	}
+	if host == "" {
+		return nil, unsupported("serverless binding")
+	}
+	addr := net.JoinHostPort(host, port)
+
+	var conn *ldap.Conn
+	if opts.TLS != nil && !opts.StartTLS {
+		conn, err = ldap.DialURL("ldaps://"+addr, ldap.DialWithTLSConfig(opts.TLS))
+	} else {
+		conn, err = ldap.DialURL("ldap://" + addr)
+	}
+	if err != nil {
+		return nil, wrapError("Dial", "", err)
+	}
+	if opts.Timeout > 0 {
+		conn.SetTimeout(opts.Timeout)
+	}
+	d := &Directory{conn: conn, host: host}
+
+	if err = d.bind(opts); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+// bind secures the connection if requested and authenticates it.
+func (d *Directory) bind(opts Options) error {
+	if opts.StartTLS {
+		config := opts.TLS
+		if config == nil {
+			config = &tls.Config{ServerName: d.host}
+		}
+		if err := d.conn.StartTLS(config); err != nil {
+			return wrapError("StartTLS", "", err)
+		}
+	}
+	switch {
+	case opts.GSSAPI != nil:
+		spn := opts.ServicePrincipal
+		if spn == "" {
+			spn = "ldap/" + d.host
+		}
+		if err := d.conn.GSSAPIBind(opts.GSSAPI, spn, ""); err != nil {
+			return wrapError("Bind", "", err)
+		}
+	case opts.Username != "" || opts.Password != "":
+		if err := d.conn.Bind(opts.Username, opts.Password); err != nil {
+			return wrapError("Bind", "", err)
+		}
+	}
+	return nil
+}
+
+// Close closes the connection.
+func (d *Directory) Close() error {
+	return d.conn.Close()
+}
+
+// Bind reads the object with the given ADsPath and returns it. The attributes
+// returned by the server for "*" are loaded into the entry's cache, and
+// other attributes are read when they are first requested.
+func (d *Directory) Bind(path string) (adsi.Entry, error) {
+	dn, err := d.dn(path)
+	if err != nil {
+		return nil, &adsi.Error{Op: "Open", Path: path, Err: err}
+	}
+	e := &Entry{d: d, dn: dn, path: path}
+	if err = e.load([]string{"*"}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Find searches beneath the object with the given ADsPath. The filter,
+// attributes, scope, page size, size and time limits, tombstone and security
+// mask of the query are honored; DirSync queries are not supported. Each row
+// includes an ADsPath column.
+func (d *Directory) Find(path string, q adsi.Query) (adsi.RowIterator, error) {
+	dn, err := d.dn(path)
+	if err == nil && q.DirSync {
+		err = unsupported("directory synchronization")
+	}
+	if err != nil {
+		return nil, &adsi.Error{Op: "Search", Path: path, Err: err}
+	}
+	return d.search(path, dn, q), nil
+}
+
+// dn returns the distinguished name held by an ADsPath. The path must use the
+// LDAP scheme and may only name the server the directory is connected to.
+// The rootDSE is named by an empty distinguished name.
+func (d *Directory) dn(path string) (string, error) {
+	p, err := adspath.Parse(path)
+	if err != nil {
+		return "", err
+	}
+	if !strings.EqualFold(p.Scheme, "LDAP") {
+		return "", unsupported(p.Scheme + " paths")
+	}
+	if p.Path == "" && strings.EqualFold(p.Host, "RootDSE") {
+		return "", nil
+	}
+	if host, _, err := net.SplitHostPort(p.Host); err == nil {
+		p.Host = host
+	}
+	if p.Host != "" && !strings.EqualFold(p.Host, d.host) {
+		return "", unsupported("paths naming another server")
+	}
+	if strings.EqualFold(p.Path, "RootDSE") {
+		return "", nil
+	}
+	return strings.ReplaceAll(p.Path, `\/`, "/"), nil
+}
+
+// path returns the ADsPath of the object with the given distinguished name
+// on the directory's server. An empty name is the path of the rootDSE.
+func (d *Directory) path(dn string) string {
+	p := adspath.EscapeDN(dn)
+	if dn == "" {
+		p = "RootDSE"
+	}
+	return (&adspath.Path{Scheme: "LDAP", Host: d.host, Path: p}).String()
+}
+
+// unsupported returns an error reporting that a feature is not available
+// through LDAP.
+func unsupported(feature string) error {
+	return fmt.Errorf("ldapdir: %s not supported: %w", feature, errors.ErrUnsupported)
+}
+
+// hresults maps LDAP result codes to the HRESULTs that the ADSI LDAP
+// provider returns for them, so that the sentinel errors of the adsi package
+// match.
+var hresults = map[uint16]uint32{
+	ldap.LDAPResultConstraintViolation:      0x8007202F, // ERROR_DS_CONSTRAINT_VIOLATION
+	ldap.LDAPResultAttributeOrValueExists:   0x8007200D, // ERROR_DS_ATTRIBUTE_OR_VALUE_EXISTS
+	ldap.LDAPResultNoSuchObject:             0x80072030, // ERROR_DS_NO_SUCH_OBJECT
+	ldap.LDAPResultInvalidDNSyntax:          0x80072032, // ERROR_DS_INVALID_DN_SYNTAX
+	ldap.LDAPResultInvalidCredentials:       0x8007052E, // ERROR_LOGON_FAILURE
+	ldap.LDAPResultInsufficientAccessRights: 0x80070005, // E_ACCESSDENIED
+	ldap.LDAPResultBusy:                     0x8007200E, // ERROR_DS_BUSY
+	ldap.LDAPResultUnavailable:              0x8007200F, // ERROR_DS_UNAVAILABLE
+	ldap.LDAPResultUnwillingToPerform:       0x80072035, // ERROR_DS_UNWILLING_TO_PERFORM
+	ldap.LDAPResultObjectClassViolation:     0x80072014, // ERROR_DS_OBJ_CLASS_VIOLATION
+	ldap.LDAPResultEntryAlreadyExists:       0x80071392, // ERROR_OBJECT_ALREADY_EXISTS
+	ldap.ErrorNetwork:                       0x8007203A, // ERROR_DS_SERVER_DOWN
+	ldap.LDAPResultTimeLimitExceeded:        0x800705B4, // ERROR_TIMEOUT
+	ldap.LDAPResultStrongAuthRequired:       0x80072028, // ERROR_DS_STRONG_AUTH_REQUIRED
+	ldap.LDAPResultConfidentialityRequired:  0x80072028, // ERROR_DS_STRONG_AUTH_REQUIRED
+}
+
+// wrapError returns err as an *adsi.Error for the given operation and path.
+// Errors returned by the server carry their result code as an api.LDAPError
+// and the diagnostic message of the server as extended error information,
+// as they do when they are returned by ADSI.
+func wrapError(op, path string, err error) error {
+	if err == nil {
+		return nil
+	}
+	e := &adsi.Error{Op: op, Path: path, Err: err}
+	var ldapErr *ldap.Error
+	if !errors.As(err, &ldapErr) {
+		return e
+	}
+	e.HRESULT = hresults[ldapErr.ResultCode]
+	if ldapErr.ResultCode < ldap.ErrorNetwork {
+		e.Err = api.LDAPError(ldapErr.ResultCode)
+		if ldapErr.Err != nil && ldapErr.Err.Error() != "" {
+			e.Extended = &api.ExtendedError{Code: diagnosticCode(ldapErr.Err.Error()), Message: ldapErr.Err.Error(), Provider: "LDAP Provider"}
+		}
+	}
+	return e
+}
+
+// diagnosticCode returns the Win32 error code at the start of a diagnostic
+// message returned by Active Directory, such as "0000208D: NameErr: ...".
+func diagnosticCode(message string) uint32 {
+	var code uint32
+	if _, err := fmt.Sscanf(message, "%08X:", &code); err != nil {
+		return 0
+	}
+	return code
+}
@@ -0,0 +1,216 @@
+package ldapdir
+
+import (
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-adsi/adsi"
+	"github.com/go-adsi/adsi/api"
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// controlTypeSDFlags is the OID of the control that selects the parts of
+// nTSecurityDescriptor returned by a search.
+const controlTypeSDFlags = "1.2.840.113556.1.4.801"
+
+// sdFlagsControl is the LDAP_SERVER_SD_FLAGS_OID control.
+type sdFlagsControl uint32
+
+func (c sdFlagsControl) GetControlType() string {
+	return controlTypeSDFlags
+}
+
+func (c sdFlagsControl) Encode() *ber.Packet {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Control")
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, controlTypeSDFlags, "Control Type"))
+	value := ber.Encode(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, nil, "Control Value")
+	flags := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "SD Flags")
+	flags.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, int64(c), "Flags"))
+	value.AppendChild(flags)
+	packet.AppendChild(value)
+	return packet
+}
+
+func (c sdFlagsControl) String() string {
+	return "Control Type: LDAP_SERVER_SD_FLAGS_OID"
+}
+
+// Rows iterates over the rows of a search. It implements adsi.RowIterator.
+// Paged searches read one page at a time, as the rows are consumed.
+type Rows struct {
+	d       *Directory
+	path    string
+	req     *ldap.SearchRequest
+	paging  *ldap.ControlPaging
+	names   []string
+	entries []*ldap.Entry
+	limit   int
+	count   int
+	done    bool
+	closed  bool
+}
+
+// search returns the rows of the query rooted at the object with the given
+// distinguished name. No request is sent until the first row is read.
+func (d *Directory) search(path, dn string, q adsi.Query) *Rows {
+	filter := q.Filter
+	if filter == "" {
+		filter = "(objectClass=*)"
+	}
+	scope := ldap.ScopeWholeSubtree
+	switch q.Scope {
+	case adsi.ScopeBase:
+		scope = ldap.ScopeBaseObject
+	case adsi.ScopeOneLevel:
+		scope = ldap.ScopeSingleLevel
+	}
+	var names []string
+	for _, name := range q.Attributes {
+		if !strings.EqualFold(name, "ADsPath") {
+			names = append(names, name)
+		}
+	}
+	if len(q.Attributes) != 0 && len(names) == 0 {
+		// Only the ADsPath was requested, which is built from the
+		// distinguished name of each row
+		names = []string{"1.1"}
+	}
+
+	r := &Rows{d: d, path: path, limit: q.SizeLimit}
+	if len(q.Attributes) != 0 && !slices.Contains(q.Attributes, "*") {
+		// Rows hold the requested attributes in the order of the request,
+		// unless "*" asks for all of them
+		r.names = q.Attributes
+	}
+	r.req = ldap.NewSearchRequest(dn, scope, ldap.NeverDerefAliases, q.SizeLimit, int(q.TimeLimit/time.Second), false, filter, names, nil)
+	switch {
+	case q.PageSize == 0:
+		r.paging = ldap.NewControlPaging(adsi.DefaultPageSize)
+	case q.PageSize > 0:
+		r.paging = ldap.NewControlPaging(uint32(q.PageSize))
+	}
+	if r.paging != nil {
+		r.req.Controls = append(r.req.Controls, r.paging)
+	}
+	if q.Tombstone {
+		r.req.Controls = append(r.req.Controls, ldap.NewControlMicrosoftShowDeleted())
+	}
+	if q.SecurityMask != 0 {
+		r.req.Controls = append(r.req.Controls, sdFlagsControl(q.SecurityMask))
+	}
+	return r
+}
+
+// Next returns the next row, or io.EOF once every row has been returned.
+func (r *Rows) Next() (*adsi.Row, error) {
+	if r.closed {
+		return nil, adsi.ErrClosed
+	}
+	for len(r.entries) == 0 {
+		if r.done {
+			return nil, io.EOF
+		}
+		if err := r.fetch(); err != nil {
+			r.done = true
+			return nil, err
+		}
+	}
+	entry := r.entries[0]
+	r.entries = r.entries[1:]
+	r.count++
+	if r.limit > 0 && r.count >= r.limit {
+		r.entries, r.done = nil, true
+		r.abandon()
+	}
+	return r.row(entry)
+}
+
+// fetch reads the next page of results.
+func (r *Rows) fetch() error {
+	result, err := r.d.conn.Search(r.req)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		// As with ADSI, the rows returned before the limit was reached are
+		// the result of the search
+		err, r.done = nil, true
+	}
+	if err != nil {
+		return wrapError("Search", r.path, err)
+	}
+	r.entries = result.Entries
+	if r.paging == nil {
+		r.done = true
+		return nil
+	}
+	control, _ := ldap.FindControl(result.Controls, ldap.ControlTypePaging).(*ldap.ControlPaging)
+	if control == nil || len(control.Cookie) == 0 {
+		r.done = true
+		return nil
+	}
+	r.paging.SetCookie(control.Cookie)
+	return nil
+}
+
+// abandon releases the server's state for a paged search that has not been
+// read to the end.
+func (r *Rows) abandon() {
+	if r.paging == nil || len(r.paging.Cookie) == 0 {
+		return
+	}
+	r.paging.PagingSize = 0
+	r.d.conn.Search(r.req)
+	r.paging.SetCookie(nil)
+}
+
+// row converts an entry returned by the server to a row. When attributes were
+// requested by name the columns are in the order of the request.
+func (r *Rows) row(entry *ldap.Entry) (*adsi.Row, error) {
+	var columns []adsi.Column
+	add := func(attr *ldap.EntryAttribute) error {
+		values, err := r.d.values(attr.Name, attr.ByteValues)
+		if err != nil {
+			return wrapError("Search", r.path, err)
+		}
+		columns = append(columns, adsi.Column{Name: attr.Name, Type: r.d.syntax(attr.Name).adsType(), Values: values})
+		return nil
+	}
+	if r.names == nil {
+		for _, attr := range entry.Attributes {
+			if err := add(attr); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		for _, name := range r.names {
+			if strings.EqualFold(name, "ADsPath") {
+				continue
+			}
+			for _, attr := range entry.Attributes {
+				if strings.EqualFold(attr.Name, name) && len(attr.ByteValues) > 0 {
+					if err := add(attr); err != nil {
+						return nil, err
+					}
+					break
+				}
+			}
+		}
+	}
+	columns = append(columns, adsi.Column{Name: "ADsPath", Type: api.ADSTYPE_CASE_IGNORE_STRING, Values: []interface{}{r.d.path(entry.DN)}})
+	return adsi.NewRow(columns...), nil
+}
+
+// Close releases the rows. If the search was paged and has not been read to
+// the end, the server is told to discard its results.
+func (r *Rows) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	if !r.done {
+		r.abandon()
+	}
+	r.entries = nil
+	return nil
+}
@@ -0,0 +1,239 @@
+package ldapdir
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-adsi/adsi"
+	"github.com/go-adsi/adsi/api"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// Attribute syntaxes of Active Directory, as held by the attributeSyntax
+// attribute of attributeSchema objects.
+const (
+	syntaxDN                 = "2.5.5.1"
+	syntaxOID                = "2.5.5.2"
+	syntaxCaseExact          = "2.5.5.3"
+	syntaxCaseIgnore         = "2.5.5.4"
+	syntaxPrintable          = "2.5.5.5"
+	syntaxNumeric            = "2.5.5.6"
+	syntaxDNWithBinary       = "2.5.5.7"
+	syntaxBoolean            = "2.5.5.8"
+	syntaxInteger            = "2.5.5.9"
+	syntaxOctetString        = "2.5.5.10"
+	syntaxTime               = "2.5.5.11"
+	syntaxUnicode            = "2.5.5.12"
+	syntaxPresentation       = "2.5.5.13"
+	syntaxDNWithString       = "2.5.5.14"
+	syntaxSecurityDescriptor = "2.5.5.15"
+	syntaxLargeInteger       = "2.5.5.16"
+	syntaxSID                = "2.5.5.17"
+)
+
+// oMSyntaxUTCTime is the oMSyntax of time attributes that hold UTC times
+// rather than generalized times.
+const oMSyntaxUTCTime = 23
+
+// syntax describes how the values of an attribute are represented.
+type syntax struct {
+	oid      string
+	oMSyntax int
+}
+
+// adsType returns the ADSTYPE of the values of the syntax.
+func (s syntax) adsType() uint32 {
+	switch s.oid {
+	case syntaxDN:
+		return api.ADSTYPE_DN_STRING
+	case syntaxCaseExact:
+		return api.ADSTYPE_CASE_EXACT_STRING
+	case syntaxOID, syntaxCaseIgnore, syntaxUnicode, syntaxPresentation:
+		return api.ADSTYPE_CASE_IGNORE_STRING
+	case syntaxPrintable:
+		return api.ADSTYPE_PRINTABLE_STRING
+	case syntaxNumeric:
+		return api.ADSTYPE_NUMERIC_STRING
+	case syntaxDNWithBinary:
+		return api.ADSTYPE_DN_WITH_BINARY
+	case syntaxBoolean:
+		return api.ADSTYPE_BOOLEAN
+	case syntaxInteger:
+		return api.ADSTYPE_INTEGER
+	case syntaxOctetString, syntaxSID:
+		return api.ADSTYPE_OCTET_STRING
+	case syntaxTime:
+		return api.ADSTYPE_UTC_TIME
+	case syntaxDNWithString:
+		return api.ADSTYPE_DN_WITH_STRING
+	case syntaxSecurityDescriptor:
+		return api.ADSTYPE_NT_SECURITY_DESCRIPTOR
+	case syntaxLargeInteger:
+		return api.ADSTYPE_LARGE_INTEGER
+	}
+	return api.ADSTYPE_PROV_SPECIFIC
+}
+
+// binary returns true if values of the syntax are compared byte by byte
+// rather than case-insensitively.
+func (s syntax) binary() bool {
+	switch s.oid {
+	case syntaxCaseExact, syntaxOctetString, syntaxSecurityDescriptor, syntaxSID, "":
+		return true
+	}
+	return false
+}
+
+// decode converts a value returned by the server to the Go type used by ADSI
+// for its syntax. Values of attributes whose syntax is not known are
+// returned as strings, or as byte slices if they are not valid UTF-8.
+func (s syntax) decode(b []byte) (interface{}, error) {
+	switch s.oid {
+	case syntaxBoolean:
+		return strings.EqualFold(string(b), "TRUE"), nil
+	case syntaxInteger:
+		n, err := strconv.ParseInt(string(b), 10, 32)
+		return int32(n), err
+	case syntaxLargeInteger:
+		return strconv.ParseInt(string(b), 10, 64)
+	case syntaxOctetString, syntaxSecurityDescriptor, syntaxSID:
+		return bytes.Clone(b), nil
+	case syntaxTime:
+		if s.oMSyntax == oMSyntaxUTCTime {
+			return time.Parse("060102150405Z0700", string(b))
+		}
+		return adsi.ParseGeneralizedTime(string(b))
+	case syntaxDNWithBinary:
+		count, value, dn, ok := splitDNWith(string(b), "B:")
+		if !ok || count != len(value) {
+			return nil, fmt.Errorf("ldapdir: invalid DN with binary value %q", b)
+		}
+		binary, err := hex.DecodeString(value)
+		return api.DNWithBinary{DN: dn, Binary: binary}, err
+	case syntaxDNWithString:
+		count, value, dn, ok := splitDNWith(string(b), "S:")
+		if !ok || count != utf8.RuneCountInString(value) {
+			return nil, fmt.Errorf("ldapdir: invalid DN with string value %q", b)
+		}
+		return api.DNWithString{DN: dn, String: value}, nil
+	case "":
+		if !utf8.Valid(b) {
+			return bytes.Clone(b), nil
+		}
+	}
+	return string(b), nil
+}
+
+// splitDNWith splits a value of the form prefix + "count:value:dn".
+func splitDNWith(s, prefix string) (count int, value, dn string, ok bool) {
+	s, ok = strings.CutPrefix(s, prefix)
+	if !ok {
+		return
+	}
+	parts := strings.SplitN(s, ":", 3)
+	if len(parts) != 3 {
+		return 0, "", "", false
+	}
+	count, err := strconv.Atoi(parts[0])
+	return count, parts[1], parts[2], err == nil
+}
+
+// encode converts a value passed to PutEx to its LDAP representation.
+func encode(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	case bool:
+		if v {
+			return []byte("TRUE"), nil
+		}
+		return []byte("FALSE"), nil
+	case int:
+		return strconv.AppendInt(nil, int64(v), 10), nil
+	case int32:
+		return strconv.AppendInt(nil, int64(v), 10), nil
+	case int64:
+		return strconv.AppendInt(nil, v, 10), nil
+	case uint32:
+		return strconv.AppendUint(nil, uint64(v), 10), nil
+	case time.Time:
+		return []byte(adsi.FormatGeneralizedTime(v)), nil
+	case api.DNWithBinary:
+		binary := strings.ToUpper(hex.EncodeToString(v.Binary))
+		return []byte(fmt.Sprintf("B:%d:%s:%s", len(binary), binary, v.DN)), nil
+	case api.DNWithString:
+		return []byte(fmt.Sprintf("S:%d:%s:%s", utf8.RuneCountInString(v.String), v.String, v.DN)), nil
+	}
+	return nil, unsupported(fmt.Sprintf("writing values of type %T", value))
+}
+
+// schema holds the syntaxes of the attributes defined by the schema, by
+// lower-case name. It is read by Directory.syntax when it is first needed.
+type schema struct {
+	loaded   bool
+	syntaxes map[string]syntax
+}
+
+// syntax returns the syntax of the named attribute. The schema is read the
+// first time it is called. If it can't be read, because the connection is
+// anonymous for instance, every attribute is treated as having an unknown
+// syntax.
+func (d *Directory) syntax(name string) syntax {
+	if i := strings.IndexByte(name, ';'); i >= 0 {
+		name = name[:i]
+	}
+	d.m.Lock()
+	defer d.m.Unlock()
+	if !d.schema.loaded {
+		d.schema.loaded = true
+		d.schema.syntaxes, _ = d.readSchema()
+	}
+	return d.schema.syntaxes[strings.ToLower(name)]
+}
+
+// readSchema reads the syntaxes of the attributes defined by the schema of
+// the forest.
+func (d *Directory) readSchema() (map[string]syntax, error) {
+	root, err := d.conn.Search(ldap.NewSearchRequest("", ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false,
+		"(objectClass=*)", []string{"schemaNamingContext"}, nil))
+	if err != nil {
+		return nil, err
+	}
+	if len(root.Entries) == 0 {
+		return nil, nil
+	}
+	base := root.Entries[0].GetAttributeValue("schemaNamingContext")
+	result, err := d.conn.SearchWithPaging(ldap.NewSearchRequest(base, ldap.ScopeSingleLevel, ldap.NeverDerefAliases, 0, 0, false,
+		"(objectClass=attributeSchema)", []string{"lDAPDisplayName", "attributeSyntax", "oMSyntax"}, nil), adsi.DefaultPageSize)
+	if err != nil {
+		return nil, err
+	}
+	syntaxes := make(map[string]syntax, len(result.Entries))
+	for _, entry := range result.Entries {
+		om, _ := strconv.Atoi(entry.GetAttributeValue("oMSyntax"))
+		syntaxes[strings.ToLower(entry.GetAttributeValue("lDAPDisplayName"))] = syntax{
+			oid:      entry.GetAttributeValue("attributeSyntax"),
+			oMSyntax: om,
+		}
+	}
+	return syntaxes, nil
+}
+
+// values converts the values of an attribute returned by the server.
+func (d *Directory) values(name string, raw [][]byte) (values []interface{}, err error) {
+	s := d.syntax(name)
+	values = make([]interface{}, len(raw))
+	for i, b := range raw {
+		if values[i], err = s.decode(b); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,262 @@
+package ldif
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/go-adsi/adsi"
+	"github.com/go-adsi/adsi/adspath"
+	"github.com/go-adsi/adsi/api"
+)
+
+// Attribute syntaxes whose LDIF values are converted before being written.
+const (
+	syntaxBoolean            = "2.5.5.8"
+	syntaxInteger            = "2.5.5.9"
+	syntaxOctetString        = "2.5.5.10"
+	syntaxSecurityDescriptor = "2.5.5.15"
+	syntaxLargeInteger       = "2.5.5.16"
+	syntaxSID                = "2.5.5.17"
+)
+
+// Applier applies LDIF change records to a directory through the write
+// methods of the adsi package, in the manner of ldifde.
+type Applier struct {
+	// Server is the domain controller to which the changes are applied. If
+	// empty a serverless binding is used.
+	Server string
+
+	// User and Password are the credentials used to bind. If empty the
+	// security context of the application is used.
+	User     string
+	Password string
+
+	client *adsi.Client
+	schema *adsi.Schema
+}
+
+// NewApplier returns an applier that writes to the given server using the
+// connection settings of c. The schema of the server's forest is read with
+// the applier's credentials when the first record is applied, so that values
+// can be converted to the syntax of their attributes, and is then used for
+// every record.
+func NewApplier(c *adsi.Client, server string) (*Applier, error) {
+	return &Applier{Server: server, client: c}, nil
+}
+
+// ApplyAll applies every remaining record read from r in order and returns
+// the number of records applied. It stops at the first record that fails.
+func (a *Applier) ApplyAll(r *Reader) (n int, err error) {
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		if err = a.Apply(rec); err != nil {
+			return n, fmt.Errorf("ldif: record at line %d (%s): %w", rec.Line, rec.DN, err)
+		}
+		n++
+	}
+}
+
+// Apply applies a single record. Add and modify records are validated
+// against the schema before they are written. Attributes of add records
+// that can't be written, such as the system-only attributes included by
+// Writer, are skipped so that exported entries can be imported again.
+//
+// Active Directory always removes the old relative distinguished name
+// value when an object is renamed, so the deleteoldrdn field of modrdn
+// records has no effect.
+func (a *Applier) Apply(rec *Record) error {
+	if err := a.loadSchema(); err != nil {
+		return err
+	}
+	switch rec.ChangeType {
+	case ChangeAdd:
+		return a.add(rec)
+	case ChangeModify:
+		return a.modify(rec)
+	case ChangeDelete:
+		return a.delete(rec)
+	case ChangeModRDN, ChangeModDN:
+		return a.rename(rec)
+	}
+	return fmt.Errorf("%w: unknown change type %q", ErrSyntax, rec.ChangeType)
+}
+
+func (a *Applier) add(rec *Record) error {
+	cs := a.changeset()
+	var class string
+	for _, attr := range rec.Attributes {
+		if strings.EqualFold(attr.Name, "objectClass") && len(attr.Values) > 0 {
+			// The most specific class is listed last
+			class = attr.Values[len(attr.Values)-1]
+		}
+	}
+	if class == "" {
+		return fmt.Errorf("%w: add record has no objectClass", ErrSyntax)
+	}
+	cs.Create(class, rec.DN)
+	rdn := strings.ToLower(strings.SplitN(rec.DN, "=", 2)[0])
+	for _, attr := range rec.Attributes {
+		switch strings.ToLower(attr.Name) {
+		case "objectclass", "distinguishedname", rdn:
+			// These are set by the server when the object is created
+			continue
+		}
+		if schema, ok := a.schema.Attribute(attr.Name); ok && (schema.SystemOnly || schema.Constructed || schema.IsBackLink()) {
+			continue
+		}
+		values, err := a.values(attr)
+		if err != nil {
+			return err
+		}
+		cs.Put(rec.DN, attr.Name, values...)
+	}
+	return a.apply(cs)
+}
+
+func (a *Applier) modify(rec *Record) error {
+	cs := a.changeset()
+	for _, mod := range rec.Modifications {
+		values, err := a.values(mod.Attribute)
+		if err != nil {
+			return err
+		}
+		var control uint32
+		switch {
+		case mod.Op == ModAdd:
+			control = api.ADS_PROPERTY_APPEND
+		case mod.Op == ModReplace && len(values) > 0:
+			control = api.ADS_PROPERTY_UPDATE
+		case mod.Op == ModDelete && len(values) > 0:
+			control = api.ADS_PROPERTY_DELETE
+		default:
+			control = api.ADS_PROPERTY_CLEAR
+		}
+		cs.PutEx(rec.DN, control, mod.Name, values...)
+	}
+	return a.apply(cs)
+}
+
+func (a *Applier) delete(rec *Record) error {
+	obj, err := a.client.OpenSC(a.path(rec.DN), a.User, a.Password, a.flags())
+	if err != nil {
+		return err
+	}
+	class, err := obj.Class()
+	obj.Close()
+	if err != nil {
+		return err
+	}
+	cs := a.changeset()
+	cs.Delete(class, rec.DN)
+	return a.apply(cs)
+}
+
+func (a *Applier) rename(rec *Record) error {
+	superior := rec.NewSuperior
+	if superior == "" {
+		if i := indexUnescaped(rec.DN, ','); i >= 0 {
+			superior = rec.DN[i+1:]
+		}
+	}
+	container, err := a.client.OpenContainerSC(a.path(superior), a.User, a.Password, a.flags())
+	if err != nil {
+		return err
+	}
+	defer container.Close()
+	obj, err := container.MoveHere(a.path(rec.DN), rec.NewRDN)
+	if err != nil {
+		return err
+	}
+	obj.Close()
+	return nil
+}
+
+// values converts the LDIF values of an attribute to the Go types expected
+// by PutEx for the attribute's syntax.
+func (a *Applier) values(attr Attribute) (values []interface{}, err error) {
+	syntax := ""
+	if schema, ok := a.schema.Attribute(attr.Name); ok {
+		syntax = schema.Syntax
+	}
+	for _, s := range attr.Values {
+		var value interface{} = s
+		switch syntax {
+		case syntaxBoolean:
+			value = strings.EqualFold(s, "TRUE")
+		case syntaxInteger:
+			var v int64
+			if v, err = strconv.ParseInt(s, 10, 32); err == nil {
+				value = int32(v)
+			}
+		case syntaxLargeInteger:
+			value, err = strconv.ParseInt(s, 10, 64)
+		case syntaxOctetString, syntaxSecurityDescriptor, syntaxSID:
+			value = []byte(s)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", attr.Name, err)
+		}
+		values = append(values, value)
+	}
+	return
+}
+
+// loadSchema reads the schema of the server's forest if it hasn't been read
+// yet.
+func (a *Applier) loadSchema() (err error) {
+	if a.schema != nil {
+		return nil
+	}
+	if a.User == "" {
+		a.schema, err = a.client.Schema(a.Server)
+	} else {
+		a.schema, err = a.client.SchemaSC(a.Server, a.User, a.Password)
+	}
+	return err
+}
+
+func (a *Applier) changeset() *adsi.Changeset {
+	return &adsi.Changeset{Server: a.Server, User: a.User, Password: a.Password, Schema: a.schema}
+}
+
+func (a *Applier) apply(cs *adsi.Changeset) error {
+	report, err := cs.Apply(a.client)
+	if err != nil {
+		return err
+	}
+	return report.Err()
+}
+
+func (a *Applier) path(dn string) string {
+	return (&adspath.Path{Scheme: "LDAP", Host: a.Server, Path: adspath.EscapeDN(dn)}).String()
+}
+
+func (a *Applier) flags() uint32 {
+	flags := uint32(api.ADS_SECURE_AUTHENTICATION | api.ADS_USE_SEALING)
+	if a.Server != "" {
+		flags |= api.ADS_SERVER_BIND
+	}
+	return flags
+}
+
+// indexUnescaped returns the index of the first occurrence of c in s that
+// is not escaped by a backslash, or -1.
+func indexUnescaped(s string, c byte) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case c:
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,72 @@
+// Package ldif reads and writes directory data in the LDAP Data Interchange
+// Format, allowing the results of adsi searches to be exchanged with other
+// directory tools such as ldifde.
+//
+// See https://www.rfc-editor.org/rfc/rfc2849
+package ldif
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-adsi/adsi"
+	"github.com/go-adsi/adsi/api"
+)
+
+// lineLength is the length at which long lines are folded.
+const lineLength = 76
+
+// FormatValue returns the LDAP string representation of a value as returned
+// by a search, together with a flag indicating whether it is binary data. It
+// returns an error for values of unsupported types.
+func FormatValue(value interface{}) (s string, binary bool, err error) {
+	switch v := value.(type) {
+	case string:
+		return v, false, nil
+	case []byte:
+		return string(v), true, nil
+	case bool:
+		if v {
+			return "TRUE", false, nil
+		}
+		return "FALSE", false, nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), false, nil
+	case int64:
+		return strconv.FormatInt(v, 10), false, nil
+	case int:
+		return strconv.Itoa(v), false, nil
+	case time.Time:
+		return adsi.FormatGeneralizedTime(v), false, nil
+	case api.DNWithBinary:
+		return fmt.Sprintf("B:%d:%s:%s", len(v.Binary)*2, strings.ToUpper(hex.EncodeToString(v.Binary)), v.DN), false, nil
+	case api.DNWithString:
+		return fmt.Sprintf("S:%d:%s:%s", utf8.RuneCountInString(v.String), v.String, v.DN), false, nil
+	}
+	return "", false, fmt.Errorf("ldif: unsupported value type %T", value)
+}
+
+// isSafe returns true if s may be written as an LDIF SAFE-STRING rather than
+// being base64 encoded.
+func isSafe(s string) bool {
+	if s == "" {
+		return true
+	}
+	switch s[0] {
+	case ' ', ':', '<':
+		return false
+	}
+	if s[len(s)-1] == ' ' {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c == 0 || c == '\n' || c == '\r' || c > 127 {
+			return false
+		}
+	}
+	return true
+}
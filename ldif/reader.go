@@ -0,0 +1,300 @@
+package ldif
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrSyntax is returned when the input is not valid LDIF.
+var ErrSyntax = errors.New("ldif: syntax error")
+
+// Change types of LDIF change records.
+const (
+	ChangeAdd    = "add"
+	ChangeDelete = "delete"
+	ChangeModify = "modify"
+	ChangeModRDN = "modrdn"
+	ChangeModDN  = "moddn"
+)
+
+// Modification operations of LDIF modify records.
+const (
+	ModAdd     = "add"
+	ModDelete  = "delete"
+	ModReplace = "replace"
+)
+
+// Attribute holds the values of an attribute in an LDIF record. Values that
+// were base64 encoded are decoded and may hold binary data.
+type Attribute struct {
+	Name   string
+	Values []string
+}
+
+// Modification is a single change to an attribute in a modify record. A
+// delete modification without values removes the attribute entirely.
+type Modification struct {
+	Op string
+	Attribute
+}
+
+// Record is a single LDIF record. Content records are read as add
+// records.
+type Record struct {
+	DN         string
+	ChangeType string
+
+	// Attributes holds the attributes of the entry for add records.
+	Attributes []Attribute
+
+	// Modifications holds the changes made by modify records.
+	Modifications []Modification
+
+	// NewRDN, DeleteOldRDN and NewSuperior describe the rename made by
+	// modrdn and moddn records. NewSuperior is empty if the entry stays in
+	// its current container.
+	NewRDN       string
+	DeleteOldRDN bool
+	NewSuperior  string
+
+	// Line is the line number at which the record starts.
+	Line int
+}
+
+// Reader reads LDIF records. Both content records and change records are
+// supported. Values referenced by URL are not.
+type Reader struct {
+	s       *bufio.Scanner
+	line    int
+	peeked  bool
+	started bool
+}
+
+// NewReader returns a reader that reads LDIF from r.
+func NewReader(r io.Reader) *Reader {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<24)
+	return &Reader{s: s}
+}
+
+// physical returns the next physical line with any trailing carriage
+// return removed. If unread was called the current line is returned again.
+func (r *Reader) physical() (line string, ok bool) {
+	if r.peeked {
+		r.peeked = false
+	} else if !r.s.Scan() {
+		return "", false
+	} else {
+		r.line++
+	}
+	return strings.TrimSuffix(r.s.Text(), "\r"), true
+}
+
+// unread causes the next call to physical to return the current line again.
+func (r *Reader) unread() {
+	r.peeked = true
+}
+
+// logical returns the next logical line and the number of the physical
+// line it starts on, with folded lines joined and comments removed. An
+// empty line marks the end of a record.
+func (r *Reader) logical() (line string, number int, ok bool) {
+	for {
+		if line, ok = r.physical(); !ok {
+			return
+		}
+		number = r.line
+		for {
+			next, more := r.physical()
+			if !more {
+				break
+			}
+			if !strings.HasPrefix(next, " ") {
+				r.unread()
+				break
+			}
+			line += next[1:]
+		}
+		if !strings.HasPrefix(line, "#") {
+			return line, number, true
+		}
+	}
+}
+
+// Next reads the next record. It returns io.EOF once the input has been
+// consumed.
+func (r *Reader) Next() (rec *Record, err error) {
+	var lines []string
+	start := 0
+	for {
+		line, number, ok := r.logical()
+		if !ok {
+			break
+		}
+		if line == "" {
+			if len(lines) > 0 {
+				break
+			}
+			continue
+		}
+		if len(lines) == 0 {
+			start = number
+		}
+		lines = append(lines, line)
+		if !r.started {
+			r.started = true
+			if strings.HasPrefix(strings.ToLower(line), "version:") {
+				lines = lines[:0]
+			}
+		}
+	}
+	if err = r.s.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, io.EOF
+	}
+	return parseRecord(lines, start)
+}
+
+// All reads all of the remaining records.
+func (r *Reader) All() (recs []*Record, err error) {
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return recs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		recs = append(recs, rec)
+	}
+}
+
+// parseRecord parses the logical lines of a single record.
+func parseRecord(lines []string, start int) (*Record, error) {
+	rec := &Record{Line: start}
+	fail := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: record at line %d: %s", ErrSyntax, start, fmt.Sprintf(format, args...))
+	}
+
+	pairs := make([][2]string, len(lines))
+	for i, line := range lines {
+		name, value, err := parseLine(line)
+		if err != nil {
+			return nil, fail("%v", err)
+		}
+		pairs[i] = [2]string{name, value}
+	}
+	if !strings.EqualFold(pairs[0][0], "dn") {
+		return nil, fail("record does not start with dn")
+	}
+	rec.DN = pairs[0][1]
+	pairs = pairs[1:]
+
+	// Controls can't be passed through ADSI, so they are ignored
+	for len(pairs) > 0 && strings.EqualFold(pairs[0][0], "control") {
+		pairs = pairs[1:]
+	}
+	rec.ChangeType = ChangeAdd
+	content := true
+	if len(pairs) > 0 && strings.EqualFold(pairs[0][0], "changetype") {
+		rec.ChangeType = strings.ToLower(pairs[0][1])
+		pairs = pairs[1:]
+		content = false
+	}
+
+	switch rec.ChangeType {
+	case ChangeAdd:
+		if content && len(pairs) == 0 {
+			return nil, fail("entry has no attributes")
+		}
+		for _, pair := range pairs {
+			rec.Attributes = appendValue(rec.Attributes, pair[0], pair[1])
+		}
+	case ChangeDelete:
+		if len(pairs) > 0 {
+			return nil, fail("unexpected %s in delete record", pairs[0][0])
+		}
+	case ChangeModify:
+		for len(pairs) > 0 {
+			op := strings.ToLower(pairs[0][0])
+			if op != ModAdd && op != ModDelete && op != ModReplace {
+				return nil, fail("unknown modify operation %q", pairs[0][0])
+			}
+			mod := Modification{Op: op, Attribute: Attribute{Name: pairs[0][1]}}
+			pairs = pairs[1:]
+			for len(pairs) > 0 && pairs[0][0] != "-" {
+				if !strings.EqualFold(pairs[0][0], mod.Name) {
+					return nil, fail("value for %s in modification of %s", pairs[0][0], mod.Name)
+				}
+				mod.Values = append(mod.Values, pairs[0][1])
+				pairs = pairs[1:]
+			}
+			if len(pairs) == 0 {
+				return nil, fail("modification of %s is not terminated by -", mod.Name)
+			}
+			pairs = pairs[1:]
+			rec.Modifications = append(rec.Modifications, mod)
+		}
+	case ChangeModRDN, ChangeModDN:
+		for _, pair := range pairs {
+			switch strings.ToLower(pair[0]) {
+			case "newrdn":
+				rec.NewRDN = pair[1]
+			case "deleteoldrdn":
+				rec.DeleteOldRDN = pair[1] == "1"
+			case "newsuperior":
+				rec.NewSuperior = pair[1]
+			default:
+				return nil, fail("unexpected %s in %s record", pair[0], rec.ChangeType)
+			}
+		}
+		if rec.NewRDN == "" {
+			return nil, fail("%s record has no newrdn", rec.ChangeType)
+		}
+	default:
+		return nil, fail("unknown change type %q", rec.ChangeType)
+	}
+	return rec, nil
+}
+
+// parseLine splits a logical line into its attribute description and
+// decoded value. The separator line of modify records is returned as "-".
+func parseLine(line string) (name, value string, err error) {
+	if line == "-" {
+		return "-", "", nil
+	}
+	i := strings.IndexByte(line, ':')
+	if i <= 0 {
+		return "", "", fmt.Errorf("missing separator in %q", line)
+	}
+	name, rest := line[:i], line[i+1:]
+	switch {
+	case strings.HasPrefix(rest, ":"):
+		b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(rest[1:]))
+		if err != nil {
+			return "", "", fmt.Errorf("invalid base64 value for %s", name)
+		}
+		return name, string(b), nil
+	case strings.HasPrefix(rest, "<"):
+		return "", "", fmt.Errorf("URL value for %s is not supported", name)
+	}
+	return name, strings.TrimLeft(rest, " "), nil
+}
+
+// appendValue adds a value to the named attribute, preserving the order in
+// which attributes first appear.
+func appendValue(attrs []Attribute, name, value string) []Attribute {
+	for i := range attrs {
+		if strings.EqualFold(attrs[i].Name, name) {
+			attrs[i].Values = append(attrs[i].Values, value)
+			return attrs
+		}
+	}
+	return append(attrs, Attribute{Name: name, Values: []string{value}})
+}
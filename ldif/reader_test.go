@@ -0,0 +1,190 @@
+package ldif
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReader(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []*Record
+	}{
+		{
+			name: "content record",
+			in: "version: 1\n" +
+				"dn: CN=Jane Doe,OU=Staff,DC=example,DC=com\n" +
+				"objectClass: top\n" +
+				"objectClass: user\n" +
+				"sAMAccountName: jdoe\n",
+			want: []*Record{{
+				DN:         "CN=Jane Doe,OU=Staff,DC=example,DC=com",
+				ChangeType: ChangeAdd,
+				Attributes: []Attribute{
+					{Name: "objectClass", Values: []string{"top", "user"}},
+					{Name: "sAMAccountName", Values: []string{"jdoe"}},
+				},
+				Line: 2,
+			}},
+		},
+		{
+			name: "folded lines, comments and CRLF",
+			in: "# exported by ldifde\r\n" +
+				"dn: CN=Jane Doe,OU=St\r\n" +
+				" aff,DC=example,DC=com\r\n" +
+				"# a comment\r\n" +
+				"#  folded\r\n" +
+				"description: first\r\n" +
+				"  second\r\n",
+			want: []*Record{{
+				DN:         "CN=Jane Doe,OU=Staff,DC=example,DC=com",
+				ChangeType: ChangeAdd,
+				Attributes: []Attribute{{Name: "description", Values: []string{"first second"}}},
+				Line:       2,
+			}},
+		},
+		{
+			name: "base64 and empty values",
+			in: "dn:: Q049WsOrLERDPWV4YW1wbGUsREM9Y29t\n" +
+				"objectGUID:: AAEC/w==\n" +
+				"description:\n",
+			want: []*Record{{
+				DN:         "CN=Zë,DC=example,DC=com",
+				ChangeType: ChangeAdd,
+				Attributes: []Attribute{
+					{Name: "objectGUID", Values: []string{"\x00\x01\x02\xff"}},
+					{Name: "description", Values: []string{""}},
+				},
+				Line: 1,
+			}},
+		},
+		{
+			name: "change records",
+			in: "version: 1\n" +
+				"\n" +
+				"dn: CN=a,DC=example,DC=com\n" +
+				"control: 1.2.840.113556.1.4.417 true\n" +
+				"changetype: delete\n" +
+				"\n" +
+				"\n" +
+				"dn: CN=b,DC=example,DC=com\n" +
+				"changetype: Modify\n" +
+				"add: member\n" +
+				"member: CN=a,DC=example,DC=com\n" +
+				"member: CN=c,DC=example,DC=com\n" +
+				"-\n" +
+				"delete: description\n" +
+				"-\n" +
+				"replace: info\n" +
+				"info: text\n" +
+				"-\n" +
+				"\n" +
+				"dn: CN=c,DC=example,DC=com\n" +
+				"changetype: moddn\n" +
+				"newrdn: CN=d\n" +
+				"deleteoldrdn: 1\n" +
+				"newsuperior: OU=Staff,DC=example,DC=com\n" +
+				"\n" +
+				"dn: CN=e,DC=example,DC=com\n" +
+				"changetype: add\n",
+			want: []*Record{
+				{DN: "CN=a,DC=example,DC=com", ChangeType: ChangeDelete, Line: 3},
+				{
+					DN:         "CN=b,DC=example,DC=com",
+					ChangeType: ChangeModify,
+					Modifications: []Modification{
+						{Op: ModAdd, Attribute: Attribute{Name: "member", Values: []string{"CN=a,DC=example,DC=com", "CN=c,DC=example,DC=com"}}},
+						{Op: ModDelete, Attribute: Attribute{Name: "description"}},
+						{Op: ModReplace, Attribute: Attribute{Name: "info", Values: []string{"text"}}},
+					},
+					Line: 8,
+				},
+				{
+					DN:           "CN=c,DC=example,DC=com",
+					ChangeType:   ChangeModDN,
+					NewRDN:       "CN=d",
+					DeleteOldRDN: true,
+					NewSuperior:  "OU=Staff,DC=example,DC=com",
+					Line:         20,
+				},
+				{DN: "CN=e,DC=example,DC=com", ChangeType: ChangeAdd, Line: 26},
+			},
+		},
+		{
+			name: "empty input",
+			in:   "\n\n# nothing\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewReader(strings.NewReader(tt.in)).All()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("records differ")
+				for _, r := range got {
+					t.Logf("got  %+v", *r)
+				}
+				for _, r := range tt.want {
+					t.Logf("want %+v", *r)
+				}
+			}
+		})
+	}
+}
+
+func TestReaderSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"no dn", "cn: a\n"},
+		{"no separator", "dn: CN=a\nobjectClass\n"},
+		{"no attributes", "dn: CN=a\n"},
+		{"invalid base64", "dn: CN=a\ncn:: ***\n"},
+		{"url value", "dn: CN=a\njpegPhoto:< file:///tmp/photo.jpg\n"},
+		{"unknown change type", "dn: CN=a\nchangetype: rename\n"},
+		{"values in delete", "dn: CN=a\nchangetype: delete\ncn: a\n"},
+		{"unknown modify operation", "dn: CN=a\nchangetype: modify\nincrement: uSNChanged\n-\n"},
+		{"unterminated modification", "dn: CN=a\nchangetype: modify\nreplace: info\ninfo: text\n"},
+		{"value for another attribute", "dn: CN=a\nchangetype: modify\nreplace: info\ndescription: text\n-\n"},
+		{"modrdn without newrdn", "dn: CN=a\nchangetype: modrdn\ndeleteoldrdn: 1\n"},
+		{"unexpected field in moddn", "dn: CN=a\nchangetype: moddn\nnewrdn: CN=b\ncn: b\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, err := NewReader(strings.NewReader(tt.in)).Next()
+			if !errors.Is(err, ErrSyntax) {
+				t.Errorf("Next() = %+v, %v, want ErrSyntax", rec, err)
+			}
+		})
+	}
+}
+
+func TestReaderErrorLine(t *testing.T) {
+	r := NewReader(strings.NewReader("dn: CN=a\ncn: a\n\n# second\ndn: CN=b\nbad\n"))
+	if _, err := r.Next(); err != nil {
+		t.Fatal(err)
+	}
+	_, err := r.Next()
+	if err == nil || !strings.Contains(err.Error(), "line 5") {
+		t.Errorf("Next() returned %v, want an error at line 5", err)
+	}
+}
+
+func TestReaderEOF(t *testing.T) {
+	r := NewReader(strings.NewReader("dn: CN=a\nchangetype: delete\n"))
+	if _, err := r.Next(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := r.Next(); err != io.EOF {
+			t.Errorf("Next() returned %v, want io.EOF", err)
+		}
+	}
+}
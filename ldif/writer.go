@@ -0,0 +1,127 @@
+package ldif
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/go-adsi/adsi"
+	"github.com/go-adsi/adsi/adspath"
+)
+
+// Writer writes LDIF content records. The version line is written before
+// the first record. Output is buffered, so Flush must be called once all
+// records have been written.
+type Writer struct {
+	w       *bufio.Writer
+	started bool
+}
+
+// NewWriter returns a writer that writes LDIF to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+// WriteEntry writes a content record for the entry with the given
+// distinguished name and attributes. Binary values, and strings that can't
+// be represented safely in LDIF, are base64 encoded.
+func (w *Writer) WriteEntry(dn string, columns []adsi.Column) error {
+	if w.started {
+		w.w.WriteByte('\n')
+	} else {
+		w.w.WriteString("version: 1\n")
+		w.started = true
+	}
+	w.writeLine("dn", dn, false)
+	for _, column := range columns {
+		for _, value := range column.Values {
+			s, binary, err := FormatValue(value)
+			if err != nil {
+				return err
+			}
+			w.writeLine(column.Name, s, binary)
+		}
+	}
+	return nil
+}
+
+// WriteRow writes a content record for a row of search results. The
+// distinguished name is taken from the distinguishedName column if present,
+// or from the ADsPath otherwise. Neither column is written as an attribute.
+func (w *Writer) WriteRow(row *adsi.Row) error {
+	dn := row.AttrString("distinguishedName")
+	if dn == "" {
+		if p, err := adspath.Parse(row.Path()); err == nil {
+			dn = strings.ReplaceAll(p.Path, `\/`, "/")
+		}
+	}
+	if dn == "" {
+		return errors.New("ldif: row has no distinguished name")
+	}
+	var columns []adsi.Column
+	for _, column := range row.Columns() {
+		if strings.EqualFold(column.Name, "ADsPath") || strings.EqualFold(column.Name, "distinguishedName") {
+			continue
+		}
+		columns = append(columns, column)
+	}
+	return w.WriteEntry(dn, columns)
+}
+
+// WriteResult writes a content record for each of the remaining rows in
+// the result set and returns the number of records written.
+func (w *Writer) WriteResult(result *adsi.SearchResult) (n int, err error) {
+	for {
+		row, err := result.Next()
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		if err = w.WriteRow(row); err != nil {
+			return n, err
+		}
+		n++
+	}
+}
+
+// WriteObject writes a content record for the given attributes of the
+// object. If no attributes are given every attribute the caller is
+// permitted to read is written.
+func (w *Writer) WriteObject(obj *adsi.Object, attrs ...string) error {
+	if len(attrs) > 0 {
+		attrs = append(attrs[:len(attrs):len(attrs)], "distinguishedName")
+	}
+	row, err := obj.Row(attrs...)
+	if err != nil {
+		return err
+	}
+	return w.WriteRow(row)
+}
+
+// writeLine writes a single attribute value, folding it if necessary.
+func (w *Writer) writeLine(attr, value string, binary bool) {
+	var line string
+	if binary || !isSafe(value) {
+		line = attr + ":: " + base64.StdEncoding.EncodeToString([]byte(value))
+	} else {
+		line = attr + ": " + value
+	}
+	// Continuation lines start with a space, which counts towards their
+	// length
+	for n := lineLength; len(line) > n; n = lineLength - 1 {
+		w.w.WriteString(line[:n])
+		w.w.WriteString("\n ")
+		line = line[n:]
+	}
+	w.w.WriteString(line)
+	w.w.WriteByte('\n')
+}
@@ -0,0 +1,128 @@
+package adsi
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// Leak describes an object that holds a COM interface.
+type Leak struct {
+	// Type is the Go type of the object, such as "*adsi.Object".
+	Type string
+
+	// Created is the time at which the object was created, and Stack is the
+	// stack trace of the goroutine that created it.
+	Created time.Time
+	Stack   string
+}
+
+// String returns a description of the leak including its stack trace.
+func (l Leak) String() string {
+	return fmt.Sprintf("%s created at %s and never closed:\n%s", l.Type, l.Created.Format(time.RFC3339), l.Stack)
+}
+
+// leakDetection is set while leak detection is enabled.
+var leakDetection atomic.Bool
+
+// leaks holds the state of leak detection.
+var leaks struct {
+	sync.Mutex
+	open    map[uintptr]*Leak
+	leaked  []Leak
+	handler func(Leak)
+}
+
+// SetLeakDetection enables or disables leak detection. While it is enabled
+// the stack trace of every object that holds a COM interface is recorded
+// when the object is created, and objects that are garbage collected without
+// being closed are reported by CheckLeaks and passed to the handler set with
+// SetLeakHandler.
+//
+// Leak detection is intended for debugging. It slows down the creation of
+// objects considerably and only applies to objects created while it is
+// enabled.
+func SetLeakDetection(enabled bool) {
+	leakDetection.Store(enabled)
+}
+
+// SetLeakHandler sets a function that is called from a finalizer each time
+// an object is garbage collected without being closed, such as one that logs
+// the leak. It must not block.
+func SetLeakHandler(fn func(Leak)) {
+	leaks.Lock()
+	defer leaks.Unlock()
+	leaks.handler = fn
+}
+
+// CheckLeaks runs a garbage collection and returns the objects that have been
+// found to be garbage collected without being closed since the previous call.
+//
+// Finalizers run asynchronously, so an object that has just become
+// unreachable may only be reported by a later call.
+func CheckLeaks() []Leak {
+	runtime.GC()
+	leaks.Lock()
+	defer leaks.Unlock()
+	found := leaks.leaked
+	leaks.leaked = nil
+	return found
+}
+
+// OpenObjects returns the objects created while leak detection was enabled
+// that have not yet been closed, whether or not they are still reachable.
+// It is useful to find the objects that hold COM references when an
+// application shuts down.
+func OpenObjects() []Leak {
+	leaks.Lock()
+	defer leaks.Unlock()
+	open := make([]Leak, 0, len(leaks.open))
+	for _, l := range leaks.open {
+		open = append(open, *l)
+	}
+	return open
+}
+
+// track records the creation of an object that holds a COM interface, if
+// leak detection is enabled, and returns the object.
+func track[T any](p *T) *T {
+	if !leakDetection.Load() {
+		return p
+	}
+	l := &Leak{Type: fmt.Sprintf("%T", p), Created: time.Now(), Stack: string(debug.Stack())}
+	key := uintptr(unsafe.Pointer(p))
+	leaks.Lock()
+	if leaks.open == nil {
+		leaks.open = make(map[uintptr]*Leak)
+	}
+	leaks.open[key] = l
+	leaks.Unlock()
+	runtime.SetFinalizer(p, func(p *T) { leaked(uintptr(unsafe.Pointer(p))) })
+	return p
+}
+
+// untrack records that an object has been closed.
+func untrack[T any](p *T) {
+	leaks.Lock()
+	defer leaks.Unlock()
+	delete(leaks.open, uintptr(unsafe.Pointer(p)))
+}
+
+// leaked is called when a tracked object is garbage collected.
+func leaked(key uintptr) {
+	leaks.Lock()
+	l, ok := leaks.open[key]
+	if ok {
+		delete(leaks.open, key)
+		leaks.leaked = append(leaks.leaked, *l)
+	}
+	handler := leaks.handler
+	leaks.Unlock()
+	if ok && handler != nil {
+		handler(*l)
+	}
+}
@@ -0,0 +1,168 @@
+package adsi
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-adsi/adsi/api"
+)
+
+// Linked attributes come in pairs. The forward link, such as member, is
+// written by clients and the back link, such as memberOf, is computed by the
+// directory from the forward links that refer to an object. Writes to a back
+// link are rejected by the server with an unhelpful error that doesn't name
+// the forward link. The checks in this file catch them before they are sent.
+
+var (
+	// ErrBackLink is returned when a write is made to a back link attribute,
+	// such as memberOf, by an object opened through a client that has a link
+	// schema set with SetLinkSchema.
+	ErrBackLink = errors.New("attribute is a back link maintained by the directory")
+
+	// ErrNotLinked is returned by AddLinks and RemoveLinks when the
+	// attribute is not a linked attribute.
+	ErrNotLinked = errors.New("attribute is not a linked attribute")
+
+	// ErrNoLinkSchema is returned by AddLinks and RemoveLinks when the
+	// object was not opened through a client that has a link schema set with
+	// SetLinkSchema.
+	ErrNoLinkSchema = errors.New("no link schema has been set")
+)
+
+// SetLinkSchema sets the schema that identifies the linked attributes for the
+// objects opened by the client, including those derived from them with
+// methods such as ToUser, Create and Children. Those objects reject writes to
+// back links with an error matching ErrBackLink that names the forward link
+// to write instead, and route the edits of AddLinks and RemoveLinks to the
+// right objects. It applies only to objects opened after it is called. A nil
+// schema disables the checks, which is the default.
+//
+// The schema is usually the one cached by the client:
+//
+//	s, err := c.Schema("")
+//	if err != nil {
+//		return err
+//	}
+//	c.SetLinkSchema(s)
+//
+// Calling RefreshSchema does not change the schema set here.
+func (c *Client) SetLinkSchema(s *Schema) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	h := c.h.clone()
+	h.schema = s
+	c.h = h
+}
+
+// checkWrite returns an error if the attribute with the given name is a back
+// link according to the link schema of the object. The caller must hold the
+// object's lock.
+func (o *object) checkWrite(op, name string) error {
+	if o.h == nil || o.h.schema == nil {
+		return nil
+	}
+	attr, ok := o.h.schema.Attribute(name)
+	if !ok || !attr.IsBackLink() {
+		return nil
+	}
+	var path string
+	o.h.run(func() { path, _ = o.iface.AdsPath() })
+	err := ErrBackLink
+	if forward, ok := o.h.schema.ForwardLink(name); ok {
+		err = fmt.Errorf("%w: write %s on the linked objects instead", ErrBackLink, forward.Name)
+	}
+	return &Error{Op: op + " " + name, Path: path, Err: err}
+}
+
+// AddLinks adds the objects with the given distinguished names to the
+// linked attribute of the object with the given name. It requires a link
+// schema to have been set with SetLinkSchema.
+//
+// If the attribute is a forward link, such as member, the values are added
+// to the attribute cache of the object and must be commited with SetInfo to
+// be made persistent. If it is a back link, such as memberOf, the object is
+// instead added to the forward link of each of the given objects, which are
+// opened and commited one at a time. An error stops the edit, leaving the
+// objects before it changed.
+func (o *object) AddLinks(name string, dns ...string) error {
+	return o.editLinks("AddLinks", api.ADS_PROPERTY_APPEND, name, dns)
+}
+
+// RemoveLinks removes the objects with the given distinguished names from
+// the linked attribute of the object with the given name. It requires a link
+// schema to have been set with SetLinkSchema, and routes the edit as
+// described by AddLinks.
+func (o *object) RemoveLinks(name string, dns ...string) error {
+	return o.editLinks("RemoveLinks", api.ADS_PROPERTY_DELETE, name, dns)
+}
+
+// editLinks applies the given control code to the linked attribute of the
+// object with the given name, or to the forward link of each of the given
+// objects if the attribute is a back link.
+func (o *object) editLinks(op string, controlCode uint32, name string, dns []string) error {
+	forward, self, err := o.linkTarget(op, name)
+	if err != nil {
+		return err
+	}
+	if forward == nil {
+		return o.PutEx(controlCode, name, stringsToValues(dns)...)
+	}
+	for _, dn := range dns {
+		path, err := o.pathForDN(dn)
+		if err != nil {
+			return err
+		}
+		if err := o.putLink(path, controlCode, forward.Name, self); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// linkTarget returns the forward link that edits of the attribute with the
+// given name must be written to, along with the distinguished name of the
+// object, or a nil forward link if the attribute is itself a forward link.
+func (o *object) linkTarget(op, name string) (forward *AttributeSchema, self string, err error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return nil, "", ErrClosed
+	}
+	var path string
+	o.h.run(func() { path, _ = o.iface.AdsPath() })
+	fail := func(err error) (*AttributeSchema, string, error) {
+		return nil, "", &Error{Op: op + " " + name, Path: path, Err: err}
+	}
+	if o.h == nil || o.h.schema == nil {
+		return fail(ErrNoLinkSchema)
+	}
+	attr, ok := o.h.schema.Attribute(name)
+	if !ok || !attr.IsLinked() {
+		return fail(ErrNotLinked)
+	}
+	if !attr.IsBackLink() {
+		return nil, "", nil
+	}
+	if forward, ok = o.h.schema.ForwardLink(name); !ok {
+		return fail(ErrNotLinked)
+	}
+	if self, err = o.AttrString("distinguishedName"); err != nil {
+		return nil, "", err
+	}
+	return forward, self, nil
+}
+
+// putLink applies the given control code to the forward link of the object
+// with the given path for the given value, and commits the change. The object
+// is opened in the same way as OpenDN opens objects.
+func (o *object) putLink(path string, controlCode uint32, name, value string) error {
+	obj, err := o.h.open(path)
+	if err != nil {
+		return err
+	}
+	defer obj.Close()
+	if err := obj.PutEx(controlCode, name, value); err != nil {
+		return err
+	}
+	return obj.SetInfo()
+}
@@ -0,0 +1,176 @@
+package adsi
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// lockoutAttrs are the attributes read by LockoutInfo.
+var lockoutAttrs = []string{
+	"lockoutTime", "badPwdCount", "badPasswordTime",
+	"msDS-User-Account-Control-Computed",
+}
+
+// LockoutInfo describes the account lockout state of a user as seen by a
+// particular domain controller.
+//
+// The badPwdCount and badPasswordTime attributes are not replicated, so each
+// domain controller holds its own values. The lockoutTime attribute is
+// replicated, but may not yet have reached every domain controller.
+type LockoutInfo struct {
+	// Server is the domain controller that was queried, or empty if the
+	// user's own binding was used.
+	Server string
+
+	// Locked is true if the domain controller considers the account to be
+	// locked out. It accounts for lockouts that have expired.
+	Locked bool
+
+	// LockoutTime is the time the account was locked out, or the zero time
+	// if it has not been locked out.
+	LockoutTime time.Time
+
+	// BadPasswordCount is the number of failed logon attempts recorded by
+	// the domain controller.
+	BadPasswordCount int
+
+	// BadPasswordTime is the time of the last failed logon attempt recorded
+	// by the domain controller.
+	BadPasswordTime time.Time
+}
+
+// LockoutInfo retrieves the account lockout state of the user from the
+// server the user is bound to.
+func (u *User) LockoutInfo() (info *LockoutInfo, err error) {
+	return u.LockoutInfoOn("")
+}
+
+// LockoutInfoOn retrieves the account lockout state of the user from the
+// given domain controller. If server is empty the server the user is bound
+// to is queried.
+func (u *User) LockoutInfoOn(server string) (info *LockoutInfo, err error) {
+	dn, err := u.DN()
+	if err != nil {
+		return
+	}
+	path, err := u.pathForDNOn(server, dn)
+	if err != nil {
+		return
+	}
+	return u.lockoutInfoAt(server, path)
+}
+
+// lockoutInfoAt reads the account lockout state of the user at the given
+// path, which refers to the given server.
+func (u *User) lockoutInfoAt(server, path string) (info *LockoutInfo, err error) {
+	result, err := u.h.search(path, Query{Attributes: lockoutAttrs, Scope: ScopeBase})
+	if err != nil {
+		return
+	}
+	defer result.Close()
+	rows, err := result.All()
+	if err != nil {
+		return
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("unable to read lockout state of \"%s\"", path)
+	}
+	row := rows[0]
+	return &LockoutInfo{
+		Server:           server,
+		Locked:           AccountControl(uint32(row.AttrInt64("msDS-User-Account-Control-Computed"))).Has(AccountControlLockout),
+		LockoutTime:      row.AttrTime("lockoutTime"),
+		BadPasswordCount: row.AttrInt("badPwdCount"),
+		BadPasswordTime:  row.AttrTime("badPasswordTime"),
+	}, nil
+}
+
+// Unlock clears the lockoutTime attribute of the user, which unlocks the
+// account if it is locked out. The value must be commited with SetInfo to be
+// made persistent.
+func (u *User) Unlock() error {
+	return u.PutInt("lockoutTime", 0)
+}
+
+// LockoutReport holds the account lockout state of a user as seen by each
+// domain controller of its domain.
+type LockoutReport struct {
+	// Servers holds the lockout state reported by each domain controller
+	// that was successfully queried, ordered by the time of the last failed
+	// logon attempt it recorded, most recent first.
+	Servers []*LockoutInfo
+
+	// Errors maps each domain controller that could not be queried to the
+	// error that was encountered.
+	Errors map[string]error
+}
+
+// Locked returns true if any domain controller considers the account to be
+// locked out.
+func (r *LockoutReport) Locked() bool {
+	for _, info := range r.Servers {
+		if info.Locked {
+			return true
+		}
+	}
+	return false
+}
+
+// BadPasswordServers returns the lockout state of the domain controllers
+// that have recorded at least one failed logon attempt since the count was
+// last reset, most recent first. Failed attempts are forwarded to the PDC
+// emulator, so the other servers listed are the ones that authenticated the
+// failing client, which helps to locate the source of a lockout.
+func (r *LockoutReport) BadPasswordServers() (infos []*LockoutInfo) {
+	for _, info := range r.Servers {
+		if info.BadPasswordCount > 0 {
+			infos = append(infos, info)
+		}
+	}
+	return
+}
+
+// LockoutReport queries every domain controller of the user's domain for
+// the user's badPwdCount, badPasswordTime and lockoutTime attributes.
+//
+// Domain controllers that cannot be reached are recorded in the Errors field
+// of the report rather than causing the whole operation to fail. An error is
+// returned only if the domain controllers cannot be enumerated or none of
+// them could be queried.
+func (u *User) LockoutReport() (report *LockoutReport, err error) {
+	dn, err := u.DN()
+	if err != nil {
+		return
+	}
+
+	report = new(LockoutReport)
+	var m sync.Mutex
+	servers, errs, err := u.fanOut(dn, func(server, path string) error {
+		info, err := u.lockoutInfoAt(server, path)
+		if err != nil {
+			return err
+		}
+		m.Lock()
+		defer m.Unlock()
+		report.Servers = append(report.Servers, info)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.Errors = errs
+	sort.Slice(report.Servers, func(i, j int) bool {
+		a, b := report.Servers[i], report.Servers[j]
+		if !a.BadPasswordTime.Equal(b.BadPasswordTime) {
+			return a.BadPasswordTime.After(b.BadPasswordTime)
+		}
+		return a.Server < b.Server
+	})
+
+	if len(report.Servers) == 0 {
+		return report, fmt.Errorf("unable to query lockout state on any of %d domain controllers", len(servers))
+	}
+	return report, nil
+}
@@ -0,0 +1,138 @@
+package adsi
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"unsafe"
+
+	"github.com/go-adsi/adsi/api"
+	"github.com/go-adsi/adsi/comiid"
+	"github.com/scjalliance/comutil"
+)
+
+// LogLevels holds the levels at which each kind of operation is logged.
+type LogLevels struct {
+	// Bind is the level of messages reporting that an object was opened.
+	Bind slog.Level
+
+	// Search is the level of messages reporting a completed search.
+	Search slog.Level
+
+	// Write is the level of messages reporting a write operation, as
+	// described by AuditEvent.
+	Write slog.Level
+
+	// Error is the level of messages reporting an operation of any kind
+	// that failed.
+	Error slog.Level
+}
+
+// DefaultLogLevels are the levels used by a client unless they are changed
+// with SetLogLevels.
+var DefaultLogLevels = LogLevels{
+	Bind:   slog.LevelDebug,
+	Search: slog.LevelDebug,
+	Write:  slog.LevelInfo,
+	Error:  slog.LevelError,
+}
+
+// SetLogger sets the logger that receives messages about the binds,
+// searches and write operations made through the client and the objects,
+// containers and searchers opened by it. It applies only to objects opened
+// after it is called. Passwords and attribute values are never logged. A nil
+// logger disables logging, which is the default.
+func (c *Client) SetLogger(logger *slog.Logger) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	h := c.h.clone()
+	h.logger = logger
+	c.h = h
+}
+
+// SetLogLevels sets the levels at which operations are logged. Like
+// SetLogger it applies only to objects opened after it is called.
+func (c *Client) SetLogLevels(levels LogLevels) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	h := c.h.clone()
+	h.levels = levels
+	c.h = h
+}
+
+// logging returns true if a logger is set.
+func (h *hooks) logging() bool {
+	return h != nil && h.logger != nil
+}
+
+// log writes a message at the given level, or at the error level if err is
+// not nil.
+func (h *hooks) log(level slog.Level, err error, msg string, attrs ...slog.Attr) {
+	if !h.logging() {
+		return
+	}
+	if err != nil {
+		level = h.levels.Error
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	h.logger.LogAttrs(context.Background(), level, msg, attrs...)
+}
+
+// logBind logs an attempt to open the object with the given path.
+func (h *hooks) logBind(path, user string, flags uint32, start time.Time, err error) {
+	if !h.logging() {
+		return
+	}
+	attrs := []slog.Attr{slog.String("path", path)}
+	if user != "" {
+		attrs = append(attrs, slog.String("user", user))
+	}
+	attrs = append(attrs, slog.Any("flags", flags), slog.Duration("duration", time.Since(start)))
+	h.log(h.levels.Bind, err, "adsi bind", attrs...)
+}
+
+// logWrite logs a write operation.
+func (h *hooks) logWrite(e AuditEvent) {
+	if !h.logging() {
+		return
+	}
+	attrs := []slog.Attr{slog.String("op", e.Op.String()), slog.String("path", e.Path)}
+	if e.Class != "" {
+		attrs = append(attrs, slog.String("class", e.Class))
+	}
+	if e.Name != "" {
+		attrs = append(attrs, slog.String("name", e.Name))
+	}
+	if e.Source != "" {
+		attrs = append(attrs, slog.String("source", e.Source))
+	}
+	if len(e.Attributes) > 0 {
+		attrs = append(attrs, slog.Any("attributes", e.Attributes))
+	}
+	attrs = append(attrs, slog.Duration("duration", e.Duration))
+	h.log(h.levels.Write, e.Err, "adsi write", attrs...)
+}
+
+// logSearch logs a search that has completed or failed.
+func (r *SearchResult) logSearch(err error) {
+	if !r.h.logging() {
+		return
+	}
+	r.h.log(r.h.levels.Search, err, "adsi search",
+		slog.String("base", r.base),
+		slog.String("filter", r.filter),
+		slog.Int("rows", r.rows),
+		slog.Duration("duration", time.Since(r.start)))
+}
+
+// searchBase returns the ADsPath of the object a searcher is rooted at, for
+// logging and tracing. The caller must hold the searcher's lock.
+func (s *Searcher) searchBase() string {
+	idispatch, err := s.iface.QueryInterface(comutil.GUID(comiid.IADs))
+	if err != nil {
+		return ""
+	}
+	defer idispatch.Release()
+	path, _ := (*api.IADs)(unsafe.Pointer(idispatch)).AdsPath()
+	return path
+}
@@ -0,0 +1,176 @@
+package adsi
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-adsi/adsi/api"
+)
+
+// LogonHoursSize is the number of bytes in an encoded logonHours attribute.
+const LogonHoursSize = 21
+
+// ErrInvalidLogonHours is returned when a logonHours value does not have the
+// expected length.
+var ErrInvalidLogonHours = errors.New("logonHours value must be 21 bytes")
+
+// LogonHours is a decoded logonHours attribute. It holds one bit for each
+// hour of the week, starting with the hour after midnight on Sunday in UTC.
+// A set bit indicates that the user is permitted to log on during that hour.
+//
+// Methods that accept a *time.Location translate between local hours and the
+// UTC bitmap using the location's offset from UTC at that hour of the current
+// week, so that hours on either side of a daylight saving time transition
+// are each translated with their own offset. Offsets are rounded down to the
+// hour, because the bitmap cannot express partial hours. A nil location is
+// treated as UTC.
+//
+// See https://learn.microsoft.com/windows/win32/adschema/a-logonhours
+type LogonHours [LogonHoursSize]byte
+
+// LogonHoursRange describes a contiguous range of permitted logon hours on a
+// particular day. Start is inclusive and End is exclusive, so a range
+// covering the whole day has a Start of 0 and an End of 24.
+type LogonHoursRange struct {
+	Day   time.Weekday
+	Start int
+	End   int
+}
+
+// String returns a description of the range, such as "Monday 08:00-17:00".
+func (r LogonHoursRange) String() string {
+	return fmt.Sprintf("%s %02d:00-%02d:00", r.Day, r.Start, r.End)
+}
+
+// AllLogonHours returns a logon hours value that permits logon at any time.
+func AllLogonHours() (h LogonHours) {
+	for i := range h {
+		h[i] = 0xFF
+	}
+	return
+}
+
+// ParseLogonHours decodes the given logonHours attribute value.
+//
+// An empty value indicates that no restriction has been applied, and is
+// returned as AllLogonHours.
+func ParseLogonHours(b []byte) (h LogonHours, err error) {
+	if len(b) == 0 {
+		return AllLogonHours(), nil
+	}
+	if len(b) != LogonHoursSize {
+		return h, ErrInvalidLogonHours
+	}
+	copy(h[:], b)
+	return h, nil
+}
+
+// Bytes returns the encoded form of the logon hours, suitable for storage in
+// the logonHours attribute.
+func (h LogonHours) Bytes() []byte {
+	b := make([]byte, LogonHoursSize)
+	copy(b, h[:])
+	return b
+}
+
+// Allowed returns true if logon is permitted during the given hour of the
+// given day in loc.
+func (h LogonHours) Allowed(day time.Weekday, hour int, loc *time.Location) bool {
+	i := logonHourIndex(day, hour, loc)
+	return h[i/8]&(1<<uint(i%8)) != 0
+}
+
+// AllowedAt returns true if logon is permitted at time t.
+func (h LogonHours) AllowedAt(t time.Time) bool {
+	t = t.UTC()
+	return h.Allowed(t.Weekday(), t.Hour(), time.UTC)
+}
+
+// Set permits or denies logon during the given hour of the given day in loc.
+func (h *LogonHours) Set(day time.Weekday, hour int, allowed bool, loc *time.Location) {
+	i := logonHourIndex(day, hour, loc)
+	if allowed {
+		h[i/8] |= 1 << uint(i%8)
+	} else {
+		h[i/8] &^= 1 << uint(i%8)
+	}
+}
+
+// SetRange permits or denies logon for the hours from start (inclusive) to
+// end (exclusive) on the given day in loc.
+func (h *LogonHours) SetRange(day time.Weekday, start, end int, allowed bool, loc *time.Location) {
+	for hour := start; hour < end; hour++ {
+		h.Set(day, hour, allowed, loc)
+	}
+}
+
+// Ranges returns the permitted logon hours as a list of ranges in loc,
+// ordered by day and then by start hour. Ranges do not span midnight; an
+// overnight permission is reported as two ranges.
+func (h LogonHours) Ranges(loc *time.Location) (ranges []LogonHoursRange) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		start := -1
+		for hour := 0; hour <= 24; hour++ {
+			allowed := hour < 24 && h.Allowed(day, hour, loc)
+			switch {
+			case allowed && start < 0:
+				start = hour
+			case !allowed && start >= 0:
+				ranges = append(ranges, LogonHoursRange{Day: day, Start: start, End: hour})
+				start = -1
+			}
+		}
+	}
+	return
+}
+
+// logonHourIndex returns the bit index within the UTC bitmap of the given
+// hour and day in loc.
+func logonHourIndex(day time.Weekday, hour int, loc *time.Location) int {
+	return logonHourIndexAt(day, hour, loc, time.Now())
+}
+
+// logonHourIndexAt returns the bit index within the UTC bitmap of the given
+// hour and day in loc, in the week that contains now.
+func logonHourIndexAt(day time.Weekday, hour int, loc *time.Location, now time.Time) int {
+	offset := 0
+	if loc != nil {
+		now = now.In(loc)
+		t := time.Date(now.Year(), now.Month(), now.Day()+int(day-now.Weekday()), hour, 0, 0, 0, loc)
+		_, seconds := t.Zone()
+		offset = seconds / 3600
+		if seconds%3600 < 0 {
+			offset--
+		}
+	}
+	i := (int(day)*24 + hour - offset) % (7 * 24)
+	if i < 0 {
+		i += 7 * 24
+	}
+	return i
+}
+
+// LogonHours retrieves the hours during which the user is permitted to log
+// on. If no restriction has been applied AllLogonHours is returned.
+func (u *User) LogonHours() (h LogonHours, err error) {
+	u.m.Lock()
+	defer u.m.Unlock()
+	if u.closed() {
+		return h, ErrClosed
+	}
+	b, err := u.object.AttrBytes("logonHours")
+	if errors.Is(err, api.ErrPropertyNotFound) {
+		return AllLogonHours(), nil
+	}
+	if err != nil {
+		return
+	}
+	return ParseLogonHours(b)
+}
+
+// SetLogonHours sets the hours during which the user is permitted to log on.
+// The value must be commited with SetInfo to be made persistent.
+func (u *User) SetLogonHours(h LogonHours) error {
+	return u.PutBytes("logonHours", h.Bytes())
+}
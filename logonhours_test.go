@@ -0,0 +1,93 @@
+package adsi
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestLogonHourIndex(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Daylight saving time began in New York at 02:00 on Sunday, March 10,
+	// 2024, and ended at 02:00 on Sunday, November 3, 2024
+	spring := time.Date(2024, 3, 13, 12, 0, 0, 0, time.UTC)
+	autumn := time.Date(2024, 11, 6, 12, 0, 0, 0, time.UTC)
+	summer := time.Date(2024, 10, 30, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		day  time.Weekday
+		hour int
+		loc  *time.Location
+		now  time.Time
+		want int
+	}{
+		{"utc", time.Monday, 9, time.UTC, spring, 24 + 9},
+		{"nil location", time.Monday, 9, nil, spring, 24 + 9},
+		{"east of utc", time.Monday, 9, time.FixedZone("", 2*3600), spring, 24 + 7},
+		{"west of utc", time.Monday, 9, time.FixedZone("", -5*3600), spring, 24 + 14},
+		{"wraps to saturday", time.Sunday, 0, time.FixedZone("", 3600), spring, 6*24 + 23},
+		{"wraps to sunday", time.Saturday, 23, time.FixedZone("", -3600), spring, 0},
+		{"half hour east", time.Monday, 9, time.FixedZone("", 5*3600+1800), spring, 24 + 4},
+		{"half hour west", time.Monday, 9, time.FixedZone("", -(9*3600 + 1800)), spring, 24 + 19},
+		{"before spring transition", time.Sunday, 1, newYork, spring, 6},
+		{"after spring transition", time.Monday, 9, newYork, spring, 24 + 13},
+		{"week before autumn transition", time.Saturday, 9, newYork, summer, 6*24 + 13},
+		{"week of autumn transition", time.Saturday, 9, newYork, autumn, 6*24 + 14},
+		{"after autumn transition", time.Sunday, 9, newYork, autumn, 14},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := logonHourIndexAt(tt.day, tt.hour, tt.loc, tt.now); got != tt.want {
+				t.Errorf("logonHourIndexAt(%v, %d) = %d, want %d", tt.day, tt.hour, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogonHours(t *testing.T) {
+	var h LogonHours
+	h.SetRange(time.Monday, 8, 17, true, time.UTC)
+	h.Set(time.Saturday, 23, true, time.UTC)
+	h.Set(time.Sunday, 0, true, time.UTC)
+
+	want := []LogonHoursRange{
+		{Day: time.Sunday, Start: 0, End: 1},
+		{Day: time.Monday, Start: 8, End: 17},
+		{Day: time.Saturday, Start: 23, End: 24},
+	}
+	if got := h.Ranges(time.UTC); !reflect.DeepEqual(got, want) {
+		t.Errorf("Ranges() = %v, want %v", got, want)
+	}
+	if got := h.Ranges(time.FixedZone("", 3600)); !reflect.DeepEqual(got, []LogonHoursRange{
+		{Day: time.Sunday, Start: 0, End: 2},
+		{Day: time.Monday, Start: 9, End: 18},
+	}) {
+		t.Errorf("Ranges() an hour east of UTC = %v", got)
+	}
+
+	if !h.AllowedAt(time.Date(2024, 3, 11, 8, 30, 0, 0, time.UTC)) {
+		t.Error("AllowedAt() = false during a permitted hour")
+	}
+	if h.AllowedAt(time.Date(2024, 3, 11, 12, 30, 0, 0, time.FixedZone("", 7*3600))) {
+		t.Error("AllowedAt() = true at 05:30 UTC")
+	}
+
+	parsed, err := ParseLogonHours(h.Bytes())
+	if err != nil || parsed != h {
+		t.Errorf("ParseLogonHours(Bytes()) = %v, %v, want %v", parsed, err, h)
+	}
+	if all, err := ParseLogonHours(nil); err != nil || all != AllLogonHours() {
+		t.Errorf("ParseLogonHours(nil) = %v, %v, want AllLogonHours()", all, err)
+	}
+	if _, err := ParseLogonHours(make([]byte, 20)); err != ErrInvalidLogonHours {
+		t.Errorf("ParseLogonHours() of 20 bytes returned %v, want ErrInvalidLogonHours", err)
+	}
+	if got := (LogonHoursRange{Day: time.Monday, Start: 8, End: 17}).String(); got != "Monday 08:00-17:00" {
+		t.Errorf("String() = %q", got)
+	}
+}
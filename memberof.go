@@ -0,0 +1,102 @@
+package adsi
+
+import (
+	"errors"
+
+	"github.com/go-adsi/adsi/api"
+)
+
+// MemberOf returns the groups that the object is a direct member of, as
+// recorded by the memberOf back-link attribute. If includePrimary is true
+// the object's primary group, which memberOf never includes, is returned as
+// well.
+//
+// The memberOf attribute only lists groups held by the domain controller
+// that answered the request. When bound to a global catalog, domain local
+// groups from other domains are omitted.
+//
+// The groups are opened with OpenDN. The returned groups consume resources
+// until they are closed. It is the caller's responsibility to call Close on
+// each of the returned groups when they are no longer needed.
+func (o *object) MemberOf(includePrimary bool) (groups []*Group, err error) {
+	dns, err := o.dnValues("memberOf")
+	if err != nil {
+		return
+	}
+
+	var paths []string
+	for _, dn := range dns {
+		path, err := o.pathForDN(dn)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	if includePrimary {
+		path, ok, err := o.primaryGroupPath()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			paths = append(paths, path)
+		}
+	}
+
+	for _, path := range paths {
+		group, err := o.openGroup(path)
+		if err != nil {
+			for _, opened := range groups {
+				opened.Close()
+			}
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+	return
+}
+
+// primaryGroupPath returns an ADsPath that binds to the primary group of the
+// object by SID. If the object has no primaryGroupID attribute ok is false.
+func (o *object) primaryGroupPath() (path string, ok bool, err error) {
+	sid, ok, err := o.primaryGroupSID()
+	if err != nil || !ok {
+		return
+	}
+	path, err = o.pathForDN(sid.bindingString())
+	return path, err == nil, err
+}
+
+// primaryGroupSID returns the security identifier of the primary group of the
+// object, which is formed from the domain portion of the object's own SID and
+// its primaryGroupID attribute. If the object has no primaryGroupID attribute
+// ok is false.
+func (o *object) primaryGroupSID() (sid SID, ok bool, err error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return SID{}, false, ErrClosed
+	}
+	rid, err := o.AttrInt("primaryGroupID")
+	if errors.Is(err, api.ErrPropertyNotFound) {
+		return SID{}, false, nil
+	}
+	if err != nil {
+		return
+	}
+	own, err := o.sid()
+	if err != nil {
+		return
+	}
+	return own.Domain().WithRID(uint32(rid)), true, nil
+}
+
+// openGroup opens the group at the given path in the same way as OpenDN
+// opens objects.
+func (o *object) openGroup(path string) (group *Group, err error) {
+	obj, err := o.h.open(path)
+	if err != nil {
+		return
+	}
+	defer obj.Close()
+	return obj.ToGroup()
+}
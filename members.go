@@ -1,6 +1,7 @@
 package adsi
 
 import (
+	"context"
 	"sync"
 	"unsafe"
 
@@ -15,13 +16,14 @@ import (
 type Members struct {
 	m     sync.RWMutex
 	iface *api.IADsMembers
+	h     *hooks
 }
 
 // NewMembers returns a membership that manages the given COM
 // interface.
 func NewMembers(iface *api.IADsMembers) *Members {
 	comshim.Add(1)
-	return &Members{iface: iface}
+	return track(&Members{iface: iface})
 }
 
 func (m *Members) closed() bool {
@@ -30,15 +32,18 @@ func (m *Members) closed() bool {
 
 // Close will release resources consumed by the membership. It should be
 // called when the membership is no longer needed.
-func (m *Members) Close() {
+func (m *Members) Close() error {
 	m.m.Lock()
 	defer m.m.Unlock()
 	if m.closed() {
-		return
+		return nil
 	}
+	untrack(m)
 	defer comshim.Done()
-	m.iface.Release()
+	defer m.h.released()
+	m.h.run(func() { m.iface.Release() })
 	m.iface = nil
+	return nil
 }
 
 // Iter returns an object iterator that provides access to the members
@@ -49,18 +54,39 @@ func (m *Members) Iter() (iter *ObjectIter, err error) {
 	if m.closed() {
 		return nil, ErrClosed
 	}
-	iunknown, err := m.iface.NewEnum()
-	if err != nil {
-		return
+	m.h.run(func() {
+		var iunknown *ole.IUnknown
+		iunknown, err = m.iface.NewEnum()
+		if err != nil {
+			return
+		}
+		defer iunknown.Release()
+		var idispatch *ole.IDispatch
+		idispatch, err = iunknown.QueryInterface(ole.IID_IEnumVariant)
+		if err != nil {
+			return
+		}
+		iface := (*ole.IEnumVARIANT)(unsafe.Pointer(idispatch))
+		iter = NewObjectIter(iface)
+		iter.h = m.h.opened()
+	})
+	return
+}
+
+// IterContext returns an object iterator like Iter that is bound to ctx. The
+// iterator releases its enumerator as soon as ctx is done or the last member
+// has been returned, so an iterator that is abandoned part way is released
+// when ctx is cancelled without having to be closed. After ctx is done Next
+// returns the error of ctx.
+func (m *Members) IterContext(ctx context.Context) (iter *ObjectIter, err error) {
+	if err = ctx.Err(); err != nil {
+		return nil, err
 	}
-	defer iunknown.Release()
-	idispatch, err := iunknown.QueryInterface(ole.IID_IEnumVariant)
-	if err != nil {
-		return
+	if iter, err = m.Iter(); err != nil {
+		return nil, err
 	}
-	iface := (*ole.IEnumVARIANT)(unsafe.Pointer(idispatch))
-	iter = NewObjectIter(iface)
-	return
+	iter.bind(ctx)
+	return iter, nil
 }
 
 // Filter returns the current filter of the membership.
@@ -70,12 +96,15 @@ func (m *Members) Filter() (filter []string, err error) {
 	if m.closed() {
 		return nil, ErrClosed
 	}
-	variant, err := m.iface.Filter()
-	if err != nil {
-		return
-	}
-	defer variant.Clear()
-	filter = variant.ToArray().ToStringArray()
+	m.h.run(func() {
+		var variant *ole.VARIANT
+		variant, err = m.iface.Filter()
+		if err != nil {
+			return
+		}
+		defer variant.Clear()
+		filter = variant.ToArray().ToStringArray()
+	})
 	return
 }
 
@@ -86,9 +115,12 @@ func (m *Members) SetFilter(filter ...string) (err error) {
 	if m.closed() {
 		return ErrClosed
 	}
-	safeByteArray := comutil.SafeArrayFromStringSlice(filter)
-	variant := ole.NewVariant(ole.VT_ARRAY|ole.VT_BSTR, int64(uintptr(unsafe.Pointer(safeByteArray))))
-	v := &variant
-	defer v.Clear()
-	return m.iface.SetFilter(v)
+	m.h.run(func() {
+		safeByteArray := comutil.SafeArrayFromStringSlice(filter)
+		variant := ole.NewVariant(ole.VT_ARRAY|ole.VT_BSTR, int64(uintptr(unsafe.Pointer(safeByteArray))))
+		v := &variant
+		defer v.Clear()
+		err = m.iface.SetFilter(v)
+	})
+	return
 }
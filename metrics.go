@@ -0,0 +1,108 @@
+package adsi
+
+import (
+	"expvar"
+	"time"
+)
+
+// MetricsRecorder receives measurements of the directory operations made
+// through a client. Its methods are called synchronously and may be called
+// concurrently, so they must be safe for concurrent use and return promptly.
+type MetricsRecorder interface {
+	// Operation records a completed operation, which is named in the same
+	// way as TraceSpan.Name. A non-nil err indicates that it failed.
+	Operation(name string, duration time.Duration, err error)
+
+	// OpenObjects adds delta to the number of objects, containers,
+	// iterators, searchers and result sets opened through the client that
+	// have not yet been closed.
+	OpenObjects(delta int)
+}
+
+// SetMetricsRecorder sets the recorder that receives measurements of the
+// binds, searches and write operations made through the client and the
+// objects, containers and searchers opened by it. It applies only to objects
+// opened after it is called. A nil recorder disables metrics, which is the
+// default.
+func (c *Client) SetMetricsRecorder(m MetricsRecorder) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	h := c.h.clone()
+	h.meter = m
+	c.h = h
+}
+
+// measure records a completed operation.
+func (h *hooks) measure(name string, start time.Time, err error) {
+	if h == nil || h.meter == nil {
+		return
+	}
+	h.meter.Operation(name, time.Since(start), err)
+}
+
+// opened records that an object sharing the hooks has been opened, adding a
+// reference to the worker, and returns the hooks.
+func (h *hooks) opened() *hooks {
+	if h == nil {
+		return nil
+	}
+	h.w.acquire()
+	if h.meter != nil {
+		h.meter.OpenObjects(1)
+	}
+	return h
+}
+
+// released records that an object sharing the hooks has been closed,
+// removing its reference to the worker.
+func (h *hooks) released() {
+	if h == nil {
+		return
+	}
+	if h.meter != nil {
+		h.meter.OpenObjects(-1)
+	}
+	h.w.release()
+}
+
+// ExpvarMetrics is a MetricsRecorder that publishes its measurements with the
+// expvar package. The operation maps are keyed by operation name.
+type ExpvarMetrics struct {
+	Operations  *expvar.Map // number of operations
+	Errors      *expvar.Map // number of failed operations
+	Nanoseconds *expvar.Map // total duration of operations
+	Open        *expvar.Int // number of open objects
+}
+
+// NewExpvarMetrics returns a recorder whose measurements are published as an
+// expvar map with the given name, holding "operations", "errors",
+// "nanoseconds" and "open". Like expvar.Publish it panics if the name is
+// already in use.
+func NewExpvarMetrics(name string) *ExpvarMetrics {
+	m := &ExpvarMetrics{
+		Operations:  new(expvar.Map).Init(),
+		Errors:      new(expvar.Map).Init(),
+		Nanoseconds: new(expvar.Map).Init(),
+		Open:        new(expvar.Int),
+	}
+	vars := expvar.NewMap(name)
+	vars.Set("operations", m.Operations)
+	vars.Set("errors", m.Errors)
+	vars.Set("nanoseconds", m.Nanoseconds)
+	vars.Set("open", m.Open)
+	return m
+}
+
+// Operation records a completed operation.
+func (m *ExpvarMetrics) Operation(name string, duration time.Duration, err error) {
+	m.Operations.Add(name, 1)
+	m.Nanoseconds.Add(name, int64(duration))
+	if err != nil {
+		m.Errors.Add(name, 1)
+	}
+}
+
+// OpenObjects adds delta to the number of open objects.
+func (m *ExpvarMetrics) OpenObjects(delta int) {
+	m.Open.Add(int64(delta))
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"sync"
+	"time"
 	"unsafe"
 
 	"github.com/go-adsi/adsi/api"
@@ -25,12 +26,22 @@ type Object struct {
 // NewObject returns an object that manages the given COM interface.
 func NewObject(iface *api.IADs) *Object {
 	comshim.Add(1)
-	return &Object{object{iface: iface}}
+	return track(&Object{object{iface: iface}})
 }
 
 type object struct {
 	m     sync.RWMutex
 	iface *api.IADs
+	h     *hooks
+
+	// pending holds the attributes put since the last SetInfo, for auditing
+	pending []string
+
+	// ifaces holds the interfaces acquired with queryInterface
+	ifaces map[uuid.UUID]*ole.IDispatch
+
+	// cache records the attributes loaded when NoImplicitGetInfo is used
+	cache propertyCache
 }
 
 func (o *object) closed() bool {
@@ -38,16 +49,54 @@ func (o *object) closed() bool {
 }
 
 // Close will release resources consumed by the object. It should be
-// called when the object is no longer needed.
-func (o *object) Close() {
+// called when the object is no longer needed. Closing an object that has
+// already been closed has no effect.
+func (o *object) Close() error {
 	o.m.Lock()
 	defer o.m.Unlock()
 	if o.closed() {
-		return
+		return nil
 	}
+	untrack(o)
 	defer comshim.Done()
-	o.iface.Release() // FIXME: What happens if release returns an error?
+	defer o.h.released()
+	o.h.run(func() {
+		o.releaseInterfaces()
+		o.iface.Release()
+	})
 	o.iface = nil
+	return nil
+}
+
+// releaseInterfaces releases the interfaces acquired with queryInterface.
+// It must be called on the object's worker while holding the object's lock,
+// by every Close method of the types that embed the object.
+func (o *object) releaseInterfaces() {
+	for _, idispatch := range o.ifaces {
+		idispatch.Release()
+	}
+	o.ifaces = nil
+}
+
+// queryInterface returns the interface of the object with the given IID. The
+// interface is acquired the first time it is requested and kept until the
+// object is closed, so that converting an object repeatedly doesn't query it
+// each time. Each call adds a reference to the interface that the caller must
+// release. It must be called on the object's worker while holding the
+// object's lock.
+func (o *object) queryInterface(iid uuid.UUID) (idispatch *ole.IDispatch, err error) {
+	if idispatch = o.ifaces[iid]; idispatch == nil {
+		idispatch, err = o.iface.QueryInterface(comutil.GUID(iid))
+		if err != nil {
+			return nil, err
+		}
+		if o.ifaces == nil {
+			o.ifaces = make(map[uuid.UUID]*ole.IDispatch)
+		}
+		o.ifaces[iid] = idispatch
+	}
+	idispatch.AddRef()
+	return idispatch, nil
 }
 
 // Name retrieves the name of the object.
@@ -57,7 +106,11 @@ func (o *object) Name() (name string, err error) {
 	if o.closed() {
 		return "", ErrClosed
 	}
-	name, err = o.iface.Name()
+	o.h.run(func() {
+		defer beginCall()()
+		name, err = o.iface.Name()
+		err = o.err("Name", err)
+	})
 	return
 }
 
@@ -68,7 +121,11 @@ func (o *object) Class() (class string, err error) {
 	if o.closed() {
 		return "", ErrClosed
 	}
-	class, err = o.iface.Class()
+	o.h.run(func() {
+		defer beginCall()()
+		class, err = o.iface.Class()
+		err = o.err("Class", err)
+	})
 	return
 }
 
@@ -82,7 +139,11 @@ func (o *object) GUID() (guid uuid.UUID, err error) {
 	}
 
 	var sguid string
-	sguid, err = o.iface.GUID() // may return binary octet string in hexadecimal form
+	o.h.run(func() {
+		defer beginCall()()
+		sguid, err = o.iface.GUID() // may return binary octet string in hexadecimal form
+		err = o.err("GUID", err)
+	})
 	if err != nil {
 		return
 	}
@@ -121,7 +182,11 @@ func (o *object) Path() (path string, err error) {
 	if o.closed() {
 		return "", ErrClosed
 	}
-	path, err = o.iface.AdsPath()
+	o.h.run(func() {
+		defer beginCall()()
+		path, err = o.iface.AdsPath()
+		err = o.err("Path", err)
+	})
 	return
 }
 
@@ -132,7 +197,11 @@ func (o *object) Parent() (path string, err error) {
 	if o.closed() {
 		return "", ErrClosed
 	}
-	path, err = o.iface.Parent()
+	o.h.run(func() {
+		defer beginCall()()
+		path, err = o.iface.Parent()
+		err = o.err("Parent", err)
+	})
 	return
 }
 
@@ -144,7 +213,11 @@ func (o *object) Schema() (path string, err error) {
 	if o.closed() {
 		return "", ErrClosed
 	}
-	path, err = o.iface.Schema()
+	o.h.run(func() {
+		defer beginCall()()
+		path, err = o.iface.Schema()
+		err = o.err("Schema", err)
+	})
 	return
 }
 
@@ -164,7 +237,13 @@ func (o *object) Pull(attrs ...string) (err error) {
 	}
 	defer v.Clear()
 
-	err = o.iface.GetInfoEx(v)
+	o.h.run(func() {
+		defer beginCall()()
+		err = o.err("GetInfoEx", o.iface.GetInfoEx(v))
+	})
+	if err == nil {
+		o.cache.add(attrs...)
+	}
 	return
 }
 
@@ -176,22 +255,49 @@ func (o *object) Pull(attrs ...string) (err error) {
 // If the attribute contains IUnknown or IDispatch members, it is the
 // caller's responsibility to release them.
 func (o *object) Attr(name string) (values []interface{}, err error) {
-	variant, err := o.iface.GetEx(name)
+	o.h.run(func() { values, err = o.attr(name) })
+	return
+}
+
+// attr retrieves the values of the attribute with the given name. It must be
+// called on the object's worker.
+func (o *object) attr(name string) (values []interface{}, err error) {
+	err = o.attrVariants(name, func(elements []ole.VARIANT) (err error) {
+		values, err = variantValues(nil, elements)
+		if err != nil {
+			releaseValues(values)
+		}
+		return
+	})
 	if err != nil {
 		return nil, err
 	}
+	return values, nil
+}
+
+// attrVariants retrieves the values of the attribute with the given name
+// and passes them to fn, which reads them in place as described by
+// eachVariant. It must be called on the object's worker.
+func (o *object) attrVariants(name string, fn func(elements []ole.VARIANT) error) (err error) {
+	if err = o.cache.check(name); err != nil {
+		return err
+	}
+	defer beginCall()()
+	variant, err := o.iface.GetEx(name)
+	if err != nil {
+		return o.err("GetEx "+name, err)
+	}
 	defer variant.Clear()
 
 	array := variant.ToArray()
 	if array == nil {
-		return nil, ErrNonArrayAttribute
+		return ErrNonArrayAttribute
 	}
 
-	values, err = comutil.SafeArrayToVariantSlice(array)
-	if err != nil {
-		return nil, fmt.Errorf("unable to read \"%s\" attribute: %v", name, err)
+	if err = eachVariant(array, fn); err != nil {
+		return fmt.Errorf("unable to read \"%s\" attribute: %v", name, err)
 	}
-	return values, nil
+	return nil
 }
 
 // AttrStringSlice attempts to retrieve the attribute with the given name and
@@ -199,22 +305,12 @@ func (o *object) Attr(name string) (values []interface{}, err error) {
 //
 // Any non-string values contained in the attribute will be ommitted.
 func (o *object) AttrStringSlice(name string) (values []string, err error) {
-	elements, err := o.Attr(name)
-	if err != nil {
-		return
-	}
-	for _, element := range elements {
-		switch v := element.(type) {
-		case string:
-			values = append(values, v)
-		case *ole.IUnknown:
-			v.Release()
-		case *ole.IDispatch:
-			v.Release()
-		default:
-			// TODO: Consider returning error
-		}
-	}
+	o.h.run(func() {
+		err = o.attrVariants(name, func(elements []ole.VARIANT) error {
+			values = variantStrings(nil, elements)
+			return nil
+		})
+	})
 	return
 }
 
@@ -239,22 +335,12 @@ func (o *object) AttrString(name string) (attr string, err error) {
 //
 // Any non-byte values contained in the attribute will be ommitted.
 func (o *object) AttrBytesSlice(name string) (values [][]byte, err error) {
-	elements, err := o.Attr(name)
-	if err != nil {
-		return
-	}
-	for _, element := range elements {
-		switch v := element.(type) {
-		case []byte:
-			values = append(values, v)
-		case *ole.IUnknown:
-			v.Release()
-		case *ole.IDispatch:
-			v.Release()
-		default:
-			// TODO: Consider returning error
-		}
-	}
+	o.h.run(func() {
+		err = o.attrVariants(name, func(elements []ole.VARIANT) error {
+			values = variantBytes(elements)
+			return nil
+		})
+	})
 	return
 }
 
@@ -279,22 +365,12 @@ func (o *object) AttrBytes(name string) (attr []byte, err error) {
 //
 // Any non-bool values contained in the attribute will be ommitted.
 func (o *object) AttrBoolSlice(name string) (values []bool, err error) {
-	elements, err := o.Attr(name)
-	if err != nil {
-		return
-	}
-	for _, element := range elements {
-		switch v := element.(type) {
-		case bool:
-			values = append(values, v)
-		case *ole.IUnknown:
-			v.Release()
-		case *ole.IDispatch:
-			v.Release()
-		default:
-			// TODO: Consider returning error
-		}
-	}
+	o.h.run(func() {
+		err = o.attrVariants(name, func(elements []ole.VARIANT) error {
+			values = variantBools(elements)
+			return nil
+		})
+	})
 	return
 }
 
@@ -324,42 +400,19 @@ func (o *object) AttrBool(name string) (attr bool, err error) {
 // 64-bit integer types will be coerced into integer types, which may
 // overflow the value on 32-bit systems.
 func (o *object) AttrIntSlice(name string) (values []int, err error) {
-	elements, err := o.Attr(name)
-	if err != nil {
-		return
-	}
-	for i, element := range elements {
-		switch v := element.(type) {
-		case int:
-			values = append(values, v)
-		case uint:
-			values = append(values, int(v))
-		case int16:
-			values = append(values, int(v))
-		case uint16:
-			values = append(values, int(v))
-		case int32:
-			values = append(values, int(v))
-		case uint32:
-			values = append(values, int(v))
-		case int64:
-			values = append(values, int(v))
-		case uint64:
-			values = append(values, int(v))
-		case *ole.IUnknown:
-			v.Release()
-		case *ole.IDispatch:
-			var value int64
-			value, err = dispatchToInt64(v)
-			v.Release()
+	o.h.run(func() {
+		err = o.attrVariants(name, func(elements []ole.VARIANT) error {
+			array, err := variantInt64s(elements)
 			if err != nil {
-				return nil, fmt.Errorf("attribute \"%s\" value %d: %v", name, i, err)
+				return err
 			}
-			values = append(values, int(value))
-		default:
-			// TODO: Consider returning error
-		}
-	}
+			values = make([]int, len(array))
+			for i, v := range array {
+				values[i] = int(v)
+			}
+			return nil
+		})
+	})
 	return
 }
 
@@ -386,42 +439,12 @@ func (o *object) AttrInt(name string) (attr int, err error) {
 //
 // Unsigned integer values will be coerced into signed types.
 func (o *object) AttrInt64Slice(name string) (values []int64, err error) {
-	elements, err := o.Attr(name)
-	if err != nil {
-		return nil, err
-	}
-	for i, element := range elements {
-		switch v := element.(type) {
-		case int:
-			values = append(values, int64(v))
-		case uint:
-			values = append(values, int64(v))
-		case int16:
-			values = append(values, int64(v))
-		case uint16:
-			values = append(values, int64(v))
-		case int32:
-			values = append(values, int64(v))
-		case uint32:
-			values = append(values, int64(v))
-		case int64:
-			values = append(values, v)
-		case uint64:
-			values = append(values, int64(v))
-		case *ole.IUnknown:
-			v.Release()
-		case *ole.IDispatch:
-			var value int64
-			value, err = dispatchToInt64(v)
-			v.Release()
-			if err != nil {
-				return nil, fmt.Errorf("attribute \"%s\" value %d: %v", name, i, err)
-			}
-			values = append(values, value)
-		default:
-			// TODO: Consider returning error
-		}
-	}
+	o.h.run(func() {
+		err = o.attrVariants(name, func(elements []ole.VARIANT) (err error) {
+			values, err = variantInt64s(elements)
+			return
+		})
+	})
 	return
 }
 
@@ -448,38 +471,12 @@ func (o *object) AttrInt64(name string) (attr int64, err error) {
 //
 // Values are returned as-is, without any byte ordering adjustment.
 func (o *object) AttrGUIDSlice(name string) (values []uuid.UUID, err error) {
-	elements, err := o.Attr(name)
-	if err != nil {
-		return
-	}
-	for _, element := range elements {
-		switch v := element.(type) {
-		case string:
-			value, parseErr := uuid.Parse(v)
-			if parseErr == nil {
-				values = append(values, value)
-			} else {
-				// TODO: Consider returning error
-			}
-		case []byte:
-			if len(v) == 16 {
-				value, parseErr := uuid.FromBytes(v)
-				if parseErr == nil {
-					values = append(values, value)
-				} else {
-					// TODO: Consider returning error
-				}
-			} else {
-				// TODO: Consider returning error
-			}
-		case *ole.IUnknown:
-			v.Release()
-		case *ole.IDispatch:
-			v.Release()
-		default:
-			// TODO: Consider returning error
-		}
-	}
+	o.h.run(func() {
+		err = o.attrVariants(name, func(elements []ole.VARIANT) error {
+			values = variantGUIDs(elements)
+			return nil
+		})
+	})
 	return
 }
 
@@ -507,7 +504,18 @@ func (o *object) PutInt(name string, val int) error {
 	if o.closed() {
 		return ErrClosed
 	}
-	return o.iface.PutInt(name, val)
+	if err := o.checkWrite("Put", name); err != nil {
+		return err
+	}
+	var err error
+	o.h.run(func() {
+		defer beginCall()()
+		start := time.Now()
+		err = o.iface.PutInt(name, val)
+		err = o.err("Put "+name, err)
+		o.auditPut(name, start, err)
+	})
+	return err
 }
 
 // PutString sets the values of a string attribute in the ADSI attribute
@@ -518,7 +526,95 @@ func (o *object) PutString(name string, val string) error {
 	if o.closed() {
 		return ErrClosed
 	}
-	return o.iface.PutString(name, val)
+	if err := o.checkWrite("Put", name); err != nil {
+		return err
+	}
+	var err error
+	o.h.run(func() {
+		defer beginCall()()
+		start := time.Now()
+		err = o.iface.PutString(name, val)
+		err = o.err("Put "+name, err)
+		o.auditPut(name, start, err)
+	})
+	return err
+}
+
+// PutInt64 sets the value of a large integer attribute in the ADSI attribute
+// cache. The value must be commited with SetInfo to be made persistent.
+func (o *object) PutInt64(name string, val int64) error {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return ErrClosed
+	}
+	if err := o.checkWrite("Put", name); err != nil {
+		return err
+	}
+	var err error
+	o.h.run(func() {
+		defer beginCall()()
+		start := time.Now()
+		err = putInt64(o.iface, name, val)
+		err = o.err("Put "+name, err)
+		o.auditPut(name, start, err)
+	})
+	return err
+}
+
+// PutBytes sets the value of an octet string attribute in the ADSI attribute
+// cache. The value must be commited with SetInfo to be made persistent.
+func (o *object) PutBytes(name string, val []byte) error {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return ErrClosed
+	}
+	if err := o.checkWrite("Put", name); err != nil {
+		return err
+	}
+	variant, err := bytesToVariant(val)
+	if err != nil {
+		return err
+	}
+	defer variant.Clear()
+	o.h.run(func() {
+		defer beginCall()()
+		start := time.Now()
+		err = o.iface.Put(name, variant)
+		err = o.err("Put "+name, err)
+		o.auditPut(name, start, err)
+	})
+	return err
+}
+
+// PutEx modifies the values of a multi-valued attribute in the ADSI attribute
+// cache. The control code determines whether the values replace, are appended
+// to, or are deleted from the existing values, or whether the attribute is
+// cleared. See the ADS_PROPERTY_* constants in the api package for the
+// available options.
+//
+// Values may be strings, byte slices, booleans or integers. 64-bit integers
+// are stored as large integers. The value must be commited with SetInfo to be
+// made persistent.
+func (o *object) PutEx(controlCode uint32, name string, values ...interface{}) error {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return ErrClosed
+	}
+	if err := o.checkWrite("PutEx", name); err != nil {
+		return err
+	}
+	var err error
+	o.h.run(func() {
+		defer beginCall()()
+		start := time.Now()
+		err = putEx(o.iface, controlCode, name, values)
+		err = o.err("PutEx "+name, err)
+		o.auditPut(name, start, err)
+	})
+	return err
 }
 
 // SetInfo saves the cached property values of the ADSI object to the underlying
@@ -529,7 +625,20 @@ func (o *object) SetInfo() error {
 	if o.closed() {
 		return ErrClosed
 	}
-	return o.iface.SetInfo()
+	var err error
+	o.h.run(func() {
+		defer beginCall()()
+		start := time.Now()
+		err = o.iface.SetInfo()
+		err = o.err("SetInfo", err)
+		if o.h.auditing() {
+			o.audit(AuditSetInfo, start, o.pending, err)
+			if err == nil {
+				o.pending = nil
+			}
+		}
+	})
+	return err
 }
 
 // ToContainer attempts to acquire a container interface for the object.
@@ -539,12 +648,14 @@ func (o *object) ToContainer() (c *Container, err error) {
 	if o.closed() {
 		return nil, ErrClosed
 	}
-	idispatch, err := o.iface.QueryInterface(comutil.GUID(comiid.IADsContainer))
+	var idispatch *ole.IDispatch
+	o.h.run(func() { idispatch, err = o.queryInterface(comiid.IADsContainer) })
 	if err != nil {
 		return
 	}
 	iface := (*api.IADsContainer)(unsafe.Pointer(idispatch))
 	c = NewContainer(iface)
+	c.h = o.h.opened()
 	return
 }
 
@@ -555,12 +666,14 @@ func (o *object) ToComputer() (c *Computer, err error) {
 	if o.closed() {
 		return nil, ErrClosed
 	}
-	idispatch, err := o.iface.QueryInterface(comutil.GUID(comiid.IADsComputer))
+	var idispatch *ole.IDispatch
+	o.h.run(func() { idispatch, err = o.queryInterface(comiid.IADsComputer) })
 	if err != nil {
 		return
 	}
 	iface := (*api.IADsComputer)(unsafe.Pointer(idispatch))
 	c = NewComputer(iface)
+	c.h = o.h.opened()
 	return
 }
 
@@ -571,12 +684,14 @@ func (o *object) ToGroup() (g *Group, err error) {
 	if o.closed() {
 		return nil, ErrClosed
 	}
-	idispatch, err := o.iface.QueryInterface(comutil.GUID(comiid.IADsGroup))
+	var idispatch *ole.IDispatch
+	o.h.run(func() { idispatch, err = o.queryInterface(comiid.IADsGroup) })
 	if err != nil {
 		return
 	}
 	iface := (*api.IADsGroup)(unsafe.Pointer(idispatch))
 	g = NewGroup(iface)
+	g.h = o.h.opened()
 	return
 }
 
@@ -587,11 +702,32 @@ func (o *object) ToUser() (u *User, err error) {
 	if o.closed() {
 		return nil, ErrClosed
 	}
-	idispatch, err := o.iface.QueryInterface(comutil.GUID(comiid.IADsUser))
+	var idispatch *ole.IDispatch
+	o.h.run(func() { idispatch, err = o.queryInterface(comiid.IADsUser) })
 	if err != nil {
 		return
 	}
 	iface := (*api.IADsUser)(unsafe.Pointer(idispatch))
 	u = NewUser(iface)
+	u.h = o.h.opened()
+	return
+}
+
+// ToSearcher attempts to acquire a directory search interface for the object.
+// Searches conducted with the returned searcher are rooted at the object.
+func (o *object) ToSearcher() (s *Searcher, err error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return nil, ErrClosed
+	}
+	var idispatch *ole.IDispatch
+	o.h.run(func() { idispatch, err = o.queryInterface(comiid.IDirectorySearch) })
+	if err != nil {
+		return
+	}
+	iface := (*api.IDirectorySearch)(unsafe.Pointer(idispatch))
+	s = NewSearcher(iface)
+	s.h = o.h.opened()
 	return
 }
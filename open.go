@@ -156,3 +156,113 @@ func OpenComputerSC(path, user, password string, flags uint32) (computer *Comput
 	defer c.Close()
 	return c.OpenComputerSC(path, user, password, flags)
 }
+
+// OpenSearcher opens an ADSI directory searcher rooted at the given path. It
+// creates an ephemeral local client and uses it to open the requested
+// searcher. The connection is made using the security context of the
+// application and the default client flags specifying that it be encrypted
+// and read-only.
+//
+// OpenSearcher returns the searcher as a Searcher type, which provides an
+// idiomatic go wrapper around the underlying component object model
+// IDirectorySearch interface.
+//
+// If the returned directory object does not implement the IDirectorySearch
+// interface an error is returned.
+//
+// The returned searcher consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned searcher when it is no
+// longer needed.
+func OpenSearcher(path string) (searcher *Searcher, err error) {
+	c, err := NewClient()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	return c.OpenSearcher(path)
+}
+
+// OpenSearcherSC opens an ADSI directory searcher rooted at the given path.
+// Most users will use OpenSearcher instead. It creates an ephemeral local
+// client and uses it to open the requested searcher.
+//
+// When provided, the username and password are used to establish a security
+// context for the connection. When they are not provided the existing
+// security context of the application is used instead. The provided flags will
+// be used to make the connection.
+//
+// OpenSearcherSC returns the searcher as a Searcher type, which provides an
+// idiomatic go wrapper around the underlying component object model
+// IDirectorySearch interface.
+//
+// If the returned directory object does not implement the IDirectorySearch
+// interface an error is returned.
+//
+// The returned searcher consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned searcher when it is no
+// longer needed.
+func OpenSearcherSC(path, user, password string, flags uint32) (searcher *Searcher, err error) {
+	c, err := NewClient()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	return c.OpenSearcherSC(path, user, password, flags)
+}
+
+// Search executes the given query against the directory, rooted at the object
+// with the given path. It creates an ephemeral local client and uses it to
+// open a searcher, which is released once the search has been executed. The
+// connection is made using the security context of the application and the
+// default client flags specifying that it be encrypted and read-only.
+//
+// The returned result set consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned result set when it is
+// no longer needed.
+func Search(path string, q Query) (result *SearchResult, err error) {
+	c, err := NewClient()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	return c.Search(path, q)
+}
+
+// OpenDomain opens the Active Directory domain object with the given path,
+// such as "LDAP://DC=example,DC=com". It creates an ephemeral local client
+// and uses it to open the requested domain. The connection is made using the
+// security context of the application and the default client flags
+// specifying that it be encrypted and read-only.
+//
+// The returned domain consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned domain when it is no
+// longer needed.
+func OpenDomain(path string) (domain *Domain, err error) {
+	c, err := NewClient()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	return c.OpenDomain(path)
+}
+
+// OpenDomainSC opens the Active Directory domain object with the given path.
+// Most users will use OpenDomain instead. It creates an ephemeral local
+// client and uses it to open the requested domain.
+//
+// When provided, the username and password are used to establish a security
+// context for the connection. When they are not provided the existing
+// security context of the application is used instead. The provided flags will
+// be used to make the connection.
+//
+// The returned domain consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned domain when it is no
+// longer needed.
+func OpenDomainSC(path, user, password string, flags uint32) (domain *Domain, err error) {
+	c, err := NewClient()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	return c.OpenDomainSC(path, user, password, flags)
+}
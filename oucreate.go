@@ -0,0 +1,216 @@
+package adsi
+
+import (
+	"errors"
+
+	"github.com/go-adsi/adsi/sddl"
+)
+
+// everyoneSID is the well-known security identifier of the Everyone group.
+var everyoneSID = SID{Revision: 1, Authority: 1, SubAuthorities: []uint32{0}}
+
+// accidentalDeletionRights is the access mask denied to Everyone on objects
+// that are protected from accidental deletion.
+const accidentalDeletionRights = sddl.RightDelete | sddl.RightDSDeleteTree
+
+// OUOptions holds the settings of an organizational unit created by
+// CreateOU.
+type OUOptions struct {
+	// Name is the name of the organizational unit, which forms its relative
+	// distinguished name. It is required.
+	Name string
+
+	Description string
+
+	// Protect protects the unit from accidental deletion in the same way as
+	// the Active Directory administration tools do by default.
+	Protect bool
+}
+
+// CreateOU creates an organizational unit within the container and returns
+// it as a container.
+//
+// If opts.Protect is set, the unit is created with a security descriptor
+// that holds the default permissions of the organizationalUnit class and an
+// entry that denies Everyone the Delete and Delete Subtree rights, so that
+// it is never left unprotected. The default permissions are read from the
+// schema, with the credentials of the container.
+//
+// The returned container consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned container when it is
+// no longer needed.
+func (c *Container) CreateOU(opts OUOptions) (ou *Container, err error) {
+	if opts.Name == "" {
+		return nil, errors.New("organizational unit name is required")
+	}
+	var sd *SecurityDescriptor
+	if opts.Protect {
+		if sd, err = c.protectedOUSecurityDescriptor(); err != nil {
+			return
+		}
+	}
+	obj, err := c.Create("organizationalUnit", "OU="+escapeRDNValue(opts.Name))
+	if err != nil {
+		return
+	}
+	defer obj.Close()
+	if opts.Description != "" {
+		if err = obj.PutString("description", opts.Description); err != nil {
+			return
+		}
+	}
+	if sd != nil {
+		// The descriptor is committed with the rest of the new object
+		err = obj.writeDACL("SetInfo", sd)
+	} else {
+		err = obj.SetInfo()
+	}
+	if err != nil {
+		return
+	}
+	return obj.ToContainer()
+}
+
+// protectedOUSecurityDescriptor returns the security descriptor of a new
+// organizational unit within the container that is protected from
+// accidental deletion. It holds only a DACL, so that the owner and group are
+// set by the server as they are for any other new object.
+func (c *Container) protectedOUSecurityDescriptor() (*SecurityDescriptor, error) {
+	obj, err := c.ToObject()
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+	server, err := obj.server()
+	if err != nil {
+		return nil, err
+	}
+	root, err := readRootDSE(server, obj.h.open)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := obj.searchDNAll(root.SchemaNamingContext, Query{
+		Filter:     "(&(objectClass=classSchema)(lDAPDisplayName=organizationalUnit))",
+		Attributes: []string{"defaultSecurityDescriptor"},
+		Scope:      ScopeOneLevel,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("organizationalUnit class not found in schema")
+	}
+	class := &ClassSchema{Name: "organizationalUnit", DefaultSecurityDescriptor: rows[0].AttrString("defaultSecurityDescriptor")}
+
+	dn, err := obj.DN()
+	if err != nil {
+		return nil, err
+	}
+	domainSID, err := obj.sidForDN(domainDN(dn))
+	if err != nil {
+		return nil, err
+	}
+	rootDomainSID, err := obj.sidForDN(root.RootDomainNamingContext)
+	if err != nil {
+		return nil, err
+	}
+	dacl, err := class.DefaultDACL(domainSID, rootDomainSID)
+	if err != nil {
+		return nil, err
+	}
+	dacl.AddExplicit(ACE{Type: ACETypeAccessDenied, Mask: accidentalDeletionRights, SID: everyoneSID})
+	return &SecurityDescriptor{DACL: dacl}, nil
+}
+
+// sidForDN reads the security identifier of the object with the given
+// distinguished name, bound with the credentials of this object.
+func (o *object) sidForDN(dn string) (sid SID, err error) {
+	path, err := o.pathForDN(dn)
+	if err != nil {
+		return
+	}
+	obj, err := o.h.open(path)
+	if err != nil {
+		return
+	}
+	defer obj.Close()
+	return obj.SID()
+}
+
+// ProtectedFromAccidentalDeletion returns true if the DACL of the container
+// denies Everyone the right to delete it, which is how the Active Directory
+// administration tools protect objects from accidental deletion.
+func (c *Container) ProtectedFromAccidentalDeletion() (bool, error) {
+	obj, err := c.ToObject()
+	if err != nil {
+		return false, err
+	}
+	defer obj.Close()
+	return obj.ProtectedFromAccidentalDeletion()
+}
+
+// SetProtectedFromAccidentalDeletion protects or unprotects the container
+// from accidental deletion, as described by
+// Object.SetProtectedFromAccidentalDeletion.
+func (c *Container) SetProtectedFromAccidentalDeletion(protect bool) error {
+	obj, err := c.ToObject()
+	if err != nil {
+		return err
+	}
+	defer obj.Close()
+	return obj.SetProtectedFromAccidentalDeletion(protect)
+}
+
+// ProtectedFromAccidentalDeletion returns true if the DACL of the object
+// denies Everyone the right to delete it, which is how the Active Directory
+// administration tools protect objects from accidental deletion.
+func (o *object) ProtectedFromAccidentalDeletion() (bool, error) {
+	sd, err := o.SecurityDescriptor()
+	if err != nil || sd.DACL == nil {
+		return false, err
+	}
+	for _, ace := range sd.DACL.ACEs {
+		if isAccidentalDeletionACE(&ace) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SetProtectedFromAccidentalDeletion adds or removes an explicit ACE that
+// denies Everyone the Delete and Delete Subtree rights on the object, which
+// is how the Active Directory administration tools protect objects from
+// accidental deletion. The change is written to the directory immediately.
+func (o *object) SetProtectedFromAccidentalDeletion(protect bool) error {
+	sd, err := o.SecurityDescriptor()
+	if err != nil {
+		return err
+	}
+	if sd.DACL == nil {
+		sd.DACL = new(ACL)
+	}
+	found := false
+	aces := sd.DACL.ACEs[:0]
+	for _, ace := range sd.DACL.ACEs {
+		if isAccidentalDeletionACE(&ace) {
+			found = true
+			if !protect {
+				continue
+			}
+		}
+		aces = append(aces, ace)
+	}
+	sd.DACL.ACEs = aces
+	switch {
+	case protect && found, !protect && !found:
+		return nil
+	case protect:
+		sd.DACL.AddExplicit(ACE{Type: ACETypeAccessDenied, Mask: accidentalDeletionRights, SID: everyoneSID})
+	}
+	return o.SetDACL(sd.DACL)
+}
+
+func isAccidentalDeletionACE(ace *ACE) bool {
+	return ace.Type == ACETypeAccessDenied && !ace.IsInherited() &&
+		ace.Mask&accidentalDeletionRights == accidentalDeletionRights && ace.SID.Equal(everyoneSID)
+}
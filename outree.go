@@ -0,0 +1,137 @@
+package adsi
+
+import (
+	"sort"
+	"strings"
+)
+
+// OUNode is a node in the tree of organizational units built by OUTree.
+type OUNode struct {
+	Name        string
+	DN          string
+	Description string
+
+	// GPLinks holds the Group Policy links of the unit in order of link
+	// precedence.
+	GPLinks []GPLink
+
+	// BlocksInheritance is true if the unit blocks inheritance of Group
+	// Policy from its parents.
+	BlocksInheritance bool
+
+	// ApproxChildren is the approximate number of objects of any class
+	// directly beneath the unit, as reported by the constructed
+	// msDS-Approx-Immed-Subordinates attribute.
+	ApproxChildren int
+
+	Parent   *OUNode
+	Children []*OUNode
+}
+
+// Walk calls fn for the node and each of its descendants in depth-first
+// order. The depth of the node Walk is called on is zero. If fn returns an
+// error the walk stops and the error is returned.
+func (n *OUNode) Walk(fn func(node *OUNode, depth int) error) error {
+	return n.walk(fn, 0)
+}
+
+func (n *OUNode) walk(fn func(node *OUNode, depth int) error, depth int) error {
+	if err := fn(n, depth); err != nil {
+		return err
+	}
+	for _, child := range n.Children {
+		if err := child.walk(fn, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Find returns the node with the given distinguished name, or nil if it is
+// not part of the tree. Names are compared case-insensitively.
+func (n *OUNode) Find(dn string) (found *OUNode) {
+	n.Walk(func(node *OUNode, depth int) error {
+		if strings.EqualFold(node.DN, dn) {
+			found = node
+			return errStopWalk
+		}
+		return nil
+	})
+	return
+}
+
+// errStopWalk is used to end a walk early.
+var errStopWalk = stopWalk{}
+
+type stopWalk struct{}
+
+func (stopWalk) Error() string { return "stop walk" }
+
+// ouTreeAttrs are the attributes read for each organizational unit.
+var ouTreeAttrs = []string{
+	"name", "distinguishedName", "description", "gPLink", "gPOptions",
+	"msDS-Approx-Immed-Subordinates",
+}
+
+// OUTree builds an in-memory tree of the organizational units beneath the
+// object, which is usually a domain or an organizational unit. The object
+// itself is the root of the tree.
+//
+// The tree is built from a single paged subtree search rather than by
+// binding to each container in turn. Malformed gPLink values are ignored.
+func (o *object) OUTree() (root *OUNode, err error) {
+	dn, err := o.DN()
+	if err != nil {
+		return
+	}
+	rows, err := o.searchDNAll(dn, Query{
+		Filter:     "(|(objectCategory=organizationalUnit)(objectClass=domainDNS))",
+		Attributes: ouTreeAttrs,
+	})
+	if err != nil {
+		return
+	}
+
+	nodes := make(map[string]*OUNode, len(rows))
+	for _, row := range rows {
+		node := &OUNode{
+			Name:              row.AttrString("name"),
+			DN:                row.AttrString("distinguishedName"),
+			Description:       row.AttrString("description"),
+			BlocksInheritance: row.AttrInt("gPOptions")&gpOptionsBlockInheritance != 0,
+			ApproxChildren:    row.AttrInt("msDS-Approx-Immed-Subordinates"),
+		}
+		node.GPLinks, _ = ParseGPLink(row.AttrString("gPLink"))
+		nodes[strings.ToLower(node.DN)] = node
+	}
+
+	root = nodes[strings.ToLower(dn)]
+	if root == nil {
+		root = &OUNode{Name: rdnValue(dn), DN: dn}
+		nodes[strings.ToLower(dn)] = root
+	}
+	for key, node := range nodes {
+		if node == root {
+			continue
+		}
+		// Attach each unit to its nearest ancestor in the tree, which skips
+		// over intermediate containers that are not units
+		for parent := parentDN(node.DN); parent != ""; parent = parentDN(parent) {
+			if p := nodes[strings.ToLower(parent)]; p != nil {
+				node.Parent = p
+				p.Children = append(p.Children, node)
+				break
+			}
+		}
+		if node.Parent == nil {
+			delete(nodes, key)
+		}
+	}
+	root.Walk(func(node *OUNode, depth int) error {
+		sort.Slice(node.Children, func(i, j int) bool {
+			return strings.ToLower(node.Children[i].Name) < strings.ToLower(node.Children[j].Name)
+		})
+		return nil
+	})
+	return root, nil
+}
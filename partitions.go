@@ -0,0 +1,171 @@
+package adsi
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PartitionKind is the kind of a directory partition.
+type PartitionKind int
+
+// Partition kinds.
+const (
+	// PartitionDomain is the domain naming context of a domain in the forest.
+	PartitionDomain PartitionKind = iota + 1
+
+	// PartitionConfiguration is the configuration naming context.
+	PartitionConfiguration
+
+	// PartitionSchema is the schema naming context.
+	PartitionSchema
+
+	// PartitionApplication is an application directory partition, such as
+	// DomainDnsZones or ForestDnsZones, which is replicated only to the
+	// domain controllers chosen to host it.
+	PartitionApplication
+
+	// PartitionExternal is a cross-reference to a naming context held
+	// outside of the forest.
+	PartitionExternal
+)
+
+// String returns the name of the partition kind, such as "Application".
+func (k PartitionKind) String() string {
+	switch k {
+	case PartitionDomain:
+		return "Domain"
+	case PartitionConfiguration:
+		return "Configuration"
+	case PartitionSchema:
+		return "Schema"
+	case PartitionApplication:
+		return "Application"
+	case PartitionExternal:
+		return "External"
+	default:
+		return "PartitionKind(" + strconv.Itoa(int(k)) + ")"
+	}
+}
+
+// systemFlags bits of crossRef objects.
+const (
+	crossRefNTDSNC     = 0x1 // FLAG_CR_NTDS_NC
+	crossRefNTDSDomain = 0x2 // FLAG_CR_NTDS_DOMAIN
+)
+
+// Partition is a directory partition of the forest, as described by its
+// crossRef object in the CN=Partitions container of the configuration naming
+// context.
+type Partition struct {
+	// NamingContext is the distinguished name of the root of the partition.
+	NamingContext string
+
+	// CrossRef is the distinguished name of the crossRef object that
+	// describes the partition.
+	CrossRef string
+
+	Kind PartitionKind
+
+	// DNSRoot is the DNS name of the partition, such as
+	// "DomainDnsZones.example.com".
+	DNSRoot string
+
+	// NetBIOSName is the NetBIOS name of the domain. It is only set for
+	// domain partitions.
+	NetBIOSName string
+
+	// Enabled is false while an application partition is being created or
+	// after it has been disabled.
+	Enabled bool
+
+	// Replicas holds the DNS host names of the domain controllers that hold a
+	// writable replica of the partition.
+	Replicas []string
+
+	// ReadOnlyReplicas holds the DNS host names of the read-only domain
+	// controllers that hold a replica of the partition.
+	ReadOnlyReplicas []string
+
+	// Local is true if the partition is held by the server the forest was
+	// opened with, according to the namingContexts attribute of its rootDSE.
+	Local bool
+}
+
+// Partitions returns the partitions of the forest, including its application
+// partitions, along with the domain controllers that host them.
+func (f *Forest) Partitions() (partitions []*Partition, err error) {
+	rows, err := f.search("CN=Partitions,"+f.root.ConfigurationNamingContext, Query{
+		Filter:     "(objectClass=crossRef)",
+		Attributes: []string{"distinguishedName", "nCName", "dnsRoot", "nETBIOSName", "systemFlags", "Enabled"},
+		Scope:      ScopeOneLevel,
+	})
+	if err != nil {
+		return
+	}
+	local := make(map[string]bool)
+	for _, nc := range f.root.NamingContexts {
+		local[strings.ToLower(nc)] = true
+	}
+	byNC := make(map[string]*Partition)
+	for _, row := range rows {
+		p := &Partition{
+			NamingContext: row.AttrString("nCName"),
+			CrossRef:      row.AttrString("distinguishedName"),
+			DNSRoot:       row.AttrString("dnsRoot"),
+			NetBIOSName:   row.AttrString("nETBIOSName"),
+			Enabled:       len(row.Attr("Enabled")) == 0 || row.AttrBool("Enabled"),
+		}
+		p.Kind = f.partitionKind(p.NamingContext, row.AttrInt("systemFlags"))
+		p.Local = local[strings.ToLower(p.NamingContext)]
+		partitions = append(partitions, p)
+		byNC[strings.ToLower(p.NamingContext)] = p
+	}
+
+	// The partitions held by each domain controller are listed on its NTDS
+	// settings object. hasMasterNCs is kept for compatibility and only lists
+	// the domain, configuration and schema naming contexts.
+	rows, err = f.search("CN=Sites,"+f.root.ConfigurationNamingContext, Query{
+		Filter:     "(objectClass=nTDSDSA)",
+		Attributes: []string{"distinguishedName", "msDS-hasMasterNCs", "hasMasterNCs", "msDS-hasFullReplicaNCs"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		name, err := f.serverName(row.AttrString("distinguishedName"))
+		if err != nil {
+			return nil, err
+		}
+		seen := make(map[*Partition]bool)
+		for _, nc := range append(row.AttrStringSlice("msDS-hasMasterNCs"), row.AttrStringSlice("hasMasterNCs")...) {
+			if p := byNC[strings.ToLower(nc)]; p != nil && !seen[p] {
+				seen[p] = true
+				p.Replicas = append(p.Replicas, name)
+			}
+		}
+		for _, nc := range row.AttrStringSlice("msDS-hasFullReplicaNCs") {
+			if p := byNC[strings.ToLower(nc)]; p != nil && !seen[p] {
+				seen[p] = true
+				p.ReadOnlyReplicas = append(p.ReadOnlyReplicas, name)
+			}
+		}
+	}
+	return
+}
+
+// partitionKind returns the kind of the partition rooted at nc, whose crossRef
+// object has the given systemFlags.
+func (f *Forest) partitionKind(nc string, flags int) PartitionKind {
+	switch {
+	case flags&crossRefNTDSNC == 0:
+		return PartitionExternal
+	case flags&crossRefNTDSDomain != 0:
+		return PartitionDomain
+	case strings.EqualFold(nc, f.root.ConfigurationNamingContext):
+		return PartitionConfiguration
+	case strings.EqualFold(nc, f.root.SchemaNamingContext):
+		return PartitionSchema
+	default:
+		return PartitionApplication
+	}
+}
@@ -0,0 +1,218 @@
+package adsi
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/go-adsi/adsi/api"
+)
+
+// Flags of the pwdProperties attribute of a domain.
+const (
+	pwdPropertiesComplex              = 0x01
+	pwdPropertiesReversibleEncryption = 0x10
+)
+
+// passwordPolicyAttrs are the domain attributes read by PasswordPolicy.
+var passwordPolicyAttrs = []string{
+	"distinguishedName", "minPwdLength", "pwdHistoryLength", "pwdProperties",
+	"maxPwdAge", "minPwdAge", "lockoutThreshold", "lockoutDuration",
+	"lockOutObservationWindow",
+}
+
+// passwordSettingsAttrs are the msDS-PasswordSettings attributes read by
+// PasswordSettings.
+var passwordSettingsAttrs = []string{
+	"distinguishedName", "name", "msDS-PasswordSettingsPrecedence",
+	"msDS-MinimumPasswordLength", "msDS-PasswordHistoryLength",
+	"msDS-PasswordComplexityEnabled", "msDS-PasswordReversibleEncryptionEnabled",
+	"msDS-MaximumPasswordAge", "msDS-MinimumPasswordAge",
+	"msDS-LockoutThreshold", "msDS-LockoutDuration",
+	"msDS-LockoutObservationWindow", "msDS-PSOAppliesTo",
+}
+
+// PasswordPolicy describes the password and account lockout rules that apply
+// to an account. It is read either from a domain object or from a
+// fine-grained password settings object (PSO).
+//
+// Durations of zero indicate that no limit applies. A LockoutDuration of zero
+// with a non-zero LockoutThreshold means that locked out accounts remain
+// locked until an administrator unlocks them.
+type PasswordPolicy struct {
+	// DN is the distinguished name of the domain or PSO that defines the
+	// policy.
+	DN string
+
+	// Name is the name of the PSO, or empty for the domain policy.
+	Name string
+
+	// Precedence is the msDS-PasswordSettingsPrecedence of the PSO. Lower
+	// values take priority. It is zero for the domain policy.
+	Precedence int
+
+	MinLength            int
+	HistoryLength        int
+	ComplexityEnabled    bool
+	ReversibleEncryption bool
+	MaxAge               time.Duration
+	MinAge               time.Duration
+
+	LockoutThreshold         int
+	LockoutDuration          time.Duration
+	LockoutObservationWindow time.Duration
+
+	// AppliesTo holds the distinguished names of the users and groups the
+	// PSO is linked to. It is empty for the domain policy.
+	AppliesTo []string
+}
+
+// IsFineGrained returns true if the policy was read from a PSO.
+func (p *PasswordPolicy) IsFineGrained() bool {
+	return p.Name != ""
+}
+
+// domainPasswordPolicy builds a policy from the attributes of a domain
+// object.
+func domainPasswordPolicy(row *Row) *PasswordPolicy {
+	props := row.AttrInt("pwdProperties")
+	return &PasswordPolicy{
+		DN:                       row.AttrString("distinguishedName"),
+		MinLength:                row.AttrInt("minPwdLength"),
+		HistoryLength:            row.AttrInt("pwdHistoryLength"),
+		ComplexityEnabled:        props&pwdPropertiesComplex != 0,
+		ReversibleEncryption:     props&pwdPropertiesReversibleEncryption != 0,
+		MaxAge:                   durationFromInterval(row.AttrInt64("maxPwdAge")),
+		MinAge:                   durationFromInterval(row.AttrInt64("minPwdAge")),
+		LockoutThreshold:         row.AttrInt("lockoutThreshold"),
+		LockoutDuration:          durationFromInterval(row.AttrInt64("lockoutDuration")),
+		LockoutObservationWindow: durationFromInterval(row.AttrInt64("lockOutObservationWindow")),
+	}
+}
+
+// passwordSettingsPolicy builds a policy from the attributes of a PSO.
+func passwordSettingsPolicy(row *Row) *PasswordPolicy {
+	return &PasswordPolicy{
+		DN:                       row.AttrString("distinguishedName"),
+		Name:                     row.AttrString("name"),
+		Precedence:               row.AttrInt("msDS-PasswordSettingsPrecedence"),
+		MinLength:                row.AttrInt("msDS-MinimumPasswordLength"),
+		HistoryLength:            row.AttrInt("msDS-PasswordHistoryLength"),
+		ComplexityEnabled:        row.AttrBool("msDS-PasswordComplexityEnabled"),
+		ReversibleEncryption:     row.AttrBool("msDS-PasswordReversibleEncryptionEnabled"),
+		MaxAge:                   durationFromInterval(row.AttrInt64("msDS-MaximumPasswordAge")),
+		MinAge:                   durationFromInterval(row.AttrInt64("msDS-MinimumPasswordAge")),
+		LockoutThreshold:         row.AttrInt("msDS-LockoutThreshold"),
+		LockoutDuration:          durationFromInterval(row.AttrInt64("msDS-LockoutDuration")),
+		LockoutObservationWindow: durationFromInterval(row.AttrInt64("msDS-LockoutObservationWindow")),
+		AppliesTo:                row.AttrStringSlice("msDS-PSOAppliesTo"),
+	}
+}
+
+// durationFromInterval converts a relative time interval, expressed as a
+// negative number of 100 nanosecond intervals, to a duration. The "never"
+// sentinel, which is the most negative 64-bit integer, is returned as zero.
+func durationFromInterval(v int64) time.Duration {
+	if v == math.MinInt64 {
+		return 0
+	}
+	if v < 0 {
+		v = -v
+	}
+	return time.Duration(v) * 100
+}
+
+// PasswordPolicy retrieves the default password and lockout policy of the
+// domain.
+func (d *Domain) PasswordPolicy() (policy *PasswordPolicy, err error) {
+	dn, err := d.DN()
+	if err != nil {
+		return
+	}
+	rows, err := d.searchDNAll(dn, Query{Attributes: passwordPolicyAttrs, Scope: ScopeBase})
+	if err != nil {
+		return
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("unable to read password policy of \"%s\"", dn)
+	}
+	return domainPasswordPolicy(rows[0]), nil
+}
+
+// PasswordSettings enumerates the fine-grained password settings objects
+// (PSOs) defined in the domain's Password Settings Container.
+func (d *Domain) PasswordSettings() (policies []*PasswordPolicy, err error) {
+	dn, err := d.DN()
+	if err != nil {
+		return
+	}
+	rows, err := d.searchDNAll("CN=Password Settings Container,CN=System,"+dn, Query{
+		Filter:     "(objectClass=msDS-PasswordSettings)",
+		Attributes: passwordSettingsAttrs,
+		Scope:      ScopeOneLevel,
+	})
+	if err != nil {
+		return
+	}
+	for _, row := range rows {
+		policies = append(policies, passwordSettingsPolicy(row))
+	}
+	return
+}
+
+// EffectivePasswordPolicy returns the password policy that applies to the
+// user. If a PSO applies to the user, as reported by the constructed
+// msDS-ResultantPSO attribute, it is returned. Otherwise the default policy
+// of the user's domain is returned.
+//
+// Reading msDS-ResultantPSO requires permission to read the PSO. Without it
+// the domain policy is returned even when a PSO applies.
+func (u *User) EffectivePasswordPolicy() (policy *PasswordPolicy, err error) {
+	pso, dn, err := u.resultantPSO()
+	if err != nil {
+		return
+	}
+	if pso != "" {
+		rows, err := u.searchDNAll(pso, Query{Attributes: passwordSettingsAttrs, Scope: ScopeBase})
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) > 0 {
+			return passwordSettingsPolicy(rows[0]), nil
+		}
+	}
+	domain := domainDN(dn)
+	if domain == "" {
+		return nil, fmt.Errorf("unable to determine domain of \"%s\"", dn)
+	}
+	rows, err := u.searchDNAll(domain, Query{Attributes: passwordPolicyAttrs, Scope: ScopeBase})
+	if err != nil {
+		return
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("unable to read password policy of \"%s\"", domain)
+	}
+	return domainPasswordPolicy(rows[0]), nil
+}
+
+// resultantPSO returns the distinguished name of the PSO that applies to the
+// user, if any, along with the distinguished name of the user.
+func (u *User) resultantPSO() (pso, dn string, err error) {
+	u.m.Lock()
+	defer u.m.Unlock()
+	if u.closed() {
+		return "", "", ErrClosed
+	}
+	if dn, err = u.object.AttrString("distinguishedName"); err != nil {
+		return
+	}
+	if err = u.object.Pull("msDS-ResultantPSO"); err != nil {
+		return
+	}
+	pso, err = u.object.AttrString("msDS-ResultantPSO")
+	if errors.Is(err, api.ErrPropertyNotFound) {
+		return "", dn, nil
+	}
+	return
+}
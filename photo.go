@@ -0,0 +1,125 @@
+package adsi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/go-adsi/adsi/api"
+)
+
+// MaxThumbnailPhotoSize is the largest value, in bytes, that the schema
+// permits in the thumbnailPhoto attribute. Outlook and Microsoft 365 display
+// the photo at 96 by 96 pixels, for which a few kilobytes are enough.
+const MaxThumbnailPhotoSize = 100 * 1024
+
+var (
+	// ErrPhotoTooLarge is returned when a photo given to SetThumbnailPhoto
+	// or SetJPEGPhoto exceeds the maximum size and cannot be re-encoded to
+	// fit.
+	ErrPhotoTooLarge = errors.New("photo exceeds the maximum size")
+
+	// ErrInvalidPhoto is returned when a photo given to SetThumbnailPhoto or
+	// SetJPEGPhoto is not a JPEG image.
+	ErrInvalidPhoto = errors.New("photo is not a JPEG image")
+)
+
+// jpegSignature is the start of image marker that begins every JPEG file,
+// followed by the first byte of the next marker.
+var jpegSignature = []byte{0xFF, 0xD8, 0xFF}
+
+// PhotoOptions controls how SetThumbnailPhoto and SetJPEGPhoto write a
+// photo.
+type PhotoOptions struct {
+	// MaxSize is the largest photo, in bytes, that is written. If zero
+	// MaxThumbnailPhotoSize is used.
+	MaxSize int
+
+	// Reencode is called with photos larger than MaxSize and returns a
+	// smaller JPEG encoding of the photo, such as one that is scaled down
+	// or saved at a lower quality. If nil, photos that are too large are
+	// rejected with ErrPhotoTooLarge, as are re-encoded photos that still
+	// do not fit.
+	Reencode func(photo []byte, maxSize int) ([]byte, error)
+}
+
+// ThumbnailPhoto returns the photo held in the thumbnailPhoto attribute of
+// the user, or nil if the user has no photo.
+func (u *User) ThumbnailPhoto() ([]byte, error) {
+	return u.photo("thumbnailPhoto")
+}
+
+// JPEGPhoto returns the first photo held in the jpegPhoto attribute of the
+// user, or nil if the user has no photo.
+func (u *User) JPEGPhoto() ([]byte, error) {
+	return u.photo("jpegPhoto")
+}
+
+// SetThumbnailPhoto replaces the thumbnailPhoto attribute of the user with
+// the given JPEG photo, after checking it against opts. If photo is empty
+// the attribute is cleared. The value must be commited with SetInfo to be
+// made persistent.
+func (u *User) SetThumbnailPhoto(photo []byte, opts PhotoOptions) error {
+	return u.setPhoto("thumbnailPhoto", photo, opts)
+}
+
+// SetJPEGPhoto replaces the jpegPhoto attribute of the user with the given
+// JPEG photo, after checking it against opts. If photo is empty the
+// attribute is cleared. The value must be commited with SetInfo to be made
+// persistent.
+func (u *User) SetJPEGPhoto(photo []byte, opts PhotoOptions) error {
+	return u.setPhoto("jpegPhoto", photo, opts)
+}
+
+func (u *User) photo(name string) (photo []byte, err error) {
+	u.m.Lock()
+	defer u.m.Unlock()
+	if u.closed() {
+		return nil, ErrClosed
+	}
+	photo, err = u.object.AttrBytes(name)
+	if errors.Is(err, api.ErrPropertyNotFound) {
+		return nil, nil
+	}
+	return
+}
+
+func (u *User) setPhoto(name string, photo []byte, opts PhotoOptions) error {
+	if len(photo) == 0 {
+		return u.PutEx(api.ADS_PROPERTY_CLEAR, name)
+	}
+	photo, err := checkPhoto(photo, opts)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return u.PutBytes(name, photo)
+}
+
+// checkPhoto returns the photo, re-encoded if it is too large and opts
+// provides a way to do so, or an error if it cannot be written.
+func checkPhoto(photo []byte, opts PhotoOptions) ([]byte, error) {
+	if !bytes.HasPrefix(photo, jpegSignature) {
+		return nil, ErrInvalidPhoto
+	}
+	limit := opts.MaxSize
+	if limit <= 0 {
+		limit = MaxThumbnailPhotoSize
+	}
+	if len(photo) <= limit {
+		return photo, nil
+	}
+	if opts.Reencode == nil {
+		return nil, fmt.Errorf("%w: %d bytes, limit %d", ErrPhotoTooLarge, len(photo), limit)
+	}
+	photo, err := opts.Reencode(photo, limit)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(photo, jpegSignature) {
+		return nil, ErrInvalidPhoto
+	}
+	if len(photo) > limit {
+		return nil, fmt.Errorf("%w: %d bytes after re-encoding, limit %d", ErrPhotoTooLarge, len(photo), limit)
+	}
+	return photo, nil
+}
@@ -0,0 +1,270 @@
+package adsi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-adsi/adsi/adspath"
+	"github.com/google/uuid"
+)
+
+// DefaultPollInterval is the interval used by Poll when PollOptions does not
+// specify one.
+const DefaultPollInterval = 30 * time.Second
+
+// pollAttrs are the attributes requested by every poller.
+var pollAttrs = []string{"distinguishedName", "objectGUID", "uSNCreated", "uSNChanged", "isDeleted"}
+
+// USNChange describes a change to an object found by a Poller.
+type USNChange struct {
+	DN   string
+	GUID uuid.UUID
+
+	// USNCreated and USNChanged are the update sequence numbers at which the
+	// object was created and last changed on the polled server.
+	USNCreated int64
+	USNChanged int64
+
+	// Created is true if the object was created since the previous poll.
+	Created bool
+
+	// Deleted is true if the object has been deleted. Deletions are only
+	// reported if PollOptions.Deleted is set.
+	Deleted bool
+
+	// Row holds the attributes of the object after the change, including
+	// those requested by PollOptions.Attributes.
+	Row *Row
+}
+
+// PollState records how far a poller has progressed on a particular domain
+// controller, so that polling can be resumed later. Update sequence numbers
+// are local to each domain controller, so a state is only meaningful for
+// the server it was recorded on.
+type PollState struct {
+	Server string
+	USN    int64
+}
+
+// PollOptions controls the objects and attributes reported by Poll.
+type PollOptions struct {
+	// Server is the domain controller to poll. If empty a domain controller
+	// of the computer's domain is chosen when polling starts and used
+	// thereafter.
+	Server string
+
+	// Interval is the time between polls. If zero DefaultPollInterval is
+	// used.
+	Interval time.Duration
+
+	// Filter is an LDAP filter that changed objects must also match, such as
+	// "(objectCategory=person)". If empty every changed object is reported.
+	Filter string
+
+	// Attributes lists additional attributes to return with each change.
+	Attributes []string
+
+	// Deleted includes deleted objects in the results.
+	Deleted bool
+
+	// Resume continues from a previously recorded state. It is ignored if
+	// it was recorded on a different server, in which case only changes
+	// made after polling starts are reported.
+	Resume *PollState
+}
+
+// Poller periodically searches for objects whose uSNChanged has advanced
+// since the previous poll. Unlike a Watcher it does not hold a search open
+// on the server, and it reports changes that were made while it was not
+// running when resumed from a saved state.
+type Poller struct {
+	// C receives a value for each changed object, in the order of their
+	// update sequence numbers. It is closed when the poller is closed or a
+	// poll fails, after which Err reports the cause.
+	C <-chan USNChange
+
+	client *Client
+	baseDN string
+	opts   PollOptions
+	done   chan struct{}
+	ended  chan struct{}
+	once   sync.Once
+
+	m     sync.Mutex
+	state PollState
+	err   error
+}
+
+// Poll starts polling the subtree beneath the object with the given
+// distinguished name for changes. Each poll is made with an ephemeral client,
+// using the security context of the application and the default client
+// flags.
+//
+// It is the caller's responsibility to call Close on the poller when it is
+// no longer needed.
+func Poll(baseDN string, opts PollOptions) (*Poller, error) {
+	return poll(nil, baseDN, opts)
+}
+
+// Poll starts polling the subtree beneath the object with the given
+// distinguished name for changes. Each poll is made through the client,
+// using the existing security context of the application and any flags
+// specified via SetFlags. A poll made after the client has been closed fails
+// and ends the poller.
+//
+// It is the caller's responsibility to call Close on the poller when it is
+// no longer needed.
+func (c *Client) Poll(baseDN string, opts PollOptions) (*Poller, error) {
+	return poll(c, baseDN, opts)
+}
+
+func poll(client *Client, baseDN string, opts PollOptions) (*Poller, error) {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultPollInterval
+	}
+	p := &Poller{client: client, baseDN: baseDN, opts: opts, done: make(chan struct{}), ended: make(chan struct{})}
+	root, err := p.readRootDSE(opts.Server)
+	if err != nil {
+		return nil, err
+	}
+	server := opts.Server
+	if server == "" {
+		// Stay on the same server, because update sequence numbers differ
+		// between domain controllers
+		server = root.DNSHostName
+	}
+	state := PollState{Server: server, USN: root.HighestCommittedUSN}
+	if r := opts.Resume; r != nil && r.Server != "" && strings.EqualFold(r.Server, server) {
+		state.USN = r.USN
+	}
+
+	c := make(chan USNChange)
+	p.C, p.state = c, state
+	go p.run(c)
+	return p, nil
+}
+
+// State returns the progress of the poller, which may be saved and passed
+// to PollOptions.Resume. Every change up to and including State().USN has
+// been delivered on C.
+func (p *Poller) State() PollState {
+	p.m.Lock()
+	defer p.m.Unlock()
+	return p.state
+}
+
+// Err returns the error that ended the poller, if any. It must only be
+// called after C has been closed.
+func (p *Poller) Err() error {
+	p.m.Lock()
+	defer p.m.Unlock()
+	return p.err
+}
+
+// Close stops the poller. C is closed once the poller has stopped.
+func (p *Poller) Close() error {
+	p.once.Do(func() {
+		close(p.done)
+		<-p.ended
+	})
+	return nil
+}
+
+// run polls until the poller is closed or a poll fails.
+func (p *Poller) run(c chan<- USNChange) {
+	defer close(p.ended)
+	defer close(c)
+	for {
+		if err := p.poll(c); err != nil {
+			p.m.Lock()
+			p.err = err
+			p.m.Unlock()
+			return
+		}
+		select {
+		case <-p.done:
+			return
+		case <-time.After(p.opts.Interval):
+		}
+	}
+}
+
+// poll reports the changes made since the previous poll. If the poller is
+// closed while changes are being delivered the state is only advanced to
+// the last change delivered.
+func (p *Poller) poll(c chan<- USNChange) error {
+	state := p.State()
+	root, err := p.readRootDSE(state.Server)
+	if err != nil {
+		return err
+	}
+	high := root.HighestCommittedUSN
+	if high <= state.USN {
+		return nil
+	}
+
+	filter := fmt.Sprintf("(&(uSNChanged>=%d)(uSNChanged<=%d))", state.USN+1, high)
+	if p.opts.Filter != "" {
+		filter = fmt.Sprintf("(&(uSNChanged>=%d)(uSNChanged<=%d)%s)", state.USN+1, high, p.opts.Filter)
+	}
+	path := (&adspath.Path{Scheme: "LDAP", Host: state.Server, Path: adspath.EscapeDN(p.baseDN)}).String()
+	result, err := p.search(path, Query{
+		Filter:     filter,
+		Attributes: append(append([]string(nil), pollAttrs...), p.opts.Attributes...),
+		Tombstone:  p.opts.Deleted,
+	})
+	if err != nil {
+		return err
+	}
+	rows, err := result.All()
+	result.Close()
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].AttrInt64("uSNChanged") < rows[j].AttrInt64("uSNChanged") })
+
+	for _, row := range rows {
+		change := USNChange{
+			DN:         row.AttrString("distinguishedName"),
+			GUID:       guidFromWindowsBytes(row.AttrBytes("objectGUID")),
+			USNCreated: row.AttrInt64("uSNCreated"),
+			USNChanged: row.AttrInt64("uSNChanged"),
+			Deleted:    row.AttrBool("isDeleted"),
+			Row:        row,
+		}
+		change.Created = change.USNCreated > state.USN
+		select {
+		case c <- change:
+		case <-p.done:
+			return nil
+		}
+		p.m.Lock()
+		p.state.USN = change.USNChanged
+		p.m.Unlock()
+	}
+	p.m.Lock()
+	p.state.USN = high
+	p.m.Unlock()
+	return nil
+}
+
+// readRootDSE reads the rootDSE of the given server through the client of
+// the poller, or through an ephemeral client if it has none.
+func (p *Poller) readRootDSE(server string) (*RootDSE, error) {
+	if p.client == nil {
+		return ReadRootDSE(server)
+	}
+	return p.client.ReadRootDSE(server)
+}
+
+// search executes the given query through the client of the poller, or
+// through an ephemeral client if it has none.
+func (p *Poller) search(path string, q Query) (*SearchResult, error) {
+	if p.client == nil {
+		return Search(path, q)
+	}
+	return p.client.Search(path, q)
+}
@@ -0,0 +1,108 @@
+package adsi
+
+import "fmt"
+
+// PrimaryGroupSID returns the security identifier of the object's primary
+// group, which is derived from its primaryGroupID attribute. If the object
+// has no primary group, such as when it is itself a group, ok is false.
+func (o *object) PrimaryGroupSID() (sid SID, ok bool, err error) {
+	return o.primaryGroupSID()
+}
+
+// PrimaryGroup opens the object's primary group. If the object has no
+// primary group a nil group and a nil error are returned.
+//
+// The group is bound by SID on the same server as the object. The returned
+// group consumes resources until it is closed. It is the caller's
+// responsibility to call Close on the returned group when it is no longer
+// needed.
+func (o *object) PrimaryGroup() (group *Group, err error) {
+	path, ok, err := o.primaryGroupPath()
+	if err != nil || !ok {
+		return
+	}
+	return o.openGroup(path)
+}
+
+// PrimaryGroupToken retrieves the relative identifier of the group, which is
+// the value that members holding it as their primary group store in their
+// primaryGroupID attribute.
+//
+// The primaryGroupToken attribute is constructed, so it is retrieved from the
+// server with Pull before it is read.
+func (g *Group) PrimaryGroupToken() (token uint32, err error) {
+	g.m.Lock()
+	defer g.m.Unlock()
+	if g.closed() {
+		return 0, ErrClosed
+	}
+	if err = g.object.Pull("primaryGroupToken"); err != nil {
+		return
+	}
+	value, err := g.object.AttrInt("primaryGroupToken")
+	if err != nil {
+		return
+	}
+	return uint32(value), nil
+}
+
+// IsPrimaryFor returns true if the group is the primary group of u.
+func (g *Group) IsPrimaryFor(u *User) (bool, error) {
+	token, err := g.PrimaryGroupToken()
+	if err != nil {
+		return false, err
+	}
+	rid, err := u.primaryGroupID()
+	if err != nil {
+		return false, err
+	}
+	return rid == token, nil
+}
+
+// SetAsPrimaryFor makes the group the primary group of u.
+//
+// Active Directory requires an object to be a member of a group before the
+// group can become its primary group. If u is not already a member of the
+// group it is added first. The primaryGroupID attribute of u is then updated
+// and committed with SetInfo, which also commits any other changes pending in
+// the attribute cache of u.
+//
+// The previous primary group is not removed from the membership of u; it is
+// converted to an ordinary membership by the server.
+func (g *Group) SetAsPrimaryFor(u *User) error {
+	path, err := u.Path()
+	if err != nil {
+		return err
+	}
+	member, err := g.IsMember(path)
+	if err != nil {
+		return err
+	}
+	if !member {
+		if err = g.Add(path); err != nil {
+			return fmt.Errorf("unable to add member before setting primary group: %w", err)
+		}
+	}
+	token, err := g.PrimaryGroupToken()
+	if err != nil {
+		return err
+	}
+	if err = u.PutInt("primaryGroupID", int(token)); err != nil {
+		return err
+	}
+	return u.SetInfo()
+}
+
+// primaryGroupID retrieves the primaryGroupID attribute of the user.
+func (u *User) primaryGroupID() (rid uint32, err error) {
+	u.m.Lock()
+	defer u.m.Unlock()
+	if u.closed() {
+		return 0, ErrClosed
+	}
+	value, err := u.object.AttrInt("primaryGroupID")
+	if err != nil {
+		return
+	}
+	return uint32(value), nil
+}
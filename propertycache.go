@@ -0,0 +1,86 @@
+package adsi
+
+import (
+	"strings"
+	"sync"
+)
+
+// ADSI loads the values of every attribute of an object into its property
+// cache the first time an attribute that is not in the cache is read. That
+// implicit GetInfo call can be expensive for objects with large attributes,
+// and because it also replaces values that have been put but not yet saved,
+// it can silently undo changes in read-modify-write sequences. The functions
+// in this file let the caller decide when the cache is loaded instead.
+
+// propertyCache records which attributes have been loaded into the property
+// cache of an object on which NoImplicitGetInfo has been called.
+type propertyCache struct {
+	m        sync.Mutex
+	explicit bool
+	full     bool
+	loaded   map[string]bool
+}
+
+// check returns ErrNotLoaded if implicit loading is disabled and the
+// attribute with the given name has not been loaded.
+func (c *propertyCache) check(name string) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if !c.explicit || c.full || c.loaded[strings.ToLower(name)] {
+		return nil
+	}
+	return ErrNotLoaded
+}
+
+// add records that the attributes with the given names have been loaded.
+func (c *propertyCache) add(names ...string) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if !c.explicit {
+		return
+	}
+	if c.loaded == nil {
+		c.loaded = make(map[string]bool)
+	}
+	for _, name := range names {
+		c.loaded[strings.ToLower(name)] = true
+	}
+}
+
+// NoImplicitGetInfo prevents ADSI from loading every attribute of the object
+// when an attribute that is not in its property cache is read. Once it has
+// been called, attributes can only be read after they have been loaded with
+// Pull or Refresh, or set with one of the Put functions. Reading any other
+// attribute returns ErrNotLoaded.
+func (o *object) NoImplicitGetInfo() {
+	o.cache.m.Lock()
+	defer o.cache.m.Unlock()
+	o.cache.explicit = true
+}
+
+// Refresh loads the values of all of the object's attributes into its
+// property cache, replacing any cached values. Changes that have been put
+// but not yet saved with SetInfo are discarded.
+//
+// Constructed attributes are not loaded by Refresh and must be retrieved
+// with Pull.
+func (o *object) Refresh() error {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return ErrClosed
+	}
+	var err error
+	o.h.run(func() {
+		defer beginCall()()
+		err = o.err("GetInfo", o.iface.GetInfo())
+	})
+	if err != nil {
+		return err
+	}
+	o.pending = nil
+	o.cache.m.Lock()
+	o.cache.full = true
+	o.cache.m.Unlock()
+	return nil
+}
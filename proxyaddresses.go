@@ -0,0 +1,190 @@
+package adsi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-adsi/adsi/api"
+)
+
+// ErrInvalidProxyAddress is returned when a proxyAddresses value does not
+// take the form "prefix:address".
+var ErrInvalidProxyAddress = errors.New("invalid proxy address")
+
+// ProxyAddress is a single value of the proxyAddresses attribute, such as
+// "SMTP:jane@example.com".
+//
+// The prefix identifies the address type. An upper case prefix marks the
+// primary address of that type, while a lower case prefix marks a secondary
+// address.
+type ProxyAddress struct {
+	Prefix  string
+	Address string
+}
+
+// ParseProxyAddress parses a proxyAddresses value of the form
+// "prefix:address".
+func ParseProxyAddress(s string) (addr ProxyAddress, err error) {
+	i := strings.IndexByte(s, ':')
+	if i <= 0 || i == len(s)-1 {
+		return addr, fmt.Errorf("%w: %q", ErrInvalidProxyAddress, s)
+	}
+	return ProxyAddress{Prefix: s[:i], Address: s[i+1:]}, nil
+}
+
+// Type returns the address type in upper case, such as "SMTP" or "X500".
+func (a ProxyAddress) Type() string {
+	return strings.ToUpper(a.Prefix)
+}
+
+// IsPrimary returns true if the address is the primary address of its type.
+func (a ProxyAddress) IsPrimary() bool {
+	return a.Prefix != "" && a.Prefix == strings.ToUpper(a.Prefix) && a.Prefix != strings.ToLower(a.Prefix)
+}
+
+// String returns the address in "prefix:address" form.
+func (a ProxyAddress) String() string {
+	return a.Prefix + ":" + a.Address
+}
+
+// matches returns true if the address has the given type and address. Address
+// comparison is case-insensitive.
+func (a ProxyAddress) matches(typ, address string) bool {
+	return strings.EqualFold(a.Prefix, typ) && strings.EqualFold(a.Address, address)
+}
+
+// ProxyAddresses is the decoded set of values held by the proxyAddresses
+// attribute of a mail-enabled object.
+type ProxyAddresses []ProxyAddress
+
+// ParseProxyAddresses parses a set of proxyAddresses values.
+func ParseProxyAddresses(values []string) (addrs ProxyAddresses, err error) {
+	for _, value := range values {
+		addr, err := ParseProxyAddress(value)
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, addr)
+	}
+	return
+}
+
+// Strings returns the addresses in "prefix:address" form, suitable for
+// storage in the proxyAddresses attribute.
+func (p ProxyAddresses) Strings() []string {
+	values := make([]string, len(p))
+	for i := range p {
+		values[i] = p[i].String()
+	}
+	return values
+}
+
+// Primary returns the primary address of the given type, such as "SMTP".
+func (p ProxyAddresses) Primary(typ string) (addr ProxyAddress, ok bool) {
+	for _, addr := range p {
+		if addr.IsPrimary() && strings.EqualFold(addr.Prefix, typ) {
+			return addr, true
+		}
+	}
+	return ProxyAddress{}, false
+}
+
+// OfType returns all addresses of the given type.
+func (p ProxyAddresses) OfType(typ string) (addrs ProxyAddresses) {
+	for _, addr := range p {
+		if strings.EqualFold(addr.Prefix, typ) {
+			addrs = append(addrs, addr)
+		}
+	}
+	return
+}
+
+// Contains returns true if the set contains an address with the given type
+// and address, regardless of whether it is primary.
+func (p ProxyAddresses) Contains(typ, address string) bool {
+	return p.index(typ, address) >= 0
+}
+
+// Add adds a secondary address of the given type. If the address is already
+// present the set is left unchanged.
+func (p *ProxyAddresses) Add(typ, address string) {
+	if p.Contains(typ, address) {
+		return
+	}
+	*p = append(*p, ProxyAddress{Prefix: strings.ToLower(typ), Address: address})
+}
+
+// Remove removes the address with the given type and address. It returns
+// false if the address was not present.
+//
+// Removing the primary address of a type leaves the type without a primary
+// address, which Exchange will treat as an error. Call SetPrimary to promote
+// another address.
+func (p *ProxyAddresses) Remove(typ, address string) bool {
+	i := p.index(typ, address)
+	if i < 0 {
+		return false
+	}
+	*p = append((*p)[:i], (*p)[i+1:]...)
+	return true
+}
+
+// SetPrimary makes the given address the primary address of its type. The
+// existing primary address of that type, if any, is demoted to a secondary
+// address. If the address is not present it is added.
+func (p *ProxyAddresses) SetPrimary(typ, address string) {
+	found := false
+	for i := range *p {
+		addr := &(*p)[i]
+		if !strings.EqualFold(addr.Prefix, typ) {
+			continue
+		}
+		if strings.EqualFold(addr.Address, address) {
+			addr.Prefix = strings.ToUpper(typ)
+			found = true
+		} else {
+			addr.Prefix = strings.ToLower(typ)
+		}
+	}
+	if !found {
+		*p = append(*p, ProxyAddress{Prefix: strings.ToUpper(typ), Address: address})
+	}
+}
+
+func (p ProxyAddresses) index(typ, address string) int {
+	for i := range p {
+		if p[i].matches(typ, address) {
+			return i
+		}
+	}
+	return -1
+}
+
+// ProxyAddresses retrieves and parses the proxyAddresses attribute of the
+// object.
+func (o *object) ProxyAddresses() (addrs ProxyAddresses, err error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return nil, ErrClosed
+	}
+	values, err := o.AttrStringSlice("proxyAddresses")
+	if errors.Is(err, api.ErrPropertyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return
+	}
+	return ParseProxyAddresses(values)
+}
+
+// SetProxyAddresses replaces the proxyAddresses attribute of the object with
+// the given set of addresses. If the set is empty the attribute is cleared.
+// The value must be commited with SetInfo to be made persistent.
+func (o *object) SetProxyAddresses(addrs ProxyAddresses) error {
+	if len(addrs) == 0 {
+		return o.PutEx(api.ADS_PROPERTY_CLEAR, "proxyAddresses")
+	}
+	return o.PutEx(api.ADS_PROPERTY_UPDATE, "proxyAddresses", stringsToValues(addrs.Strings())...)
+}
@@ -0,0 +1,127 @@
+package adsi
+
+import "time"
+
+// Lifetimes that apply when the corresponding attributes of the directory
+// service object are not set.
+const (
+	// DefaultTombstoneLifetime is the tombstone lifetime of forests created
+	// on Windows Server 2003 SP1 or later.
+	DefaultTombstoneLifetime = 180 * 24 * time.Hour
+)
+
+// recycleBinFeature is the relative name of the Recycle Bin optional feature
+// within the directory service object.
+const recycleBinFeature = "CN=Recycle Bin Feature,CN=Optional Features,"
+
+// DeletedObjectLifetimes reports how long deleted objects are retained by
+// the forest.
+type DeletedObjectLifetimes struct {
+	// DeletedObjectLifetime is how long a deleted object remains restorable
+	// with all of its attributes before it is recycled. It is only
+	// meaningful when the Recycle Bin is enabled.
+	DeletedObjectLifetime time.Duration
+
+	// TombstoneLifetime is how long a recycled object or tombstone is kept
+	// before it is removed from the directory by garbage collection.
+	TombstoneLifetime time.Duration
+}
+
+// directoryServiceDN returns the distinguished name of the directory service
+// object, which holds forest-wide settings of the directory.
+func (f *Forest) directoryServiceDN() string {
+	return "CN=Directory Service,CN=Windows NT,CN=Services," + f.root.ConfigurationNamingContext
+}
+
+// DeletedObjectLifetimes reads the msDS-deletedObjectLifetime and
+// tombstoneLifetime settings of the forest. Settings that are not present
+// are reported with their effective defaults: the tombstone lifetime
+// defaults to DefaultTombstoneLifetime and the deleted object lifetime
+// defaults to the tombstone lifetime.
+func (f *Forest) DeletedObjectLifetimes() (lifetimes DeletedObjectLifetimes, err error) {
+	rows, err := f.search(f.directoryServiceDN(), Query{
+		Attributes: []string{"msDS-deletedObjectLifetime", "tombstoneLifetime"},
+		Scope:      ScopeBase,
+	})
+	if err != nil {
+		return
+	}
+	lifetimes.TombstoneLifetime = DefaultTombstoneLifetime
+	if len(rows) == 0 {
+		lifetimes.DeletedObjectLifetime = lifetimes.TombstoneLifetime
+		return
+	}
+	days := func(name string) time.Duration {
+		return time.Duration(rows[0].AttrInt64(name)) * 24 * time.Hour
+	}
+	if d := days("tombstoneLifetime"); d > 0 {
+		lifetimes.TombstoneLifetime = d
+	}
+	lifetimes.DeletedObjectLifetime = lifetimes.TombstoneLifetime
+	if d := days("msDS-deletedObjectLifetime"); d > 0 {
+		lifetimes.DeletedObjectLifetime = d
+	}
+	return
+}
+
+// RecycleBinEnabled returns true if the Recycle Bin optional feature has
+// been enabled for the forest.
+func (f *Forest) RecycleBinEnabled() (enabled bool, err error) {
+	rows, err := f.search(recycleBinFeature+f.directoryServiceDN(), Query{
+		Attributes: []string{"msDS-EnabledFeatureBL"},
+		Scope:      ScopeBase,
+	})
+	if err != nil {
+		if isNoSuchObject(err) {
+			return false, nil
+		}
+		return
+	}
+	return len(rows) > 0 && len(rows[0].AttrStringSlice("msDS-EnabledFeatureBL")) > 0, nil
+}
+
+// DeletedObjects returns the objects of the domain that have been deleted
+// but not yet removed from the directory.
+//
+// ADSI searches can only request the show deleted control, so objects that
+// have been recycled after the Recycle Bin was enabled are not visible.
+// Tombstones of objects deleted before it was enabled are returned with
+// Recycled set.
+func (d *Domain) DeletedObjects() ([]*DeletedObject, error) {
+	return d.deletedObjects("")
+}
+
+// DeletedObjectsFrom returns the deleted objects of the domain whose last
+// known parent is the container with the given distinguished name. This is
+// typically used to find the contents of an organizational unit that was
+// deleted along with its children.
+func (d *Domain) DeletedObjectsFrom(parentDN string) ([]*DeletedObject, error) {
+	return d.deletedObjects("(lastKnownParent=" + EscapeFilter(parentDN) + ")")
+}
+
+// RestorableObjects returns the deleted objects of the domain that have not
+// been recycled and can be restored with Restore.
+func (d *Domain) RestorableObjects() ([]*DeletedObject, error) {
+	return d.deletedObjects("(!(isRecycled=TRUE))")
+}
+
+func (d *Domain) deletedObjects(filter string) (objs []*DeletedObject, err error) {
+	domain, err := d.DN()
+	if err != nil {
+		return
+	}
+	// The Deleted Objects container cannot be bound to directly, so the
+	// search is rooted at the domain
+	rows, err := d.searchDNAll(domain, Query{
+		Filter:     "(&(isDeleted=TRUE)" + filter + ")",
+		Attributes: deletedObjectAttrs,
+		Tombstone:  true,
+	})
+	if err != nil {
+		return
+	}
+	for _, row := range rows {
+		objs = append(objs, deletedObjectFromRow(row))
+	}
+	return
+}
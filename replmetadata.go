@@ -0,0 +1,252 @@
+package adsi
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The constructed msDS-ReplAttributeMetaData and msDS-ReplValueMetaData
+// attributes hold the replication metadata of an object, with one XML
+// document per attribute or linked value. The metadata records the last
+// originating write of each attribute, which is the change made by a client
+// rather than one received through replication, and so answers the question
+// of who changed an attribute, where and when.
+//
+// See https://learn.microsoft.com/windows/win32/adschema/a-msds-replattributemetadata
+
+// AttributeMetadata is the replication metadata of an attribute of an object.
+type AttributeMetadata struct {
+	// Attribute is the LDAP display name of the attribute.
+	Attribute string
+
+	// Version is incremented by each originating write of the attribute.
+	Version int
+
+	// LastOriginatingChange is the time of the last originating write.
+	LastOriginatingChange time.Time
+
+	// OriginatingInvocationID identifies the database of the domain
+	// controller on which the last originating write was made.
+	OriginatingInvocationID uuid.UUID
+
+	// OriginatingServer is the distinguished name of the NTDS settings object
+	// of the domain controller on which the last originating write was made.
+	// It is empty if the domain controller has since been removed.
+	OriginatingServer string
+
+	// OriginatingUSN is the update sequence number of the last originating
+	// write on the originating domain controller, and LocalUSN the update
+	// sequence number at which the write was applied on the domain
+	// controller that was queried.
+	OriginatingUSN int64
+	LocalUSN       int64
+}
+
+// ValueMetadata is the replication metadata of a value of a linked attribute
+// of an object, such as a member of a group. Linked attributes replicate each
+// of their values separately once the forest functional level is Windows
+// Server 2003 or higher. Values written earlier have no metadata of their
+// own and are reported with a zero Created time.
+type ValueMetadata struct {
+	// Attribute is the LDAP display name of the linked attribute.
+	Attribute string
+
+	// Object is the distinguished name of the object the value refers to.
+	Object string
+
+	// Created is the time at which the value was added.
+	Created time.Time
+
+	// Deleted is the time at which the value was removed, or the zero time
+	// if the value is present. Removed values are retained until the
+	// tombstone lifetime of the forest has elapsed.
+	Deleted time.Time
+
+	Version                 int
+	LastOriginatingChange   time.Time
+	OriginatingInvocationID uuid.UUID
+	OriginatingServer       string
+	OriginatingUSN          int64
+	LocalUSN                int64
+}
+
+// Present returns true if the value has not been removed.
+func (m *ValueMetadata) Present() bool {
+	return m.Deleted.IsZero()
+}
+
+// ParseAttributeMetadata parses a value of the msDS-ReplAttributeMetaData
+// attribute, such as one returned by a search.
+func ParseAttributeMetadata(s string) (m AttributeMetadata, err error) {
+	var doc struct {
+		Name                  string `xml:"pszAttributeName"`
+		Version               int    `xml:"dwVersion"`
+		LastOriginatingChange string `xml:"ftimeLastOriginatingChange"`
+		InvocationID          string `xml:"uuidLastOriginatingDsaInvocationID"`
+		OriginatingUSN        int64  `xml:"usnOriginatingChange"`
+		LocalUSN              int64  `xml:"usnLocalChange"`
+		OriginatingServer     string `xml:"pszLastOriginatingDsaDN"`
+	}
+	if err = unmarshalMetadata(s, "DS_REPL_ATTR_META_DATA", &doc); err != nil {
+		return
+	}
+	d := metadataDecoder{}
+	m = AttributeMetadata{
+		Attribute:               doc.Name,
+		Version:                 doc.Version,
+		LastOriginatingChange:   d.time(doc.LastOriginatingChange),
+		OriginatingInvocationID: d.uuid(doc.InvocationID),
+		OriginatingServer:       doc.OriginatingServer,
+		OriginatingUSN:          doc.OriginatingUSN,
+		LocalUSN:                doc.LocalUSN,
+	}
+	return m, d.err
+}
+
+// ParseValueMetadata parses a value of the msDS-ReplValueMetaData attribute,
+// such as one returned by a search.
+func ParseValueMetadata(s string) (m ValueMetadata, err error) {
+	var doc struct {
+		Name                  string `xml:"pszAttributeName"`
+		Object                string `xml:"pszObjectDn"`
+		Deleted               string `xml:"ftimeDeleted"`
+		Created               string `xml:"ftimeCreated"`
+		Version               int    `xml:"dwVersion"`
+		LastOriginatingChange string `xml:"ftimeLastOriginatingChange"`
+		InvocationID          string `xml:"uuidLastOriginatingDsaInvocationID"`
+		OriginatingUSN        int64  `xml:"usnOriginatingChange"`
+		LocalUSN              int64  `xml:"usnLocalChange"`
+		OriginatingServer     string `xml:"pszLastOriginatingDsaDN"`
+	}
+	if err = unmarshalMetadata(s, "DS_REPL_VALUE_META_DATA", &doc); err != nil {
+		return
+	}
+	d := metadataDecoder{}
+	m = ValueMetadata{
+		Attribute:               doc.Name,
+		Object:                  doc.Object,
+		Created:                 d.time(doc.Created),
+		Deleted:                 d.time(doc.Deleted),
+		Version:                 doc.Version,
+		LastOriginatingChange:   d.time(doc.LastOriginatingChange),
+		OriginatingInvocationID: d.uuid(doc.InvocationID),
+		OriginatingServer:       doc.OriginatingServer,
+		OriginatingUSN:          doc.OriginatingUSN,
+		LocalUSN:                doc.LocalUSN,
+	}
+	return m, d.err
+}
+
+// unmarshalMetadata decodes a replication metadata document whose root
+// element has the given name into v.
+func unmarshalMetadata(s, root string, v interface{}) error {
+	// Domain controllers terminate each document with a null character,
+	// which is not valid XML.
+	s = strings.TrimRight(s, "\x00\r\n\t ")
+	var start xml.StartElement
+	d := xml.NewDecoder(strings.NewReader(s))
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return fmt.Errorf("invalid replication metadata: %w", err)
+		}
+		if element, ok := token.(xml.StartElement); ok {
+			start = element
+			break
+		}
+	}
+	if start.Name.Local != root {
+		return fmt.Errorf("invalid replication metadata: unexpected element %s", start.Name.Local)
+	}
+	if err := d.DecodeElement(v, &start); err != nil {
+		return fmt.Errorf("invalid replication metadata: %w", err)
+	}
+	return nil
+}
+
+// metadataDecoder converts the text of metadata elements, recording the
+// first error encountered.
+type metadataDecoder struct {
+	err error
+}
+
+// time parses a time in the form "2006-01-02T15:04:05Z". The start of the
+// Windows file time epoch, which the metadata uses for times that are not
+// set, is returned as the zero time.
+func (d *metadataDecoder) time(s string) time.Time {
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		d.fail(err)
+		return time.Time{}
+	}
+	if t.Year() <= 1601 {
+		return time.Time{}
+	}
+	return t
+}
+
+// uuid parses a GUID in its string form.
+func (d *metadataDecoder) uuid(s string) uuid.UUID {
+	if s == "" {
+		return uuid.Nil
+	}
+	id, err := uuid.Parse(s)
+	if err != nil {
+		d.fail(err)
+	}
+	return id
+}
+
+func (d *metadataDecoder) fail(err error) {
+	if d.err == nil {
+		d.err = fmt.Errorf("invalid replication metadata: %w", err)
+	}
+}
+
+// AttributeMetadata retrieves the replication metadata of every attribute
+// of the object that has a value or has had one, from the constructed
+// msDS-ReplAttributeMetaData attribute.
+func (o *object) AttributeMetadata() (metadata []AttributeMetadata, err error) {
+	values, err := o.pullStringSlice("msDS-ReplAttributeMetaData")
+	if err != nil {
+		return
+	}
+	for _, value := range values {
+		m, err := ParseAttributeMetadata(value)
+		if err != nil {
+			return nil, err
+		}
+		metadata = append(metadata, m)
+	}
+	return
+}
+
+// ValueMetadata retrieves the replication metadata of the values of the
+// linked attributes of the object, from the constructed msDS-ReplValueMetaData
+// attribute. The metadata of removed values is included until they are
+// garbage collected.
+//
+// Domain controllers return at most MaxValRange values of an attribute in a
+// single request, which is 1500 by default, or 5000 on Windows Server 2008 or
+// later. Search with range retrieval to read the metadata of larger groups.
+func (o *object) ValueMetadata() (metadata []ValueMetadata, err error) {
+	values, err := o.pullStringSlice("msDS-ReplValueMetaData")
+	if err != nil {
+		return
+	}
+	for _, value := range values {
+		m, err := ParseValueMetadata(value)
+		if err != nil {
+			return nil, err
+		}
+		metadata = append(metadata, m)
+	}
+	return
+}
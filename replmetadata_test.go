@@ -0,0 +1,140 @@
+package adsi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	testInvocationID = "4a3b2c1d-0e9f-4a8b-9c7d-6e5f4a3b2c1d"
+	testDSA          = "CN=NTDS Settings,CN=DC1,CN=Servers,CN=Default-First-Site-Name,CN=Sites,CN=Configuration,DC=example,DC=com"
+)
+
+func TestParseAttributeMetadata(t *testing.T) {
+	doc := "<DS_REPL_ATTR_META_DATA>\n" +
+		"\t<pszAttributeName>description</pszAttributeName>\n" +
+		"\t<dwVersion>3</dwVersion>\n" +
+		"\t<ftimeLastOriginatingChange>2024-03-01T12:30:45Z</ftimeLastOriginatingChange>\n" +
+		"\t<uuidLastOriginatingDsaInvocationID>" + testInvocationID + "</uuidLastOriginatingDsaInvocationID>\n" +
+		"\t<usnOriginatingChange>123456</usnOriginatingChange>\n" +
+		"\t<usnLocalChange>123789</usnLocalChange>\n" +
+		"\t<pszLastOriginatingDsaDN>" + testDSA + "</pszLastOriginatingDsaDN>\n" +
+		"</DS_REPL_ATTR_META_DATA>\n"
+	want := AttributeMetadata{
+		Attribute:               "description",
+		Version:                 3,
+		LastOriginatingChange:   time.Date(2024, 3, 1, 12, 30, 45, 0, time.UTC),
+		OriginatingInvocationID: uuid.MustParse(testInvocationID),
+		OriginatingServer:       testDSA,
+		OriginatingUSN:          123456,
+		LocalUSN:                123789,
+	}
+	tests := []struct {
+		name string
+		in   string
+		want AttributeMetadata
+	}{
+		{"document", doc, want},
+		{"null terminated", doc + "\x00", want},
+		{"removed server", strings.Replace(doc, testDSA, "", 1), func() AttributeMetadata {
+			m := want
+			m.OriginatingServer = ""
+			return m
+		}()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAttributeMetadata(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseAttributeMetadata() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseValueMetadata(t *testing.T) {
+	doc := func(created, deleted string) string {
+		return "<DS_REPL_VALUE_META_DATA>\n" +
+			"\t<pszAttributeName>member</pszAttributeName>\n" +
+			"\t<pszObjectDn>CN=Jane Doe,OU=Staff,DC=example,DC=com</pszObjectDn>\n" +
+			"\t<cbData>0</cbData>\n" +
+			"\t<pbData></pbData>\n" +
+			"\t<ftimeDeleted>" + deleted + "</ftimeDeleted>\n" +
+			"\t<ftimeCreated>" + created + "</ftimeCreated>\n" +
+			"\t<dwVersion>1</dwVersion>\n" +
+			"\t<ftimeLastOriginatingChange>2024-03-01T12:30:45Z</ftimeLastOriginatingChange>\n" +
+			"\t<uuidLastOriginatingDsaInvocationID>" + testInvocationID + "</uuidLastOriginatingDsaInvocationID>\n" +
+			"\t<usnOriginatingChange>2000</usnOriginatingChange>\n" +
+			"\t<usnLocalChange>2001</usnLocalChange>\n" +
+			"\t<pszLastOriginatingDsaDN>" + testDSA + "</pszLastOriginatingDsaDN>\n" +
+			"</DS_REPL_VALUE_META_DATA>\x00"
+	}
+	changed := time.Date(2024, 3, 1, 12, 30, 45, 0, time.UTC)
+	tests := []struct {
+		name             string
+		created, deleted string
+		wantCreated      time.Time
+		wantDeleted      time.Time
+	}{
+		{"present", "2024-02-01T08:00:00Z", "1601-01-01T00:00:00Z", time.Date(2024, 2, 1, 8, 0, 0, 0, time.UTC), time.Time{}},
+		{"removed", "2024-02-01T08:00:00Z", "2024-03-01T12:30:45Z", time.Date(2024, 2, 1, 8, 0, 0, 0, time.UTC), changed},
+		{"legacy value", "1601-01-01T00:00:00Z", "1601-01-01T00:00:00Z", time.Time{}, time.Time{}},
+		{"empty times", "", "", time.Time{}, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseValueMetadata(doc(tt.created, tt.deleted))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := ValueMetadata{
+				Attribute:               "member",
+				Object:                  "CN=Jane Doe,OU=Staff,DC=example,DC=com",
+				Created:                 tt.wantCreated,
+				Deleted:                 tt.wantDeleted,
+				Version:                 1,
+				LastOriginatingChange:   changed,
+				OriginatingInvocationID: uuid.MustParse(testInvocationID),
+				OriginatingServer:       testDSA,
+				OriginatingUSN:          2000,
+				LocalUSN:                2001,
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ParseValueMetadata() = %+v, want %+v", got, want)
+			}
+			if got.Present() != tt.wantDeleted.IsZero() {
+				t.Errorf("Present() = %v", got.Present())
+			}
+		})
+	}
+}
+
+func TestParseReplicationMetadataInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"empty", ""},
+		{"not xml", "description"},
+		{"wrong root", "<DS_REPL_VALUE_META_DATA><pszAttributeName>member</pszAttributeName></DS_REPL_VALUE_META_DATA>"},
+		{"unterminated", "<DS_REPL_ATTR_META_DATA><pszAttributeName>description"},
+		{"invalid version", "<DS_REPL_ATTR_META_DATA><dwVersion>three</dwVersion></DS_REPL_ATTR_META_DATA>"},
+		{"invalid time", "<DS_REPL_ATTR_META_DATA><ftimeLastOriginatingChange>yesterday</ftimeLastOriginatingChange></DS_REPL_ATTR_META_DATA>"},
+		{"invalid invocation id", "<DS_REPL_ATTR_META_DATA><uuidLastOriginatingDsaInvocationID>dc1</uuidLastOriginatingDsaInvocationID></DS_REPL_ATTR_META_DATA>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := ParseAttributeMetadata(tt.in)
+			if err == nil || !strings.HasPrefix(err.Error(), "invalid replication metadata") {
+				t.Errorf("ParseAttributeMetadata() = %+v, %v, want an error", m, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package adsi
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-adsi/adsi/adspath"
+	"github.com/go-adsi/adsi/api"
+)
+
+// DN retrieves the distinguished name of the object.
+func (o *object) DN() (dn string, err error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return "", ErrClosed
+	}
+	return o.AttrString("distinguishedName")
+}
+
+// OpenDN opens the object with the given distinguished name using the same
+// namespace and server as this object. The connection is made through the
+// client that opened this object, using the same credentials and flags.
+//
+// The returned object consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned object when it is no
+// longer needed.
+func (o *object) OpenDN(dn string) (obj *Object, err error) {
+	path, err := o.pathForDN(dn)
+	if err != nil {
+		return
+	}
+	return o.h.open(path)
+}
+
+// SearchDN executes the given query against the directory, rooted at the
+// object with the given distinguished name on the same namespace and server
+// as this object. The connection is made in the same way as OpenDN.
+//
+// The returned result set consumes resources until it is closed. It is the
+// caller's responsibility to call Close on the result set when it is no
+// longer needed.
+func (o *object) SearchDN(dn string, q Query) (result *SearchResult, err error) {
+	path, err := o.pathForDN(dn)
+	if err != nil {
+		return
+	}
+	return o.h.search(path, q)
+}
+
+// searchDNAll executes the given query with SearchDN and returns all of the
+// resulting rows.
+func (o *object) searchDNAll(dn string, q Query) (rows []*Row, err error) {
+	result, err := o.SearchDN(dn, q)
+	if err != nil {
+		return
+	}
+	defer result.Close()
+	return result.All()
+}
+
+// ResolveDN reads the distinguished name held by the given attribute, such as
+// manager, managedBy or homeMDB, and opens the object it refers to with
+// OpenDN. If the attribute holds more than one value only the first is
+// resolved.
+//
+// If the attribute is not set a nil object and a nil error are returned.
+//
+// The returned object consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned object when it is no
+// longer needed.
+func (o *object) ResolveDN(attr string) (obj *Object, err error) {
+	dns, err := o.dnValues(attr)
+	if err != nil || len(dns) == 0 {
+		return nil, err
+	}
+	return o.OpenDN(dns[0])
+}
+
+// ResolveDNs reads the distinguished names held by the given multi-valued
+// attribute, such as member or directReports, and opens each of the objects
+// they refer to with OpenDN. If any object cannot be opened the objects that
+// were already opened are closed and an error is returned.
+//
+// The returned objects consume resources until they are closed. It is the
+// caller's responsibilty to call Close on each of the returned objects when
+// they are no longer needed.
+func (o *object) ResolveDNs(attr string) (objs []*Object, err error) {
+	dns, err := o.dnValues(attr)
+	if err != nil {
+		return nil, err
+	}
+	for _, dn := range dns {
+		obj, err := o.OpenDN(dn)
+		if err != nil {
+			for _, opened := range objs {
+				opened.Close()
+			}
+			return nil, err
+		}
+		objs = append(objs, obj)
+	}
+	return
+}
+
+// dnValues returns the string values of the given attribute. An attribute
+// that is not set is returned as an empty slice.
+func (o *object) dnValues(attr string) (dns []string, err error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return nil, ErrClosed
+	}
+	dns, err = o.AttrStringSlice(attr)
+	if errors.Is(err, api.ErrPropertyNotFound) {
+		return nil, nil
+	}
+	return
+}
+
+// pathForDN returns an ADsPath for the given distinguished name that uses the
+// namespace and server of this object.
+func (o *object) pathForDN(dn string) (path string, err error) {
+	return o.pathForDNOn("", dn)
+}
+
+// pathForDNOn returns an ADsPath for the given distinguished name that uses
+// the namespace of this object and the given server. If server is empty the
+// server of this object is used.
+func (o *object) pathForDNOn(server, dn string) (path string, err error) {
+	self, err := o.Path()
+	if err != nil {
+		return
+	}
+	p, err := adspath.Parse(self)
+	if err != nil {
+		return
+	}
+	if server == "" {
+		server = p.Host
+	}
+	return (&adspath.Path{Scheme: p.Scheme, Host: server, Path: adspath.EscapeDN(dn)}).String(), nil
+}
+
+// server returns the server named in the ADsPath of the object, which is
+// empty for serverless bindings.
+func (o *object) server() (server string, err error) {
+	self, err := o.Path()
+	if err != nil {
+		return
+	}
+	p, err := adspath.Parse(self)
+	if err != nil {
+		return
+	}
+	return p.Host, nil
+}
+
+// Row reads the given attributes of the object with a base-scope search and
+// returns them as a row of search results. If no attributes are given every
+// attribute the caller is permitted to read is returned. Unlike the Attr
+// methods the values are converted to native Go types in the same way as
+// search results.
+func (o *object) Row(attrs ...string) (*Row, error) {
+	return o.baseRow(Query{Attributes: attrs})
+}
+
+// baseRow executes the given query against the object itself with a base
+// scope and returns the resulting row. Unlike Pull, attributes that are
+// missing from the schema or not set on the object are simply absent from
+// the row.
+func (o *object) baseRow(q Query) (row *Row, err error) {
+	path, err := o.Path()
+	if err != nil {
+		return
+	}
+	q.Scope = ScopeBase
+	result, err := o.h.search(path, q)
+	if err != nil {
+		return
+	}
+	defer result.Close()
+	rows, err := result.All()
+	if err != nil {
+		return
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("unable to read \"%s\"", path)
+	}
+	return rows[0], nil
+}
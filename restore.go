@@ -0,0 +1,174 @@
+package adsi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-adsi/adsi/api"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrDeletedObjectNotFound is returned when a deleted object cannot be
+	// found in the Deleted Objects container.
+	ErrDeletedObjectNotFound = errors.New("deleted object not found")
+
+	// ErrParentDeleted is returned when a deleted object cannot be restored
+	// to its original location because its last known parent has also been
+	// deleted. The parent must be restored first.
+	ErrParentDeleted = errors.New("last known parent has been deleted")
+)
+
+// deletedDNMarker separates the original relative name of a deleted object
+// from its GUID in the mangled name given to it on deletion.
+const deletedDNMarker = `\0ADEL:`
+
+// DeletedObject describes an object that has been deleted and moved to the
+// Deleted Objects container of its naming context.
+type DeletedObject struct {
+	// DN is the mangled distinguished name of the object within the Deleted
+	// Objects container.
+	DN   string
+	GUID uuid.UUID
+
+	// Class is the most specific object class of the object.
+	Class string
+
+	// Name is the relative name the object had before it was deleted, taken
+	// from msDS-LastKnownRDN.
+	Name string
+
+	// LastKnownParent is the distinguished name of the container that held
+	// the object before it was deleted.
+	LastKnownParent string
+
+	// WhenDeleted is the time of the last change to the object, which for a
+	// deleted object is normally its deletion.
+	WhenDeleted time.Time
+
+	// Recycled is true if the object has been recycled, or was deleted
+	// before the Recycle Bin was enabled. Recycled objects have been stripped
+	// of most of their attributes and cannot be restored with their links.
+	Recycled bool
+}
+
+// OriginalDN returns the distinguished name the object had before it was
+// deleted, which is where RestoreToLastKnownParent will restore it.
+func (d *DeletedObject) OriginalDN() string {
+	rdnType := "CN"
+	if rdns := splitDN(d.DN); len(rdns) > 0 {
+		if i := strings.IndexByte(rdns[0], '='); i > 0 {
+			rdnType = rdns[0][:i]
+		}
+	}
+	return rdnType + "=" + escapeRDNValue(d.Name) + "," + d.LastKnownParent
+}
+
+// deletedObjectAttrs are the attributes read for each deleted object.
+var deletedObjectAttrs = []string{
+	"distinguishedName", "objectGUID", "objectClass", "msDS-LastKnownRDN",
+	"lastKnownParent", "whenChanged", "isRecycled",
+}
+
+func deletedObjectFromRow(row *Row) *DeletedObject {
+	d := &DeletedObject{
+		DN:              row.AttrString("distinguishedName"),
+		GUID:            guidFromWindowsBytes(row.AttrBytes("objectGUID")),
+		Name:            row.AttrString("msDS-LastKnownRDN"),
+		LastKnownParent: row.AttrString("lastKnownParent"),
+		WhenDeleted:     row.AttrTime("whenChanged"),
+		Recycled:        row.AttrBool("isRecycled"),
+	}
+	if classes := row.AttrStringSlice("objectClass"); len(classes) > 0 {
+		d.Class = classes[len(classes)-1]
+	}
+	return d
+}
+
+// DeletedObject looks up the deleted object with the given distinguished
+// name, which is the mangled name it was given when it was moved to the
+// Deleted Objects container.
+func (d *Domain) DeletedObject(dn string) (obj *DeletedObject, err error) {
+	domain, err := d.DN()
+	if err != nil {
+		return
+	}
+	rows, err := d.searchDNAll(domain, Query{
+		Filter:     "(&(isDeleted=TRUE)(distinguishedName=" + EscapeFilter(dn) + "))",
+		Attributes: deletedObjectAttrs,
+		Tombstone:  true,
+	})
+	if err != nil {
+		return
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrDeletedObjectNotFound, dn)
+	}
+	return deletedObjectFromRow(rows[0]), nil
+}
+
+// Restore restores the deleted object with the given distinguished name,
+// giving it the distinguished name newDN. The object's isDeleted attribute is
+// removed and it is moved out of the Deleted Objects container in a single
+// modification, which takes effect immediately.
+//
+// ADSI cannot address deleted objects, so the change is made through the
+// Windows LDAP client with the show deleted control, on the same server as
+// the domain. A domain opened through a client binds with the credentials it
+// was opened with, otherwise the credentials of the calling thread are used.
+// The restore is reported to the audit hook as AuditRestore. Objects that
+// have already been recycled cannot be restored. Attributes that were
+// stripped when the object was deleted are not recovered unless the Recycle
+// Bin was enabled at the time.
+func (d *Domain) Restore(deletedDN, newDN string) error {
+	server, err := d.server()
+	if err != nil {
+		return err
+	}
+	var user, password string
+	if d.h != nil {
+		user, password = d.h.user, d.h.password
+	}
+	source, _ := d.pathForDN(deletedDN)
+	start := time.Now()
+	err = api.LDAPModify(server, user, password, deletedDN, []api.LDAPModification{
+		{Op: api.LDAP_MOD_DELETE, Attr: "isDeleted"},
+		{Op: api.LDAP_MOD_REPLACE, Attr: "distinguishedName", Values: []string{newDN}},
+	}, []api.LDAPControl{
+		{OID: api.LDAP_SERVER_SHOW_DELETED_OID, Critical: true},
+	})
+	err = wrapLDAPError("Restore", source, err)
+	if d.h.auditing() {
+		path, _ := d.pathForDN(newDN)
+		d.h.write(start, AuditEvent{
+			Op:         AuditRestore,
+			Path:       path,
+			Source:     source,
+			Attributes: []string{"isDeleted", "distinguishedName"},
+			Err:        err,
+		})
+	}
+	return err
+}
+
+// RestoreToLastKnownParent restores the deleted object with the given
+// distinguished name to the container it was deleted from, under its
+// original name. It returns the distinguished name of the restored object.
+//
+// If the container has itself been deleted ErrParentDeleted is returned.
+func (d *Domain) RestoreToLastKnownParent(deletedDN string) (newDN string, err error) {
+	obj, err := d.DeletedObject(deletedDN)
+	if err != nil {
+		return
+	}
+	if strings.Contains(obj.LastKnownParent, deletedDNMarker) {
+		return "", fmt.Errorf("%w: %s", ErrParentDeleted, obj.LastKnownParent)
+	}
+	newDN = obj.OriginalDN()
+	if err = d.Restore(deletedDN, newDN); err != nil {
+		return "", err
+	}
+	return
+}
@@ -0,0 +1,152 @@
+package adsi
+
+import (
+	"strconv"
+
+	"github.com/go-adsi/adsi/adspath"
+)
+
+// RootDSE holds the operational attributes published by the rootDSE of a
+// directory server, which describe the server and the naming contexts it
+// holds.
+//
+// See https://learn.microsoft.com/windows/win32/adschema/rootdse
+type RootDSE struct {
+	// Server is the server that was queried, or empty if a serverless bind
+	// was used.
+	Server string
+
+	DefaultNamingContext       string
+	ConfigurationNamingContext string
+	SchemaNamingContext        string
+	RootDomainNamingContext    string
+	NamingContexts             []string
+
+	// DNSHostName is the DNS name of the domain controller that answered.
+	DNSHostName string
+
+	// ServerName is the distinguished name of the server object of the
+	// domain controller in the configuration naming context.
+	ServerName string
+
+	// DSServiceName is the distinguished name of the NTDS settings object of
+	// the domain controller.
+	DSServiceName string
+
+	DomainFunctionality           int
+	ForestFunctionality           int
+	DomainControllerFunctionality int
+
+	IsGlobalCatalogReady bool
+	IsSynchronized       bool
+	HighestCommittedUSN  int64
+
+	SupportedControls     []string
+	SupportedCapabilities []string
+}
+
+// ReadRootDSE reads the rootDSE of the given server. If server is empty the
+// rootDSE of a domain controller of the computer's domain is read. The
+// connection is made using the security context of the application and the
+// default client flags.
+func ReadRootDSE(server string) (root *RootDSE, err error) {
+	return readRootDSE(server, Open)
+}
+
+// ReadRootDSE reads the rootDSE of the given server. If server is empty the
+// rootDSE of a domain controller of the computer's domain is read. The
+// existing security context of the application and any flags specified via
+// SetFlags will be used when making the connection.
+func (c *Client) ReadRootDSE(server string) (root *RootDSE, err error) {
+	return readRootDSE(server, c.Open)
+}
+
+// readRootDSE reads the rootDSE of the given server, using open to bind to
+// it.
+func readRootDSE(server string, open func(path string) (*Object, error)) (root *RootDSE, err error) {
+	path := (&adspath.Path{Scheme: "LDAP", Host: server, Path: "RootDSE"}).String()
+	if server == "" {
+		path = "LDAP://RootDSE"
+	}
+	obj, err := open(path)
+	if err != nil {
+		return
+	}
+	defer obj.Close()
+
+	obj.m.Lock()
+	defer obj.m.Unlock()
+	if obj.closed() {
+		return nil, ErrClosed
+	}
+	str := func(name string) string {
+		v, _ := obj.AttrString(name)
+		return v
+	}
+	strs := func(name string) []string {
+		v, _ := obj.AttrStringSlice(name)
+		return v
+	}
+	num := func(name string) int64 {
+		v, _ := strconv.ParseInt(str(name), 10, 64)
+		return v
+	}
+	return &RootDSE{
+		Server:                        server,
+		DefaultNamingContext:          str("defaultNamingContext"),
+		ConfigurationNamingContext:    str("configurationNamingContext"),
+		SchemaNamingContext:           str("schemaNamingContext"),
+		RootDomainNamingContext:       str("rootDomainNamingContext"),
+		NamingContexts:                strs("namingContexts"),
+		DNSHostName:                   str("dnsHostName"),
+		ServerName:                    str("serverName"),
+		DSServiceName:                 str("dsServiceName"),
+		DomainFunctionality:           int(num("domainFunctionality")),
+		ForestFunctionality:           int(num("forestFunctionality")),
+		DomainControllerFunctionality: int(num("domainControllerFunctionality")),
+		IsGlobalCatalogReady:          str("isGlobalCatalogReady") == "TRUE",
+		IsSynchronized:                str("isSynchronized") == "TRUE",
+		HighestCommittedUSN:           num("highestCommittedUSN"),
+		SupportedControls:             strs("supportedControl"),
+		SupportedCapabilities:         strs("supportedCapabilities"),
+	}, nil
+}
+
+// path returns an LDAP ADsPath that binds to the object with the given
+// distinguished name on the server the rootDSE was read from.
+func (r *RootDSE) path(dn string) string {
+	return (&adspath.Path{Scheme: "LDAP", Host: r.Server, Path: adspath.EscapeDN(dn)}).String()
+}
+
+// OpenConfiguration opens the configuration naming context of the forest of
+// the computer, which holds the sites, partitions, extended rights and other
+// forest-wide settings of the directory. Its distinguished name is read from
+// the rootDSE.
+//
+// The returned container consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned container when it is
+// no longer needed.
+func (c *Client) OpenConfiguration() (container *Container, err error) {
+	return c.openNamingContext(func(root *RootDSE) string { return root.ConfigurationNamingContext })
+}
+
+// OpenSchema opens the schema naming context of the forest of the computer,
+// which holds the attributeSchema and classSchema objects that define the
+// directory. Its distinguished name is read from the rootDSE.
+//
+// The returned container consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned container when it is
+// no longer needed.
+func (c *Client) OpenSchema() (container *Container, err error) {
+	return c.openNamingContext(func(root *RootDSE) string { return root.SchemaNamingContext })
+}
+
+// openNamingContext opens the naming context that nc selects from the rootDSE
+// of a domain controller of the computer's domain.
+func (c *Client) openNamingContext(nc func(root *RootDSE) string) (container *Container, err error) {
+	root, err := c.ReadRootDSE("")
+	if err != nil {
+		return nil, err
+	}
+	return c.OpenContainer(root.path(nc(root)))
+}
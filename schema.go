@@ -0,0 +1,378 @@
+package adsi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ClassCategory is the value of the objectClassCategory attribute of a
+// classSchema object.
+type ClassCategory int
+
+// Class categories.
+const (
+	ClassCategory88         ClassCategory = 0
+	ClassCategoryStructural ClassCategory = 1
+	ClassCategoryAbstract   ClassCategory = 2
+	ClassCategoryAuxiliary  ClassCategory = 3
+)
+
+// String returns the name of the class category.
+func (c ClassCategory) String() string {
+	switch c {
+	case ClassCategory88:
+		return "88"
+	case ClassCategoryStructural:
+		return "structural"
+	case ClassCategoryAbstract:
+		return "abstract"
+	case ClassCategoryAuxiliary:
+		return "auxiliary"
+	}
+	return fmt.Sprintf("unknown class category %d", int(c))
+}
+
+// systemFlagsConstructed is the systemFlags bit that marks a constructed
+// attribute.
+const systemFlagsConstructed = 0x4
+
+// AttributeSchema describes an attribute defined in the schema, as held by an
+// attributeSchema object.
+type AttributeSchema struct {
+	// Name is the lDAPDisplayName of the attribute.
+	Name string
+
+	// CN is the common name of the attributeSchema object.
+	CN string
+	DN string
+
+	// OID is the attributeID of the attribute.
+	OID string
+
+	// Syntax is the attributeSyntax OID of the attribute, such as
+	// "2.5.5.12" for Unicode strings.
+	Syntax   string
+	OMSyntax int
+
+	SingleValued bool
+	SystemOnly   bool
+	Constructed  bool
+
+	// LinkID is non-zero for linked attributes. Forward links have even
+	// values and back links have odd values.
+	LinkID int
+
+	SearchFlags int
+	RangeLower  *int64
+	RangeUpper  *int64
+
+	// InGlobalCatalog is true if the attribute is replicated to the global
+	// catalog.
+	InGlobalCatalog bool
+
+	// SchemaIDGUID and AttributeSecurityGUID are converted from their
+	// binary layout so that their string form matches the one displayed by
+	// Windows tools and used by the appliesTo attribute of extended rights.
+	SchemaIDGUID          uuid.UUID
+	AttributeSecurityGUID uuid.UUID
+	Description           string
+}
+
+// IsLinked returns true if the attribute is a forward or back link.
+func (a *AttributeSchema) IsLinked() bool {
+	return a.LinkID != 0
+}
+
+// IsBackLink returns true if the attribute is a back link, such as memberOf.
+func (a *AttributeSchema) IsBackLink() bool {
+	return a.LinkID != 0 && a.LinkID%2 == 1
+}
+
+// ClassSchema describes an object class defined in the schema, as held by a
+// classSchema object.
+type ClassSchema struct {
+	// Name is the lDAPDisplayName of the class.
+	Name string
+
+	// CN is the common name of the classSchema object.
+	CN string
+	DN string
+
+	// OID is the governsID of the class.
+	OID string
+
+	// SubClassOf is the name of the class this class is derived from.
+	SubClassOf string
+	Category   ClassCategory
+
+	// RDNAttribute is the name of the attribute used as the relative
+	// distinguished name of instances of the class.
+	RDNAttribute string
+
+	// MustContain and MayContain hold the mandatory and optional attributes
+	// declared directly by the class, including the system variants.
+	MustContain []string
+	MayContain  []string
+
+	// AuxiliaryClasses holds the auxiliary classes that are added to the
+	// class, including the system variants.
+	AuxiliaryClasses []string
+
+	// PossSuperiors holds the classes that may contain instances of the
+	// class, including the system variants.
+	PossSuperiors []string
+
+	DefaultObjectCategory     string
+	DefaultSecurityDescriptor string
+
+	// SchemaIDGUID is converted from its binary layout so that its string
+	// form matches the one displayed by Windows tools.
+	SchemaIDGUID uuid.UUID
+	Description  string
+}
+
+// attributeSchemaAttrs are the attributes read from each attributeSchema
+// object.
+var attributeSchemaAttrs = []string{
+	"lDAPDisplayName", "cn", "distinguishedName", "attributeID",
+	"attributeSyntax", "oMSyntax", "isSingleValued", "systemOnly",
+	"systemFlags", "linkID", "searchFlags", "rangeLower", "rangeUpper",
+	"isMemberOfPartialAttributeSet", "schemaIDGUID", "attributeSecurityGUID",
+	"adminDescription",
+}
+
+// classSchemaAttrs are the attributes read from each classSchema object.
+var classSchemaAttrs = []string{
+	"lDAPDisplayName", "cn", "distinguishedName", "governsID", "subClassOf",
+	"objectClassCategory", "rDNAttID", "mustContain", "systemMustContain",
+	"mayContain", "systemMayContain", "auxiliaryClass", "systemAuxiliaryClass",
+	"possSuperiors", "systemPossSuperiors", "defaultObjectCategory",
+	"defaultSecurityDescriptor", "schemaIDGUID", "adminDescription",
+}
+
+// Schema is an in-memory copy of the attribute and class definitions held by
+// the schema naming context. Lookups are served from memory and do not
+// contact the server. Names are matched case-insensitively.
+type Schema struct {
+	dn         string
+	classes    map[string]*ClassSchema
+	attributes map[string]*AttributeSchema
+
+	// links holds the linked attributes by linkID
+	links map[int]*AttributeSchema
+}
+
+// Schema reads the schema of the forest.
+func (f *Forest) Schema() (*Schema, error) {
+	return loadSchema(f.root.SchemaNamingContext, f.search)
+}
+
+// loadSchema reads every attributeSchema and classSchema object beneath the
+// schema naming context with the given distinguished name.
+func loadSchema(dn string, search func(dn string, q Query) ([]*Row, error)) (*Schema, error) {
+	s := &Schema{
+		dn:         dn,
+		classes:    make(map[string]*ClassSchema),
+		attributes: make(map[string]*AttributeSchema),
+		links:      make(map[int]*AttributeSchema),
+	}
+
+	rows, err := search(dn, Query{
+		Filter:     "(objectClass=attributeSchema)",
+		Attributes: attributeSchemaAttrs,
+		Scope:      ScopeOneLevel,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		attr := attributeSchemaFromRow(row)
+		s.attributes[strings.ToLower(attr.Name)] = attr
+		if attr.IsLinked() {
+			s.links[attr.LinkID] = attr
+		}
+	}
+
+	rows, err = search(dn, Query{
+		Filter:     "(objectClass=classSchema)",
+		Attributes: classSchemaAttrs,
+		Scope:      ScopeOneLevel,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		class := classSchemaFromRow(row)
+		s.classes[strings.ToLower(class.Name)] = class
+	}
+	return s, nil
+}
+
+func attributeSchemaFromRow(row *Row) *AttributeSchema {
+	attr := &AttributeSchema{
+		Name:                  row.AttrString("lDAPDisplayName"),
+		CN:                    row.AttrString("cn"),
+		DN:                    row.AttrString("distinguishedName"),
+		OID:                   row.AttrString("attributeID"),
+		Syntax:                row.AttrString("attributeSyntax"),
+		OMSyntax:              row.AttrInt("oMSyntax"),
+		SingleValued:          row.AttrBool("isSingleValued"),
+		SystemOnly:            row.AttrBool("systemOnly"),
+		Constructed:           row.AttrInt("systemFlags")&systemFlagsConstructed != 0,
+		LinkID:                row.AttrInt("linkID"),
+		SearchFlags:           row.AttrInt("searchFlags"),
+		InGlobalCatalog:       row.AttrBool("isMemberOfPartialAttributeSet"),
+		SchemaIDGUID:          guidFromWindowsBytes(row.AttrBytes("schemaIDGUID")),
+		AttributeSecurityGUID: guidFromWindowsBytes(row.AttrBytes("attributeSecurityGUID")),
+		Description:           row.AttrString("adminDescription"),
+	}
+	if row.Has("rangeLower") {
+		v := row.AttrInt64("rangeLower")
+		attr.RangeLower = &v
+	}
+	if row.Has("rangeUpper") {
+		v := row.AttrInt64("rangeUpper")
+		attr.RangeUpper = &v
+	}
+	return attr
+}
+
+func classSchemaFromRow(row *Row) *ClassSchema {
+	return &ClassSchema{
+		Name:                      row.AttrString("lDAPDisplayName"),
+		CN:                        row.AttrString("cn"),
+		DN:                        row.AttrString("distinguishedName"),
+		OID:                       row.AttrString("governsID"),
+		SubClassOf:                row.AttrString("subClassOf"),
+		Category:                  ClassCategory(row.AttrInt("objectClassCategory")),
+		RDNAttribute:              row.AttrString("rDNAttID"),
+		MustContain:               append(row.AttrStringSlice("systemMustContain"), row.AttrStringSlice("mustContain")...),
+		MayContain:                append(row.AttrStringSlice("systemMayContain"), row.AttrStringSlice("mayContain")...),
+		AuxiliaryClasses:          append(row.AttrStringSlice("systemAuxiliaryClass"), row.AttrStringSlice("auxiliaryClass")...),
+		PossSuperiors:             append(row.AttrStringSlice("systemPossSuperiors"), row.AttrStringSlice("possSuperiors")...),
+		DefaultObjectCategory:     row.AttrString("defaultObjectCategory"),
+		DefaultSecurityDescriptor: row.AttrString("defaultSecurityDescriptor"),
+		SchemaIDGUID:              guidFromWindowsBytes(row.AttrBytes("schemaIDGUID")),
+		Description:               row.AttrString("adminDescription"),
+	}
+}
+
+// DN returns the distinguished name of the schema naming context.
+func (s *Schema) DN() string {
+	return s.dn
+}
+
+// Classes returns every class defined in the schema, sorted by name.
+func (s *Schema) Classes() []*ClassSchema {
+	classes := make([]*ClassSchema, 0, len(s.classes))
+	for _, class := range s.classes {
+		classes = append(classes, class)
+	}
+	sort.Slice(classes, func(i, j int) bool { return classes[i].Name < classes[j].Name })
+	return classes
+}
+
+// Attributes returns every attribute defined in the schema, sorted by name.
+func (s *Schema) Attributes() []*AttributeSchema {
+	attrs := make([]*AttributeSchema, 0, len(s.attributes))
+	for _, attr := range s.attributes {
+		attrs = append(attrs, attr)
+	}
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].Name < attrs[j].Name })
+	return attrs
+}
+
+// Class returns the class with the given lDAPDisplayName.
+func (s *Schema) Class(name string) (class *ClassSchema, ok bool) {
+	class, ok = s.classes[strings.ToLower(name)]
+	return
+}
+
+// Attribute returns the attribute with the given lDAPDisplayName.
+func (s *Schema) Attribute(name string) (attr *AttributeSchema, ok bool) {
+	attr, ok = s.attributes[strings.ToLower(name)]
+	return
+}
+
+// ForwardLink returns the forward link that the back link with the given
+// lDAPDisplayName belongs to, such as member for memberOf. Back links are
+// maintained by the directory and are changed by writing the forward link
+// on the objects they list.
+func (s *Schema) ForwardLink(name string) (attr *AttributeSchema, ok bool) {
+	back, ok := s.Attribute(name)
+	if !ok || !back.IsBackLink() {
+		return nil, false
+	}
+	attr, ok = s.links[back.LinkID-1]
+	return
+}
+
+// BackLink returns the back link of the forward link with the given
+// lDAPDisplayName, such as memberOf for member. Not every forward link has
+// a back link.
+func (s *Schema) BackLink(name string) (attr *AttributeSchema, ok bool) {
+	forward, ok := s.Attribute(name)
+	if !ok || !forward.IsLinked() || forward.IsBackLink() {
+		return nil, false
+	}
+	attr, ok = s.links[forward.LinkID+1]
+	return
+}
+
+// MandatoryAttributes returns the attributes that an instance of the given
+// class must hold. It includes the attributes required by every class the
+// class is derived from and by every auxiliary class it includes.
+func (s *Schema) MandatoryAttributes(class string) ([]string, error) {
+	return s.collect(class, func(c *ClassSchema) []string { return c.MustContain })
+}
+
+// OptionalAttributes returns the attributes that an instance of the given
+// class may hold in addition to its mandatory attributes. It includes the
+// attributes permitted by every class the class is derived from and by every
+// auxiliary class it includes.
+func (s *Schema) OptionalAttributes(class string) ([]string, error) {
+	return s.collect(class, func(c *ClassSchema) []string { return c.MayContain })
+}
+
+// collect walks the inheritance hierarchy of the given class and returns the
+// sorted, de-duplicated union of the attributes selected by fn.
+func (s *Schema) collect(class string, fn func(*ClassSchema) []string) ([]string, error) {
+	seen := make(map[string]bool)
+	names := make(map[string]string)
+	var walk func(name string) error
+	walk = func(name string) error {
+		key := strings.ToLower(name)
+		if seen[key] {
+			return nil
+		}
+		seen[key] = true
+		c, ok := s.classes[key]
+		if !ok {
+			return fmt.Errorf("schema class \"%s\" not found", name)
+		}
+		for _, attr := range fn(c) {
+			names[strings.ToLower(attr)] = attr
+		}
+		for _, aux := range c.AuxiliaryClasses {
+			if err := walk(aux); err != nil {
+				return err
+			}
+		}
+		if c.SubClassOf != "" && !strings.EqualFold(c.SubClassOf, c.Name) {
+			return walk(c.SubClassOf)
+		}
+		return nil
+	}
+	if err := walk(class); err != nil {
+		return nil, err
+	}
+	attrs := make([]string, 0, len(names))
+	for _, name := range names {
+		attrs = append(attrs, name)
+	}
+	sort.Strings(attrs)
+	return attrs, nil
+}
@@ -0,0 +1,84 @@
+package adsi
+
+import "strings"
+
+// Schema returns the schema of the forest that the given server belongs to.
+// If server is empty a domain controller of the computer's domain is used.
+//
+// The schema is read from the server on first use and cached by the client,
+// so that repeated lookups of attribute syntaxes and class definitions don't
+// contact the server. Call RefreshSchema to discard the cached copy after the
+// schema has been extended.
+func (c *Client) Schema(server string) (s *Schema, err error) {
+	key := strings.ToLower(server)
+	c.sm.Lock()
+	defer c.sm.Unlock()
+	if s = c.schemas[key]; s != nil {
+		return s, nil
+	}
+	if s, err = c.loadSchema(server); err != nil {
+		return nil, err
+	}
+	if c.schemas == nil {
+		c.schemas = make(map[string]*Schema)
+	}
+	c.schemas[key] = s
+	return s, nil
+}
+
+// SchemaSC reads the schema of the forest that the given server belongs to,
+// binding with the given credentials. Unlike Schema the result is not
+// cached, so callers that need it repeatedly should keep their own copy.
+func (c *Client) SchemaSC(server, user, password string) (*Schema, error) {
+	return c.loadSchemaSC(server, user, password, fastBindFlags(server))
+}
+
+// RefreshSchema reads the schema of the forest that the given server belongs
+// to and replaces the copy cached by the client. Schemas that have already
+// been returned by Schema are not modified.
+func (c *Client) RefreshSchema(server string) error {
+	s, err := c.loadSchema(server)
+	if err != nil {
+		return err
+	}
+	c.sm.Lock()
+	defer c.sm.Unlock()
+	if c.schemas == nil {
+		c.schemas = make(map[string]*Schema)
+	}
+	c.schemas[strings.ToLower(server)] = s
+	return nil
+}
+
+// forgetSchema discards the cached schema of the forest that the given
+// server belongs to, so that it is read again when next needed.
+func (c *Client) forgetSchema(server string) {
+	c.sm.Lock()
+	defer c.sm.Unlock()
+	delete(c.schemas, strings.ToLower(server))
+}
+
+// loadSchema reads the schema of the forest that the given server belongs to
+// using the client's connection settings.
+func (c *Client) loadSchema(server string) (*Schema, error) {
+	return c.loadSchemaSC(server, "", "", c.Flags())
+}
+
+// loadSchemaSC reads the schema of the forest that the given server belongs
+// to, binding with the given credentials and flags. The schema is not cached.
+func (c *Client) loadSchemaSC(server, user, password string, flags uint32) (*Schema, error) {
+	root, err := readRootDSE(server, func(path string) (*Object, error) {
+		return c.OpenSC(path, user, password, flags)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return loadSchema(root.SchemaNamingContext, func(dn string, q Query) ([]*Row, error) {
+		result, err := c.searchSC(root.path(dn), user, password, flags, q)
+		if err != nil {
+			return nil, err
+		}
+		defer result.Close()
+		return result.All()
+	})
+}
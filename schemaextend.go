@@ -0,0 +1,408 @@
+package adsi
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/go-adsi/adsi/api"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidSchemaDefinition is returned when a schema extension is rejected
+// before it is sent to the server.
+var ErrInvalidSchemaDefinition = errors.New("invalid schema definition")
+
+// writableFlags are the flags used to bind to a specific domain controller in
+// order to make changes.
+const writableFlags = api.ADS_SECURE_AUTHENTICATION | api.ADS_USE_SEALING | api.ADS_SERVER_BIND
+
+var oidPattern = regexp.MustCompile(`^[0-2](\.(0|[1-9][0-9]*))+$`)
+
+// dnOMObjectClass is the oMObjectClass value required by attributes with the
+// DN syntax.
+var dnOMObjectClass = []byte{0x2B, 0x0C, 0x02, 0x87, 0x73, 0x1C, 0x00, 0x85, 0x4A}
+
+// syntaxOMSyntax maps each attributeSyntax to the oMSyntax values it permits.
+// The first value is used when none is specified.
+var syntaxOMSyntax = map[string][]int{
+	"2.5.5.1":  {127},
+	"2.5.5.2":  {6},
+	"2.5.5.3":  {27},
+	"2.5.5.4":  {20},
+	"2.5.5.5":  {22, 19},
+	"2.5.5.6":  {18},
+	"2.5.5.8":  {1},
+	"2.5.5.9":  {2, 10},
+	"2.5.5.10": {4},
+	"2.5.5.11": {24, 23},
+	"2.5.5.12": {64},
+	"2.5.5.15": {66},
+	"2.5.5.16": {65},
+	"2.5.5.17": {4},
+}
+
+// AttributeDefinition describes a new attributeSchema object.
+type AttributeDefinition struct {
+	// Name is the lDAPDisplayName of the attribute. It is also used as the
+	// common name of the attributeSchema object unless CN is set.
+	Name string
+	CN   string
+
+	// OID is the attributeID of the attribute, which must be allocated from
+	// an OID arc owned by the organization.
+	OID string
+
+	// Syntax is the attributeSyntax OID, such as "2.5.5.12" for Unicode
+	// strings. OMSyntax may be left at zero to use the usual oMSyntax of the
+	// syntax. Only syntaxes that do not require a custom oMObjectClass, plus
+	// the DN syntax, are supported.
+	Syntax   string
+	OMSyntax int
+
+	SingleValued    bool
+	RangeLower      *int32
+	RangeUpper      *int32
+	SearchFlags     int
+	InGlobalCatalog bool
+	Description     string
+
+	// SchemaIDGUID may be set to give the attribute a well-known GUID. If it
+	// is zero the server generates one.
+	SchemaIDGUID uuid.UUID
+}
+
+// ClassDefinition describes a new classSchema object.
+type ClassDefinition struct {
+	// Name is the lDAPDisplayName of the class. It is also used as the common
+	// name of the classSchema object unless CN is set.
+	Name string
+	CN   string
+
+	// OID is the governsID of the class, which must be allocated from an OID
+	// arc owned by the organization.
+	OID string
+
+	// SubClassOf is the class the new class is derived from. If empty "top"
+	// is used.
+	SubClassOf string
+
+	// Category must be ClassCategoryStructural, ClassCategoryAbstract or
+	// ClassCategoryAuxiliary.
+	Category ClassCategory
+
+	MustContain      []string
+	MayContain       []string
+	PossSuperiors    []string
+	AuxiliaryClasses []string
+
+	DefaultSecurityDescriptor string
+	Description               string
+
+	// SchemaIDGUID may be set to give the class a well-known GUID. If it is
+	// zero the server generates one.
+	SchemaIDGUID uuid.UUID
+}
+
+// CreateAttribute adds a new attribute to the schema of the forest.
+//
+// The definition is validated and checked against the current schema before
+// anything is written. A forest opened through a client checks the schema
+// cached by the client, and discards the cached copy once the extension has
+// been made. The attributeSchema object is created on the schema master,
+// with the credentials the forest was opened with, which requires membership
+// of Schema Admins. Schema extensions cannot be removed once they have been
+// made, only deactivated. Call UpdateSchemaNow once all extensions have been
+// made to make them available immediately.
+func (f *Forest) CreateAttribute(def AttributeDefinition) error {
+	if def.CN == "" {
+		def.CN = def.Name
+	}
+	if def.Name == "" || !oidPattern.MatchString(def.OID) {
+		return fmt.Errorf("%w: attribute requires a name and a valid OID", ErrInvalidSchemaDefinition)
+	}
+	permitted, ok := syntaxOMSyntax[def.Syntax]
+	if !ok {
+		return fmt.Errorf("%w: unsupported attribute syntax \"%s\"", ErrInvalidSchemaDefinition, def.Syntax)
+	}
+	if def.OMSyntax == 0 {
+		def.OMSyntax = permitted[0]
+	} else if !containsInt(permitted, def.OMSyntax) {
+		return fmt.Errorf("%w: oMSyntax %d is not valid for syntax \"%s\"", ErrInvalidSchemaDefinition, def.OMSyntax, def.Syntax)
+	}
+
+	schema, err := f.currentSchema()
+	if err != nil {
+		return err
+	}
+	if _, exists := schema.Attribute(def.Name); exists {
+		return fmt.Errorf("%w: attribute \"%s\" already exists", ErrInvalidSchemaDefinition, def.Name)
+	}
+	for _, attr := range schema.Attributes() {
+		if attr.OID == def.OID {
+			return fmt.Errorf("%w: OID %s is already used by \"%s\"", ErrInvalidSchemaDefinition, def.OID, attr.Name)
+		}
+	}
+
+	return f.createSchemaObject("attributeSchema", def.CN, func(obj *Object) error {
+		set := schemaSetter(obj)
+		if err := set("lDAPDisplayName", def.Name); err != nil {
+			return err
+		}
+		if err := set("attributeID", def.OID); err != nil {
+			return err
+		}
+		if err := set("attributeSyntax", def.Syntax); err != nil {
+			return err
+		}
+		if err := set("oMSyntax", def.OMSyntax); err != nil {
+			return err
+		}
+		if err := set("isSingleValued", def.SingleValued); err != nil {
+			return err
+		}
+		if def.Syntax == "2.5.5.1" {
+			if err := set("oMObjectClass", dnOMObjectClass); err != nil {
+				return err
+			}
+		}
+		if def.RangeLower != nil {
+			if err := set("rangeLower", *def.RangeLower); err != nil {
+				return err
+			}
+		}
+		if def.RangeUpper != nil {
+			if err := set("rangeUpper", *def.RangeUpper); err != nil {
+				return err
+			}
+		}
+		if def.SearchFlags != 0 {
+			if err := set("searchFlags", def.SearchFlags); err != nil {
+				return err
+			}
+		}
+		if def.InGlobalCatalog {
+			if err := set("isMemberOfPartialAttributeSet", true); err != nil {
+				return err
+			}
+		}
+		if def.Description != "" {
+			if err := set("adminDescription", def.Description); err != nil {
+				return err
+			}
+		}
+		if def.SchemaIDGUID != (uuid.UUID{}) {
+			if err := set("schemaIDGUID", windowsBytesFromGUID(def.SchemaIDGUID)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CreateClass adds a new class to the schema of the forest.
+//
+// The definition is validated and checked against the current schema before
+// anything is written, in the same way as CreateAttribute. Every referenced
+// class and attribute must already exist. The classSchema object is created
+// on the schema master, which requires membership of Schema Admins. Call
+// UpdateSchemaNow once all extensions have been made to make them available
+// immediately.
+func (f *Forest) CreateClass(def ClassDefinition) error {
+	if def.CN == "" {
+		def.CN = def.Name
+	}
+	if def.SubClassOf == "" {
+		def.SubClassOf = "top"
+	}
+	if def.Name == "" || !oidPattern.MatchString(def.OID) {
+		return fmt.Errorf("%w: class requires a name and a valid OID", ErrInvalidSchemaDefinition)
+	}
+	switch def.Category {
+	case ClassCategoryStructural, ClassCategoryAbstract, ClassCategoryAuxiliary:
+	default:
+		return fmt.Errorf("%w: invalid class category %d", ErrInvalidSchemaDefinition, int(def.Category))
+	}
+
+	schema, err := f.currentSchema()
+	if err != nil {
+		return err
+	}
+	if _, exists := schema.Class(def.Name); exists {
+		return fmt.Errorf("%w: class \"%s\" already exists", ErrInvalidSchemaDefinition, def.Name)
+	}
+	for _, class := range schema.Classes() {
+		if class.OID == def.OID {
+			return fmt.Errorf("%w: OID %s is already used by \"%s\"", ErrInvalidSchemaDefinition, def.OID, class.Name)
+		}
+	}
+	for _, name := range append(append([]string{def.SubClassOf}, def.PossSuperiors...), def.AuxiliaryClasses...) {
+		if _, ok := schema.Class(name); !ok {
+			return fmt.Errorf("%w: class \"%s\" does not exist", ErrInvalidSchemaDefinition, name)
+		}
+	}
+	for _, name := range append(append([]string(nil), def.MustContain...), def.MayContain...) {
+		if _, ok := schema.Attribute(name); !ok {
+			return fmt.Errorf("%w: attribute \"%s\" does not exist", ErrInvalidSchemaDefinition, name)
+		}
+	}
+
+	return f.createSchemaObject("classSchema", def.CN, func(obj *Object) error {
+		set := schemaSetter(obj)
+		if err := set("lDAPDisplayName", def.Name); err != nil {
+			return err
+		}
+		if err := set("governsID", def.OID); err != nil {
+			return err
+		}
+		if err := set("subClassOf", def.SubClassOf); err != nil {
+			return err
+		}
+		if err := set("objectClassCategory", int(def.Category)); err != nil {
+			return err
+		}
+		if err := set("mustContain", stringsToValues(def.MustContain)...); err != nil {
+			return err
+		}
+		if err := set("mayContain", stringsToValues(def.MayContain)...); err != nil {
+			return err
+		}
+		if err := set("possSuperiors", stringsToValues(def.PossSuperiors)...); err != nil {
+			return err
+		}
+		if err := set("auxiliaryClass", stringsToValues(def.AuxiliaryClasses)...); err != nil {
+			return err
+		}
+		if def.DefaultSecurityDescriptor != "" {
+			if err := set("defaultSecurityDescriptor", def.DefaultSecurityDescriptor); err != nil {
+				return err
+			}
+		}
+		if def.Description != "" {
+			if err := set("adminDescription", def.Description); err != nil {
+				return err
+			}
+		}
+		if def.SchemaIDGUID != (uuid.UUID{}) {
+			if err := set("schemaIDGUID", windowsBytesFromGUID(def.SchemaIDGUID)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateSchemaNow asks the schema master to refresh its schema cache, which
+// makes recent schema extensions available without waiting for the periodic
+// refresh.
+func (f *Forest) UpdateSchemaNow() error {
+	server, err := f.schemaMaster()
+	if err != nil {
+		return err
+	}
+	root, err := f.openWritable("LDAP://" + server + "/RootDSE")
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+	if err = root.PutInt("schemaUpdateNow", 1); err != nil {
+		return err
+	}
+	return root.SetInfo()
+}
+
+// createSchemaObject creates a schema object of the given class on the
+// schema master. The attributes of the object are set by fn before it is
+// committed.
+func (f *Forest) createSchemaObject(class, cn string, fn func(obj *Object) error) error {
+	server, err := f.schemaMaster()
+	if err != nil {
+		return err
+	}
+	path := (&RootDSE{Server: server}).path(f.root.SchemaNamingContext)
+	var container *Container
+	if f.client == nil {
+		container, err = OpenContainerSC(path, "", "", writableFlags)
+	} else {
+		container, err = f.client.OpenContainerSC(path, f.user, f.password, f.writableFlags())
+	}
+	if err != nil {
+		return err
+	}
+	defer container.Close()
+
+	obj, err := container.Create(class, "CN="+escapeRDNValue(cn))
+	if err != nil {
+		return err
+	}
+	defer obj.Close()
+	if err = fn(obj); err != nil {
+		return err
+	}
+	if err = obj.SetInfo(); err != nil {
+		return err
+	}
+	if f.client != nil {
+		f.client.forgetSchema(f.root.Server)
+	}
+	return nil
+}
+
+// currentSchema returns the schema of the forest. A forest opened through a
+// client uses the copy cached by the client.
+func (f *Forest) currentSchema() (*Schema, error) {
+	if f.client == nil {
+		return f.Schema()
+	}
+	return f.client.Schema(f.root.Server)
+}
+
+// openWritable opens the object with the given path, which names the schema
+// master, in order to make changes to it.
+func (f *Forest) openWritable(path string) (*Object, error) {
+	if f.client == nil {
+		return OpenSC(path, "", "", writableFlags)
+	}
+	return f.client.OpenSC(path, f.user, f.password, f.writableFlags())
+}
+
+// writableFlags returns the flags of the forest with those needed to bind to
+// a specific domain controller in order to make changes.
+func (f *Forest) writableFlags() uint32 {
+	return f.flags&^api.ADS_READONLY_SERVER | api.ADS_SERVER_BIND
+}
+
+// schemaSetter returns a function that sets the values of an attribute of
+// obj, ignoring attributes without values.
+func schemaSetter(obj *Object) func(name string, values ...interface{}) error {
+	return func(name string, values ...interface{}) error {
+		if len(values) == 0 {
+			return nil
+		}
+		if err := obj.PutEx(api.ADS_PROPERTY_UPDATE, name, values...); err != nil {
+			return fmt.Errorf("unable to set \"%s\": %w", name, err)
+		}
+		return nil
+	}
+}
+
+// schemaMaster returns the DNS host name of the schema master.
+func (f *Forest) schemaMaster() (string, error) {
+	return f.roleOwner(f.root.SchemaNamingContext)
+}
+
+// windowsBytesFromGUID converts a UUID to the mixed-endian binary layout used
+// by Windows. It is the inverse of guidFromWindowsBytes.
+func windowsBytesFromGUID(guid uuid.UUID) []byte {
+	swapped := guidFromWindowsBytes(guid[:])
+	return swapped[:]
+}
+
+func containsInt(values []int, v int) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
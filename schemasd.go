@@ -0,0 +1,123 @@
+package adsi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-adsi/adsi/sddl"
+)
+
+// ErrNoDefaultSecurityDescriptor is returned when a schema class does not
+// define a default security descriptor.
+var ErrNoDefaultSecurityDescriptor = errors.New("class has no default security descriptor")
+
+// ParseDefaultSecurityDescriptor parses the defaultSecurityDescriptor of the
+// class. It describes the explicit permissions that new instances of the
+// class receive before inherited permissions from their parent are applied.
+func (c *ClassSchema) ParseDefaultSecurityDescriptor() (*sddl.SecurityDescriptor, error) {
+	if c.DefaultSecurityDescriptor == "" {
+		return nil, ErrNoDefaultSecurityDescriptor
+	}
+	return sddl.Parse(c.DefaultSecurityDescriptor)
+}
+
+// DefaultACEs returns the entries of the default discretionary ACL of the
+// class that apply to the given trustee. The trustee may be a SID string or
+// an SDDL alias such as "AU". Domain-relative aliases in the descriptor, such
+// as "DA", are resolved against domainSID so that they can be matched against
+// SID strings, which are compared case-insensitively. Pass the zero SID if
+// domain-relative aliases are not of interest.
+func (c *ClassSchema) DefaultACEs(trustee string, domainSID SID) (aces []sddl.ACE, err error) {
+	sd, err := c.ParseDefaultSecurityDescriptor()
+	if err != nil || sd.DACL == nil {
+		return
+	}
+	var domain string
+	if len(domainSID.SubAuthorities) > 0 {
+		domain = domainSID.String()
+	}
+	want, ok := sddl.ResolveTrustee(trustee, domain)
+	if !ok {
+		want = trustee
+	}
+	for _, ace := range sd.DACL.ACEs {
+		have, ok := sddl.ResolveTrustee(ace.Trustee, domain)
+		if !ok {
+			have = ace.Trustee
+		}
+		if strings.EqualFold(have, want) {
+			aces = append(aces, ace)
+		}
+	}
+	return
+}
+
+// sddlACETypes maps the SDDL ACE types that have a binary form in ACE to
+// their type codes.
+var sddlACETypes = map[string]uint8{
+	"A":  ACETypeAccessAllowed,
+	"D":  ACETypeAccessDenied,
+	"AU": ACETypeSystemAudit,
+	"OA": ACETypeAccessAllowedObject,
+	"OD": ACETypeAccessDeniedObject,
+	"OU": ACETypeSystemAuditObject,
+}
+
+// sddlACEFlags maps SDDL ACE flags to their binary form.
+var sddlACEFlags = map[string]uint8{
+	"OI": ACEFlagObjectInherit,
+	"CI": ACEFlagContainerInherit,
+	"NP": ACEFlagNoPropagate,
+	"IO": ACEFlagInheritOnly,
+	"ID": ACEFlagInherited,
+	"SA": ACEFlagAuditSuccess,
+	"FA": ACEFlagAuditFailure,
+}
+
+// DefaultDACL returns the default discretionary ACL of the class in the
+// binary form held by nTSecurityDescriptor, so that it can be extended and
+// supplied when an object is created. Domain-relative aliases are resolved
+// against domainSID, except for those of groups that are only defined in the
+// forest root domain, such as "EA", which are resolved against
+// rootDomainSID.
+func (c *ClassSchema) DefaultDACL(domainSID, rootDomainSID SID) (*ACL, error) {
+	sd, err := c.ParseDefaultSecurityDescriptor()
+	if err != nil {
+		return nil, err
+	}
+	if sd.DACL == nil {
+		return nil, ErrNoDefaultSecurityDescriptor
+	}
+	var domain, root string
+	if len(domainSID.SubAuthorities) > 0 {
+		domain = domainSID.String()
+	}
+	if len(rootDomainSID.SubAuthorities) > 0 {
+		root = rootDomainSID.String()
+	}
+	acl := &ACL{ACEs: make([]ACE, 0, len(sd.DACL.ACEs))}
+	for _, entry := range sd.DACL.ACEs {
+		ace := ACE{Mask: entry.Rights, ObjectType: entry.ObjectType, InheritedObjectType: entry.InheritedObjectType}
+		var ok bool
+		if ace.Type, ok = sddlACETypes[entry.Type]; !ok {
+			return nil, fmt.Errorf("%w: ACE type %q", sddl.ErrUnsupported, entry.Type)
+		}
+		for _, flag := range entry.Flags {
+			f, ok := sddlACEFlags[flag]
+			if !ok {
+				return nil, fmt.Errorf("%w: ACE flag %q", sddl.ErrUnsupported, flag)
+			}
+			ace.Flags |= f
+		}
+		trustee, ok := sddl.ResolveForestTrustee(entry.Trustee, domain, root)
+		if !ok {
+			return nil, fmt.Errorf("unable to resolve trustee %q of the default security descriptor", entry.Trustee)
+		}
+		if ace.SID, err = ParseSIDString(trustee); err != nil {
+			return nil, err
+		}
+		acl.ACEs = append(acl.ACEs, ace)
+	}
+	return acl, nil
+}
@@ -0,0 +1,84 @@
+package sddl
+
+import "strconv"
+
+// wellKnownAliases maps SDDL trustee aliases to fixed SID strings.
+var wellKnownAliases = map[string]string{
+	"AN": "S-1-5-7",      // Anonymous logon
+	"AO": "S-1-5-32-548", // Account operators
+	"AU": "S-1-5-11",     // Authenticated users
+	"BA": "S-1-5-32-544", // Built-in administrators
+	"BG": "S-1-5-32-546", // Built-in guests
+	"BO": "S-1-5-32-551", // Backup operators
+	"BU": "S-1-5-32-545", // Built-in users
+	"CG": "S-1-3-1",      // Creator group
+	"CO": "S-1-3-0",      // Creator owner
+	"ED": "S-1-5-9",      // Enterprise domain controllers
+	"IU": "S-1-5-4",      // Interactively logged-on user
+	"LS": "S-1-5-19",     // Local service
+	"NS": "S-1-5-20",     // Network service
+	"NU": "S-1-5-2",      // Network logon user
+	"OW": "S-1-3-4",      // Owner rights
+	"PO": "S-1-5-32-550", // Printer operators
+	"PS": "S-1-5-10",     // Principal self
+	"PU": "S-1-5-32-547", // Power users
+	"RC": "S-1-5-12",     // Restricted code
+	"RD": "S-1-5-32-555", // Terminal server users
+	"RE": "S-1-5-32-552", // Replicator
+	"RU": "S-1-5-32-554", // Pre-Windows 2000 compatible access
+	"SO": "S-1-5-32-549", // Server operators
+	"SU": "S-1-5-6",      // Service logon user
+	"SY": "S-1-5-18",     // Local system
+	"WD": "S-1-1-0",      // Everyone
+}
+
+// domainAliases maps SDDL trustee aliases to the relative identifiers of
+// groups defined in each domain.
+var domainAliases = map[string]uint32{
+	"RO": 498, // Enterprise read-only domain controllers
+	"LA": 500, // Administrator
+	"LG": 501, // Guest
+	"DA": 512, // Domain admins
+	"DU": 513, // Domain users
+	"DG": 514, // Domain guests
+	"DC": 515, // Domain computers
+	"DD": 516, // Domain controllers
+	"CA": 517, // Certificate publishers
+	"SA": 518, // Schema admins
+	"EA": 519, // Enterprise admins
+	"PA": 520, // Group policy creator owners
+	"CN": 522, // Cloneable domain controllers
+	"RS": 553, // RAS servers
+}
+
+// rootDomainAliases lists the aliases of groups that are only defined in the
+// forest root domain.
+var rootDomainAliases = map[string]bool{"RO": true, "EA": true, "SA": true}
+
+// ResolveTrustee converts a trustee alias to a SID string. Aliases of groups
+// defined in each domain, such as "DA", are resolved relative to the given
+// domain SID string. Trustees that are already SID strings are returned
+// unchanged. If the alias is unknown, or requires a domain SID that was not
+// provided, ok is false.
+func ResolveTrustee(trustee, domainSID string) (sid string, ok bool) {
+	if len(trustee) > 2 && (trustee[:2] == "S-" || trustee[:2] == "s-") {
+		return trustee, true
+	}
+	if sid, ok = wellKnownAliases[trustee]; ok {
+		return sid, true
+	}
+	if rid, ok := domainAliases[trustee]; ok && domainSID != "" {
+		return domainSID + "-" + strconv.FormatUint(uint64(rid), 10), true
+	}
+	return "", false
+}
+
+// ResolveForestTrustee is like ResolveTrustee, but resolves the aliases of
+// groups that are only defined in the forest root domain, such as "EA",
+// relative to rootDomainSID, as Active Directory does.
+func ResolveForestTrustee(trustee, domainSID, rootDomainSID string) (sid string, ok bool) {
+	if rootDomainAliases[trustee] {
+		domainSID = rootDomainSID
+	}
+	return ResolveTrustee(trustee, domainSID)
+}
@@ -0,0 +1,82 @@
+package sddl
+
+import "fmt"
+
+// Access rights that can be expressed by name in SDDL. The directory service
+// rights are listed first, followed by the standard and generic rights.
+const (
+	RightDSCreateChild   uint32 = 0x00000001 // CC
+	RightDSDeleteChild   uint32 = 0x00000002 // DC
+	RightDSListChildren  uint32 = 0x00000004 // LC
+	RightDSSelf          uint32 = 0x00000008 // SW
+	RightDSReadProperty  uint32 = 0x00000010 // RP
+	RightDSWriteProperty uint32 = 0x00000020 // WP
+	RightDSDeleteTree    uint32 = 0x00000040 // DT
+	RightDSListObject    uint32 = 0x00000080 // LO
+	RightDSControlAccess uint32 = 0x00000100 // CR
+	RightDelete          uint32 = 0x00010000 // SD
+	RightReadControl     uint32 = 0x00020000 // RC
+	RightWriteDAC        uint32 = 0x00040000 // WD
+	RightWriteOwner      uint32 = 0x00080000 // WO
+	RightGenericAll      uint32 = 0x10000000 // GA
+	RightGenericExecute  uint32 = 0x20000000 // GX
+	RightGenericWrite    uint32 = 0x40000000 // GW
+	RightGenericRead     uint32 = 0x80000000 // GR
+)
+
+// rightsOrder lists the named rights in the order they are written.
+var rightsOrder = []struct {
+	name  string
+	right uint32
+}{
+	{"GA", RightGenericAll},
+	{"GR", RightGenericRead},
+	{"GW", RightGenericWrite},
+	{"GX", RightGenericExecute},
+	{"RP", RightDSReadProperty},
+	{"WP", RightDSWriteProperty},
+	{"CC", RightDSCreateChild},
+	{"DC", RightDSDeleteChild},
+	{"LC", RightDSListChildren},
+	{"SW", RightDSSelf},
+	{"LO", RightDSListObject},
+	{"DT", RightDSDeleteTree},
+	{"CR", RightDSControlAccess},
+	{"RC", RightReadControl},
+	{"WD", RightWriteDAC},
+	{"WO", RightWriteOwner},
+	{"SD", RightDelete},
+}
+
+var rightsByName = func() map[string]uint32 {
+	m := map[string]uint32{
+		// File and registry rights, which are composites of the above
+		"FA": 0x001F01FF,
+		"FR": 0x00120089,
+		"FW": 0x00120116,
+		"FX": 0x001200A0,
+		"KA": 0x000F003F,
+		"KR": 0x00020019,
+		"KW": 0x00020006,
+		"KX": 0x00020019,
+	}
+	for _, r := range rightsOrder {
+		m[r.name] = r.right
+	}
+	return m
+}()
+
+func rightNames(mask uint32) (names []string) {
+	remaining := mask
+	for _, r := range rightsOrder {
+		if mask&r.right != 0 {
+			names = append(names, r.name)
+			remaining &^= r.right
+		}
+	}
+	if remaining != 0 || len(names) == 0 {
+		// SDDL cannot mix named rights with a numeric mask
+		return []string{fmt.Sprintf("0x%x", mask)}
+	}
+	return
+}
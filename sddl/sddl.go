@@ -0,0 +1,295 @@
+// Package sddl parses security descriptors expressed in the Security
+// Descriptor Definition Language, such as the defaultSecurityDescriptor
+// attribute of Active Directory schema classes.
+//
+// See https://learn.microsoft.com/windows/win32/secauthz/security-descriptor-string-format
+package sddl
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalid is returned when a string cannot be parsed as SDDL.
+	ErrInvalid = errors.New("invalid SDDL")
+
+	// ErrUnsupported is returned when a string holds conditional or resource
+	// attribute ACEs, which are valid SDDL but cannot be represented by ACE.
+	ErrUnsupported = errors.New("unsupported SDDL")
+)
+
+// SecurityDescriptor is a parsed SDDL security descriptor. Components that
+// are absent from the string are left empty.
+type SecurityDescriptor struct {
+	// Owner and Group hold the owner and primary group as a SID string or a
+	// two-letter alias such as "DA".
+	Owner string
+	Group string
+
+	DACL *ACL
+	SACL *ACL
+}
+
+// ACL is a discretionary or system access control list.
+type ACL struct {
+	// Flags holds the ACL flags, such as "P" (protected), "AI"
+	// (auto-inherited) and "AR" (auto-inherit required).
+	Flags string
+
+	ACEs []ACE
+}
+
+// Protected returns true if the ACL does not inherit entries from its
+// parent.
+func (acl *ACL) Protected() bool {
+	return strings.Contains(acl.Flags, "P")
+}
+
+// ACE is a single access control entry.
+type ACE struct {
+	// Type is the ACE type, such as "A" (allow), "D" (deny), "OA" (object
+	// allow) or "AU" (audit).
+	Type string
+
+	// Flags holds the two-letter ACE flags, such as "CI" (container
+	// inherit) and "IO" (inherit only).
+	Flags []string
+
+	// Rights is the access mask granted, denied or audited by the entry.
+	Rights uint32
+
+	// ObjectType and InheritedObjectType hold the GUIDs of object ACEs. They
+	// are the zero UUID when not present.
+	ObjectType          uuid.UUID
+	InheritedObjectType uuid.UUID
+
+	// Trustee is the SID string or two-letter alias the entry applies to.
+	Trustee string
+}
+
+// HasFlag returns true if the entry carries the given two-letter flag.
+func (ace *ACE) HasFlag(flag string) bool {
+	for _, f := range ace.Flags {
+		if strings.EqualFold(f, flag) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAllow returns true if the entry grants access.
+func (ace *ACE) IsAllow() bool {
+	return ace.Type == "A" || ace.Type == "OA" || ace.Type == "XA" || ace.Type == "ZA"
+}
+
+// IsDeny returns true if the entry denies access.
+func (ace *ACE) IsDeny() bool {
+	return ace.Type == "D" || ace.Type == "OD" || ace.Type == "XD"
+}
+
+// RightNames returns the SDDL names of the rights held by the entry. If the
+// access mask holds bits that have no name it is returned as a single
+// hexadecimal value instead.
+func (ace *ACE) RightNames() []string {
+	return rightNames(ace.Rights)
+}
+
+// Parse parses a security descriptor string. Descriptors that hold
+// conditional or resource attribute ACEs are rejected with ErrUnsupported.
+func Parse(s string) (sd *SecurityDescriptor, err error) {
+	sd = new(SecurityDescriptor)
+	s = strings.TrimSpace(s)
+	for len(s) > 0 {
+		if len(s) < 2 || s[1] != ':' {
+			return nil, fmt.Errorf("%w: unexpected %q", ErrInvalid, s)
+		}
+		tag := s[0]
+		end := componentEnd(s, 2)
+		value := s[2:end]
+		s = s[end:]
+		switch tag {
+		case 'O':
+			sd.Owner = value
+		case 'G':
+			sd.Group = value
+		case 'D':
+			if sd.DACL, err = parseACL(value); err != nil {
+				return nil, err
+			}
+		case 'S':
+			if sd.SACL, err = parseACL(value); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("%w: unknown component %q", ErrInvalid, tag)
+		}
+	}
+	return sd, nil
+}
+
+// componentEnd returns the index at which the component starting at i ends,
+// which is the start of the next top-level "X:" tag or the end of s.
+func componentEnd(s string, i int) int {
+	depth := 0
+	for ; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case 'O', 'G', 'D', 'S':
+			if depth == 0 && i+1 < len(s) && s[i+1] == ':' {
+				return i
+			}
+		}
+	}
+	return len(s)
+}
+
+func parseACL(s string) (acl *ACL, err error) {
+	acl = new(ACL)
+	i := strings.IndexByte(s, '(')
+	if i < 0 {
+		acl.Flags = s
+		return acl, nil
+	}
+	acl.Flags = s[:i]
+	s = s[i:]
+	for len(s) > 0 {
+		if s[0] != '(' {
+			return nil, fmt.Errorf("%w: expected '(' in %q", ErrInvalid, s)
+		}
+		end := aceEnd(s)
+		if end < 0 {
+			return nil, fmt.Errorf("%w: unterminated ACE %q", ErrInvalid, s)
+		}
+		ace, err := parseACE(s[1:end])
+		if err != nil {
+			return nil, err
+		}
+		acl.ACEs = append(acl.ACEs, ace)
+		s = s[end+1:]
+	}
+	return acl, nil
+}
+
+// aceEnd returns the index of the parenthesis that closes the ACE at the
+// start of s, or -1 if there is none. The parentheses and quoted strings of
+// conditional expressions are skipped.
+func aceEnd(s string) int {
+	depth := 0
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '(':
+			depth++
+		case c == ')':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func parseACE(s string) (ace ACE, err error) {
+	fields := strings.Split(s, ";")
+	if len(fields) < 6 {
+		return ace, fmt.Errorf("%w: malformed ACE %q", ErrInvalid, s)
+	}
+	if len(fields) > 6 {
+		return ace, fmt.Errorf("%w: conditional or resource attribute ACE %q", ErrUnsupported, s)
+	}
+	ace.Type = fields[0]
+	if len(fields[1])%2 != 0 {
+		return ace, fmt.Errorf("%w: bad ACE flags %q", ErrInvalid, fields[1])
+	}
+	for flags := fields[1]; len(flags) >= 2; flags = flags[2:] {
+		ace.Flags = append(ace.Flags, flags[:2])
+	}
+	if ace.Rights, err = parseRights(fields[2]); err != nil {
+		return ace, err
+	}
+	if fields[3] != "" {
+		if ace.ObjectType, err = uuid.Parse(fields[3]); err != nil {
+			return ace, fmt.Errorf("%w: bad object type %q", ErrInvalid, fields[3])
+		}
+	}
+	if fields[4] != "" {
+		if ace.InheritedObjectType, err = uuid.Parse(fields[4]); err != nil {
+			return ace, fmt.Errorf("%w: bad inherited object type %q", ErrInvalid, fields[4])
+		}
+	}
+	ace.Trustee = fields[5]
+	return ace, nil
+}
+
+func parseRights(s string) (mask uint32, err error) {
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		v, err := strconv.ParseUint(s[2:], 16, 32)
+		if err != nil {
+			return 0, fmt.Errorf("%w: bad access mask %q", ErrInvalid, s)
+		}
+		return uint32(v), nil
+	}
+	for len(s) >= 2 {
+		right, ok := rightsByName[s[:2]]
+		if !ok {
+			return 0, fmt.Errorf("%w: unknown right %q", ErrInvalid, s[:2])
+		}
+		mask |= right
+		s = s[2:]
+	}
+	if s != "" {
+		return 0, fmt.Errorf("%w: trailing rights %q", ErrInvalid, s)
+	}
+	return mask, nil
+}
+
+// String formats the security descriptor as SDDL.
+func (sd *SecurityDescriptor) String() string {
+	var b strings.Builder
+	if sd.Owner != "" {
+		b.WriteString("O:" + sd.Owner)
+	}
+	if sd.Group != "" {
+		b.WriteString("G:" + sd.Group)
+	}
+	if sd.DACL != nil {
+		b.WriteString("D:" + sd.DACL.String())
+	}
+	if sd.SACL != nil {
+		b.WriteString("S:" + sd.SACL.String())
+	}
+	return b.String()
+}
+
+// String formats the ACL as SDDL, without its "D:" or "S:" prefix.
+func (acl *ACL) String() string {
+	var b strings.Builder
+	b.WriteString(acl.Flags)
+	for i := range acl.ACEs {
+		b.WriteString(acl.ACEs[i].String())
+	}
+	return b.String()
+}
+
+// String formats the entry as SDDL.
+func (ace *ACE) String() string {
+	guid := func(id uuid.UUID) string {
+		if id == (uuid.UUID{}) {
+			return ""
+		}
+		return id.String()
+	}
+	return fmt.Sprintf("(%s;%s;%s;%s;%s;%s)", ace.Type, strings.Join(ace.Flags, ""),
+		strings.Join(rightNames(ace.Rights), ""), guid(ace.ObjectType), guid(ace.InheritedObjectType), ace.Trustee)
+}
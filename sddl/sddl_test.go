@@ -0,0 +1,218 @@
+package sddl
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// The defaultSecurityDescriptor of the group class, shortened
+const groupSD = "D:(A;;RPWPCRCCDCLCLORCWOWDSDDTSW;;;DA)(A;;RPWPCRCCDCLCLORCWOWDSDDTSW;;;SY)" +
+	"(A;;RPLCLORC;;;AU)(OA;;CR;ab721a55-1e2f-11d0-9819-00aa0040529b;;AU)" +
+	"(OA;CIIO;RP;4c164200-20c0-11d0-a768-00aa006e0529;bf967aba-0de6-11d0-a285-00aa003049e2;RU)" +
+	"S:(AU;SA;WPCRCCDCWOWDSDDT;;;WD)"
+
+func TestParse(t *testing.T) {
+	sd, err := Parse(groupSD)
+	if err != nil {
+		t.Fatal(err)
+	}
+	full := RightDSReadProperty | RightDSWriteProperty | RightDSControlAccess | RightDSCreateChild |
+		RightDSDeleteChild | RightDSListChildren | RightDSListObject | RightReadControl | RightWriteOwner |
+		RightWriteDAC | RightDelete | RightDSDeleteTree | RightDSSelf
+	want := &SecurityDescriptor{
+		DACL: &ACL{ACEs: []ACE{
+			{Type: "A", Rights: full, Trustee: "DA"},
+			{Type: "A", Rights: full, Trustee: "SY"},
+			{Type: "A", Rights: RightDSReadProperty | RightDSListChildren | RightDSListObject | RightReadControl, Trustee: "AU"},
+			{Type: "OA", Rights: RightDSControlAccess, ObjectType: uuid.MustParse("ab721a55-1e2f-11d0-9819-00aa0040529b"), Trustee: "AU"},
+			{
+				Type:                "OA",
+				Flags:               []string{"CI", "IO"},
+				Rights:              RightDSReadProperty,
+				ObjectType:          uuid.MustParse("4c164200-20c0-11d0-a768-00aa006e0529"),
+				InheritedObjectType: uuid.MustParse("bf967aba-0de6-11d0-a285-00aa003049e2"),
+				Trustee:             "RU",
+			},
+		}},
+		SACL: &ACL{ACEs: []ACE{
+			{Type: "AU", Flags: []string{"SA"}, Rights: full &^ (RightDSReadProperty | RightDSListChildren | RightDSListObject | RightReadControl | RightDSSelf), Trustee: "WD"},
+		}},
+	}
+	if !reflect.DeepEqual(sd, want) {
+		t.Errorf("Parse() = %+v, want %+v", sd, want)
+	}
+}
+
+func TestParseComponents(t *testing.T) {
+	tests := []struct {
+		in   string
+		want *SecurityDescriptor
+	}{
+		{"", &SecurityDescriptor{}},
+		{"O:DAG:DA", &SecurityDescriptor{Owner: "DA", Group: "DA"}},
+		{"O:S-1-5-21-1-2-3-512G:SYD:PAI", &SecurityDescriptor{Owner: "S-1-5-21-1-2-3-512", Group: "SY", DACL: &ACL{Flags: "PAI"}}},
+		{" D:(A;;0x1f01ff;;;BA) ", &SecurityDescriptor{DACL: &ACL{ACEs: []ACE{{Type: "A", Rights: 0x1f01ff, Trustee: "BA"}}}}},
+		{"D:(A;;FA;;;BA)", &SecurityDescriptor{DACL: &ACL{ACEs: []ACE{{Type: "A", Rights: 0x1f01ff, Trustee: "BA"}}}}},
+		{"D:(A;;GA;;;S-1-5-32-544)S:", &SecurityDescriptor{
+			DACL: &ACL{ACEs: []ACE{{Type: "A", Rights: RightGenericAll, Trustee: "S-1-5-32-544"}}},
+			SACL: &ACL{},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, in := range []string{
+		"X:DA",
+		"O",
+		"D:(A;;GA;;BA)",
+		"D:(A;;GA;;;BA",
+		"D:(A;;GA;;;BA)x",
+		"D:(A;;XX;;;BA)",
+		"D:(A;;GAR;;;BA)",
+		"D:(A;;0xzz;;;BA)",
+		"D:(A;C;GA;;;BA)",
+		"D:(OA;;CR;not-a-guid;;AU)",
+		"D:(OA;;CR;;not-a-guid;AU)",
+	} {
+		if sd, err := Parse(in); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Parse(%q) = %+v, %v, want ErrInvalid", in, sd, err)
+		}
+	}
+}
+
+func TestParseConditional(t *testing.T) {
+	for _, in := range []string{
+		`D:(XA;;FX;;;S-1-1-0;(@User.Title=="PM"))`,
+		`D:(XD;;FX;;;S-1-1-0;(Member_of {SID(BA)}))(A;;GA;;;BA)`,
+		`D:(ZA;;CR;;;AU;(@User.Dept=="R(D)"))`,
+		`S:(RA;;;;;WD;("Project",TS,0,"Windows","SQL"))`,
+	} {
+		if sd, err := Parse(in); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("Parse(%q) = %+v, %v, want ErrUnsupported", in, sd, err)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"O:DAG:DAD:PAI(A;CI;RPWP;;;AU)", "O:DAG:DAD:PAI(A;CI;RPWP;;;AU)"},
+		// Rights are written in a fixed order
+		{"D:(A;;WPRP;;;AU)", "D:(A;;RPWP;;;AU)"},
+		// Masks that are not made of named rights are written in hexadecimal
+		{"D:(A;;0x100000;;;WD)", "D:(A;;0x100000;;;WD)"},
+		{"D:(A;;FA;;;BA)", "D:(A;;0x1f01ff;;;BA)"},
+		{"D:(A;;;;;BA)", "D:(A;;0x0;;;BA)"},
+		{groupSD, "D:(A;;RPWPCCDCLCSWLODTCRRCWDWOSD;;;DA)(A;;RPWPCCDCLCSWLODTCRRCWDWOSD;;;SY)" +
+			"(A;;RPLCLORC;;;AU)(OA;;CR;ab721a55-1e2f-11d0-9819-00aa0040529b;;AU)" +
+			"(OA;CIIO;RP;4c164200-20c0-11d0-a768-00aa006e0529;bf967aba-0de6-11d0-a285-00aa003049e2;RU)" +
+			"S:(AU;SA;WPCCDCDTCRWDWOSD;;;WD)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			sd, err := Parse(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := sd.String()
+			if got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+			again, err := Parse(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(again, sd) {
+				t.Errorf("Parse(String()) = %+v, want %+v", again, sd)
+			}
+		})
+	}
+}
+
+func TestACE(t *testing.T) {
+	tests := []struct {
+		ace             ACE
+		allow, deny     bool
+		inherit, audits bool
+	}{
+		{ACE{Type: "A", Flags: []string{"CI"}}, true, false, true, false},
+		{ACE{Type: "OA"}, true, false, false, false},
+		{ACE{Type: "D"}, false, true, false, false},
+		{ACE{Type: "OD", Flags: []string{"ci"}}, false, true, true, false},
+		{ACE{Type: "AU", Flags: []string{"SA", "FA"}}, false, false, false, true},
+	}
+	for _, tt := range tests {
+		if got := tt.ace.IsAllow(); got != tt.allow {
+			t.Errorf("%v IsAllow() = %v", tt.ace.Type, got)
+		}
+		if got := tt.ace.IsDeny(); got != tt.deny {
+			t.Errorf("%v IsDeny() = %v", tt.ace.Type, got)
+		}
+		if got := tt.ace.HasFlag("CI"); got != tt.inherit {
+			t.Errorf("%v HasFlag(CI) = %v", tt.ace.Type, got)
+		}
+		if got := tt.ace.HasFlag("FA"); got != tt.audits {
+			t.Errorf("%v HasFlag(FA) = %v", tt.ace.Type, got)
+		}
+	}
+	if !(&ACL{Flags: "PAI"}).Protected() || (&ACL{Flags: "AI"}).Protected() {
+		t.Error("Protected() does not follow the P flag")
+	}
+}
+
+func TestResolveTrustee(t *testing.T) {
+	const domain = "S-1-5-21-1004336348-1177238915-682003330"
+	tests := []struct {
+		trustee, domain string
+		want            string
+		ok              bool
+	}{
+		{"SY", "", "S-1-5-18", true},
+		{"BA", "", "S-1-5-32-544", true},
+		{"WD", domain, "S-1-1-0", true},
+		{"DA", domain, domain + "-512", true},
+		{"DA", "", "", false},
+		{"S-1-5-21-1-2-3-1105", "", "S-1-5-21-1-2-3-1105", true},
+		{"QQ", domain, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ResolveTrustee(tt.trustee, tt.domain)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ResolveTrustee(%q, %q) = %q, %v, want %q, %v", tt.trustee, tt.domain, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestResolveForestTrustee(t *testing.T) {
+	const domain = "S-1-5-21-1004336348-1177238915-682003330"
+	const root = "S-1-5-21-1-2-3"
+	tests := []struct {
+		trustee string
+		want    string
+	}{
+		{"DA", domain + "-512"},
+		{"EA", root + "-519"},
+		{"SA", root + "-518"},
+		{"AU", "S-1-5-11"},
+	}
+	for _, tt := range tests {
+		if got, ok := ResolveForestTrustee(tt.trustee, domain, root); got != tt.want || !ok {
+			t.Errorf("ResolveForestTrustee(%q) = %q, %v, want %q", tt.trustee, got, ok, tt.want)
+		}
+	}
+}
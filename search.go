@@ -0,0 +1,667 @@
+package adsi
+
+import (
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-adsi/adsi/api"
+	"github.com/google/uuid"
+	"github.com/scjalliance/comshim"
+)
+
+// SearchScope specifies how far a search descends beneath its base object.
+type SearchScope int
+
+// Search scopes. The zero value is ScopeSubtree.
+const (
+	ScopeSubtree SearchScope = iota
+	ScopeOneLevel
+	ScopeBase
+)
+
+// DefaultPageSize is the page size used by searches that do not specify one.
+const DefaultPageSize = 1000
+
+// Query describes a directory search.
+type Query struct {
+	// Filter is an LDAP search filter. If empty "(objectClass=*)" is used.
+	Filter string
+
+	// Attributes is the list of attributes to return for each row. If empty
+	// all attributes are returned.
+	Attributes []string
+
+	// Scope determines how far the search descends beneath its base object.
+	Scope SearchScope
+
+	// PageSize is the number of rows requested from the server in each page.
+	// If zero DefaultPageSize is used. If negative paging is disabled, which
+	// limits the results to the server's maximum page size.
+	PageSize int
+
+	// SizeLimit is the maximum number of rows that will be returned. If zero
+	// no limit is applied.
+	SizeLimit int
+
+	// TimeLimit is the maximum amount of time the server will spend on the
+	// search. If zero no limit is applied.
+	TimeLimit time.Duration
+
+	// Tombstone includes deleted objects in the search results.
+	Tombstone bool
+
+	// SecurityMask selects the parts of nTSecurityDescriptor that are
+	// returned, as a combination of the *SecurityInformation flags. If zero
+	// all parts are requested, which omits the attribute entirely unless
+	// the caller has the right to read the SACL.
+	SecurityMask uint32
+
+	// DirSync makes the search a directory synchronization search, which
+	// returns only the objects and attributes that have changed since the
+	// search that returned DirSyncCookie. If the cookie is empty every
+	// object is returned. The updated cookie is available from the result
+	// set once all rows have been read. DirSync searches must use a subtree
+	// scope rooted at the head of a naming context and are not paged.
+	DirSync       bool
+	DirSyncCookie []byte
+
+	// DirSyncFlags holds a combination of the LDAP_DIRSYNC_* flags in the
+	// api package.
+	DirSyncFlags uint32
+
+	// RawValues records the ADSTYPE and data of every value in Column.Raw,
+	// including values that cannot be converted to Go types and are left
+	// out of Column.Values.
+	RawValues bool
+}
+
+// prefs returns the search preferences that implement the query.
+func (q *Query) prefs() []api.ADS_SEARCHPREF_INFO {
+	var scope uint32
+	switch q.Scope {
+	case ScopeBase:
+		scope = api.ADS_SCOPE_BASE
+	case ScopeOneLevel:
+		scope = api.ADS_SCOPE_ONELEVEL
+	default:
+		scope = api.ADS_SCOPE_SUBTREE
+	}
+	prefs := []api.ADS_SEARCHPREF_INFO{
+		api.NewSearchPrefInteger(api.ADS_SEARCHPREF_SEARCH_SCOPE, scope),
+		// Results are streamed, so there's no need for ADSI to keep a copy
+		api.NewSearchPrefBoolean(api.ADS_SEARCHPREF_CACHE_RESULTS, false),
+	}
+	switch {
+	case q.DirSync:
+		// The server returns DirSync results in batches of its own choosing
+	case q.PageSize == 0:
+		prefs = append(prefs, api.NewSearchPrefInteger(api.ADS_SEARCHPREF_PAGESIZE, DefaultPageSize))
+	case q.PageSize > 0:
+		prefs = append(prefs, api.NewSearchPrefInteger(api.ADS_SEARCHPREF_PAGESIZE, uint32(q.PageSize)))
+	}
+	if q.SizeLimit > 0 {
+		prefs = append(prefs, api.NewSearchPrefInteger(api.ADS_SEARCHPREF_SIZE_LIMIT, uint32(q.SizeLimit)))
+	}
+	if q.TimeLimit > 0 {
+		prefs = append(prefs, api.NewSearchPrefInteger(api.ADS_SEARCHPREF_TIME_LIMIT, uint32(q.TimeLimit/time.Second)))
+	}
+	if q.Tombstone {
+		prefs = append(prefs, api.NewSearchPrefBoolean(api.ADS_SEARCHPREF_TOMBSTONE, true))
+	}
+	if q.SecurityMask != 0 {
+		prefs = append(prefs, api.NewSearchPrefInteger(api.ADS_SEARCHPREF_SECURITY_MASK, q.SecurityMask))
+	}
+	if q.DirSync {
+		prefs = append(prefs, api.NewSearchPrefProvSpecific(api.ADS_SEARCHPREF_DIRSYNC, q.DirSyncCookie))
+		if q.DirSyncFlags != 0 {
+			prefs = append(prefs, api.NewSearchPrefInteger(api.ADS_SEARCHPREF_DIRSYNC_FLAG, q.DirSyncFlags))
+		}
+	}
+	return prefs
+}
+
+// Searcher provides access to directory searches rooted at a particular
+// object.
+type Searcher struct {
+	m     sync.RWMutex
+	iface *api.IDirectorySearch
+	h     *hooks
+}
+
+// NewSearcher returns a searcher that manages the given COM interface.
+func NewSearcher(iface *api.IDirectorySearch) *Searcher {
+	comshim.Add(1)
+	return track(&Searcher{iface: iface})
+}
+
+func (s *Searcher) closed() bool {
+	return (s.iface == nil)
+}
+
+// Close will release resources consumed by the searcher. It should be
+// called when the searcher is no longer needed.
+func (s *Searcher) Close() error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.closed() {
+		return nil
+	}
+	untrack(s)
+	defer comshim.Done()
+	defer s.h.released()
+	s.h.run(func() { s.iface.Release() })
+	s.iface = nil
+	return nil
+}
+
+// Search executes the given query and returns a result set that provides
+// access to the matching rows.
+//
+// The returned result set consumes resources until it is closed. It is the
+// caller's responsibility to call Close on the result set when it is no
+// longer needed. The result set remains valid after the searcher is closed.
+func (s *Searcher) Search(q Query) (result *SearchResult, err error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.closed() {
+		return nil, ErrClosed
+	}
+	s.h.run(func() { result, err = s.search(q) })
+	return
+}
+
+// search executes the query on the searcher's worker. The caller must hold
+// the searcher's lock.
+func (s *Searcher) search(q Query) (result *SearchResult, err error) {
+	defer beginCall()()
+	result = &SearchResult{raw: q.RawValues, h: s.h, filter: q.Filter, start: time.Now()}
+	if s.h.observing() {
+		result.base = s.searchBase()
+	}
+	defer func() {
+		if err != nil {
+			if result.base == "" {
+				result.base = s.searchBase()
+			}
+			err = wrapError("Search", result.base, err)
+			result.searched(err)
+			result = nil
+		}
+	}()
+	err = s.iface.SetSearchPreference(q.prefs())
+	// The preferences refer to the memory of the DirSync cookie
+	runtime.KeepAlive(q.DirSyncCookie)
+	if err != nil {
+		return
+	}
+	filter := q.Filter
+	if filter == "" {
+		filter = "(objectClass=*)"
+	}
+	handle, err := s.iface.ExecuteSearch(filter, q.Attributes)
+	if err != nil {
+		return
+	}
+	s.iface.AddRef()
+	comshim.Add(1)
+	result.iface, result.handle = s.iface, handle
+	result.h.opened()
+	return track(result), nil
+}
+
+// SearchResult provides an iterator for the rows returned by a search.
+type SearchResult struct {
+	m       sync.Mutex
+	iface   *api.IDirectorySearch
+	handle  api.ADS_SEARCH_HANDLE
+	started bool
+	raw     bool
+
+	// Conversion state reused between rows
+	dec   api.Decoder
+	col   api.ADS_SEARCH_COLUMN
+	width int
+
+	// Logging and tracing state
+	h        *hooks
+	base     string
+	filter   string
+	start    time.Time
+	rows     int
+	reported bool
+}
+
+func (r *SearchResult) closed() bool {
+	return (r.iface == nil)
+}
+
+// Close will release resources consumed by the result set. It should be
+// called when the result set is no longer needed. It returns an error if
+// the search could not be closed cleanly.
+func (r *SearchResult) Close() error {
+	r.m.Lock()
+	defer r.m.Unlock()
+	if r.closed() {
+		return nil
+	}
+	untrack(r)
+	defer comshim.Done()
+	defer r.h.released()
+	r.searched(nil)
+	var err error
+	r.h.run(func() {
+		defer beginCall()()
+		err = r.iface.CloseSearchHandle(r.handle)
+		r.iface.Release()
+		err = wrapError("CloseSearchHandle", r.base, err)
+	})
+	r.iface = nil
+	return err
+}
+
+// Next moves the iterator to the next row and returns it. If it has reached
+// the end of the results it will return io.EOF. If the result set has already
+// been closed it will return ErrClosed.
+func (r *SearchResult) Next() (row *Row, err error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	if r.closed() {
+		return nil, ErrClosed
+	}
+	r.h.run(func() {
+		// Rows of a search usually have the same number of columns, so the
+		// previous row is used to size the next one
+		row = &Row{columns: make([]Column, 0, r.width)}
+		if err = r.next(row); err != nil {
+			row = nil
+		}
+	})
+	return
+}
+
+// Scan moves the iterator to the next row and stores it in row, reusing the
+// columns it already holds. Unlike Next it does not allocate a new row for
+// each call, which keeps memory use flat when reading large result sets:
+//
+//	var row adsi.Row
+//	for err := result.Scan(&row); err == nil; err = result.Scan(&row) {
+//		...
+//	}
+//
+// The columns and value slices of row are overwritten by the next call to
+// Scan. Use Row.Copy to retain a row. The values themselves are not reused
+// and may be retained.
+//
+// If it has reached the end of the results it will return io.EOF. If the
+// result set has already been closed it will return ErrClosed.
+func (r *SearchResult) Scan(row *Row) (err error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	if r.closed() {
+		return ErrClosed
+	}
+	r.h.run(func() { err = r.next(row) })
+	return
+}
+
+// next reads the next row into row on the result set's worker. The caller
+// must hold the result set's lock.
+func (r *SearchResult) next(row *Row) (err error) {
+	defer beginCall()()
+	if r.started {
+		err = r.iface.GetNextRow(r.handle)
+	} else {
+		err = r.iface.GetFirstRow(r.handle)
+		r.started = true
+	}
+	if err == api.ErrNoMoreRows {
+		r.searched(nil)
+		return io.EOF
+	}
+	if err != nil {
+		err = wrapError("Next", r.base, err)
+		r.searched(err)
+		return err
+	}
+	r.rows++
+	return r.readColumns(r.iface, row)
+}
+
+// columnSource reads the columns of the current row of a search. It is
+// implemented by api.IDirectorySearch.
+type columnSource interface {
+	GetNextColumn(handle api.ADS_SEARCH_HANDLE, column *api.ADS_SEARCH_COLUMN) error
+	FreeColumn(column *api.ADS_SEARCH_COLUMN) error
+}
+
+// readColumns reads the columns of the current row from src into row.
+func (r *SearchResult) readColumns(src columnSource, row *Row) error {
+	// The column is held by the result set so that it is not allocated for
+	// each call
+	col := &r.col
+	n := 0
+	for ; ; n++ {
+		*col = api.ADS_SEARCH_COLUMN{}
+		err := src.GetNextColumn(r.handle, col)
+		if err == api.ErrNoMoreColumns {
+			break
+		}
+		if err != nil {
+			row.reset(n)
+			return err
+		}
+		column := row.column(n)
+		column.Name = r.dec.String(col.AttrName)
+		column.Type = col.ADsType
+		values := col.ValueSlice()
+		if column.Values == nil && len(values) > 0 {
+			column.Values = make([]interface{}, 0, len(values))
+		}
+		for i := range values {
+			value, convErr := r.dec.Value(&values[i])
+			if convErr == nil {
+				column.Values = append(column.Values, value)
+			}
+			if r.raw {
+				column.Raw = append(column.Raw, RawValue{Type: values[i].Type, Data: values[i].RawData(), Value: value})
+			}
+		}
+		src.FreeColumn(col)
+	}
+	row.reset(n)
+	r.width = n
+	return nil
+}
+
+// DirSyncCookie returns the updated cookie of a DirSync search, which can be
+// used with Query.DirSyncCookie to retrieve subsequent changes. It must be
+// called after Next has returned io.EOF and before the result set is
+// closed.
+func (r *SearchResult) DirSyncCookie() (cookie []byte, err error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	if r.closed() {
+		return nil, ErrClosed
+	}
+	r.h.run(func() {
+		defer beginCall()()
+		var col api.ADS_SEARCH_COLUMN
+		if err = r.iface.GetColumn(r.handle, api.ADS_DIRSYNC_COOKIE, &col); err != nil {
+			err = wrapError("DirSyncCookie", r.base, err)
+			return
+		}
+		defer r.iface.FreeColumn(&col)
+		if values := col.ValueSlice(); len(values) > 0 {
+			cookie = values[0].BytesValue()
+		}
+	})
+	return
+}
+
+// All reads all of the remaining rows in the result set and returns them.
+func (r *SearchResult) All() (rows []*Row, err error) {
+	for {
+		row, err := r.Next()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+}
+
+// Column holds the values of a single attribute within a row of search
+// results.
+type Column struct {
+	// Name is the name of the attribute.
+	Name string
+
+	// Type is the ADSTYPE of the values, as defined by the ADSTYPE_*
+	// constants in the api package.
+	Type uint32
+
+	// Values holds the attribute values converted to native Go types as
+	// described by api.ADSVALUE.Value.
+	Values []interface{}
+
+	// Raw holds every value of the attribute as returned by ADSI, when the
+	// query asked for Query.RawValues.
+	Raw []RawValue
+}
+
+// RawValue is a single attribute value as returned by ADSI, for callers that
+// interpret unusual attribute syntaxes themselves.
+type RawValue struct {
+	// Type is the ADSTYPE of the value, as defined by the ADSTYPE_*
+	// constants in the api package.
+	Type uint32
+
+	// Data holds a copy of the data of the value, as described by
+	// api.ADSVALUE.RawData.
+	Data []byte
+
+	// Value holds the value converted to a native Go type, or nil if its
+	// type cannot be converted.
+	Value interface{}
+}
+
+// Row is a single row of search results. It holds a copy of the values
+// returned by the server and remains valid after its result set is closed.
+//
+// A row returned by Next is never modified by the result set. A row passed
+// to SearchResult.Scan is overwritten by each call.
+type Row struct {
+	columns []Column
+}
+
+// Copy returns a copy of the row that is not affected by later calls to
+// SearchResult.Scan.
+func (r *Row) Copy() *Row {
+	columns := make([]Column, len(r.columns))
+	for i, col := range r.columns {
+		columns[i] = col
+		if col.Values != nil {
+			columns[i].Values = append([]interface{}(nil), col.Values...)
+		}
+		if col.Raw != nil {
+			columns[i].Raw = append([]RawValue(nil), col.Raw...)
+		}
+	}
+	return &Row{columns: columns}
+}
+
+// column returns the nth column of the row, which must be at most the
+// number of columns already read into it, reusing the memory of a column
+// from a previous row if there is one. Its values are emptied.
+func (r *Row) column(n int) *Column {
+	switch {
+	case n >= cap(r.columns):
+		r.columns = append(r.columns, Column{})
+	case n >= len(r.columns):
+		r.columns = r.columns[:n+1]
+	}
+	col := &r.columns[n]
+	clear(col.Values)
+	col.Values = col.Values[:0]
+	clear(col.Raw)
+	col.Raw = col.Raw[:0]
+	return col
+}
+
+// reset truncates the row to its first n columns. The values of the columns
+// that are dropped are cleared so that they can be garbage collected, but
+// their memory is kept for the next row.
+func (r *Row) reset(n int) {
+	for i := n; i < len(r.columns); i++ {
+		clear(r.columns[i].Values)
+		r.columns[i].Values = r.columns[i].Values[:0]
+		clear(r.columns[i].Raw)
+		r.columns[i].Raw = r.columns[i].Raw[:0]
+	}
+	if n < len(r.columns) {
+		r.columns = r.columns[:n]
+	}
+}
+
+// Columns returns the columns of the row in the order they were returned by
+// the server.
+func (r *Row) Columns() []Column {
+	return r.columns
+}
+
+// Column returns the column with the given name, which is matched
+// case-insensitively, and whether the row contains it. Its Raw field holds
+// the values as returned by ADSI if the query asked for Query.RawValues.
+func (r *Row) Column(name string) (column Column, ok bool) {
+	if col := r.lookup(name); col != nil {
+		return *col, true
+	}
+	return Column{}, false
+}
+
+// Has returns true if the row contains a column with the given name. Column
+// names are matched case-insensitively.
+func (r *Row) Has(name string) bool {
+	return r.lookup(name) != nil
+}
+
+// Path returns the ADsPath of the row's object, or an empty string if the
+// row has no ADsPath column. ADSI only returns the ADsPath when it is named
+// in the attributes of the query, or when no attributes are named and every
+// attribute of the object is returned.
+func (r *Row) Path() string {
+	return r.AttrString("ADsPath")
+}
+
+// Attr returns the values of the column with the given name. If the row does
+// not contain the column nil is returned.
+func (r *Row) Attr(name string) []interface{} {
+	if col := r.lookup(name); col != nil {
+		return col.Values
+	}
+	return nil
+}
+
+// AttrStringSlice returns the string values of the column with the given
+// name. Any non-string values will be ommitted.
+func (r *Row) AttrStringSlice(name string) (values []string) {
+	for _, element := range r.Attr(name) {
+		if v, ok := element.(string); ok {
+			values = append(values, v)
+		}
+	}
+	return
+}
+
+// AttrString returns the first string value of the column with the given
+// name, or an empty string if there is none.
+func (r *Row) AttrString(name string) string {
+	if values := r.AttrStringSlice(name); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// AttrBytesSlice returns the octet string values of the column with the
+// given name. Any non-byte values will be ommitted.
+func (r *Row) AttrBytesSlice(name string) (values [][]byte) {
+	for _, element := range r.Attr(name) {
+		if v, ok := element.([]byte); ok {
+			values = append(values, v)
+		}
+	}
+	return
+}
+
+// AttrBytes returns the first octet string value of the column with the
+// given name, or nil if there is none.
+func (r *Row) AttrBytes(name string) []byte {
+	if values := r.AttrBytesSlice(name); len(values) > 0 {
+		return values[0]
+	}
+	return nil
+}
+
+// AttrBool returns the first boolean value of the column with the given
+// name, or false if there is none.
+func (r *Row) AttrBool(name string) bool {
+	for _, element := range r.Attr(name) {
+		if v, ok := element.(bool); ok {
+			return v
+		}
+	}
+	return false
+}
+
+// AttrInt64Slice returns the integer values of the column with the given
+// name. Any non-integer values will be ommitted.
+func (r *Row) AttrInt64Slice(name string) (values []int64) {
+	for _, element := range r.Attr(name) {
+		switch v := element.(type) {
+		case int32:
+			values = append(values, int64(v))
+		case int64:
+			values = append(values, v)
+		}
+	}
+	return
+}
+
+// AttrInt64 returns the first integer value of the column with the given
+// name, or 0 if there is none.
+func (r *Row) AttrInt64(name string) int64 {
+	if values := r.AttrInt64Slice(name); len(values) > 0 {
+		return values[0]
+	}
+	return 0
+}
+
+// AttrInt returns the first integer value of the column with the given name,
+// or 0 if there is none.
+func (r *Row) AttrInt(name string) int {
+	return int(r.AttrInt64(name))
+}
+
+// AttrTime returns the first value of the column with the given name as a
+// time. Large integer values are interpreted as FILETIME-style timestamps as
+// described by TimeFromFileTime. The zero time is returned if there is no
+// value.
+func (r *Row) AttrTime(name string) time.Time {
+	for _, element := range r.Attr(name) {
+		switch v := element.(type) {
+		case time.Time:
+			return v
+		case int64:
+			return TimeFromFileTime(v)
+		case string:
+			if t, err := ParseGeneralizedTime(v); err == nil {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// AttrGUID returns the first value of the column with the given name as a
+// GUID. The value must be a 16 byte octet string, such as objectGUID, and is
+// interpreted using the same byte ordering as Object.AttrGUID.
+func (r *Row) AttrGUID(name string) (guid uuid.UUID) {
+	if b := r.AttrBytes(name); len(b) == 16 {
+		copy(guid[:], b)
+	}
+	return
+}
+
+func (r *Row) lookup(name string) *Column {
+	for i := range r.columns {
+		if strings.EqualFold(r.columns[i].Name, name) {
+			return &r.columns[i]
+		}
+	}
+	return nil
+}
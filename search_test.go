@@ -0,0 +1,131 @@
+package adsi
+
+import (
+	"reflect"
+	"testing"
+	"unicode/utf16"
+	"unsafe"
+
+	"github.com/go-adsi/adsi/api"
+)
+
+// columns is a columnSource that returns the same columns for every row.
+type columns struct {
+	cols []api.ADS_SEARCH_COLUMN
+	next int
+}
+
+func (c *columns) GetNextColumn(handle api.ADS_SEARCH_HANDLE, column *api.ADS_SEARCH_COLUMN) error {
+	if c.next == len(c.cols) {
+		c.next = 0
+		return api.ErrNoMoreColumns
+	}
+	*column = c.cols[c.next]
+	c.next++
+	return nil
+}
+
+func (c *columns) FreeColumn(column *api.ADS_SEARCH_COLUMN) error {
+	return nil
+}
+
+// keep holds the memory referenced by the columns built by column, which
+// the garbage collector cannot see through the unions of the values.
+var keep []interface{}
+
+// utf16Ptr returns a pointer to a null-terminated UTF-16 copy of s.
+func utf16Ptr(s string) *uint16 {
+	p := &append(utf16.Encode([]rune(s)), 0)[0]
+	keep = append(keep, p)
+	return p
+}
+
+// column returns a search column with the given name holding the given
+// string values.
+func column(name string, values ...string) api.ADS_SEARCH_COLUMN {
+	adsValues := make([]api.ADSVALUE, len(values))
+	for i, value := range values {
+		adsValues[i].Type = api.ADSTYPE_CASE_IGNORE_STRING
+		// The union of the value follows its 8 byte header
+		*(**uint16)(unsafe.Add(unsafe.Pointer(&adsValues[i]), 8)) = utf16Ptr(value)
+	}
+	keep = append(keep, adsValues)
+	col := api.ADS_SEARCH_COLUMN{AttrName: utf16Ptr(name), ADsType: api.ADSTYPE_CASE_IGNORE_STRING, NumValues: uint32(len(values))}
+	if len(values) > 0 {
+		col.ADsValues = &adsValues[0]
+	}
+	return col
+}
+
+// userColumns returns the columns of a typical row of a search for users.
+func userColumns() *columns {
+	return &columns{cols: []api.ADS_SEARCH_COLUMN{
+		column("distinguishedName", "CN=Jane Doe,OU=Staff,DC=example,DC=com"),
+		column("objectClass", "top", "person", "organizationalPerson", "user"),
+		column("sAMAccountName", "jdoe"),
+		column("userPrincipalName", "jdoe@example.com"),
+		column("ADsPath", "LDAP://CN=Jane Doe,OU=Staff,DC=example,DC=com"),
+	}}
+}
+
+func TestSearchResultReadColumns(t *testing.T) {
+	r := new(SearchResult)
+	src := userColumns()
+	var row Row
+	for i := 0; i < 2; i++ {
+		if err := r.readColumns(src, &row); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := row.AttrString("sAMAccountName"), "jdoe"; got != want {
+			t.Errorf("sAMAccountName = %q, want %q", got, want)
+		}
+		if got, want := row.AttrStringSlice("objectClass"), []string{"top", "person", "organizationalPerson", "user"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("objectClass = %q, want %q", got, want)
+		}
+		if got, want := row.Path(), "LDAP://CN=Jane Doe,OU=Staff,DC=example,DC=com"; got != want {
+			t.Errorf("Path() = %q, want %q", got, want)
+		}
+		if got, want := len(row.Columns()), 5; got != want {
+			t.Errorf("row has %d columns, want %d", got, want)
+		}
+	}
+
+	// A shorter row reuses the columns of the longer one
+	src.cols = src.cols[:2]
+	if err := r.readColumns(src, &row); err != nil {
+		t.Fatal(err)
+	}
+	if got := row.AttrString("sAMAccountName"); got != "" {
+		t.Errorf("sAMAccountName of truncated row = %q, want \"\"", got)
+	}
+}
+
+// BenchmarkSearchResultNext measures the conversion of the columns of a row
+// by next, with a new row for each call, as made by Next.
+func BenchmarkSearchResultNext(b *testing.B) {
+	r := new(SearchResult)
+	src := userColumns()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		row := &Row{columns: make([]Column, 0, r.width)}
+		if err := r.readColumns(src, row); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkSearchResultScan measures the conversion of the columns of a row
+// by next into a reused row, as made by Scan.
+func BenchmarkSearchResultScan(b *testing.B) {
+	r := new(SearchResult)
+	src := userColumns()
+	var row Row
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := r.readColumns(src, &row); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
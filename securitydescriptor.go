@@ -0,0 +1,445 @@
+package adsi
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+	"unsafe"
+
+	"github.com/go-adsi/adsi/api"
+	"github.com/go-adsi/adsi/comiid"
+	ole "github.com/go-ole/go-ole"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidSecurityDescriptor is returned when a binary security descriptor
+// cannot be parsed.
+var ErrInvalidSecurityDescriptor = errors.New("invalid security descriptor")
+
+// Security descriptor control flags.
+const (
+	SecurityDescriptorDACLPresent   uint16 = 0x0004
+	SecurityDescriptorSACLPresent   uint16 = 0x0010
+	SecurityDescriptorDACLProtected uint16 = 0x1000
+	SecurityDescriptorSACLProtected uint16 = 0x2000
+	SecurityDescriptorSelfRelative  uint16 = 0x8000
+)
+
+// ACE types that are understood by SecurityDescriptor. ACEs of other types
+// are preserved as opaque data.
+const (
+	ACETypeAccessAllowed       uint8 = 0x00
+	ACETypeAccessDenied        uint8 = 0x01
+	ACETypeSystemAudit         uint8 = 0x02
+	ACETypeAccessAllowedObject uint8 = 0x05
+	ACETypeAccessDeniedObject  uint8 = 0x06
+	ACETypeSystemAuditObject   uint8 = 0x07
+)
+
+// ACE flags.
+const (
+	ACEFlagObjectInherit    uint8 = 0x01
+	ACEFlagContainerInherit uint8 = 0x02
+	ACEFlagNoPropagate      uint8 = 0x04
+	ACEFlagInheritOnly      uint8 = 0x08
+	ACEFlagInherited        uint8 = 0x10
+	ACEFlagAuditSuccess     uint8 = 0x40
+	ACEFlagAuditFailure     uint8 = 0x80
+)
+
+// Flags of object ACEs that record which of the object types are present.
+const (
+	aceObjectTypePresent          uint32 = 0x1
+	aceInheritedObjectTypePresent uint32 = 0x2
+)
+
+// Security information flags that select the parts of a security descriptor
+// that are read or written, as used by the SD flags control.
+const (
+	OwnerSecurityInformation uint32 = 0x1
+	GroupSecurityInformation uint32 = 0x2
+	DACLSecurityInformation  uint32 = 0x4
+	SACLSecurityInformation  uint32 = 0x8
+)
+
+// SecurityDescriptor is the decoded form of a binary self-relative security
+// descriptor, such as the value of nTSecurityDescriptor or
+// msDS-AllowedToActOnBehalfOfOtherIdentity. The sddl package handles the
+// string form used by defaultSecurityDescriptor.
+type SecurityDescriptor struct {
+	Control uint16
+	Owner   *SID
+	Group   *SID
+	DACL    *ACL
+	SACL    *ACL
+}
+
+// ACL is an access control list.
+type ACL struct {
+	Revision uint8
+	ACEs     []ACE
+}
+
+// ACE is an access control entry.
+type ACE struct {
+	Type  uint8
+	Flags uint8
+	Mask  uint32
+
+	// ObjectType and InheritedObjectType are only meaningful for object
+	// ACEs. They are uuid.Nil when absent.
+	ObjectType          uuid.UUID
+	InheritedObjectType uuid.UUID
+
+	SID SID
+
+	// Data holds any bytes that follow the SID, such as the application data
+	// of callback ACEs. For ACEs of types that are not understood it holds
+	// the entire body of the ACE and the other fields are unset.
+	Data []byte
+}
+
+// IsObjectACE returns true if the ACE is one of the object ACE types, which
+// can be restricted to a property, property set, extended right or class.
+func (ace *ACE) IsObjectACE() bool {
+	return ace.Type == ACETypeAccessAllowedObject || ace.Type == ACETypeAccessDeniedObject || ace.Type == ACETypeSystemAuditObject
+}
+
+// IsInherited returns true if the ACE was inherited from a parent object.
+func (ace *ACE) IsInherited() bool {
+	return ace.Flags&ACEFlagInherited != 0
+}
+
+func (ace *ACE) known() bool {
+	switch ace.Type {
+	case ACETypeAccessAllowed, ACETypeAccessDenied, ACETypeSystemAudit:
+		return true
+	}
+	return ace.IsObjectACE()
+}
+
+// ParseSecurityDescriptor decodes a binary self-relative security
+// descriptor.
+func ParseSecurityDescriptor(b []byte) (sd *SecurityDescriptor, err error) {
+	if len(b) < 20 || b[0] != 1 {
+		return nil, ErrInvalidSecurityDescriptor
+	}
+	sd = &SecurityDescriptor{Control: binary.LittleEndian.Uint16(b[2:])}
+	offsets := [4]uint32{}
+	for i := range offsets {
+		offsets[i] = binary.LittleEndian.Uint32(b[4+4*i:])
+	}
+	if sd.Owner, err = parseSIDAt(b, offsets[0]); err != nil {
+		return nil, err
+	}
+	if sd.Group, err = parseSIDAt(b, offsets[1]); err != nil {
+		return nil, err
+	}
+	if sd.SACL, err = parseACLAt(b, offsets[2]); err != nil {
+		return nil, err
+	}
+	if sd.DACL, err = parseACLAt(b, offsets[3]); err != nil {
+		return nil, err
+	}
+	return sd, nil
+}
+
+func parseSIDAt(b []byte, offset uint32) (*SID, error) {
+	if offset == 0 {
+		return nil, nil
+	}
+	if int(offset)+8 > len(b) {
+		return nil, ErrInvalidSecurityDescriptor
+	}
+	n := 8 + 4*int(b[offset+1])
+	if int(offset)+n > len(b) {
+		return nil, ErrInvalidSecurityDescriptor
+	}
+	sid, err := ParseSID(b[offset : int(offset)+n])
+	if err != nil {
+		return nil, ErrInvalidSecurityDescriptor
+	}
+	return &sid, nil
+}
+
+func parseACLAt(b []byte, offset uint32) (*ACL, error) {
+	if offset == 0 {
+		return nil, nil
+	}
+	if int(offset)+8 > len(b) {
+		return nil, ErrInvalidSecurityDescriptor
+	}
+	size := int(binary.LittleEndian.Uint16(b[offset+2:]))
+	count := int(binary.LittleEndian.Uint16(b[offset+4:]))
+	if size < 8 || int(offset)+size > len(b) {
+		return nil, ErrInvalidSecurityDescriptor
+	}
+	acl := &ACL{Revision: b[offset]}
+	data := b[int(offset)+8 : int(offset)+size]
+	for i := 0; i < count; i++ {
+		if len(data) < 4 {
+			return nil, ErrInvalidSecurityDescriptor
+		}
+		aceSize := int(binary.LittleEndian.Uint16(data[2:]))
+		if aceSize < 4 || aceSize > len(data) {
+			return nil, ErrInvalidSecurityDescriptor
+		}
+		ace, err := parseACE(data[:aceSize])
+		if err != nil {
+			return nil, err
+		}
+		acl.ACEs = append(acl.ACEs, ace)
+		data = data[aceSize:]
+	}
+	return acl, nil
+}
+
+func parseACE(b []byte) (ace ACE, err error) {
+	ace.Type, ace.Flags = b[0], b[1]
+	body := b[4:]
+	if !ace.known() {
+		ace.Data = append([]byte(nil), body...)
+		return ace, nil
+	}
+	if len(body) < 4 {
+		return ace, ErrInvalidSecurityDescriptor
+	}
+	ace.Mask = binary.LittleEndian.Uint32(body)
+	body = body[4:]
+	if ace.IsObjectACE() {
+		if len(body) < 4 {
+			return ace, ErrInvalidSecurityDescriptor
+		}
+		flags := binary.LittleEndian.Uint32(body)
+		body = body[4:]
+		for _, field := range []struct {
+			flag uint32
+			guid *uuid.UUID
+		}{
+			{aceObjectTypePresent, &ace.ObjectType},
+			{aceInheritedObjectTypePresent, &ace.InheritedObjectType},
+		} {
+			if flags&field.flag == 0 {
+				continue
+			}
+			if len(body) < 16 {
+				return ace, ErrInvalidSecurityDescriptor
+			}
+			*field.guid = guidFromWindowsBytes(body[:16])
+			body = body[16:]
+		}
+	}
+	if len(body) < 8 || len(body) < 8+4*int(body[1]) {
+		return ace, ErrInvalidSecurityDescriptor
+	}
+	n := 8 + 4*int(body[1])
+	if ace.SID, err = ParseSID(body[:n]); err != nil {
+		return ace, ErrInvalidSecurityDescriptor
+	}
+	if len(body) > n {
+		ace.Data = append([]byte(nil), body[n:]...)
+	}
+	return ace, nil
+}
+
+// Bytes encodes the security descriptor in binary self-relative form. The
+// DACL and SACL present flags are set to match the lists that are held.
+func (sd *SecurityDescriptor) Bytes() []byte {
+	control := sd.Control | SecurityDescriptorSelfRelative
+	control &^= SecurityDescriptorDACLPresent | SecurityDescriptorSACLPresent
+	if sd.DACL != nil {
+		control |= SecurityDescriptorDACLPresent
+	}
+	if sd.SACL != nil {
+		control |= SecurityDescriptorSACLPresent
+	}
+
+	b := make([]byte, 20)
+	b[0] = 1
+	binary.LittleEndian.PutUint16(b[2:], control)
+	put := func(field int, data []byte) {
+		if data == nil {
+			return
+		}
+		binary.LittleEndian.PutUint32(b[4+4*field:], uint32(len(b)))
+		b = append(b, data...)
+	}
+	put(2, sd.SACL.bytes())
+	put(3, sd.DACL.bytes())
+	if sd.Owner != nil {
+		put(0, sd.Owner.Bytes())
+	}
+	if sd.Group != nil {
+		put(1, sd.Group.Bytes())
+	}
+	return b
+}
+
+func (acl *ACL) bytes() []byte {
+	if acl == nil {
+		return nil
+	}
+	revision := acl.Revision
+	b := make([]byte, 8)
+	for i := range acl.ACEs {
+		if acl.ACEs[i].IsObjectACE() {
+			// Object ACEs require the directory service revision
+			revision = 4
+		}
+		b = append(b, acl.ACEs[i].bytes()...)
+	}
+	if revision == 0 {
+		revision = 2
+	}
+	b[0] = revision
+	binary.LittleEndian.PutUint16(b[2:], uint16(len(b)))
+	binary.LittleEndian.PutUint16(b[4:], uint16(len(acl.ACEs)))
+	return b
+}
+
+func (ace *ACE) bytes() []byte {
+	b := []byte{ace.Type, ace.Flags, 0, 0}
+	if ace.known() {
+		b = binary.LittleEndian.AppendUint32(b, ace.Mask)
+		if ace.IsObjectACE() {
+			var flags uint32
+			var guids []byte
+			if ace.ObjectType != uuid.Nil {
+				flags |= aceObjectTypePresent
+				guids = append(guids, windowsBytesFromGUID(ace.ObjectType)...)
+			}
+			if ace.InheritedObjectType != uuid.Nil {
+				flags |= aceInheritedObjectTypePresent
+				guids = append(guids, windowsBytesFromGUID(ace.InheritedObjectType)...)
+			}
+			b = binary.LittleEndian.AppendUint32(b, flags)
+			b = append(b, guids...)
+		}
+		b = append(b, ace.SID.Bytes()...)
+	}
+	b = append(b, ace.Data...)
+	binary.LittleEndian.PutUint16(b[2:], uint16(len(b)))
+	return b
+}
+
+// AddExplicit adds the given ACEs to the list in canonical order: explicit
+// ACEs that deny access are placed before those that allow it, and all
+// explicit ACEs are placed before inherited ones.
+func (acl *ACL) AddExplicit(aces ...ACE) {
+	for _, ace := range aces {
+		deny := ace.Type == ACETypeAccessDenied || ace.Type == ACETypeAccessDeniedObject
+		i := 0
+		for ; i < len(acl.ACEs); i++ {
+			existing := &acl.ACEs[i]
+			if existing.IsInherited() {
+				break
+			}
+			if deny && existing.Type != ACETypeAccessDenied && existing.Type != ACETypeAccessDeniedObject {
+				break
+			}
+		}
+		acl.ACEs = append(acl.ACEs, ACE{})
+		copy(acl.ACEs[i+1:], acl.ACEs[i:])
+		acl.ACEs[i] = ace
+	}
+}
+
+// SecurityDescriptor reads the owner, group and DACL of the object's
+// nTSecurityDescriptor attribute. The SACL is not requested, so the caller
+// does not need the right to read it.
+func (o *object) SecurityDescriptor() (sd *SecurityDescriptor, err error) {
+	row, err := o.baseRow(Query{
+		Attributes:   []string{"nTSecurityDescriptor"},
+		SecurityMask: OwnerSecurityInformation | GroupSecurityInformation | DACLSecurityInformation,
+	})
+	if err != nil {
+		return
+	}
+	b := row.AttrBytes("nTSecurityDescriptor")
+	if b == nil {
+		return nil, errors.New("unable to read security descriptor")
+	}
+	return ParseSecurityDescriptor(b)
+}
+
+// SetDACL replaces the DACL of the object's nTSecurityDescriptor attribute.
+// The owner, group and SACL are left unchanged, as are the control bits of
+// the existing descriptor, such as whether the DACL is protected from
+// inheritance. The change is written to the directory immediately, through
+// the binding of the object and so with its credentials. Changes already made
+// to the attribute cache of the object are commited along with it.
+func (o *object) SetDACL(acl *ACL) error {
+	if acl == nil {
+		return errors.New("nil ACL")
+	}
+	sd, err := o.SecurityDescriptor()
+	if err != nil {
+		return err
+	}
+	sd.DACL = acl
+	return o.writeDACL("SetDACL", sd)
+}
+
+// writeDACL writes the DACL of sd to the object's nTSecurityDescriptor
+// attribute along with any other changes in its attribute cache, as
+// described by writeSecurityDescriptor.
+func (o *object) writeDACL(op string, sd *SecurityDescriptor) error {
+	variant, err := bytesToVariant(sd.Bytes())
+	if err != nil {
+		return err
+	}
+	defer variant.Clear()
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return ErrClosed
+	}
+	o.h.run(func() {
+		defer beginCall()()
+		err = o.writeSecurityDescriptor(op, variant, DACLSecurityInformation)
+	})
+	return err
+}
+
+// writeSecurityDescriptor writes the parts of the security descriptor held
+// by variant that are selected by info, by limiting the security mask of the
+// object to them for the duration of the write. The write is audited in the
+// same way as a Put of nTSecurityDescriptor followed by SetInfo, and errors
+// are returned as an *Error for the given operation. It must be called on
+// the object's worker while holding the object's lock, after beginCall.
+func (o *object) writeSecurityDescriptor(op string, variant *ole.VARIANT, info uint32) error {
+	idispatch, err := o.queryInterface(comiid.IADsObjectOptions)
+	if err != nil {
+		return o.err(op, err)
+	}
+	defer idispatch.Release()
+	options := (*api.IADsObjectOptions)(unsafe.Pointer(idispatch))
+	mask, err := options.IntOption(api.ADS_OPTION_SECURITY_MASK)
+	if err != nil {
+		return o.err(op, err)
+	}
+	if err = options.SetIntOption(api.ADS_OPTION_SECURITY_MASK, int32(info)); err != nil {
+		return o.err(op, err)
+	}
+
+	// Errors are wrapped before they are audited, as auditing makes ADSI
+	// calls that replace the extended error
+	start := time.Now()
+	err = o.err(op, o.iface.Put("nTSecurityDescriptor", variant))
+	o.auditPut("nTSecurityDescriptor", start, err)
+	if err == nil {
+		start = time.Now()
+		err = o.err(op, o.iface.SetInfo())
+		if o.h.auditing() {
+			o.audit(AuditSetInfo, start, o.pending, err)
+			if err == nil {
+				o.pending = nil
+			}
+		}
+	}
+
+	if restoreErr := options.SetIntOption(api.ADS_OPTION_SECURITY_MASK, mask); err == nil {
+		err = o.err(op, restoreErr)
+	}
+	return err
+}
@@ -0,0 +1,227 @@
+package adsi
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+var (
+	testAdministrators = SID{Revision: 1, Authority: 5, SubAuthorities: []uint32{32, 544}}
+	testEveryone       = SID{Revision: 1, Authority: 1, SubAuthorities: []uint32{0}}
+	testSystem         = SID{Revision: 1, Authority: 5, SubAuthorities: []uint32{18}}
+
+	// The schemaIDGUIDs of the user class and of the member attribute
+	testUserClass = uuid.MustParse("bf967aba-0de6-11d0-a285-00aa003049e2")
+	testMember    = uuid.MustParse("bf9679c0-0de6-11d0-a285-00aa003049e2")
+)
+
+func TestParseSecurityDescriptor(t *testing.T) {
+	// O:BAG:BAD:(A;;GA;;;WD), laid out with the owner first as Windows
+	// writes it
+	b := []byte{
+		1, 0, 0x04, 0x80, // revision, control
+		20, 0, 0, 0, // owner
+		36, 0, 0, 0, // group
+		0, 0, 0, 0, // SACL
+		52, 0, 0, 0, // DACL
+		1, 2, 0, 0, 0, 0, 0, 5, 32, 0, 0, 0, 0x20, 2, 0, 0,
+		1, 2, 0, 0, 0, 0, 0, 5, 32, 0, 0, 0, 0x20, 2, 0, 0,
+		2, 0, 28, 0, 1, 0, 0, 0, // ACL header
+		0, 0, 20, 0, 0, 0, 0, 0x10, // ACE header, GENERIC_ALL
+		1, 1, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0,
+	}
+	sd, err := ParseSecurityDescriptor(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &SecurityDescriptor{
+		Control: SecurityDescriptorSelfRelative | SecurityDescriptorDACLPresent,
+		Owner:   &testAdministrators,
+		Group:   &testAdministrators,
+		DACL: &ACL{Revision: 2, ACEs: []ACE{
+			{Type: ACETypeAccessAllowed, Mask: 0x10000000, SID: testEveryone},
+		}},
+	}
+	if !reflect.DeepEqual(sd, want) {
+		t.Errorf("ParseSecurityDescriptor() = %+v, want %+v", sd, want)
+	}
+}
+
+func TestSecurityDescriptorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		sd   *SecurityDescriptor
+	}{
+		{"empty", &SecurityDescriptor{Control: SecurityDescriptorSelfRelative}},
+		{"owner and group", &SecurityDescriptor{
+			Control: SecurityDescriptorSelfRelative,
+			Owner:   &testAdministrators,
+			Group:   &testSystem,
+		}},
+		{"empty DACL", &SecurityDescriptor{
+			Control: SecurityDescriptorSelfRelative | SecurityDescriptorDACLPresent,
+			DACL:    &ACL{Revision: 2},
+		}},
+		{"protected DACL and SACL", &SecurityDescriptor{
+			Control: SecurityDescriptorSelfRelative | SecurityDescriptorDACLPresent | SecurityDescriptorSACLPresent | SecurityDescriptorDACLProtected,
+			Owner:   &testAdministrators,
+			DACL: &ACL{Revision: 4, ACEs: []ACE{
+				{Type: ACETypeAccessDeniedObject, Mask: 0x20, ObjectType: testMember, SID: testEveryone},
+				{Type: ACETypeAccessAllowed, Flags: ACEFlagContainerInherit, Mask: 0x000F01FF, SID: testSystem},
+				{Type: ACETypeAccessAllowedObject, Flags: ACEFlagContainerInherit | ACEFlagInheritOnly, Mask: 0x10, InheritedObjectType: testUserClass, SID: testEveryone},
+				{Type: ACETypeAccessAllowedObject, Flags: ACEFlagInherited, Mask: 0x20, ObjectType: testMember, InheritedObjectType: testUserClass, SID: testAdministrators},
+				{Type: 0x09, Flags: ACEFlagInherited, Data: []byte{1, 2, 3, 4, 5, 6, 7, 8}},
+				{Type: ACETypeAccessAllowed, Mask: 0x10, SID: testEveryone, Data: []byte{0xaa, 0xbb, 0xcc, 0xdd}},
+			}},
+			SACL: &ACL{Revision: 2, ACEs: []ACE{
+				{Type: ACETypeSystemAudit, Flags: ACEFlagAuditSuccess | ACEFlagAuditFailure, Mask: 0x000D0000, SID: testEveryone},
+			}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := tt.sd.Bytes()
+			got, err := ParseSecurityDescriptor(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.sd) {
+				t.Errorf("ParseSecurityDescriptor(Bytes()) = %+v, want %+v", got, tt.sd)
+			}
+			if again := got.Bytes(); !bytes.Equal(again, b) {
+				t.Errorf("Bytes() is not stable: %x, want %x", again, b)
+			}
+		})
+	}
+}
+
+func TestSecurityDescriptorBytes(t *testing.T) {
+	sd := &SecurityDescriptor{
+		// The present flags follow the lists that are held
+		Control: SecurityDescriptorSACLPresent | SecurityDescriptorDACLProtected,
+		DACL: &ACL{ACEs: []ACE{
+			{Type: ACETypeAccessAllowedObject, Mask: 0x100, ObjectType: testUserClass, SID: testEveryone},
+		}},
+	}
+	b := sd.Bytes()
+	got, err := ParseSecurityDescriptor(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := SecurityDescriptorSelfRelative | SecurityDescriptorDACLPresent | SecurityDescriptorDACLProtected; got.Control != want {
+		t.Errorf("Control = %#04x, want %#04x", got.Control, want)
+	}
+	if got.DACL.Revision != 4 {
+		t.Errorf("DACL revision = %d, want 4 for object ACEs", got.DACL.Revision)
+	}
+	// Object types are stored in the Windows byte order
+	guid := []byte{0xba, 0x7a, 0x96, 0xbf, 0xe6, 0x0d, 0xd0, 0x11, 0xa2, 0x85, 0x00, 0xaa, 0x00, 0x30, 0x49, 0xe2}
+	if !bytes.Contains(b, guid) {
+		t.Errorf("Bytes() = %x does not hold the object type %x", b, guid)
+	}
+}
+
+func TestParseSecurityDescriptorInvalid(t *testing.T) {
+	valid := (&SecurityDescriptor{
+		Owner: &testAdministrators,
+		DACL:  &ACL{ACEs: []ACE{{Type: ACETypeAccessAllowedObject, Mask: 0x10, ObjectType: testMember, SID: testEveryone}}},
+	}).Bytes()
+	// Bytes writes the DACL first, then the owner
+	const dacl = 20
+	modify := func(fn func(b []byte)) []byte {
+		b := append([]byte(nil), valid...)
+		fn(b)
+		return b
+	}
+	tests := []struct {
+		name string
+		b    []byte
+	}{
+		{"empty", nil},
+		{"short header", valid[:19]},
+		{"revision", modify(func(b []byte) { b[0] = 2 })},
+		{"owner beyond descriptor", modify(func(b []byte) { b[4] = byte(len(b) - 4) })},
+		{"owner sub-authorities", modify(func(b []byte) { b[len(b)-15] = 5 })},
+		{"DACL beyond descriptor", modify(func(b []byte) { b[16] = byte(len(b) - 4) })},
+		{"DACL size", modify(func(b []byte) { b[dacl+2] = 0xff })},
+		{"DACL size below header", modify(func(b []byte) { b[dacl+2] = 4 })},
+		{"ACE count", modify(func(b []byte) { b[dacl+4] = 2 })},
+		{"ACE size", modify(func(b []byte) { b[dacl+8+2] = 3 })},
+		{"ACE size beyond ACL", modify(func(b []byte) { b[dacl+8+2] += 4 })},
+		{"object type flag without GUID", modify(func(b []byte) { b[dacl+8+8] = 3 })},
+		{"ACE SID", modify(func(b []byte) { b[dacl+8+12+16+1] = 9 })},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if sd, err := ParseSecurityDescriptor(tt.b); err != ErrInvalidSecurityDescriptor {
+				t.Errorf("ParseSecurityDescriptor() = %+v, %v, want ErrInvalidSecurityDescriptor", sd, err)
+			}
+		})
+	}
+}
+
+func TestACLAddExplicit(t *testing.T) {
+	allow := func(mask uint32) ACE { return ACE{Type: ACETypeAccessAllowed, Mask: mask, SID: testEveryone} }
+	deny := func(mask uint32) ACE { return ACE{Type: ACETypeAccessDenied, Mask: mask, SID: testEveryone} }
+	denyObject := func(mask uint32) ACE { return ACE{Type: ACETypeAccessDeniedObject, Mask: mask, SID: testEveryone} }
+	inherited := func(ace ACE) ACE { ace.Flags |= ACEFlagInherited; return ace }
+
+	tests := []struct {
+		name     string
+		existing []ACE
+		add      []ACE
+		want     []ACE
+	}{
+		{"empty", nil, []ACE{allow(1)}, []ACE{allow(1)}},
+		{"allow after allows", []ACE{deny(1), allow(2)}, []ACE{allow(3)}, []ACE{deny(1), allow(2), allow(3)}},
+		{"deny before allows", []ACE{deny(1), allow(2)}, []ACE{deny(3)}, []ACE{deny(1), deny(3), allow(2)}},
+		{"object deny before allows", []ACE{allow(2)}, []ACE{denyObject(3)}, []ACE{denyObject(3), allow(2)}},
+		{"before inherited", []ACE{allow(1), inherited(deny(2))}, []ACE{allow(3)}, []ACE{allow(1), allow(3), inherited(deny(2))}},
+		{"deny before inherited", []ACE{inherited(allow(1))}, []ACE{deny(2)}, []ACE{deny(2), inherited(allow(1))}},
+		{"several", []ACE{allow(1)}, []ACE{allow(2), deny(3), deny(4)}, []ACE{deny(3), deny(4), allow(1), allow(2)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acl := &ACL{ACEs: append([]ACE(nil), tt.existing...)}
+			acl.AddExplicit(tt.add...)
+			if !reflect.DeepEqual(acl.ACEs, tt.want) {
+				t.Errorf("ACEs = %+v, want %+v", acl.ACEs, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassSchemaDefaultDACL(t *testing.T) {
+	domain := SID{Revision: 1, Authority: 5, SubAuthorities: []uint32{21, 1, 2, 3}}
+	root := SID{Revision: 1, Authority: 5, SubAuthorities: []uint32{21, 4, 5, 6}}
+	class := &ClassSchema{
+		Name: "organizationalUnit",
+		DefaultSecurityDescriptor: "O:DAG:DAD:(A;;GA;;;DA)(A;CI;RPLCLORC;;;AU)" +
+			"(OA;;CCDC;bf967aba-0de6-11d0-a285-00aa003049e2;;AO)(A;;GA;;;EA)(A;;GA;;;SY)",
+	}
+	got, err := class.DefaultDACL(domain, root)
+	if err != nil {
+		t.Fatalf("DefaultDACL() failed: %v", err)
+	}
+	want := []ACE{
+		{Type: ACETypeAccessAllowed, Mask: 0x10000000, SID: domain.WithRID(512)},
+		{Type: ACETypeAccessAllowed, Flags: ACEFlagContainerInherit, Mask: 0x20094, SID: SID{Revision: 1, Authority: 5, SubAuthorities: []uint32{11}}},
+		{Type: ACETypeAccessAllowedObject, Mask: 0x3, ObjectType: testUserClass, SID: SID{Revision: 1, Authority: 5, SubAuthorities: []uint32{32, 548}}},
+		{Type: ACETypeAccessAllowed, Mask: 0x10000000, SID: root.WithRID(519)},
+		{Type: ACETypeAccessAllowed, Mask: 0x10000000, SID: testSystem},
+	}
+	if !reflect.DeepEqual(got.ACEs, want) {
+		t.Errorf("DefaultDACL() = %+v, want %+v", got.ACEs, want)
+	}
+
+	if _, err := class.DefaultDACL(SID{}, SID{}); err == nil {
+		t.Error("DefaultDACL() without domain SIDs succeeded, want error")
+	}
+	if _, err := (&ClassSchema{}).DefaultDACL(domain, root); !errors.Is(err, ErrNoDefaultSecurityDescriptor) {
+		t.Errorf("DefaultDACL() without descriptor = %v, want ErrNoDefaultSecurityDescriptor", err)
+	}
+}
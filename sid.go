@@ -0,0 +1,206 @@
+package adsi
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-adsi/adsi/adspath"
+	"github.com/go-adsi/adsi/api"
+)
+
+// ErrInvalidSID is returned when a security identifier cannot be parsed.
+var ErrInvalidSID = errors.New("invalid security identifier")
+
+// SID is a Windows security identifier, as held by the objectSid attribute of
+// users, groups and computers.
+type SID struct {
+	Revision       uint8
+	Authority      uint64
+	SubAuthorities []uint32
+}
+
+// ParseSID parses a security identifier from its binary form.
+func ParseSID(b []byte) (sid SID, err error) {
+	if len(b) < 8 || len(b) != 8+4*int(b[1]) {
+		return SID{}, ErrInvalidSID
+	}
+	sid.Revision = b[0]
+	for _, c := range b[2:8] {
+		sid.Authority = sid.Authority<<8 | uint64(c)
+	}
+	for i := 8; i < len(b); i += 4 {
+		sid.SubAuthorities = append(sid.SubAuthorities, binary.LittleEndian.Uint32(b[i:]))
+	}
+	return
+}
+
+// ParseSIDString parses a security identifier in its string form, such as
+// "S-1-5-21-1004336348-1177238915-682003330-512".
+func ParseSIDString(s string) (sid SID, err error) {
+	parts := strings.Split(s, "-")
+	if len(parts) < 3 || !strings.EqualFold(parts[0], "S") {
+		return SID{}, fmt.Errorf("%w: %q", ErrInvalidSID, s)
+	}
+	rev, err := strconv.ParseUint(parts[1], 10, 8)
+	if err != nil {
+		return SID{}, fmt.Errorf("%w: %q", ErrInvalidSID, s)
+	}
+	sid.Revision = uint8(rev)
+	if sid.Authority, err = strconv.ParseUint(parts[2], 0, 48); err != nil {
+		return SID{}, fmt.Errorf("%w: %q", ErrInvalidSID, s)
+	}
+	for _, part := range parts[3:] {
+		sub, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return SID{}, fmt.Errorf("%w: %q", ErrInvalidSID, s)
+		}
+		sid.SubAuthorities = append(sid.SubAuthorities, uint32(sub))
+	}
+	return
+}
+
+// Bytes returns the binary form of the security identifier.
+func (sid SID) Bytes() []byte {
+	b := make([]byte, 8+4*len(sid.SubAuthorities))
+	b[0] = sid.Revision
+	b[1] = byte(len(sid.SubAuthorities))
+	for i := 0; i < 6; i++ {
+		b[7-i] = byte(sid.Authority >> (8 * uint(i)))
+	}
+	for i, sub := range sid.SubAuthorities {
+		binary.LittleEndian.PutUint32(b[8+4*i:], sub)
+	}
+	return b
+}
+
+// String returns the string form of the security identifier.
+func (sid SID) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "S-%d-%d", sid.Revision, sid.Authority)
+	for _, sub := range sid.SubAuthorities {
+		fmt.Fprintf(&b, "-%d", sub)
+	}
+	return b.String()
+}
+
+// Equal returns true if sid and other are the same security identifier.
+func (sid SID) Equal(other SID) bool {
+	if sid.Revision != other.Revision || sid.Authority != other.Authority || len(sid.SubAuthorities) != len(other.SubAuthorities) {
+		return false
+	}
+	for i := range sid.SubAuthorities {
+		if sid.SubAuthorities[i] != other.SubAuthorities[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// RID returns the relative identifier of the security identifier, which is
+// its last sub-authority.
+func (sid SID) RID() uint32 {
+	if len(sid.SubAuthorities) == 0 {
+		return 0
+	}
+	return sid.SubAuthorities[len(sid.SubAuthorities)-1]
+}
+
+// Domain returns the security identifier of the domain that issued sid, which
+// is sid without its relative identifier.
+func (sid SID) Domain() SID {
+	if len(sid.SubAuthorities) == 0 {
+		return sid
+	}
+	return SID{
+		Revision:       sid.Revision,
+		Authority:      sid.Authority,
+		SubAuthorities: append([]uint32(nil), sid.SubAuthorities[:len(sid.SubAuthorities)-1]...),
+	}
+}
+
+// WithRID returns a security identifier formed by appending the given
+// relative identifier to sid.
+func (sid SID) WithRID(rid uint32) SID {
+	subs := make([]uint32, len(sid.SubAuthorities), len(sid.SubAuthorities)+1)
+	copy(subs, sid.SubAuthorities)
+	return SID{Revision: sid.Revision, Authority: sid.Authority, SubAuthorities: append(subs, rid)}
+}
+
+// bindingString returns the "<SID=...>" form of the security identifier that
+// can be used in place of a distinguished name when binding to an LDAP
+// object.
+func (sid SID) bindingString() string {
+	return "<SID=" + hex.EncodeToString(sid.Bytes()) + ">"
+}
+
+// SID retrieves the security identifier of the object from its objectSid
+// attribute.
+func (o *object) SID() (sid SID, err error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return SID{}, ErrClosed
+	}
+	return o.sid()
+}
+
+func (o *object) sid() (sid SID, err error) {
+	value, err := o.AttrBytes("objectSid")
+	if err != nil {
+		if errors.Is(err, api.ErrPropertyNotFound) {
+			err = fmt.Errorf("object has no security identifier: %w", err)
+		}
+		return
+	}
+	return ParseSID(value)
+}
+
+// DNFromSID returns the distinguished name of the object with the given
+// security identifier, such as one taken from a token or an access control
+// entry. The object is bound directly by SID, which is resolved by a domain
+// controller of the computer's domain without a search. If the domain holds
+// no such object, the global catalog is asked next, which finds objects in
+// the other domains of the forest.
+//
+// If no object has the SID an error matching ErrNoSuchObject is returned.
+// This is the case for well-known SIDs without a directory object, such as
+// Everyone.
+func (c *Client) DNFromSID(sid SID) (dn string, err error) {
+	dn, err = c.dnAt("LDAP://" + sid.bindingString())
+	if isNoSuchObject(err) {
+		dn, err = c.dnAt("GC://" + sid.bindingString())
+	}
+	return
+}
+
+// SIDFromDN returns the security identifier of the object with the given
+// distinguished name, from its objectSid attribute.
+func (c *Client) SIDFromDN(dn string) (sid SID, err error) {
+	obj, err := c.Open((&adspath.Path{Scheme: "LDAP", Path: adspath.EscapeDN(dn)}).String())
+	if err != nil {
+		return SID{}, err
+	}
+	defer obj.Close()
+	return obj.SID()
+}
+
+// dnAt opens the object with the given path, which may use a binding string
+// such as "<SID=...>" in place of a distinguished name, and returns its
+// distinguished name.
+func (c *Client) dnAt(path string) (dn string, err error) {
+	obj, err := c.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer obj.Close()
+	obj.m.Lock()
+	defer obj.m.Unlock()
+	if obj.closed() {
+		return "", ErrClosed
+	}
+	return obj.AttrString("distinguishedName")
+}
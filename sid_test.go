@@ -0,0 +1,138 @@
+package adsi
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSIDRoundTrip(t *testing.T) {
+	tests := []struct {
+		s string
+		b []byte
+	}{
+		{"S-1-0-0", []byte{1, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}},
+		{"S-1-5-18", []byte{1, 1, 0, 0, 0, 0, 0, 5, 18, 0, 0, 0}},
+		{"S-1-5-32-544", []byte{1, 2, 0, 0, 0, 0, 0, 5, 32, 0, 0, 0, 0x20, 2, 0, 0}},
+		{"S-1-5-21-1004336348-1177238915-682003330-512", []byte{
+			1, 5, 0, 0, 0, 0, 0, 5,
+			21, 0, 0, 0,
+			0xdc, 0xf4, 0xdc, 0x3b,
+			0x83, 0x3d, 0x2b, 0x46,
+			0x82, 0x8b, 0xa6, 0x28,
+			0x00, 0x02, 0x00, 0x00,
+		}},
+		{"S-1-16-12288", []byte{1, 1, 0, 0, 0, 0, 0, 16, 0, 0x30, 0, 0}},
+		{"S-1-281474976710655", []byte{1, 0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			fromString, err := ParseSIDString(tt.s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := fromString.Bytes(); !bytes.Equal(got, tt.b) {
+				t.Errorf("Bytes() = %x, want %x", got, tt.b)
+			}
+			fromBytes, err := ParseSID(tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := fromBytes.String(); got != tt.s {
+				t.Errorf("String() = %q, want %q", got, tt.s)
+			}
+			if !fromBytes.Equal(fromString) {
+				t.Errorf("%v is not equal to %v", fromBytes, fromString)
+			}
+		})
+	}
+}
+
+func TestParseSIDStringHexAuthority(t *testing.T) {
+	sid, err := ParseSIDString("s-1-0x0000000000005-18")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sid.String(), "S-1-5-18"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestParseSIDInvalid(t *testing.T) {
+	for _, b := range [][]byte{
+		nil,
+		{1, 0, 0, 0, 0, 0, 0},
+		{1, 1, 0, 0, 0, 0, 0, 5},
+		{1, 1, 0, 0, 0, 0, 0, 5, 18, 0, 0},
+		{1, 1, 0, 0, 0, 0, 0, 5, 18, 0, 0, 0, 0},
+	} {
+		if _, err := ParseSID(b); err != ErrInvalidSID {
+			t.Errorf("ParseSID(%x) returned %v, want ErrInvalidSID", b, err)
+		}
+	}
+	for _, s := range []string{
+		"",
+		"S-1",
+		"X-1-5-18",
+		"S-x-5-18",
+		"S-256-5-18",
+		"S-1-281474976710656",
+		"S-1-5-",
+		"S-1-5-18-4294967296",
+		"S-1-5--18",
+		"S-1-5-+18",
+	} {
+		if _, err := ParseSIDString(s); !errors.Is(err, ErrInvalidSID) {
+			t.Errorf("ParseSIDString(%q) returned %v, want ErrInvalidSID", s, err)
+		}
+	}
+}
+
+func TestSIDRelativeIdentifiers(t *testing.T) {
+	user, err := ParseSIDString("S-1-5-21-1004336348-1177238915-682003330-1105")
+	if err != nil {
+		t.Fatal(err)
+	}
+	domain := user.Domain()
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"Domain", domain.String(), "S-1-5-21-1004336348-1177238915-682003330"},
+		{"WithRID", domain.WithRID(512).String(), "S-1-5-21-1004336348-1177238915-682003330-512"},
+		{"Domain of authority", SID{Revision: 1, Authority: 5}.Domain().String(), "S-1-5"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+	if got := user.RID(); got != 1105 {
+		t.Errorf("RID() = %d, want 1105", got)
+	}
+	if got := (SID{Revision: 1, Authority: 5}).RID(); got != 0 {
+		t.Errorf("RID() without sub-authorities = %d, want 0", got)
+	}
+	if !domain.WithRID(1105).Equal(user) {
+		t.Error("WithRID(RID()) of the domain is not equal to the user")
+	}
+
+	// Neither Domain nor WithRID may share storage with sid
+	group := domain.WithRID(513)
+	domain.WithRID(512).SubAuthorities[0] = 99
+	user.Domain().SubAuthorities[0] = 99
+	if group.SubAuthorities[0] != 21 || user.SubAuthorities[0] != 21 {
+		t.Error("derived SIDs share sub-authorities with the original")
+	}
+}
+
+func TestSIDBindingString(t *testing.T) {
+	sid, err := ParseSIDString("S-1-5-32-544")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sid.bindingString(), "<SID=01020000000000052000000020020000>"; got != want {
+		t.Errorf("bindingString() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,169 @@
+package adsi
+
+import (
+	"net"
+	"strings"
+	"time"
+)
+
+// Site is an Active Directory site, as held in the CN=Sites container of the
+// configuration naming context.
+type Site struct {
+	Name        string
+	DN          string
+	Description string
+	Location    string
+
+	// Subnets holds the subnets associated with the site in CIDR notation.
+	Subnets []string
+
+	// Servers holds the DNS host names of the domain controllers in the site.
+	Servers []string
+}
+
+// Subnet is a subnet object that associates an address range with a site.
+type Subnet struct {
+	// Name is the address range in CIDR notation, such as "10.0.0.0/16".
+	Name        string
+	DN          string
+	Description string
+	Location    string
+
+	// Site is the name of the site the subnet belongs to, or empty if the
+	// subnet is not associated with a site.
+	Site string
+
+	// SiteDN is the distinguished name of the site the subnet belongs to.
+	SiteDN string
+}
+
+// IPNet parses the name of the subnet as an address range.
+func (s *Subnet) IPNet() (*net.IPNet, error) {
+	_, ipnet, err := net.ParseCIDR(s.Name)
+	return ipnet, err
+}
+
+// SiteLink connects two or more sites for the purpose of replication.
+type SiteLink struct {
+	Name string
+	DN   string
+
+	// Transport is the name of the inter-site transport, usually "IP" or
+	// "SMTP".
+	Transport string
+
+	// Sites holds the names of the sites connected by the link.
+	Sites []string
+
+	Cost int
+
+	// ReplInterval is the interval between replication cycles.
+	ReplInterval time.Duration
+
+	Options int
+}
+
+// Sites returns the sites of the forest along with their subnets and domain
+// controllers.
+func (f *Forest) Sites() (sites []*Site, err error) {
+	base := "CN=Sites," + f.root.ConfigurationNamingContext
+	rows, err := f.search(base, Query{
+		Filter:     "(objectClass=site)",
+		Attributes: []string{"name", "distinguishedName", "description", "location"},
+		Scope:      ScopeOneLevel,
+	})
+	if err != nil {
+		return
+	}
+	byDN := make(map[string]*Site)
+	for _, row := range rows {
+		site := &Site{
+			Name:        row.AttrString("name"),
+			DN:          row.AttrString("distinguishedName"),
+			Description: row.AttrString("description"),
+			Location:    row.AttrString("location"),
+		}
+		sites = append(sites, site)
+		byDN[strings.ToLower(site.DN)] = site
+	}
+
+	subnets, err := f.Subnets()
+	if err != nil {
+		return nil, err
+	}
+	for _, subnet := range subnets {
+		if site := byDN[strings.ToLower(subnet.SiteDN)]; site != nil {
+			site.Subnets = append(site.Subnets, subnet.Name)
+		}
+	}
+
+	rows, err = f.search(base, Query{
+		Filter:     "(objectClass=server)",
+		Attributes: []string{"distinguishedName", "dNSHostName"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		// CN=<server>,CN=Servers,CN=<site>,CN=Sites,...
+		siteDN := parentDN(parentDN(row.AttrString("distinguishedName")))
+		if site := byDN[strings.ToLower(siteDN)]; site != nil {
+			if name := row.AttrString("dNSHostName"); name != "" {
+				site.Servers = append(site.Servers, name)
+			}
+		}
+	}
+	return
+}
+
+// Subnets returns the subnets defined in the forest.
+func (f *Forest) Subnets() (subnets []*Subnet, err error) {
+	rows, err := f.search("CN=Subnets,CN=Sites,"+f.root.ConfigurationNamingContext, Query{
+		Filter:     "(objectClass=subnet)",
+		Attributes: []string{"name", "distinguishedName", "description", "location", "siteObject"},
+		Scope:      ScopeOneLevel,
+	})
+	if err != nil {
+		return
+	}
+	for _, row := range rows {
+		siteDN := row.AttrString("siteObject")
+		subnets = append(subnets, &Subnet{
+			Name:        row.AttrString("name"),
+			DN:          row.AttrString("distinguishedName"),
+			Description: row.AttrString("description"),
+			Location:    row.AttrString("location"),
+			Site:        rdnValue(siteDN),
+			SiteDN:      siteDN,
+		})
+	}
+	return
+}
+
+// SiteLinks returns the site links defined in the forest for all inter-site
+// transports.
+func (f *Forest) SiteLinks() (links []*SiteLink, err error) {
+	rows, err := f.search("CN=Inter-Site Transports,CN=Sites,"+f.root.ConfigurationNamingContext, Query{
+		Filter:     "(objectClass=siteLink)",
+		Attributes: []string{"name", "distinguishedName", "siteList", "cost", "replInterval", "options"},
+	})
+	if err != nil {
+		return
+	}
+	for _, row := range rows {
+		dn := row.AttrString("distinguishedName")
+		link := &SiteLink{
+			Name:         row.AttrString("name"),
+			DN:           dn,
+			Transport:    rdnValue(parentDN(dn)),
+			Cost:         row.AttrInt("cost"),
+			ReplInterval: time.Duration(row.AttrInt("replInterval")) * time.Minute,
+			Options:      row.AttrInt("options"),
+		}
+		for _, site := range row.AttrStringSlice("siteList") {
+			link.Sites = append(link.Sites, rdnValue(site))
+		}
+		links = append(links, link)
+	}
+	return
+}
@@ -0,0 +1,216 @@
+package adsi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-adsi/adsi/api"
+)
+
+// Snapshot holds a copy of the attributes of an object at a point in time.
+type Snapshot struct {
+	DN   string
+	Time time.Time
+
+	// Attributes maps each attribute name to its values, converted to native
+	// Go types in the same way as search results.
+	Attributes map[string][]interface{}
+}
+
+// Attr returns the values of the attribute with the given name. Names are
+// matched case-insensitively. If the snapshot does not contain the attribute
+// nil is returned.
+func (s *Snapshot) Attr(name string) []interface{} {
+	if values, ok := s.Attributes[name]; ok {
+		return values
+	}
+	for attr, values := range s.Attributes {
+		if strings.EqualFold(attr, name) {
+			return values
+		}
+	}
+	return nil
+}
+
+// Snapshot reads the given attributes of the object and returns a copy of
+// their values. If no attributes are given every attribute the caller is
+// permitted to read is included.
+func (o *object) Snapshot(attrs ...string) (snap Snapshot, err error) {
+	if len(attrs) > 0 {
+		attrs = append(attrs[:len(attrs):len(attrs)], "distinguishedName")
+	}
+	row, err := o.Row(attrs...)
+	if err != nil {
+		return
+	}
+	snap.Time = time.Now()
+	snap.Attributes = make(map[string][]interface{})
+	for _, column := range row.Columns() {
+		switch {
+		case strings.EqualFold(column.Name, "ADsPath"):
+		case strings.EqualFold(column.Name, "distinguishedName"):
+			snap.DN = row.AttrString(column.Name)
+			fallthrough
+		default:
+			snap.Attributes[column.Name] = column.Values
+		}
+	}
+	return snap, nil
+}
+
+// AttributeChange describes the difference in the values of a single
+// attribute between two snapshots. Old is nil for added attributes and New
+// is nil for removed attributes.
+type AttributeChange struct {
+	Name string
+	Old  []interface{}
+	New  []interface{}
+}
+
+// SnapshotDiff holds the differences between two snapshots. Each list is
+// sorted by attribute name.
+type SnapshotDiff struct {
+	Added   []AttributeChange
+	Removed []AttributeChange
+	Changed []AttributeChange
+}
+
+// Empty returns true if the snapshots were identical.
+func (d *SnapshotDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Diff compares two snapshots of an object and returns the attributes that
+// were added, removed or changed between a and b. Attribute names are
+// matched case-insensitively and the order of values is ignored.
+func Diff(a, b Snapshot) (d SnapshotDiff) {
+	old := make(map[string]string, len(a.Attributes))
+	for name := range a.Attributes {
+		old[strings.ToLower(name)] = name
+	}
+	for name, values := range b.Attributes {
+		key := strings.ToLower(name)
+		oldName, ok := old[key]
+		if !ok {
+			d.Added = append(d.Added, AttributeChange{Name: name, New: values})
+			continue
+		}
+		delete(old, key)
+		if !sameValues(a.Attributes[oldName], values) {
+			d.Changed = append(d.Changed, AttributeChange{Name: name, Old: a.Attributes[oldName], New: values})
+		}
+	}
+	for _, name := range old {
+		d.Removed = append(d.Removed, AttributeChange{Name: name, Old: a.Attributes[name]})
+	}
+	for _, changes := range [][]AttributeChange{d.Added, d.Removed, d.Changed} {
+		sort.Slice(changes, func(i, j int) bool { return strings.ToLower(changes[i].Name) < strings.ToLower(changes[j].Name) })
+	}
+	return
+}
+
+// sameValues returns true if a and b hold the same values in any order.
+func sameValues(a, b []interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := make(map[string]int, len(a))
+	for _, value := range a {
+		counts[valueKey(value)]++
+	}
+	for _, value := range b {
+		key := valueKey(value)
+		if counts[key] == 0 {
+			return false
+		}
+		counts[key]--
+	}
+	return true
+}
+
+// valueKey returns a string that identifies a value and its type.
+func valueKey(value interface{}) string {
+	if t, ok := value.(time.Time); ok {
+		return fmt.Sprintf("time.Time:%d", t.UnixNano())
+	}
+	return fmt.Sprintf("%T:%v", value, value)
+}
+
+// Restore writes the values held by the snapshot back to the object, so
+// that the given attributes match the snapshot again. Attributes that were
+// absent from the snapshot are cleared. If no attributes are given every
+// attribute that differs from the snapshot and that the caller is permitted
+// to write, as reported by allowedAttributesEffective, is restored.
+//
+// The changes are committed with a single SetInfo. Attributes that are
+// maintained by the system, such as uSNChanged, can't be restored and are
+// skipped unless they are named explicitly.
+func (o *object) Restore(snap Snapshot, attrs ...string) error {
+	current, err := o.Snapshot(attrs...)
+	if err != nil {
+		return err
+	}
+	if len(attrs) == 0 {
+		row, err := o.baseRow(Query{Attributes: []string{"allowedAttributesEffective"}})
+		if err != nil {
+			return err
+		}
+		writable := make(map[string]bool)
+		for _, name := range row.AttrStringSlice("allowedAttributesEffective") {
+			writable[strings.ToLower(name)] = true
+		}
+		seen := make(map[string]bool)
+		for _, s := range []Snapshot{snap, current} {
+			for name := range s.Attributes {
+				key := strings.ToLower(name)
+				if writable[key] && !seen[key] {
+					seen[key] = true
+					attrs = append(attrs, name)
+				}
+			}
+		}
+	}
+
+	changed := false
+	for _, name := range attrs {
+		values := snap.Attr(name)
+		if sameValues(values, current.Attr(name)) {
+			continue
+		}
+		if len(values) == 0 {
+			err = o.PutEx(api.ADS_PROPERTY_CLEAR, name)
+		} else {
+			err = o.PutEx(api.ADS_PROPERTY_UPDATE, name, restoreValues(values)...)
+		}
+		if err != nil {
+			return err
+		}
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return o.SetInfo()
+}
+
+// restoreValues converts values read from a search to types accepted by
+// PutEx.
+func restoreValues(values []interface{}) []interface{} {
+	converted := make([]interface{}, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case time.Time:
+			converted[i] = FormatGeneralizedTime(v)
+		case api.DNWithBinary:
+			converted[i] = fmt.Sprintf("B:%d:%X:%s", len(v.Binary)*2, v.Binary, v.DN)
+		case api.DNWithString:
+			converted[i] = fmt.Sprintf("S:%d:%s:%s", utf8.RuneCountInString(v.String), v.String, v.DN)
+		default:
+			converted[i] = value
+		}
+	}
+	return converted
+}
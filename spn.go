@@ -0,0 +1,126 @@
+package adsi
+
+import (
+	"errors"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/go-adsi/adsi/api"
+)
+
+// SPNs returns the service principal names registered to the object.
+func (o *object) SPNs() (spns []string, err error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return nil, ErrClosed
+	}
+	spns, err = o.AttrStringSlice("servicePrincipalName")
+	if errors.Is(err, api.ErrPropertyNotFound) {
+		return nil, nil
+	}
+	return
+}
+
+// AddSPN registers the given service principal names to the object. The value
+// must be commited with SetInfo to be made persistent.
+//
+// Active Directory rejects the update when an SPN is already registered to
+// the object. Use DuplicateSPNs or SPNOwners to find conflicting
+// registrations on other objects.
+func (o *object) AddSPN(spns ...string) error {
+	return o.PutEx(api.ADS_PROPERTY_APPEND, "servicePrincipalName", stringsToValues(spns)...)
+}
+
+// RemoveSPN removes the given service principal names from the object. The
+// value must be commited with SetInfo to be made persistent.
+func (o *object) RemoveSPN(spns ...string) error {
+	return o.PutEx(api.ADS_PROPERTY_DELETE, "servicePrincipalName", stringsToValues(spns)...)
+}
+
+// SPNOwners searches beneath the given path for objects that have registered
+// spn and returns their distinguished names. To search the entire forest
+// supply a global catalog path, such as "GC://DC=example,DC=com".
+func (c *Client) SPNOwners(path, spn string) (owners []string, err error) {
+	result, err := c.Search(path, Query{
+		Filter:     "(servicePrincipalName=" + EscapeFilter(spn) + ")",
+		Attributes: []string{"distinguishedName"},
+	})
+	if err != nil {
+		return
+	}
+	defer result.Close()
+	for {
+		row, err := result.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		owners = append(owners, row.AttrString("distinguishedName"))
+	}
+	return
+}
+
+// DuplicateSPNs searches beneath the given path for service principal names
+// that are registered to more than one object. It returns a map of each
+// duplicated SPN to the distinguished names of the objects that hold it. To
+// search the entire forest supply a global catalog path, such as
+// "GC://DC=example,DC=com".
+//
+// SPNs are compared case-insensitively, as they are by the KDC. The keys of
+// the returned map use the spelling of the first registration found.
+func (c *Client) DuplicateSPNs(path string) (duplicates map[string][]string, err error) {
+	result, err := c.Search(path, Query{
+		Filter:     "(servicePrincipalName=*)",
+		Attributes: []string{"distinguishedName", "servicePrincipalName"},
+	})
+	if err != nil {
+		return
+	}
+	defer result.Close()
+
+	type registration struct {
+		spn    string
+		owners []string
+	}
+	seen := make(map[string]*registration)
+	for {
+		row, err := result.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		dn := row.AttrString("distinguishedName")
+		for _, spn := range row.AttrStringSlice("servicePrincipalName") {
+			key := strings.ToLower(spn)
+			reg := seen[key]
+			if reg == nil {
+				reg = &registration{spn: spn}
+				seen[key] = reg
+			}
+			reg.owners = append(reg.owners, dn)
+		}
+	}
+
+	duplicates = make(map[string][]string)
+	for _, reg := range seen {
+		if len(reg.owners) > 1 {
+			sort.Strings(reg.owners)
+			duplicates[reg.spn] = reg.owners
+		}
+	}
+	return
+}
+
+func stringsToValues(s []string) []interface{} {
+	values := make([]interface{}, len(s))
+	for i := range s {
+		values[i] = s[i]
+	}
+	return values
+}
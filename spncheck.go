@@ -0,0 +1,134 @@
+package adsi
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/go-adsi/adsi/api"
+)
+
+// hostServiceClasses are the service classes registered for every computer
+// when it joins the domain, whose host names follow those of the computer.
+var hostServiceClasses = []string{"HOST", "RestrictedKrbHost"}
+
+// SPNReport compares the host service principal names registered to a
+// computer with its names. After a computer is renamed, its HOST and
+// RestrictedKrbHost SPNs can be left naming its previous name, which breaks
+// Kerberos authentication to it under the new one.
+type SPNReport struct {
+	// DNSHostName is the dNSHostName of the computer, and NetBIOSName its
+	// sAMAccountName without the trailing "$".
+	DNSHostName string
+	NetBIOSName string
+
+	// Expected holds the HOST and RestrictedKrbHost SPNs for both names.
+	Expected []string
+
+	// Missing holds the expected SPNs that are not registered.
+	Missing []string
+
+	// Stale holds the registered HOST and RestrictedKrbHost SPNs that name
+	// neither the computer nor one of its msDS-AdditionalDnsHostName or
+	// msDS-AdditionalSamAccountName aliases.
+	Stale []string
+}
+
+// Consistent returns true if no SPNs are missing or stale.
+func (r *SPNReport) Consistent() bool {
+	return len(r.Missing) == 0 && len(r.Stale) == 0
+}
+
+// CheckSPNs compares the host SPNs registered to the computer with its
+// dNSHostName and sAMAccountName. Other service classes are not checked, as
+// the names they use are chosen by the services that register them.
+func (c *Computer) CheckSPNs() (report *SPNReport, err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return nil, ErrClosed
+	}
+	attrs := make(map[string][]string)
+	for _, name := range []string{"dNSHostName", "sAMAccountName", "servicePrincipalName", "msDS-AdditionalDnsHostName", "msDS-AdditionalSamAccountName"} {
+		values, err := c.AttrStringSlice(name)
+		if err != nil && !errors.Is(err, api.ErrPropertyNotFound) {
+			return nil, err
+		}
+		attrs[name] = values
+	}
+	first := func(name string) string {
+		if values := attrs[name]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	report = &SPNReport{
+		DNSHostName: first("dNSHostName"),
+		NetBIOSName: strings.TrimSuffix(first("sAMAccountName"), "$"),
+	}
+	hosts := make(map[string]bool)
+	for _, host := range append([]string{report.DNSHostName, report.NetBIOSName}, attrs["msDS-AdditionalDnsHostName"]...) {
+		hosts[strings.ToLower(host)] = true
+	}
+	for _, sam := range attrs["msDS-AdditionalSamAccountName"] {
+		hosts[strings.ToLower(strings.TrimSuffix(sam, "$"))] = true
+	}
+
+	registered := make(map[string]bool)
+	for _, spn := range attrs["servicePrincipalName"] {
+		registered[strings.ToLower(spn)] = true
+		class, host, ok := splitSPN(spn)
+		if ok && containsFold(hostServiceClasses, class) && !hosts[strings.ToLower(host)] {
+			report.Stale = append(report.Stale, spn)
+		}
+	}
+	for _, class := range hostServiceClasses {
+		for _, host := range []string{report.DNSHostName, report.NetBIOSName} {
+			if host == "" {
+				continue
+			}
+			spn := class + "/" + host
+			report.Expected = append(report.Expected, spn)
+			if !registered[strings.ToLower(spn)] {
+				report.Missing = append(report.Missing, spn)
+			}
+		}
+	}
+	return report, nil
+}
+
+// RepairSPNs checks the host SPNs of the computer with CheckSPNs, then
+// registers the missing SPNs and removes the stale ones. It returns the
+// report of the check, which describes the changes made. The changes must be
+// commited with SetInfo to be made persistent.
+//
+// Registering SPNs that are held by another object fails when the changes
+// are commited. Use SPNOwners to find such conflicts beforehand.
+func (c *Computer) RepairSPNs() (report *SPNReport, err error) {
+	if report, err = c.CheckSPNs(); err != nil {
+		return nil, err
+	}
+	if len(report.Stale) > 0 {
+		if err = c.RemoveSPN(report.Stale...); err != nil {
+			return nil, err
+		}
+	}
+	if len(report.Missing) > 0 {
+		if err = c.AddSPN(report.Missing...); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// splitSPN returns the service class and host name of an SPN of the form
+// "class/host[:port][/service]".
+func splitSPN(spn string) (class, host string, ok bool) {
+	class, rest, ok := strings.Cut(spn, "/")
+	if !ok {
+		return "", "", false
+	}
+	host, _, _ = strings.Cut(rest, "/")
+	host, _, _ = strings.Cut(host, ":")
+	return class, host, true
+}
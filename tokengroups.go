@@ -0,0 +1,60 @@
+package adsi
+
+// TokenGroups returns the security identifiers of every group that the user
+// is a member of, directly or transitively, including its primary group. The
+// list is computed by the domain controller from the constructed tokenGroups
+// attribute and matches the group SIDs that would appear in the user's
+// security token.
+//
+// The tokenGroups attribute is constructed, so it is retrieved from the
+// server with Pull before it is read.
+func (u *User) TokenGroups() (sids []SID, err error) {
+	u.m.Lock()
+	defer u.m.Unlock()
+	if u.closed() {
+		return nil, ErrClosed
+	}
+	if err = u.object.Pull("tokenGroups"); err != nil {
+		return
+	}
+	values, err := u.object.AttrBytesSlice("tokenGroups")
+	if err != nil {
+		return
+	}
+	for _, value := range values {
+		sid, err := ParseSID(value)
+		if err != nil {
+			return nil, err
+		}
+		sids = append(sids, sid)
+	}
+	return
+}
+
+// TokenGroupObjects returns the groups identified by TokenGroups. Each group
+// is bound by SID on the same server as the user.
+//
+// The returned groups consume resources until they are closed. It is the
+// caller's responsibility to call Close on each of the returned groups when
+// they are no longer needed.
+func (u *User) TokenGroupObjects() (groups []*Group, err error) {
+	sids, err := u.TokenGroups()
+	if err != nil {
+		return
+	}
+	for _, sid := range sids {
+		path, err := u.pathForDN(sid.bindingString())
+		if err == nil {
+			var group *Group
+			if group, err = u.openGroup(path); err == nil {
+				groups = append(groups, group)
+				continue
+			}
+		}
+		for _, opened := range groups {
+			opened.Close()
+		}
+		return nil, err
+	}
+	return
+}
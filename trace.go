@@ -0,0 +1,88 @@
+package adsi
+
+import (
+	"time"
+
+	"github.com/go-adsi/adsi/adspath"
+)
+
+// Span names reported to a Tracer. Write operations are named "adsi." followed
+// by the AuditOp, such as "adsi.SetInfo".
+const (
+	SpanBind   = "adsi.bind"
+	SpanSearch = "adsi.search"
+)
+
+// TraceSpan describes a directory operation that has completed.
+type TraceSpan struct {
+	// Name identifies the operation. See SpanBind and SpanSearch.
+	Name string
+
+	Start time.Time
+	End   time.Time
+
+	// Server is the host named by Path, if any.
+	Server string
+
+	// Path is the ADsPath of the object that was opened, searched or
+	// changed.
+	Path string
+
+	// Filter is the LDAP filter of a search.
+	Filter string
+
+	// Rows is the number of rows returned by a search.
+	Rows int
+
+	// Attributes lists the attributes changed by a write operation.
+	Attributes []string
+
+	// Err is the error returned by the operation, if any, and HRESULT is its
+	// COM error code, or zero if it has none.
+	Err     error
+	HRESULT uint32
+}
+
+// Tracer receives a span for each directory operation, so that operations
+// can be included in distributed traces. Span is called synchronously once
+// the operation has completed and must return promptly.
+//
+// The adsiotel module provides a Tracer that records spans with
+// OpenTelemetry.
+type Tracer interface {
+	Span(span TraceSpan)
+}
+
+// SetTracer sets the tracer that receives spans for the binds, searches and
+// write operations made through the client and the objects, containers and
+// searchers opened by it. It applies only to objects opened after it is
+// called. A nil tracer disables tracing, which is the default.
+func (c *Client) SetTracer(t Tracer) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	h := c.h.clone()
+	h.tracer = t
+	c.h = h
+}
+
+// tracing returns true if a tracer is set.
+func (h *hooks) tracing() bool {
+	return h != nil && h.tracer != nil
+}
+
+// trace completes a span and passes it to the tracer.
+func (h *hooks) trace(span TraceSpan) {
+	if !h.tracing() {
+		return
+	}
+	if span.End.IsZero() {
+		span.End = time.Now()
+	}
+	if p, err := adspath.Parse(span.Path); err == nil {
+		span.Server = p.Host
+	}
+	if code, ok := hresult(span.Err); ok {
+		span.HRESULT = uint32(code)
+	}
+	h.tracer.Span(span)
+}
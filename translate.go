@@ -24,12 +24,19 @@ See also https://docs.microsoft.com/en-us/windows/win32/api/iads/nn-iads-iadsnam
 package adsi
 
 import (
+	"runtime"
 	"sync"
 
 	"github.com/go-adsi/adsi/api"
+	ole "github.com/go-ole/go-ole"
 	"github.com/scjalliance/comshim"
+	"github.com/scjalliance/comutil"
 )
 
+// translateBatchSize is the number of names translated in each round trip by
+// TranslateAll.
+const translateBatchSize = 500
+
 // NameTranslator provides active directory name translation services via the
 // IADsNameTranslate interface.
 type NameTranslator struct {
@@ -44,25 +51,31 @@ func (nt *NameTranslator) closed() bool {
 // NewNameTranslator creates a new NameTranslator bound to server.
 // For the local host, leave server blank ("").
 func NewNameTranslator(server string) (*NameTranslator, error) {
+	if runtime.GOOS != "windows" {
+		return &NameTranslator{}, ErrUnsupported
+	}
 	comshim.Add(1)
 
 	tr, err := api.NewIADsNameTranslate(server)
 	if err != nil {
 		return &NameTranslator{}, err
 	}
-	return &NameTranslator{iface: tr}, nil
+	return track(&NameTranslator{iface: tr}), nil
 }
 
 // Close will release resources consumed by the translator. It should be
 // called when the translator is no longer needed.
-func (nt *NameTranslator) Close() {
+func (nt *NameTranslator) Close() error {
 	nt.m.Lock()
 	defer nt.m.Unlock()
 	if nt.closed() {
-		return
+		return nil
 	}
+	untrack(nt)
 	nt.iface.Release()
+	nt.iface = nil
 	comshim.Done()
+	return nil
 }
 
 // Init initializes the translator to use either Domain, Server, or GC for translation.
@@ -100,3 +113,111 @@ func (nt *NameTranslator) Set(adsPath string, setType uint32) error {
 	}
 	return nt.iface.Set(adsPath, setType)
 }
+
+// SetEx sets the input objects to be translated, in the format matching
+// setType (an ADS_NAME_TYPE_ENUM). The names are resolved by the directory
+// server in a single request. If any of the names cannot be resolved an
+// error is returned. SetEx must be called after Init and before GetEx.
+func (nt *NameTranslator) SetEx(names []string, setType uint32) error {
+	nt.m.Lock()
+	defer nt.m.Unlock()
+	if nt.closed() {
+		return ErrClosed
+	}
+	v, err := comutil.BuildVarArrayStr(names...)
+	if err != nil {
+		return err
+	}
+	defer v.Clear()
+	return nt.iface.SetEx(setType, v)
+}
+
+// GetEx attempts to get the results of the translation of the names given to
+// SetEx in the specified format, in the order the names were given. See
+// ADS_NAME_TYPE_ENUM for the available options. GetEx must be called last.
+func (nt *NameTranslator) GetEx(formatType uint32) (names []string, err error) {
+	nt.m.Lock()
+	defer nt.m.Unlock()
+	if nt.closed() {
+		return nil, ErrClosed
+	}
+	v, err := nt.iface.GetEx(formatType)
+	if err != nil {
+		return nil, err
+	}
+	defer v.Clear()
+	array := v.ToArray()
+	if array == nil {
+		return nil, ErrNonArrayAttribute
+	}
+	err = eachVariant(array, func(elements []ole.VARIANT) error {
+		names = variantStrings(nil, elements)
+		return nil
+	})
+	return names, err
+}
+
+// Translation is the outcome of the translation of a single name by
+// TranslateAll.
+type Translation struct {
+	// Name is the name that was translated.
+	Name string
+
+	// Result is the translated name, or empty if the name could not be
+	// translated.
+	Result string
+
+	// Err is the error that prevented the name from being translated.
+	Err error
+}
+
+// TranslateAll translates many names from the format matching setType to
+// the given format, making one request to the directory server for each
+// batch of names rather than one for each name. The results are returned in
+// the order of the names. Init must be called first.
+//
+// SetEx fails the whole batch when one of its names cannot be resolved, as
+// happens for the foreign security principals of deleted accounts. When a
+// batch fails its names are translated one at a time, so that the error of
+// each name is reported in its Translation and the other names of the batch
+// are still translated.
+func (nt *NameTranslator) TranslateAll(names []string, setType, formatType uint32) (results []Translation, err error) {
+	results = make([]Translation, 0, len(names))
+	for start := 0; start < len(names); start += translateBatchSize {
+		batch := names[start:min(start+translateBatchSize, len(names))]
+		translated, err := nt.translateBatch(batch, setType, formatType)
+		if err == ErrClosed {
+			return nil, err
+		}
+		if err == nil && len(translated) == len(batch) {
+			for i, name := range batch {
+				results = append(results, Translation{Name: name, Result: translated[i]})
+			}
+			continue
+		}
+		for _, name := range batch {
+			result, err := nt.translate(name, setType, formatType)
+			if err == ErrClosed {
+				return nil, err
+			}
+			results = append(results, Translation{Name: name, Result: result, Err: err})
+		}
+	}
+	return results, nil
+}
+
+// translateBatch translates the given names with SetEx and GetEx.
+func (nt *NameTranslator) translateBatch(names []string, setType, formatType uint32) ([]string, error) {
+	if err := nt.SetEx(names, setType); err != nil {
+		return nil, err
+	}
+	return nt.GetEx(formatType)
+}
+
+// translate translates a single name with Set and Get.
+func (nt *NameTranslator) translate(name string, setType, formatType uint32) (string, error) {
+	if err := nt.Set(name, setType); err != nil {
+		return "", err
+	}
+	return nt.Get(formatType)
+}
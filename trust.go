@@ -0,0 +1,206 @@
+package adsi
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TrustDirection is the value of the trustDirection attribute of a
+// trustedDomain object.
+type TrustDirection int
+
+// Trust directions.
+const (
+	TrustDirectionDisabled      TrustDirection = 0
+	TrustDirectionInbound       TrustDirection = 1
+	TrustDirectionOutbound      TrustDirection = 2
+	TrustDirectionBidirectional TrustDirection = 3
+)
+
+// String returns the name of the trust direction.
+func (d TrustDirection) String() string {
+	switch d {
+	case TrustDirectionDisabled:
+		return "disabled"
+	case TrustDirectionInbound:
+		return "inbound"
+	case TrustDirectionOutbound:
+		return "outbound"
+	case TrustDirectionBidirectional:
+		return "bidirectional"
+	}
+	return fmt.Sprintf("unknown trust direction %d", int(d))
+}
+
+// TrustType is the value of the trustType attribute of a trustedDomain
+// object.
+type TrustType int
+
+// Trust types.
+const (
+	TrustTypeDownlevel TrustType = 1
+	TrustTypeUplevel   TrustType = 2
+	TrustTypeMIT       TrustType = 3
+	TrustTypeDCE       TrustType = 4
+	TrustTypeAAD       TrustType = 5
+)
+
+// String returns the name of the trust type.
+func (t TrustType) String() string {
+	switch t {
+	case TrustTypeDownlevel:
+		return "downlevel"
+	case TrustTypeUplevel:
+		return "uplevel"
+	case TrustTypeMIT:
+		return "MIT"
+	case TrustTypeDCE:
+		return "DCE"
+	case TrustTypeAAD:
+		return "AAD"
+	}
+	return fmt.Sprintf("unknown trust type %d", int(t))
+}
+
+// TrustAttributes is the value of the trustAttributes attribute of a
+// trustedDomain object.
+//
+// See https://learn.microsoft.com/openspecs/windows_protocols/ms-adts/e9a2d23c-c31e-4a6f-88a0-6646fdb51a3c
+type TrustAttributes uint32
+
+// Trust attribute flags.
+const (
+	TrustAttributeNonTransitive                        TrustAttributes = 0x00000001
+	TrustAttributeUplevelOnly                          TrustAttributes = 0x00000002
+	TrustAttributeQuarantinedDomain                    TrustAttributes = 0x00000004
+	TrustAttributeForestTransitive                     TrustAttributes = 0x00000008
+	TrustAttributeCrossOrganization                    TrustAttributes = 0x00000010
+	TrustAttributeWithinForest                         TrustAttributes = 0x00000020
+	TrustAttributeTreatAsExternal                      TrustAttributes = 0x00000040
+	TrustAttributeUsesRC4Encryption                    TrustAttributes = 0x00000080
+	TrustAttributeUsesAESKeys                          TrustAttributes = 0x00000100
+	TrustAttributeCrossOrganizationNoTGTDelegation     TrustAttributes = 0x00000200
+	TrustAttributePIMTrust                             TrustAttributes = 0x00000400
+	TrustAttributeCrossOrganizationEnableTGTDelegation TrustAttributes = 0x00000800
+)
+
+var trustAttributeNames = []struct {
+	flag TrustAttributes
+	name string
+}{
+	{TrustAttributeNonTransitive, "NON_TRANSITIVE"},
+	{TrustAttributeUplevelOnly, "UPLEVEL_ONLY"},
+	{TrustAttributeQuarantinedDomain, "QUARANTINED_DOMAIN"},
+	{TrustAttributeForestTransitive, "FOREST_TRANSITIVE"},
+	{TrustAttributeCrossOrganization, "CROSS_ORGANIZATION"},
+	{TrustAttributeWithinForest, "WITHIN_FOREST"},
+	{TrustAttributeTreatAsExternal, "TREAT_AS_EXTERNAL"},
+	{TrustAttributeUsesRC4Encryption, "USES_RC4_ENCRYPTION"},
+	{TrustAttributeUsesAESKeys, "USES_AES_KEYS"},
+	{TrustAttributeCrossOrganizationNoTGTDelegation, "CROSS_ORGANIZATION_NO_TGT_DELEGATION"},
+	{TrustAttributePIMTrust, "PIM_TRUST"},
+	{TrustAttributeCrossOrganizationEnableTGTDelegation, "CROSS_ORGANIZATION_ENABLE_TGT_DELEGATION"},
+}
+
+// Has returns true if all of the given flags are set.
+func (a TrustAttributes) Has(flags TrustAttributes) bool {
+	return a&flags == flags
+}
+
+// String returns the names of the flags that are set, separated by '|'.
+func (a TrustAttributes) String() string {
+	var names []string
+	remaining := a
+	for _, entry := range trustAttributeNames {
+		if a&entry.flag != 0 {
+			names = append(names, entry.name)
+			remaining &^= entry.flag
+		}
+	}
+	if remaining != 0 {
+		names = append(names, fmt.Sprintf("0x%08X", uint32(remaining)))
+	}
+	if len(names) == 0 {
+		return "0"
+	}
+	return strings.Join(names, "|")
+}
+
+// Trust describes a trust relationship between the domain and another
+// domain or realm, as recorded by a trustedDomain object.
+type Trust struct {
+	DN string
+
+	// Partner is the DNS name of the trusted domain, or its NetBIOS name
+	// for downlevel trusts.
+	Partner string
+
+	// FlatName is the NetBIOS name of the trusted domain.
+	FlatName string
+
+	Direction  TrustDirection
+	Type       TrustType
+	Attributes TrustAttributes
+
+	// SID is the security identifier of the trusted domain. It is empty for
+	// trusts with realms that do not use SIDs.
+	SID SID
+
+	WhenCreated time.Time
+	WhenChanged time.Time
+}
+
+// IsTransitive returns true if the trust is transitive.
+func (t *Trust) IsTransitive() bool {
+	return !t.Attributes.Has(TrustAttributeNonTransitive)
+}
+
+// SIDFilteringEnabled returns true if SID filtering is applied to incoming
+// authentication across the trust.
+func (t *Trust) SIDFilteringEnabled() bool {
+	return t.Attributes.Has(TrustAttributeQuarantinedDomain)
+}
+
+// trustAttrs are the attributes read by Trusts.
+var trustAttrs = []string{
+	"distinguishedName", "trustPartner", "flatName", "trustDirection",
+	"trustType", "trustAttributes", "securityIdentifier", "whenCreated",
+	"whenChanged",
+}
+
+// Trusts enumerates the trust relationships of the domain from the
+// trustedDomain objects held in its CN=System container.
+func (d *Domain) Trusts() (trusts []*Trust, err error) {
+	dn, err := d.DN()
+	if err != nil {
+		return
+	}
+	rows, err := d.searchDNAll("CN=System,"+dn, Query{
+		Filter:     "(objectClass=trustedDomain)",
+		Attributes: trustAttrs,
+		Scope:      ScopeOneLevel,
+	})
+	if err != nil {
+		return
+	}
+	for _, row := range rows {
+		trust := &Trust{
+			DN:          row.AttrString("distinguishedName"),
+			Partner:     row.AttrString("trustPartner"),
+			FlatName:    row.AttrString("flatName"),
+			Direction:   TrustDirection(row.AttrInt("trustDirection")),
+			Type:        TrustType(row.AttrInt("trustType")),
+			Attributes:  TrustAttributes(uint32(row.AttrInt64("trustAttributes"))),
+			WhenCreated: row.AttrTime("whenCreated"),
+			WhenChanged: row.AttrTime("whenChanged"),
+		}
+		if b := row.AttrBytes("securityIdentifier"); len(b) > 0 {
+			if trust.SID, err = ParseSID(b); err != nil {
+				return nil, err
+			}
+		}
+		trusts = append(trusts, trust)
+	}
+	return
+}
@@ -1,6 +1,9 @@
 package adsi
 
 import (
+	"errors"
+	"time"
+
 	"github.com/go-adsi/adsi/api"
 	"github.com/scjalliance/comshim"
 )
@@ -14,7 +17,7 @@ type User struct {
 // NewUser returns a user that manages the given COM interface.
 func NewUser(iface *api.IADsUser) *User {
 	comshim.Add(1)
-	return &User{iface: iface, object: object{iface: &iface.IADs}}
+	return track(&User{iface: iface, object: object{iface: &iface.IADs}})
 }
 
 func (u *User) closed() bool {
@@ -23,16 +26,22 @@ func (u *User) closed() bool {
 
 // Close will release resources consumed by the user. It should be
 // called when the user is no longer needed.
-func (u *User) Close() {
+func (u *User) Close() error {
 	u.m.Lock()
 	defer u.m.Unlock()
 	if u.closed() {
-		return
+		return nil
 	}
+	untrack(u)
 	defer comshim.Done()
-	u.iface.Release()
+	defer u.h.released()
+	u.h.run(func() {
+		u.releaseInterfaces()
+		u.iface.Release()
+	})
 	u.object.iface = nil
 	u.iface = nil
+	return nil
 }
 
 // AccountDisabled retrieves the disablement status of a user account.
@@ -42,7 +51,12 @@ func (u *User) AccountDisabled() (disabled bool, err error) {
 	if u.closed() {
 		return false, ErrClosed
 	}
-	return u.iface.AccountDisabled()
+	u.h.run(func() {
+		defer beginCall()()
+		disabled, err = u.iface.AccountDisabled()
+		err = u.err("AccountDisabled", err)
+	})
+	return
 }
 
 // SetAccountDisabled sets an account as disabled.
@@ -52,15 +66,163 @@ func (u *User) SetAccountDisabled(val bool) (err error) {
 	if u.closed() {
 		return ErrClosed
 	}
-	return u.iface.SetAccountDisabled(val)
+	u.h.run(func() {
+		defer beginCall()()
+		start := time.Now()
+		err = u.iface.SetAccountDisabled(val)
+		err = u.err("SetAccountDisabled", err)
+		u.auditPut("userAccountControl", start, err)
+	})
+	return err
 }
 
 // FullName returns the user's FullName property.
-func (u *User) FullName() (string, error) {
+func (u *User) FullName() (name string, err error) {
 	u.m.Lock()
 	defer u.m.Unlock()
 	if u.closed() {
 		return "", ErrClosed
 	}
-	return u.iface.FullName()
+	u.h.run(func() {
+		defer beginCall()()
+		name, err = u.iface.FullName()
+		err = u.err("FullName", err)
+	})
+	return
+}
+
+// PasswordLastSet returns the time at which the user's password was last
+// changed. The zero time is returned if the password has never been set or
+// the user must change the password at next logon.
+func (u *User) PasswordLastSet() (time.Time, error) {
+	return u.attrTime("pwdLastSet")
+}
+
+// LastLogon returns the time of the user's last logon as recorded by the
+// domain controller the user is bound to. The lastLogon attribute is not
+// replicated, so other domain controllers may hold a more recent value.
+func (u *User) LastLogon() (time.Time, error) {
+	return u.attrTime("lastLogon")
+}
+
+// LastLogonTimestamp returns the replicated lastLogonTimestamp of the user.
+// It may lag the true last logon time by up to 14 days.
+func (u *User) LastLogonTimestamp() (time.Time, error) {
+	return u.attrTime("lastLogonTimestamp")
+}
+
+// BadPasswordTime returns the time of the last failed logon attempt recorded
+// by the domain controller the user is bound to.
+func (u *User) BadPasswordTime() (time.Time, error) {
+	return u.attrTime("badPasswordTime")
+}
+
+// LockoutTime returns the time at which the user's account was locked out.
+// The zero time is returned if the account is not locked out.
+func (u *User) LockoutTime() (time.Time, error) {
+	return u.attrTime("lockoutTime")
+}
+
+// attrTime retrieves a FILETIME-style large integer attribute of the user.
+// Attributes that have not been set are returned as the zero time.
+func (u *User) attrTime(name string) (t time.Time, err error) {
+	u.m.Lock()
+	defer u.m.Unlock()
+	if u.closed() {
+		return time.Time{}, ErrClosed
+	}
+	t, err = u.object.AttrTime(name)
+	if errors.Is(err, api.ErrPropertyNotFound) {
+		return time.Time{}, nil
+	}
+	return
+}
+
+// AccountExpires returns the time at which the user's account expires. If
+// the account never expires then expires is false and the returned time is
+// the zero time.
+//
+// Active Directory stores both 0 and FileTimeNever in accountExpires to
+// indicate an account that never expires. Naive conversion of the latter
+// produces a date in the year 30828, so both are reported as never.
+func (u *User) AccountExpires() (t time.Time, expires bool, err error) {
+	t, err = u.attrTime("accountExpires")
+	if err != nil || t.IsZero() {
+		return time.Time{}, false, err
+	}
+	return t, true, nil
+}
+
+// SetAccountExpires sets the time at which the user's account expires. If t
+// is the zero time the account is set to never expire. The value must be
+// commited with SetInfo to be made persistent.
+//
+// Note that Active Directory users and computers displays accountExpires as
+// the day before the stored time, because the account expires at the start of
+// the stored day.
+func (u *User) SetAccountExpires(t time.Time) error {
+	u.m.Lock()
+	defer u.m.Unlock()
+	if u.closed() {
+		return ErrClosed
+	}
+	value := FileTimeNever
+	if !t.IsZero() {
+		value = FileTimeFromTime(t)
+	}
+	var err error
+	u.h.run(func() {
+		defer beginCall()()
+		start := time.Now()
+		err = putInt64(&u.iface.IADs, "accountExpires", value)
+		err = u.err("Put accountExpires", err)
+		u.auditPut("accountExpires", start, err)
+	})
+	return err
+}
+
+// SetAccountNeverExpires sets the user's account to never expire. The value
+// must be commited with SetInfo to be made persistent.
+func (u *User) SetAccountNeverExpires() error {
+	return u.SetAccountExpires(time.Time{})
+}
+
+// SetPassword resets the password of the user. The change takes effect
+// immediately. The caller must have the right to reset the password of the
+// account, and the connection must be encrypted.
+func (u *User) SetPassword(password string) error {
+	u.m.Lock()
+	defer u.m.Unlock()
+	if u.closed() {
+		return ErrClosed
+	}
+	var err error
+	u.h.run(func() {
+		defer beginCall()()
+		start := time.Now()
+		err = u.iface.SetPassword(password)
+		err = u.err("SetPassword", err)
+		u.audit(AuditSetPassword, start, nil, err)
+	})
+	return err
+}
+
+// ChangePassword changes the password of the user from oldPassword to
+// newPassword. The change takes effect immediately and is subject to the
+// password policy of the user.
+func (u *User) ChangePassword(oldPassword, newPassword string) error {
+	u.m.Lock()
+	defer u.m.Unlock()
+	if u.closed() {
+		return ErrClosed
+	}
+	var err error
+	u.h.run(func() {
+		defer beginCall()()
+		start := time.Now()
+		err = u.iface.ChangePassword(oldPassword, newPassword)
+		err = u.err("ChangePassword", err)
+		u.audit(AuditSetPassword, start, nil, err)
+	})
+	return err
 }
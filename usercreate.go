@@ -0,0 +1,138 @@
+package adsi
+
+import "errors"
+
+// UserOptions holds the settings of a user account created by CreateUser.
+type UserOptions struct {
+	// Name is the common name of the user, which forms its relative
+	// distinguished name. It is required.
+	Name string
+
+	// SAMAccountName is the pre-Windows 2000 logon name of the user. It is
+	// required.
+	SAMAccountName string
+
+	UserPrincipalName string
+	GivenName         string
+	Surname           string
+	DisplayName       string
+	Description       string
+	Mail              string
+
+	// Password is the initial password of the user. If it is empty the
+	// account is created without a password and left disabled.
+	Password string
+
+	// MustChangePassword requires the user to change the initial password
+	// at next logon. It can only be used with a password.
+	MustChangePassword bool
+
+	// Disabled leaves the account disabled even if a password is given.
+	Disabled bool
+}
+
+// CreateUser creates a user account within the container and returns it.
+//
+// The account is created in several steps, in the order required by Active
+// Directory: the object is created with its names, which makes a disabled
+// account; the initial password is set; and finally the account is enabled
+// and, if requested, flagged to change its password at next logon. If any
+// step after the first fails the partially created account is deleted.
+//
+// The returned user consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned user when it is no
+// longer needed.
+func (c *Container) CreateUser(opts UserOptions) (user *User, err error) {
+	if err = opts.validate(); err != nil {
+		return nil, err
+	}
+	rdn := "CN=" + escapeRDNValue(opts.Name)
+
+	obj, err := c.Create("user", rdn)
+	if err != nil {
+		return
+	}
+	defer obj.Close()
+	for _, attr := range []struct{ name, value string }{
+		{"sAMAccountName", opts.SAMAccountName},
+		{"userPrincipalName", opts.UserPrincipalName},
+		{"givenName", opts.GivenName},
+		{"sn", opts.Surname},
+		{"displayName", opts.DisplayName},
+		{"description", opts.Description},
+		{"mail", opts.Mail},
+	} {
+		if attr.value == "" {
+			continue
+		}
+		if err = obj.PutString(attr.name, attr.value); err != nil {
+			return
+		}
+	}
+	if err = obj.SetInfo(); err != nil {
+		return
+	}
+
+	if user, err = obj.ToUser(); err != nil {
+		c.Delete("user", rdn)
+		return nil, err
+	}
+	if err = user.initialize(opts); err != nil {
+		user.Close()
+		c.Delete("user", rdn)
+		return nil, err
+	}
+	return user, nil
+}
+
+// validate checks that the options describe an account that can be created.
+func (opts *UserOptions) validate() error {
+	if opts.Name == "" {
+		return errors.New("user name is required")
+	}
+	if opts.SAMAccountName == "" {
+		return errors.New("user sAMAccountName is required")
+	}
+	if opts.MustChangePassword && opts.Password == "" {
+		return errors.New("user must change password requires a password")
+	}
+	return nil
+}
+
+// initialize sets the initial password of a newly created user and enables
+// the account.
+func (u *User) initialize(opts UserOptions) error {
+	if opts.Password == "" {
+		return nil
+	}
+	if err := u.SetPassword(opts.Password); err != nil {
+		return err
+	}
+	if !opts.Disabled {
+		// Setting the password changes userAccountControl on the server, so
+		// the cached value must be refreshed before it is modified
+		if err := u.pull("userAccountControl"); err != nil {
+			return err
+		}
+		if err := u.updateAccountControl(0, AccountControlAccountDisable|AccountControlPasswordNotRequired); err != nil {
+			return err
+		}
+	}
+	if opts.MustChangePassword {
+		if err := u.PutInt64("pwdLastSet", 0); err != nil {
+			return err
+		}
+	}
+	return u.SetInfo()
+}
+
+// pull retrieves the given attributes from the server, replacing any cached
+// values.
+func (u *User) pull(attrs ...string) error {
+	u.m.Lock()
+	defer u.m.Unlock()
+	if u.closed() {
+		return ErrClosed
+	}
+	return u.object.Pull(attrs...)
+}
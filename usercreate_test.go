@@ -0,0 +1,24 @@
+package adsi
+
+import "testing"
+
+func TestUserOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    UserOptions
+		wantErr bool
+	}{
+		{"valid", UserOptions{Name: "Alice", SAMAccountName: "alice"}, false},
+		{"password", UserOptions{Name: "Alice", SAMAccountName: "alice", Password: "x", MustChangePassword: true}, false},
+		{"no name", UserOptions{SAMAccountName: "alice"}, true},
+		{"no sAMAccountName", UserOptions{Name: "Alice"}, true},
+		{"must change without password", UserOptions{Name: "Alice", SAMAccountName: "alice", MustChangePassword: true}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,378 @@
+package adsi
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/go-adsi/adsi/api"
+)
+
+// The userParameters attribute holds the Terminal Services settings of a
+// user, such as the Remote Desktop profile path, in a binary structure that
+// is stored as the UTF-16 code units of a Unicode string. The structure is
+// described in section 2.3.1 of [MS-TSTS].
+//
+// [MS-TSTS]: https://learn.microsoft.com/openspecs/windows_protocols/ms-tsts/3e3a3fae-4e7b-4a6f-8b00-3bd3a01c60a0
+
+// Names of the Terminal Services properties held in userParameters. String
+// properties are held both as ANSI strings and, in the properties whose
+// names end in "W", as Unicode strings.
+const (
+	TSPropertyCfgPresent           = "CtxCfgPresent"
+	TSPropertyCfgFlags1            = "CtxCfgFlags1"
+	TSPropertyCallback             = "CtxCallback"
+	TSPropertyShadow               = "CtxShadow"
+	TSPropertyMaxConnectionTime    = "CtxMaxConnectionTime"
+	TSPropertyMaxDisconnectionTime = "CtxMaxDisconnectionTime"
+	TSPropertyMaxIdleTime          = "CtxMaxIdleTime"
+	TSPropertyKeyboardLayout       = "CtxKeyboardLayout"
+	TSPropertyMinEncryptionLevel   = "CtxMinEncryptionLevel"
+	TSPropertyProfilePath          = "CtxWFProfilePath"
+	TSPropertyHomeDirectory        = "CtxWFHomeDir"
+	TSPropertyHomeDrive            = "CtxWFHomeDirDrive"
+	TSPropertyInitialProgram       = "CtxInitialProgram"
+	TSPropertyWorkDirectory        = "CtxWorkDirectory"
+	TSPropertyCallbackNumber       = "CtxCallbackNumber"
+	TSPropertyNWLogonServer        = "CtxNWLogonServer"
+)
+
+const (
+	// tsCfgPresent is the value of CtxCfgPresent in every userParameters
+	// structure that holds Terminal Services settings.
+	tsCfgPresent = 0xB00B1E55
+
+	// tsLogonDisabled is the bit of CtxCfgFlags1 that denies the user
+	// permission to log on to a terminal server.
+	tsLogonDisabled = 0x00008000
+
+	// tsSignature follows the reserved data at the start of the structure.
+	tsSignature = 'P'
+
+	// tsReservedSize is the size in bytes of the reserved data.
+	tsReservedSize = 96
+)
+
+// ErrInvalidUserParameters is returned when the userParameters attribute does
+// not hold a valid Terminal Services structure.
+var ErrInvalidUserParameters = errors.New("invalid userParameters")
+
+// UserParameters holds the Terminal Services settings stored in the
+// userParameters attribute of a user. The zero value holds no settings and
+// is ready to use.
+//
+// The settings are held as named properties, which can be read and written
+// with the typed methods. Properties that are not described by this package
+// and the data that follows the properties are kept as they were read, so
+// that they are written back unchanged.
+type UserParameters struct {
+	reserved []byte
+	props    []tsProperty
+	trailer  []byte
+
+	// other holds a value that does not hold Terminal Services settings,
+	// which is written back unless properties are set
+	other []byte
+}
+
+// tsProperty is a single property of a userParameters structure, with its
+// value decoded from the hexadecimal form in which it is stored.
+type tsProperty struct {
+	name  string
+	typ   uint16
+	value []byte
+}
+
+// ParseUserParameters parses the value of the userParameters attribute. An
+// empty value, or one that holds data other than Terminal Services settings,
+// such as the settings of Remote Access written by older versions of
+// Windows, is returned as a UserParameters holding no properties; in the
+// latter case the data is kept and written back by String.
+func ParseUserParameters(s string) (*UserParameters, error) {
+	b := utf16LEBytes(s)
+	if len(b) < tsReservedSize+4 || binary.LittleEndian.Uint16(b[tsReservedSize:]) != tsSignature {
+		p := &UserParameters{other: b}
+		if len(b) >= tsReservedSize {
+			p.reserved = b[:tsReservedSize]
+		}
+		return p, nil
+	}
+	p := &UserParameters{reserved: b[:tsReservedSize]}
+	count := int(binary.LittleEndian.Uint16(b[tsReservedSize+2:]))
+	b = b[tsReservedSize+4:]
+	for i := 0; i < count; i++ {
+		if len(b) < 6 {
+			return nil, fmt.Errorf("%w: property %d is truncated", ErrInvalidUserParameters, i)
+		}
+		nameLength := int(binary.LittleEndian.Uint16(b))
+		valueLength := int(binary.LittleEndian.Uint16(b[2:]))
+		typ := binary.LittleEndian.Uint16(b[4:])
+		b = b[6:]
+		if len(b) < nameLength+valueLength || nameLength%2 != 0 {
+			return nil, fmt.Errorf("%w: property %d is truncated", ErrInvalidUserParameters, i)
+		}
+		name := decodeUTF16(b[:nameLength])
+		value, err := hex.DecodeString(string(b[nameLength : nameLength+valueLength]))
+		if err != nil {
+			return nil, fmt.Errorf("%w: property %s: %v", ErrInvalidUserParameters, name, err)
+		}
+		p.props = append(p.props, tsProperty{name: name, typ: typ, value: value})
+		b = b[nameLength+valueLength:]
+	}
+	p.trailer = b
+	return p, nil
+}
+
+// String returns the value of the userParameters attribute that holds the
+// settings.
+func (p *UserParameters) String() string {
+	if len(p.props) == 0 && len(p.trailer) == 0 {
+		if p.other != nil {
+			return encodeUTF16(p.other)
+		}
+		return encodeUTF16(p.reserved)
+	}
+	b := make([]byte, 0, tsReservedSize+4)
+	b = append(b, p.reservedData()...)
+	b = binary.LittleEndian.AppendUint16(b, tsSignature)
+	b = binary.LittleEndian.AppendUint16(b, uint16(len(p.props)))
+	for _, prop := range p.props {
+		name := utf16LEBytes(prop.name)
+		b = binary.LittleEndian.AppendUint16(b, uint16(len(name)))
+		b = binary.LittleEndian.AppendUint16(b, uint16(len(prop.value)*2))
+		b = binary.LittleEndian.AppendUint16(b, prop.typ)
+		b = append(b, name...)
+		b = hex.AppendEncode(b, prop.value)
+	}
+	b = append(b, p.trailer...)
+	return encodeUTF16(b)
+}
+
+// reservedData returns the reserved data that starts the structure, which
+// Windows fills with spaces.
+func (p *UserParameters) reservedData() []byte {
+	if len(p.reserved) == tsReservedSize {
+		return p.reserved
+	}
+	reserved := make([]byte, tsReservedSize)
+	for i := 0; i < tsReservedSize; i += 2 {
+		reserved[i] = ' '
+	}
+	return reserved
+}
+
+// Names returns the names of the properties, in the order in which they are
+// stored.
+func (p *UserParameters) Names() []string {
+	names := make([]string, len(p.props))
+	for i, prop := range p.props {
+		names[i] = prop.name
+	}
+	return names
+}
+
+// Value returns the raw value of the property with the given name, and
+// whether it is set.
+func (p *UserParameters) Value(name string) ([]byte, bool) {
+	if prop := p.lookup(name); prop != nil {
+		return prop.value, true
+	}
+	return nil, false
+}
+
+// SetValue sets the raw value of the property with the given name.
+func (p *UserParameters) SetValue(name string, value []byte) {
+	if prop := p.lookup(name); prop != nil {
+		prop.value = value
+		return
+	}
+	if name != TSPropertyCfgPresent && p.lookup(TSPropertyCfgPresent) == nil {
+		// CtxCfgPresent marks the structure as holding Terminal Services
+		// settings and is always the first property
+		p.props = append(p.props, tsProperty{name: TSPropertyCfgPresent, typ: 1, value: binary.LittleEndian.AppendUint32(nil, tsCfgPresent)})
+	}
+	p.props = append(p.props, tsProperty{name: name, typ: 1, value: value})
+}
+
+// Delete removes the property with the given name.
+func (p *UserParameters) Delete(name string) {
+	for i := range p.props {
+		if strings.EqualFold(p.props[i].name, name) {
+			p.props = append(p.props[:i], p.props[i+1:]...)
+			return
+		}
+	}
+}
+
+// Uint32 returns the value of the 32-bit integer property with the given
+// name, and whether it is set.
+func (p *UserParameters) Uint32(name string) (uint32, bool) {
+	value, ok := p.Value(name)
+	if !ok || len(value) < 4 {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint32(value), true
+}
+
+// SetUint32 sets the value of the 32-bit integer property with the given
+// name.
+func (p *UserParameters) SetUint32(name string, value uint32) {
+	p.SetValue(name, binary.LittleEndian.AppendUint32(nil, value))
+}
+
+// Text returns the value of the string property with the given name, and
+// whether it is set. The Unicode form of the property is preferred to the
+// ANSI form when both are present.
+func (p *UserParameters) Text(name string) (string, bool) {
+	if value, ok := p.Value(name + "W"); ok {
+		return strings.TrimRight(decodeUTF16(value), "\x00"), true
+	}
+	if value, ok := p.Value(name); ok {
+		return strings.TrimRight(string(value), "\x00"), true
+	}
+	return "", false
+}
+
+// SetText sets the value of the string property with the given name, in both
+// its ANSI and its Unicode forms. Characters that cannot be represented in
+// ASCII are replaced by question marks in the ANSI form. If s is empty both
+// forms are removed.
+func (p *UserParameters) SetText(name, s string) {
+	if s == "" {
+		p.Delete(name)
+		p.Delete(name + "W")
+		return
+	}
+	ansi := make([]byte, 0, len(s)+1)
+	for _, r := range s {
+		if r >= 0x80 {
+			r = '?'
+		}
+		ansi = append(ansi, byte(r))
+	}
+	p.SetValue(name, append(ansi, 0))
+	p.SetValue(name+"W", append(utf16LEBytes(s), 0, 0))
+}
+
+// ProfilePath returns the path of the user's Remote Desktop Services
+// profile.
+func (p *UserParameters) ProfilePath() string {
+	s, _ := p.Text(TSPropertyProfilePath)
+	return s
+}
+
+// SetProfilePath sets the path of the user's Remote Desktop Services
+// profile. An empty path removes it.
+func (p *UserParameters) SetProfilePath(path string) {
+	p.SetText(TSPropertyProfilePath, path)
+}
+
+// HomeDirectory returns the path of the user's Remote Desktop Services home
+// directory, and the drive letter to which it is mapped if it is a network
+// path.
+func (p *UserParameters) HomeDirectory() (path, drive string) {
+	path, _ = p.Text(TSPropertyHomeDirectory)
+	drive, _ = p.Text(TSPropertyHomeDrive)
+	return
+}
+
+// SetHomeDirectory sets the path of the user's Remote Desktop Services home
+// directory and the drive letter, such as "H:", to which it is mapped. The
+// drive is empty for local paths. An empty path removes both.
+func (p *UserParameters) SetHomeDirectory(path, drive string) {
+	if path == "" {
+		drive = ""
+	}
+	p.SetText(TSPropertyHomeDirectory, path)
+	p.SetText(TSPropertyHomeDrive, drive)
+}
+
+// AllowLogon returns true unless the user is denied permission to log on to
+// terminal servers. Users without settings are permitted to log on.
+func (p *UserParameters) AllowLogon() bool {
+	flags, _ := p.Uint32(TSPropertyCfgFlags1)
+	return flags&tsLogonDisabled == 0
+}
+
+// SetAllowLogon grants or denies the user permission to log on to terminal
+// servers.
+func (p *UserParameters) SetAllowLogon(allow bool) {
+	flags, _ := p.Uint32(TSPropertyCfgFlags1)
+	if allow {
+		flags &^= tsLogonDisabled
+	} else {
+		flags |= tsLogonDisabled
+	}
+	p.SetUint32(TSPropertyCfgFlags1, flags)
+}
+
+func (p *UserParameters) lookup(name string) *tsProperty {
+	for i := range p.props {
+		if strings.EqualFold(p.props[i].name, name) {
+			return &p.props[i]
+		}
+	}
+	return nil
+}
+
+// UserParameters retrieves and parses the userParameters attribute of the
+// user. A user without the attribute is returned a UserParameters holding no
+// settings.
+func (u *User) UserParameters() (p *UserParameters, err error) {
+	u.m.Lock()
+	defer u.m.Unlock()
+	if u.closed() {
+		return nil, ErrClosed
+	}
+	s, err := u.object.AttrString("userParameters")
+	if err != nil && !errors.Is(err, api.ErrPropertyNotFound) {
+		return nil, err
+	}
+	return ParseUserParameters(s)
+}
+
+// SetUserParameters replaces the userParameters attribute of the user with
+// the given settings. If they are empty the attribute is cleared. The value
+// must be commited with SetInfo to be made persistent.
+func (u *User) SetUserParameters(p *UserParameters) error {
+	s := p.String()
+	if s == "" {
+		return u.PutEx(api.ADS_PROPERTY_CLEAR, "userParameters")
+	}
+	return u.PutString("userParameters", s)
+}
+
+// encodeUTF16 returns the string whose UTF-16 code units hold the given
+// bytes in little-endian order. A final odd byte is padded with zero.
+func encodeUTF16(b []byte) string {
+	units := make([]uint16, (len(b)+1)/2)
+	for i := range units {
+		units[i] = uint16(b[2*i])
+		if 2*i+1 < len(b) {
+			units[i] |= uint16(b[2*i+1]) << 8
+		}
+	}
+	return string(utf16.Decode(units))
+}
+
+// decodeUTF16 decodes a little-endian UTF-16 string.
+func decodeUTF16(b []byte) string {
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(units))
+}
+
+// utf16LEBytes returns the UTF-16 encoding of s in little-endian order.
+func utf16LEBytes(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	b := make([]byte, 0, 2*len(units))
+	for _, u := range units {
+		b = binary.LittleEndian.AppendUint16(b, u)
+	}
+	return b
+}
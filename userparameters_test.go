@@ -0,0 +1,166 @@
+package adsi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// tsStructure builds the value of userParameters holding the given
+// properties, as Windows writes it, with each property given as its name
+// followed by its value.
+func tsStructure(props ...interface{}) string {
+	b := []byte(strings.Repeat(" \x00", tsReservedSize/2))
+	b = binary.LittleEndian.AppendUint16(b, tsSignature)
+	b = binary.LittleEndian.AppendUint16(b, uint16(len(props)/2))
+	for i := 0; i < len(props); i += 2 {
+		name := utf16LEBytes(props[i].(string))
+		value := []byte(hex.EncodeToString(props[i+1].([]byte)))
+		b = binary.LittleEndian.AppendUint16(b, uint16(len(name)))
+		b = binary.LittleEndian.AppendUint16(b, uint16(len(value)))
+		b = binary.LittleEndian.AppendUint16(b, 1)
+		b = append(append(b, name...), value...)
+	}
+	return encodeUTF16(b)
+}
+
+func le32(n uint32) []byte { return binary.LittleEndian.AppendUint32(nil, n) }
+
+func TestParseUserParameters(t *testing.T) {
+	s := tsStructure(
+		TSPropertyCfgPresent, le32(tsCfgPresent),
+		TSPropertyCfgFlags1, le32(0x02018010),
+		TSPropertyProfilePath, []byte(`\\fs1\profiles\jdoe`+"\x00"),
+		TSPropertyProfilePath+"W", append(utf16LEBytes(`\\fs1\profiles\jdöe`), 0, 0),
+		TSPropertyHomeDirectory, []byte(`\\fs1\home\jdoe`+"\x00"),
+		TSPropertyHomeDrive, []byte("H:\x00"),
+	)
+	p, err := ParseUserParameters(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{TSPropertyCfgPresent, TSPropertyCfgFlags1, TSPropertyProfilePath, TSPropertyProfilePath + "W", TSPropertyHomeDirectory, TSPropertyHomeDrive}
+	if got := p.Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %q, want %q", got, want)
+	}
+	if got, want := p.ProfilePath(), `\\fs1\profiles\jdöe`; got != want {
+		t.Errorf("ProfilePath() = %q, want %q", got, want)
+	}
+	if path, drive := p.HomeDirectory(); path != `\\fs1\home\jdoe` || drive != "H:" {
+		t.Errorf("HomeDirectory() = %q, %q", path, drive)
+	}
+	if p.AllowLogon() {
+		t.Error("AllowLogon() = true with the logon disabled flag set")
+	}
+	if n, ok := p.Uint32("ctxcfgpresent"); !ok || n != tsCfgPresent {
+		t.Errorf("Uint32(ctxcfgpresent) = %#x, %v", n, ok)
+	}
+	if got := p.String(); got != s {
+		t.Errorf("String() does not reproduce the parsed value")
+	}
+}
+
+func TestParseUserParametersOther(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"empty", ""},
+		{"short", "m:                    d"},
+		{"remote access", strings.Repeat(" ", tsReservedSize/2-1) + "1" + "trailing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ParseUserParameters(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if names := p.Names(); len(names) != 0 {
+				t.Errorf("Names() = %q, want none", names)
+			}
+			if !p.AllowLogon() {
+				t.Error("AllowLogon() = false without settings")
+			}
+			if got := p.String(); got != tt.in {
+				t.Errorf("String() = %q, want %q", got, tt.in)
+			}
+		})
+	}
+}
+
+func TestParseUserParametersInvalid(t *testing.T) {
+	valid := tsStructure(TSPropertyCfgPresent, le32(tsCfgPresent))
+	b := utf16LEBytes(valid)
+	tests := []struct {
+		name string
+		in   []byte
+	}{
+		{"missing property", append(append([]byte(nil), b[:tsReservedSize+2]...), 2, 0)},
+		{"truncated header", b[:len(b)-len(TSPropertyCfgPresent)*2-8-4]},
+		{"truncated value", b[:len(b)-2]},
+		{"odd name length", func() []byte {
+			c := append([]byte(nil), b...)
+			c[tsReservedSize+4]++
+			return c
+		}()},
+		{"invalid hex", func() []byte {
+			c := append([]byte(nil), b...)
+			c[len(c)-2] = 'x'
+			return c
+		}()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if p, err := ParseUserParameters(encodeUTF16(tt.in)); !errors.Is(err, ErrInvalidUserParameters) {
+				t.Errorf("ParseUserParameters() = %+v, %v, want ErrInvalidUserParameters", p, err)
+			}
+		})
+	}
+}
+
+func TestUserParametersSet(t *testing.T) {
+	var p UserParameters
+	if got := p.String(); got != "" {
+		t.Errorf("String() of the zero value = %q, want \"\"", got)
+	}
+
+	p.SetProfilePath(`\\fs1\profiles\zoë`)
+	p.SetHomeDirectory(`\\fs1\home\zoë`, "H:")
+	p.SetAllowLogon(false)
+	want := []string{TSPropertyCfgPresent, TSPropertyProfilePath, TSPropertyProfilePath + "W", TSPropertyHomeDirectory, TSPropertyHomeDirectory + "W", TSPropertyHomeDrive, TSPropertyHomeDrive + "W", TSPropertyCfgFlags1}
+	if got := p.Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %q, want %q", got, want)
+	}
+	if ansi, _ := p.Value(TSPropertyProfilePath); !bytes.Equal(ansi, []byte(`\\fs1\profiles\zo?`+"\x00")) {
+		t.Errorf("ANSI profile path = %q", ansi)
+	}
+
+	parsed, err := ParseUserParameters(p.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := parsed.ProfilePath(); got != `\\fs1\profiles\zoë` {
+		t.Errorf("ProfilePath() = %q", got)
+	}
+	if path, drive := parsed.HomeDirectory(); path != `\\fs1\home\zoë` || drive != "H:" {
+		t.Errorf("HomeDirectory() = %q, %q", path, drive)
+	}
+	if parsed.AllowLogon() {
+		t.Error("AllowLogon() = true after SetAllowLogon(false)")
+	}
+	parsed.SetAllowLogon(true)
+	if !parsed.AllowLogon() {
+		t.Error("AllowLogon() = false after SetAllowLogon(true)")
+	}
+
+	parsed.SetHomeDirectory("", "H:")
+	parsed.SetProfilePath("")
+	want = []string{TSPropertyCfgPresent, TSPropertyCfgFlags1}
+	if got := parsed.Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() after clearing = %q, want %q", got, want)
+	}
+}
@@ -1,13 +1,17 @@
 package adsi
 
 import (
+	"encoding/binary"
 	"errors"
+	"fmt"
+	"math"
 	"unsafe"
 
 	ole "github.com/go-ole/go-ole"
 	"github.com/scjalliance/comutil"
 	"github.com/go-adsi/adsi/api"
 	"github.com/go-adsi/adsi/comiid"
+	"github.com/google/uuid"
 )
 
 func reverseUint16(v uint16) uint16 {
@@ -39,3 +43,346 @@ func dispatchToInt64(v *ole.IDispatch) (value int64, err error) {
 
 	return 0, errors.New("unsupported COM interface for integer conversion")
 }
+
+// putInt64 stores val in the named attribute of iface. The LDAP provider
+// expects large integer values to be supplied as an IADsLargeInteger, so one
+// is created to carry the value.
+func putInt64(iface *api.IADs, name string, val int64) (err error) {
+	largeInt, err := api.NewIADsLargeInteger()
+	if err != nil {
+		return
+	}
+	defer largeInt.Release()
+	if err = largeInt.SetValue(val); err != nil {
+		return
+	}
+	variant := ole.NewVariant(ole.VT_DISPATCH, int64(uintptr(unsafe.Pointer(&largeInt.IDispatch))))
+	return iface.Put(name, &variant)
+}
+
+// bytesToVariant returns a variant holding a safe array of bytes with the
+// given contents. It is the caller's responsibility to clear the returned
+// variant.
+func bytesToVariant(b []byte) (v *ole.VARIANT, err error) {
+	array, _ := comutil.SafeArrayCreateVector(ole.VT_UI1, 0, uint32(len(b)))
+	if array == nil {
+		return nil, errors.New("unable to create safe array of bytes")
+	}
+	for i := range b {
+		if err = comutil.SafeArrayPutElement(array, int32(i), unsafe.Pointer(&b[i])); err != nil {
+			(&ole.SafeArrayConversion{Array: array}).Release()
+			return nil, err
+		}
+	}
+	variant := ole.NewVariant(ole.VT_ARRAY|ole.VT_UI1, int64(uintptr(unsafe.Pointer(array))))
+	return &variant, nil
+}
+
+// valueToVariant converts a Go value to a variant that can be stored in an
+// ADSI attribute. Strings, byte slices, booleans and integers are supported.
+// 64-bit integers are stored as IADsLargeInteger values, and values of type
+// int outside the range of a 32-bit integer as VT_I8. Values of type uint32
+// keep their bit pattern, as the flags held by attributes such as groupType
+// require. It is the caller's responsibility to clear the returned variant.
+func valueToVariant(value interface{}) (v *ole.VARIANT, err error) {
+	var variant ole.VARIANT
+	switch x := value.(type) {
+	case string:
+		variant = ole.NewVariant(ole.VT_BSTR, int64(uintptr(unsafe.Pointer(ole.SysAllocStringLen(x)))))
+	case []byte:
+		return bytesToVariant(x)
+	case bool:
+		if x {
+			variant = ole.NewVariant(ole.VT_BOOL, -1)
+		} else {
+			variant = ole.NewVariant(ole.VT_BOOL, 0)
+		}
+	case int:
+		if x < math.MinInt32 || x > math.MaxInt32 {
+			variant = ole.NewVariant(ole.VT_I8, int64(x))
+		} else {
+			variant = ole.NewVariant(ole.VT_I4, int64(int32(x)))
+		}
+	case int32:
+		variant = ole.NewVariant(ole.VT_I4, int64(x))
+	case uint32:
+		variant = ole.NewVariant(ole.VT_I4, int64(int32(x)))
+	case int64:
+		largeInt, err := api.NewIADsLargeInteger()
+		if err != nil {
+			return nil, err
+		}
+		if err = largeInt.SetValue(x); err != nil {
+			largeInt.Release()
+			return nil, err
+		}
+		// The variant takes ownership of the reference
+		variant = ole.NewVariant(ole.VT_DISPATCH, int64(uintptr(unsafe.Pointer(&largeInt.IDispatch))))
+	default:
+		return nil, fmt.Errorf("unsupported attribute value type %T", value)
+	}
+	return &variant, nil
+}
+
+// valuesToVariantArray converts a slice of Go values to a variant holding a
+// safe array of variants, as expected by IADs.PutEx. It is the caller's
+// responsibility to clear the returned variant.
+func valuesToVariantArray(values []interface{}) (v *ole.VARIANT, err error) {
+	array, _ := comutil.SafeArrayCreateVector(ole.VT_VARIANT, 0, uint32(len(values)))
+	if array == nil {
+		return nil, errors.New("unable to create safe array of variants")
+	}
+	for i, value := range values {
+		var element *ole.VARIANT
+		if element, err = valueToVariant(value); err == nil {
+			// SafeArrayPutElement makes its own copy of the variant
+			err = comutil.SafeArrayPutElement(array, int32(i), unsafe.Pointer(element))
+			element.Clear()
+		}
+		if err != nil {
+			(&ole.SafeArrayConversion{Array: array}).Release()
+			return nil, err
+		}
+	}
+	variant := ole.NewVariant(ole.VT_ARRAY|ole.VT_VARIANT, int64(uintptr(unsafe.Pointer(array))))
+	return &variant, nil
+}
+
+// putEx applies a PutEx operation with the given values to iface.
+func putEx(iface *api.IADs, controlCode uint32, name string, values []interface{}) (err error) {
+	if controlCode == api.ADS_PROPERTY_CLEAR {
+		var variant ole.VARIANT
+		ole.VariantInit(&variant)
+		return iface.PutEx(controlCode, name, &variant)
+	}
+	variant, err := valuesToVariantArray(values)
+	if err != nil {
+		return
+	}
+	defer variant.Clear()
+	return iface.PutEx(controlCode, name, variant)
+}
+
+// guidFromWindowsBytes converts a GUID in the mixed-endian binary layout used
+// by Windows, such as the value of schemaIDGUID, to a UUID whose string form
+// matches the one displayed by Windows tools.
+func guidFromWindowsBytes(b []byte) (guid uuid.UUID) {
+	if len(b) != 16 {
+		return
+	}
+	binary.BigEndian.PutUint32(guid[0:], reverseUint32(binary.BigEndian.Uint32(b[0:])))
+	binary.BigEndian.PutUint16(guid[4:], reverseUint16(binary.BigEndian.Uint16(b[4:])))
+	binary.BigEndian.PutUint16(guid[6:], reverseUint16(binary.BigEndian.Uint16(b[6:])))
+	copy(guid[8:], b[8:])
+	return
+}
+
+// hresultNoSuchObject is the HRESULT form of ERROR_DS_NO_SUCH_OBJECT, which
+// is returned when binding to or searching beneath a missing object.
+const hresultNoSuchObject = 0x80072030
+
+// hresult returns the HRESULT carried by err, if any.
+func hresult(err error) (code uintptr, ok bool) {
+	var oleErr *ole.OleError
+	if errors.As(err, &oleErr) {
+		return oleErr.Code(), true
+	}
+	return 0, false
+}
+
+// isNoSuchObject returns true if err reports that a directory object does
+// not exist.
+func isNoSuchObject(err error) bool {
+	code, ok := hresult(err)
+	return ok && code == hresultNoSuchObject
+}
+
+// eachVariant locks an array of variants and passes its elements to fn, so
+// that they can be read in place rather than being copied out of the array
+// one by one. The elements are only valid until fn returns, and interfaces
+// held by them belong to the array; fn must add a reference to any interface
+// it retains.
+func eachVariant(array *ole.SafeArrayConversion, fn func(elements []ole.VARIANT) error) error {
+	vt, err := array.GetType()
+	if err != nil {
+		return err
+	}
+	if ole.VT(vt) != ole.VT_VARIANT {
+		return comutil.ErrNonVariantArray
+	}
+	n, err := array.TotalElements(0)
+	if err != nil || n <= 0 {
+		return err
+	}
+
+	data, err := api.SafeArrayAccessData(array.Array)
+	if err != nil {
+		return err
+	}
+	defer api.SafeArrayUnaccessData(array.Array)
+	return fn(unsafe.Slice((*ole.VARIANT)(data), n))
+}
+
+// variantValues converts variants to Go values, as
+// comutil.SafeArrayToVariantSlice does for the elements of an array.
+// Strings are decoded with d, which may be nil.
+//
+// If the variants contain IUnknown or IDispatch members, a reference is added
+// to each of them and it is the caller's responsibility to release them.
+func variantValues(d *api.Decoder, elements []ole.VARIANT) (values []interface{}, err error) {
+	values = make([]interface{}, 0, len(elements))
+	for i := range elements {
+		element := &elements[i]
+		var value interface{}
+		switch element.VT {
+		case ole.VT_BSTR:
+			value = d.BSTR(variantBSTR(element))
+		case ole.VT_DISPATCH, ole.VT_UNKNOWN:
+			unknown := element.ToIUnknown()
+			if unknown == nil {
+				continue
+			}
+			unknown.AddRef()
+			if element.VT == ole.VT_DISPATCH {
+				value = element.ToIDispatch()
+			} else {
+				value = unknown
+			}
+		default:
+			var valueErr error
+			value, valueErr = comutil.VariantToValue(element)
+			if valueErr != nil {
+				if err == nil {
+					err = fmt.Errorf("unable to interpret array element %d: %v", i, valueErr)
+				}
+				continue
+			}
+		}
+		values = append(values, value)
+	}
+	return values, err
+}
+
+// releaseValues releases the IUnknown and IDispatch members of values.
+func releaseValues(values []interface{}) {
+	for _, value := range values {
+		switch v := value.(type) {
+		case *ole.IUnknown:
+			v.Release()
+		case *ole.IDispatch:
+			v.Release()
+		}
+	}
+}
+
+// variantStrings returns the string members of the variants, decoding them
+// with d, which may be nil.
+func variantStrings(d *api.Decoder, elements []ole.VARIANT) (values []string) {
+	for i := range elements {
+		if elements[i].VT == ole.VT_BSTR {
+			values = append(values, d.BSTR(variantBSTR(&elements[i])))
+		}
+	}
+	return
+}
+
+// variantBytes returns the byte array members of the variants.
+func variantBytes(elements []ole.VARIANT) (values [][]byte) {
+	for i := range elements {
+		if elements[i].VT == ole.VT_ARRAY|ole.VT_UI1 {
+			if b, ok := arrayBytes(elements[i].ToArray()); ok {
+				values = append(values, b)
+			}
+		}
+	}
+	return
+}
+
+// variantBools returns the boolean members of the variants.
+func variantBools(elements []ole.VARIANT) (values []bool) {
+	for i := range elements {
+		if elements[i].VT == ole.VT_BOOL {
+			values = append(values, int16(elements[i].Val) != 0)
+		}
+	}
+	return
+}
+
+// variantInt64s returns the integer members of the variants, including the
+// IADsLargeInteger objects that hold 64-bit attribute values. Unsigned
+// integers are coerced into signed ones.
+func variantInt64s(elements []ole.VARIANT) (values []int64, err error) {
+	for i := range elements {
+		element := &elements[i]
+		switch element.VT {
+		case ole.VT_I1:
+			values = append(values, int64(int8(element.Val)))
+		case ole.VT_UI1:
+			values = append(values, int64(uint8(element.Val)))
+		case ole.VT_I2:
+			values = append(values, int64(int16(element.Val)))
+		case ole.VT_UI2:
+			values = append(values, int64(uint16(element.Val)))
+		case ole.VT_I4, ole.VT_INT:
+			values = append(values, int64(int32(element.Val)))
+		case ole.VT_UI4, ole.VT_UINT:
+			values = append(values, int64(uint32(element.Val)))
+		case ole.VT_I8, ole.VT_UI8:
+			values = append(values, element.Val)
+		case ole.VT_DISPATCH:
+			value, err := dispatchToInt64(element.ToIDispatch())
+			if err != nil {
+				return nil, fmt.Errorf("value %d: %v", i, err)
+			}
+			values = append(values, value)
+		}
+	}
+	return
+}
+
+// variantGUIDs returns the members of the variants that hold GUIDs, either in
+// string form or as 16 byte arrays. Byte arrays are returned as-is, without
+// any byte ordering adjustment.
+func variantGUIDs(elements []ole.VARIANT) (values []uuid.UUID) {
+	for i := range elements {
+		element := &elements[i]
+		switch element.VT {
+		case ole.VT_BSTR:
+			var d *api.Decoder
+			if value, err := uuid.Parse(d.BSTR(variantBSTR(element))); err == nil {
+				values = append(values, value)
+			}
+		case ole.VT_ARRAY | ole.VT_UI1:
+			if b, ok := arrayBytes(element.ToArray()); ok {
+				if value, err := uuid.FromBytes(b); err == nil {
+					values = append(values, value)
+				}
+			}
+		}
+	}
+	return
+}
+
+// arrayBytes returns a copy of the elements of a byte array.
+func arrayBytes(array *ole.SafeArrayConversion) (b []byte, ok bool) {
+	n, err := array.TotalElements(0)
+	if err != nil {
+		return nil, false
+	}
+	b = make([]byte, n)
+	if n == 0 {
+		return b, true
+	}
+	data, err := api.SafeArrayAccessData(array.Array)
+	if err != nil {
+		return nil, false
+	}
+	defer api.SafeArrayUnaccessData(array.Array)
+	copy(b, unsafe.Slice((*byte)(data), n))
+	return b, true
+}
+
+// variantBSTR returns the BSTR held by a VT_BSTR variant.
+func variantBSTR(v *ole.VARIANT) *uint16 {
+	return *(**uint16)(unsafe.Pointer(&v.Val))
+}
@@ -0,0 +1,145 @@
+package adsi
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"testing"
+	"unicode/utf16"
+	"unsafe"
+
+	ole "github.com/go-ole/go-ole"
+	"github.com/google/uuid"
+)
+
+// bstrs keeps the strings of the variants returned by bstrVariant alive, as
+// the variants hold them as integers that the garbage collector ignores.
+var bstrs [][]uint16
+
+// bstrVariant returns a VT_BSTR variant holding s. The string is allocated
+// by Go rather than by SysAllocString, so the variant must not be cleared.
+func bstrVariant(s string) ole.VARIANT {
+	units := utf16.Encode([]rune(s))
+	buf := make([]uint16, 2, 2+len(units)+1)
+	*(*uint32)(unsafe.Pointer(&buf[0])) = uint32(len(units) * 2)
+	buf = append(append(buf, units...), 0)
+	bstrs = append(bstrs, buf)
+	return ole.NewVariant(ole.VT_BSTR, int64(uintptr(unsafe.Pointer(&buf[2]))))
+}
+
+func TestVariantValues(t *testing.T) {
+	elements := []ole.VARIANT{
+		bstrVariant("CN=Users,DC=example,DC=com"),
+		ole.NewVariant(ole.VT_I4, int64(uint32(0xffffffff))),
+		ole.NewVariant(ole.VT_BOOL, -1),
+		ole.NewVariant(ole.VT_DISPATCH, 0),
+		ole.NewVariant(ole.VT_I8, 1<<40),
+	}
+	got, err := variantValues(nil, elements)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{"CN=Users,DC=example,DC=com", int32(-1), true, int64(1 << 40)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("variantValues() = %#v, want %#v", got, want)
+	}
+}
+
+func TestVariantTypedValues(t *testing.T) {
+	guid := uuid.MustParse("6f4f5d3c-1b2a-4e8f-9c7d-0a1b2c3d4e5f")
+	elements := []ole.VARIANT{
+		bstrVariant("first"),
+		bstrVariant(guid.String()),
+		ole.NewVariant(ole.VT_I1, -2&0xff),
+		ole.NewVariant(ole.VT_UI1, 200),
+		ole.NewVariant(ole.VT_I2, -3&0xffff),
+		ole.NewVariant(ole.VT_UI2, 60000),
+		ole.NewVariant(ole.VT_I4, int64(uint32(0xfffffffb))),
+		ole.NewVariant(ole.VT_UI4, 0xfffffffb),
+		ole.NewVariant(ole.VT_I8, -1<<40),
+		ole.NewVariant(ole.VT_BOOL, 0),
+		ole.NewVariant(ole.VT_BOOL, 0xffff),
+	}
+
+	if got, want := variantStrings(nil, elements), []string{"first", guid.String()}; !reflect.DeepEqual(got, want) {
+		t.Errorf("variantStrings() = %q, want %q", got, want)
+	}
+	if got, want := variantBools(elements), []bool{false, true}; !reflect.DeepEqual(got, want) {
+		t.Errorf("variantBools() = %v, want %v", got, want)
+	}
+	if got, want := variantGUIDs(elements), []uuid.UUID{guid}; !reflect.DeepEqual(got, want) {
+		t.Errorf("variantGUIDs() = %v, want %v", got, want)
+	}
+	ints, err := variantInt64s(elements)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{-2, 200, -3, 60000, -5, 0xfffffffb, -1 << 40}; !reflect.DeepEqual(ints, want) {
+		t.Errorf("variantInt64s() = %v, want %v", ints, want)
+	}
+}
+
+func TestValueToVariantIntegers(t *testing.T) {
+	// Computed at run time so that the test builds where int has 32 bits
+	var above, below int64 = math.MaxInt32 + 1, math.MinInt32 - 1
+	tests := []struct {
+		name   string
+		in     interface{}
+		wantVT ole.VT
+		want   int64
+	}{
+		{"int", 42, ole.VT_I4, 42},
+		{"negative int", -42, ole.VT_I4, int64(int32(-42))},
+		{"int32 max", math.MaxInt32, ole.VT_I4, math.MaxInt32},
+		{"int32 min", math.MinInt32, ole.VT_I4, math.MinInt32},
+		{"int above int32", int(above), ole.VT_I8, above},
+		{"int below int32", int(below), ole.VT_I8, below},
+		{"int32", int32(-7), ole.VT_I4, -7},
+		{"uint32 flags", uint32(0x80000002), ole.VT_I4, int64(int32(-0x7ffffffe))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantVT == ole.VT_I8 && strconv.IntSize < 64 {
+				t.Skip("int has 32 bits")
+			}
+			v, err := valueToVariant(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v.VT != tt.wantVT || v.Val != tt.want {
+				t.Errorf("valueToVariant(%v) = %v %d, want %v %d", tt.in, v.VT, v.Val, tt.wantVT, tt.want)
+			}
+		})
+	}
+}
+
+func BenchmarkVariantValues(b *testing.B) {
+	elements := []ole.VARIANT{
+		bstrVariant("top"),
+		bstrVariant("person"),
+		bstrVariant("organizationalPerson"),
+		bstrVariant("user"),
+		ole.NewVariant(ole.VT_I4, 512),
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := variantValues(nil, elements); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVariantStrings(b *testing.B) {
+	elements := []ole.VARIANT{
+		bstrVariant("top"),
+		bstrVariant("person"),
+		bstrVariant("organizationalPerson"),
+		bstrVariant("user"),
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		variantStrings(nil, elements)
+	}
+}
@@ -0,0 +1,176 @@
+package adsi
+
+import (
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-adsi/adsi/api"
+	"github.com/google/uuid"
+)
+
+// watchPollInterval is how long a watcher waits for a notification before
+// checking whether it has been closed.
+const watchPollInterval = time.Second
+
+// watchAttrs are the attributes requested by every watcher.
+var watchAttrs = []string{"objectGUID", "uSNChanged", "isDeleted"}
+
+// ObjectChange describes a change to an object reported by a Watcher.
+type ObjectChange struct {
+	// DN is the distinguished name of the object after the change.
+	DN   string
+	GUID uuid.UUID
+
+	// USNChanged is the update sequence number of the change on the server
+	// that reported it.
+	USNChanged int64
+
+	// Deleted is true if the object has been deleted. Deletions are only
+	// reported if WatchOptions.Deleted is set.
+	Deleted bool
+
+	// Attributes holds the raw values of every attribute returned by the
+	// server, including those requested by WatchOptions.Attributes.
+	Attributes map[string][][]byte
+
+	// Received is the time at which the notification was received.
+	Received time.Time
+}
+
+// WatchOptions controls the objects and attributes reported by Watch.
+type WatchOptions struct {
+	// Server is the domain controller to watch. Notifications only report
+	// changes once they have replicated to the server. If empty a domain
+	// controller of the computer's domain is chosen.
+	Server string
+
+	// Scope determines whether changes to the base object itself, its
+	// immediate children or its entire subtree are reported. Active
+	// Directory only permits a subtree scope when the base object is the
+	// head of a naming context.
+	Scope SearchScope
+
+	// Attributes lists additional attributes to return with each change.
+	Attributes []string
+
+	// Deleted includes deleted objects in the notifications.
+	Deleted bool
+}
+
+// Watcher delivers notifications of changes to the objects beneath a base
+// object, using an LDAP search with the change notification control that
+// remains outstanding until the watcher is closed.
+type Watcher struct {
+	// C receives a value each time an object changes. It is closed when the
+	// watcher is closed or the search fails, after which Err reports the
+	// cause.
+	C <-chan ObjectChange
+
+	n     *api.LDAPNotification
+	done  chan struct{}
+	ended chan struct{}
+	once  sync.Once
+	err   error
+}
+
+// Watch starts watching the object with the given distinguished name for
+// changes. The connection is made using the security context of the
+// application.
+//
+// Each notification carries the state of the object after the change rather
+// than the change itself, and several changes in quick succession may be
+// reported by a single notification. The watcher consumes a connection to
+// the server until it is closed. It is the caller's responsibility to call
+// Close on the watcher when it is no longer needed.
+func Watch(baseDN string, opts WatchOptions) (*Watcher, error) {
+	var scope uint32
+	switch opts.Scope {
+	case ScopeBase:
+		scope = api.LDAP_SCOPE_BASE
+	case ScopeOneLevel:
+		scope = api.LDAP_SCOPE_ONELEVEL
+	default:
+		scope = api.LDAP_SCOPE_SUBTREE
+	}
+	var controls []api.LDAPControl
+	if opts.Deleted {
+		controls = append(controls, api.LDAPControl{OID: api.LDAP_SERVER_SHOW_DELETED_OID})
+	}
+	attrs := append(append([]string(nil), watchAttrs...), opts.Attributes...)
+	n, err := api.LDAPNotify(opts.Server, baseDN, scope, attrs, controls)
+	if err != nil {
+		return nil, err
+	}
+
+	c := make(chan ObjectChange)
+	w := &Watcher{C: c, n: n, done: make(chan struct{}), ended: make(chan struct{})}
+	go w.run(c)
+	return w, nil
+}
+
+// run reads notifications until the watcher is closed or the search fails.
+func (w *Watcher) run(c chan<- ObjectChange) {
+	defer close(w.ended)
+	defer close(c)
+	for {
+		select {
+		case <-w.done:
+			return
+		default:
+		}
+		entry, err := w.n.Next(watchPollInterval)
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			w.err = err
+			return
+		}
+		if entry == nil {
+			continue
+		}
+		select {
+		case c <- objectChangeFromEntry(entry):
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// Err returns the error that ended the watcher, if any. It must only be
+// called after C has been closed.
+func (w *Watcher) Err() error {
+	return w.err
+}
+
+// Close stops the watcher and releases its connection. C is closed once
+// the watcher has stopped. It returns an error if the connection could not
+// be closed cleanly.
+func (w *Watcher) Close() (err error) {
+	w.once.Do(func() {
+		close(w.done)
+		<-w.ended
+		err = w.n.Close()
+	})
+	return err
+}
+
+// objectChangeFromEntry converts a notification entry to an ObjectChange.
+func objectChangeFromEntry(entry *api.LDAPEntry) ObjectChange {
+	change := ObjectChange{DN: entry.DN, Attributes: make(map[string][][]byte), Received: time.Now()}
+	if values := entry.Values("objectGUID"); len(values) > 0 {
+		change.GUID = guidFromWindowsBytes(values[0])
+	}
+	if values := entry.Values("uSNChanged"); len(values) > 0 {
+		change.USNChanged, _ = strconv.ParseInt(string(values[0]), 10, 64)
+	}
+	if values := entry.Values("isDeleted"); len(values) > 0 {
+		change.Deleted = string(values[0]) == "TRUE"
+	}
+	for name, values := range entry.Attributes {
+		change.Attributes[name] = values
+	}
+	return change
+}
@@ -0,0 +1,150 @@
+package adsi
+
+import (
+	"strconv"
+
+	"github.com/go-adsi/adsi/adspath"
+)
+
+// WellKnownObject identifies one of the containers that Active Directory
+// creates in every domain. The containers are listed in the wellKnownObjects
+// attribute of the domain under fixed GUIDs, which makes it possible to bind
+// to them without knowing their names. Their names can differ from the
+// defaults, because the containers may have been renamed or redirected, as
+// redircmp and redirusr do for Computers and Users.
+type WellKnownObject int
+
+// Well-known objects of a domain.
+const (
+	WellKnownUsers WellKnownObject = iota + 1
+	WellKnownComputers
+	WellKnownDomainControllers
+	WellKnownManagedServiceAccounts
+	WellKnownSystem
+	WellKnownInfrastructure
+	WellKnownDeletedObjects
+	WellKnownLostAndFound
+	WellKnownForeignSecurityPrincipals
+	WellKnownProgramData
+	WellKnownMicrosoftProgramData
+	WellKnownNTDSQuotas
+	WellKnownKeys
+)
+
+// wellKnownGUIDs hold the well-known GUIDs in the hexadecimal form used by
+// the WKGUID binding syntax, as defined in ntdsapi.h.
+var wellKnownGUIDs = map[WellKnownObject]string{
+	WellKnownUsers:                     "a9d1ca15768811d1aded00c04fd8d5cd",
+	WellKnownComputers:                 "aa312825768811d1aded00c04fd8d5cd",
+	WellKnownDomainControllers:         "a361b2ffffd211d1aa4b00c04fd7d83a",
+	WellKnownManagedServiceAccounts:    "1eb93889e40c45df9f0c64d23bbb6237",
+	WellKnownSystem:                    "ab1d30f3768811d1aded00c04fd8d5cd",
+	WellKnownInfrastructure:            "2fbac1870ade11d297c400c04fd8d5cd",
+	WellKnownDeletedObjects:            "18e2ea80684f11d2b9aa00c04f79f805",
+	WellKnownLostAndFound:              "ab8153b7768811d1aded00c04fd8d5cd",
+	WellKnownForeignSecurityPrincipals: "22b70c67d56e4efb91e9300fca3dc1aa",
+	WellKnownProgramData:               "09460c08ae1e4a4ea0f64aee7daa1e5a",
+	WellKnownMicrosoftProgramData:      "f4be92a4c777485e878e9421d53087db",
+	WellKnownNTDSQuotas:                "6227f0af1fc2410d8e3bb10615bb5b0f",
+	WellKnownKeys:                      "683a24e2e8164bd3af86ac3c2cf3f981",
+}
+
+// GUID returns the well-known GUID of the object in the hexadecimal form used
+// by the WKGUID binding syntax, or an empty string if w is not a well-known
+// object.
+func (w WellKnownObject) GUID() string {
+	return wellKnownGUIDs[w]
+}
+
+// String returns the default name of the object, such as "Users".
+func (w WellKnownObject) String() string {
+	switch w {
+	case WellKnownUsers:
+		return "Users"
+	case WellKnownComputers:
+		return "Computers"
+	case WellKnownDomainControllers:
+		return "Domain Controllers"
+	case WellKnownManagedServiceAccounts:
+		return "Managed Service Accounts"
+	case WellKnownSystem:
+		return "System"
+	case WellKnownInfrastructure:
+		return "Infrastructure"
+	case WellKnownDeletedObjects:
+		return "Deleted Objects"
+	case WellKnownLostAndFound:
+		return "LostAndFound"
+	case WellKnownForeignSecurityPrincipals:
+		return "ForeignSecurityPrincipals"
+	case WellKnownProgramData:
+		return "Program Data"
+	case WellKnownMicrosoftProgramData:
+		return "Microsoft"
+	case WellKnownNTDSQuotas:
+		return "NTDS Quotas"
+	case WellKnownKeys:
+		return "Keys"
+	default:
+		return "WellKnownObject(" + strconv.Itoa(int(w)) + ")"
+	}
+}
+
+// WellKnownPath returns the LDAP ADsPath that binds to the well-known object
+// within the naming context with the given distinguished name, such as
+// "LDAP://<WKGUID=a9d1ca15768811d1aded00c04fd8d5cd,DC=example,DC=com>". It
+// returns an empty string if w is not a well-known object.
+//
+// The object bound with the returned path reports the path as its ADsPath.
+// Use WellKnownDN to learn the distinguished name of the object.
+func WellKnownPath(w WellKnownObject, nc string) string {
+	guid := w.GUID()
+	if guid == "" {
+		return ""
+	}
+	return (&adspath.Path{Scheme: "LDAP", Path: "<WKGUID=" + guid + "," + adspath.EscapeDN(nc) + ">"}).String()
+}
+
+// wellKnownPath returns the path of the well-known object within the given
+// naming context, or within the default naming context of the domain of the
+// computer if nc is empty.
+func (c *Client) wellKnownPath(op string, w WellKnownObject, nc string) (path string, err error) {
+	if w.GUID() == "" {
+		return "", &Error{Op: op + " " + w.String(), Err: ErrInvalidWellKnownObject}
+	}
+	if nc == "" {
+		root, err := c.ReadRootDSE("")
+		if err != nil {
+			return "", err
+		}
+		nc = root.DefaultNamingContext
+	}
+	return WellKnownPath(w, nc), nil
+}
+
+// OpenWellKnownContainer opens the well-known container within the naming
+// context with the given distinguished name. If nc is empty the default
+// naming context of the domain of the computer is used.
+//
+// The returned container consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned container when it is
+// no longer needed.
+func (c *Client) OpenWellKnownContainer(w WellKnownObject, nc string) (container *Container, err error) {
+	path, err := c.wellKnownPath("OpenWellKnownContainer", w, nc)
+	if err != nil {
+		return nil, err
+	}
+	return c.OpenContainer(path)
+}
+
+// WellKnownDN returns the distinguished name of the well-known object within
+// the naming context with the given distinguished name, such as
+// "CN=Users,DC=example,DC=com". If nc is empty the default naming context of
+// the domain of the computer is used.
+func (c *Client) WellKnownDN(w WellKnownObject, nc string) (dn string, err error) {
+	path, err := c.wellKnownPath("WellKnownDN", w, nc)
+	if err != nil {
+		return "", err
+	}
+	return c.dnAt(path)
+}
@@ -0,0 +1,114 @@
+package adsi
+
+import (
+	"runtime"
+	"sync/atomic"
+
+	"github.com/go-adsi/adsi/api"
+	ole "github.com/go-ole/go-ole"
+)
+
+// worker executes COM calls on a dedicated thread that has been initialized
+// for the apartment model of its client. Each client owns one or more
+// workers, and each object opened through the client makes all of its COM
+// calls with the worker that opened it, so every interface is created and
+// used on the thread that owns it. This avoids the cost of locking and
+// initializing a thread for each call and keeps the extended error
+// information of a call on the thread that made it.
+//
+// A worker is reference counted. The client holds one reference and each
+// object pinned to the worker holds another, so the thread keeps running
+// until the client and all of those objects have been closed.
+type worker struct {
+	calls  chan func()
+	thread uint32
+	refs   atomic.Int64
+}
+
+// startWorker starts a worker thread in the given apartment model and
+// returns once its apartment has been initialized. The returned worker holds
+// a single reference.
+func startWorker(apartment Apartment) (*worker, error) {
+	w := &worker{calls: make(chan func())}
+	w.refs.Store(1)
+	started := make(chan error)
+	go w.loop(apartment, started)
+	if err := <-started; err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// loop runs on the worker thread and executes calls until the worker is
+// stopped.
+func (w *worker) loop(apartment Apartment, started chan<- error) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	if runtime.GOOS != "windows" {
+		started <- ErrUnsupported
+		return
+	}
+	if err := ole.CoInitializeEx(0, apartment.coinit()); err != nil {
+		started <- err
+		return
+	}
+	defer ole.CoUninitialize()
+	w.thread = api.CurrentThreadID()
+	started <- nil
+	for fn := range w.calls {
+		fn()
+	}
+}
+
+// run calls fn on the worker thread and waits for it to return. If fn
+// panics the panic is raised again in the calling goroutine. Calls made
+// from the worker thread itself, such as those made by an audit hook, are
+// executed directly. A nil worker executes fn on the calling thread.
+func (w *worker) run(fn func()) {
+	if w == nil || w.owns() {
+		fn()
+		return
+	}
+	var p interface{}
+	done := make(chan struct{})
+	w.calls <- func() {
+		defer close(done)
+		defer func() { p = recover() }()
+		fn()
+	}
+	<-done
+	if p != nil {
+		panic(p)
+	}
+}
+
+// owns returns true if it is called from the worker thread.
+func (w *worker) owns() bool {
+	return w.thread != 0 && api.CurrentThreadID() == w.thread
+}
+
+// acquire adds a reference to the worker.
+func (w *worker) acquire() {
+	if w != nil {
+		w.refs.Add(1)
+	}
+}
+
+// release removes a reference from the worker, stopping it once the last
+// reference has been removed.
+func (w *worker) release() {
+	if w != nil && w.refs.Add(-1) == 0 {
+		close(w.calls)
+	}
+}
+
+// run calls fn on the worker of the client that opened the object sharing
+// the hooks, or on the calling thread if the object was not opened by a
+// client.
+func (h *hooks) run(fn func()) {
+	if h == nil {
+		fn()
+		return
+	}
+	h.w.run(fn)
+}
@@ -0,0 +1,140 @@
+package adsi
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/go-adsi/adsi/api"
+)
+
+// namespacePath is the root of the WinNT namespace, which can be bound on any
+// computer without a directory.
+const namespacePath = "WinNT:"
+
+// threadOf returns the thread on which the hooks run their calls.
+func threadOf(h *hooks) (id uint32) {
+	h.run(func() { id = api.CurrentThreadID() })
+	return
+}
+
+func TestWorkerRun(t *testing.T) {
+	for _, apartment := range []Apartment{ApartmentMTA, ApartmentSTA} {
+		t.Run(apartment.String(), func(t *testing.T) {
+			runtime.LockOSThread()
+			defer runtime.UnlockOSThread()
+
+			w, err := startWorker(apartment)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer w.release()
+
+			var outer, inner uint32
+			w.run(func() {
+				outer = api.CurrentThreadID()
+				// Calls made from the worker thread run directly
+				w.run(func() { inner = api.CurrentThreadID() })
+			})
+			if outer != w.thread || inner != w.thread {
+				t.Errorf("calls ran on threads %d and %d, want %d", outer, inner, w.thread)
+			}
+			if caller := api.CurrentThreadID(); outer == caller {
+				t.Errorf("call ran on the calling thread %d", caller)
+			}
+
+			defer func() {
+				if p := recover(); p != "boom" {
+					t.Errorf("recovered %v, want the panic of the call", p)
+				}
+			}()
+			w.run(func() { panic("boom") })
+		})
+	}
+}
+
+func TestClientPinsObjectsToWorkers(t *testing.T) {
+	const workers = 3
+	c, err := NewClientWithOptions(ClientOptions{Workers: workers})
+	if err != nil {
+		t.Skipf("unable to create a client: %v", err)
+	}
+	defer c.Close()
+
+	// Binds are distributed among the workers in turn
+	var objects []*Object
+	defer func() {
+		for _, obj := range objects {
+			obj.Close()
+		}
+	}()
+	for i := 0; i < workers+1; i++ {
+		obj, err := c.Open(namespacePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		objects = append(objects, obj)
+	}
+	seen := make(map[*worker]bool)
+	for i, obj := range objects[:workers] {
+		if seen[obj.h.w] {
+			t.Errorf("object %d shares a worker with an earlier object", i)
+		}
+		seen[obj.h.w] = true
+	}
+	if objects[workers].h.w != objects[0].h.w {
+		t.Error("binds do not return to the first worker once each has been used")
+	}
+
+	for i, obj := range objects[:workers] {
+		// Each object makes its calls on the worker that opened it
+		if got := threadOf(obj.h); got != obj.h.w.thread {
+			t.Errorf("object %d ran a call on thread %d, want %d", i, got, obj.h.w.thread)
+		}
+		if _, err := obj.Name(); err != nil {
+			t.Errorf("object %d: %v", i, err)
+		}
+
+		// Objects opened on behalf of an object share its worker
+		derived, err := obj.h.open(namespacePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if derived.h.w != obj.h.w {
+			t.Errorf("object derived from object %d was opened on another worker", i)
+		}
+		if _, err := derived.Name(); err != nil {
+			t.Errorf("object derived from object %d: %v", i, err)
+		}
+		derived.Close()
+	}
+}
+
+func TestObjectOutlivesClient(t *testing.T) {
+	// Open closes its ephemeral client before returning the object
+	obj, err := Open(namespacePath)
+	if err != nil {
+		t.Skipf("unable to open %s: %v", namespacePath, err)
+	}
+	defer obj.Close()
+	w := obj.h.w
+	if got := threadOf(obj.h); got != w.thread {
+		t.Errorf("call ran on thread %d, want %d", got, w.thread)
+	}
+	if _, err := obj.Name(); err != nil {
+		t.Error(err)
+	}
+
+	// The worker of the object is used for the objects derived from it,
+	// even though the client can no longer open objects
+	derived, err := obj.h.open(namespacePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer derived.Close()
+	if derived.h.w != w {
+		t.Error("derived object was opened on another worker")
+	}
+	if refs := w.refs.Load(); refs != 2 {
+		t.Errorf("worker holds %d references, want one for each open object", refs)
+	}
+}
@@ -0,0 +1,103 @@
+package adsi
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/go-adsi/adsi/api"
+)
+
+// Workstations returns the NetBIOS names of the computers the user is
+// permitted to log on to. An empty slice indicates that the user may log on
+// to any computer.
+func (u *User) Workstations() (names []string, err error) {
+	u.m.Lock()
+	defer u.m.Unlock()
+	if u.closed() {
+		return nil, ErrClosed
+	}
+	value, err := u.object.AttrString("userWorkstations")
+	if errors.Is(err, api.ErrPropertyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return
+	}
+	return splitWorkstations(value), nil
+}
+
+// SetWorkstations restricts the user to logging on to the computers with the
+// given NetBIOS names. If no names are provided the restriction is removed.
+// The value must be commited with SetInfo to be made persistent.
+func (u *User) SetWorkstations(names ...string) error {
+	var cleaned []string
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			cleaned = append(cleaned, name)
+		}
+	}
+	if len(cleaned) == 0 {
+		return u.PutEx(api.ADS_PROPERTY_CLEAR, "userWorkstations")
+	}
+	return u.PutString("userWorkstations", strings.Join(cleaned, ","))
+}
+
+// AddWorkstation permits the user to log on to the computers with the given
+// NetBIOS names in addition to those already permitted. Names that are
+// already present are ignored. The value must be commited with SetInfo to be
+// made persistent.
+//
+// Note that adding a workstation to a user without any restriction will
+// restrict the user to the given workstations.
+func (u *User) AddWorkstation(names ...string) error {
+	current, err := u.Workstations()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if indexFold(current, name) < 0 {
+			current = append(current, name)
+		}
+	}
+	return u.SetWorkstations(current...)
+}
+
+// RemoveWorkstation prevents the user from logging on to the computers with
+// the given NetBIOS names. The value must be commited with SetInfo to be made
+// persistent.
+//
+// Note that removing the last workstation removes the restriction entirely,
+// permitting the user to log on to any computer.
+func (u *User) RemoveWorkstation(names ...string) error {
+	current, err := u.Workstations()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if i := indexFold(current, name); i >= 0 {
+			current = append(current[:i], current[i+1:]...)
+		}
+	}
+	return u.SetWorkstations(current...)
+}
+
+// splitWorkstations decodes the comma-separated userWorkstations value.
+func splitWorkstations(value string) (names []string) {
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return
+}
+
+// indexFold returns the index of the first element of s that is equal to v
+// under Unicode case-folding, or -1 if there is none.
+func indexFold(s []string, v string) int {
+	for i := range s {
+		if strings.EqualFold(s[i], v) {
+			return i
+		}
+	}
+	return -1
+}